@@ -0,0 +1,79 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/ofri/mde/internal/assets"
+	"github.com/ofri/mde/internal/config"
+)
+
+// runAssets implements `mde assets [--dir assets] [--delete | --move-to
+// dir] [workspace]`. It reports the files in the assets directory that no
+// markdown file in the workspace links to, and only deletes or moves them
+// when asked.
+func runAssets(cfg *config.Config, args []string) error {
+	fs := flag.NewFlagSet("assets", flag.ContinueOnError)
+	dir := fs.String("dir", cfg.AssetsDir, "assets directory, relative to the workspace (assets_dir)")
+	remove := fs.Bool("delete", false, "delete the unused assets")
+	moveTo := fs.String("move-to", "", "move the unused assets into this directory")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: mde assets [--dir assets] [--delete | --move-to dir] [workspace]")
+		fs.PrintDefaults()
+	}
+
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil
+		}
+		return err
+	}
+	if fs.NArg() > 1 || (*remove && *moveTo != "") {
+		fs.Usage()
+		return errors.New("give at most one workspace, and either --delete or --move-to")
+	}
+
+	root := "."
+	if fs.NArg() == 1 {
+		root = fs.Arg(0)
+	}
+	assetsDir := config.ExpandHome(*dir)
+	if !filepath.IsAbs(assetsDir) {
+		assetsDir = filepath.Join(root, assetsDir)
+	}
+	report, err := assets.Scan(root, assetsDir, nil)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%d of %d assets in %s are unused by the %d notes in %s (%s)\n",
+		len(report.Unused), report.Assets, report.Dir, report.Files, report.Root, assets.FormatSize(report.Size))
+	for _, path := range report.Unused {
+		rel, err := filepath.Rel(report.Dir, path)
+		if err != nil {
+			rel = path
+		}
+		fmt.Println("  " + rel)
+	}
+
+	switch {
+	case len(report.Unused) == 0:
+	case *remove:
+		if err := assets.Delete(report.Unused); err != nil {
+			return err
+		}
+		fmt.Fprintf(os.Stderr, "Deleted %d files\n", len(report.Unused))
+	case *moveTo != "":
+		moved, err := assets.Move(report.Unused, report.Dir, config.ExpandHome(*moveTo))
+		if err != nil {
+			return fmt.Errorf("moved %d files, then: %w", len(moved), err)
+		}
+		fmt.Fprintf(os.Stderr, "Moved %d files to %s\n", len(moved), *moveTo)
+	default:
+		fmt.Fprintln(os.Stderr, "Dry run: pass --delete or --move-to dir to clean up")
+	}
+	return nil
+}
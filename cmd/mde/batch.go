@@ -0,0 +1,76 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/ofri/mde/pkg/editor"
+)
+
+// runBatch implements `mde --batch 'script' file...` and
+// `mde --batch-file script.txt file...`, running the script headlessly
+// against each file in turn. Files are only written by a save command.
+func runBatch(args []string) error {
+	fs := flag.NewFlagSet("batch", flag.ContinueOnError)
+	script := fs.String("batch", "", "commands to run, e.g. 'replace-all:foo:bar; format-tables; save'")
+	scriptFile := fs.String("batch-file", "", "file of commands to run, one per line")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: mde --batch 'commands' file...")
+		fmt.Fprintln(fs.Output(), "       mde --batch-file script.txt file...")
+		fs.PrintDefaults()
+		fmt.Fprintln(fs.Output(), "\nCommands:")
+		for _, line := range editor.BatchCommands() {
+			fmt.Fprintln(fs.Output(), "  "+line)
+		}
+	}
+
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil
+		}
+		return err
+	}
+	if (*script == "") == (*scriptFile == "") {
+		fs.Usage()
+		return errors.New("give exactly one of --batch and --batch-file")
+	}
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return errors.New("no files to edit")
+	}
+
+	if *scriptFile != "" {
+		data, err := os.ReadFile(*scriptFile)
+		if err != nil {
+			return err
+		}
+		*script = string(data)
+	}
+	// Catch mistakes before any file is touched
+	if _, err := editor.ParseScript(*script); err != nil {
+		return err
+	}
+
+	for _, filename := range fs.Args() {
+		ed, err := editor.Open(filename)
+		if err != nil {
+			return err
+		}
+		if err := ed.RunScript(*script); err != nil {
+			return fmt.Errorf("%s: %w", filename, err)
+		}
+		if ed.Modified() {
+			fmt.Fprintf(os.Stderr, "%s: changes not saved, add a save command\n", filename)
+		}
+	}
+	return nil
+}
+
+// isBatchFlag reports whether arg starts batch mode.
+func isBatchFlag(arg string) bool {
+	name, _, _ := strings.Cut(strings.TrimLeft(arg, "-"), "=")
+	return strings.HasPrefix(arg, "-") && (name == "batch" || name == "batch-file")
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/ofri/mde/internal/tui"
+)
+
+// runDiff implements `mde diff a.md b.md`, returning the side-by-side view
+// to run instead of the editor. Returns nil when there is nothing to show
+// (e.g. --help).
+func runDiff(args []string) (*tui.DiffView, error) {
+	fs := flag.NewFlagSet("diff", flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: mde diff <file> <file>")
+		fs.PrintDefaults()
+	}
+
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if fs.NArg() != 2 {
+		fs.Usage()
+		return nil, errors.New("diff needs exactly two files")
+	}
+
+	left, err := os.ReadFile(fs.Arg(0))
+	if err != nil {
+		return nil, err
+	}
+	right, err := os.ReadFile(fs.Arg(1))
+	if err != nil {
+		return nil, err
+	}
+	return tui.NewDiffView(fs.Arg(0), string(left), fs.Arg(1), string(right))
+}
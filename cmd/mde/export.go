@@ -0,0 +1,73 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+
+	"github.com/ofri/mde/internal/config"
+	"github.com/ofri/mde/internal/pandoc"
+)
+
+// runExport implements `mde export --via-pandoc --to docx [-o out.docx]
+// file.md`, converting the file with pandoc and the options configured
+// for the format. Pandoc's log is streamed to stderr as it converts.
+func runExport(cfg *config.Config, args []string) error {
+	fs := flag.NewFlagSet("export", flag.ContinueOnError)
+	viaPandoc := fs.Bool("via-pandoc", false, "convert with pandoc (pandoc_command), the only exporter so far")
+	to := fs.String("to", "", "output format, e.g. docx, epub, latex, odt")
+	output := fs.String("o", "", "file to write (default: the input with the format's extension)")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: mde export --via-pandoc --to format [-o output] file.md")
+		fs.PrintDefaults()
+	}
+
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil
+		}
+		return err
+	}
+	if !*viaPandoc {
+		fs.Usage()
+		return errors.New("exporting needs --via-pandoc")
+	}
+	if *to == "" || fs.NArg() != 1 {
+		fs.Usage()
+		return errors.New("give an output format with --to and one file to export")
+	}
+
+	input := fs.Arg(0)
+	markdown, err := os.ReadFile(input)
+	if err != nil {
+		return err
+	}
+	if *output == "" {
+		*output = pandoc.OutputPath(input, *to)
+	}
+	if abs, err := filepath.Abs(*output); err == nil {
+		*output = abs // pandoc runs in the input's directory
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	fmt.Fprintf(os.Stderr, "Converting %s to %s with pandoc...\n", input, *to)
+	err = pandoc.Convert(ctx, string(markdown), pandoc.Options{
+		Command: cfg.PandocCommand,
+		To:      *to,
+		Output:  *output,
+		Dir:     filepath.Dir(input),
+		Args:    pandoc.ArgsFor(cfg.PandocArgs, *to),
+	}, func(line string) {
+		fmt.Fprintln(os.Stderr, "  "+line)
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(os.Stderr, "Wrote "+*output)
+	return nil
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ofri/mde/internal/config"
+	"github.com/ofri/mde/internal/journal"
+	"github.com/ofri/mde/internal/tui"
+)
+
+// runJournal implements `mde journal [--date YYYY-MM-DD] [text...]`.
+// Without text it opens the day's note (creating it if necessary) with the
+// cursor at the end. With text it appends a timestamped capture and exits.
+// Returns false when the editor should not be started.
+func runJournal(app *tui.Model, cfg *config.Config, args []string) (bool, error) {
+	fs := flag.NewFlagSet("journal", flag.ContinueOnError)
+	date := fs.String("date", "", "open the note for another day (YYYY-MM-DD)")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: mde journal [--date YYYY-MM-DD] [text to capture...]")
+		fs.PrintDefaults()
+	}
+
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	now := time.Now()
+	day := now
+	if *date != "" {
+		parsed, err := time.ParseInLocation(journal.DateLayout, *date, time.Local)
+		if err != nil {
+			return false, fmt.Errorf("invalid --date %q: expected YYYY-MM-DD", *date)
+		}
+		day = parsed
+	}
+
+	content := journal.DefaultContent(day)
+	if cfg.JournalTemplate != "" {
+		rendered, err := app.RenderTemplate(cfg.JournalTemplate, day.Format(journal.DateLayout))
+		if err != nil {
+			return false, err
+		}
+		content = rendered
+	}
+
+	path, _, err := journal.Ensure(cfg.JournalDir, day, content)
+	if err != nil {
+		return false, err
+	}
+
+	if text := strings.Join(fs.Args(), " "); text != "" {
+		if err := journal.Capture(path, now, text); err != nil {
+			return false, err
+		}
+		fmt.Println("Captured to " + path)
+		return false, nil
+	}
+
+	app.SetFilename(path)
+	app.GetEditor().MoveCursorToDocumentEnd()
+	return true, nil
+}
@@ -5,28 +5,110 @@ import (
 	"os"
 
 	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/ofri/mde/internal/config"
+	"github.com/ofri/mde/internal/control"
 	"github.com/ofri/mde/internal/plugins"
+	"github.com/ofri/mde/internal/theme"
 	"github.com/ofri/mde/internal/tui"
 )
 
 func main() {
+	if err := run(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// run starts the editor or a subcommand. Errors come back to main, so
+// deferred cleanup such as removing the control socket runs before exit.
+func run() error {
 	// Initialize plugins with defaults
-	err := plugins.InitializePlugins()
+	if err := plugins.InitializePlugins(); err != nil {
+		return fmt.Errorf("initializing plugins: %w", err)
+	}
+	
+	cfg, err := config.Load()
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error initializing plugins: %v\n", err)
-		os.Exit(1)
+		return fmt.Errorf("loading config: %w", err)
+	}
+	
+	if err := plugins.RegisterFileTypes(cfg.FileTypes); err != nil {
+		return fmt.Errorf("loading config: %w", err)
+	}
+	
+	if err := theme.LoadDir(cfg.ThemesDir); err != nil {
+		return fmt.Errorf("loading themes: %w", err)
 	}
 	
 	app := tui.New()
+	app.SetConfig(cfg)
+	var model tea.Model = tui.WithTerminalCursor(app)
+	
+	if len(os.Args) > 1 && isBatchFlag(os.Args[1]) {
+		defer shutdownPlugins()
+		return runBatch(os.Args[1:])
+	}
 	
 	if len(os.Args) > 1 {
-		app.SetFilename(os.Args[1])
+		// start is false when the subcommand is done without the editor
+		start := false
+		switch os.Args[1] {
+		case "new":
+			start, err = runNew(app, os.Args[2:])
+		case "journal":
+			start, err = runJournal(app, cfg, os.Args[2:])
+		case "export":
+			err = runExport(cfg, os.Args[2:])
+		case "serve":
+			start, err = runServe(app, cfg, os.Args[2:])
+		case "share":
+			start, err = runShare(app, cfg, os.Args[2:])
+		case "join":
+			start, err = runJoin(app, cfg, os.Args[2:])
+		case "assets":
+			err = runAssets(cfg, os.Args[2:])
+		case "export-site":
+			err = runExportSite(cfg, os.Args[2:])
+		case "theme":
+			err = runTheme(os.Args[2:])
+		case "diff":
+			var view *tui.DiffView
+			if view, err = runDiff(os.Args[2:]); view != nil {
+				model, start = view, true
+			}
+		default:
+			app.SetFilename(os.Args[1])
+			start = true
+		}
+		if err != nil || !start {
+			return err
+		}
 	}
 	
-	p := tea.NewProgram(app, tea.WithAltScreen(), tea.WithMouseCellMotion())
+	// Hover tooltips need motion events without a button held
+	mouse := tea.WithMouseCellMotion()
+	if cfg.MouseHover {
+		mouse = tea.WithMouseAllMotion()
+	}
+	p := tea.NewProgram(model, tea.WithAltScreen(), mouse)
 	
-	if _, err := p.Run(); err != nil {
-		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-		os.Exit(1)
+	if cfg.ControlSocket != "" {
+		server, err := control.Listen(cfg.ControlSocket, tui.ControlHandler(p.Send))
+		if err != nil {
+			return fmt.Errorf("starting control socket: %w", err)
+		}
+		defer server.Close()
+	}
+	
+	_, err = p.Run()
+	app.Shutdown()
+	shutdownPlugins()
+	return err
+}
+
+// shutdownPlugins stops the plugins, reporting but not failing on errors.
+func shutdownPlugins() {
+	if err := plugins.Shutdown(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error shutting down plugins: %v\n", err)
 	}
-}
\ No newline at end of file
+}
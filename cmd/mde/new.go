@@ -0,0 +1,56 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/ofri/mde/internal/templates"
+	"github.com/ofri/mde/internal/tui"
+)
+
+// runNew implements `mde new [--template name] [--title text] [file]`.
+// The scaffolded buffer is opened unsaved; nothing is written until Ctrl+S.
+// Returns false when the editor should not be started (e.g. --list).
+func runNew(app *tui.Model, args []string) (bool, error) {
+	fs := flag.NewFlagSet("new", flag.ContinueOnError)
+	templateName := fs.String("template", "note", "template to scaffold from")
+	title := fs.String("title", "", "document title (defaults to the file name)")
+	list := fs.Bool("list", false, "list available templates and exit")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: mde new [--template name] [--title text] [file]")
+		fs.PrintDefaults()
+	}
+
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	if *list {
+		fmt.Println(strings.Join(app.TemplateNames(), "\n"))
+		return false, nil
+	}
+
+	filename := fs.Arg(0)
+	if filename != "" {
+		if _, err := os.Stat(filename); err == nil {
+			return false, fmt.Errorf("%s already exists", filename)
+		}
+		if *title == "" {
+			*title = templates.TitleFromFilename(filename)
+		}
+	}
+
+	content, err := app.RenderTemplate(*templateName, *title)
+	if err != nil {
+		return false, err
+	}
+
+	app.NewBuffer(content, filename)
+	return true, nil
+}
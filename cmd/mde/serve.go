@@ -0,0 +1,46 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+
+	"github.com/ofri/mde/internal/browser"
+	"github.com/ofri/mde/internal/config"
+	"github.com/ofri/mde/internal/tui"
+)
+
+// runServe implements `mde serve [--addr host:port] [--no-browser]
+// file.md`: it opens the file in the editor with the live HTML preview
+// server running and the page open in the browser.
+func runServe(app *tui.Model, cfg *config.Config, args []string) (bool, error) {
+	fs := flag.NewFlagSet("serve", flag.ContinueOnError)
+	addr := fs.String("addr", cfg.PreviewAddress, "host:port to serve on (preview_address); port 0 picks one")
+	noBrowser := fs.Bool("no-browser", false, "only print the URL")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: mde serve [--addr host:port] [--no-browser] file.md")
+		fs.PrintDefaults()
+	}
+
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return false, nil
+		}
+		return false, err
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return false, errors.New("give one file to serve")
+	}
+
+	cfg.PreviewAddress = *addr
+	app.SetFilename(fs.Arg(0))
+	url, err := app.StartPreviewServer()
+	if err != nil {
+		return false, err
+	}
+	if !*noBrowser {
+		_ = browser.Open(url) // The status line shows the URL either way
+	}
+	return true, nil
+}
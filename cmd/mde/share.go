@@ -0,0 +1,67 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+
+	"github.com/ofri/mde/internal/config"
+	"github.com/ofri/mde/internal/tui"
+)
+
+// runShare implements `mde share [--addr address] [file.md]`: it opens
+// the file in the editor hosting a shared session that others join.
+func runShare(app *tui.Model, cfg *config.Config, args []string) (bool, error) {
+	fs := flag.NewFlagSet("share", flag.ContinueOnError)
+	addr := fs.String("addr", cfg.ShareAddress, "host:port or unix socket path to host on (share_address)")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: mde share [--addr address] [file.md]")
+		fs.PrintDefaults()
+	}
+
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return false, nil
+		}
+		return false, err
+	}
+	if fs.NArg() > 1 {
+		fs.Usage()
+		return false, errors.New("give at most one file to share")
+	}
+
+	cfg.ShareAddress = *addr
+	if fs.NArg() == 1 {
+		app.SetFilename(fs.Arg(0))
+	}
+	_, err := app.StartShare()
+	return err == nil, err
+}
+
+// runJoin implements `mde join [address]`: it opens the editor on the
+// buffer of the session hosted at address, share_address by default.
+func runJoin(app *tui.Model, cfg *config.Config, args []string) (bool, error) {
+	fs := flag.NewFlagSet("join", flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: mde join [address]")
+		fmt.Fprintf(fs.Output(), "The address defaults to share_address (%s).\n", cfg.ShareAddress)
+	}
+
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return false, nil
+		}
+		return false, err
+	}
+	if fs.NArg() > 1 {
+		fs.Usage()
+		return false, errors.New("give at most one address to join")
+	}
+
+	addr := cfg.ShareAddress
+	if fs.NArg() == 1 {
+		addr = fs.Arg(0)
+	}
+	err := app.JoinShare(addr)
+	return err == nil, err
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/ofri/mde/internal/config"
+	"github.com/ofri/mde/internal/site"
+)
+
+// runExportSite implements `mde export-site ./docs -o ./public`, turning a
+// tree of markdown files into a static HTML site with an index.
+func runExportSite(cfg *config.Config, args []string) error {
+	fs := flag.NewFlagSet("export-site", flag.ContinueOnError)
+	output := fs.String("o", "public", "directory to write the site to")
+	tmpl := fs.String("template", "", "html/template file each page is rendered with (default: built in)")
+	title := fs.String("title", "", "site title (default: the source directory's name)")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: mde export-site [-o output] [--template page.html] [--title title] directory")
+		fs.PrintDefaults()
+	}
+
+	// Flags may follow the directory, as in the usual `export-site ./docs -o ./public`
+	var dirs []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			if errors.Is(err, flag.ErrHelp) {
+				return nil
+			}
+			return err
+		}
+		if fs.NArg() == 0 {
+			break
+		}
+		dirs = append(dirs, fs.Arg(0))
+		args = fs.Args()[1:]
+	}
+	if len(dirs) != 1 {
+		fs.Usage()
+		return errors.New("give one directory of markdown files")
+	}
+
+	pages, err := site.Build(site.Options{
+		Source:      dirs[0],
+		Output:      *output,
+		Template:    *tmpl,
+		Title:       *title,
+		Typographer: cfg.Typographer,
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "Wrote %d pages to %s\n", len(pages), *output)
+	return nil
+}
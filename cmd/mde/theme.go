@@ -0,0 +1,45 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/ofri/mde/internal/theme"
+)
+
+// runTheme implements `mde theme [--json] [--list] [name]`, printing every
+// style of the named theme, or the configured one, in the format of a
+// user theme file so it can be saved and edited.
+func runTheme(args []string) error {
+	fs := flag.NewFlagSet("theme", flag.ContinueOnError)
+	asJSON := fs.Bool("json", false, "print JSON instead of TOML")
+	list := fs.Bool("list", false, "list available themes and exit")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: mde theme [--json] [--list] [name]")
+		fs.PrintDefaults()
+	}
+
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil
+		}
+		return err
+	}
+
+	if *list {
+		fmt.Println(strings.Join(theme.Names(), "\n"))
+		return nil
+	}
+
+	t := theme.Active()
+	if name := fs.Arg(0); name != "" {
+		var ok bool
+		if t, ok = theme.Get(name); !ok {
+			return fmt.Errorf("unknown theme %q", name)
+		}
+	}
+	return t.Encode(os.Stdout, *asJSON)
+}
@@ -0,0 +1,198 @@
+// Package abbrev expands abbreviations as they are typed, turning "->"
+// into "→" or a user's "btw" into "by the way".
+//
+// Two kinds of abbreviation are recognized:
+//
+//	->   symbol abbreviations (ending in punctuation) expand as soon as
+//	     their last character is typed
+//	btw  word abbreviations (ending in a letter or digit) expand when the
+//	     word is finished by a space or punctuation, and only as a whole
+//	     word, so "subtwo" is left alone
+//
+// A backslash before an abbreviation keeps it literal (\-> stays "\->",
+// which markdown renders as "->"), and nothing expands inside a
+// `code span`. Columns are rune offsets, matching the editor's cursor.
+package abbrev
+
+import (
+	"strings"
+	"unicode"
+)
+
+// builtin holds the substitutions available out of the box.
+var builtin = map[string]string{
+	"->":   "→",
+	"<-":   "←",
+	"=>":   "⇒",
+	"<=>":  "⇔",
+	"(c)":  "©",
+	"(r)":  "®",
+	"(tm)": "™",
+	"+-":   "±",
+	"...":  "…",
+}
+
+// Builtin returns a copy of the built-in substitution table.
+func Builtin() map[string]string {
+	table := make(map[string]string, len(builtin))
+	for abbrev, expansion := range builtin {
+		table[abbrev] = expansion
+	}
+	return table
+}
+
+// Match is an abbreviation found before the cursor.
+// Start and End are rune columns of the abbreviation within the line.
+type Match struct {
+	Start     int
+	End       int
+	Abbrev    string
+	Expansion string
+}
+
+// Expander looks up abbreviations in a substitution table.
+type Expander struct {
+	table map[string]string
+}
+
+// New returns an Expander for the built-in table (when builtins is set)
+// overlaid with user entries. A user entry with an empty expansion
+// removes a built-in one.
+func New(user map[string]string, builtins bool) *Expander {
+	table := make(map[string]string)
+	if builtins {
+		table = Builtin()
+	}
+	for abbrev, expansion := range user {
+		if expansion == "" {
+			delete(table, abbrev)
+			continue
+		}
+		if abbrev != "" {
+			table[abbrev] = expansion
+		}
+	}
+	return &Expander{table: table}
+}
+
+// Empty reports whether there is nothing to expand.
+func (e *Expander) Empty() bool {
+	return e == nil || len(e.table) == 0
+}
+
+// Find returns the abbreviation completed by the character just typed,
+// which sits before rune column col of line. Symbol abbreviations end at
+// col; word abbreviations end just before the delimiter at col-1.
+func (e *Expander) Find(line string, col int) (Match, bool) {
+	if e.Empty() {
+		return Match{}, false
+	}
+	runes := []rune(line)
+	if col <= 0 || col > len(runes) {
+		return Match{}, false
+	}
+
+	match, ok := e.longest(runes, col, false)
+	if ok && match.Start > 0 && runes[match.Start-1] == []rune(match.Abbrev)[0] {
+		// "-->" closes an HTML comment rather than drawing an arrow
+		ok = false
+	}
+	if !ok && !isWordRune(runes[col-1]) {
+		return e.FindWord(string(runes[:col-1]), col-1)
+	}
+	if !ok || escaped(runes, match.Start) || inCodeSpan(runes, match.Start) {
+		return Match{}, false
+	}
+	return match, true
+}
+
+// FindWord returns the word abbreviation ending exactly at rune column
+// col, for when the word is finished by something other than a typed
+// character, such as a line break.
+func (e *Expander) FindWord(line string, col int) (Match, bool) {
+	if e.Empty() {
+		return Match{}, false
+	}
+	runes := []rune(line)
+	if col <= 0 || col > len(runes) {
+		return Match{}, false
+	}
+	if col < len(runes) && isWordRune(runes[col]) {
+		return Match{}, false
+	}
+
+	match, ok := e.longest(runes, col, true)
+	if !ok || escaped(runes, match.Start) || inCodeSpan(runes, match.Start) {
+		return Match{}, false
+	}
+	return match, true
+}
+
+// longest finds the longest abbreviation of the requested kind ending at
+// rune column end.
+func (e *Expander) longest(runes []rune, end int, word bool) (Match, bool) {
+	var best Match
+	found := false
+	before := string(runes[:end])
+	for abbrev, expansion := range e.table {
+		if isWordAbbrev(abbrev) != word || !strings.HasSuffix(before, abbrev) {
+			continue
+		}
+		start := end - len([]rune(abbrev))
+		if word && start > 0 && isWordRune(runes[start-1]) {
+			continue
+		}
+		if !found || end-start > best.End-best.Start {
+			best = Match{Start: start, End: end, Abbrev: abbrev, Expansion: expansion}
+			found = true
+		}
+	}
+	return best, found
+}
+
+// isWordAbbrev reports whether abbrev waits for the end of a word.
+func isWordAbbrev(abbrev string) bool {
+	runes := []rune(abbrev)
+	return isWordRune(runes[len(runes)-1])
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_'
+}
+
+// escaped reports whether the rune at start follows an unescaped backslash.
+func escaped(runes []rune, start int) bool {
+	n := 0
+	for i := start - 1; i >= 0 && runes[i] == '\\'; i-- {
+		n++
+	}
+	return n%2 == 1
+}
+
+// inCodeSpan reports whether rune column col lies inside a code span,
+// counting a span still being typed (no closing backticks yet).
+func inCodeSpan(runes []rune, col int) bool {
+	open := 0
+	for i := 0; i < col; {
+		if runes[i] == '\\' && open == 0 {
+			i += 2
+			continue
+		}
+		if runes[i] != '`' {
+			i++
+			continue
+		}
+		run := 0
+		for i < len(runes) && runes[i] == '`' {
+			run++
+			i++
+		}
+		switch {
+		case open == 0:
+			open = run
+		case run == open:
+			open = 0
+		}
+	}
+	return open > 0
+}
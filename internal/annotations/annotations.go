@@ -0,0 +1,185 @@
+// Package annotations keeps review comments on a document in a sidecar
+// file beside it, so a document can be commented on without changing its
+// text.
+//
+// The comments on notes.md are kept in notes.md.comments.json. Each
+// comment quotes the text it is anchored to as well as where that text
+// was, so it is found again after the document is edited, in mde or
+// elsewhere. A comment whose text is gone stays at its old line.
+package annotations
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/ofri/mde/pkg/ast"
+)
+
+// Suffix is appended to a document's path to name its sidecar file.
+const Suffix = ".comments.json"
+
+// Comment is a review note on a range of a document.
+type Comment struct {
+	Line    int       `json:"line"`   // Where the quote starts, from 1
+	Col     int       `json:"column"` // In characters, from 1
+	Quote   string    `json:"quote"`  // The text commented on
+	Text    string    `json:"text"`
+	Author  string    `json:"author,omitempty"`
+	Created time.Time `json:"created"`
+}
+
+// sidecar is the layout of the sidecar file.
+type sidecar struct {
+	Comments []Comment `json:"comments"`
+}
+
+// Path returns the sidecar file of the document at path.
+func Path(path string) string {
+	return path + Suffix
+}
+
+// Load reads the comments on the document at path; a document without a
+// sidecar file has none.
+func Load(path string) ([]Comment, error) {
+	data, err := os.ReadFile(Path(path))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var file sidecar
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("%s: %w", Path(path), err)
+	}
+	return file.Comments, nil
+}
+
+// Save writes comments to the sidecar file of the document at path,
+// removing the file once no comments are left.
+func Save(path string, comments []Comment) error {
+	if len(comments) == 0 {
+		if err := os.Remove(Path(path)); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+		return nil
+	}
+	data, err := json.MarshalIndent(sidecar{Comments: comments}, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(Path(path), append(data, '\n'), 0o644)
+}
+
+// New returns a comment saying text about r in doc.
+func New(doc *ast.Document, r ast.Range, text, author string, created time.Time) Comment {
+	return Comment{
+		Line:    r.Start.Line + 1,
+		Col:     r.Start.Col + 1,
+		Quote:   doc.GetSelectionText(&ast.Selection{Start: r.Start, End: r.End}),
+		Text:    text,
+		Author:  author,
+		Created: created,
+	}
+}
+
+// Locate returns the range of doc c is anchored to: its quote where it
+// was, or else the occurrence of it nearest there. When the quote is not
+// found, it returns an empty range at the start of the line c was on,
+// and false.
+func (c Comment) Locate(doc *ast.Document) (ast.Range, bool) {
+	want := doc.ValidatePosition(ast.BufferPos{Line: c.Line - 1, Col: c.Col - 1})
+	quote := strings.Split(c.Quote, "\n")
+	if quotedAt(doc, want, quote) {
+		return quoteRange(want, quote), true
+	}
+
+	count := doc.LineCount()
+	for distance := 0; distance < count; distance++ {
+		lines := []int{want.Line - distance}
+		if distance > 0 {
+			lines = append(lines, want.Line+distance)
+		}
+		for _, line := range lines {
+			if line < 0 || line >= count {
+				continue
+			}
+			if start, ok := nearestQuote(doc, line, want.Col, quote); ok {
+				return quoteRange(start, quote), true
+			}
+		}
+		if want.Line-distance < 0 && want.Line+distance >= count {
+			break
+		}
+	}
+	return ast.Range{Start: ast.BufferPos{Line: want.Line}, End: ast.BufferPos{Line: want.Line}}, false
+}
+
+// MoveTo anchors c at the start of r, where its quote was found.
+func (c *Comment) MoveTo(r ast.Range) {
+	c.Line, c.Col = r.Start.Line+1, r.Start.Col+1
+}
+
+// nearestQuote returns where on line the quote starts nearest rune column
+// col.
+func nearestQuote(doc *ast.Document, line, col int, quote []string) (ast.BufferPos, bool) {
+	text := doc.GetLine(line)
+	found := false
+	var best ast.BufferPos
+	for offset := 0; offset <= len(text); {
+		i := strings.Index(text[offset:], quote[0])
+		if i < 0 {
+			break
+		}
+		start := ast.BufferPos{Line: line, Col: ast.RuneOffset(text, offset+i)}
+		if quotedAt(doc, start, quote) && (!found || abs(start.Col-col) < abs(best.Col-col)) {
+			best, found = start, true
+		}
+		_, size := utf8.DecodeRuneInString(text[offset+i:])
+		offset += i + max(size, 1)
+	}
+	return best, found
+}
+
+// quotedAt reports whether the quote's lines start at pos in doc.
+func quotedAt(doc *ast.Document, pos ast.BufferPos, quote []string) bool {
+	if pos.Line+len(quote) > doc.LineCount() {
+		return false
+	}
+	text := doc.GetLine(pos.Line)
+	rest := text[ast.ByteOffset(text, pos.Col):]
+	if len(quote) == 1 {
+		return strings.HasPrefix(rest, quote[0])
+	}
+	if rest != quote[0] {
+		return false
+	}
+	for i, line := range quote[1 : len(quote)-1] {
+		if doc.GetLine(pos.Line+1+i) != line {
+			return false
+		}
+	}
+	return strings.HasPrefix(doc.GetLine(pos.Line+len(quote)-1), quote[len(quote)-1])
+}
+
+// quoteRange returns the range the quote's lines cover from start.
+func quoteRange(start ast.BufferPos, quote []string) ast.Range {
+	last := quote[len(quote)-1]
+	end := ast.BufferPos{Line: start.Line + len(quote) - 1, Col: utf8.RuneCountInString(last)}
+	if len(quote) == 1 {
+		end.Col += start.Col
+	}
+	return ast.Range{Start: start, End: end}
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
@@ -0,0 +1,213 @@
+// Package assets finds the files in an assets directory, such as pasted
+// images, that no markdown file of a workspace refers to any longer, and
+// deletes or moves them away.
+//
+// A file counts as used when any link points at it: an image, a plain
+// link, a reference definition, or an HTML src or href attribute. Links
+// in every markdown file under the workspace root are considered, so an
+// image shared between notes is kept.
+package assets
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// maxWalkFiles bounds the files looked at in a workspace.
+const maxWalkFiles = 20000
+
+var (
+	inlineRe     = regexp.MustCompile(`!?\[[^\]]*\]\(\s*(<[^>\n]*>|[^)\s]+)`)
+	definitionRe = regexp.MustCompile(`(?m)^ {0,3}\[[^\]^][^\]]*\]:\s*(<[^>\n]*>|\S+)`)
+	attributeRe  = regexp.MustCompile(`(?i)\b(?:src|href|srcset)\s*=\s*(?:"([^"]*)"|'([^']*)')`)
+)
+
+// Report is the outcome of a scan.
+type Report struct {
+	Root   string   // Workspace whose markdown files were read
+	Dir    string   // Assets directory scanned
+	Files  int      // Markdown files read
+	Assets int      // Files in Dir
+	Unused []string // Files in Dir nothing links to, sorted
+	Size   int64    // Total size of Unused in bytes
+}
+
+// References returns the destinations of every link and image in
+// markdown, as written.
+func References(markdown string) []string {
+	var refs []string
+	for _, re := range []*regexp.Regexp{inlineRe, definitionRe} {
+		for _, m := range re.FindAllStringSubmatch(markdown, -1) {
+			refs = append(refs, strings.TrimSuffix(strings.TrimPrefix(m[1], "<"), ">"))
+		}
+	}
+	for _, m := range attributeRe.FindAllStringSubmatch(markdown, -1) {
+		value := m[1] + m[2]
+		// srcset lists "url width" candidates separated by commas
+		for _, candidate := range strings.Split(value, ",") {
+			if fields := strings.Fields(candidate); len(fields) > 0 {
+				refs = append(refs, fields[0])
+			}
+		}
+	}
+	return refs
+}
+
+// Scan reads every markdown file under root, skipping hidden directories,
+// and reports the files under dir that none of them links to, leaving
+// out hidden and markdown files. Buffers maps absolute paths to text read
+// in place of the file's, such as unsaved edits.
+func Scan(root, dir string, buffers map[string]string) (Report, error) {
+	root, err := filepath.Abs(root)
+	if err != nil {
+		return Report{}, err
+	}
+	dir, err = filepath.Abs(dir)
+	if err != nil {
+		return Report{}, err
+	}
+	report := Report{Root: root, Dir: dir}
+
+	used := map[string]bool{}
+	count := 0
+	err = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil // Unreadable parts of the workspace hold no links we can see
+		}
+		if d.IsDir() {
+			if path != root && strings.HasPrefix(d.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if count++; count > maxWalkFiles {
+			return fmt.Errorf("more than %d files under %s", maxWalkFiles, root)
+		}
+		if !isMarkdown(path) {
+			return nil
+		}
+		text, ok := buffers[path]
+		if !ok {
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			text = string(data)
+		}
+		report.Files++
+		for _, ref := range References(text) {
+			if target, ok := resolve(filepath.Dir(path), ref); ok {
+				used[target] = true
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return report, err
+	}
+
+	err = filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if path == dir && errors.Is(err, fs.ErrNotExist) {
+				return filepath.SkipAll // No assets yet
+			}
+			return err
+		}
+		if path != dir && strings.HasPrefix(d.Name(), ".") {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() || isMarkdown(path) {
+			return nil // Notes kept among the assets are not assets
+		}
+		report.Assets++
+		if !used[path] {
+			report.Unused = append(report.Unused, path)
+			if info, err := d.Info(); err == nil {
+				report.Size += info.Size()
+			}
+		}
+		return nil
+	})
+	sort.Strings(report.Unused)
+	return report, err
+}
+
+// resolve returns the file a link destination points at from a file in
+// dir, for destinations naming a local file.
+func resolve(dir, ref string) (string, bool) {
+	u, err := url.Parse(strings.TrimSpace(ref))
+	if err != nil || u.Host != "" || u.Path == "" {
+		return "", false
+	}
+	if u.Scheme != "" && u.Scheme != "file" {
+		return "", false
+	}
+	path := filepath.FromSlash(u.Path)
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(dir, path)
+	}
+	return filepath.Clean(path), true
+}
+
+// Delete removes the files, stopping at the first failure.
+func Delete(paths []string) error {
+	for _, path := range paths {
+		if err := os.Remove(path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Move moves the files from under dir to the same places under to,
+// keeping any file already there, and returns where they went.
+func Move(paths []string, dir, to string) ([]string, error) {
+	var moved []string
+	for _, path := range paths {
+		rel, err := filepath.Rel(dir, path)
+		if err != nil || strings.HasPrefix(rel, "..") {
+			rel = filepath.Base(path)
+		}
+		target := filepath.Join(to, rel)
+		if _, err := os.Lstat(target); err == nil {
+			return moved, fmt.Errorf("%s already exists", target)
+		}
+		if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+			return moved, err
+		}
+		if err := os.Rename(path, target); err != nil {
+			return moved, err
+		}
+		moved = append(moved, target)
+	}
+	return moved, nil
+}
+
+// FormatSize renders a byte count for reports, as "1.2 MB".
+func FormatSize(size int64) string {
+	switch {
+	case size >= 1<<20:
+		return fmt.Sprintf("%.1f MB", float64(size)/(1<<20))
+	case size >= 1<<10:
+		return fmt.Sprintf("%.1f KB", float64(size)/(1<<10))
+	}
+	return fmt.Sprintf("%d B", size)
+}
+
+func isMarkdown(path string) bool {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".md", ".markdown":
+		return true
+	}
+	return false
+}
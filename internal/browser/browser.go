@@ -0,0 +1,61 @@
+// Package browser opens URLs with the platform's default handler:
+//
+//	Linux/BSD: xdg-open (or $BROWSER when set)
+//	macOS:     open
+//	Windows:   rundll32 url.dll,FileProtocolHandler
+//
+// When no handler can be found ErrUnavailable is returned.
+package browser
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+)
+
+// ErrUnavailable is returned when no URL handler exists on this system.
+var ErrUnavailable = errors.New("no browser or URL handler available")
+
+// Open starts the default handler for url without waiting for it to exit.
+func Open(url string) error {
+	name, args, err := command(url)
+	if err != nil {
+		return err
+	}
+
+	cmd := exec.Command(name, args...)
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to open %s: %w", url, err)
+	}
+	// Reap the handler in the background; its exit status is irrelevant
+	go func() { _ = cmd.Wait() }()
+	return nil
+}
+
+// command returns the handler invocation for url on this platform.
+func command(url string) (string, []string, error) {
+	if browser := os.Getenv("BROWSER"); browser != "" {
+		return browser, []string{url}, nil
+	}
+
+	var name string
+	var args []string
+	switch runtime.GOOS {
+	case "darwin":
+		name, args = "open", []string{url}
+	case "windows":
+		name, args = "rundll32", []string{"url.dll,FileProtocolHandler", url}
+	case "linux", "freebsd", "openbsd", "netbsd":
+		name, args = "xdg-open", []string{url}
+	default:
+		return "", nil, ErrUnavailable
+	}
+
+	path, err := exec.LookPath(name)
+	if err != nil {
+		return "", nil, ErrUnavailable
+	}
+	return path, args, nil
+}
@@ -0,0 +1,341 @@
+package cite
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// Entry is a reference in a bibliography, reduced to what a preview
+// shows.
+type Entry struct {
+	Key       string
+	Type      string // "article", "book", ...
+	Title     string
+	Authors   []string // Family names, or whole names where not split
+	Year      string
+	Container string // Journal, book or publisher it appeared in
+}
+
+// Summary describes the entry on one line, as
+// "Smith and Jones (2020). Title. Journal".
+func (e Entry) Summary() string {
+	var parts []string
+	byline := ""
+	switch len(e.Authors) {
+	case 0:
+	case 1:
+		byline = e.Authors[0]
+	case 2:
+		byline = e.Authors[0] + " and " + e.Authors[1]
+	default:
+		byline = e.Authors[0] + " et al."
+	}
+	if e.Year != "" {
+		byline = strings.TrimSpace(byline + " (" + e.Year + ")")
+	}
+	for _, part := range []string{byline, e.Title, e.Container} {
+		if part != "" {
+			parts = append(parts, strings.TrimSuffix(part, "."))
+		}
+	}
+	if len(parts) == 0 {
+		return e.Key
+	}
+	return strings.Join(parts, ". ")
+}
+
+// Load reads the bibliography at path: CSL-JSON when it ends in .json,
+// BibTeX otherwise. Entries are in file order.
+func Load(path string) ([]Entry, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		entries, err := ParseCSLJSON(data)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		return entries, nil
+	}
+	return ParseBibTeX(string(data)), nil
+}
+
+// cslEntry is the part of a CSL-JSON item an Entry holds.
+type cslEntry struct {
+	ID             any    `json:"id"`
+	Type           string `json:"type"`
+	Title          string `json:"title"`
+	ContainerTitle string `json:"container-title"`
+	Publisher      string `json:"publisher"`
+	Author         []struct {
+		Family  string `json:"family"`
+		Given   string `json:"given"`
+		Literal string `json:"literal"`
+	} `json:"author"`
+	Issued struct {
+		DateParts [][]any `json:"date-parts"`
+		Literal   string  `json:"literal"`
+	} `json:"issued"`
+}
+
+// ParseCSLJSON parses a CSL-JSON array of items.
+func ParseCSLJSON(data []byte) ([]Entry, error) {
+	var items []cslEntry
+	if err := json.Unmarshal(data, &items); err != nil {
+		return nil, err
+	}
+	entries := make([]Entry, 0, len(items))
+	for _, item := range items {
+		entry := Entry{
+			Key:       strings.TrimSpace(fmt.Sprint(item.ID)),
+			Type:      item.Type,
+			Title:     item.Title,
+			Container: item.ContainerTitle,
+			Year:      item.Issued.Literal,
+		}
+		if entry.Key == "" || item.ID == nil {
+			continue
+		}
+		if entry.Container == "" {
+			entry.Container = item.Publisher
+		}
+		if len(item.Issued.DateParts) > 0 && len(item.Issued.DateParts[0]) > 0 {
+			entry.Year = fmt.Sprint(item.Issued.DateParts[0][0])
+		}
+		for _, author := range item.Author {
+			switch {
+			case author.Family != "":
+				entry.Authors = append(entry.Authors, author.Family)
+			case author.Literal != "":
+				entry.Authors = append(entry.Authors, author.Literal)
+			}
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// bibEntryRe matches the head of a BibTeX entry, "@article{key,".
+var bibEntryRe = regexp.MustCompile(`@(\w+)\s*[{(]`)
+
+// ParseBibTeX parses the entries of a BibTeX file. @string, @preamble and
+// @comment blocks, and entries it cannot make sense of, are skipped.
+func ParseBibTeX(text string) []Entry {
+	var entries []Entry
+	for offset := 0; offset < len(text); {
+		m := bibEntryRe.FindStringSubmatchIndex(text[offset:])
+		if m == nil {
+			break
+		}
+		kind := strings.ToLower(text[offset+m[2] : offset+m[3]])
+		body, next := balanced(text, offset+m[1]-1)
+		offset = next
+		switch kind {
+		case "string", "preamble", "comment":
+			continue
+		}
+		if entry, ok := parseBibEntry(kind, body); ok {
+			entries = append(entries, entry)
+		}
+	}
+	return entries
+}
+
+// balanced returns the text inside the bracket at text[open], up to the
+// one closing it, and the offset after that.
+func balanced(text string, open int) (string, int) {
+	closer := byte('}')
+	if text[open] == '(' {
+		closer = ')'
+	}
+	depth := 0
+	for i := open + 1; i < len(text); i++ {
+		switch text[i] {
+		case '{':
+			depth++
+		case '}':
+			if depth == 0 && closer == '}' {
+				return text[open+1 : i], i + 1
+			}
+			depth--
+		case ')':
+			if depth == 0 && closer == ')' {
+				return text[open+1 : i], i + 1
+			}
+		}
+	}
+	return text[open+1:], len(text)
+}
+
+// parseBibEntry reads "key, field = value, ..." of an entry of kind.
+func parseBibEntry(kind, body string) (Entry, bool) {
+	comma := strings.IndexByte(body, ',')
+	if comma < 0 {
+		return Entry{}, false
+	}
+	entry := Entry{Key: strings.TrimSpace(body[:comma]), Type: kind}
+	if entry.Key == "" {
+		return Entry{}, false
+	}
+
+	fields := map[string]string{}
+	rest := body[comma+1:]
+	for {
+		eq := strings.IndexByte(rest, '=')
+		if eq < 0 {
+			break
+		}
+		name := strings.ToLower(strings.TrimSpace(strings.Trim(rest[:eq], ", \t\r\n")))
+		value, next := bibValue(rest[eq+1:])
+		fields[name] = value
+		rest = next
+	}
+
+	entry.Title = cleanTeX(fields["title"])
+	entry.Year = cleanTeX(fields["year"])
+	if date := cleanTeX(fields["date"]); entry.Year == "" && len(date) >= 4 {
+		entry.Year = date[:4]
+	}
+	for _, name := range []string{"journal", "journaltitle", "booktitle", "publisher", "school", "institution"} {
+		if fields[name] != "" {
+			entry.Container = cleanTeX(fields[name])
+			break
+		}
+	}
+	names := fields["author"]
+	if names == "" {
+		names = fields["editor"]
+	}
+	for _, name := range splitNames(names) {
+		if family := familyName(name); family != "" {
+			entry.Authors = append(entry.Authors, family)
+		}
+	}
+	return entry, true
+}
+
+// splitNames splits a BibTeX name list at the "and"s outside braces.
+func splitNames(names string) []string {
+	var split []string
+	depth, start := 0, 0
+	for i := 0; i < len(names); i++ {
+		switch names[i] {
+		case '{':
+			depth++
+		case '}':
+			depth--
+		case ' ', '\t', '\n', '\r':
+			rest := strings.TrimLeft(names[i:], " \t\r\n")
+			if depth == 0 && strings.HasPrefix(rest, "and") && len(rest) > 3 && unicode.IsSpace(rune(rest[3])) {
+				split = append(split, names[start:i])
+				i = len(names) - len(rest) + 3
+				start = i
+			}
+		}
+	}
+	if strings.TrimSpace(names[start:]) != "" {
+		split = append(split, names[start:])
+	}
+	return split
+}
+
+// bibValue reads a field value at the start of text: {braced}, "quoted"
+// or a bare word, joined with # concatenation. Returns it and the text
+// after it.
+func bibValue(text string) (string, string) {
+	var value strings.Builder
+	for {
+		text = strings.TrimLeft(text, " \t\r\n")
+		if text == "" {
+			return value.String(), ""
+		}
+		switch text[0] {
+		case '{':
+			inner, next := balanced(text, 0)
+			value.WriteString(inner)
+			text = text[next:]
+		case '"':
+			end := 1
+			for depth := 0; end < len(text); end++ {
+				if text[end] == '{' {
+					depth++
+				} else if text[end] == '}' {
+					depth--
+				} else if text[end] == '"' && depth == 0 {
+					break
+				}
+			}
+			value.WriteString(text[1:min(end, len(text))])
+			text = text[min(end+1, len(text)):]
+		default:
+			end := strings.IndexAny(text, ",#")
+			if end < 0 {
+				end = len(text)
+			}
+			value.WriteString(strings.TrimSpace(text[:end]))
+			text = text[end:]
+		}
+		text = strings.TrimLeft(text, " \t\r\n")
+		if !strings.HasPrefix(text, "#") {
+			return value.String(), text
+		}
+		text = text[1:]
+	}
+}
+
+// texAccentRe matches accent commands such as \"o and \'{e}, whose letter
+// is kept.
+var texAccentRe = regexp.MustCompile(`\\[` + "`" + `'^"~=.uvHc]\{?(\w)\}?`)
+
+// cleanTeX reduces a BibTeX value to plain text: braces, accents and
+// escapes dropped, whitespace collapsed.
+func cleanTeX(value string) string {
+	value = texAccentRe.ReplaceAllString(value, "$1")
+	value = strings.NewReplacer(`\&`, "&", `\%`, "%", `\$`, "$", `\_`, "_", `\#`, "#", "---", "—", "--", "–", "~", " ").Replace(value)
+	value = strings.Map(func(r rune) rune {
+		if r == '{' || r == '}' || r == '\\' {
+			return -1
+		}
+		return r
+	}, value)
+	return strings.Join(strings.Fields(value), " ")
+}
+
+// familyName picks the family name out of a BibTeX name, written
+// "Family, Given" or "Given Family"; "{World Health Organization}" was
+// braced to stay whole and is kept as is.
+func familyName(name string) string {
+	name = strings.TrimSpace(name)
+	if strings.HasPrefix(name, "{") {
+		if inner, next := balanced(name, 0); next == len(name) {
+			return cleanTeX(inner)
+		}
+	}
+	name = cleanTeX(name)
+	if family, _, ok := strings.Cut(name, ","); ok {
+		return strings.TrimSpace(family)
+	}
+	fields := strings.Fields(name)
+	if len(fields) == 0 {
+		return ""
+	}
+	// Lowercase particles belong to the family name: "Ludwig van Beethoven"
+	start := len(fields) - 1
+	for start > 1 && startsLower(fields[start-1]) {
+		start--
+	}
+	return strings.Join(fields[start:], " ")
+}
+
+func startsLower(word string) bool {
+	for _, r := range word {
+		return unicode.IsLower(r)
+	}
+	return false
+}
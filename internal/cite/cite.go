@@ -0,0 +1,126 @@
+// Package cite implements pandoc-style citations and the bibliographies
+// they refer to.
+//
+// A citation is a bracketed list of keys, each with an optional prefix
+// and locator, separated by semicolons:
+//
+//	[@smith2020]
+//	[see @smith2020, p. 33; -@jones1999]
+//
+// Keys name entries in a BibTeX or CSL-JSON bibliography; see Load.
+package cite
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/ofri/mde/pkg/ast"
+)
+
+// citationRe matches a bracketed span holding an @, not followed by the
+// "(" or "[" that would make it a link.
+var citationRe = regexp.MustCompile(`\[[^\[\]]*@[^\[\]]*\](?:[^(\[]|$)`)
+
+// keyRe matches a key after its @: letters, digits and underscores, with
+// internal punctuation as in "doe:2020.a".
+var keyRe = regexp.MustCompile(`^\w(?:[\w:.#$%&+?<>~/-]*\w)?`)
+
+// Citation is a bracketed citation within a line. Start and End are byte
+// offsets including the brackets.
+type Citation struct {
+	Start int
+	End   int
+	Keys  []Key
+}
+
+// Key is one cited key. Start and End are byte offsets of "@key".
+type Key struct {
+	Start int
+	End   int
+	Name  string
+}
+
+// FindCitations returns the citations in line.
+func FindCitations(line string) []Citation {
+	var citations []Citation
+	for _, m := range citationRe.FindAllStringIndex(line, -1) {
+		end := strings.IndexByte(line[m[0]:m[1]], ']') + m[0] + 1
+		keys := findKeys(line, m[0]+1, end-1)
+		if len(keys) > 0 {
+			citations = append(citations, Citation{Start: m[0], End: end, Keys: keys})
+		}
+	}
+	return citations
+}
+
+// findKeys returns the keys in line[start:end], the inside of a citation.
+func findKeys(line string, start, end int) []Key {
+	var keys []Key
+	for i := start; i < end; i++ {
+		if line[i] != '@' || !keyBoundary(line, start, i) {
+			continue
+		}
+		if m := keyRe.FindString(line[i+1 : end]); m != "" {
+			keys = append(keys, Key{Start: i, End: i + 1 + len(m), Name: m})
+			i += len(m)
+		}
+	}
+	return keys
+}
+
+// keyBoundary reports whether the @ at i may start a key: it opens the
+// citation or follows a space, a semicolon or the "-" suppressing the
+// author, so "a@b.com" is not one.
+func keyBoundary(line string, start, i int) bool {
+	if i == start {
+		return true
+	}
+	switch line[i-1] {
+	case ' ', '\t', ';', '-':
+		return true
+	}
+	return false
+}
+
+// KeyAt returns the key under rune column col, if any.
+func KeyAt(line string, col int) (Key, bool) {
+	offset := ast.ByteOffset(line, col)
+	for _, citation := range FindCitations(line) {
+		for _, key := range citation.Keys {
+			if offset >= key.Start && offset < key.End {
+				return key, true
+			}
+		}
+	}
+	return Key{}, false
+}
+
+// Partial is a key being typed inside an open citation.
+type Partial struct {
+	Key    string // Typed so far, after the @
+	Closed bool   // A "]" closes the citation after the cursor
+}
+
+// PartialAt reports whether rune column col sits right after "@" and a
+// partially typed key inside brackets on line, as in "[see @smi".
+func PartialAt(line string, col int) (Partial, bool) {
+	offset := ast.ByteOffset(line, col)
+	before := line[:offset]
+	open := strings.LastIndexByte(before, '[')
+	if open < 0 || strings.IndexByte(before[open:], ']') >= 0 {
+		return Partial{}, false
+	}
+	at := strings.LastIndexByte(before, '@')
+	if at <= open || !keyBoundary(line, open+1, at) {
+		return Partial{}, false
+	}
+	key := before[at+1:]
+	if key != "" && keyRe.FindString(key) != key && keyRe.FindString(key+"a") != key+"a" {
+		return Partial{}, false // Not a key, trailing punctuation aside
+	}
+
+	after := line[offset:]
+	closeAt := strings.IndexByte(after, ']')
+	reopen := strings.IndexByte(after, '[')
+	return Partial{Key: key, Closed: closeAt >= 0 && (reopen < 0 || closeAt < reopen)}, true
+}
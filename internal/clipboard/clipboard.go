@@ -0,0 +1,84 @@
+// Package clipboard writes rich content to the system clipboard.
+//
+// Plain text can always be delivered through the terminal via OSC 52
+// (see tea.SetClipboard), but OSC 52 has no notion of MIME types. Rich
+// formats such as text/html therefore need platform tooling:
+//
+//	Linux (Wayland): wl-copy --type text/html
+//	Linux (X11):     xclip -selection clipboard -t text/html
+//	macOS:           osascript with an «data HTML» literal
+//
+// When none of these tools is available ErrUnavailable is returned and
+// the caller is expected to fall back to OSC 52.
+package clipboard
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+)
+
+// ErrUnavailable is returned when no clipboard tool supporting the
+// requested MIME type could be found on this system.
+var ErrUnavailable = errors.New("no rich clipboard tool available")
+
+// WriteHTML places html on the system clipboard with the text/html MIME type.
+func WriteHTML(html string) error {
+	switch runtime.GOOS {
+	case "darwin":
+		return writeDarwinHTML(html)
+	case "linux", "freebsd", "openbsd", "netbsd":
+		return writeUnixHTML(html)
+	}
+	return ErrUnavailable
+}
+
+// writeUnixHTML prefers wl-copy on Wayland sessions and xclip on X11.
+func writeUnixHTML(html string) error {
+	if os.Getenv("WAYLAND_DISPLAY") != "" {
+		if path, err := exec.LookPath("wl-copy"); err == nil {
+			return run(path, html, "--type", "text/html")
+		}
+	}
+
+	if os.Getenv("DISPLAY") != "" {
+		if path, err := exec.LookPath("xclip"); err == nil {
+			return run(path, html, "-selection", "clipboard", "-t", "text/html")
+		}
+	}
+
+	return ErrUnavailable
+}
+
+// writeDarwinHTML uses AppleScript because pbcopy only handles plain text.
+func writeDarwinHTML(html string) error {
+	path, err := exec.LookPath("osascript")
+	if err != nil {
+		return ErrUnavailable
+	}
+
+	script := fmt.Sprintf("set the clipboard to «data HTML%s»", hex.EncodeToString([]byte(html)))
+	return run(path, "", "-e", script)
+}
+
+// run executes a clipboard tool, feeding stdin and surfacing stderr on failure.
+func run(path, stdin string, args ...string) error {
+	cmd := exec.Command(path, args...)
+	cmd.Stdin = bytes.NewBufferString(stdin)
+
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		if msg := bytes.TrimSpace(stderr.Bytes()); len(msg) > 0 {
+			return fmt.Errorf("%s: %w: %s", path, err, msg)
+		}
+		return fmt.Errorf("%s: %w", path, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,140 @@
+package clipboard
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// ErrNoImage is returned by ReadImage when the clipboard holds no image.
+var ErrNoImage = errors.New("no image on the clipboard")
+
+// imageTypes are the MIME types ReadImage takes, in order of preference,
+// with the extension the image is saved with.
+var imageTypes = []struct{ mime, ext string }{
+	{"image/png", ".png"},
+	{"image/jpeg", ".jpg"},
+	{"image/gif", ".gif"},
+	{"image/webp", ".webp"},
+}
+
+// CanReadImage reports whether a tool for reading images off the
+// clipboard exists here, so callers can skip ReadImage cheaply:
+//
+//	Linux (Wayland): wl-paste
+//	Linux (X11):     xclip
+//	macOS:           osascript
+func CanReadImage() bool {
+	_, _, err := imageReader()
+	return err == nil
+}
+
+// ReadImage returns the image on the clipboard and the extension it
+// should be saved with, as ".png". ErrNoImage is returned when the
+// clipboard holds something else, ErrUnavailable when no tool can read it.
+func ReadImage() ([]byte, string, error) {
+	path, kind, err := imageReader()
+	if err != nil {
+		return nil, "", err
+	}
+	switch kind {
+	case "wl-paste":
+		return readListedImage(path, []string{"--list-types"}, func(mime string) []string {
+			return []string{"--no-newline", "--type", mime}
+		})
+	case "xclip":
+		return readListedImage(path, []string{"-selection", "clipboard", "-t", "TARGETS", "-o"}, func(mime string) []string {
+			return []string{"-selection", "clipboard", "-t", mime, "-o"}
+		})
+	}
+	return readDarwinImage(path)
+}
+
+// imageReader finds the tool ReadImage uses.
+func imageReader() (path, kind string, err error) {
+	switch runtime.GOOS {
+	case "darwin":
+		if path, err := exec.LookPath("osascript"); err == nil {
+			return path, "osascript", nil
+		}
+	case "linux", "freebsd", "openbsd", "netbsd":
+		if os.Getenv("WAYLAND_DISPLAY") != "" {
+			if path, err := exec.LookPath("wl-paste"); err == nil {
+				return path, "wl-paste", nil
+			}
+		}
+		if os.Getenv("DISPLAY") != "" {
+			if path, err := exec.LookPath("xclip"); err == nil {
+				return path, "xclip", nil
+			}
+		}
+	}
+	return "", "", ErrUnavailable
+}
+
+// readListedImage asks a tool for the types on the clipboard, one per
+// line, then reads the preferred image type.
+func readListedImage(path string, listArgs []string, readArgs func(mime string) []string) ([]byte, string, error) {
+	listed, err := output(path, listArgs...)
+	if err != nil {
+		return nil, "", ErrNoImage // An empty clipboard fails to list
+	}
+	types := map[string]bool{}
+	for _, line := range strings.Split(string(listed), "\n") {
+		types[strings.TrimSpace(line)] = true
+	}
+	for _, t := range imageTypes {
+		if !types[t.mime] {
+			continue
+		}
+		data, err := output(path, readArgs(t.mime)...)
+		if err != nil {
+			return nil, "", err
+		}
+		if len(data) == 0 {
+			return nil, "", ErrNoImage
+		}
+		return data, t.ext, nil
+	}
+	return nil, "", ErrNoImage
+}
+
+// readDarwinImage coerces the clipboard to PNG, which AppleScript prints
+// as a «data PNGf...» literal of hex digits.
+func readDarwinImage(path string) ([]byte, string, error) {
+	out, err := output(path, "-e", "the clipboard as «class PNGf»")
+	if err != nil {
+		return nil, "", ErrNoImage // The coercion fails without an image
+	}
+	literal := strings.TrimSpace(string(out))
+	digits, ok := strings.CutPrefix(literal, "«data PNGf")
+	if !ok {
+		return nil, "", ErrNoImage
+	}
+	data, err := hex.DecodeString(strings.TrimSuffix(digits, "»"))
+	if err != nil {
+		return nil, "", fmt.Errorf("%s: unexpected clipboard data: %w", path, err)
+	}
+	return data, ".png", nil
+}
+
+// output executes a clipboard tool and returns its stdout, surfacing
+// stderr on failure.
+func output(path string, args ...string) ([]byte, error) {
+	cmd := exec.Command(path, args...)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := bytes.TrimSpace(stderr.Bytes()); len(msg) > 0 {
+			return nil, fmt.Errorf("%s: %w: %s", path, err, msg)
+		}
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return stdout.Bytes(), nil
+}
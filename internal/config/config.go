@@ -0,0 +1,480 @@
+// Package config loads user configuration for MDE.
+//
+// Configuration lives in a single TOML file, by default
+// $XDG_CONFIG_HOME/mde/config.toml (~/.config/mde/config.toml).
+// A missing file is not an error: every setting has a sensible default,
+// so mde works out of the box and the file only records overrides.
+//
+// A project may override the settings that describe how its documents
+// are written (tab_width, line_width, link_style and [lint]) in a
+// .mde.toml file at its root, found by walking up from the open file's
+// directory; see Project.
+//
+// Settings the user's file leaves to their defaults give way to a file's
+// .editorconfig (see package editorconfig); IsSet tells them apart.
+//
+// Example:
+//
+//	author = "Jane Doe"
+//	templates_dir = "~/notes/templates"
+//	journal_dir = "~/notes/journal"
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+// Config holds all user-configurable settings.
+type Config struct {
+	// Author is substituted into templates as {{.Author}}
+	Author string `toml:"author"`
+
+	// TemplatesDir holds user document templates (<name>.md)
+	// Defaults to <config dir>/templates
+	TemplatesDir string `toml:"templates_dir"`
+
+	// JournalDir is where `mde journal` keeps dated notes (YYYY-MM-DD.md)
+	JournalDir string `toml:"journal_dir"`
+
+	// JournalTemplate optionally names a template for new journal entries;
+	// when empty a plain date heading is used
+	JournalTemplate string `toml:"journal_template"`
+
+	// WikiLinks enables [[Page Name]] links between notes
+	WikiLinks bool `toml:"wiki_links"`
+
+	// MathUnicode renders simple TeX math as Unicode in preview
+	// (\alpha^2 → α²); when false math is shown as written
+	MathUnicode bool `toml:"math_unicode"`
+
+	// Typographer shows smart quotes, en/em dashes and ellipses in preview
+	// and copied HTML; the source keeps its plain ASCII punctuation
+	Typographer bool `toml:"typographer"`
+
+	// DiagramCommand renders ```mermaid (and other diagram) fences to an
+	// image; see package diagram for placeholders. Empty disables it.
+	DiagramCommand string `toml:"diagram_command"`
+
+	// DiagramFormat is the image extension passed as {output}
+	DiagramFormat string `toml:"diagram_format"`
+
+	// FilterTimeout is how many seconds filter-selection waits for its
+	// command before killing it
+	FilterTimeout int `toml:"filter_timeout"`
+
+	// Interpreters lets run-block execute fenced code by language, e.g.
+	// python = "python3" or go = "go run {file}": the code is passed on
+	// stdin, or in a temporary file named by {file}. It runs unsandboxed
+	// with the user's permissions, so only the languages listed run.
+	Interpreters map[string]string `toml:"interpreters"`
+
+	// RunTimeout is how many seconds run-block waits for the code
+	RunTimeout int `toml:"run_timeout"`
+
+	// PandocCommand is the pandoc executable that export runs
+	PandocCommand string `toml:"pandoc_command"`
+
+	// PandocArgs adds pandoc options by output format, e.g.
+	// docx = ["--reference-doc=/path/ref.docx"]; those under "all" are
+	// passed for every format
+	PandocArgs map[string][]string `toml:"pandoc_args"`
+
+	// PreviewAddress is the host:port the live HTML preview server
+	// listens on; port 0 picks a free one
+	PreviewAddress string `toml:"preview_address"`
+
+	// ShareAddress is where share hosts a shared editing session and join
+	// looks for one: a host:port, or the path of a unix socket
+	ShareAddress string `toml:"share_address"`
+
+	// LinkCheckTimeout is how many seconds check-links-online waits for
+	// each page
+	LinkCheckTimeout int `toml:"link_check_timeout"`
+
+	// LinkCheckConcurrency is how many pages check-links-online requests
+	// at once
+	LinkCheckConcurrency int `toml:"link_check_concurrency"`
+
+	// TypewriterScrolling keeps the cursor line vertically centered,
+	// scrolling the text instead of the cursor
+	TypewriterScrolling bool `toml:"typewriter_scrolling"`
+
+	// TabWidth is how many columns a tab stop spans
+	TabWidth int `toml:"tab_width"`
+
+	// IndentStyle is what Tab inserts: "tab", or "space" for spaces up to
+	// the next tab stop
+	IndentStyle string `toml:"indent_style"`
+
+	// LineWidth is the column reflow-paragraph wraps text at
+	LineWidth int `toml:"line_width"`
+
+	// LinkStyle is the kind of link the link command makes: "inline",
+	// [text](url), or "reference", [text][1] with the URL defined at the
+	// end of the document
+	LinkStyle string `toml:"link_style"`
+
+	// Lint turns lint rules on or off by name, e.g. [lint]
+	// undefined-acronym = false; rules not listed are on
+	Lint map[string]bool `toml:"lint"`
+
+	// ShowInvisibles marks tabs (→), trailing spaces (·) and non-breaking
+	// spaces (␣) in the editor with dim markers
+	ShowInvisibles bool `toml:"show_invisibles"`
+
+	// IndentGuides draws faint vertical guides at each tab stop of a
+	// line's indentation, following nested lists and code blocks
+	IndentGuides bool `toml:"indent_guides"`
+
+	// StickyHeading pins the heading of the section scrolled into as a
+	// dimmed first row of the editor
+	StickyHeading bool `toml:"sticky_heading"`
+
+	// Breadcrumb shows the headings enclosing the cursor in the status
+	// bar, as "Chapter 2 ▸ Setup ▸ Install"
+	Breadcrumb bool `toml:"breadcrumb"`
+
+	// Theme names the color theme: "default", "light", one of the user
+	// themes (<name>.toml or <name>.json) in ThemesDir, or "auto" for
+	// LightTheme or DarkTheme by the terminal's background color
+	Theme string `toml:"theme"`
+
+	// LightTheme and DarkTheme are the themes "auto" picks from
+	LightTheme string `toml:"light_theme"`
+	DarkTheme  string `toml:"dark_theme"`
+
+	// ScreenReader draws plain text for terminal screen readers: no
+	// colors, box-drawing characters or cursor glyph, the terminal's cursor
+	// on the editor's, and a status bar of fixed fields naming the mode
+	ScreenReader bool `toml:"screen_reader"`
+
+	// CursorStyle is the cursor's shape while inserting: "block", "bar"
+	// or "underline"; CursorOverwriteStyle is its shape while overwriting
+	CursorStyle          string `toml:"cursor_style"`
+	CursorOverwriteStyle string `toml:"cursor_overwrite_style"`
+
+	// TerminalCursor shows the terminal's own cursor, shaped by the cursor
+	// styles and blinking with CursorBlink, instead of drawing a glyph
+	TerminalCursor bool `toml:"terminal_cursor"`
+	CursorBlink    bool `toml:"cursor_blink"`
+
+	// CursorPreviewStyle is the terminal cursor's shape in preview, at
+	// the top of the page, or "hidden"
+	CursorPreviewStyle string `toml:"cursor_preview_style"`
+
+	// ThemesDir holds user themes, loaded at startup
+	// Defaults to <config dir>/themes
+	ThemesDir string `toml:"themes_dir"`
+
+	// StripTrailingWhitespace removes trailing spaces and tabs when saving.
+	// Markdown hard line breaks keep their two spaces.
+	StripTrailingWhitespace bool `toml:"strip_trailing_whitespace"`
+
+	// InsertFinalNewline ends the text with a line break when saving, if
+	// it lacks one. Left unset, a file's .editorconfig decides, and
+	// without one the text is saved as it is
+	InsertFinalNewline bool `toml:"insert_final_newline"`
+
+	// TOCStartMarker and TOCEndMarker are the lines enclosing a generated
+	// table of contents; TOCOnSave refreshes it whenever the file is saved
+	TOCStartMarker string `toml:"toc_start_marker"`
+	TOCEndMarker   string `toml:"toc_end_marker"`
+	TOCOnSave      bool   `toml:"toc_on_save"`
+
+	// AutoRenumberLists renumbers an ordered list after editing adds or
+	// removes lines in it, or starts a new item
+	AutoRenumberLists bool `toml:"auto_renumber_lists"`
+
+	// NumberHeadings keeps hierarchical numbers ("2.3.1 Title") before
+	// headings, renumbering as sections are added, removed or moved
+	NumberHeadings bool `toml:"number_headings"`
+
+	// PasteTables chooses what pasting tab- or comma-separated data does:
+	// "ask" offers to convert it to a pipe table, "always" converts it
+	// and "never" pastes it as is
+	PasteTables string `toml:"paste_tables"`
+
+	// SmartLinkPaste turns selected text into a link when a URL is pasted
+	// over it, rather than replacing it
+	SmartLinkPaste bool `toml:"smart_link_paste"`
+
+	// AssetsDir is where images pasted from the clipboard are saved,
+	// relative to the document's directory unless absolute
+	AssetsDir string `toml:"assets_dir"`
+
+	// Bibliography is a BibTeX (.bib) or CSL-JSON (.json) file whose
+	// entries [@key] citations name, relative to the document's directory
+	// unless absolute; empty turns citation completion off
+	Bibliography string `toml:"bibliography"`
+
+	// Glossary is a markdown file of terms and their definitions, as
+	// "*[API]: Application Programming Interface" lines or a definition
+	// list, relative to the document's directory unless absolute. Its
+	// terms are defined on hover, and acronyms neither it nor the document
+	// defines are reported as warnings; empty turns the checks off
+	Glossary string `toml:"glossary"`
+
+	// WordGoal is a word count to reach, with progress shown in the
+	// status bar; 0 turns it off
+	WordGoal int `toml:"word_goal"`
+
+	// WordGoalScope chooses what counts toward word_goal: "document" for
+	// every word in the file, or "session" for the words added to it
+	// today, remembered across restarts
+	WordGoalScope string `toml:"word_goal_scope"`
+
+	// EncryptedPatterns match the names of notes kept encrypted on disk:
+	// .age notes use age, the others GnuPG with a passphrase
+	EncryptedPatterns []string `toml:"encrypted_patterns"`
+
+	// AgeIdentity is the age identity file that decrypts .age notes and
+	// whose recipient they are encrypted to
+	AgeIdentity string `toml:"age_identity"`
+
+	// MaxDownloadMB is the largest document opened from an http(s) URL
+	MaxDownloadMB int `toml:"max_download_mb"`
+
+	// HistoryDir keeps a snapshot of each local file every time it is
+	// saved, for the history command to compare and restore; empty turns
+	// history off. Defaults to <data dir>/history
+	// (~/.local/share/mde/history)
+	HistoryDir string `toml:"history_dir"`
+
+	// HistorySnapshots and HistoryMaxMB bound the snapshots kept of each
+	// file, the oldest going first; 0 means no limit
+	HistorySnapshots int `toml:"history_snapshots"`
+	HistoryMaxMB     int `toml:"history_max_mb"`
+
+	// ShowStatusBar and ShowHelpBar choose which bars are drawn under the
+	// text; the help bar still appears while a prompt is open
+	ShowStatusBar bool `toml:"show_status_bar"`
+	ShowHelpBar   bool `toml:"show_help_bar"`
+
+	// MouseHover reports every mouse movement so hovering a link shows its
+	// URL, and a footnote reference its text, in the status line
+	MouseHover bool `toml:"mouse_hover"`
+
+	// ScrollLines and ScrollColumns are how far one mouse wheel step
+	// scrolls vertically and horizontally
+	ScrollLines   int `toml:"scroll_lines"`
+	ScrollColumns int `toml:"scroll_columns"`
+
+	// NaturalScroll inverts the wheel so content follows the fingers, as
+	// on a touchpad
+	NaturalScroll bool `toml:"natural_scroll"`
+
+	// ShiftWheelHorizontal makes Shift+wheel scroll sideways
+	ShiftWheelHorizontal bool `toml:"shift_wheel_horizontal"`
+
+	// Keymap selects the key bindings: "default", or "vim" for modal
+	// editing with normal, insert and visual modes
+	Keymap string `toml:"keymap"`
+
+	// AutoReplace expands built-in abbreviations while typing, such as
+	// "->" to "→" and "(c)" to "©"
+	AutoReplace bool `toml:"auto_replace"`
+
+	// Abbreviations adds user expansions to the built-in ones, e.g.
+	// btw = "by the way"; an empty expansion disables a built-in
+	Abbreviations map[string]string `toml:"abbreviations"`
+
+	// FileTypes chooses the parser for file extensions beyond the
+	// built-in ones, e.g. ".mdx" = "commonmark"
+	FileTypes map[string]string `toml:"file_types"`
+
+	// ZenWidth is the width of the centered text column in zen mode
+	ZenWidth int `toml:"zen_width"`
+
+	// ZenFocus chooses what zen mode leaves undimmed around the cursor:
+	// "paragraph", "sentence" or "none"
+	ZenFocus string `toml:"zen_focus"`
+
+	// ControlSocket is a unix socket path on which a running mde accepts
+	// JSON-RPC requests (open, goto, insert, diagnostics, ...) from other
+	// tools; see package control. Empty disables it.
+	ControlSocket string `toml:"control_socket"`
+
+	// LanguageServers runs a language server for files by extension, e.g.
+	// [language_servers.".md"] command = "marksman", args = ["server"]
+	LanguageServers map[string]LanguageServer `toml:"language_servers"`
+
+	set map[string]bool // Keys the file set
+}
+
+// IsSet reports whether the config file set key itself, rather than
+// leaving it to its default.
+func (c *Config) IsSet(key string) bool {
+	return c.set[key]
+}
+
+// LanguageServer is a language server command, run with the file's
+// directory as the workspace root.
+type LanguageServer struct {
+	Command string   `toml:"command"`
+	Args    []string `toml:"args"`
+
+	// LanguageID names the file's language to the server; "markdown"
+	// when empty
+	LanguageID string `toml:"language_id"`
+}
+
+// Default returns the built-in configuration used when no file exists.
+func Default() *Config {
+	cfg := &Config{
+		JournalDir:    ExpandHome("~/journal"),
+		MathUnicode:   true,
+		DiagramFormat: "svg",
+		FilterTimeout: 10,
+		RunTimeout:    30,
+		PandocCommand: "pandoc",
+		ShowStatusBar: true,
+		ShowHelpBar:   true,
+		MouseHover:    true,
+		StickyHeading: true,
+		Breadcrumb:    true,
+		Theme:         "auto",
+		LightTheme:    "light",
+		DarkTheme:     "default",
+		CursorStyle:   "block",
+		CursorBlink:   true,
+
+		CursorOverwriteStyle: "underline",
+		CursorPreviewStyle:   "hidden",
+		Keymap:               "default",
+		AutoReplace:          true,
+		ScrollLines:          3,
+		ScrollColumns:        2,
+
+		ShiftWheelHorizontal: true,
+		ZenWidth:             80,
+		ZenFocus:             "paragraph",
+
+		TabWidth:    4,
+		IndentStyle: "tab",
+		LineWidth:   80,
+		LinkStyle:   "inline",
+
+		PreviewAddress: "127.0.0.1:6419",
+		ShareAddress:   "127.0.0.1:7419",
+
+		LinkCheckTimeout:     10,
+		LinkCheckConcurrency: 8,
+
+		TOCStartMarker: "<!-- toc -->",
+		TOCEndMarker:   "<!-- /toc -->",
+		PasteTables:    "ask",
+		SmartLinkPaste: true,
+		AssetsDir:      "assets",
+		WordGoalScope:  "document",
+
+		EncryptedPatterns: []string{"*.md.age", "*.md.gpg", "*.md.asc"},
+		MaxDownloadMB:     5,
+
+		HistorySnapshots: 50,
+		HistoryMaxMB:     10,
+	}
+	if dir, err := Dir(); err == nil {
+		cfg.TemplatesDir = filepath.Join(dir, "templates")
+		cfg.ThemesDir = filepath.Join(dir, "themes")
+	}
+	if dir, err := DataDir(); err == nil {
+		cfg.HistoryDir = filepath.Join(dir, "history")
+	}
+	return cfg
+}
+
+// DataDir returns the directory MDE keeps its data in:
+// $XDG_DATA_HOME/mde, or ~/.local/share/mde.
+func DataDir() (string, error) {
+	if base := os.Getenv("XDG_DATA_HOME"); filepath.IsAbs(base) {
+		return filepath.Join(base, "mde"), nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate data directory: %w", err)
+	}
+	return filepath.Join(home, ".local", "share", "mde"), nil
+}
+
+// Dir returns the MDE configuration directory.
+func Dir() (string, error) {
+	base, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate config directory: %w", err)
+	}
+	return filepath.Join(base, "mde"), nil
+}
+
+// Path returns the location of the user configuration file.
+func Path() (string, error) {
+	dir, err := Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "config.toml"), nil
+}
+
+// Load reads the user configuration file, falling back to defaults
+// when it does not exist.
+func Load() (*Config, error) {
+	path, err := Path()
+	if err != nil {
+		return Default(), nil
+	}
+	return LoadFile(path)
+}
+
+// LoadFile reads configuration from path layered over the defaults.
+// A missing file yields the defaults without error.
+func LoadFile(path string) (*Config, error) {
+	cfg := Default()
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return cfg, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config %s: %w", path, err)
+	}
+
+	meta, err := toml.Decode(string(data), cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse config %s: %w", path, err)
+	}
+	cfg.set = make(map[string]bool)
+	for _, key := range meta.Keys() {
+		cfg.set[key[0]] = true
+	}
+
+	cfg.TemplatesDir = ExpandHome(cfg.TemplatesDir)
+	cfg.ThemesDir = ExpandHome(cfg.ThemesDir)
+	cfg.JournalDir = ExpandHome(cfg.JournalDir)
+	cfg.AgeIdentity = ExpandHome(cfg.AgeIdentity)
+	cfg.ControlSocket = ExpandHome(cfg.ControlSocket)
+	cfg.PandocCommand = ExpandHome(cfg.PandocCommand)
+	cfg.AssetsDir = ExpandHome(cfg.AssetsDir)
+	cfg.Bibliography = ExpandHome(cfg.Bibliography)
+	cfg.Glossary = ExpandHome(cfg.Glossary)
+	cfg.HistoryDir = ExpandHome(cfg.HistoryDir)
+	cfg.ShareAddress = ExpandHome(cfg.ShareAddress)
+	return cfg, nil
+}
+
+// ExpandHome replaces a leading "~/" with the user's home directory.
+func ExpandHome(path string) string {
+	if path != "~" && !strings.HasPrefix(path, "~/") {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return filepath.Join(home, strings.TrimPrefix(path, "~"))
+}
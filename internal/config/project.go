@@ -0,0 +1,97 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+// ProjectFile names a project's settings file.
+const ProjectFile = ".mde.toml"
+
+// Project holds the settings a project's .mde.toml may override: those
+// describing how its documents are written. Settings that run commands or
+// reach outside the project stay the user's, so that opening a file from
+// someone else's repository cannot make mde run anything.
+type Project struct {
+	TabWidth  *int            `toml:"tab_width"`
+	LineWidth *int            `toml:"line_width"`
+	LinkStyle *string         `toml:"link_style"`
+	Lint      map[string]bool `toml:"lint"`
+}
+
+// FindProject returns the path of the .mde.toml in dir or the nearest
+// directory above it, or "" if there is none.
+func FindProject(dir string) string {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return ""
+	}
+	for {
+		path := filepath.Join(dir, ProjectFile)
+		if info, err := os.Stat(path); err == nil && info.Mode().IsRegular() {
+			return path
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+// LoadProject reads the project settings at path. Settings a project may
+// not override are an error, rather than silently ignored.
+func LoadProject(path string) (*Project, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return &Project{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read project settings %s: %w", path, err)
+	}
+
+	project := &Project{}
+	meta, err := toml.Decode(string(data), project)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse project settings %s: %w", path, err)
+	}
+	if undecoded := meta.Undecoded(); len(undecoded) > 0 {
+		keys := make([]string, len(undecoded))
+		for i, key := range undecoded {
+			keys[i] = key.String()
+		}
+		return nil, fmt.Errorf("%s: a project cannot set %s (only tab_width, line_width, link_style and [lint])", path, strings.Join(keys, ", "))
+	}
+	return project, nil
+}
+
+// Apply sets the project's settings in cfg, returning the function that
+// puts cfg's own back. Lint rules are merged, the project's deciding
+// those both list.
+func (p *Project) Apply(cfg *Config) (restore func()) {
+	tabWidth, lineWidth, linkStyle, lint := cfg.TabWidth, cfg.LineWidth, cfg.LinkStyle, cfg.Lint
+	if p.TabWidth != nil {
+		cfg.TabWidth = *p.TabWidth
+	}
+	if p.LineWidth != nil {
+		cfg.LineWidth = *p.LineWidth
+	}
+	if p.LinkStyle != nil {
+		cfg.LinkStyle = *p.LinkStyle
+	}
+	if len(p.Lint) > 0 {
+		cfg.Lint = make(map[string]bool, len(lint)+len(p.Lint))
+		maps.Copy(cfg.Lint, lint)
+		maps.Copy(cfg.Lint, p.Lint)
+	}
+	return func() {
+		cfg.TabWidth, cfg.LineWidth, cfg.LinkStyle, cfg.Lint = tabWidth, lineWidth, linkStyle, lint
+	}
+}
@@ -0,0 +1,211 @@
+// Package control serves JSON-RPC 2.0 on a unix socket, so scripts, tests
+// and other tools can drive a running editor. Requests and responses are
+// JSON objects, one per line:
+//
+//	{"jsonrpc":"2.0","id":1,"method":"goto","params":{"line":10}}
+//	{"jsonrpc":"2.0","id":1,"result":null}
+//
+// Requests on one connection are answered in order; notifications (no id)
+// are run without a response.
+package control
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"sync"
+)
+
+// Error codes defined by JSON-RPC 2.0.
+const (
+	CodeParseError     = -32700
+	CodeInvalidRequest = -32600
+	CodeMethodNotFound = -32601
+	CodeInvalidParams  = -32602
+	CodeInternalError  = -32603
+	CodeServerError    = -32000 // The method failed
+)
+
+// Error is a JSON-RPC error. A handler returns one to choose the code;
+// any other error is reported with CodeServerError.
+type Error struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// ErrMethodNotFound is returned by handlers for methods they do not serve.
+var ErrMethodNotFound = errors.New("method not found")
+
+// InvalidParams reports params a method cannot use.
+func InvalidParams(err error) error {
+	return &Error{Code: CodeInvalidParams, Message: err.Error()}
+}
+
+// Handler runs method with its raw params and returns the result, which
+// is marshaled to JSON.
+type Handler func(ctx context.Context, method string, params json.RawMessage) (any, error)
+
+// request is a JSON-RPC request or notification.
+type request struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+// response answers a request; exactly one of Result and Error is set.
+type response struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   *Error          `json:"error,omitempty"`
+}
+
+// Server accepts connections on a unix socket until closed.
+type Server struct {
+	listener net.Listener
+	handler  Handler
+	ctx      context.Context
+	cancel   context.CancelFunc
+	wg       sync.WaitGroup
+
+	mu    sync.Mutex
+	conns map[net.Conn]struct{}
+}
+
+// Listen serves handler on a unix socket at path, readable and writable
+// by the user only. A stale socket left by an earlier run is replaced.
+func Listen(path string, handler Handler) (*Server, error) {
+	if conn, err := net.Dial("unix", path); err == nil {
+		conn.Close()
+		return nil, fmt.Errorf("%s is in use by another instance", path)
+	}
+	os.Remove(path)
+
+	listener, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Chmod(path, 0o600); err != nil {
+		listener.Close()
+		return nil, err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	s := &Server{
+		listener: listener,
+		handler:  handler,
+		ctx:      ctx,
+		cancel:   cancel,
+		conns:    make(map[net.Conn]struct{}),
+	}
+	s.wg.Add(1)
+	go s.accept()
+	return s, nil
+}
+
+// Addr returns the socket path.
+func (s *Server) Addr() string {
+	return s.listener.Addr().String()
+}
+
+// Close stops accepting connections, closes the open ones and removes the
+// socket, waiting for requests in progress to be cancelled.
+func (s *Server) Close() error {
+	s.cancel()
+	err := s.listener.Close()
+	s.mu.Lock()
+	for conn := range s.conns {
+		conn.Close()
+	}
+	s.mu.Unlock()
+	s.wg.Wait()
+	return err
+}
+
+func (s *Server) accept() {
+	defer s.wg.Done()
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return // Closed
+		}
+		s.mu.Lock()
+		s.conns[conn] = struct{}{}
+		s.mu.Unlock()
+
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			s.serve(conn)
+			s.mu.Lock()
+			delete(s.conns, conn)
+			s.mu.Unlock()
+			conn.Close()
+		}()
+	}
+}
+
+// serve answers the requests on conn until it is closed.
+func (s *Server) serve(conn net.Conn) {
+	scanner := bufio.NewScanner(conn)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	encoder := json.NewEncoder(conn)
+	for scanner.Scan() {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		resp, ok := s.handle(scanner.Bytes())
+		if !ok {
+			continue // A notification
+		}
+		if err := encoder.Encode(resp); err != nil {
+			return
+		}
+	}
+}
+
+// handle runs one request, returning its response unless it was a
+// notification.
+func (s *Server) handle(line []byte) (response, bool) {
+	resp := response{JSONRPC: "2.0", ID: json.RawMessage("null")}
+	var req request
+	if err := json.Unmarshal(line, &req); err != nil {
+		resp.Error = &Error{Code: CodeParseError, Message: err.Error()}
+		return resp, true
+	}
+	if req.ID != nil {
+		resp.ID = req.ID
+	}
+	if req.JSONRPC != "2.0" || req.Method == "" {
+		resp.Error = &Error{Code: CodeInvalidRequest, Message: `want "jsonrpc":"2.0" and a method`}
+		return resp, true
+	}
+
+	result, err := s.handler(s.ctx, req.Method, req.Params)
+	if req.ID == nil {
+		return resp, false
+	}
+	var rpcErr *Error
+	switch {
+	case err == nil:
+		if resp.Result, err = json.Marshal(result); err != nil {
+			resp.Error = &Error{Code: CodeInternalError, Message: err.Error()}
+		}
+	case errors.Is(err, ErrMethodNotFound):
+		resp.Error = &Error{Code: CodeMethodNotFound, Message: fmt.Sprintf("method %q not found", req.Method)}
+	case errors.As(err, &rpcErr):
+		resp.Error = rpcErr
+	default:
+		resp.Error = &Error{Code: CodeServerError, Message: err.Error()}
+	}
+	return resp, true
+}
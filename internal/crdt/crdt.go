@@ -0,0 +1,473 @@
+// Package crdt lets replicas of a document be edited concurrently and
+// merged without conflicts, as a replicated growable array (RGA): every
+// character ever inserted keeps a unique ID and the ID of the character
+// it was inserted after, and deleting one only hides it. Replicas that
+// have applied the same operations hold the same text, whatever order the
+// operations arrived in.
+//
+// Text is a replica of plain text. Document keeps an ast.Document in step
+// with one, turning edits made to the document into operations for the
+// other replicas and applying theirs to it.
+package crdt
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"slices"
+	"strings"
+	"unicode/utf8"
+)
+
+// ID identifies a character: the replica that inserted it and that
+// replica's Lamport clock when it did.
+type ID struct {
+	Site    string `json:"site"`
+	Counter int    `json:"counter"`
+}
+
+// after reports whether id orders after other: later counters first,
+// the site breaking ties, so concurrent insertions at the same place end
+// up in the same order on every replica.
+func (id ID) after(other ID) bool {
+	if id.Counter != other.Counter {
+		return id.Counter > other.Counter
+	}
+	return id.Site > other.Site
+}
+
+// Op is an operation on a replica: inserting Char, identified by ID,
+// after the character After (the zero ID for the start of the text), or
+// deleting the character ID.
+type Op struct {
+	ID     ID   `json:"id"`
+	After  ID   `json:"after,omitempty"`
+	Char   rune `json:"char,omitempty"`
+	Delete bool `json:"delete,omitempty"`
+}
+
+// Change is the effect of an operation on the visible text: Text inserted
+// at rune offset Offset, or Deleted runes removed from it.
+type Change struct {
+	Offset  int
+	Text    string
+	Deleted int
+}
+
+// Shift returns where rune offset offset of the text before the change is
+// after it. Text inserted at offset goes after it.
+func (c Change) Shift(offset int) int {
+	if c.Offset < offset {
+		offset -= min(c.Deleted, offset-c.Offset)
+	}
+	if c.Offset < offset {
+		offset += utf8.RuneCountInString(c.Text)
+	}
+	return offset
+}
+
+// element is a character of the replica, deleted ones included.
+type element struct {
+	id      ID
+	char    rune
+	deleted bool
+}
+
+// blockSize is how many elements a block holds before it is split in two.
+// Elements are kept in blocks so that inserting one moves only its block's
+// and finding the visible offset of one counts blocks, not characters.
+const blockSize = 512
+
+// block is a run of consecutive elements of the replica.
+type block struct {
+	index    int // Of the block in Text.blocks
+	elements []element
+	visible  int // Elements not deleted
+}
+
+// pos is where an element is: its block's index and its index within it.
+// The position just past the last element is its block's length.
+type pos struct {
+	block, i int
+}
+
+// baseSite inserts the text replicas start from, so that replicas
+// created from the same text agree on its IDs.
+const baseSite = ""
+
+// Text is a replica of a text.
+type Text struct {
+	site    string
+	clock   int
+	blocks  []*block      // Never empty; only a replica of no text has an empty block
+	where   map[ID]*block // The block holding each element
+	pending []Op          // Operations waiting for the character they refer to
+}
+
+// NewSite returns a random name for a replica.
+func NewSite() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// NewText returns the replica site of text. Replicas of the same text can
+// be merged.
+func NewText(site, text string) *Text {
+	t := newText(site)
+	for _, r := range text {
+		t.clock++
+		t.append(element{id: ID{Site: baseSite, Counter: t.clock}, char: r})
+	}
+	return t
+}
+
+func newText(site string) *Text {
+	return &Text{site: site, blocks: []*block{{}}, where: make(map[ID]*block)}
+}
+
+// Snapshot is the state of a replica: every element, deleted ones
+// included, in runs of characters one site inserted one after another.
+// A replica joining late starts from one instead of replaying every
+// operation.
+type Snapshot struct {
+	Clock int   `json:"clock"`
+	Runs  []Run `json:"runs"`
+}
+
+// Run is a run of elements inserted by Site with consecutive counters
+// from Counter, all deleted or all not.
+type Run struct {
+	Site    string `json:"site"`
+	Counter int    `json:"counter"`
+	Text    string `json:"text"`
+	Deleted bool   `json:"deleted,omitempty"`
+}
+
+// Snapshot returns the state of the replica, to start another from.
+func (t *Text) Snapshot() Snapshot {
+	snapshot := Snapshot{Clock: t.clock}
+	var text []rune
+	flush := func() {
+		if n := len(snapshot.Runs); n > 0 {
+			snapshot.Runs[n-1].Text = string(text)
+		}
+		text = text[:0]
+	}
+	for _, b := range t.blocks {
+		for _, e := range b.elements {
+			if n := len(snapshot.Runs); n > 0 {
+				last := snapshot.Runs[n-1]
+				if last.Site == e.id.Site && last.Counter+len(text) == e.id.Counter && last.Deleted == e.deleted {
+					text = append(text, e.char)
+					continue
+				}
+			}
+			flush()
+			snapshot.Runs = append(snapshot.Runs, Run{Site: e.id.Site, Counter: e.id.Counter, Deleted: e.deleted})
+			text = append(text, e.char)
+		}
+	}
+	flush()
+	return snapshot
+}
+
+// FromSnapshot returns the replica site of the state snapshot, which can
+// be merged with the replica it was taken from.
+func FromSnapshot(site string, snapshot Snapshot) *Text {
+	t := newText(site)
+	t.clock = snapshot.Clock
+	for _, run := range snapshot.Runs {
+		counter := run.Counter
+		for _, r := range run.Text {
+			t.append(element{id: ID{Site: run.Site, Counter: counter}, char: r, deleted: run.Deleted})
+			counter++
+		}
+	}
+	return t
+}
+
+// String returns the visible text.
+func (t *Text) String() string {
+	var b strings.Builder
+	for _, bl := range t.blocks {
+		for _, e := range bl.elements {
+			if !e.deleted {
+				b.WriteRune(e.char)
+			}
+		}
+	}
+	return b.String()
+}
+
+// Insert inserts text at rune offset offset of the visible text,
+// returning the operations to send to the other replicas.
+func (t *Text) Insert(offset int, text string) []Op {
+	after := ID{}
+	if prev, ok := t.prev(t.visiblePos(offset)); ok {
+		after = t.at(prev).id
+	}
+	var ops []Op
+	for _, r := range text {
+		t.clock++
+		op := Op{ID: ID{Site: t.site, Counter: t.clock}, After: after, Char: r}
+		t.apply(op)
+		ops = append(ops, op)
+		after = op.ID
+	}
+	return ops
+}
+
+// Delete deletes n runes from rune offset offset of the visible text,
+// returning the operations to send to the other replicas.
+func (t *Text) Delete(offset, n int) []Op {
+	var ids []ID
+	for p := t.visiblePos(offset); len(ids) < n && t.valid(p); p = t.next(p) {
+		if e := t.at(p); !e.deleted {
+			ids = append(ids, e.id)
+		}
+	}
+	ops := make([]Op, 0, len(ids))
+	for _, id := range ids {
+		op := Op{ID: id, Delete: true}
+		t.apply(op)
+		ops = append(ops, op)
+	}
+	return ops
+}
+
+// Update makes the visible text text, as the one edit that replaces the
+// runes between what the texts have in common at either end, returning
+// the operations to send to the other replicas.
+func (t *Text) Update(text string) []Op {
+	old := []rune(t.String())
+	updated := []rune(text)
+	prefix := 0
+	for prefix < len(old) && prefix < len(updated) && old[prefix] == updated[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(old)-prefix && suffix < len(updated)-prefix && old[len(old)-1-suffix] == updated[len(updated)-1-suffix] {
+		suffix++
+	}
+	ops := t.Delete(prefix, len(old)-prefix-suffix)
+	return append(ops, t.Insert(prefix, string(updated[prefix:len(updated)-suffix]))...)
+}
+
+// Apply applies an operation from another replica, returning its effect
+// on the visible text. Operations already applied change nothing, and one
+// that refers to a character not seen yet waits for it; applying that
+// character applies the operation too, so the changes may be several.
+func (t *Text) Apply(op Op) []Change {
+	change, ok := t.apply(op)
+	if !ok {
+		t.pending = append(t.pending, op)
+		return nil
+	}
+	changes := appendChange(nil, change)
+	for applied := true; applied; {
+		applied = false
+		for i, waiting := range t.pending {
+			if change, ok := t.apply(waiting); ok {
+				t.pending = append(t.pending[:i], t.pending[i+1:]...)
+				changes = appendChange(changes, change)
+				applied = true
+				break
+			}
+		}
+	}
+	return changes
+}
+
+// Ops returns the operations that build the replica from an empty one,
+// to bring a replica that has none of its history up to date.
+func (t *Text) Ops() []Op {
+	var ops, deletes []Op
+	var after ID
+	for _, b := range t.blocks {
+		for _, e := range b.elements {
+			ops = append(ops, Op{ID: e.id, After: after, Char: e.char})
+			after = e.id
+			if e.deleted {
+				deletes = append(deletes, Op{ID: e.id, Delete: true})
+			}
+		}
+	}
+	return append(ops, deletes...)
+}
+
+// apply integrates op, reporting false when the character it refers to
+// is not there yet. The change is empty when op has no visible effect.
+func (t *Text) apply(op Op) (Change, bool) {
+	t.clock = max(t.clock, op.ID.Counter)
+	if op.Delete {
+		p, ok := t.find(op.ID)
+		if !ok {
+			return Change{}, false
+		}
+		e := &t.blocks[p.block].elements[p.i]
+		if e.deleted {
+			return Change{}, true
+		}
+		e.deleted = true
+		t.blocks[p.block].visible--
+		return Change{Offset: t.visibleOffset(p), Deleted: 1}, true
+	}
+
+	if _, ok := t.where[op.ID]; ok {
+		return Change{}, true
+	}
+	p := pos{}
+	if op.After != (ID{}) {
+		after, ok := t.find(op.After)
+		if !ok {
+			return Change{}, false
+		}
+		p = t.next(after)
+	}
+	// Characters inserted concurrently after the same one, and those
+	// inserted after them, go first when their IDs order after op's
+	for t.valid(p) && t.at(p).id.after(op.ID) {
+		p = t.next(p)
+	}
+	p = t.insert(p, element{id: op.ID, char: op.Char})
+	return Change{Offset: t.visibleOffset(p), Text: string(op.Char)}, true
+}
+
+// at returns the element at p, which must be valid.
+func (t *Text) at(p pos) element {
+	return t.blocks[p.block].elements[p.i]
+}
+
+// valid reports whether p is at an element rather than past the last.
+func (t *Text) valid(p pos) bool {
+	return p.i < len(t.blocks[p.block].elements)
+}
+
+// next returns the position after p.
+func (t *Text) next(p pos) pos {
+	p.i++
+	for p.i >= len(t.blocks[p.block].elements) && p.block+1 < len(t.blocks) {
+		p = pos{block: p.block + 1}
+	}
+	return p
+}
+
+// prev returns the position of the element before p, if there is one.
+func (t *Text) prev(p pos) (pos, bool) {
+	for p.i == 0 {
+		if p.block == 0 {
+			return pos{}, false
+		}
+		p = pos{block: p.block - 1, i: len(t.blocks[p.block-1].elements)}
+	}
+	p.i--
+	return p, true
+}
+
+// find returns the position of the element id.
+func (t *Text) find(id ID) (pos, bool) {
+	b, ok := t.where[id]
+	if !ok {
+		return pos{}, false
+	}
+	for i, e := range b.elements {
+		if e.id == id {
+			return pos{block: b.index, i: i}, true
+		}
+	}
+	return pos{}, false
+}
+
+// visiblePos returns the position of the element at rune offset offset
+// of the visible text, or the position past the last element.
+func (t *Text) visiblePos(offset int) pos {
+	for bi, b := range t.blocks {
+		if offset >= b.visible {
+			offset -= b.visible
+			continue
+		}
+		for i, e := range b.elements {
+			if !e.deleted {
+				if offset == 0 {
+					return pos{block: bi, i: i}
+				}
+				offset--
+			}
+		}
+	}
+	last := len(t.blocks) - 1
+	return pos{block: last, i: len(t.blocks[last].elements)}
+}
+
+// visibleOffset returns the rune offset in the visible text of the
+// element at p.
+func (t *Text) visibleOffset(p pos) int {
+	offset := 0
+	for _, b := range t.blocks[:p.block] {
+		offset += b.visible
+	}
+	for _, e := range t.blocks[p.block].elements[:p.i] {
+		if !e.deleted {
+			offset++
+		}
+	}
+	return offset
+}
+
+// append adds e after the last element.
+func (t *Text) append(e element) {
+	last := len(t.blocks) - 1
+	t.insert(pos{block: last, i: len(t.blocks[last].elements)}, e)
+}
+
+// insert puts e at p, splitting the block when it grows too large, and
+// returns where e ends up.
+func (t *Text) insert(p pos, e element) pos {
+	b := t.blocks[p.block]
+	b.elements = slices.Insert(b.elements, p.i, e)
+	if !e.deleted {
+		b.visible++
+	}
+	t.where[e.id] = b
+	if len(b.elements) < 2*blockSize {
+		return p
+	}
+
+	split := &block{elements: slices.Clone(b.elements[blockSize:])}
+	b.elements = slices.Clip(b.elements[:blockSize])
+	for _, e := range split.elements {
+		t.where[e.id] = split
+		if !e.deleted {
+			split.visible++
+		}
+	}
+	b.visible -= split.visible
+	t.blocks = slices.Insert(t.blocks, p.block+1, split)
+	for i := p.block + 1; i < len(t.blocks); i++ {
+		t.blocks[i].index = i
+	}
+	if p.i >= blockSize {
+		return pos{block: p.block + 1, i: p.i - blockSize}
+	}
+	return p
+}
+
+// appendChange appends change to changes unless it is empty, joining it
+// to the last when it carries straight on from it.
+func appendChange(changes []Change, change Change) []Change {
+	if change.Text == "" && change.Deleted == 0 {
+		return changes
+	}
+	if n := len(changes); n > 0 {
+		last := &changes[n-1]
+		switch {
+		case change.Text != "" && last.Text != "" && change.Offset == last.Offset+utf8.RuneCountInString(last.Text):
+			last.Text += change.Text
+			return changes
+		case change.Deleted > 0 && last.Deleted > 0 && change.Offset == last.Offset:
+			last.Deleted += change.Deleted
+			return changes
+		}
+	}
+	return append(changes, change)
+}
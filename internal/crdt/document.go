@@ -0,0 +1,90 @@
+package crdt
+
+import (
+	"github.com/ofri/mde/pkg/ast"
+)
+
+// Document keeps an ast.Document in step with a replica of its text.
+// Edits go on being made to the document as usual; Commit turns those
+// made since the last call into operations for the other replicas, and
+// Apply makes theirs to the document.
+type Document struct {
+	doc       *ast.Document
+	text      *Text
+	committed []Op // Local operations Commit has yet to return
+}
+
+// NewDocument returns the replica site of doc. Replicas of documents
+// with the same text can be merged.
+func NewDocument(doc *ast.Document, site string) *Document {
+	return &Document{doc: doc, text: NewText(site, doc.GetText())}
+}
+
+// JoinDocument keeps doc in step with text, a replica started from
+// another's snapshot; doc holds its visible text.
+func JoinDocument(doc *ast.Document, text *Text) *Document {
+	return &Document{doc: doc, text: text}
+}
+
+// Doc returns the document kept in step.
+func (d *Document) Doc() *ast.Document {
+	return d.doc
+}
+
+// Commit returns the operations making the edits to the document since
+// the last call.
+func (d *Document) Commit() []Op {
+	ops := append(d.committed, d.text.Update(d.doc.GetText())...)
+	d.committed = nil
+	return ops
+}
+
+// Snapshot returns the state of the replica, for a replica joining late.
+// Edits to the document are committed first.
+func (d *Document) Snapshot() Snapshot {
+	d.committed = append(d.committed, d.text.Update(d.doc.GetText())...)
+	return d.text.Snapshot()
+}
+
+// Apply applies operations from other replicas to the document, returning
+// their effect on its text. Edits to the document not committed yet are
+// committed first, so that the operations apply to the text they were
+// made to; the next Commit returns them.
+func (d *Document) Apply(ops []Op) []Change {
+	d.committed = append(d.committed, d.text.Update(d.doc.GetText())...)
+	var changes []Change
+	for _, op := range ops {
+		for _, change := range d.text.Apply(op) {
+			d.change(change)
+			changes = appendChange(changes, change)
+		}
+	}
+	return changes
+}
+
+// change makes change to the document.
+func (d *Document) change(change Change) {
+	pos := PosAt(d.doc, change.Offset)
+	if change.Deleted > 0 {
+		d.doc.DeleteRange(pos, PosAt(d.doc, change.Offset+change.Deleted))
+	}
+	for _, r := range change.Text {
+		if r == '\n' {
+			pos = d.doc.InsertNewline(pos)
+		} else {
+			pos = d.doc.InsertChar(pos, r)
+		}
+	}
+}
+
+// PosAt returns the position of rune offset offset of the text of doc,
+// counting each line break as one rune; offsets past the end give the end.
+func PosAt(doc *ast.Document, offset int) ast.BufferPos {
+	return doc.RuneOffsetToPos(offset)
+}
+
+// OffsetAt returns the rune offset of pos in the text of doc, the inverse
+// of PosAt.
+func OffsetAt(doc *ast.Document, pos ast.BufferPos) int {
+	return doc.PosToRuneOffset(pos)
+}
@@ -0,0 +1,165 @@
+// Package critic finds and resolves CriticMarkup, the plain-text syntax
+// for changes suggested to a document:
+//
+//	{++inserted text++}
+//	{--deleted text--}
+//	{>>a comment<<}
+//
+// A mark may span lines. Accepting an insertion keeps its text and
+// accepting a deletion drops it; rejecting does the reverse. A comment
+// goes either way.
+package critic
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"github.com/ofri/mde/pkg/ast"
+)
+
+// Kind is the kind of a mark.
+type Kind int
+
+const (
+	Insertion Kind = iota
+	Deletion
+	Comment
+)
+
+// delimiters are the opening and closing delimiters of each kind; all are
+// three characters long.
+var delimiters = map[Kind][2]string{
+	Insertion: {"{++", "++}"},
+	Deletion:  {"{--", "--}"},
+	Comment:   {"{>>", "<<}"},
+}
+
+// Mark is a suggested change found in a document.
+type Mark struct {
+	Kind  Kind
+	Range ast.Range // The whole mark, delimiters included
+	Text  string    // The text inserted, deleted or commented
+}
+
+// Span is a mark found in a single line, by byte offsets, delimiters
+// included.
+type Span struct {
+	Kind       Kind
+	Start, End int
+}
+
+// Open returns the delimiter opening a mark of kind.
+func Open(kind Kind) string {
+	return delimiters[kind][0]
+}
+
+// Close returns the delimiter closing a mark of kind.
+func Close(kind Kind) string {
+	return delimiters[kind][1]
+}
+
+// Wrap returns text marked up as a change of kind.
+func Wrap(kind Kind, text string) string {
+	return Open(kind) + text + Close(kind)
+}
+
+// Inner returns the range of the mark's text, within its delimiters.
+func (m Mark) Inner() ast.Range {
+	inner := m.Range
+	inner.Start.Col += utf8.RuneCountInString(Open(m.Kind))
+	inner.End.Col -= utf8.RuneCountInString(Close(m.Kind))
+	return inner
+}
+
+// Resolve returns the text that replaces the mark once it is accepted or
+// rejected.
+func (m Mark) Resolve(accept bool) string {
+	if m.Kind != Comment && accept == (m.Kind == Insertion) {
+		return m.Text
+	}
+	return ""
+}
+
+// Find returns the marks in doc, in order.
+func Find(doc *ast.Document) []Mark {
+	text := doc.GetText()
+	var marks []Mark
+	var pos ast.BufferPos
+	last := 0
+	// at returns the position of byte offset i, moving on from the last
+	at := func(i int) ast.BufferPos {
+		for _, r := range text[last:i] {
+			if r == '\n' {
+				pos.Line++
+				pos.Col = 0
+			} else {
+				pos.Col++
+			}
+		}
+		last = i
+		return pos
+	}
+
+	for _, span := range Spans(text) {
+		start := at(span.Start)
+		marks = append(marks, Mark{
+			Kind:  span.Kind,
+			Range: ast.Range{Start: start, End: at(span.End)},
+			Text:  text[span.Start+len(Open(span.Kind)) : span.End-len(Close(span.Kind))],
+		})
+	}
+	return marks
+}
+
+// Spans returns the marks in text, in order. Marks found in a single line
+// of a document may be only part of those in the document, which can
+// span lines.
+func Spans(text string) []Span {
+	var spans []Span
+	for i := 0; i < len(text); {
+		open := strings.IndexByte(text[i:], '{')
+		if open < 0 {
+			break
+		}
+		open += i
+		kind, ok := kindAt(text, open)
+		if !ok {
+			i = open + 1
+			continue
+		}
+		body := open + len(Open(kind))
+		end := strings.Index(text[body:], Close(kind))
+		if end < 0 {
+			i = body
+			continue
+		}
+		end += body + len(Close(kind))
+		spans = append(spans, Span{Kind: kind, Start: open, End: end})
+		i = end
+	}
+	return spans
+}
+
+// kindAt returns the kind of mark opening at byte offset i of text.
+func kindAt(text string, i int) (Kind, bool) {
+	for kind, delims := range delimiters {
+		if strings.HasPrefix(text[i:], delims[0]) {
+			return kind, true
+		}
+	}
+	return 0, false
+}
+
+// At returns the mark holding pos, its delimiters included, or else the
+// one ending at pos.
+func At(marks []Mark, pos ast.BufferPos) (Mark, bool) {
+	for i, mark := range marks {
+		if !pos.Before(mark.Range.Start) && pos.Before(mark.Range.End) {
+			return mark, true
+		}
+		if mark.Range.End == pos && (i+1 == len(marks) || marks[i+1].Range.Start != pos) {
+			return mark, true
+		}
+	}
+	return Mark{}, false
+}
@@ -0,0 +1,124 @@
+// Package crypt keeps notes encrypted at rest with age or GnuPG.
+//
+// Both tools run as external commands. Text passes to and from them
+// through pipes, and the passphrase through a pipe of its own, so
+// plaintext is never written to disk.
+//
+// GnuPG notes use symmetric encryption with a passphrase. age notes use an
+// identity file instead, since age reads passphrases only from a terminal.
+package crypt
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// ErrNoIdentity is returned for age notes when no identity file is set.
+var ErrNoIdentity = errors.New("age notes need age_identity set")
+
+// Matches reports whether the base name of name matches one of patterns,
+// as filepath.Match does.
+func Matches(name string, patterns []string) bool {
+	base := filepath.Base(name)
+	for _, pattern := range patterns {
+		if ok, _ := filepath.Match(pattern, base); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// Cipher encrypts and decrypts one note.
+type Cipher struct {
+	age      bool
+	armor    bool   // ASCII output, for .asc notes
+	identity string // age identity file
+}
+
+// For returns the cipher for the note name: age for .age files, GnuPG
+// otherwise. identity is the age identity file.
+func For(name, identity string) Cipher {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".age":
+		return Cipher{age: true, identity: identity}
+	case ".asc":
+		return Cipher{armor: true}
+	default:
+		return Cipher{}
+	}
+}
+
+// NeedsPassphrase reports whether Encrypt and Decrypt take a passphrase.
+func (c Cipher) NeedsPassphrase() bool {
+	return !c.age
+}
+
+// Decrypt returns the plaintext of ciphertext.
+func (c Cipher) Decrypt(ctx context.Context, ciphertext []byte, passphrase string) ([]byte, error) {
+	if c.age {
+		if c.identity == "" {
+			return nil, ErrNoIdentity
+		}
+		return run(ctx, ciphertext, "", "age", "--decrypt", "-i", c.identity)
+	}
+	return run(ctx, ciphertext, passphrase, "gpg", gpgArgs("--decrypt")...)
+}
+
+// Encrypt returns plaintext encrypted.
+func (c Cipher) Encrypt(ctx context.Context, plaintext []byte, passphrase string) ([]byte, error) {
+	if c.age {
+		if c.identity == "" {
+			return nil, ErrNoIdentity
+		}
+		return run(ctx, plaintext, "", "age", "--encrypt", "-i", c.identity)
+	}
+	args := gpgArgs("--symmetric", "--cipher-algo", "AES256")
+	if c.armor {
+		args = append(args, "--armor")
+	}
+	return run(ctx, plaintext, passphrase, "gpg", args...)
+}
+
+// gpgArgs runs GnuPG without prompts, reading the passphrase from file
+// descriptor 3 and not caching it in the agent.
+func gpgArgs(args ...string) []string {
+	return append([]string{"--batch", "--yes", "--quiet", "--pinentry-mode", "loopback",
+		"--passphrase-fd", "3", "--no-symkey-cache", "--output", "-"}, args...)
+}
+
+// run pipes input through the command and returns its output. A non-empty
+// passphrase is passed on file descriptor 3.
+func run(ctx context.Context, input []byte, passphrase, name string, args ...string) ([]byte, error) {
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Stdin = bytes.NewReader(input)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if passphrase != "" {
+		r, w, err := os.Pipe()
+		if err != nil {
+			return nil, err
+		}
+		defer r.Close()
+		cmd.ExtraFiles = []*os.File{r}
+		go func() {
+			w.WriteString(passphrase)
+			w.Close()
+		}()
+	}
+
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("%s: %s", name, msg)
+		}
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	return stdout.Bytes(), nil
+}
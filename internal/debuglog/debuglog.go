@@ -0,0 +1,52 @@
+// Package debuglog records failures mde recovers from, so a plugin bug
+// can be reported without interrupting editing.
+//
+// Entries are appended to the file named by $MDE_DEBUG_LOG, or to
+// <user cache dir>/mde/debug.log (~/.cache/mde/debug.log on Linux).
+// Logging never fails loudly: if the file cannot be written the entry is
+// dropped.
+package debuglog
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+var mu sync.Mutex
+
+// Path returns the log file location, or "" if none can be determined.
+func Path() string {
+	if path := os.Getenv("MDE_DEBUG_LOG"); path != "" {
+		return path
+	}
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "mde", "debug.log")
+}
+
+// Printf appends a timestamped entry to the log.
+func Printf(format string, args ...interface{}) {
+	path := Path()
+	if path == "" {
+		return
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return
+	}
+	defer f.Close()
+
+	fmt.Fprintf(f, "%s %s\n", time.Now().Format(time.RFC3339), fmt.Sprintf(format, args...))
+}
@@ -0,0 +1,88 @@
+// Package diagram renders diagram fences (```mermaid and friends) to image
+// files through a user-configured external command.
+//
+// MDE does not render diagrams itself; the terminal preview shows their
+// source in a frame. A command such as the mermaid CLI can be configured to
+// produce an image instead:
+//
+//	diagram_command = "mmdc -i {input} -o {output}"
+//
+// Placeholders are substituted shell-quoted: {input} is a temporary file
+// holding the diagram source, {output} the image path to write and {lang}
+// the fence language.
+package diagram
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/ofri/mde/internal/shell"
+)
+
+// ErrNoCommand is returned when no diagram command is configured.
+var ErrNoCommand = errors.New("no diagram command configured")
+
+// OutputPath returns a stable image path for source, so re-rendering an
+// unchanged diagram overwrites the same file.
+func OutputPath(lang, source, format string) string {
+	if format == "" {
+		format = "svg"
+	}
+	sum := sha256.Sum256([]byte(lang + "\x00" + source))
+	name := "mde-" + lang + "-" + hex.EncodeToString(sum[:])[:12] + "." + format
+	return filepath.Join(os.TempDir(), name)
+}
+
+// Expand substitutes placeholders in command.
+func Expand(command, input, output, lang string) string {
+	return strings.NewReplacer(
+		"{input}", shell.Quote(input),
+		"{output}", shell.Quote(output),
+		"{lang}", shell.Quote(lang),
+	).Replace(command)
+}
+
+// Render writes source to a temporary file and runs command through the
+// shell to produce output. Command output is included in errors.
+func Render(ctx context.Context, command, lang, source, output string) error {
+	if strings.TrimSpace(command) == "" {
+		return ErrNoCommand
+	}
+
+	input, err := os.CreateTemp("", "mde-diagram-*."+lang)
+	if err != nil {
+		return fmt.Errorf("failed to create diagram source file: %w", err)
+	}
+	defer os.Remove(input.Name())
+
+	if _, err := input.WriteString(source); err != nil {
+		input.Close()
+		return fmt.Errorf("failed to write diagram source: %w", err)
+	}
+	if err := input.Close(); err != nil {
+		return fmt.Errorf("failed to write diagram source: %w", err)
+	}
+
+	cmd := exec.CommandContext(ctx, "sh", "-c", Expand(command, input.Name(), output, lang))
+	if out, err := cmd.CombinedOutput(); err != nil {
+		if msg := strings.TrimSpace(string(out)); msg != "" {
+			return fmt.Errorf("diagram command failed: %s", firstLine(msg))
+		}
+		return fmt.Errorf("diagram command failed: %w", err)
+	}
+	return nil
+}
+
+func firstLine(s string) string {
+	if i := strings.IndexByte(s, '\n'); i >= 0 {
+		return s[:i]
+	}
+	return s
+}
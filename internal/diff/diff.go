@@ -0,0 +1,155 @@
+// Package diff compares texts line by line, and changed lines rune by
+// rune, with Myers' algorithm, and pairs the results up for a side-by-side
+// display.
+package diff
+
+import "slices"
+
+// Op is the kind of an edit or row.
+type Op int
+
+const (
+	Equal  Op = iota
+	Delete    // Only in the first text
+	Insert    // Only in the second text
+	Change    // A row pairing a deleted line with an inserted one
+)
+
+// Edit is one step of a shortest edit script. A indexes the first text
+// and B the second; either is -1 when the step does not involve it.
+type Edit struct {
+	Op   Op
+	A, B int
+}
+
+// Row pairs a line of the first text with a line of the second for a
+// side-by-side view. A or B is -1 when that side has no line in the row.
+type Row struct {
+	Op   Op
+	A, B int
+}
+
+// Span is a range of runes [Start, End) within a line.
+type Span struct {
+	Start, End int
+}
+
+// Edits returns a shortest edit script turning a into b.
+func Edits[T comparable](a, b []T) []Edit {
+	n, m := len(a), len(b)
+	limit := n + m
+	offset := limit + 1
+	v := make([]int, 2*limit+3)
+
+	// trace[d] holds v for diagonals -d-1..d+1 as it was before round d
+	var trace [][]int
+	for d := 0; d <= limit; d++ {
+		trace = append(trace, slices.Clone(v[offset-d-1:offset+d+2]))
+		for k := -d; k <= d; k += 2 {
+			x := v[offset+k-1] + 1
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1]
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[offset+k] = x
+			if x >= n && y >= m {
+				return backtrack(trace, n, m)
+			}
+		}
+	}
+	return nil // Unreachable: d = n+m always reaches the end
+}
+
+// backtrack walks the trace from the end of both texts to their start,
+// collecting the edits of the path found.
+func backtrack(trace [][]int, n, m int) []Edit {
+	var edits []Edit
+	x, y := n, m
+	for d := len(trace) - 1; d >= 0; d-- {
+		v := func(k int) int { return trace[d][k+d+1] }
+		k := x - y
+		prevK := k - 1
+		if k == -d || (k != d && v(k-1) < v(k+1)) {
+			prevK = k + 1
+		}
+		prevX := v(prevK)
+		prevY := prevX - prevK
+
+		for x > prevX && y > prevY && x > 0 && y > 0 {
+			x--
+			y--
+			edits = append(edits, Edit{Op: Equal, A: x, B: y})
+		}
+		if d == 0 {
+			break
+		}
+		if x == prevX {
+			edits = append(edits, Edit{Op: Insert, A: -1, B: y - 1})
+		} else {
+			edits = append(edits, Edit{Op: Delete, A: x - 1, B: -1})
+		}
+		x, y = prevX, prevY
+	}
+	slices.Reverse(edits)
+	return edits
+}
+
+// SideBySide lines up a and b for display in two columns. Deleted and
+// inserted lines between the same unchanged lines are paired into Change
+// rows, the surplus of either kind getting rows of its own.
+func SideBySide(a, b []string) []Row {
+	var rows []Row
+	var deleted, inserted []int
+	flush := func() {
+		for i := 0; i < max(len(deleted), len(inserted)); i++ {
+			switch {
+			case i >= len(inserted):
+				rows = append(rows, Row{Op: Delete, A: deleted[i], B: -1})
+			case i >= len(deleted):
+				rows = append(rows, Row{Op: Insert, A: -1, B: inserted[i]})
+			default:
+				rows = append(rows, Row{Op: Change, A: deleted[i], B: inserted[i]})
+			}
+		}
+		deleted, inserted = deleted[:0], inserted[:0]
+	}
+
+	for _, edit := range Edits(a, b) {
+		switch edit.Op {
+		case Delete:
+			deleted = append(deleted, edit.A)
+		case Insert:
+			inserted = append(inserted, edit.B)
+		default:
+			flush()
+			rows = append(rows, Row{Op: Equal, A: edit.A, B: edit.B})
+		}
+	}
+	flush()
+	return rows
+}
+
+// Intraline finds the runes that differ between two versions of a line:
+// the spans removed from a and those added in b.
+func Intraline(a, b string) (removed, added []Span) {
+	extend := func(spans []Span, i int) []Span {
+		if last := len(spans) - 1; last >= 0 && spans[last].End == i {
+			spans[last].End++
+			return spans
+		}
+		return append(spans, Span{Start: i, End: i + 1})
+	}
+	for _, edit := range Edits([]rune(a), []rune(b)) {
+		switch edit.Op {
+		case Delete:
+			removed = extend(removed, edit.A)
+		case Insert:
+			added = extend(added, edit.B)
+		}
+	}
+	return removed, added
+}
@@ -0,0 +1,369 @@
+// Package editorconfig reads the .editorconfig files that apply to a file,
+// as described at https://editorconfig.org.
+//
+// The files are found by walking up from the file's directory, stopping
+// at one that declares root = true. Sections match files by glob, relative
+// to the directory of the .editorconfig they are in:
+//
+//	root = true
+//
+//	[*.md]
+//	indent_style = space
+//	indent_size = 2
+//	trim_trailing_whitespace = false
+//
+// Nearer files, and later sections, win. Of the properties only those mde
+// acts on are read: indent_style, indent_size (and tab_width),
+// trim_trailing_whitespace, insert_final_newline and end_of_line.
+package editorconfig
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/ofri/mde/internal/config"
+)
+
+// Filename names an EditorConfig file.
+const Filename = ".editorconfig"
+
+// Settings are the properties that apply to a file. The zero value of each
+// leaves it unset.
+type Settings struct {
+	IndentStyle            string // "tab" or "space"
+	IndentSize             int
+	TrimTrailingWhitespace *bool
+	InsertFinalNewline     *bool
+	EndOfLine              string // "lf", "crlf" or "cr"
+}
+
+// Empty reports whether no property is set.
+func (s Settings) Empty() bool {
+	return s == Settings{}
+}
+
+// LineEnding returns the text end_of_line ends lines with, or "" when it
+// is unset.
+func (s Settings) LineEnding() string {
+	switch s.EndOfLine {
+	case "lf":
+		return "\n"
+	case "crlf":
+		return "\r\n"
+	case "cr":
+		return "\r"
+	}
+	return ""
+}
+
+// Apply sets the settings in cfg where the user's config file left them
+// to their defaults, returning the function that puts cfg's own back:
+// indent_size as tab_width, indent_style, and trim_trailing_whitespace as
+// strip_trailing_whitespace.
+func (s Settings) Apply(cfg *config.Config) (restore func()) {
+	tabWidth, indentStyle, strip := cfg.TabWidth, cfg.IndentStyle, cfg.StripTrailingWhitespace
+	if s.IndentSize > 0 && !cfg.IsSet("tab_width") {
+		cfg.TabWidth = s.IndentSize
+	}
+	if s.IndentStyle != "" && !cfg.IsSet("indent_style") {
+		cfg.IndentStyle = s.IndentStyle
+	}
+	if s.TrimTrailingWhitespace != nil && !cfg.IsSet("strip_trailing_whitespace") {
+		cfg.StripTrailingWhitespace = *s.TrimTrailingWhitespace
+	}
+	return func() {
+		cfg.TabWidth, cfg.IndentStyle, cfg.StripTrailingWhitespace = tabWidth, indentStyle, strip
+	}
+}
+
+// Lookup returns the settings for the file at path from the .editorconfig
+// files above it. Having none is not an error.
+func Lookup(path string) (Settings, error) {
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return Settings{}, err
+	}
+
+	// Nearest first, then applied from the root down
+	var files []*file
+	for dir := filepath.Dir(path); ; {
+		f, err := parse(filepath.Join(dir, Filename))
+		if err != nil {
+			return Settings{}, err
+		}
+		if f != nil {
+			files = append(files, f)
+			if f.root {
+				break
+			}
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			break
+		}
+		dir = parent
+	}
+
+	properties := make(map[string]string)
+	target := filepath.ToSlash(path)
+	for i := len(files) - 1; i >= 0; i-- {
+		for _, section := range files[i].sections {
+			if section.pattern.MatchString(target) {
+				for key, value := range section.properties {
+					properties[key] = value
+				}
+			}
+		}
+	}
+	return settingsOf(properties), nil
+}
+
+// settingsOf reads the properties mde acts on. Values it does not know,
+// and "unset", leave a property unset.
+func settingsOf(properties map[string]string) Settings {
+	var s Settings
+	if style := properties["indent_style"]; style == "tab" || style == "space" {
+		s.IndentStyle = style
+	}
+	size := properties["indent_size"]
+	if size == "tab" {
+		size = properties["tab_width"]
+	}
+	if n, err := strconv.Atoi(size); err == nil && n > 0 {
+		s.IndentSize = n
+	} else if n, err := strconv.Atoi(properties["tab_width"]); err == nil && n > 0 {
+		s.IndentSize = n
+	}
+	s.TrimTrailingWhitespace = boolOf(properties["trim_trailing_whitespace"])
+	s.InsertFinalNewline = boolOf(properties["insert_final_newline"])
+	if eol := properties["end_of_line"]; eol == "lf" || eol == "crlf" || eol == "cr" {
+		s.EndOfLine = eol
+	}
+	return s
+}
+
+// boolOf returns the boolean value, or nil for any other.
+func boolOf(value string) *bool {
+	switch value {
+	case "true":
+		b := true
+		return &b
+	case "false":
+		b := false
+		return &b
+	}
+	return nil
+}
+
+// file is a parsed .editorconfig.
+type file struct {
+	root     bool
+	sections []section
+}
+
+// section is a glob's properties, keys and values in lower case.
+type section struct {
+	pattern    *regexp.Regexp
+	properties map[string]string
+}
+
+// parse reads the .editorconfig at path, or returns nil if there is none.
+// Lines it cannot read are skipped, as other editors do.
+func parse(path string) (*file, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	f := &file{}
+	dir := filepath.ToSlash(filepath.Dir(path))
+	var current map[string]string // The properties of the section read
+	scanner := bufio.NewScanner(strings.NewReader(string(data)))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case line == "" || line[0] == '#' || line[0] == ';':
+		case line[0] == '[' && line[len(line)-1] == ']':
+			pattern, err := compile(dir, line[1:len(line)-1])
+			if err != nil {
+				current = nil // Its properties apply to nothing
+				continue
+			}
+			current = make(map[string]string)
+			f.sections = append(f.sections, section{pattern: pattern, properties: current})
+		default:
+			key, value, ok := strings.Cut(line, "=")
+			if !ok {
+				continue
+			}
+			key = strings.ToLower(strings.TrimSpace(key))
+			value = strings.ToLower(strings.TrimSpace(value))
+			if current != nil {
+				current[key] = value
+			} else if key == "root" {
+				f.root = value == "true"
+			}
+		}
+	}
+	return f, scanner.Err()
+}
+
+// compile turns a section's glob into a regular expression matching the
+// absolute, slash-separated paths of the files it covers. A glob with no
+// slash matches the file name in any directory below dir.
+func compile(dir, glob string) (*regexp.Regexp, error) {
+	prefix := regexp.QuoteMeta(strings.TrimSuffix(dir, "/")) + "/"
+	if strings.Contains(glob, "/") {
+		glob = strings.TrimPrefix(glob, "/")
+	} else {
+		prefix += "(?:.*/)?"
+	}
+	expr, err := translate(glob)
+	if err != nil {
+		return nil, err
+	}
+	return regexp.Compile("^" + prefix + expr + "$")
+}
+
+// translate turns glob into a regular expression: * matches within a
+// path segment and ** across them, ? one character, [abc] and [!abc] one
+// of a set or not, {a,b} either of the alternatives and {1..3} a number
+// in the range.
+func translate(glob string) (string, error) {
+	var b strings.Builder
+	runes := []rune(glob)
+	braces := 0
+	for i := 0; i < len(runes); i++ {
+		switch r := runes[i]; r {
+		case '\\':
+			if i+1 < len(runes) {
+				i++
+				b.WriteString(regexp.QuoteMeta(string(runes[i])))
+			}
+		case '*':
+			if i+1 < len(runes) && runes[i+1] == '*' {
+				b.WriteString(".*")
+				i++
+			} else {
+				b.WriteString("[^/]*")
+			}
+		case '?':
+			b.WriteString("[^/]")
+		case '[':
+			end := i + 1
+			for end < len(runes) && runes[end] != ']' {
+				end++
+			}
+			if end == len(runes) {
+				b.WriteString(`\[`)
+				continue
+			}
+			class := string(runes[i+1 : end])
+			i = end
+			negate := strings.HasPrefix(class, "!")
+			class = strings.TrimPrefix(class, "!")
+			b.WriteString("[")
+			if negate {
+				b.WriteString("^")
+			}
+			b.WriteString(strings.ReplaceAll(class, `\`, `\\`))
+			b.WriteString("]")
+		case '{':
+			end := closingBrace(runes, i)
+			if end < 0 {
+				b.WriteString(`\{`)
+				continue
+			}
+			inner := string(runes[i+1 : end])
+			if lo, hi, ok := numericRange(inner); ok {
+				b.WriteString(rangeExpr(lo, hi))
+				i = end
+				continue
+			}
+			if !strings.Contains(inner, ",") {
+				b.WriteString(`\{`)
+				continue
+			}
+			b.WriteString("(?:")
+			braces++
+		case ',':
+			if braces > 0 {
+				b.WriteString("|")
+			} else {
+				b.WriteString(",")
+			}
+		case '}':
+			if braces > 0 {
+				b.WriteString(")")
+				braces--
+			} else {
+				b.WriteString(`\}`)
+			}
+		default:
+			b.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	if braces > 0 {
+		return "", fmt.Errorf("unclosed { in %q", glob)
+	}
+	return b.String(), nil
+}
+
+// closingBrace returns the index of the } closing the { at open, or -1.
+func closingBrace(runes []rune, open int) int {
+	depth := 0
+	for i := open; i < len(runes); i++ {
+		switch runes[i] {
+		case '\\':
+			i++
+		case '{':
+			depth++
+		case '}':
+			if depth--; depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// maxRange is the most numbers a {lo..hi} glob matches, beyond which it
+// is taken as written.
+const maxRange = 1000
+
+// numericRange parses "lo..hi", the inside of a {lo..hi} glob.
+func numericRange(inner string) (lo, hi int, ok bool) {
+	from, to, found := strings.Cut(inner, "..")
+	if !found {
+		return 0, 0, false
+	}
+	lo, err := strconv.Atoi(from)
+	if err != nil {
+		return 0, 0, false
+	}
+	hi, err = strconv.Atoi(to)
+	if err != nil {
+		return 0, 0, false
+	}
+	lo, hi = min(lo, hi), max(lo, hi)
+	return lo, hi, hi-lo < maxRange
+}
+
+// rangeExpr matches the numbers from lo to hi.
+func rangeExpr(lo, hi int) string {
+	numbers := make([]string, 0, hi-lo+1)
+	for n := lo; n <= hi; n++ {
+		numbers = append(numbers, strconv.Itoa(n))
+	}
+	return "(?:" + strings.Join(numbers, "|") + ")"
+}
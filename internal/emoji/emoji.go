@@ -0,0 +1,110 @@
+// Package emoji resolves GitHub-style :shortcode: emoji.
+//
+// Shortcodes come from goldmark-emoji's GitHub definition, the same table
+// the HTML export uses, so :smile: looks the same in preview and output.
+// Only known shortcodes are recognized; "10:30:00" is not an emoji.
+package emoji
+
+//go:generate go run gen/main.go
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/yuin/goldmark-emoji/definition"
+)
+
+var shortcodeRe = regexp.MustCompile(`:([a-z0-9_+\-]+):`)
+
+// Shortcode is a recognized :name: within a line.
+// Start and End are byte offsets including the colons.
+type Shortcode struct {
+	Start int
+	End   int
+	Name  string
+}
+
+// Lookup returns the emoji for a shortcode name (without colons).
+func Lookup(name string) (string, bool) {
+	emoji, ok := definition.Github().Get(name)
+	if !ok || !emoji.IsUnicode() {
+		return "", false
+	}
+	return string(emoji.Unicode), true
+}
+
+// Names returns all shortcode names, sorted.
+func Names() []string {
+	return names
+}
+
+// Complete returns the shortcode names starting with prefix, sorted.
+func Complete(prefix string) []string {
+	start := sort.SearchStrings(names, prefix)
+	end := start
+	for end < len(names) && strings.HasPrefix(names[end], prefix) {
+		end++
+	}
+	return names[start:end]
+}
+
+// FindShortcodes returns the known shortcodes in line.
+func FindShortcodes(line string) []Shortcode {
+	var found []Shortcode
+	for offset := 0; offset < len(line); {
+		m := shortcodeRe.FindStringSubmatchIndex(line[offset:])
+		if m == nil {
+			break
+		}
+		name := line[offset+m[2] : offset+m[3]]
+		if _, ok := Lookup(name); ok {
+			found = append(found, Shortcode{Start: offset + m[0], End: offset + m[1], Name: name})
+			offset += m[1]
+			continue
+		}
+		// The closing colon may open the next shortcode: "10:30:smile:"
+		offset += m[3]
+	}
+	return found
+}
+
+// PartialAt reports whether rune column col sits right after a partially
+// typed ":name", returning the name typed so far.
+func PartialAt(line string, col int) (string, bool) {
+	before := line
+	for i := 0; i < len(line); {
+		if col == 0 {
+			before = line[:i]
+			break
+		}
+		_, size := utf8.DecodeRuneInString(line[i:])
+		i += size
+		col--
+	}
+
+	colon := strings.LastIndexByte(before, ':')
+	if colon < 0 {
+		return "", false
+	}
+	// Require a word boundary before the colon so "10:3" does not complete
+	if colon > 0 && isNameByte(before[colon-1]) {
+		return "", false
+	}
+
+	partial := before[colon+1:]
+	if partial == "" {
+		return "", false
+	}
+	for i := 0; i < len(partial); i++ {
+		if !isNameByte(partial[i]) {
+			return "", false
+		}
+	}
+	return partial, true
+}
+
+func isNameByte(b byte) bool {
+	return (b >= 'a' && b <= 'z') || (b >= '0' && b <= '9') || b == '_' || b == '+' || b == '-'
+}
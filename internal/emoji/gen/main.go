@@ -0,0 +1,54 @@
+//go:build ignore
+
+// Command gen writes names_gen.go, the sorted list of shortcodes known to
+// goldmark-emoji's GitHub definition. The definition exposes lookups but
+// not enumeration, so the list is read once here and checked in.
+//
+// Usage: go generate ./internal/emoji
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"log"
+	"os"
+	"reflect"
+	"sort"
+
+	"github.com/yuin/goldmark-emoji/definition"
+)
+
+func main() {
+	list := reflect.ValueOf(definition.Github()).Elem().FieldByName("list")
+	if !list.IsValid() {
+		log.Fatal("goldmark-emoji definition layout changed; update the generator")
+	}
+
+	var names []string
+	for i := 0; i < list.Len(); i++ {
+		shortNames := list.Index(i).FieldByName("ShortNames")
+		for j := 0; j < shortNames.Len(); j++ {
+			names = append(names, shortNames.Index(j).String())
+		}
+	}
+	sort.Strings(names)
+
+	var buf bytes.Buffer
+	buf.WriteString("// Code generated by gen/main.go; DO NOT EDIT.\n\n")
+	buf.WriteString("package emoji\n\n")
+	buf.WriteString("// names lists every shortcode in the GitHub emoji definition, sorted.\n")
+	buf.WriteString("var names = []string{\n")
+	for _, name := range names {
+		fmt.Fprintf(&buf, "\t%q,\n", name)
+	}
+	buf.WriteString("}\n")
+
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := os.WriteFile("names_gen.go", src, 0644); err != nil {
+		log.Fatal(err)
+	}
+}
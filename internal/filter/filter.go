@@ -0,0 +1,112 @@
+// Package filter pipes text through shell commands, as vi's ! does, so
+// any tool (sort, jq, pandoc, ...) can rewrite part of a document, and
+// captures the output of code run by an interpreter.
+package filter
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/ofri/mde/internal/shell"
+)
+
+// ErrTimeout is returned when the command outlives its context's deadline.
+var ErrTimeout = errors.New("command timed out")
+
+// waitDelay bounds how long a killed command's children may hold its
+// output open.
+const waitDelay = time.Second
+
+// Run runs command through sh with input on stdin and returns its stdout.
+// A command that fails reports the last line of its stderr.
+//
+// Text without a final newline gets one for the command, and loses it
+// again from the output, so a filtered part of a line stays on its line.
+func Run(ctx context.Context, command, input string) (string, error) {
+	if strings.TrimSpace(command) == "" {
+		return "", errors.New("no command given")
+	}
+	partial := !strings.HasSuffix(input, "\n")
+	if partial {
+		input += "\n"
+	}
+
+	cmd := exec.CommandContext(ctx, "sh", "-c", command)
+	cmd.Stdin = strings.NewReader(input)
+	cmd.WaitDelay = waitDelay
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return "", ErrTimeout
+		}
+		if msg := lastLine(stderr.String()); msg != "" {
+			return "", fmt.Errorf("%w: %s", err, msg)
+		}
+		return "", err
+	}
+
+	output := stdout.String()
+	if partial {
+		output = strings.TrimSuffix(output, "\n")
+	}
+	return output, nil
+}
+
+func lastLine(s string) string {
+	s = strings.TrimSpace(s)
+	if i := strings.LastIndexByte(s, '\n'); i >= 0 {
+		return s[i+1:]
+	}
+	return s
+}
+
+// Capture runs command through sh like Run, but returns its stdout and
+// stderr interleaved as a terminal would show them, even when it fails.
+// A {file} placeholder in command is replaced by a temporary file holding
+// input, named with ext, for interpreters that will not read stdin.
+func Capture(ctx context.Context, command, input, ext string) (string, error) {
+	if strings.TrimSpace(command) == "" {
+		return "", errors.New("no command given")
+	}
+
+	var stdin io.Reader = strings.NewReader(input)
+	if strings.Contains(command, "{file}") {
+		file, err := os.CreateTemp("", "mde-run-*"+ext)
+		if err != nil {
+			return "", err
+		}
+		defer os.Remove(file.Name())
+		_, err = file.WriteString(input)
+		if closeErr := file.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			return "", err
+		}
+		command = strings.ReplaceAll(command, "{file}", shell.Quote(file.Name()))
+		stdin = nil
+	}
+
+	cmd := exec.CommandContext(ctx, "sh", "-c", command)
+	cmd.Stdin = stdin
+	cmd.WaitDelay = waitDelay
+	var output bytes.Buffer
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+
+	err := cmd.Run()
+	if err != nil && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		err = ErrTimeout
+	}
+	return output.String(), err
+}
@@ -0,0 +1,361 @@
+// Package glossary reads glossaries of terms and their definitions, finds
+// the terms in text, and reports acronyms left undefined.
+//
+// A glossary is a markdown file of abbreviations, written as in PHP
+// Markdown Extra, and definition lists:
+//
+//	*[API]: Application Programming Interface
+//
+//	Event loop
+//	: The loop that runs queued callbacks, one at a time
+//
+// A document's own abbreviations and definition lists add to the glossary
+// it is read with. Terms in
+// capitals, such as acronyms, match as written; others match in any case.
+// Either matches with a plural "s".
+package glossary
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/ofri/mde/internal/links"
+	"github.com/ofri/mde/internal/lint"
+	"github.com/ofri/mde/pkg/ast"
+)
+
+// abbreviationRe matches an abbreviation definition, "*[API]: text".
+var abbreviationRe = regexp.MustCompile(`^ {0,3}\*\[([^\]]+)\]:[ \t]*(.*)$`)
+
+// acronymRe matches words of two or more capitals, digits among them, as
+// "API", "HTTP2" or "APIs".
+var acronymRe = regexp.MustCompile(`\b[A-Z][A-Z0-9]*[A-Z][A-Z0-9]*s?\b`)
+
+// romanRe matches roman numerals, which read as acronyms.
+var romanRe = regexp.MustCompile(`^[IVXLCDM]+$`)
+
+// Term is a glossary entry.
+type Term struct {
+	Name       string
+	Definition string
+}
+
+// Match is a term, or an acronym, found in a line. Start and End are byte
+// offsets.
+type Match struct {
+	Start int
+	End   int
+	Term  Term
+}
+
+// Glossary is a set of terms.
+type Glossary struct {
+	terms  []Term
+	byKey  map[string]int
+	exact  *regexp.Regexp // Terms in capitals
+	folded *regexp.Regexp // Other terms, in any case
+}
+
+// New returns a glossary of terms; a later definition of a term replaces
+// an earlier one.
+func New(terms []Term) *Glossary {
+	g := &Glossary{byKey: map[string]int{}}
+	for _, term := range terms {
+		term.Name = strings.TrimSpace(term.Name)
+		if term.Name == "" {
+			continue
+		}
+		if i, ok := g.byKey[key(term.Name)]; ok {
+			g.terms[i] = term
+			continue
+		}
+		g.byKey[key(term.Name)] = len(g.terms)
+		g.terms = append(g.terms, term)
+	}
+
+	var exact, folded []string
+	for _, term := range g.terms {
+		if capitals(term.Name) {
+			exact = append(exact, regexp.QuoteMeta(term.Name))
+		} else {
+			folded = append(folded, regexp.QuoteMeta(term.Name))
+		}
+	}
+	g.exact = alternation(exact, "")
+	g.folded = alternation(folded, "(?i)")
+	return g
+}
+
+// alternation compiles a regexp matching any of the quoted names, longest
+// first, or returns nil when there are none. Find checks matches are
+// whole words.
+func alternation(names []string, flags string) *regexp.Regexp {
+	if len(names) == 0 {
+		return nil
+	}
+	sort.Slice(names, func(i, j int) bool { return len(names[i]) > len(names[j]) })
+	return regexp.MustCompile(flags + `(?:` + strings.Join(names, "|") + `)s?`)
+}
+
+// Parse reads the terms of a glossary written in markdown.
+func Parse(text string) []Term {
+	var terms []Term
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		if m := abbreviationRe.FindStringSubmatch(line); m != nil {
+			terms = append(terms, Term{Name: m[1], Definition: strings.TrimSpace(m[2])})
+			continue
+		}
+		if _, content, ok := ast.DefinitionMarker(line); ok {
+			// The term is the nearest non-blank line above, itself no
+			// definition; further definitions of it are joined
+			for j := i - 1; j >= 0 && j >= i-2; j-- {
+				above := strings.TrimSpace(lines[j])
+				if above == "" {
+					continue
+				}
+				if _, _, ok := ast.DefinitionMarker(lines[j]); ok && len(terms) > 0 {
+					last := &terms[len(terms)-1]
+					last.Definition += "; " + strings.TrimSpace(line[content:])
+				} else if !ok {
+					terms = append(terms, Term{Name: above, Definition: strings.TrimSpace(line[content:])})
+				}
+				break
+			}
+		}
+	}
+	return terms
+}
+
+// Load reads the glossary at path.
+func Load(path string) ([]Term, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return Parse(string(data)), nil
+}
+
+// Len returns the number of terms.
+func (g *Glossary) Len() int {
+	if g == nil {
+		return 0
+	}
+	return len(g.terms)
+}
+
+// Lookup returns the term text names, as written in a document: in any
+// case unless it is in capitals, and maybe plural.
+func (g *Glossary) Lookup(text string) (Term, bool) {
+	if g == nil {
+		return Term{}, false
+	}
+	for _, candidate := range []string{text, strings.TrimSuffix(text, "s")} {
+		if i, ok := g.byKey[key(candidate)]; ok && (!capitals(g.terms[i].Name) || g.terms[i].Name == candidate) {
+			return g.terms[i], true
+		}
+	}
+	return Term{}, false
+}
+
+// Find returns the terms in line, leaving out code spans, link
+// destinations and HTML tags.
+func (g *Glossary) Find(line string) []Match {
+	if g == nil {
+		return nil
+	}
+	prose := mask(line)
+	var matches []Match
+	for _, re := range []*regexp.Regexp{g.exact, g.folded} {
+		if re == nil {
+			continue
+		}
+		for _, m := range re.FindAllStringIndex(prose, -1) {
+			if !wordBoundary(line, m[0]) || !wordBoundary(line, m[1]) {
+				continue // Part of a longer word
+			}
+			if term, ok := g.Lookup(line[m[0]:m[1]]); ok {
+				matches = append(matches, Match{Start: m[0], End: m[1], Term: term})
+			}
+		}
+	}
+	sort.Slice(matches, func(i, j int) bool { return matches[i].Start < matches[j].Start })
+
+	// A longer term wins over one overlapping it
+	var kept []Match
+	for _, match := range matches {
+		if n := len(kept); n > 0 && match.Start < kept[n-1].End {
+			if match.End-match.Start > kept[n-1].End-kept[n-1].Start {
+				kept[n-1] = match
+			}
+			continue
+		}
+		kept = append(kept, match)
+	}
+	return kept
+}
+
+// At returns the term under rune column col of line.
+func (g *Glossary) At(line string, col int) (Match, bool) {
+	offset := ast.ByteOffset(line, col)
+	for _, match := range g.Find(line) {
+		if offset >= match.Start && offset < match.End {
+			return match, true
+		}
+	}
+	return Match{}, false
+}
+
+// Acronyms returns the acronyms in line, outside code, link destinations
+// and HTML tags, with their plural "s" dropped from the name.
+func Acronyms(line string) []Match {
+	var matches []Match
+	for _, m := range acronymRe.FindAllStringIndex(mask(line), -1) {
+		name := strings.TrimSuffix(line[m[0]:m[1]], "s")
+		if romanRe.MatchString(name) {
+			continue
+		}
+		matches = append(matches, Match{Start: m[0], End: m[1], Term: Term{Name: name}})
+	}
+	return matches
+}
+
+// Defined returns the acronyms doc defines: as glossary terms of its own,
+// or spelled out with the acronym in parentheses, as in "Application
+// Programming Interface (API)".
+func Defined(doc *ast.Document) map[string]bool {
+	defined := map[string]bool{}
+	for _, term := range Parse(doc.GetText()) {
+		defined[strings.TrimSpace(term.Name)] = true
+	}
+	for i := 0; i < doc.LineCount(); i++ {
+		line := doc.GetLine(i)
+		for _, acronym := range Acronyms(line) {
+			if acronym.Start > 0 && line[acronym.Start-1] == '(' && acronym.End < len(line) && line[acronym.End] == ')' {
+				defined[acronym.Term.Name] = true
+			}
+		}
+	}
+	return defined
+}
+
+// Rule names Check's lint rule, to turn it off in the config.
+const Rule = "undefined-acronym"
+
+// Check returns a warning at the first use of each acronym that neither
+// g nor doc defines, outside code blocks; g may be nil.
+func (g *Glossary) Check(doc *ast.Document) []lint.Diagnostic {
+	defined := Defined(doc)
+	warned := map[string]bool{}
+	var diagnostics []lint.Diagnostic
+	fences := doc.FencedBlocks()
+	for i := 0; i < doc.LineCount(); i++ {
+		for len(fences) > 0 && fences[0].EndLine < i {
+			fences = fences[1:]
+		}
+		line := doc.GetLine(i)
+		if (len(fences) > 0 && fences[0].Contains(i)) || abbreviationRe.MatchString(line) {
+			continue
+		}
+		for _, acronym := range Acronyms(line) {
+			name := acronym.Term.Name
+			if defined[name] || warned[name] {
+				continue
+			}
+			if _, ok := g.Lookup(name); ok {
+				continue
+			}
+			warned[name] = true
+			diagnostics = append(diagnostics, lint.Diagnostic{
+				Range: ast.Range{
+					Start: ast.BufferPos{Line: i, Col: ast.RuneOffset(line, acronym.Start)},
+					End:   ast.BufferPos{Line: i, Col: ast.RuneOffset(line, acronym.End)},
+				},
+				Severity: lint.Warning,
+				Message:  fmt.Sprintf("Undefined acronym %s: spell it out at first use or add it to the glossary", name),
+				Rule:     Rule,
+			})
+		}
+	}
+	return diagnostics
+}
+
+// mask blanks the parts of line that are not prose, keeping offsets.
+func mask(line string) string {
+	masked := []byte(line)
+	blank := func(start, end int) {
+		for i := start; i < end && i < len(masked); i++ {
+			masked[i] = ' '
+		}
+	}
+	for _, link := range links.Find(line) {
+		switch link.Kind {
+		case links.Inline, links.Definition:
+			blank(link.URLStart, link.URLEnd)
+		case links.Autolink, links.Bare:
+			blank(link.Start, link.End)
+		}
+	}
+	for _, tag := range ast.FindHTMLTags(line) {
+		blank(tag.Start, tag.End)
+	}
+	for i := 0; i < len(line); {
+		if line[i] != '`' {
+			i++
+			continue
+		}
+		run := i
+		for run < len(line) && line[run] == '`' {
+			run++
+		}
+		fence := line[i:run]
+		end := strings.Index(line[run:], fence)
+		if end < 0 {
+			i = run
+			continue
+		}
+		blank(i, run+end+len(fence))
+		i = run + end + len(fence)
+	}
+	return string(masked)
+}
+
+// key is how a term is looked up: as written when in capitals, lowercased
+// otherwise.
+func key(name string) string {
+	if capitals(name) {
+		return name
+	}
+	return strings.ToLower(name)
+}
+
+// capitals reports whether name has letters, all of them capitals.
+func capitals(name string) bool {
+	letters := false
+	for _, r := range name {
+		if unicode.IsLetter(r) {
+			if !unicode.IsUpper(r) {
+				return false
+			}
+			letters = true
+		}
+	}
+	return letters
+}
+
+// wordBoundary reports whether byte offset i of line is not inside a
+// word.
+func wordBoundary(line string, i int) bool {
+	before, _ := utf8.DecodeLastRuneInString(line[:i])
+	after, _ := utf8.DecodeRuneInString(line[i:])
+	return !wordRune(before) || !wordRune(after)
+}
+
+func wordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_'
+}
@@ -0,0 +1,154 @@
+// Package history keeps a snapshot of a file each time it is saved, so
+// an earlier version can be compared with the current one and restored.
+//
+// Each file's snapshots live in a directory of their own under the
+// history directory, named by a hash of the file's absolute path, as
+// <unix nanoseconds>.snap files; a "path" file in it names the file they
+// belong to. Saving text identical to the latest snapshot adds none, and
+// the oldest snapshots are dropped once a file has more than the limits
+// allow.
+package history
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// suffix ends the name of every snapshot file.
+const suffix = ".snap"
+
+// Limits bound the snapshots kept for a file; zero means no limit. The
+// latest snapshot is kept whatever its size.
+type Limits struct {
+	Count int   // Snapshots
+	Bytes int64 // Their total size
+}
+
+// Snapshot is a saved version of a file.
+type Snapshot struct {
+	Path string // The snapshot file
+	Time time.Time
+	Size int64
+}
+
+// Read returns the text of the file as it was saved.
+func (s Snapshot) Read() ([]byte, error) {
+	return os.ReadFile(s.Path)
+}
+
+// Save records content as the latest version of file in the history
+// directory dir, then drops the snapshots beyond limits. Reports whether
+// a snapshot was added: none is when content is the same as the latest.
+func Save(dir, file string, content []byte, limits Limits) (bool, error) {
+	fileDir, err := snapshotDir(dir, file)
+	if err != nil {
+		return false, err
+	}
+	snapshots, err := list(fileDir)
+	if err != nil {
+		return false, err
+	}
+	if len(snapshots) > 0 {
+		if latest, err := snapshots[0].Read(); err == nil && bytes.Equal(latest, content) {
+			return false, nil
+		}
+	}
+
+	if err := os.MkdirAll(fileDir, 0o700); err != nil {
+		return false, fmt.Errorf("failed to create history directory: %w", err)
+	}
+	abs, _ := filepath.Abs(file)
+	if err := os.WriteFile(filepath.Join(fileDir, "path"), []byte(abs+"\n"), 0o600); err != nil {
+		return false, fmt.Errorf("failed to write history: %w", err)
+	}
+
+	// Saves within the same nanosecond, on coarse clocks, take the next one
+	stamp := time.Now().UnixNano()
+	if len(snapshots) > 0 && stamp <= snapshots[0].Time.UnixNano() {
+		stamp = snapshots[0].Time.UnixNano() + 1
+	}
+	path := filepath.Join(fileDir, strconv.FormatInt(stamp, 10)+suffix)
+	if err := os.WriteFile(path, content, 0o600); err != nil {
+		return false, fmt.Errorf("failed to write snapshot: %w", err)
+	}
+
+	snapshots = append([]Snapshot{{Path: path, Time: time.Unix(0, stamp), Size: int64(len(content))}}, snapshots...)
+	for _, snapshot := range expired(snapshots, limits) {
+		os.Remove(snapshot.Path)
+	}
+	return true, nil
+}
+
+// List returns the snapshots of file in the history directory dir, the
+// latest first.
+func List(dir, file string) ([]Snapshot, error) {
+	fileDir, err := snapshotDir(dir, file)
+	if err != nil {
+		return nil, err
+	}
+	return list(fileDir)
+}
+
+// snapshotDir returns the directory holding the snapshots of file.
+func snapshotDir(dir, file string) (string, error) {
+	if dir == "" {
+		return "", errors.New("no history directory")
+	}
+	abs, err := filepath.Abs(file)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256([]byte(abs))
+	return filepath.Join(dir, hex.EncodeToString(sum[:8])), nil
+}
+
+// list returns the snapshots in fileDir, the latest first. A directory
+// that does not exist yet holds none.
+func list(fileDir string) ([]Snapshot, error) {
+	entries, err := os.ReadDir(fileDir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read history: %w", err)
+	}
+
+	var snapshots []Snapshot
+	for _, entry := range entries {
+		name := entry.Name()
+		stamp, err := strconv.ParseInt(strings.TrimSuffix(name, suffix), 10, 64)
+		if !strings.HasSuffix(name, suffix) || err != nil {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		snapshots = append(snapshots, Snapshot{Path: filepath.Join(fileDir, name), Time: time.Unix(0, stamp), Size: info.Size()})
+	}
+	slices.SortFunc(snapshots, func(a, b Snapshot) int { return b.Time.Compare(a.Time) })
+	return snapshots, nil
+}
+
+// expired returns the snapshots, latest first, that limits leave no room
+// for.
+func expired(snapshots []Snapshot, limits Limits) []Snapshot {
+	var total int64
+	for i, snapshot := range snapshots {
+		total += snapshot.Size
+		if i > 0 && ((limits.Count > 0 && i >= limits.Count) || (limits.Bytes > 0 && total > limits.Bytes)) {
+			return snapshots[i:]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,481 @@
+// Package htmlterm renders HTML as styled terminal text.
+//
+// It walks a parsed golang.org/x/net/html tree, so nested elements,
+// attributes and entities are handled the way a browser would: block
+// elements become separated paragraphs, lists and quotes nest with
+// indentation, tables are aligned and inline elements map to terminal
+// styles. Scripts, styles and comments are dropped.
+package htmlterm
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// Render converts an HTML fragment or document to terminal text.
+func Render(content string) (string, error) {
+	nodes, err := html.ParseFragment(strings.NewReader(content), &html.Node{
+		Type:     html.ElementNode,
+		Data:     "body",
+		DataAtom: atom.Body,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	r := &renderer{}
+	for _, n := range nodes {
+		r.walk(n)
+	}
+	r.flushLine()
+
+	// Drop trailing blank lines left by the last block
+	lines := r.lines
+	for len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+// inline tracks the inline formatting in effect while walking.
+type inline struct {
+	bold, italic, underline, strike, code, mark, faint bool
+}
+
+func (f inline) style() lipgloss.Style {
+	style := lipgloss.NewStyle().
+		Bold(f.bold).
+		Italic(f.italic).
+		Underline(f.underline).
+		Strikethrough(f.strike).
+		Faint(f.faint)
+	if f.code {
+		style = style.Foreground(lipgloss.Color("6"))
+	}
+	if f.mark {
+		style = style.Foreground(lipgloss.Color("0")).Background(lipgloss.Color("3"))
+	}
+	return style
+}
+
+// prefix is the indentation contributed by an enclosing block: first is
+// used on its first line (a list bullet), rest on the following ones.
+type prefix struct {
+	first string
+	rest  string
+	used  bool
+}
+
+type renderer struct {
+	lines    []string
+	line     strings.Builder
+	prefixes []*prefix
+	format   inline
+	pre      int // Depth of <pre> elements: whitespace is preserved
+}
+
+// walk renders n and its children.
+func (r *renderer) walk(n *html.Node) {
+	switch n.Type {
+	case html.TextNode:
+		r.text(n.Data)
+		return
+	case html.ElementNode:
+		r.element(n)
+		return
+	case html.DocumentNode:
+		r.children(n)
+	}
+}
+
+func (r *renderer) children(n *html.Node) {
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		r.walk(c)
+	}
+}
+
+// element renders an element according to its tag.
+func (r *renderer) element(n *html.Node) {
+	saved := r.format
+	defer func() { r.format = saved }()
+
+	switch n.DataAtom {
+	case atom.Script, atom.Style, atom.Head, atom.Template, atom.Noscript:
+		return
+
+	case atom.Br:
+		r.flushLine()
+		return
+
+	case atom.Hr:
+		r.blockBreak()
+		r.write(strings.Repeat("─", 40))
+		r.blockBreak()
+		return
+
+	case atom.Img:
+		if alt := attr(n, "alt"); alt != "" {
+			r.write(r.format.style().Render("[image: " + alt + "]"))
+		} else {
+			r.write(r.format.style().Render("[image]"))
+		}
+		return
+
+	case atom.Input:
+		if attr(n, "type") == "checkbox" {
+			if hasAttr(n, "checked") {
+				r.write("☑ ")
+			} else {
+				r.write("☐ ")
+			}
+		}
+		return
+
+	case atom.H1, atom.H2, atom.H3, atom.H4, atom.H5, atom.H6:
+		level := int(n.Data[1] - '0')
+		r.blockBreak()
+		r.format.bold = true
+		r.write(r.format.style().Render(strings.Repeat("#", level) + " "))
+		r.children(n)
+		r.blockBreak()
+		return
+
+	case atom.P, atom.Div, atom.Section, atom.Article, atom.Header, atom.Footer,
+		atom.Main, atom.Nav, atom.Aside, atom.Figure, atom.Figcaption, atom.Details,
+		atom.Summary, atom.Address, atom.Center, atom.Form, atom.Fieldset:
+		r.blockBreak()
+		r.children(n)
+		r.blockBreak()
+		return
+
+	case atom.Pre:
+		r.blockBreak()
+		r.pre++
+		r.format.code = true
+		r.children(n)
+		r.pre--
+		r.blockBreak()
+		return
+
+	case atom.Blockquote:
+		r.blockBreak()
+		r.withPrefix("│ ", "│ ", func() { r.children(n) })
+		r.blockBreak()
+		return
+
+	case atom.Ul, atom.Ol:
+		r.list(n)
+		return
+
+	case atom.Li:
+		// A list item outside <ul>/<ol>
+		r.flushLine()
+		r.withPrefix("• ", "  ", func() { r.children(n) })
+		r.flushLine()
+		return
+
+	case atom.Dl:
+		r.blockBreak()
+		r.children(n)
+		r.blockBreak()
+		return
+
+	case atom.Dt:
+		r.flushLine()
+		r.format.bold = true
+		r.children(n)
+		r.flushLine()
+		return
+
+	case atom.Dd:
+		r.flushLine()
+		r.withPrefix("    ", "    ", func() { r.children(n) })
+		r.flushLine()
+		return
+
+	case atom.Table:
+		r.blockBreak()
+		r.table(n)
+		r.blockBreak()
+		return
+
+	case atom.Strong, atom.B:
+		r.format.bold = true
+	case atom.Em, atom.I, atom.Cite, atom.Var, atom.Dfn:
+		r.format.italic = true
+	case atom.U, atom.Ins:
+		r.format.underline = true
+	case atom.Del, atom.S, atom.Strike:
+		r.format.strike = true
+	case atom.Code, atom.Kbd, atom.Samp, atom.Tt:
+		r.format.code = true
+	case atom.Mark:
+		r.format.mark = true
+	case atom.Small, atom.Sub, atom.Sup:
+		r.format.faint = true
+
+	case atom.A:
+		r.format.underline = true
+		r.children(n)
+		href := attr(n, "href")
+		if href != "" && href != textContent(n) && !strings.HasPrefix(href, "#") {
+			r.write(lipgloss.NewStyle().Faint(true).Render(" (" + href + ")"))
+		}
+		return
+	}
+
+	r.children(n)
+}
+
+// list renders <ul> and <ol> items with bullets or numbers, honoring the
+// start attribute of ordered lists.
+func (r *renderer) list(n *html.Node) {
+	nested := r.inListItem()
+	if !nested {
+		r.blockBreak()
+	} else {
+		r.flushLine()
+	}
+
+	number := 1
+	if start, err := strconv.Atoi(attr(n, "start")); err == nil {
+		number = start
+	}
+
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type != html.ElementNode || c.DataAtom != atom.Li {
+			continue
+		}
+		marker := "• "
+		if n.DataAtom == atom.Ol {
+			marker = strconv.Itoa(number) + ". "
+			number++
+		}
+		r.flushLine()
+		r.withPrefix(marker, strings.Repeat(" ", len(marker)), func() { r.children(c) })
+		r.flushLine()
+	}
+
+	if !nested {
+		r.blockBreak()
+	}
+}
+
+// table renders rows as aligned cells separated by │, with a rule under
+// header rows.
+func (r *renderer) table(n *html.Node) {
+	var rows [][]string
+	header := -1
+	var collect func(*html.Node)
+	collect = func(n *html.Node) {
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			if c.Type != html.ElementNode {
+				continue
+			}
+			if c.DataAtom != atom.Tr {
+				collect(c)
+				continue
+			}
+			var cells []string
+			isHeader := false
+			for cell := c.FirstChild; cell != nil; cell = cell.NextSibling {
+				if cell.Type != html.ElementNode || (cell.DataAtom != atom.Td && cell.DataAtom != atom.Th) {
+					continue
+				}
+				sub := &renderer{format: r.format}
+				if cell.DataAtom == atom.Th {
+					sub.format.bold = true
+					isHeader = true
+				}
+				sub.children(cell)
+				sub.flushLine()
+				cells = append(cells, strings.Join(sub.lines, " "))
+			}
+			rows = append(rows, cells)
+			if isHeader {
+				header = len(rows) - 1
+			}
+		}
+	}
+	collect(n)
+
+	var widths []int
+	for _, row := range rows {
+		for i, cell := range row {
+			if i >= len(widths) {
+				widths = append(widths, 0)
+			}
+			widths[i] = max(widths[i], ansi.StringWidth(cell))
+		}
+	}
+
+	for i, row := range rows {
+		cells := make([]string, len(widths))
+		for j := range widths {
+			cell := ""
+			if j < len(row) {
+				cell = row[j]
+			}
+			cells[j] = cell + strings.Repeat(" ", widths[j]-ansi.StringWidth(cell))
+		}
+		r.write(strings.Join(cells, " │ "))
+		r.flushLine()
+
+		if i == header {
+			rules := make([]string, len(widths))
+			for j, width := range widths {
+				rules[j] = strings.Repeat("─", width)
+			}
+			r.write(strings.Join(rules, "─┼─"))
+			r.flushLine()
+		}
+	}
+}
+
+// text writes a text node, collapsing whitespace outside <pre>.
+func (r *renderer) text(data string) {
+	if r.pre > 0 {
+		for i, part := range strings.Split(data, "\n") {
+			if i > 0 {
+				r.flushLine()
+			}
+			if part != "" {
+				r.write(r.format.style().Render(part))
+			}
+		}
+		return
+	}
+
+	collapsed := strings.Join(strings.Fields(data), " ")
+	if collapsed == "" {
+		if data != "" && r.line.Len() > 0 && !r.endsWithSpace() {
+			r.line.WriteByte(' ')
+		}
+		return
+	}
+	if isSpace(data[0]) && r.line.Len() > 0 && !r.endsWithSpace() {
+		r.line.WriteByte(' ')
+	}
+	r.write(r.format.style().Render(collapsed))
+	if isSpace(data[len(data)-1]) {
+		r.line.WriteByte(' ')
+	}
+}
+
+func (r *renderer) write(s string) {
+	r.line.WriteString(s)
+}
+
+func (r *renderer) endsWithSpace() bool {
+	s := ansi.Strip(r.line.String())
+	return s == "" || isSpace(s[len(s)-1])
+}
+
+// flushLine ends the current line, if it has content, under the active
+// prefixes.
+func (r *renderer) flushLine() {
+	content := strings.TrimRight(r.line.String(), " ")
+	r.line.Reset()
+	if strings.TrimSpace(ansi.Strip(content)) == "" {
+		return
+	}
+	r.lines = append(r.lines, r.prefix()+content)
+}
+
+// blockBreak ends the current line and separates the next block with a
+// blank line.
+func (r *renderer) blockBreak() {
+	r.flushLine()
+	// Quote bars alone still count as a blank line
+	if len(r.lines) == 0 || strings.Trim(ansi.Strip(r.lines[len(r.lines)-1]), "│ ") == "" {
+		return
+	}
+	r.lines = append(r.lines, strings.TrimRight(r.quotePrefix(), " "))
+}
+
+// prefix composes the indentation for the next line.
+func (r *renderer) prefix() string {
+	var b strings.Builder
+	for _, p := range r.prefixes {
+		if p.used {
+			b.WriteString(p.rest)
+		} else {
+			b.WriteString(p.first)
+			p.used = true
+		}
+	}
+	return b.String()
+}
+
+// quotePrefix is the prefix for blank lines: quote bars continue, list
+// indentation does not.
+func (r *renderer) quotePrefix() string {
+	var b strings.Builder
+	for _, p := range r.prefixes {
+		if p.rest == "│ " {
+			b.WriteString(p.rest)
+		}
+	}
+	return b.String()
+}
+
+// inListItem reports whether an enclosing block indents its content, as
+// list items and definitions do.
+func (r *renderer) inListItem() bool {
+	for _, p := range r.prefixes {
+		if p.rest != "" && strings.TrimSpace(p.rest) == "" {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *renderer) withPrefix(first, rest string, fn func()) {
+	r.prefixes = append(r.prefixes, &prefix{first: first, rest: rest})
+	fn()
+	r.flushLine()
+	// A blank line ending the block belongs outside it
+	if bars := strings.TrimRight(r.quotePrefix(), " "); bars != "" && len(r.lines) > 0 && r.lines[len(r.lines)-1] == bars {
+		r.lines = r.lines[:len(r.lines)-1]
+	}
+	r.prefixes = r.prefixes[:len(r.prefixes)-1]
+}
+
+func attr(n *html.Node, key string) string {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return a.Val
+		}
+	}
+	return ""
+}
+
+func hasAttr(n *html.Node, key string) bool {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return true
+		}
+	}
+	return false
+}
+
+// textContent returns the concatenated text of n's descendants.
+func textContent(n *html.Node) string {
+	if n.Type == html.TextNode {
+		return n.Data
+	}
+	var b strings.Builder
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		b.WriteString(textContent(c))
+	}
+	return b.String()
+}
+
+func isSpace(b byte) bool {
+	return b == ' ' || b == '\t' || b == '\n' || b == '\r' || b == '\f'
+}
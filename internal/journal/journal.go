@@ -0,0 +1,93 @@
+// Package journal manages dated daily notes.
+//
+// Each day gets one file named YYYY-MM-DD.md in the journal directory.
+// Entries are created on first use with a date-stamped heading (or a
+// configured template), and quick captures are appended as timestamped
+// list items so they can be recorded without opening the editor.
+package journal
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// DateLayout is the date format used for file names and headings.
+const DateLayout = "2006-01-02"
+
+// Path returns the note file for the given day.
+func Path(dir string, day time.Time) string {
+	return filepath.Join(dir, day.Format(DateLayout)+".md")
+}
+
+// DefaultContent returns the initial content for a new entry.
+func DefaultContent(day time.Time) string {
+	return "# " + day.Format(DateLayout) + " " + day.Weekday().String() + "\n\n"
+}
+
+// Ensure creates the note for day if it does not exist yet, using content
+// as its initial text. Returns the note path and whether it was created.
+func Ensure(dir string, day time.Time, content string) (string, bool, error) {
+	path := Path(dir, day)
+
+	if _, err := os.Stat(path); err == nil {
+		return path, false, nil
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		return "", false, fmt.Errorf("failed to check journal entry %s: %w", path, err)
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", false, fmt.Errorf("failed to create journal directory %s: %w", dir, err)
+	}
+
+	// O_EXCL guards against clobbering an entry created concurrently
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if errors.Is(err, fs.ErrExist) {
+		return path, false, nil
+	}
+	if err != nil {
+		return "", false, fmt.Errorf("failed to create journal entry %s: %w", path, err)
+	}
+	defer file.Close()
+
+	if _, err := file.WriteString(content); err != nil {
+		return "", false, fmt.Errorf("failed to write journal entry %s: %w", path, err)
+	}
+
+	return path, true, nil
+}
+
+// Capture appends a timestamped list item to the note at path.
+// A separating newline is added if the file does not end with one.
+func Capture(path string, at time.Time, text string) error {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return errors.New("nothing to capture")
+	}
+
+	existing, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read journal entry %s: %w", path, err)
+	}
+
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open journal entry %s: %w", path, err)
+	}
+	defer file.Close()
+
+	var entry strings.Builder
+	if len(existing) > 0 && existing[len(existing)-1] != '\n' {
+		entry.WriteString("\n")
+	}
+	entry.WriteString("- " + at.Format("15:04") + " " + text + "\n")
+
+	if _, err := file.WriteString(entry.String()); err != nil {
+		return fmt.Errorf("failed to append to journal entry %s: %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,281 @@
+// Package linkcheck finds broken links in a markdown document: relative
+// paths to files that do not exist and, when asked, web pages that fail
+// to load. Pages are requested with HEAD, falling back to GET for servers
+// that refuse it, a few at a time, each URL once.
+package linkcheck
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ofri/mde/internal/links"
+	"github.com/ofri/mde/internal/lint"
+	"github.com/ofri/mde/pkg/ast"
+)
+
+// DefaultConcurrency is how many pages are requested at once when no
+// concurrency is given.
+const DefaultConcurrency = 8
+
+// DefaultTimeout bounds each request when no timeout is given.
+const DefaultTimeout = 10 * time.Second
+
+// userAgent names the checker to servers; some turn away Go's default.
+const userAgent = "Mozilla/5.0 (compatible; mde link checker)"
+
+// imageRe matches images, which links.Find leaves out.
+var imageRe = regexp.MustCompile(`!\[[^\]]*\]\((<[^>]*>|[^)\s]+)(?:\s+"[^"]*")?\)`)
+
+// Target is a link destination and where it is written.
+type Target struct {
+	Range ast.Range
+	URL   string
+}
+
+// Options control a check.
+type Options struct {
+	Dir         string        // Directory relative paths resolve from
+	External    bool          // Request http and https URLs too
+	Concurrency int           // Requests at once; DefaultConcurrency when 0
+	Timeout     time.Duration // Per request; DefaultTimeout when 0
+	Client      *http.Client  // http.DefaultClient when nil
+}
+
+// Targets returns the destinations of the links and images in doc that
+// name a file or a web page, in document order, skipping code blocks.
+// Anchors within the document are lint's concern, and references are
+// checked through their definitions.
+func Targets(doc *ast.Document) []Target {
+	var targets []Target
+	fences := doc.FencedBlocks()
+	for i := 0; i < doc.LineCount(); i++ {
+		for len(fences) > 0 && fences[0].EndLine < i {
+			fences = fences[1:]
+		}
+		if len(fences) > 0 && fences[0].Contains(i) {
+			continue
+		}
+		line := doc.GetLine(i)
+		add := func(start, end int, dest string) {
+			if checkable(dest) {
+				targets = append(targets, Target{
+					Range: ast.Range{
+						Start: ast.BufferPos{Line: i, Col: ast.RuneOffset(line, start)},
+						End:   ast.BufferPos{Line: i, Col: ast.RuneOffset(line, end)},
+					},
+					URL: dest,
+				})
+			}
+		}
+
+		found := links.Find(line)
+		images := imageRe.FindAllStringSubmatchIndex(line, -1)
+		for len(found) > 0 || len(images) > 0 {
+			if len(images) > 0 && (len(found) == 0 || images[0][0] < found[0].Start) {
+				m := images[0]
+				images = images[1:]
+				add(m[0], m[1], strings.TrimSuffix(strings.TrimPrefix(line[m[2]:m[3]], "<"), ">"))
+				continue
+			}
+			link := found[0]
+			found = found[1:]
+			switch link.Kind {
+			case links.Inline, links.Definition, links.Autolink, links.Bare:
+				add(link.Start, link.End, link.URL)
+			}
+		}
+	}
+	return targets
+}
+
+// checkable reports whether dest is a relative path or a web URL.
+func checkable(dest string) bool {
+	if dest == "" || strings.HasPrefix(dest, "#") {
+		return false
+	}
+	u, err := url.Parse(dest)
+	if err != nil {
+		return false
+	}
+	switch u.Scheme {
+	case "http", "https":
+		return true
+	case "":
+		return u.Host == "" && u.Path != "" && !strings.HasPrefix(u.Path, "/")
+	}
+	return false
+}
+
+// Check returns a diagnostic for each broken target, in the targets'
+// order: an error for a missing file or a page the server says is gone,
+// a warning for a page that could not be reached, which may be passing.
+func Check(ctx context.Context, targets []Target, opts Options) []lint.Diagnostic {
+	pages := map[string]*pageResult{}
+	if opts.External {
+		for _, target := range targets {
+			if web(target.URL) {
+				pages[target.URL] = nil
+			}
+		}
+		pages = checkPages(ctx, pages, opts)
+	}
+
+	var diagnostics []lint.Diagnostic
+	for _, target := range targets {
+		var severity lint.Severity
+		var message string
+		if web(target.URL) {
+			result := pages[target.URL]
+			if result == nil || result.message == "" {
+				continue
+			}
+			severity, message = result.severity, result.message
+		} else {
+			path := localPath(opts.Dir, target.URL)
+			if _, err := os.Stat(path); err == nil {
+				continue
+			} else if errors.Is(err, os.ErrNotExist) {
+				severity, message = lint.Error, "File not found: "+target.URL
+			} else {
+				severity, message = lint.Warning, err.Error()
+			}
+		}
+		diagnostics = append(diagnostics, lint.Diagnostic{Range: target.Range, Severity: severity, Message: message})
+	}
+	return diagnostics
+}
+
+// web reports whether dest is a web page rather than a path.
+func web(dest string) bool {
+	return strings.HasPrefix(dest, "http://") || strings.HasPrefix(dest, "https://")
+}
+
+// localPath resolves a relative destination from dir, dropping any
+// #fragment or ?query.
+func localPath(dir, dest string) string {
+	path := dest
+	if u, err := url.Parse(dest); err == nil {
+		path = u.Path
+	}
+	return filepath.Join(dir, filepath.FromSlash(path))
+}
+
+// pageResult is what became of requesting a page; an empty message
+// means it loaded.
+type pageResult struct {
+	severity lint.Severity
+	message  string
+}
+
+// checkPages requests each page, opts.Concurrency at a time.
+func checkPages(ctx context.Context, pages map[string]*pageResult, opts Options) map[string]*pageResult {
+	concurrency := opts.Concurrency
+	if concurrency <= 0 {
+		concurrency = DefaultConcurrency
+	}
+	// Workers write to their own map, so pages is not read while written
+	urls := make([]string, 0, len(pages))
+	for page := range pages {
+		urls = append(urls, page)
+	}
+	results := make(map[string]*pageResult, len(urls))
+	slots := make(chan struct{}, concurrency)
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, page := range urls {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			select {
+			case slots <- struct{}{}:
+			case <-ctx.Done():
+				return
+			}
+			result := checkPage(ctx, page, opts)
+			<-slots
+			mu.Lock()
+			results[page] = &result
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+	for page, result := range results {
+		pages[page] = result
+	}
+	return pages
+}
+
+// checkPage requests page with HEAD, then GET when HEAD fails, as some
+// servers only answer GET.
+func checkPage(ctx context.Context, page string, opts Options) pageResult {
+	timeout := opts.Timeout
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	client := opts.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	var status int
+	var err error
+	for _, method := range []string{http.MethodHead, http.MethodGet} {
+		status, err = request(ctx, client, method, page, timeout)
+		if err == nil && status < 400 {
+			return pageResult{}
+		}
+		if errors.Is(err, context.DeadlineExceeded) || ctx.Err() != nil {
+			break // GET would take as long
+		}
+	}
+
+	switch {
+	case ctx.Err() != nil:
+		return pageResult{severity: lint.Warning, message: "Not checked: " + ctx.Err().Error()}
+	case errors.Is(err, context.DeadlineExceeded):
+		return pageResult{severity: lint.Warning, message: fmt.Sprintf("%s timed out after %s", page, timeout)}
+	case err != nil:
+		return pageResult{severity: lint.Warning, message: fmt.Sprintf("Could not reach %s: %v", page, unwrapURLError(err))}
+	case status == http.StatusNotFound || status == http.StatusGone:
+		return pageResult{severity: lint.Error, message: fmt.Sprintf("%s returned %d %s", page, status, http.StatusText(status))}
+	default:
+		return pageResult{severity: lint.Warning, message: fmt.Sprintf("%s returned %d %s", page, status, http.StatusText(status))}
+	}
+}
+
+// request sends one request, reading none of the body, and returns the
+// status.
+func request(ctx context.Context, client *http.Client, method, page string, timeout time.Duration) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, method, page, nil)
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("User-Agent", userAgent)
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	resp.Body.Close()
+	return resp.StatusCode, nil
+}
+
+// unwrapURLError drops the "Head \"url\":" prefix url.Error adds, the URL
+// being in the message already.
+func unwrapURLError(err error) error {
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		return urlErr.Err
+	}
+	return err
+}
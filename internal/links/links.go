@@ -0,0 +1,258 @@
+// Package links finds markdown links of every syntax within a line:
+//
+//	[text](https://example.com)   inline
+//	[text][id] / [text][]         reference (resolved via a definition)
+//	[id]: https://example.com     link reference definition
+//	<https://example.com>         autolink
+//	https://example.com           bare URL (GFM linkify)
+//	[^note] / [^note]: text       footnote reference and definition
+//
+// Offsets are byte offsets into the line.
+package links
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/ofri/mde/pkg/ast"
+)
+
+// Kind identifies a link syntax.
+type Kind int
+
+const (
+	Inline Kind = iota
+	Reference
+	Definition
+	Autolink
+	Bare
+	FootnoteRef
+	FootnoteDefinition
+)
+
+// Link is a link occurrence within a line.
+type Link struct {
+	Kind  Kind
+	Start int // Start of the whole link syntax
+	End   int
+
+	// TextStart/TextEnd delimit the displayed text: the bracketed text of
+	// inline and reference links, the label of definitions and the URL of
+	// autolinks and bare URLs.
+	TextStart int
+	TextEnd   int
+
+	// URL is the destination for inline, definition, autolink and bare
+	// links; URLStart/URLEnd locate it in the line when present.
+	URL      string
+	URLStart int
+	URLEnd   int
+
+	// Label is the reference label of reference links, definitions and
+	// footnotes. LabelStart/LabelEnd locate it (excluding brackets and ^).
+	Label      string
+	LabelStart int
+	LabelEnd   int
+}
+
+// Text returns the displayed text of the link within line.
+func (l Link) Text(line string) string {
+	return line[l.TextStart:l.TextEnd]
+}
+
+var (
+	inlineRe     = regexp.MustCompile(`!?\[([^\]]+)\]\(([^)\s]+)(?:\s+"[^"]*")?\)`)
+	referenceRe  = regexp.MustCompile(`\[([^\]]+)\]\[([^\]]*)\]`)
+	definitionRe = regexp.MustCompile(`^ {0,3}\[([^\]^][^\]]*)\]:\s*(\S+)`)
+	autolinkRe   = regexp.MustCompile(`<((?:[a-zA-Z][a-zA-Z0-9+.\-]{1,31}:[^\s<>]+)|(?:[a-zA-Z0-9.!#$%&'*+/=?^_` + "`" + `{|}~\-]+@[a-zA-Z0-9](?:[a-zA-Z0-9\-]*[a-zA-Z0-9])?(?:\.[a-zA-Z0-9](?:[a-zA-Z0-9\-]*[a-zA-Z0-9])?)+))>`)
+	bareRe       = regexp.MustCompile(`(?:https?://|www\.)[^\s<>]+`)
+
+	footnoteRefRe = regexp.MustCompile(`\[\^([^\]\s]+)\]`)
+	footnoteDefRe = regexp.MustCompile(`^ {0,3}\[\^([^\]\s]+)\]:[ \t]*(.*)$`)
+)
+
+// Find returns every link in line, ordered by position. Links nested in
+// an earlier match (a URL inside [text](url)) are not reported twice.
+func Find(line string) []Link {
+	var found []Link
+
+	if m := definitionRe.FindStringSubmatchIndex(line); m != nil {
+		found = append(found, Link{
+			Kind: Definition, Start: m[0], End: len(line),
+			TextStart: m[2], TextEnd: m[3],
+			Label: line[m[2]:m[3]], LabelStart: m[2], LabelEnd: m[3],
+			URL: trimAngles(line[m[4]:m[5]]), URLStart: m[4], URLEnd: m[5],
+		})
+	}
+
+	if m := footnoteDefRe.FindStringSubmatchIndex(line); m != nil {
+		found = append(found, Link{
+			Kind: FootnoteDefinition, Start: m[0], End: m[3] + 2,
+			TextStart: m[2], TextEnd: m[3],
+			Label: line[m[2]:m[3]], LabelStart: m[2], LabelEnd: m[3],
+		})
+	}
+
+	for _, m := range footnoteRefRe.FindAllStringSubmatchIndex(line, -1) {
+		found = append(found, Link{
+			Kind: FootnoteRef, Start: m[0], End: m[1],
+			TextStart: m[2], TextEnd: m[3],
+			Label: line[m[2]:m[3]], LabelStart: m[2], LabelEnd: m[3],
+		})
+	}
+
+	for _, m := range inlineRe.FindAllStringSubmatchIndex(line, -1) {
+		if line[m[0]] == '!' {
+			continue // Images are not followable links
+		}
+		found = append(found, Link{
+			Kind: Inline, Start: m[0], End: m[1],
+			TextStart: m[2], TextEnd: m[3],
+			URL: line[m[4]:m[5]], URLStart: m[4], URLEnd: m[5],
+		})
+	}
+
+	for _, m := range referenceRe.FindAllStringSubmatchIndex(line, -1) {
+		link := Link{
+			Kind: Reference, Start: m[0], End: m[1],
+			TextStart: m[2], TextEnd: m[3],
+			Label: line[m[4]:m[5]], LabelStart: m[4], LabelEnd: m[5],
+		}
+		if link.Label == "" {
+			// Collapsed reference [text][] uses the text as its label
+			link.Label = line[m[2]:m[3]]
+		}
+		found = append(found, link)
+	}
+
+	for _, m := range autolinkRe.FindAllStringSubmatchIndex(line, -1) {
+		url := line[m[2]:m[3]]
+		if !strings.Contains(url, ":") {
+			url = "mailto:" + url
+		}
+		found = append(found, Link{
+			Kind: Autolink, Start: m[0], End: m[1],
+			TextStart: m[2], TextEnd: m[3],
+			URL: url, URLStart: m[2], URLEnd: m[3],
+		})
+	}
+
+	for _, m := range bareRe.FindAllStringIndex(line, -1) {
+		end := m[0] + len(trimTrailingPunctuation(line[m[0]:m[1]]))
+		url := line[m[0]:end]
+		if strings.HasPrefix(url, "www.") {
+			url = "http://" + url
+		}
+		found = append(found, Link{
+			Kind: Bare, Start: m[0], End: end,
+			TextStart: m[0], TextEnd: end,
+			URL: url, URLStart: m[0], URLEnd: end,
+		})
+	}
+
+	return dropNested(found)
+}
+
+// At returns the link under rune column col of line, if any.
+func At(line string, col int) (Link, bool) {
+	offset := ast.ByteOffset(line, col)
+	for _, link := range Find(line) {
+		if offset >= link.Start && offset < link.End {
+			return link, true
+		}
+	}
+	return Link{}, false
+}
+
+// NormalizeLabel folds a reference label for matching: case-insensitive
+// with internal whitespace collapsed, as CommonMark specifies.
+func NormalizeLabel(label string) string {
+	return strings.ToLower(strings.Join(strings.Fields(label), " "))
+}
+
+// Definitions collects link reference definitions from lines, keyed by
+// normalized label. The first definition of a label wins.
+func Definitions(lines []string) map[string]string {
+	defs := make(map[string]string)
+	for _, line := range lines {
+		m := definitionRe.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		label := NormalizeLabel(m[1])
+		if _, exists := defs[label]; !exists {
+			defs[label] = trimAngles(m[2])
+		}
+	}
+	return defs
+}
+
+// Footnotes collects footnote definitions from lines, keyed by label.
+// A definition continues over following lines indented by four spaces
+// or a tab; its text is joined with single spaces.
+func Footnotes(lines []string) map[string]string {
+	notes := make(map[string]string)
+	for i := 0; i < len(lines); i++ {
+		m := footnoteDefRe.FindStringSubmatch(lines[i])
+		if m == nil {
+			continue
+		}
+		parts := []string{strings.TrimSpace(m[2])}
+		for i+1 < len(lines) && (strings.HasPrefix(lines[i+1], "    ") || strings.HasPrefix(lines[i+1], "\t")) {
+			i++
+			parts = append(parts, strings.TrimSpace(lines[i]))
+		}
+		if _, exists := notes[m[1]]; !exists {
+			notes[m[1]] = strings.TrimSpace(strings.Join(parts, " "))
+		}
+	}
+	return notes
+}
+
+// FootnoteLine returns the line defining footnote label, or -1.
+func FootnoteLine(lines []string, label string) int {
+	for i, line := range lines {
+		if m := footnoteDefRe.FindStringSubmatch(line); m != nil && m[1] == label {
+			return i
+		}
+	}
+	return -1
+}
+
+// dropNested sorts links by position and removes any starting inside an
+// earlier one.
+func dropNested(found []Link) []Link {
+	sort.SliceStable(found, func(i, j int) bool { return found[i].Start < found[j].Start })
+	result := found[:0]
+	end := -1
+	for _, link := range found {
+		if link.Start < end {
+			continue
+		}
+		result = append(result, link)
+		end = link.End
+	}
+	return result
+}
+
+// trimTrailingPunctuation drops sentence punctuation after a bare URL and
+// unbalanced closing parentheses, following GFM's extended autolinks.
+func trimTrailingPunctuation(url string) string {
+	for len(url) > 0 {
+		last := url[len(url)-1]
+		switch {
+		case strings.IndexByte(`?!.,:*_~'"`, last) >= 0:
+			url = url[:len(url)-1]
+		case last == ')' && strings.Count(url, ")") > strings.Count(url, "("):
+			url = url[:len(url)-1]
+		default:
+			return url
+		}
+	}
+	return url
+}
+
+func trimAngles(url string) string {
+	return strings.TrimSuffix(strings.TrimPrefix(url, "<"), ">")
+}
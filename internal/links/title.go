@@ -0,0 +1,56 @@
+package links
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// maxTitleBytes bounds how much of a page is read looking for its title
+const maxTitleBytes = 1 << 20
+
+// FetchTitle downloads the page at url and returns its <title>, with
+// whitespace collapsed. The title is empty when the page has none.
+func FetchTitle(ctx context.Context, url string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Accept", "text/html")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return "", fmt.Errorf("%s returned %s", url, resp.Status)
+	}
+	return pageTitle(io.LimitReader(resp.Body, maxTitleBytes)), nil
+}
+
+// pageTitle returns the text of the first <title> element in an HTML
+// document.
+func pageTitle(r io.Reader) string {
+	tokens := html.NewTokenizer(r)
+	for {
+		switch tokens.Next() {
+		case html.ErrorToken:
+			return ""
+		case html.StartTagToken:
+			name, _ := tokens.TagName()
+			if atom.Lookup(name) != atom.Title {
+				continue
+			}
+			// The tokenizer reads <title> as raw text, so one token holds it
+			if tokens.Next() != html.TextToken {
+				return ""
+			}
+			return strings.Join(strings.Fields(string(tokens.Text())), " ")
+		}
+	}
+}
@@ -0,0 +1,129 @@
+// Package lint finds problems in a markdown document that its author
+// likely wants to fix: links to headings, references and footnotes that
+// do not exist, and code fences left open.
+package lint
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ofri/mde/internal/links"
+	"github.com/ofri/mde/pkg/ast"
+)
+
+// Severity ranks a diagnostic.
+type Severity int
+
+const (
+	Error Severity = iota + 1
+	Warning
+)
+
+// String returns the severity's name, as in "warning".
+func (s Severity) String() string {
+	switch s {
+	case Error:
+		return "error"
+	case Warning:
+		return "warning"
+	default:
+		return "unknown"
+	}
+}
+
+// Rules name the problems Check finds, so each can be turned off.
+const (
+	RuleHeadingAnchor = "heading-anchor" // Links to an #anchor no heading has
+	RuleLinkReference = "link-reference" // [text][label] with no [label]: definition
+	RuleFootnote      = "footnote"       // [^label] with no footnote
+	RuleOpenFence     = "open-fence"     // A code fence never closed
+)
+
+// Diagnostic is a problem found in a range of the document.
+type Diagnostic struct {
+	Range    ast.Range
+	Severity Severity
+	Message  string
+	Rule     string // The rule finding it, if it can be turned off
+}
+
+// Filter returns the diagnostics whose rules are not turned off in rules,
+// which maps rule names to whether they are on.
+func Filter(diagnostics []Diagnostic, rules map[string]bool) []Diagnostic {
+	if len(rules) == 0 {
+		return diagnostics
+	}
+	var kept []Diagnostic
+	for _, d := range diagnostics {
+		if on, ok := rules[d.Rule]; !ok || on {
+			kept = append(kept, d)
+		}
+	}
+	return kept
+}
+
+// Check returns the problems in doc, in document order.
+func Check(doc *ast.Document) []Diagnostic {
+	lines := strings.Split(doc.GetText(), "\n")
+	definitions := links.Definitions(lines)
+	footnotes := links.Footnotes(lines)
+	anchors := make(map[string]bool)
+	for _, heading := range doc.Headings() {
+		anchors[heading.Anchor] = true
+	}
+
+	var diagnostics []Diagnostic
+	fences := doc.FencedBlocks()
+	for i, line := range lines {
+		for len(fences) > 0 && fences[0].EndLine < i {
+			fences = fences[1:]
+		}
+		if len(fences) > 0 && fences[0].Contains(i) {
+			continue // Code, not links
+		}
+
+		for _, link := range links.Find(line) {
+			var message, rule string
+			switch link.Kind {
+			case links.Inline:
+				if anchor, ok := strings.CutPrefix(link.URL, "#"); ok && !anchors[strings.ToLower(anchor)] {
+					message, rule = fmt.Sprintf("No heading with anchor #%s", anchor), RuleHeadingAnchor
+				}
+			case links.Reference:
+				if _, ok := definitions[links.NormalizeLabel(link.Label)]; !ok {
+					message, rule = fmt.Sprintf("Undefined link reference [%s]", link.Label), RuleLinkReference
+				}
+			case links.FootnoteRef:
+				if _, ok := footnotes[link.Label]; !ok {
+					message, rule = fmt.Sprintf("Undefined footnote [^%s]", link.Label), RuleFootnote
+				}
+			}
+			if message != "" {
+				diagnostics = append(diagnostics, Diagnostic{
+					Range: ast.Range{
+						Start: ast.BufferPos{Line: i, Col: ast.RuneOffset(line, link.Start)},
+						End:   ast.BufferPos{Line: i, Col: ast.RuneOffset(line, link.End)},
+					},
+					Severity: Error,
+					Message:  message,
+					Rule:     rule,
+				})
+			}
+		}
+	}
+
+	for _, fence := range doc.FencedBlocks() {
+		if !fence.Closed {
+			diagnostics = append(diagnostics, Diagnostic{
+				Range: ast.Range{
+					Start: ast.BufferPos{Line: fence.StartLine},
+					End:   ast.BufferPos{Line: fence.StartLine, Col: doc.GetLineLength(fence.StartLine)},
+				},
+				Severity: Warning,
+				Message:  "Code fence is never closed",
+				Rule:     RuleOpenFence,
+			})
+		}
+	}
+	return diagnostics
+}
@@ -0,0 +1,249 @@
+// Package lsp is a minimal Language Server Protocol client for markdown
+// servers such as marksman: it keeps a document in sync with the server,
+// collects the diagnostics it publishes and asks it for completions,
+// hover text and renames.
+package lsp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"os/exec"
+	"sync"
+)
+
+// Client is a connection to one language server.
+type Client struct {
+	conn *conn
+	cmd  *exec.Cmd // nil when not started by Start
+
+	mu          sync.Mutex
+	versions    map[string]int // Open documents by URI
+	diagnostics map[string][]Diagnostic
+	changed     chan struct{}
+}
+
+// Start runs command with args as a language server for the files under
+// root and initializes it.
+func Start(ctx context.Context, root, command string, args ...string) (*Client, error) {
+	cmd := exec.Command(command, args...)
+	cmd.Dir = root
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+
+	c, err := NewClient(ctx, stdio{stdout, stdin}, root)
+	if err != nil {
+		cmd.Process.Kill()
+		cmd.Wait()
+		return nil, err
+	}
+	c.cmd = cmd
+	return c, nil
+}
+
+// stdio joins a server's stdout and stdin into one stream.
+type stdio struct {
+	io.ReadCloser
+	io.WriteCloser
+}
+
+func (s stdio) Close() error {
+	return errors.Join(s.WriteCloser.Close(), s.ReadCloser.Close())
+}
+
+// NewClient initializes the server at the other end of rwc for the files
+// under root.
+func NewClient(ctx context.Context, rwc io.ReadWriteCloser, root string) (*Client, error) {
+	c := &Client{
+		versions:    make(map[string]int),
+		diagnostics: make(map[string][]Diagnostic),
+		changed:     make(chan struct{}, 1),
+	}
+	c.conn = newConn(rwc, c.handleNotification)
+
+	params := map[string]any{
+		"processId": nil,
+		"rootUri":   FileURI(root),
+		"capabilities": map[string]any{
+			"textDocument": map[string]any{
+				"synchronization":    map[string]any{"didSave": false},
+				"completion":         map[string]any{"completionItem": map[string]any{"snippetSupport": false}},
+				"hover":              map[string]any{"contentFormat": []string{"plaintext", "markdown"}},
+				"rename":             map[string]any{"prepareSupport": false},
+				"publishDiagnostics": map[string]any{},
+			},
+			"general": map[string]any{"positionEncodings": []string{"utf-16"}},
+		},
+		"workspaceFolders": []map[string]string{{"uri": FileURI(root), "name": root}},
+	}
+	if err := c.conn.call(ctx, "initialize", params, nil); err != nil {
+		c.conn.Close()
+		return nil, err
+	}
+	if err := c.conn.notification("initialized", struct{}{}); err != nil {
+		c.conn.Close()
+		return nil, err
+	}
+	return c, nil
+}
+
+func (c *Client) handleNotification(method string, params json.RawMessage) {
+	if method != "textDocument/publishDiagnostics" {
+		return
+	}
+	var p PublishDiagnostics
+	if json.Unmarshal(params, &p) != nil {
+		return
+	}
+	c.mu.Lock()
+	c.diagnostics[p.URI] = p.Diagnostics
+	c.mu.Unlock()
+	select {
+	case c.changed <- struct{}{}:
+	default: // A wake-up is already pending
+	}
+}
+
+// Changed receives after the server publishes diagnostics. Wake-ups
+// coalesce, so read the current ones with Diagnostics.
+func (c *Client) Changed() <-chan struct{} {
+	return c.changed
+}
+
+// Done is closed when the server exits or the connection fails.
+func (c *Client) Done() <-chan struct{} {
+	return c.conn.done
+}
+
+// Diagnostics returns the last diagnostics published for path.
+func (c *Client) Diagnostics(path string) []Diagnostic {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.diagnostics[FileURI(path)]
+}
+
+type textDocument struct {
+	URI string `json:"uri"`
+}
+
+type positionParams struct {
+	TextDocument textDocument `json:"textDocument"`
+	Position     Position     `json:"position"`
+}
+
+// DidOpen tells the server the editor holds path with text.
+func (c *Client) DidOpen(path, languageID, text string) error {
+	uri := FileURI(path)
+	c.mu.Lock()
+	c.versions[uri] = 1
+	c.mu.Unlock()
+	return c.conn.notification("textDocument/didOpen", map[string]any{
+		"textDocument": map[string]any{
+			"uri":        uri,
+			"languageId": languageID,
+			"version":    1,
+			"text":       text,
+		},
+	})
+}
+
+// DidChange sends the full new text of the open document path.
+func (c *Client) DidChange(path, text string) error {
+	uri := FileURI(path)
+	c.mu.Lock()
+	c.versions[uri]++
+	version := c.versions[uri]
+	c.mu.Unlock()
+	return c.conn.notification("textDocument/didChange", map[string]any{
+		"textDocument":   map[string]any{"uri": uri, "version": version},
+		"contentChanges": []map[string]string{{"text": text}},
+	})
+}
+
+// DidClose tells the server the editor no longer holds path.
+func (c *Client) DidClose(path string) error {
+	uri := FileURI(path)
+	c.mu.Lock()
+	delete(c.versions, uri)
+	delete(c.diagnostics, uri)
+	c.mu.Unlock()
+	return c.conn.notification("textDocument/didClose", map[string]any{
+		"textDocument": textDocument{URI: uri},
+	})
+}
+
+// Completion returns the completion candidates at pos in path.
+func (c *Client) Completion(ctx context.Context, path string, pos Position) ([]CompletionItem, error) {
+	var result json.RawMessage
+	err := c.conn.call(ctx, "textDocument/completion", positionParams{textDocument{FileURI(path)}, pos}, &result)
+	if err != nil || len(result) == 0 {
+		return nil, err
+	}
+	var items []CompletionItem
+	if json.Unmarshal(result, &items) == nil {
+		return items, nil
+	}
+	var list completionList
+	if err := json.Unmarshal(result, &list); err != nil {
+		return nil, err
+	}
+	return list.Items, nil
+}
+
+// Hover returns the hover text at pos in path, or "" when there is none.
+func (c *Client) Hover(ctx context.Context, path string, pos Position) (string, error) {
+	var result hover
+	err := c.conn.call(ctx, "textDocument/hover", positionParams{textDocument{FileURI(path)}, pos}, &result)
+	if err != nil || result.Contents == nil {
+		return "", err
+	}
+	return hoverText(result.Contents), nil
+}
+
+// Rename asks for the edits that rename the symbol at pos in path to
+// newName.
+func (c *Client) Rename(ctx context.Context, path string, pos Position, newName string) (WorkspaceEdit, error) {
+	var edit WorkspaceEdit
+	err := c.conn.call(ctx, "textDocument/rename", map[string]any{
+		"textDocument": textDocument{FileURI(path)},
+		"position":     pos,
+		"newName":      newName,
+	}, &edit)
+	return edit, err
+}
+
+// Shutdown asks the server to exit and closes the connection, killing
+// the server if it does not exit before ctx is done.
+func (c *Client) Shutdown(ctx context.Context) error {
+	err := c.conn.call(ctx, "shutdown", nil, nil)
+	if err == nil {
+		err = c.conn.notification("exit", nil)
+	}
+	c.conn.Close()
+	if c.cmd == nil {
+		return err
+	}
+
+	exited := make(chan struct{})
+	go func() {
+		c.cmd.Wait()
+		close(exited)
+	}()
+	select {
+	case <-exited:
+	case <-ctx.Done():
+		c.cmd.Process.Kill()
+		<-exited
+	}
+	return err
+}
@@ -0,0 +1,240 @@
+package lsp
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/textproto"
+	"strconv"
+	"sync"
+)
+
+// ErrClosed is returned for calls on a connection the server has closed.
+var ErrClosed = errors.New("language server connection closed")
+
+// ResponseError is an error the server answered a request with.
+type ResponseError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *ResponseError) Error() string {
+	return fmt.Sprintf("%s (code %d)", e.Message, e.Code)
+}
+
+// codeMethodNotFound answers server requests the client does not handle.
+const codeMethodNotFound = -32601
+
+// message is any JSON-RPC message: a request, notification or response.
+type message struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method,omitempty"`
+	Params  json.RawMessage `json:"params,omitempty"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   *ResponseError  `json:"error,omitempty"`
+}
+
+// conn speaks JSON-RPC 2.0 framed by Content-Length headers, as language
+// servers do on stdio.
+type conn struct {
+	rwc    io.ReadWriteCloser
+	notify func(method string, params json.RawMessage)
+
+	writeMu sync.Mutex
+
+	mu      sync.Mutex
+	nextID  int
+	pending map[int]chan message
+	err     error // Why the connection closed
+	done    chan struct{}
+}
+
+// newConn reads messages from rwc until it closes, passing notifications
+// to notify.
+func newConn(rwc io.ReadWriteCloser, notify func(method string, params json.RawMessage)) *conn {
+	c := &conn{
+		rwc:     rwc,
+		notify:  notify,
+		pending: make(map[int]chan message),
+		done:    make(chan struct{}),
+	}
+	go c.read()
+	return c
+}
+
+// call sends a request and decodes its result into result, which may be
+// nil to discard it. Cancelling ctx abandons the request.
+func (c *conn) call(ctx context.Context, method string, params, result any) error {
+	c.mu.Lock()
+	if c.err != nil {
+		c.mu.Unlock()
+		return c.err
+	}
+	c.nextID++
+	id := c.nextID
+	reply := make(chan message, 1)
+	c.pending[id] = reply
+	c.mu.Unlock()
+
+	if err := c.send(message{ID: json.RawMessage(strconv.Itoa(id)), Method: method}, params); err != nil {
+		c.forget(id)
+		return err
+	}
+
+	select {
+	case resp, ok := <-reply:
+		if !ok {
+			return c.closeErr()
+		}
+		if resp.Error != nil {
+			return resp.Error
+		}
+		if result == nil || len(resp.Result) == 0 || string(resp.Result) == "null" {
+			return nil
+		}
+		return json.Unmarshal(resp.Result, result)
+	case <-ctx.Done():
+		c.forget(id)
+		c.send(message{Method: "$/cancelRequest"}, map[string]int{"id": id})
+		return ctx.Err()
+	}
+}
+
+// notification sends a notification, which has no response.
+func (c *conn) notification(method string, params any) error {
+	return c.send(message{Method: method}, params)
+}
+
+func (c *conn) forget(id int) {
+	c.mu.Lock()
+	delete(c.pending, id)
+	c.mu.Unlock()
+}
+
+func (c *conn) closeErr() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.err
+}
+
+// send writes msg with params marshaled into it.
+func (c *conn) send(msg message, params any) error {
+	msg.JSONRPC = "2.0"
+	if params != nil {
+		data, err := json.Marshal(params)
+		if err != nil {
+			return err
+		}
+		msg.Params = data
+	}
+	return c.write(msg)
+}
+
+func (c *conn) write(msg message) error {
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	if _, err := fmt.Fprintf(c.rwc, "Content-Length: %d\r\n\r\n", len(body)); err != nil {
+		return err
+	}
+	_, err = c.rwc.Write(body)
+	return err
+}
+
+// read dispatches incoming messages until the connection fails, then
+// fails the calls still waiting.
+func (c *conn) read() {
+	r := bufio.NewReader(c.rwc)
+	var err error
+	for {
+		var msg message
+		if msg, err = readMessage(r); err != nil {
+			break
+		}
+		switch {
+		case msg.Method != "" && msg.ID != nil:
+			go c.answer(msg) // Writing may wait on the server, which may be writing too
+		case msg.Method != "":
+			if c.notify != nil {
+				c.notify(msg.Method, msg.Params)
+			}
+		default:
+			id, convErr := strconv.Atoi(string(msg.ID))
+			if convErr != nil {
+				continue // Not one of ours
+			}
+			c.mu.Lock()
+			reply, ok := c.pending[id]
+			delete(c.pending, id)
+			c.mu.Unlock()
+			if ok {
+				reply <- msg
+			}
+		}
+	}
+
+	c.mu.Lock()
+	c.err = ErrClosed
+	if !errors.Is(err, io.EOF) {
+		c.err = fmt.Errorf("%w: %v", ErrClosed, err)
+	}
+	for id, reply := range c.pending {
+		close(reply)
+		delete(c.pending, id)
+	}
+	c.mu.Unlock()
+	close(c.done)
+}
+
+// answer replies to a request from the server. The client declares no
+// capabilities that invite requests, so only the few servers send anyway
+// get an empty answer.
+func (c *conn) answer(req message) {
+	resp := message{JSONRPC: "2.0", ID: req.ID}
+	switch req.Method {
+	case "workspace/configuration":
+		var params struct {
+			Items []json.RawMessage `json:"items"`
+		}
+		json.Unmarshal(req.Params, &params)
+		resp.Result, _ = json.Marshal(make([]any, len(params.Items)))
+	case "window/workDoneProgress/create", "client/registerCapability", "client/unregisterCapability":
+		resp.Result = json.RawMessage("null")
+	default:
+		resp.Error = &ResponseError{Code: codeMethodNotFound, Message: "method not supported: " + req.Method}
+	}
+	c.write(resp)
+}
+
+// readMessage reads one framed message.
+func readMessage(r *bufio.Reader) (message, error) {
+	header, err := textproto.NewReader(r).ReadMIMEHeader()
+	if err != nil {
+		return message{}, err
+	}
+	length, err := strconv.Atoi(header.Get("Content-Length"))
+	if err != nil || length < 0 {
+		return message{}, fmt.Errorf("bad Content-Length %q", header.Get("Content-Length"))
+	}
+	body := make([]byte, length)
+	if _, err := io.ReadFull(r, body); err != nil {
+		return message{}, err
+	}
+	var msg message
+	if err := json.Unmarshal(body, &msg); err != nil {
+		return message{}, err
+	}
+	return msg, nil
+}
+
+// Close closes the connection.
+func (c *conn) Close() error {
+	return c.rwc.Close()
+}
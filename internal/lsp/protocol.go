@@ -0,0 +1,191 @@
+package lsp
+
+import (
+	"encoding/json"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"unicode/utf16"
+
+	"github.com/ofri/mde/pkg/ast"
+)
+
+// The subset of the Language Server Protocol the client speaks. Positions
+// count UTF-16 code units within a line, as the protocol requires; convert
+// them with ToPosition and FromPosition.
+
+// Position is a zero-based line and UTF-16 character offset.
+type Position struct {
+	Line      int `json:"line"`
+	Character int `json:"character"`
+}
+
+// Range is the text from Start up to, not including, End.
+type Range struct {
+	Start Position `json:"start"`
+	End   Position `json:"end"`
+}
+
+// TextEdit replaces Range with NewText.
+type TextEdit struct {
+	Range   Range  `json:"range"`
+	NewText string `json:"newText"`
+}
+
+// Severity ranks a diagnostic.
+type Severity int
+
+const (
+	SeverityError Severity = iota + 1
+	SeverityWarning
+	SeverityInformation
+	SeverityHint
+)
+
+// String returns the severity's name, as in "warning".
+func (s Severity) String() string {
+	switch s {
+	case SeverityError:
+		return "error"
+	case SeverityWarning:
+		return "warning"
+	case SeverityInformation:
+		return "information"
+	case SeverityHint:
+		return "hint"
+	default:
+		return "unknown"
+	}
+}
+
+// Diagnostic is a problem the server found.
+type Diagnostic struct {
+	Range    Range    `json:"range"`
+	Severity Severity `json:"severity,omitempty"`
+	Source   string   `json:"source,omitempty"`
+	Message  string   `json:"message"`
+}
+
+// PublishDiagnostics replaces the diagnostics of the document at URI.
+type PublishDiagnostics struct {
+	URI         string       `json:"uri"`
+	Version     *int         `json:"version,omitempty"`
+	Diagnostics []Diagnostic `json:"diagnostics"`
+}
+
+// CompletionItem is one completion candidate.
+type CompletionItem struct {
+	Label      string    `json:"label"`
+	Detail     string    `json:"detail,omitempty"`
+	InsertText string    `json:"insertText,omitempty"`
+	FilterText string    `json:"filterText,omitempty"`
+	TextEdit   *TextEdit `json:"textEdit,omitempty"`
+}
+
+// completionList is the result of textDocument/completion when it is not
+// a bare array of items.
+type completionList struct {
+	Items []CompletionItem `json:"items"`
+}
+
+// WorkspaceEdit is a set of changes to documents, keyed by URI.
+type WorkspaceEdit struct {
+	Changes         map[string][]TextEdit `json:"changes,omitempty"`
+	DocumentChanges []struct {
+		TextDocument struct {
+			URI string `json:"uri"`
+		} `json:"textDocument"`
+		Edits []TextEdit `json:"edits"`
+	} `json:"documentChanges,omitempty"`
+}
+
+// Edits returns the edits to the document at uri, from either form the
+// server may use.
+func (w WorkspaceEdit) Edits(uri string) []TextEdit {
+	edits := w.Changes[uri]
+	for _, change := range w.DocumentChanges {
+		if change.TextDocument.URI == uri {
+			edits = append(edits, change.Edits...)
+		}
+	}
+	return edits
+}
+
+// hover is the result of textDocument/hover.
+type hover struct {
+	Contents json.RawMessage `json:"contents"`
+}
+
+// markedString is a hover content in the deprecated MarkedString form.
+type markedString struct {
+	Language string `json:"language"`
+	Value    string `json:"value"`
+}
+
+// hoverText flattens hover contents, which may be MarkupContent, a
+// MarkedString or an array of them, to text.
+func hoverText(contents json.RawMessage) string {
+	var text string
+	if json.Unmarshal(contents, &text) == nil {
+		return text
+	}
+	var markup markedString // MarkupContent has a value too
+	if json.Unmarshal(contents, &markup) == nil && markup.Value != "" {
+		return markup.Value
+	}
+	var parts []json.RawMessage
+	if json.Unmarshal(contents, &parts) == nil {
+		texts := make([]string, 0, len(parts))
+		for _, part := range parts {
+			if t := hoverText(part); t != "" {
+				texts = append(texts, t)
+			}
+		}
+		return strings.Join(texts, "\n\n")
+	}
+	return ""
+}
+
+// ToPosition converts rune column col of line to a protocol position.
+func ToPosition(lineNum int, line string, col int) Position {
+	units := 0
+	for _, r := range line {
+		if col <= 0 {
+			break
+		}
+		units += utf16.RuneLen(r)
+		col--
+	}
+	return Position{Line: lineNum, Character: units}
+}
+
+// FromPosition converts a protocol position on line to a rune column,
+// clamped to the line.
+func FromPosition(line string, pos Position) int {
+	units, col := 0, 0
+	for _, r := range line {
+		if units >= pos.Character {
+			break
+		}
+		units += utf16.RuneLen(r)
+		col++
+	}
+	return col
+}
+
+// BufferPos converts a protocol position in doc to a buffer position.
+func BufferPos(doc *ast.Document, pos Position) ast.BufferPos {
+	if pos.Line >= doc.LineCount() {
+		last := doc.LineCount() - 1
+		return ast.BufferPos{Line: last, Col: doc.GetLineLength(last)}
+	}
+	return ast.BufferPos{Line: pos.Line, Col: FromPosition(doc.GetLine(pos.Line), pos)}
+}
+
+// FileURI returns the file:// URI of path.
+func FileURI(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	return (&url.URL{Scheme: "file", Path: filepath.ToSlash(path)}).String()
+}
@@ -0,0 +1,310 @@
+// Package mathtext finds TeX math spans in markdown and renders simple
+// expressions as Unicode for terminal display.
+//
+// Span detection follows the goldmark-mathjax / pandoc conventions:
+//
+//	$x^2$         inline math: the opening $ must not be followed by a space,
+//	              the closing $ not preceded by one nor followed by a digit
+//	$$E = mc^2$$  display math on a single line
+//	$$            a line holding only $$ opens or closes a display block
+//
+// Dollars escaped with a backslash and dollars inside `code spans` are
+// ignored, so "costs $5 and $10" is not math.
+package mathtext
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Span is a math span within a line. Start and End are byte offsets of the
+// full span, delimiters included.
+type Span struct {
+	Start   int
+	End     int
+	Display bool
+}
+
+// DelimiterLen returns the byte length of the span's $ or $$ delimiters.
+func (s Span) DelimiterLen() int {
+	if s.Display {
+		return 2
+	}
+	return 1
+}
+
+// Inner returns the TeX source between the delimiters.
+func (s Span) Inner(line string) string {
+	d := s.DelimiterLen()
+	return line[s.Start+d : s.End-d]
+}
+
+// IsBlockDelimiter reports whether line opens or closes a display block.
+func IsBlockDelimiter(line string) bool {
+	return strings.TrimSpace(line) == "$$"
+}
+
+// FindSpans returns the math spans in line, in order.
+func FindSpans(line string) []Span {
+	if IsBlockDelimiter(line) {
+		return nil
+	}
+
+	var spans []Span
+	for i := 0; i < len(line); {
+		switch line[i] {
+		case '\\':
+			i += 2
+			continue
+		case '`':
+			i = skipCodeSpan(line, i)
+			continue
+		case '$':
+			if span, ok := matchSpan(line, i); ok {
+				spans = append(spans, span)
+				i = span.End
+				continue
+			}
+		}
+		i++
+	}
+	return spans
+}
+
+// matchSpan tries to match a math span opening at byte offset start.
+func matchSpan(line string, start int) (Span, bool) {
+	if strings.HasPrefix(line[start:], "$$") {
+		end := strings.Index(line[start+2:], "$$")
+		if end <= 0 {
+			return Span{}, false
+		}
+		return Span{Start: start, End: start + 2 + end + 2, Display: true}, true
+	}
+
+	// Opening $ must be followed by non-space content
+	if start+1 >= len(line) || isSpace(line[start+1]) {
+		return Span{}, false
+	}
+
+	for i := start + 1; i < len(line); i++ {
+		switch line[i] {
+		case '\\':
+			i++
+		case '$':
+			if isSpace(line[i-1]) {
+				continue
+			}
+			// "$5 and $10": a digit after the closer means it was prose
+			if i+1 < len(line) && line[i+1] >= '0' && line[i+1] <= '9' {
+				return Span{}, false
+			}
+			return Span{Start: start, End: i + 1}, true
+		}
+	}
+	return Span{}, false
+}
+
+// skipCodeSpan returns the offset after the code span opening at start,
+// or start+run if the backticks are unmatched.
+func skipCodeSpan(line string, start int) int {
+	run := 0
+	for start+run < len(line) && line[start+run] == '`' {
+		run++
+	}
+	fence := strings.Repeat("`", run)
+	if end := strings.Index(line[start+run:], fence); end >= 0 {
+		return start + run + end + run
+	}
+	return start + run
+}
+
+func isSpace(b byte) bool {
+	return b == ' ' || b == '\t'
+}
+
+// symbols maps TeX commands to their Unicode equivalents.
+var symbols = map[string]string{
+	// Greek
+	"alpha": "α", "beta": "β", "gamma": "γ", "delta": "δ", "epsilon": "ε",
+	"zeta": "ζ", "eta": "η", "theta": "θ", "iota": "ι", "kappa": "κ",
+	"lambda": "λ", "mu": "μ", "nu": "ν", "xi": "ξ", "pi": "π", "rho": "ρ",
+	"sigma": "σ", "tau": "τ", "upsilon": "υ", "phi": "φ", "chi": "χ",
+	"psi": "ψ", "omega": "ω", "varepsilon": "ε", "varphi": "ϕ",
+	"Gamma": "Γ", "Delta": "Δ", "Theta": "Θ", "Lambda": "Λ", "Xi": "Ξ",
+	"Pi": "Π", "Sigma": "Σ", "Phi": "Φ", "Psi": "Ψ", "Omega": "Ω",
+	// Operators and relations
+	"times": "×", "div": "÷", "pm": "±", "mp": "∓", "cdot": "·",
+	"le": "≤", "leq": "≤", "ge": "≥", "geq": "≥", "ne": "≠", "neq": "≠",
+	"approx": "≈", "equiv": "≡", "sim": "∼", "propto": "∝",
+	"in": "∈", "notin": "∉", "subset": "⊂", "subseteq": "⊆", "supset": "⊃",
+	"cup": "∪", "cap": "∩", "emptyset": "∅", "forall": "∀", "exists": "∃",
+	"neg": "¬", "land": "∧", "lor": "∨", "to": "→", "rightarrow": "→",
+	"leftarrow": "←", "Rightarrow": "⇒", "Leftarrow": "⇐", "iff": "⇔",
+	"mapsto": "↦", "infty": "∞", "partial": "∂", "nabla": "∇",
+	"sum": "∑", "prod": "∏", "int": "∫", "oint": "∮", "sqrt": "√",
+	"ldots": "…", "cdots": "⋯", "circ": "∘", "degree": "°",
+	// Spacing and escapes
+	",": " ", ";": " ", "quad": "  ", "qquad": "    ", " ": " ",
+	"{": "{", "}": "}", "$": "$", "%": "%", "&": "&", "_": "_", "#": "#",
+}
+
+var superscripts = map[rune]rune{
+	'0': '⁰', '1': '¹', '2': '²', '3': '³', '4': '⁴', '5': '⁵', '6': '⁶',
+	'7': '⁷', '8': '⁸', '9': '⁹', '+': '⁺', '-': '⁻', '=': '⁼', '(': '⁽',
+	')': '⁾', 'n': 'ⁿ', 'i': 'ⁱ',
+}
+
+var subscripts = map[rune]rune{
+	'0': '₀', '1': '₁', '2': '₂', '3': '₃', '4': '₄', '5': '₅', '6': '₆',
+	'7': '₇', '8': '₈', '9': '₉', '+': '₊', '-': '₋', '=': '₌', '(': '₍',
+	')': '₎', 'a': 'ₐ', 'e': 'ₑ', 'i': 'ᵢ', 'j': 'ⱼ', 'k': 'ₖ', 'n': 'ₙ',
+	'o': 'ₒ', 'x': 'ₓ',
+}
+
+// ToUnicode renders a TeX expression as Unicode text on a best-effort
+// basis: symbols, Greek letters, \frac, \sqrt and simple super/subscripts
+// are converted; anything it cannot express is kept as written.
+func ToUnicode(expr string) string {
+	var out strings.Builder
+	for i := 0; i < len(expr); {
+		switch c := expr[i]; c {
+		case '\\':
+			name, next := readCommand(expr, i+1)
+			switch name {
+			case "frac":
+				num, afterNum := readGroup(expr, next)
+				den, afterDen := readGroup(expr, afterNum)
+				out.WriteString(wrap(ToUnicode(num)) + "/" + wrap(ToUnicode(den)))
+				i = afterDen
+				continue
+			case "sqrt":
+				arg, after := readGroup(expr, next)
+				out.WriteString("√" + wrap(ToUnicode(arg)))
+				i = after
+				continue
+			case "text", "mathrm", "mathbf", "mathit", "operatorname":
+				arg, after := readGroup(expr, next)
+				out.WriteString(arg)
+				i = after
+				continue
+			case "left", "right":
+				i = next
+				continue
+			}
+			if sym, ok := symbols[name]; ok {
+				out.WriteString(sym)
+			} else {
+				out.WriteString(expr[i:next])
+			}
+			i = next
+		case '^', '_':
+			table := superscripts
+			if c == '_' {
+				table = subscripts
+			}
+			arg, after := readGroup(expr, i+1)
+			if mapped, ok := mapRunes(ToUnicode(arg), table); ok {
+				out.WriteString(mapped)
+			} else {
+				out.WriteString(string(c) + group(ToUnicode(arg)))
+			}
+			i = after
+		case '{', '}':
+			i++
+		default:
+			out.WriteByte(c)
+			i++
+		}
+	}
+	return out.String()
+}
+
+// readCommand reads a TeX command name starting after the backslash.
+func readCommand(expr string, start int) (string, int) {
+	end := start
+	for end < len(expr) && isLetter(expr[end]) {
+		end++
+	}
+	if end == start && end < len(expr) {
+		// Single-character command such as \, or \{
+		_, size := utf8.DecodeRuneInString(expr[end:])
+		end += size
+	}
+	return expr[start:end], end
+}
+
+// readGroup reads a {braced} argument or a single character at start,
+// skipping leading spaces. Returns the argument and the offset after it.
+func readGroup(expr string, start int) (string, int) {
+	for start < len(expr) && expr[start] == ' ' {
+		start++
+	}
+	if start >= len(expr) {
+		return "", start
+	}
+	if expr[start] == '\\' {
+		_, end := readCommand(expr, start+1)
+		return expr[start:end], end
+	}
+	if expr[start] != '{' {
+		_, size := utf8.DecodeRuneInString(expr[start:])
+		return expr[start : start+size], start + size
+	}
+
+	depth := 0
+	for i := start; i < len(expr); i++ {
+		switch expr[i] {
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				return expr[start+1 : i], i + 1
+			}
+		}
+	}
+	return expr[start+1:], len(expr)
+}
+
+// mapRunes converts every rune of s through table, failing if any rune
+// has no mapping.
+func mapRunes(s string, table map[rune]rune) (string, bool) {
+	if s == "" {
+		return "", false
+	}
+	var out strings.Builder
+	for _, r := range s {
+		mapped, ok := table[r]
+		if !ok {
+			return "", false
+		}
+		out.WriteRune(mapped)
+	}
+	return out.String(), true
+}
+
+// wrap parenthesizes compound expressions so a/b stays unambiguous.
+func wrap(s string) string {
+	if utf8.RuneCountInString(s) <= 1 {
+		return s
+	}
+	for _, r := range s {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			return "(" + s + ")"
+		}
+	}
+	return s
+}
+
+// group parenthesizes multi-character scripts: e^(iπ).
+func group(s string) string {
+	if utf8.RuneCountInString(s) <= 1 {
+		return s
+	}
+	return "(" + s + ")"
+}
+
+func isLetter(b byte) bool {
+	return (b >= 'a' && b <= 'z') || (b >= 'A' && b <= 'Z')
+}
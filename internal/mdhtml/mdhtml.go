@@ -0,0 +1,83 @@
+// Package mdhtml renders markdown to HTML the way the preview shows it:
+// GitHub flavored, with footnotes, definition lists, emoji shortcodes,
+// heading ids and hard line breaks.
+package mdhtml
+
+import (
+	"bytes"
+
+	"github.com/yuin/goldmark"
+	goldmarkEmoji "github.com/yuin/goldmark-emoji"
+	gast "github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/extension"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/renderer/html"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+)
+
+// Options adjust the conversion.
+type Options struct {
+	// Typographer renders smart quotes, dashes and ellipses
+	Typographer bool
+
+	// RewriteLink, when set, maps each link and image destination to the
+	// one written
+	RewriteLink func(dest string) string
+}
+
+// Convert renders markdown to an HTML fragment.
+func Convert(markdown []byte, opts Options) ([]byte, error) {
+	extensions := []goldmark.Extender{
+		extension.GFM,
+		extension.Table,
+		extension.Strikethrough,
+		extension.Linkify,
+		extension.TaskList,
+		extension.DefinitionList,
+		extension.Footnote,
+		goldmarkEmoji.Emoji,
+	}
+	if opts.Typographer {
+		extensions = append(extensions, extension.Typographer)
+	}
+
+	parserOptions := []parser.Option{parser.WithAutoHeadingID()}
+	if opts.RewriteLink != nil {
+		parserOptions = append(parserOptions, parser.WithASTTransformers(
+			util.Prioritized(linkRewriter(opts.RewriteLink), 100)))
+	}
+
+	md := goldmark.New(
+		goldmark.WithExtensions(extensions...),
+		goldmark.WithParserOptions(parserOptions...),
+		goldmark.WithRendererOptions(
+			html.WithHardWraps(),
+			html.WithXHTML(),
+		),
+	)
+
+	var buf bytes.Buffer
+	if err := md.Convert(markdown, &buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// linkRewriter applies a RewriteLink function to the parsed document.
+type linkRewriter func(dest string) string
+
+func (rewrite linkRewriter) Transform(doc *gast.Document, reader text.Reader, pc parser.Context) {
+	gast.Walk(doc, func(n gast.Node, entering bool) (gast.WalkStatus, error) {
+		if !entering {
+			return gast.WalkContinue, nil
+		}
+		switch n := n.(type) {
+		case *gast.Link:
+			n.Destination = []byte(rewrite(string(n.Destination)))
+		case *gast.Image:
+			n.Destination = []byte(rewrite(string(n.Destination)))
+		}
+		return gast.WalkContinue, nil
+	})
+}
@@ -0,0 +1,112 @@
+// Package pandoc exports markdown to other formats (docx, epub, latex,
+// ...) through the pandoc command, reporting its progress as it runs.
+package pandoc
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// DefaultCommand is run when no pandoc command is configured.
+const DefaultCommand = "pandoc"
+
+// extensions maps output formats to the file extension they are written
+// with, where it differs from the format's name.
+var extensions = map[string]string{
+	"latex":      ".tex",
+	"beamer":     ".tex",
+	"context":    ".tex",
+	"markdown":   ".md",
+	"gfm":        ".md",
+	"commonmark": ".md",
+	"plain":      ".txt",
+	"html5":      ".html",
+	"revealjs":   ".html",
+	"asciidoc":   ".adoc",
+	"mediawiki":  ".wiki",
+	"docbook":    ".xml",
+	"jats":       ".xml",
+}
+
+// OutputPath returns the path next to input with the extension of format,
+// as in notes.md to notes.docx.
+func OutputPath(input, format string) string {
+	ext, ok := extensions[format]
+	if !ok {
+		ext = "." + format
+	}
+	return strings.TrimSuffix(input, filepath.Ext(input)) + ext
+}
+
+// Options describe one conversion.
+type Options struct {
+	Command string   // The pandoc executable; DefaultCommand when empty
+	To      string   // Output format, e.g. "docx"
+	Output  string   // File to write
+	Dir     string   // Directory relative images and links resolve from
+	Args    []string // Further pandoc options
+}
+
+// Convert runs pandoc on markdown, writing opts.Output. Each line pandoc
+// logs is passed to progress as it arrives. A failed conversion reports
+// pandoc's error messages.
+func Convert(ctx context.Context, markdown string, opts Options, progress func(line string)) error {
+	if opts.To == "" || opts.Output == "" {
+		return errors.New("pandoc: no output format or file given")
+	}
+	command := opts.Command
+	if command == "" {
+		command = DefaultCommand
+	}
+	args := append([]string{"--from", "markdown", "--to", opts.To, "--standalone",
+		"--verbose", "--output", opts.Output}, opts.Args...)
+
+	cmd := exec.CommandContext(ctx, command, args...)
+	cmd.Dir = opts.Dir
+	cmd.Stdin = strings.NewReader(markdown)
+	stderr, err := cmd.StderrPipe()
+	if err != nil {
+		return err
+	}
+	if err := cmd.Start(); err != nil {
+		if errors.Is(err, exec.ErrNotFound) {
+			return fmt.Errorf("%s not found; install pandoc or set pandoc_command", command)
+		}
+		return err
+	}
+
+	// Everything but the [INFO] chatter explains a failure
+	var problems []string
+	scanner := bufio.NewScanner(stderr)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if progress != nil && strings.TrimSpace(line) != "" {
+			progress(line)
+		}
+		if !strings.HasPrefix(line, "[INFO]") && strings.TrimSpace(line) != "" {
+			problems = append(problems, strings.TrimSpace(line))
+		}
+	}
+
+	if err := cmd.Wait(); err != nil {
+		if ctx.Err() != nil {
+			return fmt.Errorf("pandoc: %w", ctx.Err())
+		}
+		if len(problems) > 0 {
+			return fmt.Errorf("pandoc: %s", strings.Join(problems, "; "))
+		}
+		return fmt.Errorf("pandoc: %w", err)
+	}
+	return nil
+}
+
+// ArgsFor returns the configured options for format: those under "all",
+// then the format's own.
+func ArgsFor(byFormat map[string][]string, format string) []string {
+	return append(append([]string{}, byFormat["all"]...), byFormat[format]...)
+}
@@ -1,9 +1,12 @@
 package plugins
 
 import (
+	"context"
 	"fmt"
+	"github.com/ofri/mde/internal/config"
 	"github.com/ofri/mde/internal/plugins/parsers"
 	"github.com/ofri/mde/internal/plugins/renderers"
+	"github.com/ofri/mde/internal/plugins/storage"
 	"github.com/ofri/mde/pkg/plugin"
 )
 
@@ -19,14 +22,73 @@ func InitializePlugins() error {
 		return fmt.Errorf("failed to initialize parsers: %w", err)
 	}
 	
+	// Initialize storages
+	if err := initializeStorages(); err != nil {
+		return fmt.Errorf("failed to initialize storages: %w", err)
+	}
+	
 	// Set default plugins
 	if err := setDefaultPlugins(); err != nil {
 		return fmt.Errorf("failed to set default plugins: %w", err)
 	}
 	
+	// Map file extensions to parsers
+	if err := initializeFileTypes(); err != nil {
+		return fmt.Errorf("failed to initialize file types: %w", err)
+	}
+	
+	// Configure with defaults until the user configuration is loaded
+	if err := Configure(config.Default()); err != nil {
+		return fmt.Errorf("failed to configure plugins: %w", err)
+	}
+	
+	// Run Init hooks, dependencies first
+	if err := plugin.GetRegistry().Init(context.Background()); err != nil {
+		return fmt.Errorf("failed to start plugins: %w", err)
+	}
+	
 	return nil
 }
 
+// Shutdown runs the plugins' Shutdown hooks before exit
+func Shutdown() error {
+	return plugin.GetRegistry().Shutdown(context.Background())
+}
+
+// Configure applies the user configuration to every registered plugin in
+// one pass. Plugins left out of the build are skipped.
+func Configure(cfg *config.Config) error {
+	registry := plugin.GetRegistry()
+	
+	options := map[string]map[string]interface{}{
+		"commonmark": {
+			"wiki_links": cfg.WikiLinks,
+		},
+		"terminal": {
+			"tabWidth":    4,
+			"wikiLinks":   cfg.WikiLinks,
+			"mathUnicode": cfg.MathUnicode,
+			"typographer": cfg.Typographer,
+		},
+		"http": {
+			"maxBytes": int64(cfg.MaxDownloadMB) << 20,
+		},
+	}
+	
+	registered := make(map[string]bool)
+	names := append(registry.ListParsers(), registry.ListRenderers()...)
+	for _, name := range append(names, registry.ListStorages()...) {
+		registered[name] = true
+	}
+	for name := range options {
+		if !registered[name] {
+			delete(options, name)
+		}
+	}
+	
+	return registry.Configure(options)
+}
+
 // initializeRenderers registers all built-in renderers
 func initializeRenderers() error {
 	registry := plugin.GetRegistry()
@@ -37,16 +99,6 @@ func initializeRenderers() error {
 		return fmt.Errorf("failed to register terminal renderer: %w", err)
 	}
 	
-	// Configure renderer with sensible defaults
-	rendererConfig := map[string]interface{}{
-		"showLineNumbers": true,
-		"tabWidth":        4,
-	}
-	
-	if err := terminalRenderer.Configure(rendererConfig); err != nil {
-		return fmt.Errorf("failed to configure terminal renderer: %w", err)
-	}
-	
 	return nil
 }
 
@@ -60,6 +112,67 @@ func initializeParsers() error {
 		return fmt.Errorf("failed to register CommonMark parser: %w", err)
 	}
 	
+	// Register plain-text parser for non-markdown files
+	plainParser := parsers.NewPlainParser()
+	if err := registry.RegisterParser(plainParser.Name(), plainParser); err != nil {
+		return fmt.Errorf("failed to register plain parser: %w", err)
+	}
+	
+	return nil
+}
+
+// initializeStorages registers the built-in storages: local files,
+// ssh:// and sftp:// hosts and read-only http(s) URLs
+func initializeStorages() error {
+	registry := plugin.GetRegistry()
+	
+	for _, s := range []plugin.StoragePlugin{storage.NewLocal(), storage.NewSSH(), storage.NewHTTP()} {
+		if err := registry.RegisterStorage(s.Name(), s); err != nil {
+			return fmt.Errorf("failed to register %s storage: %w", s.Name(), err)
+		}
+	}
+	
+	return nil
+}
+
+// defaultFileTypes maps file extensions to the parser that opens them;
+// anything else uses the default parser
+var defaultFileTypes = map[string]string{
+	".md":       "commonmark",
+	".markdown": "commonmark",
+	".mdown":    "commonmark",
+	".mkd":      "commonmark",
+	".txt":      "plain",
+	".text":     "plain",
+	".log":      "plain",
+	".csv":      "plain",
+	".tsv":      "plain",
+}
+
+// initializeFileTypes registers the built-in extension mappings
+func initializeFileTypes() error {
+	registry := plugin.GetRegistry()
+	
+	for ext, parser := range defaultFileTypes {
+		if err := registry.RegisterFileType(ext, parser); err != nil {
+			return fmt.Errorf("failed to register file type %s: %w", ext, err)
+		}
+	}
+	
+	return nil
+}
+
+// RegisterFileTypes adds user extension mappings (extension → parser
+// name) over the built-in ones
+func RegisterFileTypes(fileTypes map[string]string) error {
+	registry := plugin.GetRegistry()
+	
+	for ext, parser := range fileTypes {
+		if err := registry.RegisterFileType(ext, parser); err != nil {
+			return fmt.Errorf("file type %s: %w", ext, err)
+		}
+	}
+	
 	return nil
 }
 
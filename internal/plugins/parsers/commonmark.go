@@ -4,21 +4,47 @@ import (
 	"context"
 	"regexp"
 	"strings"
+	"sync"
 
 	"github.com/yuin/goldmark"
+	goldmarkEmoji "github.com/yuin/goldmark-emoji"
 	"github.com/yuin/goldmark/extension"
 	"github.com/yuin/goldmark/parser"
 	goldmarkText "github.com/yuin/goldmark/text"
 
+	"github.com/ofri/mde/internal/cite"
+	"github.com/ofri/mde/internal/critic"
+	"github.com/ofri/mde/internal/emoji"
+	"github.com/ofri/mde/internal/links"
+	"github.com/ofri/mde/internal/mathtext"
+	"github.com/ofri/mde/internal/wiki"
 	mdeAST "github.com/ofri/mde/pkg/ast"
 	"github.com/ofri/mde/pkg/plugin"
 )
 
-// CommonMarkParser implements the ParserPlugin interface using goldmark
+var (
+	strikeRe    = regexp.MustCompile(`~~[^~\s](?:[^~]*[^~\s])?~~`)
+	highlightRe = regexp.MustCompile(`==[^=\s](?:[^=]*[^=\s])?==`)
+)
+
+// criticTokens are the tokens of the text in CriticMarkup marks by kind.
+var criticTokens = map[critic.Kind]mdeAST.TokenKind{
+	critic.Insertion: mdeAST.TokenInsertion,
+	critic.Deletion:  mdeAST.TokenDeletion,
+	critic.Comment:   mdeAST.TokenCriticComment,
+}
+
+// CommonMarkParser implements the ParserPlugin interface using goldmark.
+// It is safe for concurrent use; mu guards the configuration.
 type CommonMarkParser struct {
 	name     string
 	goldmark goldmark.Markdown
-	config   *plugin.ParserConfig
+	
+	mu     sync.RWMutex
+	config *plugin.ParserConfig
+	
+	// wikiLinks enables [[Page Name]] link recognition
+	wikiLinks bool
 }
 
 // NewCommonMarkParser creates a new CommonMark parser
@@ -31,6 +57,8 @@ func NewCommonMarkParser() *CommonMarkParser {
 			extension.Strikethrough,
 			extension.Linkify,
 			extension.TaskList,
+			extension.DefinitionList,
+			goldmarkEmoji.Emoji,
 		),
 		goldmark.WithParserOptions(
 			parser.WithAutoHeadingID(),
@@ -41,7 +69,7 @@ func NewCommonMarkParser() *CommonMarkParser {
 		name:     "commonmark",
 		goldmark: md,
 		config: &plugin.ParserConfig{
-			Extensions:         []string{"gfm", "table", "strikethrough", "linkify", "tasklist"},
+			Extensions:         []string{"gfm", "table", "strikethrough", "linkify", "tasklist", "definitionlist", "emoji"},
 			SyntaxHighlighting: true,
 			Options:            make(map[string]interface{}),
 		},
@@ -53,6 +81,11 @@ func (p *CommonMarkParser) Name() string {
 	return p.name
 }
 
+// NewInstance returns an independent parser with default settings
+func (p *CommonMarkParser) NewInstance() plugin.ParserPlugin {
+	return NewCommonMarkParser()
+}
+
 // Parse parses markdown text into an AST
 func (p *CommonMarkParser) Parse(ctx context.Context, text string) (*mdeAST.Document, error) {
 	// Parse with goldmark for validation (full AST conversion comes later)
@@ -79,6 +112,15 @@ func (p *CommonMarkParser) Parse(ctx context.Context, text string) (*mdeAST.Docu
 		}
 	}
 	
+	// Fenced and display math blocks and definition terms depend on
+	// neighbouring lines, which per-line highlighting cannot see
+	p.tokenizeFencedBlocks(doc)
+	p.tokenizeMathBlocks(doc)
+	p.tokenizeDefinitionTerms(doc)
+	p.tokenizeListDepths(doc)
+	p.tokenizeSetextHeadings(doc)
+	p.tokenizeHTMLBlocks(doc)
+	
 	return doc, nil
 }
 
@@ -90,10 +132,23 @@ func (p *CommonMarkParser) GetSyntaxHighlighting(ctx context.Context, line strin
 	// Parse the line to identify markdown elements
 	tokens = append(tokens, p.parseHeadings(line)...)
 	tokens = append(tokens, p.parseBoldItalic(line)...)
+	tokens = append(tokens, p.parseStrikeHighlight(line)...)
+	tokens = append(tokens, p.parseCritic(line)...)
 	tokens = append(tokens, p.parseCode(line)...)
 	tokens = append(tokens, p.parseLinks(line)...)
+	tokens = append(tokens, p.parseCitations(line)...)
+	tokens = append(tokens, p.parseMath(line)...)
+	tokens = append(tokens, p.parseEmoji(line)...)
 	tokens = append(tokens, p.parseQuotes(line)...)
 	tokens = append(tokens, p.parseLists(line)...)
+	tokens = append(tokens, p.parseDefinitions(line)...)
+	tokens = append(tokens, p.parseHTML(line)...)
+	p.mu.RLock()
+	wikiLinks := p.wikiLinks
+	p.mu.RUnlock()
+	if wikiLinks {
+		tokens = append(tokens, p.parseWikiLinks(line)...)
+	}
 	
 	// Sort tokens by start position
 	for i := 0; i < len(tokens)-1; i++ {
@@ -104,7 +159,8 @@ func (p *CommonMarkParser) GetSyntaxHighlighting(ctx context.Context, line strin
 		}
 	}
 	
-	return tokens, nil
+	// The rules above work in bytes; tokens carry rune offsets
+	return mdeAST.RuneTokens(line, tokens), nil
 }
 
 // Configure configures the parser with options
@@ -113,6 +169,9 @@ func (p *CommonMarkParser) Configure(options map[string]interface{}) error {
 		return nil
 	}
 	
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	
 	// Update config
 	if extensions, ok := options["extensions"].([]string); ok {
 		p.config.Extensions = extensions
@@ -122,6 +181,10 @@ func (p *CommonMarkParser) Configure(options map[string]interface{}) error {
 		p.config.SyntaxHighlighting = syntaxHighlighting
 	}
 	
+	if wikiLinks, ok := options["wiki_links"].(bool); ok {
+		p.wikiLinks = wikiLinks
+	}
+	
 	for key, value := range options {
 		p.config.Options[key] = value
 	}
@@ -153,6 +216,24 @@ func (p *CommonMarkParser) parseHeadings(line string) []mdeAST.Token {
 	return tokens
 }
 
+// tokenizeSetextHeadings marks heading text underlined by === or --- on
+// the following line, and the underline itself as a delimiter.
+func (p *CommonMarkParser) tokenizeSetextHeadings(doc *mdeAST.Document) {
+	for i := 0; i < doc.LineCount(); i++ {
+		level, underline := doc.SetextHeadingAt(i)
+		if level == 0 {
+			continue
+		}
+		length := doc.GetLineLength(i)
+		if underline {
+			doc.SetLineTokens(i, []mdeAST.Token{mdeAST.NewToken(0, length, mdeAST.TokenDelimiter)})
+			continue
+		}
+		heading := mdeAST.NewToken(0, length, mdeAST.TokenHeading)
+		doc.SetLineTokens(i, append([]mdeAST.Token{heading}, doc.GetLineTokens(i)...))
+	}
+}
+
 func (p *CommonMarkParser) parseBoldItalic(line string) []mdeAST.Token {
 	var tokens []mdeAST.Token
 	
@@ -182,6 +263,38 @@ func (p *CommonMarkParser) parseBoldItalic(line string) []mdeAST.Token {
 	return tokens
 }
 
+func (p *CommonMarkParser) parseStrikeHighlight(line string) []mdeAST.Token {
+	var tokens []mdeAST.Token
+	
+	// Strikethrough (~~text~~) and highlight (==text==)
+	for _, match := range strikeRe.FindAllStringIndex(line, -1) {
+		tokens = append(tokens, mdeAST.NewToken(match[0], match[0]+2, mdeAST.TokenDelimiter))
+		tokens = append(tokens, mdeAST.NewToken(match[0]+2, match[1]-2, mdeAST.TokenStrikethrough))
+		tokens = append(tokens, mdeAST.NewToken(match[1]-2, match[1], mdeAST.TokenDelimiter))
+	}
+	for _, match := range highlightRe.FindAllStringIndex(line, -1) {
+		tokens = append(tokens, mdeAST.NewToken(match[0], match[0]+2, mdeAST.TokenDelimiter))
+		tokens = append(tokens, mdeAST.NewToken(match[0]+2, match[1]-2, mdeAST.TokenHighlight))
+		tokens = append(tokens, mdeAST.NewToken(match[1]-2, match[1], mdeAST.TokenDelimiter))
+	}
+	
+	return tokens
+}
+
+func (p *CommonMarkParser) parseCritic(line string) []mdeAST.Token {
+	var tokens []mdeAST.Token
+	
+	// CriticMarkup {++insertion++}, {--deletion--} and {>>comment<<}
+	for _, span := range critic.Spans(line) {
+		body, end := span.Start+len(critic.Open(span.Kind)), span.End-len(critic.Close(span.Kind))
+		tokens = append(tokens, mdeAST.NewToken(span.Start, body, mdeAST.TokenDelimiter))
+		tokens = append(tokens, mdeAST.NewToken(body, end, criticTokens[span.Kind]))
+		tokens = append(tokens, mdeAST.NewToken(end, span.End, mdeAST.TokenDelimiter))
+	}
+	
+	return tokens
+}
+
 func (p *CommonMarkParser) parseCode(line string) []mdeAST.Token {
 	var tokens []mdeAST.Token
 	
@@ -204,18 +317,46 @@ func (p *CommonMarkParser) parseCode(line string) []mdeAST.Token {
 func (p *CommonMarkParser) parseLinks(line string) []mdeAST.Token {
 	var tokens []mdeAST.Token
 	
-	// Links [text](url)
-	linkRe := regexp.MustCompile(`\[([^\]]+)\]\(([^)]+)\)`)
-	for _, match := range linkRe.FindAllStringSubmatchIndex(line, -1) {
-		if len(match) >= 6 {
-			// Link text
-			tokens = append(tokens, mdeAST.NewToken(match[2], match[3], mdeAST.TokenLinkText))
-			// Link URL
-			tokens = append(tokens, mdeAST.NewToken(match[4], match[5], mdeAST.TokenLinkURL))
-			// Delimiters
-			tokens = append(tokens, mdeAST.NewToken(match[0], match[2], mdeAST.TokenDelimiter))
-			tokens = append(tokens, mdeAST.NewToken(match[3], match[4], mdeAST.TokenDelimiter))
-			tokens = append(tokens, mdeAST.NewToken(match[5], match[1], mdeAST.TokenDelimiter))
+	for _, link := range links.Find(line) {
+		switch link.Kind {
+		case links.Inline:
+			// [text](url)
+			tokens = append(tokens, mdeAST.NewToken(link.TextStart, link.TextEnd, mdeAST.TokenLinkText))
+			tokens = append(tokens, mdeAST.NewToken(link.URLStart, link.URLEnd, mdeAST.TokenLinkURL))
+			tokens = append(tokens, mdeAST.NewToken(link.Start, link.TextStart, mdeAST.TokenDelimiter))
+			tokens = append(tokens, mdeAST.NewToken(link.TextEnd, link.URLStart, mdeAST.TokenDelimiter))
+			tokens = append(tokens, mdeAST.NewToken(link.URLEnd, link.End, mdeAST.TokenDelimiter))
+		case links.Reference:
+			// [text][id]
+			tokens = append(tokens, mdeAST.NewToken(link.Start, link.TextStart, mdeAST.TokenDelimiter))
+			tokens = append(tokens, mdeAST.NewToken(link.TextStart, link.TextEnd, mdeAST.TokenLinkText))
+			tokens = append(tokens, mdeAST.NewToken(link.TextEnd, link.LabelStart, mdeAST.TokenDelimiter))
+			if link.LabelEnd > link.LabelStart {
+				tokens = append(tokens, mdeAST.NewToken(link.LabelStart, link.LabelEnd, mdeAST.TokenLinkReference))
+			}
+			tokens = append(tokens, mdeAST.NewToken(link.LabelEnd, link.End, mdeAST.TokenDelimiter))
+		case links.Definition:
+			// [id]: url
+			tokens = append(tokens, mdeAST.NewToken(link.Start, link.LabelStart, mdeAST.TokenDelimiter))
+			tokens = append(tokens, mdeAST.NewToken(link.LabelStart, link.LabelEnd, mdeAST.TokenLinkDefinition))
+			tokens = append(tokens, mdeAST.NewToken(link.LabelEnd, link.LabelEnd+2, mdeAST.TokenDelimiter))
+			tokens = append(tokens, mdeAST.NewToken(link.URLStart, link.URLEnd, mdeAST.TokenLinkURL))
+		case links.Autolink:
+			// <https://example.com>
+			tokens = append(tokens, mdeAST.NewToken(link.Start, link.TextStart, mdeAST.TokenDelimiter))
+			tokens = append(tokens, mdeAST.NewToken(link.TextStart, link.TextEnd, mdeAST.TokenAutolink))
+			tokens = append(tokens, mdeAST.NewToken(link.TextEnd, link.End, mdeAST.TokenDelimiter))
+		case links.Bare:
+			// https://example.com
+			tokens = append(tokens, mdeAST.NewToken(link.Start, link.End, mdeAST.TokenAutolink))
+		case links.FootnoteRef:
+			// [^1]
+			tokens = append(tokens, mdeAST.NewToken(link.Start, link.End, mdeAST.TokenFootnoteRef))
+		case links.FootnoteDefinition:
+			// [^1]: text
+			tokens = append(tokens, mdeAST.NewToken(link.Start, link.LabelStart, mdeAST.TokenDelimiter))
+			tokens = append(tokens, mdeAST.NewToken(link.LabelStart, link.LabelEnd, mdeAST.TokenFootnoteDefinition))
+			tokens = append(tokens, mdeAST.NewToken(link.LabelEnd, link.End, mdeAST.TokenDelimiter))
 		}
 	}
 	
@@ -230,56 +371,190 @@ func (p *CommonMarkParser) parseLinks(line string) []mdeAST.Token {
 	return tokens
 }
 
-func (p *CommonMarkParser) parseQuotes(line string) []mdeAST.Token {
+func (p *CommonMarkParser) parseCitations(line string) []mdeAST.Token {
 	var tokens []mdeAST.Token
 	
-	if strings.HasPrefix(strings.TrimSpace(line), ">") {
-		// Find the first >
-		index := strings.Index(line, ">")
-		if index >= 0 {
-			tokens = append(tokens, mdeAST.NewToken(index, index+1, mdeAST.TokenDelimiter))
-			if index+1 < len(line) {
-				tokens = append(tokens, mdeAST.NewToken(index+1, len(line), mdeAST.TokenQuote))
-			}
+	// Citations [@key], [see @key, p. 3; -@other]
+	for _, citation := range cite.FindCitations(line) {
+		tokens = append(tokens, mdeAST.NewToken(citation.Start, citation.Start+1, mdeAST.TokenDelimiter))
+		for _, key := range citation.Keys {
+			tokens = append(tokens, mdeAST.NewToken(key.Start, key.End, mdeAST.TokenCitation))
 		}
+		tokens = append(tokens, mdeAST.NewToken(citation.End-1, citation.End, mdeAST.TokenDelimiter))
 	}
 	
 	return tokens
 }
 
-func (p *CommonMarkParser) parseLists(line string) []mdeAST.Token {
+func (p *CommonMarkParser) parseMath(line string) []mdeAST.Token {
 	var tokens []mdeAST.Token
 	
-	trimmed := strings.TrimSpace(line)
+	// A lone $$ line opens or closes a display block
+	if mathtext.IsBlockDelimiter(line) {
+		return append(tokens, mdeAST.NewToken(0, len(line), mdeAST.TokenDelimiter))
+	}
+	
+	// Inline $x$ and single-line display $$x$$
+	for _, span := range mathtext.FindSpans(line) {
+		kind := mdeAST.TokenMath
+		if span.Display {
+			kind = mdeAST.TokenMathBlock
+		}
+		d := span.DelimiterLen()
+		tokens = append(tokens, mdeAST.NewToken(span.Start, span.Start+d, mdeAST.TokenDelimiter))
+		tokens = append(tokens, mdeAST.NewToken(span.Start+d, span.End-d, kind))
+		tokens = append(tokens, mdeAST.NewToken(span.End-d, span.End, mdeAST.TokenDelimiter))
+	}
 	
-	// Unordered lists (-, *, +)
-	if len(trimmed) > 0 && (trimmed[0] == '-' || trimmed[0] == '*' || trimmed[0] == '+') {
-		index := strings.Index(line, string(trimmed[0]))
-		if index >= 0 {
-			tokens = append(tokens, mdeAST.NewToken(index, index+1, mdeAST.TokenDelimiter))
-			if index+1 < len(line) {
-				tokens = append(tokens, mdeAST.NewToken(index+1, len(line), mdeAST.TokenList))
+	return tokens
+}
+
+// tokenizeFencedBlocks marks every line of a fenced code block as code,
+// fences included, so markdown inside one is not highlighted.
+func (p *CommonMarkParser) tokenizeFencedBlocks(doc *mdeAST.Document) {
+	for _, span := range doc.Spans() {
+		if span.Kind != mdeAST.SpanFence {
+			continue
+		}
+		for i := span.Start.Line; i <= span.End.Line; i++ {
+			doc.SetLineTokens(i, []mdeAST.Token{mdeAST.NewToken(0, doc.GetLineLength(i), mdeAST.TokenCodeBlock)})
+		}
+	}
+}
+
+// tokenizeMathBlocks replaces the per-line tokens of display math block
+// bodies, whose TeX would otherwise be highlighted as markdown.
+func (p *CommonMarkParser) tokenizeMathBlocks(doc *mdeAST.Document) {
+	for _, block := range doc.MathBlocks() {
+		for i := block.StartLine + 1; i <= block.EndLine; i++ {
+			if i == block.EndLine && block.Closed {
+				break
 			}
+			doc.SetLineTokens(i, []mdeAST.Token{mdeAST.NewToken(0, doc.GetLineLength(i), mdeAST.TokenMathBlock)})
+		}
+	}
+}
+
+func (p *CommonMarkParser) parseHTML(line string) []mdeAST.Token {
+	var tokens []mdeAST.Token
+	
+	// Inline tags <kbd>, </span>, <br/> and <!-- comments -->
+	for _, tag := range mdeAST.FindHTMLTags(line) {
+		tokens = append(tokens, mdeAST.NewToken(tag.Start, tag.End, mdeAST.TokenHTMLInline))
+	}
+	
+	return tokens
+}
+
+// tokenizeHTMLBlocks marks every line of a raw HTML block as HTML, since
+// markdown inside one is not interpreted.
+func (p *CommonMarkParser) tokenizeHTMLBlocks(doc *mdeAST.Document) {
+	for _, block := range doc.HTMLBlocks() {
+		for i := block.StartLine; i <= block.EndLine; i++ {
+			doc.SetLineTokens(i, []mdeAST.Token{mdeAST.NewToken(0, doc.GetLineLength(i), mdeAST.TokenHTMLBlock)})
 		}
 	}
+}
+
+func (p *CommonMarkParser) parseEmoji(line string) []mdeAST.Token {
+	var tokens []mdeAST.Token
 	
-	// Ordered lists (1., 2., etc.)
-	orderedRe := regexp.MustCompile(`^\s*(\d+\.)\s*(.*)`)
-	matches := orderedRe.FindStringSubmatch(line)
-	if len(matches) > 0 {
-		numLen := len(matches[1])
-		start := strings.Index(line, matches[1])
-		if start >= 0 {
-			tokens = append(tokens, mdeAST.NewToken(start, start+numLen, mdeAST.TokenDelimiter))
-			if start+numLen < len(line) {
-				tokens = append(tokens, mdeAST.NewToken(start+numLen, len(line), mdeAST.TokenList))
+	// Emoji shortcodes :smile:
+	for _, code := range emoji.FindShortcodes(line) {
+		tokens = append(tokens, mdeAST.NewToken(code.Start, code.End, mdeAST.TokenEmoji))
+	}
+	
+	return tokens
+}
+
+func (p *CommonMarkParser) parseWikiLinks(line string) []mdeAST.Token {
+	var tokens []mdeAST.Token
+	
+	// Wiki links [[Page Name]], [[Page|alias]], [[Page#heading]]
+	for _, link := range wiki.FindLinks(line) {
+		tokens = append(tokens, mdeAST.NewToken(link.Start, link.Start+2, mdeAST.TokenDelimiter))
+		tokens = append(tokens, mdeAST.NewToken(link.Start+2, link.End-2, mdeAST.TokenWikiLink))
+		tokens = append(tokens, mdeAST.NewToken(link.End-2, link.End, mdeAST.TokenDelimiter))
+	}
+	
+	return tokens
+}
+
+func (p *CommonMarkParser) parseQuotes(line string) []mdeAST.Token {
+	var tokens []mdeAST.Token
+	
+	// Each > marker is a delimiter; the content carries the nesting depth
+	depth, markers, content := mdeAST.QuoteDepth(line)
+	for _, marker := range markers {
+		tokens = append(tokens, mdeAST.NewToken(marker, marker+1, mdeAST.TokenDelimiter))
+	}
+	if depth > 0 && content < len(line) {
+		tokens = append(tokens, mdeAST.NewNestedToken(content, len(line), mdeAST.TokenQuote, depth))
+	}
+	
+	return tokens
+}
+
+func (p *CommonMarkParser) parseLists(line string) []mdeAST.Token {
+	var tokens []mdeAST.Token
+	
+	// Unordered (-, *, +) and ordered (1., 1)) lists
+	item, ok := mdeAST.ParseListItem(line)
+	if !ok {
+		return tokens
+	}
+	
+	// Without the surrounding lines, estimate depth from indentation;
+	// Parse refines it with the enclosing items
+	depth := item.Indent/2 + 1
+	tokens = append(tokens, mdeAST.NewToken(item.Marker, item.MarkerEnd, mdeAST.TokenDelimiter))
+	if item.MarkerEnd < len(line) {
+		tokens = append(tokens, mdeAST.NewNestedToken(item.MarkerEnd, len(line), mdeAST.TokenList, depth))
+	}
+	
+	return tokens
+}
+
+// tokenizeListDepths corrects list token depths using the enclosing items,
+// so 2- and 4-space nesting styles both count one level per step.
+func (p *CommonMarkParser) tokenizeListDepths(doc *mdeAST.Document) {
+	for i, depth := range doc.ListDepths() {
+		if depth == 0 {
+			continue
+		}
+		tokens := doc.GetLineTokens(i)
+		for j, token := range tokens {
+			if token.Kind() == mdeAST.TokenList {
+				tokens[j] = mdeAST.NewNestedToken(token.Start(), token.End(), token.Kind(), depth)
 			}
 		}
 	}
+}
+
+func (p *CommonMarkParser) parseDefinitions(line string) []mdeAST.Token {
+	var tokens []mdeAST.Token
+	
+	// Definition list entries (: definition)
+	if colon, content, ok := mdeAST.DefinitionMarker(line); ok {
+		tokens = append(tokens, mdeAST.NewToken(colon, colon+1, mdeAST.TokenDelimiter))
+		tokens = append(tokens, mdeAST.NewToken(content, len(line), mdeAST.TokenDefinition))
+	}
 	
 	return tokens
 }
 
+// tokenizeDefinitionTerms marks the lines introducing a definition list,
+// which are only recognizable by the definition that follows them.
+func (p *CommonMarkParser) tokenizeDefinitionTerms(doc *mdeAST.Document) {
+	for i := 0; i < doc.LineCount(); i++ {
+		if !doc.IsDefinitionTerm(i) {
+			continue
+		}
+		term := mdeAST.NewToken(0, doc.GetLineLength(i), mdeAST.TokenDefinitionTerm)
+		doc.SetLineTokens(i, append([]mdeAST.Token{term}, doc.GetLineTokens(i)...))
+	}
+}
+
 func (p *CommonMarkParser) isInsideBold(line string, pos int) bool {
 	// Check if position is inside bold markup
 	boldRe := regexp.MustCompile(`\*\*(.*?)\*\*|__(.*?)__`)
@@ -0,0 +1,56 @@
+package parsers
+
+import (
+	"context"
+	"sync"
+
+	mdeAST "github.com/ofri/mde/pkg/ast"
+	"github.com/ofri/mde/pkg/plugin"
+)
+
+// PlainParser implements the ParserPlugin interface for non-markdown
+// files such as .txt and .log: text is kept as written and produces no
+// syntax tokens, so nothing is highlighted or rendered as markdown.
+type PlainParser struct {
+	mu     sync.Mutex
+	config *plugin.ParserConfig
+}
+
+// NewPlainParser creates a new plain-text parser
+func NewPlainParser() *PlainParser {
+	return &PlainParser{
+		config: &plugin.ParserConfig{
+			Options: make(map[string]interface{}),
+		},
+	}
+}
+
+// Name returns the plugin name
+func (p *PlainParser) Name() string {
+	return "plain"
+}
+
+// NewInstance returns an independent plain parser
+func (p *PlainParser) NewInstance() plugin.ParserPlugin {
+	return NewPlainParser()
+}
+
+// Parse wraps text in a document without tokens
+func (p *PlainParser) Parse(ctx context.Context, text string) (*mdeAST.Document, error) {
+	return mdeAST.NewDocument(text), nil
+}
+
+// GetSyntaxHighlighting returns no tokens: plain text has no syntax
+func (p *PlainParser) GetSyntaxHighlighting(ctx context.Context, line string) ([]mdeAST.Token, error) {
+	return nil, nil
+}
+
+// Configure records options; the plain parser has none of its own
+func (p *PlainParser) Configure(options map[string]interface{}) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for key, value := range options {
+		p.config.Options[key] = value
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+package renderers
+
+import (
+	"strings"
+
+	"github.com/ofri/mde/pkg/plugin"
+	"github.com/rivo/uniseg"
+)
+
+// cluster returns the runes [start, end) of the grapheme cluster holding
+// rune col of runes, and the cells it takes. An emoji with a variation
+// selector or zero-width joiners is one cluster, as is a letter with its
+// combining marks, so the cursor covers all of it and never splits it.
+func cluster(runes []rune, col int) (start, end, width int) {
+	graphemes := uniseg.NewGraphemes(string(runes))
+	for graphemes.Next() {
+		end = start + len(graphemes.Runes())
+		if col < end {
+			return start, end, max(graphemes.Width(), 1)
+		}
+		start = end
+	}
+	return col, col + 1, 1
+}
+
+// replaceCluster returns runes with [start, end) replaced by glyph drawn
+// width cells wide, padded with spaces if fill is false, and styles moved
+// to match, so the rest of the line keeps its columns.
+func replaceCluster(runes []rune, styles []plugin.StyleRange, start, end, width int, glyph rune, fill bool) ([]rune, []plugin.StyleRange) {
+	pad := " "
+	if fill {
+		pad = string(glyph)
+	}
+	drawn := []rune(string(glyph) + strings.Repeat(pad, width-1))
+	replaced := append(append(append([]rune{}, runes[:start]...), drawn...), runes[end:]...)
+
+	shift := len(drawn) - (end - start)
+	move := func(pos int) int {
+		switch {
+		case pos >= end:
+			return pos + shift
+		case pos > start:
+			return start + len(drawn)
+		default:
+			return pos
+		}
+	}
+	moved := make([]plugin.StyleRange, 0, len(styles))
+	for _, s := range styles {
+		if from, to := move(s.Start), move(s.End); from < to {
+			moved = append(moved, plugin.StyleRange{Start: from, End: to, Style: s.Style})
+		}
+	}
+	return replaced, moved
+}
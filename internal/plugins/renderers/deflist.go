@@ -0,0 +1,84 @@
+package renderers
+
+import (
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/ofri/mde/pkg/ast"
+	"github.com/ofri/mde/pkg/plugin"
+)
+
+// definitionIndent is the hanging indent for definitions under their term.
+const definitionIndent = "    "
+
+// renderDefinitionListLine renders definition list terms in bold and hangs
+// definitions (and their indented continuation lines) under them.
+// Returns false if the line is not part of a definition list.
+func (r *TerminalRenderer) renderDefinitionListLine(doc *ast.Document, lineNum int, line string) (plugin.RenderedLine, bool) {
+	if _, content, ok := ast.DefinitionMarker(line); ok {
+		return r.indentInline(line[content:]), true
+	}
+
+	if doc.IsDefinitionTerm(lineNum) {
+		rendered := r.renderInlineFormatting(strings.TrimSpace(line))
+		rendered.Styles = fillStyle(rendered.Styles, utf8.RuneCountInString(rendered.Content), plugin.Style{Bold: true})
+		return rendered, true
+	}
+
+	if isDefinitionContinuation(doc, lineNum) {
+		return r.indentInline(strings.TrimSpace(line)), true
+	}
+
+	return plugin.RenderedLine{}, false
+}
+
+// indentInline renders text with inline formatting at the hanging indent.
+func (r *TerminalRenderer) indentInline(text string) plugin.RenderedLine {
+	rendered := r.renderInlineFormatting(text)
+	shift := utf8.RuneCountInString(definitionIndent)
+	for i := range rendered.Styles {
+		rendered.Styles[i].Start += shift
+		rendered.Styles[i].End += shift
+	}
+	rendered.Content = definitionIndent + rendered.Content
+	return rendered
+}
+
+// isDefinitionContinuation reports whether line is an indented line
+// continuing a definition above it.
+func isDefinitionContinuation(doc *ast.Document, lineNum int) bool {
+	for i := lineNum; i >= 0; i-- {
+		text := doc.GetLine(i)
+		if _, _, ok := ast.DefinitionMarker(text); ok {
+			return i != lineNum
+		}
+		if strings.TrimSpace(text) == "" || !strings.HasPrefix(text, "  ") {
+			return false
+		}
+	}
+	return false
+}
+
+// fillStyle adds style to every existing range and covers the gaps between
+// them, so a whole line can be emphasized without overlapping ranges.
+func fillStyle(styles []plugin.StyleRange, length int, style plugin.Style) []plugin.StyleRange {
+	sort.Slice(styles, func(i, j int) bool { return styles[i].Start < styles[j].Start })
+
+	filled := make([]plugin.StyleRange, 0, len(styles)*2+1)
+	pos := 0
+	for _, s := range styles {
+		if s.Start > pos {
+			filled = append(filled, plugin.StyleRange{Start: pos, End: s.Start, Style: style})
+		}
+		s.Style.Bold = s.Style.Bold || style.Bold
+		s.Style.Italic = s.Style.Italic || style.Italic
+		s.Style.Underline = s.Style.Underline || style.Underline
+		filled = append(filled, s)
+		pos = s.End
+	}
+	if pos < length {
+		filled = append(filled, plugin.StyleRange{Start: pos, End: length, Style: style})
+	}
+	return filled
+}
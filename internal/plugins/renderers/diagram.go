@@ -0,0 +1,111 @@
+package renderers
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"github.com/ofri/mde/internal/theme"
+	"github.com/ofri/mde/pkg/ast"
+	"github.com/ofri/mde/pkg/plugin"
+)
+
+// diagramLanguages are fence info strings whose content is diagram source
+// rather than code. Preview frames them instead of styling them as code.
+var diagramLanguages = map[string]bool{
+	"mermaid":  true,
+	"plantuml": true,
+	"dot":      true,
+	"graphviz": true,
+	"d2":       true,
+}
+
+// IsDiagramLanguage reports whether a fence language holds diagram source.
+func IsDiagramLanguage(lang string) bool {
+	return diagramLanguages[strings.ToLower(lang)]
+}
+
+// diagramBlocks returns the diagram fences of doc overlapping [start, end).
+func diagramBlocks(doc *ast.Document, start, end int) []ast.FencedBlock {
+	var blocks []ast.FencedBlock
+	for _, block := range doc.FencedBlocks() {
+		if block.EndLine < start {
+			continue
+		}
+		if block.StartLine >= end {
+			break
+		}
+		if IsDiagramLanguage(block.Language()) {
+			blocks = append(blocks, block)
+		}
+	}
+	return blocks
+}
+
+// renderDiagramLine renders one line of a diagram fence as part of a frame:
+// the opening fence becomes a labelled top border, the closing fence a
+// bottom border, and the source in between is dimmed behind a left rule.
+func (r *TerminalRenderer) renderDiagramLine(block ast.FencedBlock, lineNum int, line string, width int) plugin.RenderedLine {
+	if r.screenReader() {
+		// A frame would be read out character by character; name the
+		// diagram instead
+		content := "  " + r.expandTabs(line)
+		switch {
+		case lineNum == block.StartLine:
+			content = block.Language() + " diagram:"
+		case lineNum == block.EndLine && block.Closed:
+			content = "End of diagram"
+		}
+		return plugin.RenderedLine{Content: content, Styles: []plugin.StyleRange{}}
+	}
+
+	frameStyle := themed(theme.DiagramFrame)
+
+	switch {
+	case lineNum == block.StartLine:
+		label := "┌─ " + block.Language() + " diagram "
+		content := padRule(label, width)
+		return plugin.RenderedLine{
+			Content: content,
+			Styles: []plugin.StyleRange{
+				{Start: 0, End: utf8.RuneCountInString(content), Style: frameStyle},
+			},
+		}
+	case lineNum == block.EndLine && block.Closed:
+		content := padRule("└", width)
+		return plugin.RenderedLine{
+			Content: content,
+			Styles: []plugin.StyleRange{
+				{Start: 0, End: utf8.RuneCountInString(content), Style: frameStyle},
+			},
+		}
+	}
+
+	content := "│ " + r.expandTabs(line)
+	total := utf8.RuneCountInString(content)
+	return plugin.RenderedLine{
+		Content: content,
+		Styles: []plugin.StyleRange{
+			{Start: 0, End: 1, Style: frameStyle},
+			{Start: 2, End: total, Style: themed(theme.DiagramSource)},
+		},
+	}
+}
+
+// padRule extends prefix with horizontal rule characters to width runes.
+func padRule(prefix string, width int) string {
+	fill := width - utf8.RuneCountInString(prefix)
+	if fill < 1 {
+		fill = 1
+	}
+	return prefix + strings.Repeat("─", fill)
+}
+
+// blockContaining returns the block in blocks that contains line.
+func blockContaining(blocks []ast.FencedBlock, line int) (ast.FencedBlock, bool) {
+	for _, block := range blocks {
+		if block.Contains(line) {
+			return block, true
+		}
+	}
+	return ast.FencedBlock{}, false
+}
@@ -0,0 +1,66 @@
+package renderers
+
+import (
+	"strings"
+
+	"github.com/ofri/mde/internal/theme"
+	"github.com/ofri/mde/pkg/ast"
+	"github.com/ofri/mde/pkg/plugin"
+)
+
+// guideMarker is drawn at each indentation level when the "indentGuides"
+// option is set
+const guideMarker = '▏'
+
+// guideScanLimit bounds how far a blank line looks for indented neighbours
+const guideScanLimit = 50
+
+// indentWidth returns the display width of line's leading whitespace.
+func indentWidth(line string, tabWidth int) int {
+	trimmed := strings.TrimLeft(line, " \t")
+	return ast.DisplayColumn(line, ast.RuneOffset(line, len(line)-len(trimmed)), tabWidth)
+}
+
+// guideIndent returns the indentation to draw guides for on line i. Blank
+// lines take the smaller indentation of the nearest text above and below,
+// so guides run unbroken between items of a loose list.
+func guideIndent(doc *ast.Document, i, tabWidth int) int {
+	line := doc.GetLine(i)
+	if strings.TrimSpace(line) != "" {
+		return indentWidth(line, tabWidth)
+	}
+
+	neighbour := func(step int) int {
+		for j, n := i+step, 0; j >= 0 && j < doc.LineCount() && n < guideScanLimit; j, n = j+step, n+1 {
+			if text := doc.GetLine(j); strings.TrimSpace(text) != "" {
+				return indentWidth(text, tabWidth)
+			}
+		}
+		return 0
+	}
+	return min(neighbour(-1), neighbour(1))
+}
+
+// drawIndentGuides draws a guide in content, a line with its tabs already
+// expanded, at every tab stop before indent. Only spaces are replaced, so
+// invisibles markers win; short blank lines are padded to reach the guides.
+// Returns the new content and a style over each guide.
+func drawIndentGuides(content string, indent, tabWidth int) (string, []plugin.StyleRange) {
+	if tabWidth <= 0 || indent <= 0 {
+		return content, nil
+	}
+
+	runes := []rune(content)
+	var styles []plugin.StyleRange
+	for col := 0; col < indent; col += tabWidth {
+		for len(runes) <= col {
+			runes = append(runes, ' ')
+		}
+		if runes[col] != ' ' {
+			continue
+		}
+		runes[col] = guideMarker
+		styles = append(styles, plugin.StyleRange{Start: col, End: col + 1, Style: themed(theme.Invisible)}) // Guides stay in the background like invisibles
+	}
+	return string(runes), styles
+}
@@ -0,0 +1,107 @@
+package renderers
+
+import (
+	"html"
+	"regexp"
+	"strings"
+
+	"github.com/ofri/mde/internal/theme"
+	"github.com/ofri/mde/pkg/ast"
+	"github.com/ofri/mde/pkg/plugin"
+)
+
+var entityRe = regexp.MustCompile(`&(?:[A-Za-z][A-Za-z0-9]*|#[0-9]{1,7}|#[xX][0-9A-Fa-f]{1,6});`)
+
+// htmlInlineStyles maps formatting tags to the theme element styling their
+// content in preview. Other tags are stripped, keeping their content
+// unstyled.
+var htmlInlineStyles = map[string]string{
+	"b":      theme.Bold,
+	"strong": theme.Bold,
+	"i":      theme.Italic,
+	"em":     theme.Italic,
+	"cite":   theme.Italic,
+	"u":      theme.Underline,
+	"ins":    theme.Underline,
+	"s":      theme.Strikethrough,
+	"del":    theme.Strikethrough,
+	"strike": theme.Strikethrough,
+	"mark":   theme.Highlight,
+	"code":   theme.Code,
+	"kbd":    theme.Keyboard,
+	"samp":   theme.Code,
+}
+
+// htmlSpans renders inline HTML: <b>text</b> and friends become styled
+// text, other tags and comments disappear and entities are decoded.
+func htmlSpans(line string) []inlineSpan {
+	tags := ast.FindHTMLTags(line)
+
+	var spans []inlineSpan
+	for i, open := range tags {
+		element, ok := htmlInlineStyles[open.Name]
+		if !ok || open.Closing || open.SelfClosing {
+			continue
+		}
+		for _, close := range tags[i+1:] {
+			if close.Closing && close.Name == open.Name {
+				spans = append(spans, inlineSpan{
+					start: open.Start,
+					end:   close.End,
+					text:  htmlText(line[open.End:close.Start]),
+					style: themed(element),
+				})
+				break
+			}
+		}
+	}
+
+	// Pairs come first so the tags inside them are dropped as overlaps
+	for _, tag := range tags {
+		spans = append(spans, inlineSpan{start: tag.Start, end: tag.End})
+	}
+	for _, m := range entityRe.FindAllStringIndex(line, -1) {
+		spans = append(spans, inlineSpan{start: m[0], end: m[1], text: html.UnescapeString(line[m[0]:m[1]])})
+	}
+	return spans
+}
+
+// htmlText strips tags from s and decodes its entities.
+func htmlText(s string) string {
+	var text strings.Builder
+	last := 0
+	for _, tag := range ast.FindHTMLTags(s) {
+		text.WriteString(s[last:tag.Start])
+		last = tag.End
+	}
+	text.WriteString(s[last:])
+	return html.UnescapeString(text.String())
+}
+
+// renderHTMLBlockLine renders a line of a raw HTML block. Markdown is not
+// interpreted inside HTML blocks, so the line shows as its text content;
+// comments, declarations, scripts and styles render as blank lines.
+func (r *TerminalRenderer) renderHTMLBlockLine(doc *ast.Document, block ast.HTMLBlock, line string) plugin.RenderedLine {
+	opening := strings.ToLower(strings.TrimSpace(doc.GetLine(block.StartLine)))
+	if strings.HasPrefix(opening, "<!") || strings.HasPrefix(opening, "<?") ||
+		strings.HasPrefix(opening, "<script") || strings.HasPrefix(opening, "<style") {
+		return plugin.RenderedLine{Content: "", Styles: []plugin.StyleRange{}}
+	}
+
+	content, styles := applyInlineSpans(line, htmlSpans(line), []plugin.StyleRange{})
+	if block.Raw {
+		// <pre> and <textarea> keep their whitespace
+		return plugin.RenderedLine{
+			Content: content,
+			Styles:  fillStyle(styles, len([]rune(content)), themed(theme.CodeBlock)),
+		}
+	}
+
+	trimmed := strings.TrimLeft(content, " \t")
+	shift := len([]rune(content)) - len([]rune(trimmed))
+	for i := range styles {
+		styles[i].Start -= shift
+		styles[i].End -= shift
+	}
+	return plugin.RenderedLine{Content: trimmed, Styles: styles}
+}
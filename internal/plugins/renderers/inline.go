@@ -0,0 +1,139 @@
+package renderers
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/ofri/mde/internal/critic"
+	"github.com/ofri/mde/internal/emoji"
+	"github.com/ofri/mde/internal/links"
+	"github.com/ofri/mde/internal/theme"
+	"github.com/ofri/mde/internal/wiki"
+	"github.com/ofri/mde/pkg/plugin"
+)
+
+// Same syntax as the parser: no whitespace just inside the delimiters
+var (
+	strikeRe    = regexp.MustCompile(`~~[^~\s](?:[^~]*[^~\s])?~~`)
+	highlightRe = regexp.MustCompile(`==[^=\s](?:[^=]*[^=\s])?==`)
+)
+
+// inlineSpan replaces the source bytes [start, end) of a preview line with
+// display text drawn in style.
+type inlineSpan struct {
+	start int
+	end   int
+	text  string
+	style plugin.Style
+}
+
+// applyInlineSpans substitutes spans into line and appends a style range
+// (in runes of the result) for each non-empty one. Spans overlapping an earlier one are
+// dropped, so independent inline syntaxes can be collected separately.
+func applyInlineSpans(line string, spans []inlineSpan, styles []plugin.StyleRange) (string, []plugin.StyleRange) {
+	if len(spans) == 0 {
+		return line, styles
+	}
+
+	sort.SliceStable(spans, func(i, j int) bool { return spans[i].start < spans[j].start })
+
+	var result strings.Builder
+	runes := 0
+	last := 0
+	for _, span := range spans {
+		if span.start < last {
+			continue
+		}
+
+		result.WriteString(line[last:span.start])
+		runes += utf8.RuneCountInString(line[last:span.start])
+
+		width := utf8.RuneCountInString(span.text)
+		result.WriteString(span.text)
+		if width > 0 {
+			styles = append(styles, plugin.StyleRange{Start: runes, End: runes + width, Style: span.style})
+		}
+		runes += width
+		last = span.end
+	}
+	result.WriteString(line[last:])
+
+	return result.String(), styles
+}
+
+// wikiLinkSpans shows each [[Target|alias]] as its display text, underlined.
+func wikiLinkSpans(line string) []inlineSpan {
+	var spans []inlineSpan
+	for _, link := range wiki.FindLinks(line) {
+		text := link.Alias
+		if text == "" {
+			text = link.Target
+		}
+		spans = append(spans, inlineSpan{
+			start: link.Start,
+			end:   link.End,
+			text:  text,
+			style: themed(theme.WikiLink),
+		})
+	}
+	return spans
+}
+
+// emojiSpans replaces each known :shortcode: with its emoji.
+func emojiSpans(line string) []inlineSpan {
+	var spans []inlineSpan
+	for _, code := range emoji.FindShortcodes(line) {
+		text, _ := emoji.Lookup(code.Name)
+		spans = append(spans, inlineSpan{start: code.Start, end: code.End, text: text})
+	}
+	return spans
+}
+
+// delimitedSpans shows each match of re, a pair of two-character
+// delimiters around text, as the text alone.
+func delimitedSpans(line string, re *regexp.Regexp, style plugin.Style) []inlineSpan {
+	var spans []inlineSpan
+	for _, match := range re.FindAllStringIndex(line, -1) {
+		spans = append(spans, inlineSpan{start: match[0], end: match[1], text: line[match[0]+2 : match[1]-2], style: style})
+	}
+	return spans
+}
+
+// criticStyles are the styles of CriticMarkup marks by kind.
+var criticStyles = map[critic.Kind]string{
+	critic.Insertion: theme.Insertion,
+	critic.Deletion:  theme.Deletion,
+	critic.Comment:   theme.CriticComment,
+}
+
+// criticSpans shows CriticMarkup marks as their styled text alone.
+func criticSpans(line string) []inlineSpan {
+	var spans []inlineSpan
+	for _, span := range critic.Spans(line) {
+		text := line[span.Start+len(critic.Open(span.Kind)) : span.End-len(critic.Close(span.Kind))]
+		spans = append(spans, inlineSpan{start: span.Start, end: span.End, text: text, style: themed(criticStyles[span.Kind])})
+	}
+	return spans
+}
+
+// linkSpans shows inline and reference links as their underlined text,
+// autolinks without angle brackets, and dims reference definitions.
+func linkSpans(line string) []inlineSpan {
+	var spans []inlineSpan
+	for _, link := range links.Find(line) {
+		span := inlineSpan{start: link.Start, end: link.End, text: link.Text(line), style: themed(theme.Link)}
+		switch link.Kind {
+		case links.Definition:
+			span.text = line[link.Start:link.End]
+			span.style = themed(theme.LinkDefinition)
+		case links.FootnoteRef, links.FootnoteDefinition:
+			// [^1] reads as a bracketed marker, [^1]: as its target
+			span.text = "[" + link.Label + "]"
+			span.style = themed(theme.Reference)
+		}
+		spans = append(spans, span)
+	}
+	return spans
+}
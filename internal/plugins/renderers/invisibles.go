@@ -0,0 +1,73 @@
+package renderers
+
+import (
+	"strings"
+
+	"github.com/ofri/mde/internal/theme"
+	"github.com/ofri/mde/pkg/ast"
+	"github.com/ofri/mde/pkg/plugin"
+)
+
+// Markers drawn for whitespace when the "showInvisibles" option is set.
+// Each takes one cell, so columns are the same as without them.
+const (
+	tabMarker   = '→'
+	spaceMarker = '·'
+	nbspMarker  = '␣'
+)
+
+// markInvisibles expands tabs in line as ast.ExpandTabs does, drawing a
+// marker in the first cell of each tab and in place of every trailing
+// space and non-breaking space. Returns the display text and the invisible
+// style, dim so the markers read as annotations, over each marker.
+func markInvisibles(line string, tabWidth int) (string, []plugin.StyleRange) {
+	trailing := ast.TrailingWhitespaceStart(line)
+
+	var result strings.Builder
+	var styles []plugin.StyleRange
+	mark := func(display int, marker rune) {
+		result.WriteRune(marker)
+		styles = append(styles, plugin.StyleRange{Start: display, End: display + 1, Style: themed(theme.Invisible)})
+	}
+
+	display := 0
+	col := 0
+	for _, r := range line {
+		switch {
+		case r == '\t':
+			width := ast.DisplayColumn(line, col+1, tabWidth) - display
+			if tabWidth <= 0 {
+				width = 1
+			}
+			mark(display, tabMarker)
+			result.WriteString(strings.Repeat(" ", width-1))
+			display += width
+		case r == '\u00a0' || r == '\u202f':
+			mark(display, nbspMarker)
+			display++
+		case r == ' ' && col >= trailing:
+			mark(display, spaceMarker)
+			display++
+		default:
+			result.WriteRune(r)
+			display++
+		}
+		col++
+	}
+	return result.String(), styles
+}
+
+// placeStyles moves styles computed on a line's text onto the rendered
+// line: after a prefix of prefix runes (the line number) and scrolled left
+// by left columns. Styles scrolled out of view are dropped.
+func placeStyles(styles []plugin.StyleRange, prefix, left int) []plugin.StyleRange {
+	placed := make([]plugin.StyleRange, 0, len(styles))
+	for _, s := range styles {
+		start, end := max(s.Start-left, 0), s.End-left
+		if start >= end {
+			continue
+		}
+		placed = append(placed, plugin.StyleRange{Start: start + prefix, End: end + prefix, Style: s.Style})
+	}
+	return placed
+}
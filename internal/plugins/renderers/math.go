@@ -0,0 +1,65 @@
+package renderers
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"github.com/ofri/mde/internal/mathtext"
+	"github.com/ofri/mde/internal/theme"
+	"github.com/ofri/mde/pkg/ast"
+	"github.com/ofri/mde/pkg/plugin"
+)
+
+// mathText returns how TeX source is displayed in preview: converted to
+// Unicode when the "mathUnicode" option is set, verbatim otherwise.
+func (r *TerminalRenderer) mathText(tex string) string {
+	if unicode, _ := r.settings().Options["mathUnicode"].(bool); unicode {
+		return mathtext.ToUnicode(tex)
+	}
+	return tex
+}
+
+// mathSpans shows inline $x$ and $$x$$ spans without their delimiters.
+func (r *TerminalRenderer) mathSpans(line string) []inlineSpan {
+	var spans []inlineSpan
+	for _, span := range mathtext.FindSpans(line) {
+		spans = append(spans, inlineSpan{
+			start: span.Start,
+			end:   span.End,
+			text:  r.mathText(strings.TrimSpace(span.Inner(line))),
+			style: themed(theme.Math),
+		})
+	}
+	return spans
+}
+
+// mathBlockAt returns the display math block of blocks containing line.
+func mathBlockAt(blocks []ast.MathBlock, line int) (ast.MathBlock, bool) {
+	for _, block := range blocks {
+		if block.Contains(line) {
+			return block, true
+		}
+	}
+	return ast.MathBlock{}, false
+}
+
+// renderMathBlockLine renders one line of a $$ display block: delimiter
+// lines are blanked and the body is indented like a displayed equation.
+func (r *TerminalRenderer) renderMathBlockLine(block ast.MathBlock, lineNum int, line string) plugin.RenderedLine {
+	if lineNum == block.StartLine || (lineNum == block.EndLine && block.Closed) {
+		return plugin.RenderedLine{Content: "", Styles: []plugin.StyleRange{}}
+	}
+
+	text := r.mathText(strings.TrimSpace(line))
+	if text == "" {
+		return plugin.RenderedLine{Content: "", Styles: []plugin.StyleRange{}}
+	}
+
+	content := "    " + text
+	return plugin.RenderedLine{
+		Content: content,
+		Styles: []plugin.StyleRange{
+			{Start: 4, End: utf8.RuneCountInString(content), Style: themed(theme.Math)},
+		},
+	}
+}
@@ -0,0 +1,95 @@
+package renderers
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"github.com/ofri/mde/internal/theme"
+	"github.com/ofri/mde/pkg/ast"
+	"github.com/ofri/mde/pkg/plugin"
+)
+
+// bulletGlyphs cycle through list levels like most rich text editors.
+var bulletGlyphs = []string{"•", "◦", "▪"}
+
+// depthStyle cycles through the theme's depth styles so sibling and
+// nested structures stay distinguishable.
+func depthStyle(depth int) plugin.Style {
+	return themed(theme.Depths[(depth-1)%len(theme.Depths)])
+}
+
+// renderNestedLine renders blockquotes with one bar per level and list
+// items indented by their depth. listDepth is the item's nesting depth
+// from Document.ListDepths (0 if the line is not a list item).
+// Returns false for other lines.
+func (r *TerminalRenderer) renderNestedLine(line string, listDepth int) (plugin.RenderedLine, bool) {
+	if depth, _, content := ast.QuoteDepth(line); depth > 0 {
+		return r.renderQuoteLine(line[content:], depth), true
+	}
+
+	if listDepth > 0 {
+		if item, ok := ast.ParseListItem(line); ok {
+			return r.renderListItem(line, item, listDepth), true
+		}
+	}
+
+	return plugin.RenderedLine{}, false
+}
+
+// renderQuoteLine draws a bar per quote level, colored by level, followed
+// by the dimmed quote text.
+func (r *TerminalRenderer) renderQuoteLine(text string, depth int) plugin.RenderedLine {
+	var styles []plugin.StyleRange
+	var content strings.Builder
+	bar := r.quoteBar()
+	for level := 1; level <= depth; level++ {
+		start := 2 * (level - 1)
+		content.WriteString(bar)
+		styles = append(styles, plugin.StyleRange{
+			Start: start,
+			End:   start + 1,
+			Style: depthStyle(level),
+		})
+	}
+
+	prefix := content.String()
+	shift := utf8.RuneCountInString(prefix)
+
+	rendered := r.renderInlineFormatting(text)
+	quoteStyle := themed(theme.Quote)
+	for _, s := range fillStyle(rendered.Styles, utf8.RuneCountInString(rendered.Content), quoteStyle) {
+		s.Start += shift
+		s.End += shift
+		styles = append(styles, s)
+	}
+
+	return plugin.RenderedLine{Content: prefix + rendered.Content, Styles: styles}
+}
+
+// renderListItem indents an item two columns per level and colors its
+// bullet or number by depth.
+func (r *TerminalRenderer) renderListItem(line string, item ast.ListItem, depth int) plugin.RenderedLine {
+	marker := bulletGlyphs[(depth-1)%len(bulletGlyphs)]
+	if item.Ordered {
+		marker = line[item.Marker:item.MarkerEnd]
+	}
+
+	prefix := strings.Repeat("  ", depth) + marker + " "
+	markerStart := 2 * depth
+	markerEnd := markerStart + utf8.RuneCountInString(marker)
+
+	text := strings.TrimLeft(line[item.MarkerEnd:], " \t")
+	rendered := r.renderInlineFormatting(text)
+	shift := utf8.RuneCountInString(prefix)
+
+	styles := []plugin.StyleRange{
+		{Start: markerStart, End: markerEnd, Style: depthStyle(depth)},
+	}
+	for _, s := range rendered.Styles {
+		s.Start += shift
+		s.End += shift
+		styles = append(styles, s)
+	}
+
+	return plugin.RenderedLine{Content: prefix + rendered.Content, Styles: styles}
+}
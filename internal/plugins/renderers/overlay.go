@@ -0,0 +1,93 @@
+package renderers
+
+import (
+	"cmp"
+	"slices"
+
+	"github.com/ofri/mde/pkg/plugin"
+)
+
+// Overlay returns styles with top drawn over the columns [start, end).
+// The cursor and the selection are drawn this way, layered over whatever
+// styles the text has so the characters beneath stay readable.
+func Overlay(styles []plugin.StyleRange, start, end int, top plugin.Style) []plugin.StyleRange {
+	if start >= end {
+		return styles
+	}
+	return append(slices.Clip(styles), plugin.StyleRange{Start: start, End: end, Style: top})
+}
+
+// composite resolves overlapping style ranges over length runes into
+// disjoint ones, sorted. Ranges are layered by start, a range beneath the
+// ones it encloses and otherwise beneath those after it, so bold inside a
+// link is drawn bold in the link's color, and each run between range
+// edges is drawn in all the styles covering it.
+func composite(styles []plugin.StyleRange, length int) []plugin.StyleRange {
+	layers := make([]plugin.StyleRange, 0, len(styles))
+	for _, s := range styles {
+		s.Start, s.End = max(s.Start, 0), min(s.End, length)
+		if s.Start < s.End {
+			layers = append(layers, s)
+		}
+	}
+	slices.SortStableFunc(layers, func(a, b plugin.StyleRange) int {
+		return cmp.Or(a.Start-b.Start, b.End-a.End)
+	})
+
+	overlapping := false
+	for i := 1; i < len(layers); i++ {
+		if layers[i].Start < layers[i-1].End {
+			overlapping = true
+			break
+		}
+	}
+	if !overlapping {
+		return layers
+	}
+
+	edges := make([]int, 0, 2*len(layers))
+	for _, s := range layers {
+		edges = append(edges, s.Start, s.End)
+	}
+	slices.Sort(edges)
+	edges = slices.Compact(edges)
+
+	runs := make([]plugin.StyleRange, 0, len(edges))
+	for i := 0; i+1 < len(edges); i++ {
+		start, end := edges[i], edges[i+1]
+		var style plugin.Style
+		covered := false
+		for _, s := range layers {
+			if s.Start >= end {
+				break
+			}
+			if s.End > start {
+				style = layer(style, s.Style)
+				covered = true
+			}
+		}
+		if covered {
+			runs = append(runs, plugin.StyleRange{Start: start, End: end, Style: style})
+		}
+	}
+	return runs
+}
+
+// layer returns base with top drawn over it: top's colors replace base's
+// and its attributes are added, except reverse, which toggles so the
+// cursor still shows inside a reversed selection.
+func layer(base, top plugin.Style) plugin.Style {
+	if top.Foreground != "" {
+		base.Foreground = top.Foreground
+	}
+	if top.Background != "" {
+		base.Background = top.Background
+	}
+	base.Bold = base.Bold || top.Bold
+	base.Italic = base.Italic || top.Italic
+	base.Underline = base.Underline || top.Underline
+	base.Strikethrough = base.Strikethrough || top.Strikethrough
+	base.Faint = base.Faint || top.Faint
+	base.Reverse = base.Reverse != top.Reverse
+	return base
+}
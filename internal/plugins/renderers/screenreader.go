@@ -0,0 +1,27 @@
+package renderers
+
+// screenReader reports whether the "screenReader" option is set. Text is
+// then drawn without styles, box-drawing characters or a cursor glyph, so
+// a screen reader reading the terminal hears the document and nothing
+// else; the terminal's own cursor marks the position instead.
+func (r *TerminalRenderer) screenReader() bool {
+	on, _ := r.settings().Options["screenReader"].(bool)
+	return on
+}
+
+// gutterRule returns what separates line numbers from the text: a rule,
+// or for screen readers a colon, read as a pause.
+func (r *TerminalRenderer) gutterRule() string {
+	if r.screenReader() {
+		return ": "
+	}
+	return "│ "
+}
+
+// quoteBar returns the marker drawn for each blockquote level.
+func (r *TerminalRenderer) quoteBar() string {
+	if r.screenReader() {
+		return "> "
+	}
+	return "│ "
+}
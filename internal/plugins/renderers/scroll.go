@@ -0,0 +1,26 @@
+package renderers
+
+import (
+	"unicode/utf8"
+
+	"github.com/ofri/mde/internal/theme"
+	"github.com/ofri/mde/pkg/plugin"
+)
+
+// scrolledPastMarker is drawn at the left edge of a row whose line ends
+// before the first visible column, so a horizontally scrolled screen tells
+// such lines apart from empty ones.
+const scrolledPastMarker = "‹"
+
+// markScrolledPast draws scrolledPastMarker on line, which is empty but for
+// any line number, when its text of width display columns lies wholly left
+// of column left.
+func markScrolledPast(line plugin.RenderedLine, width, left int) plugin.RenderedLine {
+	if width == 0 || width > left {
+		return line
+	}
+	prefix := utf8.RuneCountInString(line.Content)
+	line.Content += scrolledPastMarker
+	line.Styles = append(line.Styles, plugin.StyleRange{Start: prefix, End: prefix + 1, Style: themed(theme.ScrolledPast)})
+	return line
+}
@@ -0,0 +1,40 @@
+package renderers
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"github.com/ofri/mde/internal/theme"
+	"github.com/ofri/mde/pkg/ast"
+	"github.com/ofri/mde/pkg/plugin"
+)
+
+// renderSetextLine renders a setext heading: the text in the same style as
+// the equivalent ATX heading, and the underline as a rule spanning it.
+func (r *TerminalRenderer) renderSetextLine(doc *ast.Document, lineNum, level int, underline bool) plugin.RenderedLine {
+	style := themed(theme.Heading1)
+	rule := "═"
+	if level == 2 {
+		style = themed(theme.Heading2)
+		rule = "─"
+	}
+
+	textLine := lineNum
+	if underline {
+		textLine--
+	}
+	text := strings.TrimSpace(doc.GetLine(textLine))
+
+	content := text
+	if underline && !r.screenReader() {
+		content = strings.Repeat(rule, utf8.RuneCountInString(text))
+	} else if underline {
+		content = ""
+	}
+	return plugin.RenderedLine{
+		Content: content,
+		Styles: []plugin.StyleRange{
+			{Start: 0, End: utf8.RuneCountInString(content), Style: style},
+		},
+	}
+}
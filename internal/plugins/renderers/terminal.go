@@ -4,6 +4,11 @@ import (
 	"context"
 	"fmt"
 	"strings"
+	"sync"
+	"unicode/utf8"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+	"github.com/ofri/mde/internal/theme"
 	"github.com/ofri/mde/pkg/ast"
 	"github.com/ofri/mde/pkg/plugin"
 )
@@ -35,8 +40,11 @@ const (
 	ColorDefault = ""  // Use terminal's default color
 )
 
-// TerminalRenderer implements the RendererPlugin interface for terminal output
+// TerminalRenderer implements the RendererPlugin interface for terminal output.
+// It is safe for concurrent use: Configure publishes a new configuration
+// snapshot that renders read through settings().
 type TerminalRenderer struct {
+	mu     sync.RWMutex
 	config plugin.RendererConfig
 }
 
@@ -58,36 +66,60 @@ func (r *TerminalRenderer) Name() string {
 	return "terminal"
 }
 
+// NewInstance returns an independent renderer with default settings, so
+// a consumer can configure its own without affecting others
+func (r *TerminalRenderer) NewInstance() plugin.RendererPlugin {
+	return NewTerminalRenderer()
+}
+
 // Configure configures the renderer with options
 func (r *TerminalRenderer) Configure(options map[string]interface{}) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	
+	// Build a new snapshot; the published Options map is never mutated
+	config := r.config
+	config.Options = make(map[string]interface{}, len(r.config.Options)+len(options))
+	for key, value := range r.config.Options {
+		config.Options[key] = value
+	}
+	
 	if maxWidth, ok := options["maxWidth"].(int); ok {
-		r.config.MaxWidth = maxWidth
+		config.MaxWidth = maxWidth
 	}
 	
 	if tabWidth, ok := options["tabWidth"].(int); ok {
-		r.config.TabWidth = tabWidth
+		config.TabWidth = tabWidth
 	}
 	
 	if showLineNumbers, ok := options["showLineNumbers"].(bool); ok {
-		r.config.ShowLineNumbers = showLineNumbers
+		config.ShowLineNumbers = showLineNumbers
 	}
 	
 	if lineNumberWidth, ok := options["lineNumberWidth"].(int); ok {
-		r.config.LineNumberWidth = lineNumberWidth
+		config.LineNumberWidth = lineNumberWidth
 	}
 	
 	if previewMode, ok := options["previewMode"].(bool); ok {
-		r.config.PreviewMode = previewMode
+		config.PreviewMode = previewMode
 	}
 	
 	// Store custom options
 	for key, value := range options {
-		r.config.Options[key] = value
+		config.Options[key] = value
 	}
 	
+	r.config = config
 	return nil
 }
 
+// settings returns the current configuration snapshot
+func (r *TerminalRenderer) settings() plugin.RendererConfig {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.config
+}
+
 
 // RenderVisible renders only visible lines from the viewport.
 //
@@ -123,17 +155,41 @@ func (r *TerminalRenderer) RenderVisible(ctx context.Context, renderCtx *plugin.
 	
 	// Pre-allocate slice for visible lines
 	lines := make([]plugin.RenderedLine, 0, endLine-startLine)
+	showInvisibles, _ := r.settings().Options["showInvisibles"].(bool)
+	showGuides, _ := r.settings().Options["indentGuides"].(bool)
+	showGuides = showGuides && !r.screenReader()
+	rule := r.gutterRule()
 	
 	// Process only the visible lines
 	for i := startLine; i < endLine; i++ {
-		lineContent := doc.GetLine(i)
+		if doc.IsLongLine(i) {
+			lines = append(lines, r.renderLongLine(doc.GetLine(i), i, renderCtx))
+			continue
+		}
+		
+		// Tabs are expanded first so stops line up with the viewport's
+		// display columns, which scrolling and the cursor use
+		var lineContent string
+		var markers []plugin.StyleRange
+		if showInvisibles {
+			lineContent, markers = markInvisibles(doc.GetLine(i), viewport.GetTabWidth())
+		} else {
+			lineContent = ast.ExpandTabs(doc.GetLine(i), viewport.GetTabWidth())
+		}
+		if showGuides {
+			var guides []plugin.StyleRange
+			lineContent, guides = drawIndentGuides(lineContent, guideIndent(doc, i, viewport.GetTabWidth()), viewport.GetTabWidth())
+			markers = append(markers, guides...)
+		}
 		
 		// Add line numbers if enabled
+		prefix := 0
 		if renderCtx.ShowLineNumbers {
 			// Format line number with proper width and separator
 			// Use same format as editor: "%Nd │ " (includes space after │)
-			lineNumStr := fmt.Sprintf("%*d│ ", viewport.GetLineNumberWidth()-2, i+1)
+			lineNumStr := fmt.Sprintf("%*d%s", viewport.GetLineNumberWidth()-2, i+1, rule)
 			lineContent = lineNumStr + lineContent
+			prefix = utf8.RuneCountInString(lineNumStr)
 		}
 		
 		// Apply horizontal scrolling
@@ -146,6 +202,13 @@ func (r *TerminalRenderer) RenderVisible(ctx context.Context, renderCtx *plugin.
 		if err != nil {
 			return nil, fmt.Errorf("failed to render line %d: %w", i, err)
 		}
+		if len(markers) > 0 {
+			renderedLine.Styles = placeStyles(markers, prefix, viewport.GetLeftColumn())
+		}
+		if viewport.GetLeftColumn() > 0 {
+			width := viewport.DisplayColumn(doc.GetLine(i), doc.GetLineLength(i))
+			renderedLine = markScrolledPast(renderedLine, width, viewport.GetLeftColumn())
+		}
 		
 		lines = append(lines, renderedLine)
 	}
@@ -153,6 +216,26 @@ func (r *TerminalRenderer) RenderVisible(ctx context.Context, renderCtx *plugin.
 	return lines, nil
 }
 
+// renderLongLine renders the visible window of long line i, cutting it
+// before tabs are expanded or markers placed, so a multi-megabyte line costs
+// no more than the columns on screen. Long lines show no invisibles or
+// indent guides.
+func (r *TerminalRenderer) renderLongLine(line string, i int, renderCtx *plugin.RenderContext) plugin.RenderedLine {
+	viewport := renderCtx.Viewport
+	width := viewport.GetWidth() - viewport.GetLineNumberWidth()
+	content := ast.VisibleWindow(line, viewport.GetLeftColumn(), width, viewport.GetTabWidth())
+	if renderCtx.ShowLineNumbers {
+		content = fmt.Sprintf("%*d%s", viewport.GetLineNumberWidth()-2, i+1, r.gutterRule()) + content
+	}
+	return plugin.RenderedLine{
+		Content: content,
+		Styles:  []plugin.StyleRange{},
+		Metadata: map[string]interface{}{
+			"long_line": true,
+		},
+	}
+}
+
 // RenderPreviewVisible implements viewport-aware rendering for preview mode.
 // This method renders markdown with formatting while respecting viewport boundaries.
 //
@@ -188,14 +271,43 @@ func (r *TerminalRenderer) RenderPreviewVisible(ctx context.Context, renderCtx *
 	visibleLines := allLines[startLine:endLine]
 	renderedLines := make([]plugin.RenderedLine, 0, len(visibleLines))
 	
+	// Diagram fences and math blocks span lines, so locate them across
+	// the whole document
+	diagrams := diagramBlocks(doc, startLine, endLine)
+	fences := doc.FencedBlocks()
+	mathBlocks := doc.MathBlocks()
+	listDepths := doc.ListDepths()
+	frameWidth := r.settings().MaxWidth
+	if width := viewport.GetWidth(); width > 0 && width < frameWidth {
+		frameWidth = width
+	}
+	
 	// Render each visible line with markdown formatting
-	for _, line := range visibleLines {
-		renderedLine := r.renderMarkdownLine(line)
+	for i, line := range visibleLines {
+		var renderedLine plugin.RenderedLine
+		if block, ok := blockContaining(diagrams, startLine+i); ok {
+			renderedLine = r.renderDiagramLine(block, startLine+i, line, frameWidth)
+		} else if block, ok := blockContaining(fences, startLine+i); ok && startLine+i != block.StartLine && (startLine+i != block.EndLine || !block.Closed) {
+			// Code is shown as written, without inline rendering
+			renderedLine = plugin.RenderedLine{Content: line, Styles: []plugin.StyleRange{}}
+		} else if block, ok := mathBlockAt(mathBlocks, startLine+i); ok {
+			renderedLine = r.renderMathBlockLine(block, startLine+i, line)
+		} else if block, ok := doc.HTMLBlockAt(startLine + i); ok {
+			renderedLine = r.renderHTMLBlockLine(doc, block, line)
+		} else if level, underline := doc.SetextHeadingAt(startLine + i); level > 0 {
+			renderedLine = r.renderSetextLine(doc, startLine+i, level, underline)
+		} else if definition, ok := r.renderDefinitionListLine(doc, startLine+i, line); ok {
+			renderedLine = definition
+		} else if nested, ok := r.renderNestedLine(line, listDepths[startLine+i]); ok {
+			renderedLine = nested
+		} else {
+			renderedLine = r.renderMarkdownLine(line)
+		}
 		
 		// Apply horizontal scrolling to preview content
-		if viewport.GetLeftColumn() > 0 && len(renderedLine.Content) > viewport.GetLeftColumn() {
+		if viewport.GetLeftColumn() > 0 && utf8.RuneCountInString(renderedLine.Content) > viewport.GetLeftColumn() {
 			// For preview mode, we simply trim from the left
-			renderedLine.Content = renderedLine.Content[viewport.GetLeftColumn():]
+			renderedLine.Content = renderedLine.Content[ast.ByteOffset(renderedLine.Content, viewport.GetLeftColumn()):]
 			
 			// Adjust style ranges for horizontal scroll
 			for j := range renderedLine.Styles {
@@ -226,28 +338,32 @@ func (r *TerminalRenderer) RenderPreviewVisible(ctx context.Context, renderCtx *
 // 1. If line numbers are shown, preserve them during horizontal scroll
 // 2. Apply the scroll offset only to the content portion
 // 3. Handle edge cases where content is shorter than scroll offset
+//
+// leftColumn and lineNumberWidth count runes, so multibyte text is never
+// cut mid-character.
 func (r *TerminalRenderer) applyHorizontalScroll(line string, leftColumn int, hasLineNumbers bool, lineNumberWidth int) string {
 	if !hasLineNumbers {
 		// Simple case: no line numbers, just trim from left
-		if len(line) > leftColumn {
-			return line[leftColumn:]
+		if utf8.RuneCountInString(line) > leftColumn {
+			return line[ast.ByteOffset(line, leftColumn):]
 		}
 		return ""
 	}
 	
 	// Complex case: preserve line numbers while scrolling content
-	if len(line) <= lineNumberWidth {
+	if utf8.RuneCountInString(line) <= lineNumberWidth {
 		// Line only contains line number (or less), return as-is
 		return line
 	}
 	
 	// Split line number and content
-	lineNumPart := line[:lineNumberWidth]
-	contentPart := line[lineNumberWidth:]
+	split := ast.ByteOffset(line, lineNumberWidth)
+	lineNumPart := line[:split]
+	contentPart := line[split:]
 	
 	// Apply scroll to content portion only
-	if len(contentPart) > leftColumn {
-		return lineNumPart + contentPart[leftColumn:]
+	if utf8.RuneCountInString(contentPart) > leftColumn {
+		return lineNumPart + contentPart[ast.ByteOffset(contentPart, leftColumn):]
 	}
 	
 	// Content is entirely scrolled off, but keep line number
@@ -260,90 +376,61 @@ func (r *TerminalRenderer) renderMarkdownLine(line string) plugin.RenderedLine {
 	
 	// Handle different markdown elements
 	if strings.HasPrefix(trimmedLine, "# ") {
-		// H1 heading - bright red and bold
+		// H1 heading
 		return plugin.RenderedLine{
 			Content: line,
 			Styles: []plugin.StyleRange{
-				{Start: 0, End: len(line), Style: plugin.Style{Foreground: ColorBrightRed, Bold: true}},
+				{Start: 0, End: utf8.RuneCountInString(line), Style: themed(theme.Heading1)},
 			},
 		}
 	} else if strings.HasPrefix(trimmedLine, "## ") {
-		// H2 heading - bright green and bold
+		// H2 heading
 		return plugin.RenderedLine{
 			Content: line,
 			Styles: []plugin.StyleRange{
-				{Start: 0, End: len(line), Style: plugin.Style{Foreground: ColorBrightGreen, Bold: true}},
+				{Start: 0, End: utf8.RuneCountInString(line), Style: themed(theme.Heading2)},
 			},
 		}
 	} else if strings.HasPrefix(trimmedLine, "### ") {
-		// H3 heading - bright yellow and bold
+		// H3 heading
 		return plugin.RenderedLine{
 			Content: line,
 			Styles: []plugin.StyleRange{
-				{Start: 0, End: len(line), Style: plugin.Style{Foreground: ColorBrightYellow, Bold: true}},
+				{Start: 0, End: utf8.RuneCountInString(line), Style: themed(theme.Heading3)},
 			},
 		}
 	} else if strings.HasPrefix(trimmedLine, "#### ") {
-		// H4 heading - bright blue and bold
+		// H4 heading
 		return plugin.RenderedLine{
 			Content: line,
 			Styles: []plugin.StyleRange{
-				{Start: 0, End: len(line), Style: plugin.Style{Foreground: ColorBrightBlue, Bold: true}},
+				{Start: 0, End: utf8.RuneCountInString(line), Style: themed(theme.Heading4)},
 			},
 		}
 	} else if strings.HasPrefix(trimmedLine, "##### ") {
-		// H5 heading - bright magenta and bold
+		// H5 heading
 		return plugin.RenderedLine{
 			Content: line,
 			Styles: []plugin.StyleRange{
-				{Start: 0, End: len(line), Style: plugin.Style{Foreground: ColorBrightMagenta, Bold: true}},
+				{Start: 0, End: utf8.RuneCountInString(line), Style: themed(theme.Heading5)},
 			},
 		}
 	} else if strings.HasPrefix(trimmedLine, "###### ") {
-		// H6 heading - bright cyan and bold
+		// H6 heading
 		return plugin.RenderedLine{
 			Content: line,
 			Styles: []plugin.StyleRange{
-				{Start: 0, End: len(line), Style: plugin.Style{Foreground: ColorBrightCyan, Bold: true}},
-			},
-		}
-	} else if strings.HasPrefix(trimmedLine, "> ") {
-		// Blockquote - gray/bright black
-		text := strings.TrimPrefix(trimmedLine, "> ")
-		return plugin.RenderedLine{
-			Content: "  " + text,
-			Styles: []plugin.StyleRange{
-				{Start: 0, End: len("  " + text), Style: plugin.Style{Foreground: getAccessibleColor(ColorGray)}},
-			},
-		}
-	} else if strings.HasPrefix(trimmedLine, "- ") || strings.HasPrefix(trimmedLine, "* ") {
-		// Bullet list
-		text := trimmedLine[2:]
-		return plugin.RenderedLine{
-			Content: "  • " + text,
-			Styles: []plugin.StyleRange{
-				{Start: 0, End: 3, Style: plugin.Style{Foreground: ColorYellow}}, // Bullet
+				{Start: 0, End: utf8.RuneCountInString(line), Style: themed(theme.Heading6)},
 			},
 		}
 	} else if strings.HasPrefix(trimmedLine, "```") {
-		// Code block delimiter - cyan
+		// Code block delimiter
 		return plugin.RenderedLine{
 			Content: line,
 			Styles: []plugin.StyleRange{
-				{Start: 0, End: len(line), Style: plugin.Style{Foreground: ColorCyan}},
+				{Start: 0, End: utf8.RuneCountInString(line), Style: themed(theme.CodeBlock)},
 			},
 		}
-	} else if len(trimmedLine) > 0 && (trimmedLine[0] >= '0' && trimmedLine[0] <= '9') && strings.Contains(trimmedLine, ". ") {
-		// Numbered list (simple detection)
-		parts := strings.SplitN(trimmedLine, ". ", 2)
-		if len(parts) == 2 {
-			return plugin.RenderedLine{
-				Content: "  " + parts[0] + ". " + parts[1],
-				Styles: []plugin.StyleRange{
-					{Start: 2, End: 2 + len(parts[0]) + 1, Style: plugin.Style{Foreground: ColorYellow}}, // Number
-				},
-			}
-		}
 	}
 	
 	// Handle inline formatting for regular text
@@ -357,6 +444,38 @@ func (r *TerminalRenderer) renderInlineFormatting(line string) plugin.RenderedLi
 	
 	// For simplicity, we'll apply basic styling without complex parsing
 	// In a real implementation, this would properly parse markdown
+	var spans []inlineSpan
+	
+	// Wiki links display their alias (or target) underlined
+	if wikiLinks, _ := r.settings().Options["wikiLinks"].(bool); wikiLinks {
+		spans = append(spans, wikiLinkSpans(line)...)
+	}
+	
+	// Math is shown without its $ delimiters
+	spans = append(spans, r.mathSpans(line)...)
+	
+	// Suggested changes and comments drop their CriticMarkup delimiters
+	spans = append(spans, criticSpans(line)...)
+	
+	// Links show their text; definitions are dimmed
+	spans = append(spans, linkSpans(line)...)
+	
+	// Inline HTML tags are rendered or stripped, entities decoded
+	spans = append(spans, htmlSpans(line)...)
+	
+	// Emoji shortcodes become the emoji itself
+	spans = append(spans, emojiSpans(line)...)
+	
+	// ~~strikethrough~~ and ==highlight== drop their delimiters
+	spans = append(spans, delimitedSpans(line, strikeRe, themed(theme.Strikethrough))...)
+	spans = append(spans, delimitedSpans(line, highlightRe, themed(theme.Highlight))...)
+	
+	// Smart quotes, dashes and ellipses
+	if typographer, _ := r.settings().Options["typographer"].(bool); typographer {
+		spans = append(spans, typographerSpans(line)...)
+	}
+	
+	content, styles = applyInlineSpans(content, spans, styles)
 	
 	// If no styles applied, return as-is with default style
 	if len(styles) == 0 {
@@ -387,38 +506,66 @@ func (r *TerminalRenderer) RenderLine(ctx context.Context, line string, tokens [
 		var style plugin.Style
 		switch token.Kind() {
 		case ast.TokenKeyword:
-			style = plugin.Style{Foreground: getAccessibleColor(ColorMagenta)}
+			style = themed(theme.Keyword)
 		case ast.TokenString:
-			style = plugin.Style{Foreground: getAccessibleColor(ColorGreen)}
+			style = themed(theme.String)
 		case ast.TokenComment:
-			style = plugin.Style{Foreground: getAccessibleColor(ColorGray)}
+			style = themed(theme.Comment)
 		case ast.TokenNumber:
-			style = plugin.Style{Foreground: getAccessibleColor(ColorYellow)}
+			style = themed(theme.Number)
 		// Markdown-specific tokens
 		case ast.TokenHeading:
-			style = plugin.Style{Foreground: ColorBrightRed, Bold: true}
+			style = themed(theme.Heading)
 		case ast.TokenBold:
-			style = plugin.Style{Bold: true}
+			style = themed(theme.Bold)
 		case ast.TokenItalic:
-			style = plugin.Style{Italic: true}
+			style = themed(theme.Italic)
 		case ast.TokenCode:
-			style = plugin.Style{Foreground: ColorCyan}
+			style = themed(theme.Code)
 		case ast.TokenCodeBlock:
-			style = plugin.Style{Foreground: ColorCyan}
+			style = themed(theme.CodeBlock)
 		case ast.TokenLink:
-			style = plugin.Style{Foreground: getAccessibleColor(ColorBlue), Underline: true}
+			style = themed(theme.Link)
 		case ast.TokenLinkText:
-			style = plugin.Style{Foreground: getAccessibleColor(ColorBlue)}
+			style = themed(theme.LinkText)
 		case ast.TokenLinkURL:
-			style = plugin.Style{Foreground: getAccessibleColor(ColorGray)}
+			style = themed(theme.LinkURL)
+		case ast.TokenWikiLink:
+			style = themed(theme.WikiLink)
+		case ast.TokenMath, ast.TokenMathBlock:
+			style = themed(theme.Math)
+		case ast.TokenEmoji:
+			style = themed(theme.Emoji)
+		case ast.TokenDefinitionTerm:
+			style = themed(theme.DefinitionTerm)
+		case ast.TokenDefinition:
+			style = themed(theme.Definition)
+		case ast.TokenStrikethrough:
+			style = themed(theme.Strikethrough)
+		case ast.TokenHighlight:
+			style = themed(theme.Highlight)
+		case ast.TokenLinkReference, ast.TokenLinkDefinition, ast.TokenFootnoteRef, ast.TokenFootnoteDefinition:
+			style = themed(theme.Reference)
+		case ast.TokenCitation:
+			style = themed(theme.Citation)
+		case ast.TokenInsertion:
+			style = themed(theme.Insertion)
+		case ast.TokenDeletion:
+			style = themed(theme.Deletion)
+		case ast.TokenCriticComment:
+			style = themed(theme.CriticComment)
+		case ast.TokenAutolink:
+			style = themed(theme.Link)
+		case ast.TokenHTMLInline, ast.TokenHTMLBlock:
+			style = themed(theme.HTML)
 		case ast.TokenImage:
-			style = plugin.Style{Foreground: ColorMagenta}
+			style = themed(theme.Image)
 		case ast.TokenQuote:
-			style = plugin.Style{Foreground: getAccessibleColor(ColorGray)}
+			style = themed(theme.Quote)
 		case ast.TokenList:
-			style = plugin.Style{Foreground: ColorYellow}
+			style = themed(theme.List)
 		case ast.TokenDelimiter:
-			style = plugin.Style{Foreground: getAccessibleColor(ColorGray)}
+			style = themed(theme.Delimiter)
 		default:
 			// No special styling
 			continue
@@ -457,26 +604,7 @@ func (r *TerminalRenderer) renderTextLine(line string) (plugin.RenderedLine, err
 
 // expandTabs expands tabs to spaces
 func (r *TerminalRenderer) expandTabs(line string) string {
-	if r.config.TabWidth <= 0 {
-		return line
-	}
-	
-	var result strings.Builder
-	col := 0
-	
-	for _, ch := range line {
-		if ch == '\t' {
-			// Calculate spaces needed to reach next tab stop
-			spaces := r.config.TabWidth - (col % r.config.TabWidth)
-			result.WriteString(strings.Repeat(" ", spaces))
-			col += spaces
-		} else {
-			result.WriteRune(ch)
-			col++
-		}
-	}
-	
-	return result.String()
+	return ast.ExpandTabs(line, r.settings().TabWidth)
 }
 
 // RenderToString converts rendered lines to terminal output with proper styling.
@@ -545,15 +673,28 @@ func (r *TerminalRenderer) RenderToStringWithCursor(lines []plugin.RenderedLine,
 // CURSOR POSITIONING:
 // - cursorCol is in ScreenPos coordinates (already includes line number offset)
 // - The viewport transformation handles line number offset calculation
-// - End-of-line: extend line with space, replace with cursor → "Hello█"
-// - Within line: replace existing character with cursor → "He█lo"
-// - Empty line: extend with space, replace with cursor → "█"
+// - The cursor is a style over the character beneath, which stays readable:
+//   reverse video (the theme's cursor element) for "block" and "bar", an
+//   underline for "underline" (overwrite mode by default)
+// - End-of-line and empty lines: extend line with a space to style
+// - Without text attributes (NO_COLOR, or output that is not a terminal)
+//   the character is replaced with a glyph instead: "He█lo", "He▁lo" and
+//   "He▏lo" for the three shapes
+// - A cursor inside a grapheme cluster covers all of it, and a glyph
+//   replacing a wide one is drawn as wide: "a██b" on "a😀b"
+// - cursorShape "hidden": no cursor, the terminal's own is shown instead
 func (r *TerminalRenderer) renderLineWithStylesAndCursor(line plugin.RenderedLine, cursorCol int) string {
 	// CRITICAL ARCHITECTURAL NOTE:
 	// Line numbers are already included in line.Content by RenderVisible.
 	// The cursorCol parameter is the position within line.Content where the cursor
 	// should be placed. No adjustment for line numbers is needed here.
 	
+	// Screen readers follow the terminal cursor; a glyph would hide a character
+	shape, _ := r.settings().Options["cursorShape"].(string)
+	if r.screenReader() || shape == "hidden" {
+		return r.renderLineWithStyles(line)
+	}
+	
 	// Use cursorCol directly - it's already the correct position within line.Content
 	adjustedCursorCol := cursorCol
 	
@@ -576,13 +717,33 @@ func (r *TerminalRenderer) renderLineWithStylesAndCursor(line plugin.RenderedLin
 		runes = append(runes, []rune(strings.Repeat(" ", spaceCount))...)
 	}
 	
-	// Replace character at cursor position with cursor block
-	runes[adjustedCursorCol] = '█'
+	// The cursor covers the whole grapheme cluster it lands in: a wide
+	// emoji, or a character with its variation selector or combining marks
+	start, end, width := cluster(runes, adjustedCursorCol)
+	
+	if lipgloss.ColorProfile() == termenv.Ascii {
+		cursor, fill := '█', true
+		switch shape {
+		case "underline":
+			cursor = '▁'
+		case "bar":
+			cursor, fill = '▏', false
+		}
+		runes, styles := replaceCluster(runes, line.Styles, start, end, width, cursor, fill)
+		return r.renderLineWithStyles(plugin.RenderedLine{Content: string(runes), Styles: styles})
+	}
+	
+	// Style the character at the cursor; a cell can't hold a bar, so it
+	// is drawn as a block
+	cursor := themed(theme.Cursor)
+	if shape == "underline" {
+		cursor = plugin.Style{Underline: true}
+	}
 	
 	// Create new rendered line with cursor
 	lineWithCursor := plugin.RenderedLine{
 		Content: string(runes),
-		Styles:  line.Styles,
+		Styles:  Overlay(line.Styles, start, end, cursor),
 	}
 	
 	return r.renderLineWithStyles(lineWithCursor)
@@ -590,7 +751,7 @@ func (r *TerminalRenderer) renderLineWithStylesAndCursor(line plugin.RenderedLin
 
 // formatLineNumber formats a line number using the appropriate width
 func (r *TerminalRenderer) formatLineNumber(lineNum, totalLines int) string {
-	if !r.config.ShowLineNumbers {
+	if !r.settings().ShowLineNumbers {
 		return ""
 	}
 	
@@ -605,31 +766,27 @@ func (r *TerminalRenderer) formatLineNumber(lineNum, totalLines int) string {
 
 // renderLineWithStyles applies styles to a line
 func (r *TerminalRenderer) renderLineWithStyles(line plugin.RenderedLine) string {
-	if len(line.Styles) == 0 {
+	if len(line.Styles) == 0 || r.screenReader() {
 		// No styles, return content as-is
 		return line.Content
 	}
 	
-	// Sort styles by start position to handle overlapping styles properly
-	// For now, we'll process them in the order they appear
-	
+	// Styles from different sources (tokens, invisibles, zen dimming, the
+	// cursor) may overlap; composite them into disjoint runs, each in the
+	// styles layered over it, so no text is written twice
 	var result strings.Builder
 	runes := []rune(line.Content)
 	lastEnd := 0
 	
-	for _, styleRange := range line.Styles {
+	for _, styleRange := range composite(line.Styles, len(runes)) {
 		// Add unstyled text before this style
 		if styleRange.Start > lastEnd {
 			result.WriteString(string(runes[lastEnd:styleRange.Start]))
 		}
 		
-		// Apply the style - ensure bounds are valid
-		if styleRange.Start >= 0 && styleRange.End <= len(runes) && styleRange.Start < styleRange.End {
-			text := string(runes[styleRange.Start:styleRange.End])
-			styledText := styleRange.Style.ToLipgloss().Render(text)
-			result.WriteString(styledText)
-			lastEnd = styleRange.End
-		}
+		text := string(runes[styleRange.Start:styleRange.End])
+		result.WriteString(styleRange.Style.ToLipgloss().Render(text))
+		lastEnd = styleRange.End
 	}
 	
 	// Add any remaining unstyled text
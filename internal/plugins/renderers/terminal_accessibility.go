@@ -2,6 +2,7 @@ package renderers
 
 import (
 	"os"
+	"strconv"
 )
 
 // shouldUseColor checks if colors should be used based on environment
@@ -22,6 +23,8 @@ func shouldUseColor() bool {
 
 // getAccessibleColor returns a color with better contrast
 // This maps potentially problematic colors to more accessible alternatives
+// for the default theme; once the terminal reports its background,
+// theme.Resolved checks every color against it by WCAG AA
 func getAccessibleColor(color string) string {
 	if !shouldUseColor() {
 		return "" // No color
@@ -48,9 +51,12 @@ func getHighContrastColor(color string) string {
 	
 	// In high contrast mode, use only the brightest colors
 	switch color {
-	case ColorBlack, ColorRed, ColorGreen, ColorYellow, 
-	     ColorBlue, ColorMagenta, ColorCyan, ColorWhite:
-		// Convert all normal colors to their bright variants
+	case ColorRed, ColorGreen, ColorYellow, ColorBlue, ColorMagenta, ColorCyan:
+		// Convert normal colors to their bright variants
+		n, _ := strconv.Atoi(color)
+		return strconv.Itoa(n + 8)
+	case ColorWhite, ColorGray:
+		// Grays that dim text become full white
 		return ColorBrightWhite
 	default:
 		return color
@@ -0,0 +1,167 @@
+package renderers
+
+import (
+	"strconv"
+
+	"github.com/ofri/mde/internal/theme"
+	"github.com/ofri/mde/pkg/plugin"
+)
+
+func init() {
+	theme.Register(DefaultTheme())
+	theme.Register(LightTheme())
+	theme.Register(HighContrastTheme())
+	theme.Register(DeuteranopiaTheme())
+}
+
+// DefaultTheme returns the built-in theme for dark backgrounds, drawn in
+// the terminal's own palette so it follows the user's color scheme.
+func DefaultTheme() *theme.Theme {
+	gray := getAccessibleColor(ColorGray)
+	return &theme.Theme{Name: theme.Default, Styles: map[string]plugin.Style{
+		theme.Keyword:        {Foreground: getAccessibleColor(ColorMagenta)},
+		theme.String:         {Foreground: getAccessibleColor(ColorGreen)},
+		theme.Comment:        {Foreground: gray},
+		theme.Number:         {Foreground: getAccessibleColor(ColorYellow)},
+		theme.Heading:        {Foreground: ColorBrightRed, Bold: true},
+		theme.Bold:           {Bold: true},
+		theme.Italic:         {Italic: true},
+		theme.Underline:      {Underline: true},
+		theme.Code:           {Foreground: ColorCyan},
+		theme.CodeBlock:      {Foreground: ColorCyan},
+		theme.Keyboard:       {Foreground: ColorCyan, Bold: true},
+		theme.Link:           {Foreground: getAccessibleColor(ColorBlue), Underline: true},
+		theme.LinkText:       {Foreground: getAccessibleColor(ColorBlue)},
+		theme.LinkURL:        {Foreground: gray},
+		theme.LinkDefinition: {Foreground: gray},
+		theme.Reference:      {Foreground: getAccessibleColor(ColorCyan)},
+		theme.Citation:       {Foreground: getAccessibleColor(ColorMagenta)},
+		theme.GlossaryTerm:   {Foreground: getAccessibleColor(ColorGreen), Underline: true},
+		theme.WikiLink:       {Foreground: ColorBrightCyan, Underline: true},
+		theme.Math:           {Foreground: ColorBrightBlue, Italic: true},
+		theme.Emoji:          {Foreground: ColorYellow},
+		theme.DefinitionTerm: {Bold: true},
+		theme.Definition:     {Italic: true},
+		theme.Strikethrough:  {Foreground: gray, Strikethrough: true},
+		theme.Highlight:      {Foreground: ColorBlack, Background: ColorYellow},
+		theme.Insertion:      {Foreground: getAccessibleColor(ColorGreen), Underline: true},
+		theme.Deletion:       {Foreground: getAccessibleColor(ColorRed), Strikethrough: true},
+		theme.CriticComment:  {Foreground: gray, Italic: true},
+		theme.HTML:           {Foreground: gray, Faint: true},
+		theme.Image:          {Foreground: ColorMagenta},
+		theme.Quote:          {Foreground: gray},
+		theme.List:           {Foreground: ColorYellow},
+		theme.Delimiter:      {Foreground: gray},
+
+		theme.Heading1: {Foreground: ColorBrightRed, Bold: true},
+		theme.Heading2: {Foreground: ColorBrightGreen, Bold: true},
+		theme.Heading3: {Foreground: ColorBrightYellow, Bold: true},
+		theme.Heading4: {Foreground: ColorBrightBlue, Bold: true},
+		theme.Heading5: {Foreground: ColorBrightMagenta, Bold: true},
+		theme.Heading6: {Foreground: ColorBrightCyan, Bold: true},
+
+		theme.Depth1: {Foreground: ColorYellow},
+		theme.Depth2: {Foreground: ColorCyan},
+		theme.Depth3: {Foreground: ColorMagenta},
+		theme.Depth4: {Foreground: ColorGreen},
+		theme.Depth5: {Foreground: ColorBlue},
+
+		theme.Invisible:       {Foreground: gray, Faint: true},
+		theme.ScrolledPast:    {Foreground: gray, Faint: true},
+		theme.DiagramFrame:    {Foreground: ColorMagenta},
+		theme.DiagramSource:   {Foreground: gray, Faint: true},
+		theme.StickyHeading:   {Foreground: ColorGray, Bold: true, Faint: true},
+		theme.ZenDim:          {Foreground: ColorGray, Faint: true},
+		theme.DiffRemoved:     {Foreground: ColorRed, Bold: true},
+		theme.DiffAdded:       {Foreground: ColorGreen, Bold: true},
+		theme.DiffRemovedText: {Foreground: ColorBrightWhite, Background: ColorRed},
+		theme.DiffAddedText:   {Foreground: ColorBrightWhite, Background: ColorGreen},
+		theme.DiagnosticError: {Foreground: ColorRed, Underline: true},
+		theme.DiagnosticWarn:  {Foreground: ColorYellow, Underline: true},
+		theme.DiagnosticInfo:  {Foreground: ColorCyan, Underline: true},
+		theme.ReviewComment:   {Foreground: ColorBrightYellow, Underline: true},
+		theme.RemoteCursor:    {Foreground: ColorBrightWhite, Background: ColorMagenta},
+		theme.LineNumber:      {Foreground: ColorGray},
+		theme.Cursor:          {Reverse: true},
+		theme.Selection:       {Reverse: true},
+	}}
+}
+
+// LightTheme returns the built-in theme for light backgrounds: the default
+// theme with the bright colors, which wash out on white, swapped for their
+// normal counterparts and light gray text for dark gray.
+func LightTheme() *theme.Theme {
+	return derivedTheme(theme.Light, lightColor)
+}
+
+// lightColor returns the color to draw color in on a light background.
+func lightColor(color string) string {
+	switch color {
+	case ColorWhite:
+		return ColorGray
+	case ColorBrightRed, ColorBrightGreen, ColorBrightYellow, ColorBrightBlue, ColorBrightMagenta, ColorBrightCyan:
+		n, _ := strconv.Atoi(color)
+		return strconv.Itoa(n - 8)
+	default:
+		return color
+	}
+}
+
+// HighContrastTheme returns the built-in theme for low vision: the default
+// theme in the brightest colors, with no dimmed text.
+func HighContrastTheme() *theme.Theme {
+	t := derivedTheme(theme.HighContrast, getHighContrastColor)
+	for element, style := range t.Styles {
+		style.Faint = false
+		t.Styles[element] = style
+	}
+	return t
+}
+
+// deuteranopiaColors replaces the terminal's reds and greens, which look
+// alike with red-green color blindness, with the Okabe-Ito palette: red
+// becomes vermillion and green sky blue, so diffs read as orange against
+// blue.
+var deuteranopiaColors = map[string]string{
+	ColorRed:           "#D55E00",
+	ColorBrightRed:     "#D55E00",
+	ColorGreen:         "#56B4E9",
+	ColorBrightGreen:   "#56B4E9",
+	ColorYellow:        "#F0E442",
+	ColorBrightYellow:  "#F0E442",
+	ColorBlue:          "#0072B2",
+	ColorBrightBlue:    "#0072B2",
+	ColorMagenta:       "#CC79A7",
+	ColorBrightMagenta: "#CC79A7",
+	ColorCyan:          "#009E73",
+	ColorBrightCyan:    "#009E73",
+}
+
+// DeuteranopiaTheme returns the built-in theme for red-green color
+// blindness.
+func DeuteranopiaTheme() *theme.Theme {
+	return derivedTheme(theme.Deuteranopia, func(color string) string {
+		if safe, ok := deuteranopiaColors[color]; ok && shouldUseColor() {
+			return safe
+		}
+		return color
+	})
+}
+
+// derivedTheme returns the default theme named name, with every color
+// passed through recolor.
+func derivedTheme(name string, recolor func(string) string) *theme.Theme {
+	t := DefaultTheme().Clone(name)
+	for element, style := range t.Styles {
+		style.Foreground = recolor(style.Foreground)
+		style.Background = recolor(style.Background)
+		t.Styles[element] = style
+	}
+	return t
+}
+
+// themed returns the active theme's style for element, adjusted for
+// contrast with the background.
+func themed(element string) plugin.Style {
+	return theme.Resolved().Style(element)
+}
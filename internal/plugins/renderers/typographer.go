@@ -0,0 +1,96 @@
+package renderers
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// typographerPunctuation maps ASCII punctuation to its typographic form,
+// longest first so "---" wins over "--". These match goldmark's
+// Typographer extension used for HTML output.
+var typographerPunctuation = []struct {
+	ascii, smart string
+}{
+	{"---", "—"},
+	{"...", "…"},
+	{"--", "–"},
+	{"<<", "«"},
+	{">>", "»"},
+}
+
+// typographerSpans replaces straight quotes, dashes and ellipses with
+// their typographic forms. Code spans and backslash escapes are left as
+// written, as are lines without words such as rules ("---") and table
+// delimiter rows.
+func typographerSpans(line string) []inlineSpan {
+	if strings.IndexFunc(line, func(r rune) bool { return unicode.IsLetter(r) || unicode.IsDigit(r) }) < 0 {
+		return nil
+	}
+
+	var spans []inlineSpan
+	for i := 0; i < len(line); {
+		switch line[i] {
+		case '\\':
+			i += 2
+			continue
+		case '`':
+			i = skipCodeSpan(line, i)
+			continue
+		case '"', '\'':
+			spans = append(spans, inlineSpan{start: i, end: i + 1, text: smartQuote(line, i)})
+			i++
+			continue
+		}
+
+		matched := false
+		for _, p := range typographerPunctuation {
+			if strings.HasPrefix(line[i:], p.ascii) {
+				spans = append(spans, inlineSpan{start: i, end: i + len(p.ascii), text: p.smart})
+				i += len(p.ascii)
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			i++
+		}
+	}
+	return spans
+}
+
+// smartQuote returns the curly form of the quote at byte offset i: an
+// opening quote after whitespace or an opening bracket, a closing one
+// (or apostrophe) otherwise.
+func smartQuote(line string, i int) string {
+	opening := true
+	if i > 0 {
+		prev, _ := utf8.DecodeLastRuneInString(line[:i])
+		opening = unicode.IsSpace(prev) || strings.ContainsRune("([{-–—", prev)
+	}
+
+	switch {
+	case line[i] == '"' && opening:
+		return "“"
+	case line[i] == '"':
+		return "”"
+	case opening:
+		return "‘"
+	default:
+		return "’"
+	}
+}
+
+// skipCodeSpan returns the offset after the code span opening at start,
+// or after the backtick run if it is unmatched.
+func skipCodeSpan(line string, start int) int {
+	run := 0
+	for start+run < len(line) && line[start+run] == '`' {
+		run++
+	}
+	fence := strings.Repeat("`", run)
+	if end := strings.Index(line[start+run:], fence); end >= 0 {
+		return start + run + end + run
+	}
+	return start + run
+}
@@ -0,0 +1,45 @@
+// Package storage holds the storage plugins that read and write
+// documents: local files, remote hosts, web URLs and memory.
+package storage
+
+import (
+	"context"
+	"strings"
+
+	"github.com/ofri/mde/pkg/ast"
+	"github.com/ofri/mde/pkg/plugin"
+)
+
+// Local implements the StoragePlugin interface for the local file system,
+// serving plain paths and file:// URLs.
+type Local struct{}
+
+// NewLocal creates the local file storage
+func NewLocal() *Local {
+	return &Local{}
+}
+
+// Name returns the plugin name
+func (l *Local) Name() string {
+	return "local"
+}
+
+// Schemes returns "" for plain paths and "file"
+func (l *Local) Schemes() []string {
+	return []string{"", "file"}
+}
+
+// Configure accepts no options
+func (l *Local) Configure(options map[string]interface{}) error {
+	return nil
+}
+
+func (l *Local) ReadFile(ctx context.Context, name string) ([]byte, error) {
+	return ast.LocalFiles.ReadFile(ctx, strings.TrimPrefix(name, "file://"))
+}
+
+func (l *Local) WriteFile(ctx context.Context, name string, data []byte) error {
+	return ast.LocalFiles.WriteFile(ctx, strings.TrimPrefix(name, "file://"), data)
+}
+
+var _ plugin.StoragePlugin = (*Local)(nil)
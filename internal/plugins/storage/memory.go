@@ -0,0 +1,57 @@
+package storage
+
+import (
+	"context"
+	"os"
+	"sync"
+
+	"github.com/ofri/mde/pkg/plugin"
+)
+
+// Memory implements the StoragePlugin interface with files held in
+// memory under mem:// URLs, so tests can load and save without a disk.
+type Memory struct {
+	mu    sync.RWMutex
+	files map[string][]byte
+}
+
+// NewMemory creates an empty in-memory storage
+func NewMemory() *Memory {
+	return &Memory{files: make(map[string][]byte)}
+}
+
+// Name returns the plugin name
+func (m *Memory) Name() string {
+	return "memory"
+}
+
+// Schemes returns "mem"
+func (m *Memory) Schemes() []string {
+	return []string{"mem"}
+}
+
+// Configure accepts no options
+func (m *Memory) Configure(options map[string]interface{}) error {
+	return nil
+}
+
+func (m *Memory) ReadFile(_ context.Context, name string) ([]byte, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	data, exists := m.files[name]
+	if !exists {
+		return nil, &os.PathError{Op: "read", Path: name, Err: os.ErrNotExist}
+	}
+	return append([]byte(nil), data...), nil
+}
+
+func (m *Memory) WriteFile(_ context.Context, name string, data []byte) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.files[name] = append([]byte(nil), data...)
+	return nil
+}
+
+var _ plugin.StoragePlugin = (*Memory)(nil)
@@ -0,0 +1,92 @@
+package storage
+
+import (
+	"context"
+	"sync"
+
+	"github.com/ofri/mde/internal/remote"
+	"github.com/ofri/mde/pkg/plugin"
+)
+
+// SSH implements the StoragePlugin interface for files on other hosts,
+// reached through the system ssh client.
+type SSH struct {
+	client remote.SSH
+}
+
+// NewSSH creates the ssh:// and sftp:// storage
+func NewSSH() *SSH {
+	return &SSH{}
+}
+
+// Name returns the plugin name
+func (s *SSH) Name() string {
+	return "ssh"
+}
+
+// Schemes returns "ssh" and "sftp"
+func (s *SSH) Schemes() []string {
+	return remote.Schemes
+}
+
+// Configure accepts no options; hosts are set up in ~/.ssh/config
+func (s *SSH) Configure(options map[string]interface{}) error {
+	return nil
+}
+
+func (s *SSH) ReadFile(ctx context.Context, name string) ([]byte, error) {
+	return s.client.ReadFile(ctx, name)
+}
+
+func (s *SSH) WriteFile(ctx context.Context, name string, data []byte) error {
+	return s.client.WriteFile(ctx, name, data)
+}
+
+// HTTP implements the StoragePlugin interface for documents on the web,
+// which are read-only.
+type HTTP struct {
+	mu     sync.RWMutex
+	client remote.HTTP
+}
+
+// NewHTTP creates the http:// and https:// storage
+func NewHTTP() *HTTP {
+	return &HTTP{}
+}
+
+// Name returns the plugin name
+func (h *HTTP) Name() string {
+	return "http"
+}
+
+// Schemes returns "http" and "https"
+func (h *HTTP) Schemes() []string {
+	return []string{"http", "https"}
+}
+
+// Configure sets "maxBytes", the size of the largest document fetched
+func (h *HTTP) Configure(options map[string]interface{}) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if maxBytes, ok := options["maxBytes"].(int64); ok {
+		h.client.MaxBytes = maxBytes
+	}
+	return nil
+}
+
+func (h *HTTP) ReadFile(ctx context.Context, name string) ([]byte, error) {
+	h.mu.RLock()
+	client := h.client
+	h.mu.RUnlock()
+	return client.ReadFile(ctx, name)
+}
+
+func (h *HTTP) WriteFile(ctx context.Context, name string, data []byte) error {
+	return h.client.WriteFile(ctx, name, data)
+}
+
+var (
+	_ plugin.StoragePlugin = (*SSH)(nil)
+	_ plugin.StoragePlugin = (*HTTP)(nil)
+)
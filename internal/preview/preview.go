@@ -0,0 +1,256 @@
+// Package preview serves a document as HTML on a local HTTP server for
+// proofreading in a browser. Pages reload their content through
+// server-sent events whenever the document is updated:
+//
+//	/         the page, with a script subscribing to /events
+//	/content  the current HTML fragment
+//	/events   an event stream sending the version of each update
+//
+// Other paths serve files from the document's directory, so relative
+// images show: those the document links to, and images, media, fonts and
+// stylesheets, but never hidden files such as .git or .env. Requests must
+// name the server by an IP address or localhost, so that a page elsewhere
+// cannot reach it through a domain name rebound to its address.
+package preview
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"html/template"
+	"net"
+	"net/http"
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Document is what the server shows.
+type Document struct {
+	Title   string // Shown as the page title
+	Content []byte // HTML fragment
+	Dir     string // Directory relative links resolve from; none when empty
+}
+
+// Server is a running preview server.
+type Server struct {
+	listener net.Listener
+	http     *http.Server
+
+	mu      sync.Mutex
+	doc     Document
+	version int
+	clients map[chan int]struct{}
+}
+
+// Start listens on addr (host:port, port 0 picks one) and serves doc
+// until Close.
+func Start(addr string, doc Document) (*Server, error) {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	s := &Server{listener: listener, doc: doc, version: 1, clients: map[chan int]struct{}{}}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", s.serveFile)
+	mux.HandleFunc("/content", s.serveContent)
+	mux.HandleFunc("/events", s.serveEvents)
+	s.http = &http.Server{Handler: s.checkHost(mux), ReadHeaderTimeout: 10 * time.Second}
+	go func() { _ = s.http.Serve(listener) }()
+	return s, nil
+}
+
+// URL returns the address of the page.
+func (s *Server) URL() string {
+	return "http://" + s.listener.Addr().String() + "/"
+}
+
+// Update replaces the document and tells open pages to reload it.
+func (s *Server) Update(doc Document) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.doc = doc
+	s.version++
+	for client := range s.clients {
+		select {
+		case client <- s.version:
+		default: // The client still has an update to fetch; it gets the latest
+		}
+	}
+}
+
+// Close stops the server, ending the event streams of open pages.
+func (s *Server) Close() error {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	s.mu.Lock()
+	for client := range s.clients {
+		close(client)
+		delete(s.clients, client)
+	}
+	s.mu.Unlock()
+	return s.http.Shutdown(ctx)
+}
+
+func (s *Server) document() (Document, int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.doc, s.version
+}
+
+// checkHost rejects requests whose Host is not an IP address or
+// localhost with the server's port: a domain name that resolves to the
+// server can be rebound to it by a page that name serves.
+func (s *Server) checkHost(next http.Handler) http.Handler {
+	_, port, _ := net.SplitHostPort(s.listener.Addr().String())
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host, hostPort, err := net.SplitHostPort(r.Host)
+		if err != nil || hostPort != port || (host != "localhost" && net.ParseIP(host) == nil) {
+			http.Error(w, "unknown host", http.StatusForbidden)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// assetExts are the extensions of files served whether or not the
+// document links to them, as the page may embed them.
+var assetExts = map[string]bool{
+	".png": true, ".jpg": true, ".jpeg": true, ".gif": true, ".svg": true, ".webp": true, ".avif": true, ".ico": true,
+	".mp4": true, ".webm": true, ".mp3": true, ".ogg": true, ".wav": true,
+	".css": true, ".woff": true, ".woff2": true, ".ttf": true,
+}
+
+// linkPattern matches the targets of the links and embeds in HTML, up to
+// any query or fragment.
+var linkPattern = regexp.MustCompile(`(?i)\b(?:href|src)="([^"?#]*)`)
+
+// servable reports whether the file at name, a URL path, may be served
+// from the directory of doc: not hidden, and an asset or linked to.
+func servable(doc Document, name string) bool {
+	name = path.Clean(name)
+	for _, segment := range strings.Split(name, "/") {
+		if strings.HasPrefix(segment, ".") {
+			return false
+		}
+	}
+	if assetExts[strings.ToLower(path.Ext(name))] {
+		return true
+	}
+	for _, match := range linkPattern.FindAllSubmatch(doc.Content, -1) {
+		target, err := url.PathUnescape(html.UnescapeString(string(match[1])))
+		if err != nil || target == "" || strings.Contains(target, ":") || strings.HasPrefix(target, "//") {
+			continue // Not a file in the directory
+		}
+		if path.Join("/", target) == name {
+			return true
+		}
+	}
+	return false
+}
+
+// serveFile serves the page at / and the document's files elsewhere.
+func (s *Server) serveFile(w http.ResponseWriter, r *http.Request) {
+	doc, version := s.document()
+	if r.URL.Path != "/" {
+		if doc.Dir == "" || !servable(doc, r.URL.Path) {
+			http.NotFound(w, r)
+			return
+		}
+		http.FileServer(http.Dir(doc.Dir)).ServeHTTP(w, r)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	_ = page.Execute(w, struct {
+		Title   string
+		Content template.HTML
+		Version int
+	}{doc.Title, template.HTML(doc.Content), version})
+}
+
+func (s *Server) serveContent(w http.ResponseWriter, r *http.Request) {
+	doc, _ := s.document()
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	_, _ = w.Write(doc.Content)
+}
+
+// serveEvents streams the version of every update until the page closes
+// or the server stops.
+func (s *Server) serveEvents(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+	client := make(chan int, 1)
+	s.mu.Lock()
+	s.clients[client] = struct{}{}
+	version := s.version
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		delete(s.clients, client)
+		s.mu.Unlock()
+	}()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-store")
+	fmt.Fprintf(w, "retry: 1000\ndata: %d\n\n", version)
+	flusher.Flush()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case version, ok := <-client:
+			if !ok {
+				return
+			}
+			fmt.Fprintf(w, "data: %d\n\n", version)
+			flusher.Flush()
+		}
+	}
+}
+
+// page shows the document, fetching /content again on every event with a
+// newer version and keeping the scroll position.
+var page = template.Must(template.New("page").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{.Title}}</title>
+<style>
+body { max-width: 50rem; margin: 0 auto; padding: 1.5rem 2rem; font-family: -apple-system, "Segoe UI", Helvetica, Arial, sans-serif; line-height: 1.6; color: #24292f; }
+pre { background: #f6f8fa; padding: 0.8rem; overflow-x: auto; }
+code { font-family: ui-monospace, Menlo, Consolas, monospace; font-size: 0.9em; }
+table { border-collapse: collapse; }
+th, td { border: 1px solid #d0d7de; padding: 0.3rem 0.6rem; }
+img { max-width: 100%; }
+</style>
+</head>
+<body>
+<main id="content">
+{{.Content}}
+</main>
+<script>
+let version = {{.Version}};
+const events = new EventSource("/events");
+events.onmessage = async (event) => {
+	const next = Number(event.data);
+	if (next === version) return;
+	version = next;
+	const response = await fetch("/content", {cache: "no-store"});
+	if (!response.ok) return;
+	const scroll = window.scrollY;
+	document.getElementById("content").innerHTML = await response.text();
+	window.scrollTo(0, scroll);
+};
+</script>
+</body>
+</html>
+`))
@@ -0,0 +1,59 @@
+package remote
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// ErrReadOnly is returned when saving to a URL that cannot be written.
+var ErrReadOnly = errors.New("read-only")
+
+// HTTP fetches documents from http:// and https:// URLs for reading.
+type HTTP struct {
+	MaxBytes int64 // Larger documents are refused; 0 means no limit
+}
+
+// ReadFile downloads the document at name.
+func (h HTTP) ReadFile(ctx context.Context, name string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, name, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "text/markdown, text/plain;q=0.9, */*;q=0.1")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrConnection, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, fmt.Errorf("%s returned %s", name, resp.Status)
+	}
+
+	body := io.Reader(resp.Body)
+	if h.MaxBytes > 0 {
+		if resp.ContentLength > h.MaxBytes {
+			return nil, h.tooLarge(name)
+		}
+		body = io.LimitReader(resp.Body, h.MaxBytes+1)
+	}
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrConnection, err)
+	}
+	if h.MaxBytes > 0 && int64(len(data)) > h.MaxBytes {
+		return nil, h.tooLarge(name)
+	}
+	return data, nil
+}
+
+func (h HTTP) tooLarge(name string) error {
+	return fmt.Errorf("%s is larger than %d KB", name, h.MaxBytes>>10)
+}
+
+// WriteFile refuses: URLs are opened for reading only.
+func (HTTP) WriteFile(_ context.Context, name string, _ []byte) error {
+	return fmt.Errorf("%s is %w", name, ErrReadOnly)
+}
@@ -0,0 +1,137 @@
+// Package remote edits files on other machines. The SSH backend runs the
+// system ssh client, so it uses the user's keys, agent and ~/.ssh/config.
+package remote
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/ofri/mde/internal/shell"
+)
+
+// ErrConnection is wrapped by errors from a failed or dropped connection,
+// as opposed to errors reported by the remote host.
+var ErrConnection = errors.New("connection lost")
+
+// missingStatus is the exit status of the read command for a missing file
+const missingStatus = 44
+
+// Schemes are the URL schemes the SSH backend serves.
+var Schemes = []string{"ssh", "sftp"}
+
+// Location is a file on a remote host.
+type Location struct {
+	User string
+	Host string
+	Port string
+	Path string // "~/" for paths under the home directory
+}
+
+// Parse splits a URL like ssh://user@host:2222/path/notes.md. A path
+// starting with /~/ is relative to the home directory.
+func Parse(name string) (Location, error) {
+	u, err := url.Parse(name)
+	if err != nil {
+		return Location{}, err
+	}
+	if (u.Scheme != "ssh" && u.Scheme != "sftp") || u.Host == "" || u.Path == "" || u.Path == "/" {
+		return Location{}, fmt.Errorf("not an ssh://host/path URL: %s", name)
+	}
+	loc := Location{Host: u.Hostname(), Port: u.Port(), Path: u.Path}
+	if u.User != nil {
+		loc.User = u.User.Username()
+	}
+	if strings.HasPrefix(loc.Path, "/~/") {
+		loc.Path = loc.Path[1:]
+	}
+	return loc, nil
+}
+
+// sshArgs returns the ssh arguments to run command at the location. The
+// client never prompts and gives up on a connection silent for 15 seconds.
+func (l Location) sshArgs(command string) []string {
+	args := []string{"-o", "BatchMode=yes", "-o", "ConnectTimeout=10",
+		"-o", "ServerAliveInterval=5", "-o", "ServerAliveCountMax=3"}
+	if l.Port != "" {
+		args = append(args, "-p", l.Port)
+	}
+	host := l.Host
+	if l.User != "" {
+		host = l.User + "@" + host
+	}
+	return append(args, "--", host, command)
+}
+
+// quotedPath is the path quoted for the remote shell, leaving a leading ~/
+// unquoted so the shell expands it.
+func (l Location) quotedPath(suffix string) string {
+	if rest, ok := strings.CutPrefix(l.Path, "~/"); ok {
+		return "~/" + shell.Quote(rest+suffix)
+	}
+	return shell.Quote(l.Path + suffix)
+}
+
+// SSH reads and writes files over ssh:// and sftp:// URLs.
+type SSH struct{}
+
+// ReadFile fetches the file, returning an error wrapping os.ErrNotExist
+// when it does not exist.
+func (SSH) ReadFile(ctx context.Context, name string) ([]byte, error) {
+	loc, err := Parse(name)
+	if err != nil {
+		return nil, err
+	}
+	path := loc.quotedPath("")
+	command := fmt.Sprintf("if [ -e %s ]; then cat -- %s; else exit %d; fi", path, path, missingStatus)
+	data, err := run(ctx, loc.sshArgs(command), nil)
+	var exit *exec.ExitError
+	if errors.As(err, &exit) && exit.ExitCode() == missingStatus {
+		return nil, &os.PathError{Op: "read", Path: name, Err: os.ErrNotExist}
+	}
+	return data, err
+}
+
+// WriteFile uploads data to a temporary file beside the target and then
+// renames it, so a dropped connection leaves the old file intact.
+func (SSH) WriteFile(ctx context.Context, name string, data []byte) error {
+	loc, err := Parse(name)
+	if err != nil {
+		return err
+	}
+	tmp := loc.quotedPath(".mde-tmp")
+	command := fmt.Sprintf("cat > %s && mv -f -- %s %s", tmp, tmp, loc.quotedPath(""))
+	_, err = run(ctx, loc.sshArgs(command), data)
+	return err
+}
+
+// run runs ssh with input on stdin and returns its output. ssh exits with
+// 255 when the connection fails.
+func run(ctx context.Context, args []string, input []byte) ([]byte, error) {
+	cmd := exec.CommandContext(ctx, "ssh", args...)
+	cmd.Stdin = bytes.NewReader(input)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	err := cmd.Run()
+	msg := strings.TrimSpace(stderr.String())
+	var exit *exec.ExitError
+	switch {
+	case err == nil:
+		return stdout.Bytes(), nil
+	case ctx.Err() != nil:
+		return nil, fmt.Errorf("ssh: %w: %w", ErrConnection, ctx.Err())
+	case errors.As(err, &exit) && exit.ExitCode() == 255:
+		return nil, fmt.Errorf("ssh: %w: %s", ErrConnection, msg)
+	case msg != "":
+		return nil, fmt.Errorf("ssh: %s: %w", msg, err)
+	default:
+		return nil, fmt.Errorf("ssh: %w", err)
+	}
+}
@@ -0,0 +1,87 @@
+// Package session remembers writing progress between runs, so a daily
+// word goal counts what was written since the day's first session rather
+// than since the editor last started.
+//
+// Progress is kept in the file named by $MDE_SESSION_FILE, or in
+// <user cache dir>/mde/sessions.json (~/.cache/mde/sessions.json on
+// Linux). Entries from earlier days are dropped when the file is written.
+// Like the debug log, the store never fails loudly: if the file cannot be
+// read or written, progress simply starts over.
+package session
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// dayLayout keys the store by calendar day
+const dayLayout = "2006-01-02"
+
+var mu sync.Mutex
+
+// store maps a day to each file's word count at its first session that
+// day.
+type store map[string]map[string]int
+
+// Path returns the store location, or "" if none can be determined.
+func Path() string {
+	if path := os.Getenv("MDE_SESSION_FILE"); path != "" {
+		return path
+	}
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "mde", "sessions.json")
+}
+
+// Baseline returns the word count file had when it was first opened on
+// day. The first call of the day records words as that count.
+func Baseline(file string, day time.Time, words int) int {
+	path := Path()
+	if path == "" || file == "" {
+		return words
+	}
+	if abs, err := filepath.Abs(file); err == nil {
+		file = abs
+	}
+	key := day.Format(dayLayout)
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	s := load(path)
+	if baseline, ok := s[key][file]; ok {
+		return baseline
+	}
+	// Only today's entries are worth keeping
+	s = store{key: s[key]}
+	if s[key] == nil {
+		s[key] = make(map[string]int)
+	}
+	s[key][file] = words
+	save(path, s)
+	return words
+}
+
+func load(path string) store {
+	s := store{}
+	if data, err := os.ReadFile(path); err == nil {
+		json.Unmarshal(data, &s)
+	}
+	return s
+}
+
+func save(path string, s store) {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return
+	}
+	os.WriteFile(path, data, 0o644)
+}
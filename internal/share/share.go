@@ -0,0 +1,284 @@
+// Package share connects editors editing one buffer together. One editor
+// hosts a session, on a TCP address or a unix socket, and the others join
+// it; each keeps a crdt replica of the text and sends the operations it
+// makes, and where its cursor is, to the others through the host.
+// Messages are JSON objects, one per line:
+//
+//	{"site":"9f2c…","name":"Ann"}                                   Joining
+//	{"site":"41d0…","to":"9f2c…","path":"notes.md","snapshot":{…}} The host's welcome
+//	{"site":"9f2c…","ops":[{"id":{"site":"9f2c…","counter":12},…}]}
+//	{"site":"9f2c…","cursor":120}
+//
+// Anyone who can connect can read and edit the buffer, and TCP sessions
+// are not encrypted: share on a unix socket, on localhost, or through an
+// SSH tunnel.
+package share
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/ofri/mde/internal/crdt"
+)
+
+// Message is what participants send each other. Site names the sender's
+// replica; the other fields are set as needed.
+type Message struct {
+	Site     string         `json:"site"`
+	To       string         `json:"to,omitempty"`       // Only for this site
+	Name     string         `json:"name,omitempty"`     // The sender's name, on joining and in the welcome
+	Path     string         `json:"path,omitempty"`     // The host's file name, in the welcome
+	Snapshot *crdt.Snapshot `json:"snapshot,omitempty"` // The host's replica, in the welcome
+	Ops      []crdt.Op      `json:"ops,omitempty"`      // Edits the sender made
+	Cursor   *int           `json:"cursor,omitempty"`   // Rune offset of the sender's cursor
+	Left     bool           `json:"left,omitempty"`     // The sender left
+}
+
+// Session is a shared session, hosted or joined.
+type Session struct {
+	listener net.Listener // Hosting only
+	incoming chan Message
+	done     chan struct{}
+	wg       sync.WaitGroup
+	once     sync.Once
+
+	mu    sync.Mutex
+	peers map[*peer]struct{}
+}
+
+// peer is a connection to another participant: for the host, a guest,
+// and for a guest, the host. Messages to it queue in out, so a slow
+// connection does not hold up the editor.
+type peer struct {
+	conn net.Conn
+	site string // Learned from its first message
+	out  chan Message
+	gone chan struct{}
+}
+
+// queueSize is how many messages may wait for a peer; one that falls
+// further behind is disconnected.
+const queueSize = 1024
+
+// send queues msg for p.
+func (p *peer) send(msg Message) {
+	select {
+	case p.out <- msg:
+	default:
+		p.conn.Close()
+	}
+}
+
+// write writes the messages queued for p until it is gone.
+func (p *peer) write() {
+	encoder := json.NewEncoder(p.conn)
+	for {
+		select {
+		case msg := <-p.out:
+			if encoder.Encode(msg) != nil {
+				p.conn.Close()
+			}
+		case <-p.gone:
+			return
+		}
+	}
+}
+
+// network returns the network addr is on: a unix socket for a path, TCP
+// for host:port.
+func network(addr string) string {
+	if strings.ContainsRune(addr, '/') || !strings.ContainsRune(addr, ':') {
+		return "unix"
+	}
+	return "tcp"
+}
+
+// Host starts a session that others join at addr, a host:port or the
+// path of a unix socket, readable and writable by the user only.
+func Host(addr string) (*Session, error) {
+	if network(addr) == "unix" {
+		if conn, err := net.Dial("unix", addr); err == nil {
+			conn.Close()
+			return nil, fmt.Errorf("%s is in use by another session", addr)
+		}
+		os.Remove(addr)
+	}
+	listener, err := net.Listen(network(addr), addr)
+	if err != nil {
+		return nil, err
+	}
+	if network(addr) == "unix" {
+		if err := os.Chmod(addr, 0o600); err != nil {
+			listener.Close()
+			return nil, err
+		}
+	}
+	s := newSession()
+	s.listener = listener
+	s.wg.Add(1)
+	go s.accept()
+	return s, nil
+}
+
+// Join joins the session hosted at addr.
+func Join(addr string) (*Session, error) {
+	conn, err := net.Dial(network(addr), addr)
+	if err != nil {
+		return nil, err
+	}
+	s := newSession()
+	s.add(conn)
+	return s, nil
+}
+
+func newSession() *Session {
+	return &Session{
+		incoming: make(chan Message, 64),
+		done:     make(chan struct{}),
+		peers:    make(map[*peer]struct{}),
+	}
+}
+
+// Addr returns the address others join a hosted session at, or "".
+func (s *Session) Addr() string {
+	if s.listener == nil {
+		return ""
+	}
+	return s.listener.Addr().String()
+}
+
+// Hosting reports whether the session is hosted here.
+func (s *Session) Hosting() bool {
+	return s.listener != nil
+}
+
+// Incoming returns the messages from the other participants. It is closed
+// when the session ends: once closed, or for a guest, when the host
+// leaves.
+func (s *Session) Incoming() <-chan Message {
+	return s.incoming
+}
+
+// Send sends msg to the other participants, or only to msg.To, without
+// waiting for it to be written.
+func (s *Session) Send(msg Message) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for p := range s.peers {
+		if msg.To == "" || msg.To == p.site || !s.Hosting() {
+			p.send(msg)
+		}
+	}
+}
+
+// Close leaves the session, ending it for everyone when hosted here.
+func (s *Session) Close() error {
+	var err error
+	s.once.Do(func() {
+		close(s.done)
+		if s.listener != nil {
+			err = s.listener.Close()
+		}
+		s.mu.Lock()
+		for p := range s.peers {
+			p.conn.Close()
+		}
+		s.mu.Unlock()
+		go func() {
+			s.wg.Wait()
+			close(s.incoming)
+		}()
+	})
+	return err
+}
+
+func (s *Session) accept() {
+	defer s.wg.Done()
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return // Closed
+		}
+		s.add(conn)
+	}
+}
+
+// add starts reading the messages from conn.
+func (s *Session) add(conn net.Conn) {
+	p := &peer{conn: conn, out: make(chan Message, queueSize), gone: make(chan struct{})}
+	s.mu.Lock()
+	s.peers[p] = struct{}{}
+	s.mu.Unlock()
+	s.wg.Add(2)
+	go func() {
+		defer s.wg.Done()
+		p.write()
+	}()
+	go func() {
+		defer s.wg.Done()
+		s.read(p)
+	}()
+}
+
+// read delivers the messages from p, relaying them to the other guests
+// when hosting, until the connection closes.
+func (s *Session) read(p *peer) {
+	scanner := bufio.NewScanner(p.conn)
+	scanner.Buffer(make([]byte, 64*1024), 64*1024*1024)
+	for scanner.Scan() {
+		var msg Message
+		if json.Unmarshal(scanner.Bytes(), &msg) != nil || msg.Site == "" {
+			continue
+		}
+		if s.Hosting() {
+			s.mu.Lock()
+			p.site = msg.Site
+			s.mu.Unlock()
+			s.relay(p, msg)
+		}
+		if !s.deliver(msg) {
+			break
+		}
+	}
+
+	s.mu.Lock()
+	delete(s.peers, p)
+	s.mu.Unlock()
+	close(p.gone)
+	p.conn.Close()
+	switch {
+	case s.Hosting() && p.site != "":
+		left := Message{Site: p.site, Left: true}
+		s.relay(p, left)
+		s.deliver(left)
+	case !s.Hosting():
+		s.Close() // The host left
+	}
+}
+
+// relay passes msg from p on to the other guests.
+func (s *Session) relay(from *peer, msg Message) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for p := range s.peers {
+		if p != from && (msg.To == "" || msg.To == p.site) {
+			p.send(msg)
+		}
+	}
+}
+
+// deliver queues msg for Incoming, reporting false once the session is
+// closed.
+func (s *Session) deliver(msg Message) bool {
+	select {
+	case s.incoming <- msg:
+		return true
+	case <-s.done:
+		return false
+	}
+}
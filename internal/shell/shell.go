@@ -0,0 +1,11 @@
+// Package shell builds command lines for sh -c, as run by filters,
+// diagram commands and ssh.
+package shell
+
+import "strings"
+
+// Quote wraps s in single quotes for sh, so it reaches the command as one
+// argument whatever it holds.
+func Quote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -0,0 +1,304 @@
+// Package site builds a static HTML site from a tree of markdown files:
+// each file becomes a page in a shared template, links between them are
+// rewritten to the pages, and an index lists them all.
+package site
+
+import (
+	"bytes"
+	_ "embed"
+	"fmt"
+	"html/template"
+	"io"
+	"io/fs"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/ofri/mde/internal/mdhtml"
+)
+
+//go:embed default.html
+var defaultTemplate string
+
+// Options describe one build.
+type Options struct {
+	Source      string // Directory of markdown files
+	Output      string // Directory the site is written to
+	Template    string // html/template file; the built-in one when empty
+	Title       string // Site title; the source directory's name when empty
+	Typographer bool   // Render smart quotes, dashes and ellipses
+}
+
+// Page is one page of the site.
+type Page struct {
+	Title string // The page's first heading, or its file name
+	Path  string // Slash separated, relative to the site root, e.g. "guide/intro.html"
+}
+
+// pageData is what the template renders a page from.
+type pageData struct {
+	Title     string
+	SiteTitle string
+	Content   template.HTML
+	Root      string // Relative path from the page to the site root, "" or "../"...
+	Path      string
+	Pages     []Page
+}
+
+// indexName is the page the generated index is written to.
+const indexName = "index.html"
+
+// Build converts every markdown file under opts.Source to HTML under
+// opts.Output, copies the other files alongside, and writes an index of
+// the pages. Hidden files and directories are skipped. It returns the
+// pages written, the index last.
+func Build(opts Options) ([]Page, error) {
+	source, err := filepath.Abs(opts.Source)
+	if err != nil {
+		return nil, err
+	}
+	output, err := filepath.Abs(opts.Output)
+	if err != nil {
+		return nil, err
+	}
+	if info, err := os.Stat(source); err != nil {
+		return nil, err
+	} else if !info.IsDir() {
+		return nil, fmt.Errorf("%s is not a directory", opts.Source)
+	}
+	if output == source {
+		return nil, fmt.Errorf("the output directory must differ from the source")
+	}
+
+	text := defaultTemplate
+	if opts.Template != "" {
+		data, err := os.ReadFile(opts.Template)
+		if err != nil {
+			return nil, err
+		}
+		text = string(data)
+	}
+	tmpl, err := template.New("page").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("template: %w", err)
+	}
+
+	title := opts.Title
+	if title == "" {
+		title = filepath.Base(source)
+	}
+
+	// Collect the tree first so every page can list the others
+	var sources, assets []string
+	err = filepath.WalkDir(source, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if p == source {
+			return nil
+		}
+		if strings.HasPrefix(d.Name(), ".") || p == output {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() {
+			return nil
+		}
+		rel, err := filepath.Rel(source, p)
+		if err != nil {
+			return err
+		}
+		if isMarkdown(rel) {
+			sources = append(sources, filepath.ToSlash(rel))
+		} else {
+			assets = append(assets, filepath.ToSlash(rel))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var pages []Page
+	contents := map[string][]byte{}
+	var rootIndex []byte
+	for _, rel := range sources {
+		markdown, err := os.ReadFile(filepath.Join(source, filepath.FromSlash(rel)))
+		if err != nil {
+			return nil, err
+		}
+		if rel == "index.md" || rel == "index.markdown" {
+			rootIndex = markdown
+			continue
+		}
+		pages = append(pages, Page{Title: pageTitle(markdown, rel), Path: pagePath(rel)})
+		contents[pagePath(rel)] = markdown
+	}
+	sort.Slice(pages, func(i, j int) bool { return pages[i].Path < pages[j].Path })
+
+	for _, page := range pages {
+		html, err := mdhtml.Convert(contents[page.Path], mdhtml.Options{
+			Typographer: opts.Typographer,
+			RewriteLink: RewriteLink,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", page.Path, err)
+		}
+		data := pageData{
+			Title:     page.Title,
+			SiteTitle: title,
+			Content:   template.HTML(html),
+			Root:      rootPrefix(page.Path),
+			Path:      page.Path,
+			Pages:     pages,
+		}
+		if err := render(tmpl, filepath.Join(output, filepath.FromSlash(page.Path)), data); err != nil {
+			return nil, err
+		}
+	}
+
+	index, err := indexContent(rootIndex, pages, opts.Typographer)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", indexName, err)
+	}
+	indexTitle := title
+	if rootIndex != nil {
+		indexTitle = pageTitle(rootIndex, "index.md")
+	}
+	err = render(tmpl, filepath.Join(output, indexName), pageData{
+		Title:     indexTitle,
+		SiteTitle: title,
+		Content:   template.HTML(index),
+		Path:      indexName,
+		Pages:     pages,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, rel := range assets {
+		if rel == indexName {
+			continue // The generated index wins
+		}
+		err := copyFile(filepath.Join(source, filepath.FromSlash(rel)), filepath.Join(output, filepath.FromSlash(rel)))
+		if err != nil {
+			return nil, err
+		}
+	}
+	return append(pages, Page{Title: indexTitle, Path: indexName}), nil
+}
+
+// RewriteLink points a relative link to a markdown file at its page,
+// as in guide.md#setup to guide.html#setup. Other links are kept.
+func RewriteLink(dest string) string {
+	u, err := url.Parse(dest)
+	if err != nil || u.Scheme != "" || u.Host != "" || u.Path == "" || strings.HasPrefix(u.Path, "/") {
+		return dest
+	}
+	if !isMarkdown(u.Path) {
+		return dest
+	}
+	u.Path = pagePath(u.Path)
+	return u.String()
+}
+
+// isMarkdown reports whether a file is converted to a page.
+func isMarkdown(name string) bool {
+	switch strings.ToLower(path.Ext(name)) {
+	case ".md", ".markdown":
+		return true
+	}
+	return false
+}
+
+// pagePath returns the page a markdown file is written to.
+func pagePath(rel string) string {
+	return strings.TrimSuffix(rel, path.Ext(rel)) + ".html"
+}
+
+// rootPrefix returns the relative path from a page up to the site root.
+func rootPrefix(pagePath string) string {
+	return strings.Repeat("../", strings.Count(pagePath, "/"))
+}
+
+// pageTitle returns the text of the first ATX heading in markdown, or the
+// file name without its extension.
+func pageTitle(markdown []byte, rel string) string {
+	fenced := false
+	for _, line := range strings.Split(string(markdown), "\n") {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			fenced = !fenced
+			continue
+		}
+		if fenced || !strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		text := strings.TrimLeft(trimmed, "#")
+		if len(trimmed)-len(text) > 6 || (text != "" && text[0] != ' ' && text[0] != '\t') {
+			continue
+		}
+		if text = strings.TrimSpace(strings.TrimRight(strings.TrimSpace(text), "#")); text != "" {
+			return text
+		}
+	}
+	return strings.TrimSuffix(path.Base(rel), path.Ext(rel))
+}
+
+// indexContent renders the index page: the root index.md when there is
+// one, followed by the list of pages.
+func indexContent(rootIndex []byte, pages []Page, typographer bool) ([]byte, error) {
+	var buf bytes.Buffer
+	if rootIndex != nil {
+		html, err := mdhtml.Convert(rootIndex, mdhtml.Options{Typographer: typographer, RewriteLink: RewriteLink})
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(html)
+	}
+	buf.WriteString("<ul class=\"site-index\">\n")
+	for _, page := range pages {
+		fmt.Fprintf(&buf, "<li><a href=\"%s\">%s</a></li>\n",
+			template.HTMLEscapeString(page.Path), template.HTMLEscapeString(page.Title))
+	}
+	buf.WriteString("</ul>\n")
+	return buf.Bytes(), nil
+}
+
+// render writes one page through the template.
+func render(tmpl *template.Template, target string, data pageData) error {
+	if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return fmt.Errorf("template: %s: %w", data.Path, err)
+	}
+	return os.WriteFile(target, buf.Bytes(), 0o644)
+}
+
+// copyFile copies an asset into the site.
+func copyFile(from, to string) error {
+	if err := os.MkdirAll(filepath.Dir(to), 0o755); err != nil {
+		return err
+	}
+	in, err := os.Open(from)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.Create(to)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
@@ -0,0 +1,137 @@
+// Package templates scaffolds new documents from named templates.
+//
+// Templates are markdown files using text/template syntax. User templates
+// live in the configured templates directory as <name>.md and take
+// precedence over the built-in set (adr, meeting, note).
+//
+// Available variables:
+//
+//	{{.Title}}   document title (from --title or the file name)
+//	{{.Date}}    current date, YYYY-MM-DD
+//	{{.Time}}    current time, HH:MM
+//	{{.Author}}  author from config, falling back to $USER
+package templates
+
+import (
+	"bytes"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+//go:embed builtin/*.md
+var builtin embed.FS
+
+// Vars holds the values substituted into a template.
+type Vars struct {
+	Title  string
+	Date   string
+	Time   string
+	Author string
+}
+
+// NewVars builds template variables for the given title and author at time now.
+func NewVars(title, author string, now time.Time) Vars {
+	if author == "" {
+		author = os.Getenv("USER")
+	}
+	return Vars{
+		Title:  title,
+		Date:   now.Format("2006-01-02"),
+		Time:   now.Format("15:04"),
+		Author: author,
+	}
+}
+
+// TitleFromFilename derives a human title from a file path
+// ("docs/my-decision.md" → "My decision").
+func TitleFromFilename(filename string) string {
+	base := strings.TrimSuffix(filepath.Base(filename), filepath.Ext(filename))
+	base = strings.NewReplacer("-", " ", "_", " ").Replace(base)
+	if base == "" || base == "." {
+		return ""
+	}
+	first, size := utf8.DecodeRuneInString(base)
+	return string(unicode.ToUpper(first)) + base[size:]
+}
+
+// List returns the names of all templates available from dir and the
+// built-in set, sorted and de-duplicated.
+func List(dir string) []string {
+	seen := map[string]bool{}
+
+	if entries, err := fs.ReadDir(builtin, "builtin"); err == nil {
+		for _, entry := range entries {
+			seen[strings.TrimSuffix(entry.Name(), ".md")] = true
+		}
+	}
+
+	if dir != "" {
+		if entries, err := os.ReadDir(dir); err == nil {
+			for _, entry := range entries {
+				if !entry.IsDir() && strings.HasSuffix(entry.Name(), ".md") {
+					seen[strings.TrimSuffix(entry.Name(), ".md")] = true
+				}
+			}
+		}
+	}
+
+	names := make([]string, 0, len(seen))
+	for name := range seen {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Render expands the named template with vars. User templates in dir
+// shadow built-in templates of the same name.
+func Render(dir, name string, vars Vars) (string, error) {
+	source, err := load(dir, name)
+	if err != nil {
+		return "", err
+	}
+
+	tmpl, err := template.New(name).Option("missingkey=error").Parse(source)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse template %q: %w", name, err)
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, vars); err != nil {
+		return "", fmt.Errorf("failed to expand template %q: %w", name, err)
+	}
+	return buf.String(), nil
+}
+
+// load returns the raw template source, preferring the user directory.
+func load(dir, name string) (string, error) {
+	if name == "" || strings.ContainsAny(name, `/\`) {
+		return "", fmt.Errorf("invalid template name %q", name)
+	}
+
+	if dir != "" {
+		data, err := os.ReadFile(filepath.Join(dir, name+".md"))
+		if err == nil {
+			return string(data), nil
+		}
+		if !errors.Is(err, fs.ErrNotExist) {
+			return "", fmt.Errorf("failed to read template %q: %w", name, err)
+		}
+	}
+
+	data, err := builtin.ReadFile("builtin/" + name + ".md")
+	if err != nil {
+		return "", fmt.Errorf("template %q not found", name)
+	}
+	return string(data), nil
+}
@@ -0,0 +1,202 @@
+package theme
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+	"strconv"
+	"strings"
+	"sync/atomic"
+)
+
+// Minimum contrast ratios of WCAG 2 for normal text.
+const (
+	AA  = 4.5
+	AAA = 7.0
+)
+
+// ansiPalette approximates the 16 terminal colors with xterm's defaults,
+// since terminals do not report their palette.
+var ansiPalette = [16]color.RGBA{
+	{0x00, 0x00, 0x00, 0xff}, {0xcd, 0x00, 0x00, 0xff}, {0x00, 0xcd, 0x00, 0xff}, {0xcd, 0xcd, 0x00, 0xff},
+	{0x00, 0x00, 0xee, 0xff}, {0xcd, 0x00, 0xcd, 0xff}, {0x00, 0xcd, 0xcd, 0xff}, {0xe5, 0xe5, 0xe5, 0xff},
+	{0x7f, 0x7f, 0x7f, 0xff}, {0xff, 0x00, 0x00, 0xff}, {0x00, 0xff, 0x00, 0xff}, {0xff, 0xff, 0x00, 0xff},
+	{0x5c, 0x5c, 0xff, 0xff}, {0xff, 0x00, 0xff, 0xff}, {0x00, 0xff, 0xff, 0xff}, {0xff, 0xff, 0xff, 0xff},
+}
+
+// ParseColor converts a theme color, an ANSI number ("0"-"255") or hex
+// ("#rgb" or "#rrggbb"), to RGB. ANSI colors 0-15 take xterm's defaults.
+func ParseColor(s string) (color.RGBA, bool) {
+	if hex, ok := strings.CutPrefix(s, "#"); ok {
+		if len(hex) == 3 {
+			hex = string([]byte{hex[0], hex[0], hex[1], hex[1], hex[2], hex[2]})
+		}
+		v, err := strconv.ParseUint(hex, 16, 32)
+		if err != nil || len(hex) != 6 {
+			return color.RGBA{}, false
+		}
+		return color.RGBA{uint8(v >> 16), uint8(v >> 8), uint8(v), 0xff}, true
+	}
+
+	n, err := strconv.Atoi(s)
+	switch {
+	case err != nil || n < 0 || n > 255:
+		return color.RGBA{}, false
+	case n < 16:
+		return ansiPalette[n], true
+	case n < 232:
+		// 6×6×6 cube
+		n -= 16
+		level := func(i int) uint8 {
+			if i == 0 {
+				return 0
+			}
+			return uint8(55 + 40*i)
+		}
+		return color.RGBA{level(n / 36), level(n / 6 % 6), level(n % 6), 0xff}, true
+	default:
+		gray := uint8(8 + 10*(n-232))
+		return color.RGBA{gray, gray, gray, 0xff}, true
+	}
+}
+
+// hexColor formats c as "#rrggbb".
+func hexColor(c color.RGBA) string {
+	return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+}
+
+// Luminance returns the WCAG relative luminance of c, from 0 for black to
+// 1 for white.
+func Luminance(c color.Color) float64 {
+	r, g, b, _ := c.RGBA()
+	linear := func(v uint32) float64 {
+		s := float64(v) / 0xffff
+		if s <= 0.03928 {
+			return s / 12.92
+		}
+		return math.Pow((s+0.055)/1.055, 2.4)
+	}
+	return 0.2126*linear(r) + 0.7152*linear(g) + 0.0722*linear(b)
+}
+
+// ContrastRatio returns the WCAG contrast ratio of two colors, from 1 for
+// the same luminance to 21 for black on white.
+func ContrastRatio(a, b color.Color) float64 {
+	la, lb := Luminance(a), Luminance(b)
+	return (max(la, lb) + 0.05) / (min(la, lb) + 0.05)
+}
+
+// Readable returns fg moved toward white or black, whichever takes less,
+// until it contrasts with bg by at least ratio. A color that already does
+// is returned as is; if neither direction gets there, the one with the
+// higher contrast wins.
+func Readable(fg, bg color.RGBA, ratio float64) color.RGBA {
+	if ContrastRatio(fg, bg) >= ratio {
+		return fg
+	}
+	white := color.RGBA{0xff, 0xff, 0xff, 0xff}
+	black := color.RGBA{0x00, 0x00, 0x00, 0xff}
+	best := fg
+	for step := 1; step <= 20; step++ {
+		t := float64(step) / 20
+		for _, toward := range []color.RGBA{white, black} {
+			c := mix(fg, toward, t)
+			if ContrastRatio(c, bg) >= ratio {
+				return c
+			}
+			if ContrastRatio(c, bg) > ContrastRatio(best, bg) {
+				best = c
+			}
+		}
+	}
+	return best
+}
+
+// mix returns the color t of the way from a to b.
+func mix(a, b color.RGBA, t float64) color.RGBA {
+	channel := func(x, y uint8) uint8 {
+		return uint8(math.Round(float64(x) + (float64(y)-float64(x))*t))
+	}
+	return color.RGBA{channel(a.R, b.R), channel(a.G, b.G), channel(a.B, b.B), 0xff}
+}
+
+// ForBackground returns t with each foreground color adjusted to contrast
+// with the background by the theme's MinContrast (AA by default): the
+// theme's own Background if it sets one, detected otherwise. Styles with
+// a background of their own are checked against it. Faint styles, dim by
+// design, and the terminal's default foreground are left alone, and so is
+// everything when there is no background to check against.
+func (t *Theme) ForBackground(detected color.Color) *Theme {
+	var bg color.RGBA
+	if c, ok := ParseColor(t.Background); ok {
+		bg = c
+	} else if detected != nil {
+		r, g, b, _ := detected.RGBA()
+		bg = color.RGBA{uint8(r >> 8), uint8(g >> 8), uint8(b >> 8), 0xff}
+	} else {
+		return t
+	}
+	ratio := t.MinContrast
+	if ratio == 0 {
+		ratio = AA
+	}
+
+	adjusted := t.Clone(t.Name)
+	for element, style := range adjusted.Styles {
+		fg, ok := ParseColor(style.Foreground)
+		if !ok || style.Faint {
+			continue
+		}
+		against := bg
+		if own, ok := ParseColor(style.Background); ok {
+			against = own
+		}
+		if readable := Readable(fg, against, ratio); readable != fg {
+			style.Foreground = hexColor(readable)
+			adjusted.Styles[element] = style
+		}
+	}
+	return adjusted
+}
+
+// resolution is the active theme adjusted for a background.
+type resolution struct {
+	from       *Theme
+	background *color.RGBA
+	theme      *Theme
+}
+
+var (
+	background atomic.Pointer[color.RGBA]
+	resolved   atomic.Pointer[resolution]
+)
+
+// SetBackground records the terminal's background color, as reported to
+// a query, for Resolved to check contrast against; nil forgets it.
+func SetBackground(c color.Color) {
+	if c == nil {
+		background.Store(nil)
+		return
+	}
+	r, g, b, _ := c.RGBA()
+	background.Store(&color.RGBA{uint8(r >> 8), uint8(g >> 8), uint8(b >> 8), 0xff})
+}
+
+// Resolved returns the active theme adjusted for contrast against the
+// terminal background, as ForBackground does. This is the theme to draw
+// with; the adjustment is redone only when the theme or background
+// changes.
+func Resolved() *Theme {
+	t := Active()
+	bg := background.Load()
+	if r := resolved.Load(); r != nil && r.from == t && r.background == bg {
+		return r.theme
+	}
+	var detected color.Color
+	if bg != nil {
+		detected = *bg
+	}
+	r := &resolution{from: t, background: bg, theme: t.ForBackground(detected)}
+	resolved.Store(r)
+	return r.theme
+}
@@ -0,0 +1,145 @@
+package theme
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/ofri/mde/pkg/plugin"
+)
+
+// file is the on-disk form of a theme.
+type file struct {
+	Name        string               `toml:"name" json:"name"`
+	Extends     string               `toml:"extends,omitempty" json:"extends,omitempty"`
+	Background  string               `toml:"background,omitempty" json:"background,omitempty"`
+	MinContrast float64              `toml:"min_contrast,omitempty" json:"min_contrast,omitempty"`
+	Styles      map[string]fileStyle `toml:"styles" json:"styles"`
+}
+
+type fileStyle struct {
+	Foreground    string `toml:"fg,omitempty" json:"fg,omitempty"`
+	Background    string `toml:"bg,omitempty" json:"bg,omitempty"`
+	Bold          bool   `toml:"bold,omitempty" json:"bold,omitempty"`
+	Italic        bool   `toml:"italic,omitempty" json:"italic,omitempty"`
+	Underline     bool   `toml:"underline,omitempty" json:"underline,omitempty"`
+	Strikethrough bool   `toml:"strikethrough,omitempty" json:"strikethrough,omitempty"`
+	Faint         bool   `toml:"faint,omitempty" json:"faint,omitempty"`
+	Reverse       bool   `toml:"reverse,omitempty" json:"reverse,omitempty"`
+}
+
+// isJSON reports whether path names a JSON theme; other files are TOML.
+func isJSON(path string) bool {
+	return strings.EqualFold(filepath.Ext(path), ".json")
+}
+
+// Encode writes every style of t to w as TOML or, with asJSON, as JSON.
+func (t *Theme) Encode(w io.Writer, asJSON bool) error {
+	f := file{Name: t.Name, Background: t.Background, MinContrast: t.MinContrast,
+		Styles: make(map[string]fileStyle, len(t.Styles))}
+	for element, s := range t.Styles {
+		f.Styles[element] = fileStyle(s)
+	}
+	if asJSON {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(f)
+	}
+	return toml.NewEncoder(w).Encode(f)
+}
+
+// Save writes t to path, as JSON for a .json file and TOML otherwise.
+// An existing file is not overwritten.
+func (t *Theme) Save(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	out, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+	if err != nil {
+		return err
+	}
+	if err := t.Encode(out, isJSON(path)); err != nil {
+		out.Close()
+		return fmt.Errorf("failed to write theme %s: %w", path, err)
+	}
+	return out.Close()
+}
+
+// Load reads the theme file at path. Its name defaults to the file name
+// without extension, and elements it leaves out take their style from the
+// theme it extends, the default theme unless it names another, as do its
+// background and minimum contrast.
+func Load(path string) (*Theme, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read theme %s: %w", path, err)
+	}
+	var f file
+	if isJSON(path) {
+		err = json.Unmarshal(data, &f)
+	} else {
+		_, err = toml.Decode(string(data), &f)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse theme %s: %w", path, err)
+	}
+
+	if f.Name == "" {
+		f.Name = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	}
+	if f.Extends == "" {
+		f.Extends = Default
+	}
+	t := &Theme{Name: f.Name, Styles: make(map[string]plugin.Style)}
+	if base, ok := Get(f.Extends); ok {
+		maps.Copy(t.Styles, base.Styles)
+		t.Background, t.MinContrast = base.Background, base.MinContrast
+	} else if f.Extends != Default {
+		return nil, fmt.Errorf("theme %s extends unknown theme %q", path, f.Extends)
+	}
+	if f.Background != "" {
+		t.Background = f.Background
+	}
+	if f.MinContrast != 0 {
+		t.MinContrast = f.MinContrast
+	}
+	for element, s := range f.Styles {
+		t.Styles[element] = plugin.Style(s)
+	}
+	return t, nil
+}
+
+// LoadDir registers every .toml and .json theme in dir, in name order so
+// a theme can extend one before it. A missing dir is not an error; a file
+// that fails to load is reported and the rest are still registered.
+func LoadDir(dir string) error {
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read themes %s: %w", dir, err)
+	}
+
+	var errs []error
+	for _, entry := range entries {
+		ext := strings.ToLower(filepath.Ext(entry.Name()))
+		if entry.IsDir() || (ext != ".toml" && ext != ".json") {
+			continue
+		}
+		t, err := Load(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		Register(t)
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,217 @@
+// Package theme maps the elements mde draws, from syntax tokens to the
+// sticky heading, to the styles they are drawn in.
+//
+// Themes live in a registry by name. The terminal renderer registers the
+// built-in "default" theme; user themes are TOML or JSON files loaded from
+// <config dir>/themes at startup, and may name only the elements they
+// change:
+//
+//	name = "paper"
+//	extends = "default"
+//
+//	[styles.heading1]
+//	fg = "4"
+//	bold = true
+//
+// Colors are ANSI numbers ("0"-"255") or hex ("#rrggbb"); an empty color
+// inherits the terminal's.
+//
+// Foregrounds are drawn adjusted, where needed, to contrast with the
+// background by WCAG AA. A theme may set the background it expects and a
+// stricter or looser ratio:
+//
+//	background = "#fdf6e3"
+//	min_contrast = 7.0
+package theme
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"sync"
+	"sync/atomic"
+
+	"github.com/ofri/mde/pkg/plugin"
+)
+
+// Names of the built-in themes. The default theme suits dark backgrounds.
+const (
+	Default      = "default"
+	Light        = "light"
+	HighContrast = "high-contrast"
+	Deuteranopia = "deuteranopia"
+)
+
+// Elements styled by themes.
+const (
+	// Syntax tokens
+	Keyword        = "keyword"
+	String         = "string"
+	Comment        = "comment"
+	Number         = "number"
+	Heading        = "heading"
+	Bold           = "bold"
+	Italic         = "italic"
+	Underline      = "underline"
+	Code           = "code"
+	CodeBlock      = "code_block"
+	Keyboard       = "keyboard"
+	Link           = "link"
+	LinkText       = "link_text"
+	LinkURL        = "link_url"
+	LinkDefinition = "link_definition"
+	Reference      = "reference"
+	Citation       = "citation"
+	GlossaryTerm   = "glossary_term"
+	WikiLink       = "wiki_link"
+	Math           = "math"
+	Emoji          = "emoji"
+	DefinitionTerm = "definition_term"
+	Definition     = "definition"
+	Strikethrough  = "strikethrough"
+	Highlight      = "highlight"
+	Insertion      = "insertion"
+	Deletion       = "deletion"
+	CriticComment  = "critic_comment"
+	HTML           = "html"
+	Image          = "image"
+	Quote          = "quote"
+	List           = "list"
+	Delimiter      = "delimiter"
+
+	// Preview headings by level
+	Heading1 = "heading1"
+	Heading2 = "heading2"
+	Heading3 = "heading3"
+	Heading4 = "heading4"
+	Heading5 = "heading5"
+	Heading6 = "heading6"
+
+	// Quote bars and list markers cycle through these by nesting depth
+	Depth1 = "depth1"
+	Depth2 = "depth2"
+	Depth3 = "depth3"
+	Depth4 = "depth4"
+	Depth5 = "depth5"
+
+	// Decorations
+	Invisible       = "invisible"
+	ScrolledPast    = "scrolled_past"
+	DiagramFrame    = "diagram_frame"
+	DiagramSource   = "diagram_source"
+	StickyHeading   = "sticky_heading"
+	ZenDim          = "zen_dim"
+	DiffRemoved     = "diff_removed"
+	DiffAdded       = "diff_added"
+	DiffRemovedText = "diff_removed_text"
+	DiffAddedText   = "diff_added_text"
+	DiagnosticError = "diagnostic_error"
+	DiagnosticWarn  = "diagnostic_warning"
+	DiagnosticInfo  = "diagnostic_info"
+	ReviewComment   = "review_comment"
+	RemoteCursor    = "remote_cursor"
+	LineNumber      = "line_number"
+	Cursor          = "cursor"
+	Selection       = "selection"
+)
+
+// HeadingLevels are the preview heading elements, indexed by level - 1.
+var HeadingLevels = []string{Heading1, Heading2, Heading3, Heading4, Heading5, Heading6}
+
+// Depths are the nesting elements, indexed by depth - 1.
+var Depths = []string{Depth1, Depth2, Depth3, Depth4, Depth5}
+
+// Theme is a named set of element styles. Registered themes must not be
+// modified; Clone one to derive another.
+type Theme struct {
+	Name   string
+	Styles map[string]plugin.Style
+
+	// Background is the color the theme is drawn on, overriding the one
+	// the terminal reports; empty uses the terminal's.
+	Background string
+
+	// MinContrast is the WCAG contrast ratio foregrounds must reach
+	// against the background; 0 means AA and 1 turns adjustment off.
+	MinContrast float64
+}
+
+// Style returns the style of element, or no style if the theme leaves it
+// out.
+func (t *Theme) Style(element string) plugin.Style {
+	return t.Styles[element]
+}
+
+// Elements returns the names of the styled elements, sorted.
+func (t *Theme) Elements() []string {
+	names := make([]string, 0, len(t.Styles))
+	for name := range t.Styles {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// Clone returns a copy of the theme named name.
+func (t *Theme) Clone(name string) *Theme {
+	return &Theme{Name: name, Styles: maps.Clone(t.Styles), Background: t.Background, MinContrast: t.MinContrast}
+}
+
+var (
+	mu     sync.RWMutex
+	themes = make(map[string]*Theme)
+	active atomic.Pointer[Theme]
+)
+
+// Register adds t to the registry, replacing any theme of the same name.
+// If that theme was active, t becomes active in its place.
+func Register(t *Theme) {
+	mu.Lock()
+	defer mu.Unlock()
+	themes[t.Name] = t
+	if current := active.Load(); current != nil && current.Name == t.Name {
+		active.Store(t)
+	}
+}
+
+// Get returns the registered theme named name.
+func Get(name string) (*Theme, bool) {
+	mu.RLock()
+	defer mu.RUnlock()
+	t, ok := themes[name]
+	return t, ok
+}
+
+// Names returns the names of the registered themes, sorted.
+func Names() []string {
+	mu.RLock()
+	defer mu.RUnlock()
+	names := make([]string, 0, len(themes))
+	for name := range themes {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// SetActive makes the theme named name the one Active returns.
+func SetActive(name string) error {
+	t, ok := Get(name)
+	if !ok {
+		return fmt.Errorf("unknown theme %q", name)
+	}
+	active.Store(t)
+	return nil
+}
+
+// Active returns the theme in use: the one last passed to SetActive, or
+// the default theme.
+func Active() *Theme {
+	if t := active.Load(); t != nil {
+		return t
+	}
+	if t, ok := Get(Default); ok {
+		return t
+	}
+	return &Theme{Name: Default}
+}
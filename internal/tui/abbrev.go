@@ -0,0 +1,86 @@
+package tui
+
+import (
+	"github.com/ofri/mde/internal/abbrev"
+	"github.com/ofri/mde/pkg/ast"
+)
+
+// abbrevState tracks abbreviation expansion while typing.
+type abbrevState struct {
+	// expander is built from the config on first use
+	expander *abbrev.Expander
+
+	// last is the expansion just made, which Backspace can undo
+	last *abbrevExpansion
+}
+
+// abbrevExpansion records an expansion so Backspace can restore the
+// literal text.
+type abbrevExpansion struct {
+	start   ast.BufferPos
+	end     ast.BufferPos
+	literal string
+}
+
+// abbreviations returns the expander for the configured substitutions.
+func (m *Model) abbreviations() *abbrev.Expander {
+	if m.abbrev.expander == nil {
+		m.abbrev.expander = abbrev.New(m.config.Abbreviations, m.config.AutoReplace)
+	}
+	return m.abbrev.expander
+}
+
+// expandAbbreviation replaces an abbreviation completed by the character
+// just typed. Nothing is expanded inside fenced code blocks.
+func (m *Model) expandAbbreviation() {
+	pos := m.editor.GetCursor().GetBufferPos()
+	doc := m.editor.GetDocument()
+	match, ok := m.abbreviations().Find(doc.GetLine(pos.Line), pos.Col)
+	if ok {
+		m.replaceAbbreviation(pos, match)
+	}
+}
+
+// expandWordAbbreviation expands a word abbreviation ending at the cursor,
+// before a line break is inserted after it.
+func (m *Model) expandWordAbbreviation() {
+	pos := m.editor.GetCursor().GetBufferPos()
+	doc := m.editor.GetDocument()
+	match, ok := m.abbreviations().FindWord(doc.GetLine(pos.Line), pos.Col)
+	if ok {
+		m.replaceAbbreviation(pos, match)
+	}
+}
+
+func (m *Model) replaceAbbreviation(pos ast.BufferPos, match abbrev.Match) {
+	doc := m.editor.GetDocument()
+	if _, inFence := doc.FencedBlockAt(pos.Line); inFence {
+		return
+	}
+
+	start := ast.BufferPos{Line: pos.Line, Col: match.Start}
+	literal := string([]rune(doc.GetLine(pos.Line))[match.Start:pos.Col])
+	trailing := string([]rune(literal)[match.End-match.Start:])
+
+	m.editor.SelectWith(func() { m.editor.GetCursor().SetBufferPos(start) })
+	m.editor.InsertText(match.Expansion + trailing)
+
+	m.abbrev.last = &abbrevExpansion{
+		start:   start,
+		end:     m.editor.GetCursor().GetBufferPos(),
+		literal: literal,
+	}
+}
+
+// undoAbbreviation restores the literal text of an expansion made by the
+// previous key. Returns false if there is nothing to undo.
+func (m *Model) undoAbbreviation(last *abbrevExpansion) bool {
+	cursor := m.editor.GetCursor()
+	if last == nil || cursor.HasSelection() || cursor.GetBufferPos() != last.end {
+		return false
+	}
+
+	m.editor.SelectWith(func() { cursor.SetBufferPos(last.start) })
+	m.editor.InsertText(last.literal)
+	return true
+}
@@ -0,0 +1,146 @@
+package tui
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/ofri/mde/internal/assets"
+)
+
+// unusedAssetsDir is where clean-assets moves unused assets, inside the
+// assets directory; being hidden, it is left out of later scans.
+const unusedAssetsDir = ".unused"
+
+// assetsScannedMsg reports a scan for unused assets.
+type assetsScannedMsg struct {
+	report assets.Report
+	err    error
+}
+
+func init() {
+	registerCommand(Command{
+		Name:        "clean-assets",
+		Description: "List the files in assets_dir no note in the workspace links to, and delete or move them",
+		Run:         (*Model).scanAssets,
+		Markdown:    true,
+	})
+}
+
+// scanAssets looks for unused assets in the background, counting the
+// buffer's unsaved links as used.
+func (m *Model) scanAssets() tea.Cmd {
+	doc := m.editor.GetDocument()
+	filename := doc.GetFilename()
+	if filename == "" || remoteFile(filename) {
+		m.showMessage("Save the document to a file before cleaning its assets")
+		return nil
+	}
+	root := m.workspaceRoot()
+	dir := m.assetsDir()
+	buffers := map[string]string{}
+	if abs, err := filepath.Abs(filename); err == nil {
+		buffers[abs] = doc.GetText()
+	}
+	m.showMessage("Looking for unused assets...")
+	return func() tea.Msg {
+		report, err := assets.Scan(root, dir, buffers)
+		return assetsScannedMsg{report: report, err: err}
+	}
+}
+
+func (m *Model) handleAssetsScanned(msg assetsScannedMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case msg.err != nil:
+		m.showMessage("Scanning assets failed: " + msg.err.Error())
+	case len(msg.report.Unused) == 0:
+		m.showMessage(fmt.Sprintf("No unused assets among the %d in %s", msg.report.Assets, m.relativePath(msg.report.Dir)))
+	case m.mode != ModeNormal:
+		// Another prompt opened meanwhile; leave it be
+	default:
+		m.unusedAssets = msg.report
+		m.mode = ModeAssetsPrompt
+	}
+	return m, nil
+}
+
+// handleAssetsPrompt answers the clean-assets prompt: d deletes the unused
+// assets, m moves them aside, n keeps them.
+func (m *Model) handleAssetsPrompt(key string) {
+	report := m.unusedAssets
+	switch key {
+	case "d", "D":
+		if err := assets.Delete(report.Unused); err != nil {
+			m.showMessage("Deleting assets failed: " + err.Error())
+		} else {
+			m.showMessage(fmt.Sprintf("Deleted %s (%s)", plural(len(report.Unused), "unused asset"), assets.FormatSize(report.Size)))
+		}
+	case "m", "M":
+		to := filepath.Join(report.Dir, unusedAssetsDir)
+		if moved, err := assets.Move(report.Unused, report.Dir, to); err != nil {
+			m.showMessage(fmt.Sprintf("Moving assets failed after %d: %s", len(moved), err.Error()))
+		} else {
+			m.showMessage(fmt.Sprintf("Moved %s to %s", plural(len(moved), "unused asset"), m.relativePath(to)))
+		}
+	case "n", "N":
+		m.showMessage("Unused assets kept")
+	default:
+		return
+	}
+	m.unusedAssets = assets.Report{}
+	m.mode = ModeNormal
+}
+
+// assetsPrompt is the dry-run report the prompt shows: the unused assets,
+// as many as fit, and what can be done with them.
+func (m *Model) assetsPrompt() string {
+	report := m.unusedAssets
+	actions := " | d: Delete  m: Move to " + unusedAssetsDir + "  n: Keep"
+	head := fmt.Sprintf("%s unused in %s (%s): ", plural(len(report.Unused), "asset"),
+		m.relativePath(report.Dir), assets.FormatSize(report.Size))
+
+	names := make([]string, len(report.Unused))
+	for i, path := range report.Unused {
+		if rel, err := filepath.Rel(report.Dir, path); err == nil {
+			path = filepath.ToSlash(rel)
+		}
+		names[i] = path
+	}
+	room := m.width - len(head) - len(actions)
+	shown := len(names)
+	for shown > 1 && len(listNames(names, shown)) > room {
+		shown--
+	}
+	return head + listNames(names, shown) + actions
+}
+
+// listNames joins the first shown names, noting how many more there are.
+func listNames(names []string, shown int) string {
+	list := strings.Join(names[:shown], ", ")
+	if shown < len(names) {
+		list += fmt.Sprintf(" +%d more", len(names)-shown)
+	}
+	return list
+}
+
+// relativePath shortens path to be relative to the workspace when inside
+// it.
+func (m *Model) relativePath(path string) string {
+	root, err := filepath.Abs(m.workspaceRoot())
+	if err != nil {
+		return path
+	}
+	if rel, err := filepath.Rel(root, path); err == nil && !strings.HasPrefix(rel, "..") {
+		return filepath.ToSlash(rel)
+	}
+	return path
+}
+
+// plural counts n of noun, as "1 asset" or "3 assets".
+func plural(n int, noun string) string {
+	if n == 1 {
+		return "1 " + noun
+	}
+	return fmt.Sprintf("%d %ss", n, noun)
+}
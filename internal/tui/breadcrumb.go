@@ -0,0 +1,42 @@
+package tui
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/x/ansi"
+)
+
+// breadcrumbSeparator joins the headings of the breadcrumb
+const breadcrumbSeparator = " ▸ "
+
+// screenReaderSeparator replaces breadcrumbSeparator in screen reader
+// mode, where the arrow would be read out by its Unicode name
+const screenReaderSeparator = " > "
+
+// breadcrumb returns the headings enclosing the cursor, outermost first,
+// as "Chapter 2 ▸ Setup ▸ Install". When they do not fit in width cells
+// the outer ones give way to "…", keeping the cursor's own section.
+func (m *Model) breadcrumb(width int) string {
+	if !m.config.Breadcrumb || width <= 0 || m.plainText() {
+		return ""
+	}
+	path := m.editor.GetDocument().HeadingPath(m.editor.GetCursor().GetBufferPos().Line)
+	texts := make([]string, len(path))
+	for i, heading := range path {
+		texts[i] = heading.Text
+	}
+
+	separator := breadcrumbSeparator
+	if m.config.ScreenReader {
+		separator = screenReaderSeparator
+	}
+	crumb := strings.Join(texts, separator)
+	for len(texts) > 1 && ansi.StringWidth(crumb) > width {
+		texts = texts[1:]
+		crumb = "…" + separator + strings.Join(texts, separator)
+	}
+	if ansi.StringWidth(crumb) > width {
+		crumb = ansi.Truncate(texts[0], width, "…") // The cursor's section alone
+	}
+	return crumb
+}
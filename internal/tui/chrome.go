@@ -0,0 +1,69 @@
+package tui
+
+import (
+	tea "github.com/charmbracelet/bubbletea/v2"
+)
+
+func init() {
+	registerCommand(Command{
+		Name:        "toggle-help-bar",
+		Description: "Show or hide the key help bar",
+		Run:         (*Model).toggleHelpBar,
+	})
+	registerCommand(Command{
+		Name:        "toggle-status-bar",
+		Description: "Show or hide the status bar",
+		Run:         (*Model).toggleStatusBar,
+	})
+}
+
+func (m *Model) toggleHelpBar() tea.Cmd {
+	m.config.ShowHelpBar = !m.config.ShowHelpBar
+	m.resizeEditor()
+	return nil
+}
+
+func (m *Model) toggleStatusBar() tea.Cmd {
+	m.config.ShowStatusBar = !m.config.ShowStatusBar
+	m.resizeEditor()
+	return nil
+}
+
+// statusBarVisible reports whether the status bar is drawn.
+func (m *Model) statusBarVisible() bool {
+	return m.config.ShowStatusBar
+}
+
+// helpBarVisible reports whether the help bar is drawn. Prompts and
+// completion hints live in the help bar, so it appears while one is
+// active even when hidden by configuration or zen mode.
+func (m *Model) helpBarVisible() bool {
+	if m.mode != ModeNormal || m.popup != nil {
+		return true
+	}
+	return m.config.ShowHelpBar && !m.zen.enabled
+}
+
+// chromeHeight is the number of rows taken by the visible bars.
+func (m *Model) chromeHeight() int {
+	height := 0
+	if m.statusBarVisible() {
+		height++
+	}
+	if m.helpBarVisible() {
+		height++
+	}
+	return height
+}
+
+// syncLayout resizes the editor when the visible chrome changed, e.g. a
+// prompt opened the hidden help bar.
+func (m *Model) syncLayout() {
+	if m.editor == nil || m.height == 0 {
+		return
+	}
+	viewport := m.editor.GetViewport()
+	if viewport.GetHeight() != m.GetContentHeight() || viewport.GetWidth() != m.textWidth() {
+		m.resizeEditor()
+	}
+}
@@ -0,0 +1,206 @@
+package tui
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/ofri/mde/internal/cite"
+	"github.com/ofri/mde/pkg/ast"
+)
+
+// maxCitationLabel bounds the width of a citation popup entry.
+const maxCitationLabel = 60
+
+// bibliographyState caches the bibliography last read, until its file
+// changes.
+type bibliographyState struct {
+	path    string
+	modTime time.Time
+	entries []cite.Entry
+	err     error
+}
+
+func init() {
+	registerCommand(Command{
+		Name:        "insert-citation",
+		Description: "Pick a reference from the bibliography and cite it at the cursor",
+		Run:         (*Model).insertCitation,
+		Markdown:    true,
+	})
+}
+
+// bibliographyPath is where the bibliography setting points for the open
+// document, or "" when it is unset.
+func (m *Model) bibliographyPath() string {
+	path := m.config.Bibliography
+	if path == "" || filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(m.workspaceRoot(), path)
+}
+
+// bibliography returns the entries of the configured bibliography,
+// reading it again when the file changed.
+func (m *Model) bibliography() ([]cite.Entry, error) {
+	state := &m.bib
+	path := m.bibliographyPath()
+	if path == "" {
+		*state = bibliographyState{}
+		return nil, nil
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		*state = bibliographyState{path: path, err: err}
+		return nil, err
+	}
+	if state.path == path && state.modTime.Equal(info.ModTime()) {
+		return state.entries, state.err
+	}
+	entries, err := cite.Load(path)
+	*state = bibliographyState{path: path, modTime: info.ModTime(), entries: entries, err: err}
+	return entries, err
+}
+
+// citationEntry returns the bibliography entry cited as key.
+func (m *Model) citationEntry(key string) (cite.Entry, bool) {
+	entries, _ := m.bibliography()
+	for _, entry := range entries {
+		if entry.Key == key {
+			return entry, true
+		}
+	}
+	return cite.Entry{}, false
+}
+
+// citationPopup lists the references matching a key typed after "@" in
+// an open citation: keys starting with it first, then those whose key,
+// authors or title contain it. Returns nil outside a citation.
+func (m *Model) citationPopup() *completionPopup {
+	pos := m.editor.GetCursor().GetBufferPos()
+	partial, ok := cite.PartialAt(m.editor.GetDocument().GetLine(pos.Line), pos.Col)
+	if !ok {
+		return nil
+	}
+	entries, _ := m.bibliography()
+
+	typed := strings.ToLower(partial.Key)
+	var prefixed, containing []popupItem
+	for _, entry := range entries {
+		insert := entry.Key
+		if !partial.Closed {
+			insert += "]"
+		}
+		item := popupItem{
+			label:  ansi.Truncate(entry.Key+"  "+entry.Summary(), maxCitationLabel, "…"),
+			insert: insert,
+		}
+		key := strings.ToLower(entry.Key)
+		switch {
+		case strings.HasPrefix(key, typed):
+			prefixed = append(prefixed, item)
+		case strings.Contains(key, typed) || strings.Contains(strings.ToLower(entry.Summary()), typed):
+			containing = append(containing, item)
+		}
+	}
+	return &completionPopup{
+		items:   append(prefixed, containing...),
+		replace: len([]rune(partial.Key)),
+		refresh: (*Model).citationPopup,
+	}
+}
+
+// completeCitation completes a partially typed @key in a citation at the
+// cursor, inserting a unique match directly and opening the popup
+// otherwise. Returns false if the cursor is not after one.
+func (m *Model) completeCitation() bool {
+	popup := m.citationPopup()
+	if popup == nil {
+		return false
+	}
+	switch {
+	case m.config.Bibliography == "":
+		m.showMessage("No bibliography (set bibliography)")
+	case len(popup.items) == 0:
+		if _, err := m.bibliography(); err != nil {
+			m.showMessage("Bibliography: " + err.Error())
+		} else {
+			m.showMessage("No references match")
+		}
+	default:
+		m.popup = popup
+		if len(popup.items) == 1 {
+			m.acceptPopup()
+		}
+	}
+	return true
+}
+
+// insertCitation starts a citation at the cursor, or adds a key to the
+// one it is in, and opens the reference picker, which narrows as the key
+// is typed.
+func (m *Model) insertCitation() tea.Cmd {
+	if m.config.Bibliography == "" {
+		m.showMessage("No bibliography (set bibliography)")
+		return nil
+	}
+	entries, err := m.bibliography()
+	if err != nil {
+		m.showMessage("Bibliography: " + err.Error())
+		return nil
+	}
+	if len(entries) == 0 {
+		m.showMessage("The bibliography has no references")
+		return nil
+	}
+
+	m.editor.GetCursor().ClearSelection()
+	pos := m.editor.GetCursor().GetBufferPos()
+	line := m.editor.GetDocument().GetLine(pos.Line)
+	end, inside := citationEnd(line, pos.Col)
+	switch partial, ok := cite.PartialAt(line, pos.Col); {
+	case ok && partial.Key == "":
+		// Right after an "@" already
+	case inside:
+		m.editor.GetCursor().SetBufferPos(ast.BufferPos{Line: pos.Line, Col: end - 1})
+		m.editor.InsertText("; @")
+	default:
+		m.editor.InsertText("[@")
+	}
+	m.popup = m.citationPopup()
+	return nil
+}
+
+// citationEnd returns the rune column after the citation whose brackets
+// rune column col is inside, where another key can be added.
+func citationEnd(line string, col int) (int, bool) {
+	for _, citation := range cite.FindCitations(line) {
+		start, end := ast.RuneOffset(line, citation.Start), ast.RuneOffset(line, citation.End)
+		if col > start && col < end {
+			return end, true
+		}
+	}
+	return 0, false
+}
+
+// citationHover describes the reference cited by the key under rune
+// column col of line.
+func (m *Model) citationHover(line string, col int) string {
+	key, ok := cite.KeyAt(line, col)
+	if !ok {
+		return ""
+	}
+	if entry, ok := m.citationEntry(key.Name); ok {
+		return "@" + entry.Key + ": " + entry.Summary()
+	}
+	if _, err := m.bibliography(); err != nil {
+		return "Bibliography: " + err.Error()
+	}
+	if m.config.Bibliography == "" {
+		return "@" + key.Name + " (no bibliography set)"
+	}
+	return "Unknown citation @" + key.Name
+}
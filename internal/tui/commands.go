@@ -0,0 +1,100 @@
+package tui
+
+import (
+	"sort"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea/v2"
+)
+
+// Command is a named editor action that can be run from the command
+// prompt (Alt+X) or bound to a key in handleKeyInput.
+type Command struct {
+	// Name is the identifier typed at the command prompt (e.g. "copy-as-html")
+	Name string
+
+	// Description is a one-line summary shown when completing names
+	Description string
+
+	// Run executes the command against the model
+	Run func(m *Model) tea.Cmd
+
+	// Markdown marks commands that make no sense in plain-text files
+	Markdown bool
+}
+
+// commands holds every registered command keyed by name.
+// Feature files register their commands from init().
+var commands = map[string]Command{}
+
+// registerCommand adds a command to the global command table.
+// Registering the same name twice is a programming error.
+func registerCommand(cmd Command) {
+	if _, exists := commands[cmd.Name]; exists {
+		panic("FATAL: command '" + cmd.Name + "' registered twice")
+	}
+	commands[cmd.Name] = cmd
+}
+
+// CommandNames returns all registered command names in sorted order.
+func CommandNames() []string {
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// RunCommand executes a registered command by name.
+// Returns false if no such command exists.
+func (m *Model) RunCommand(name string) (tea.Cmd, bool) {
+	cmd, ok := commands[strings.TrimSpace(name)]
+	if !ok {
+		return nil, false
+	}
+	if cmd.Markdown && !m.requireMarkdown(cmd.Name) {
+		return nil, true
+	}
+	return cmd.Run(m), true
+}
+
+// completePrefix extends a partial name to the longest prefix shared by
+// all matching candidates.
+func completePrefix(prefix string, candidates []string) string {
+	var matches []string
+	for _, name := range candidates {
+		if strings.HasPrefix(name, prefix) {
+			matches = append(matches, name)
+		}
+	}
+
+	if len(matches) == 0 {
+		return prefix
+	}
+
+	common := matches[0]
+	for _, name := range matches[1:] {
+		for !strings.HasPrefix(name, common) {
+			common = common[:len(common)-1]
+		}
+	}
+	return common
+}
+
+func (m *Model) handleCommand() (tea.Model, tea.Cmd) {
+	name := strings.TrimSpace(m.input)
+	m.mode = ModeNormal
+	m.input = ""
+
+	if name == "" {
+		return m, nil
+	}
+
+	cmd, ok := m.RunCommand(name)
+	if !ok {
+		m.showMessage("Unknown command: " + name)
+		return m, nil
+	}
+	return m, cmd
+}
@@ -0,0 +1,438 @@
+package tui
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/ofri/mde/internal/annotations"
+	"github.com/ofri/mde/internal/plugins/renderers"
+	"github.com/ofri/mde/internal/theme"
+	"github.com/ofri/mde/pkg/ast"
+	"github.com/ofri/mde/pkg/plugin"
+)
+
+// maxCommentWidth bounds the width of the comments popup.
+const maxCommentWidth = 60
+
+// commentMarker marks the lines with comments in the gutter.
+const commentMarker = "●"
+
+// reviewState holds the review comments on the open document, read from
+// its sidecar file, and where they were found in it at revision.
+type reviewState struct {
+	doc      *ast.Document
+	path     string // The document's filename when the comments were read
+	comments []annotations.Comment
+	err      error
+
+	revision int
+	ranges   []ast.Range
+	found    []bool // Whether each comment's quote is still in the text
+	moved    bool   // Some comment was found elsewhere than saved
+
+	open    bool      // The comments on the cursor line are shown
+	pending ast.Range // What the comment prompt comments on
+}
+
+func init() {
+	registerCommand(Command{
+		Name:        "add-comment",
+		Description: "Comment on the selection or the word at the cursor, kept beside the document",
+		Run:         (*Model).promptComment,
+	})
+	registerCommand(Command{
+		Name:        "show-comments",
+		Description: "Toggle showing the comments on the cursor line in a popup",
+		Run:         (*Model).toggleComments,
+	})
+	registerCommand(Command{
+		Name:        "next-comment",
+		Description: "Jump to the next comment and show it",
+		Run:         func(m *Model) tea.Cmd { m.jumpToComment(1); return nil },
+	})
+	registerCommand(Command{
+		Name:        "previous-comment",
+		Description: "Jump to the previous comment and show it",
+		Run:         func(m *Model) tea.Cmd { m.jumpToComment(-1); return nil },
+	})
+	registerCommand(Command{
+		Name:        "delete-comment",
+		Description: "Delete the comment at the cursor",
+		Run:         (*Model).deleteComment,
+	})
+}
+
+// reviewComments returns the comments on the open document, reading them
+// when another document is opened and finding them again after edits.
+// Only local, unencrypted files have comments: a sidecar file would leak
+// an encrypted note's text.
+func (m *Model) reviewComments() *reviewState {
+	state := &m.review
+	doc := m.editor.GetDocument()
+	filename := doc.GetFilename()
+	if doc != state.doc || filename != state.path {
+		*state = reviewState{doc: doc, path: filename, revision: -1, open: state.open}
+		if m.commentable() == "" {
+			state.comments, state.err = annotations.Load(filename)
+		}
+	}
+	if state.revision != doc.Revision() {
+		state.ranges = make([]ast.Range, len(state.comments))
+		state.found = make([]bool, len(state.comments))
+		for i := range state.comments {
+			c := &state.comments[i]
+			state.ranges[i], state.found[i] = c.Locate(doc)
+			if state.found[i] && (state.ranges[i].Start.Line+1 != c.Line || state.ranges[i].Start.Col+1 != c.Col) {
+				c.MoveTo(state.ranges[i])
+				state.moved = true
+			}
+		}
+		state.revision = doc.Revision()
+	}
+	return state
+}
+
+// commentable returns why the open document cannot be commented on, or
+// "" when it can.
+func (m *Model) commentable() string {
+	filename := m.editor.GetDocument().GetFilename()
+	switch {
+	case filename == "":
+		return "Save the document before commenting on it"
+	case remoteFile(filename) || m.readOnly:
+		return "Only local files can be commented on"
+	case m.encrypted(filename):
+		return "Encrypted notes cannot be commented on"
+	}
+	return ""
+}
+
+// saveComments writes the comments to the sidecar file, reporting
+// failure.
+func (m *Model) saveComments() bool {
+	state := m.reviewComments()
+	if err := annotations.Save(state.path, state.comments); err != nil {
+		m.showMessage("Saving comments: " + err.Error())
+		return false
+	}
+	state.moved = false
+	return true
+}
+
+// saveMovedComments updates the sidecar file once the document is saved,
+// when comments were found away from where they were saved.
+func (m *Model) saveMovedComments() {
+	if state := m.reviewComments(); state.moved && state.err == nil {
+		m.saveComments()
+	}
+}
+
+// promptComment asks for a comment on the selection, or else the word at
+// the cursor, or else the cursor line.
+func (m *Model) promptComment() tea.Cmd {
+	if reason := m.commentable(); reason != "" {
+		m.showMessage(reason)
+		return nil
+	}
+	if err := m.reviewComments().err; err != nil {
+		m.showMessage("Comments: " + err.Error())
+		return nil
+	}
+	doc := m.editor.GetDocument()
+	pos := m.editor.GetCursor().GetBufferPos()
+	target, ok := doc.WordAt(pos)
+	if selection := m.editor.GetCursor().GetSelection(); selection != nil {
+		target = orderedRange(selection.Start, selection.End)
+	} else if !ok {
+		target = ast.Range{Start: ast.BufferPos{Line: pos.Line}, End: ast.BufferPos{Line: pos.Line, Col: doc.GetLineLength(pos.Line)}}
+	}
+	m.reviewComments().pending = target
+	m.mode = ModeComment
+	m.input = ""
+	return nil
+}
+
+// handleComment adds the comment typed at the prompt and saves it.
+func (m *Model) handleComment() (tea.Model, tea.Cmd) {
+	text := strings.TrimSpace(m.input)
+	m.mode = ModeNormal
+	m.input = ""
+	if text == "" {
+		m.showMessage("Comment cancelled")
+		return m, nil
+	}
+	state := m.reviewComments()
+	comment := annotations.New(m.editor.GetDocument(), state.pending, text, m.author(), time.Now())
+	state.comments = append(state.comments, comment)
+	state.revision = -1
+	if m.saveComments() {
+		m.editor.GetCursor().ClearSelection()
+		m.showMessage(fmt.Sprintf("Comment added (%s on this document)", plural(len(state.comments), "comment")))
+	}
+	return m, nil
+}
+
+// author names the user to others: the configured author, or the login
+// name.
+func (m *Model) author() string {
+	if m.config.Author != "" {
+		return m.config.Author
+	}
+	return os.Getenv("USER")
+}
+
+// toggleComments shows or hides the popup of comments on the cursor line.
+func (m *Model) toggleComments() tea.Cmd {
+	state := m.reviewComments()
+	state.open = !state.open
+	switch {
+	case !state.open:
+		m.showMessage("Comments hidden")
+	case state.err != nil:
+		m.showMessage("Comments: " + state.err.Error())
+	case len(state.comments) == 0:
+		m.showMessage("No comments (add-comment to add one)")
+	default:
+		m.showMessage(fmt.Sprintf("Showing comments (%s)", plural(len(state.comments), "comment")))
+	}
+	return nil
+}
+
+// commentsAt returns the indices of the comments on line, in order.
+func (m *Model) commentsAt(line int) []int {
+	state := m.reviewComments()
+	var found []int
+	for i, r := range state.ranges {
+		if line >= r.Start.Line && line <= r.End.Line {
+			found = append(found, i)
+		}
+	}
+	sort.SliceStable(found, func(a, b int) bool { return state.ranges[found[a]].Start.Before(state.ranges[found[b]].Start) })
+	return found
+}
+
+// commentAtCursor returns the index of the comment under the cursor, or
+// else the first on its line.
+func (m *Model) commentAtCursor() (int, bool) {
+	pos := m.editor.GetCursor().GetBufferPos()
+	found := m.commentsAt(pos.Line)
+	if len(found) == 0 {
+		return 0, false
+	}
+	state := m.reviewComments()
+	for _, i := range found {
+		r := state.ranges[i]
+		if !pos.Before(r.Start) && !r.End.Before(pos) {
+			return i, true
+		}
+	}
+	return found[0], true
+}
+
+// jumpToComment moves the cursor to the start of the next (direction 1)
+// or previous (-1) comment, wrapping around, and opens the popup.
+func (m *Model) jumpToComment(direction int) {
+	state := m.reviewComments()
+	if len(state.comments) == 0 {
+		m.showMessage("No comments")
+		return
+	}
+	starts := make([]ast.BufferPos, len(state.ranges))
+	for i, r := range state.ranges {
+		starts[i] = r.Start
+	}
+	sort.Slice(starts, func(i, j int) bool { return starts[i].Before(starts[j]) })
+
+	cursor := m.editor.GetCursor()
+	pos := cursor.GetBufferPos()
+	target := starts[0]
+	if direction < 0 {
+		target = starts[len(starts)-1]
+		for i := len(starts) - 1; i >= 0; i-- {
+			if starts[i].Before(pos) {
+				target = starts[i]
+				break
+			}
+		}
+	} else {
+		for _, start := range starts {
+			if pos.Before(start) {
+				target = start
+				break
+			}
+		}
+	}
+	cursor.ClearSelection()
+	cursor.SetBufferPos(target)
+	state.open = true
+}
+
+// deleteComment removes the comment at the cursor.
+func (m *Model) deleteComment() tea.Cmd {
+	i, ok := m.commentAtCursor()
+	if !ok {
+		m.showMessage("No comment here")
+		return nil
+	}
+	state := m.reviewComments()
+	state.comments = append(state.comments[:i:i], state.comments[i+1:]...)
+	state.revision = -1
+	if m.saveComments() {
+		m.showMessage(fmt.Sprintf("Comment deleted (%s left)", plural(len(state.comments), "comment")))
+	}
+	return nil
+}
+
+// markComments underlines the commented text and marks its lines in the
+// gutter.
+func (m *Model) markComments(lines []plugin.RenderedLine) {
+	state := m.reviewComments()
+	if len(state.comments) == 0 {
+		return
+	}
+	doc := m.editor.GetDocument()
+	viewport := m.editor.GetViewport()
+	top, left := viewport.GetTopLine(), viewport.GetLeftColumn()
+	prefix := 0
+	if m.editor.ShowLineNumbers() {
+		prefix = viewport.GetLineNumberWidth()
+	}
+	style := theme.Resolved().Style(theme.ReviewComment)
+	gutter := style
+	gutter.Underline = false
+
+	for c, r := range state.ranges {
+		first := max(r.Start.Line, top)
+		last := min(r.End.Line, top+len(lines)-1, doc.LineCount()-1)
+		for line := first; line <= last; line++ {
+			i := line - top
+			if prefix > 0 && !m.config.ScreenReader && strings.HasPrefix(lines[i].Content, " ") {
+				lines[i].Content = commentMarker + lines[i].Content[1:]
+				lines[i].Styles = renderers.Overlay(lines[i].Styles, 0, 1, gutter)
+			}
+			if !state.found[c] {
+				continue // Only the gutter shows where the text was
+			}
+			text := doc.GetLine(line)
+			from, to := 0, utf8.RuneCountInString(text)
+			if line == r.Start.Line {
+				from = r.Start.Col
+			}
+			if line == r.End.Line {
+				to = r.End.Col
+			}
+			length := utf8.RuneCountInString(lines[i].Content)
+			from = max(viewport.DisplayColumn(text, from)-left, 0) + prefix
+			to = min(max(viewport.DisplayColumn(text, to)-left, 0)+prefix, length)
+			lines[i].Styles = renderers.Overlay(lines[i].Styles, from, to, style)
+		}
+	}
+}
+
+// overlayComments draws the comments on the cursor line under it while
+// the popup is open and no completion popup is.
+func (m *Model) overlayComments(content string) string {
+	if !m.review.open || m.popup != nil {
+		return content
+	}
+	found := m.commentsAt(m.editor.GetCursor().GetBufferPos().Line)
+	if len(found) == 0 {
+		return content
+	}
+	screen, err := m.editor.GetCursor().GetScreenPos()
+	if err != nil {
+		return content
+	}
+	col := 0
+	if m.editor.ShowLineNumbers() {
+		col = m.editor.GetViewport().GetLineNumberWidth()
+	}
+	return overlayBox(content, m.renderComments(found, min(maxCommentWidth, m.textWidth()-col-2)), screen.Row, col)
+}
+
+// renderComments returns the popup lines for the comments at indices:
+// each one's author and date, then its text wrapped to width.
+func (m *Model) renderComments(indices []int, width int) []string {
+	state := m.reviewComments()
+	width = max(width, 20)
+	var rows []string
+	var headers []bool
+	for n, i := range indices {
+		c := state.comments[i]
+		if n > 0 {
+			rows, headers = append(rows, ""), append(headers, false)
+		}
+		header := c.Author
+		if !c.Created.IsZero() {
+			header = strings.TrimPrefix(header+", "+c.Created.Local().Format("2006-01-02 15:04"), ", ")
+		}
+		if !state.found[i] {
+			header = strings.TrimPrefix(header+" (on text since changed: \""+c.Quote+"\")", " ")
+		}
+		for _, line := range strings.Split(ansi.Wordwrap(header, width, ""), "\n") {
+			rows, headers = append(rows, ansi.Truncate(line, width, "…")), append(headers, true)
+		}
+		for _, line := range strings.Split(ansi.Wordwrap(c.Text, width, ""), "\n") {
+			rows, headers = append(rows, ansi.Truncate(line, width, "…")), append(headers, false)
+		}
+	}
+
+	boxWidth := 0
+	for _, row := range rows {
+		boxWidth = max(boxWidth, ansi.StringWidth(row))
+	}
+	normal := lipgloss.NewStyle().Reverse(true)
+	bold := normal.Bold(true)
+	box := make([]string, len(rows))
+	for i, row := range rows {
+		text := " " + row + strings.Repeat(" ", boxWidth-ansi.StringWidth(row)) + " "
+		if headers[i] {
+			box[i] = bold.Render(text)
+		} else {
+			box[i] = normal.Render(text)
+		}
+	}
+	return box
+}
+
+// commentStatus returns the comment at the cursor for the status line
+// while the popup is closed, or "".
+func (m *Model) commentStatus() string {
+	if m.review.open {
+		return ""
+	}
+	i, ok := m.commentAtCursor()
+	if !ok {
+		return ""
+	}
+	c := m.reviewComments().comments[i]
+	status := "Comment"
+	if c.Author != "" {
+		status += " by " + c.Author
+	}
+	return status + ": " + strings.Join(strings.Fields(c.Text), " ")
+}
+
+// toggleCommentsAt opens the comments on line, clicked in the gutter, or
+// closes them when they are open there already.
+func (m *Model) toggleCommentsAt(line int) {
+	if len(m.commentsAt(line)) == 0 {
+		return
+	}
+	m.review.open = !m.review.open || m.editor.GetCursor().GetBufferPos().Line != line
+}
+
+// orderedRange returns the range between a and b, in document order.
+func orderedRange(a, b ast.BufferPos) ast.Range {
+	if b.Before(a) {
+		a, b = b, a
+	}
+	return ast.Range{Start: a, End: b}
+}
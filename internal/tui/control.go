@@ -0,0 +1,235 @@
+package tui
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/ofri/mde/internal/control"
+	"github.com/ofri/mde/internal/lsp"
+	"github.com/ofri/mde/pkg/ast"
+)
+
+// controlMethod serves one JSON-RPC method on the update goroutine. Lines
+// and columns are counted from 1, as the editor shows them.
+type controlMethod func(m *Model, params json.RawMessage) (any, tea.Cmd, error)
+
+// controlMethods holds the methods the control socket serves, by name.
+var controlMethods = map[string]controlMethod{
+	"open":        (*Model).controlOpen,
+	"save":        (*Model).controlSave,
+	"goto":        (*Model).controlGoto,
+	"insert":      (*Model).controlInsert,
+	"text":        (*Model).controlText,
+	"cursor":      (*Model).controlCursor,
+	"diagnostics": (*Model).controlDiagnostics,
+	"command":     (*Model).controlCommand,
+}
+
+// controlMsg carries a control request into the update loop.
+type controlMsg struct {
+	method string
+	params json.RawMessage
+	reply  chan<- controlReply
+}
+
+type controlReply struct {
+	result any
+	err    error
+}
+
+// ControlHandler serves the control socket by passing each request to the
+// program through send, so it runs between key presses like any edit.
+func ControlHandler(send func(tea.Msg)) control.Handler {
+	return func(ctx context.Context, method string, params json.RawMessage) (any, error) {
+		if _, ok := controlMethods[method]; !ok {
+			return nil, control.ErrMethodNotFound
+		}
+		reply := make(chan controlReply, 1)
+		send(controlMsg{method: method, params: params, reply: reply})
+		select {
+		case r := <-reply:
+			return r.result, r.err
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+func (m *Model) handleControlMsg(msg controlMsg) (tea.Model, tea.Cmd) {
+	result, cmd, err := controlMethods[msg.method](m, msg.params)
+	msg.reply <- controlReply{result: result, err: err}
+	return m, cmd
+}
+
+// controlParams decodes params into v; absent params leave v as is.
+func controlParams(params json.RawMessage, v any) error {
+	if len(params) == 0 || string(params) == "null" {
+		return nil
+	}
+	if err := json.Unmarshal(params, v); err != nil {
+		return control.InvalidParams(err)
+	}
+	return nil
+}
+
+// controlPos is a position as the control socket reports it.
+type controlPos struct {
+	Line   int `json:"line"`
+	Column int `json:"column"`
+}
+
+func toControlPos(pos ast.BufferPos) controlPos {
+	return controlPos{Line: pos.Line + 1, Column: pos.Col + 1}
+}
+
+// controlOpen opens params.path in place of the document, refusing to
+// drop unsaved changes unless params.force is set.
+func (m *Model) controlOpen(params json.RawMessage) (any, tea.Cmd, error) {
+	var p struct {
+		Path  string `json:"path"`
+		Force bool   `json:"force"`
+	}
+	if err := controlParams(params, &p); err != nil {
+		return nil, nil, err
+	}
+	if p.Path == "" {
+		return nil, nil, control.InvalidParams(errors.New("missing path"))
+	}
+	if m.editor.GetDocument().IsModified() && !p.Force {
+		return nil, nil, errors.New("the document has unsaved changes")
+	}
+
+	if remoteFile(p.Path) {
+		m.openRemote(p.Path)
+		return nil, m.startTransfer(), nil
+	}
+	if m.openEncrypted(p.Path) {
+		return nil, m.startDecrypt(), nil
+	}
+	msg := m.loadFile(p.Path)().(fileLoadedMsg)
+	if msg.err != nil {
+		return nil, nil, msg.err
+	}
+	_, cmd := m.handleFileMsg(msg)
+	return nil, cmd, nil
+}
+
+// controlSave starts saving the document, as Ctrl+S does.
+func (m *Model) controlSave(params json.RawMessage) (any, tea.Cmd, error) {
+	if m.editor.GetDocument().GetFilename() == "" {
+		return nil, nil, errors.New("the document has no filename")
+	}
+	return nil, m.saveFile(), nil
+}
+
+// controlGoto moves the cursor to params.line and params.column, clamped
+// to the document.
+func (m *Model) controlGoto(params json.RawMessage) (any, tea.Cmd, error) {
+	p := controlPos{Column: 1}
+	if err := controlParams(params, &p); err != nil {
+		return nil, nil, err
+	}
+	if p.Line < 1 || p.Column < 1 {
+		return nil, nil, control.InvalidParams(fmt.Errorf("line and column count from 1"))
+	}
+	doc := m.editor.GetDocument()
+	cursor := m.editor.GetCursor()
+	cursor.ClearSelection()
+	cursor.SetBufferPos(doc.ValidatePosition(ast.BufferPos{Line: p.Line - 1, Col: p.Column - 1}))
+	m.editor.AdjustViewPort()
+	return toControlPos(cursor.GetBufferPos()), nil, nil
+}
+
+// controlInsert inserts params.text at the cursor, replacing the selection.
+func (m *Model) controlInsert(params json.RawMessage) (any, tea.Cmd, error) {
+	var p struct {
+		Text string `json:"text"`
+	}
+	if err := controlParams(params, &p); err != nil {
+		return nil, nil, err
+	}
+	if m.readOnly {
+		return nil, nil, errors.New("the document is read-only")
+	}
+	m.editor.InsertText(p.Text)
+	return toControlPos(m.editor.GetCursor().GetBufferPos()), nil, nil
+}
+
+func (m *Model) controlText(params json.RawMessage) (any, tea.Cmd, error) {
+	doc := m.editor.GetDocument()
+	return map[string]any{
+		"path":     doc.GetFilename(),
+		"text":     doc.GetText(),
+		"modified": doc.IsModified(),
+	}, nil, nil
+}
+
+func (m *Model) controlCursor(params json.RawMessage) (any, tea.Cmd, error) {
+	return toControlPos(m.editor.GetCursor().GetBufferPos()), nil, nil
+}
+
+// controlDiagnostic is a lint.Diagnostic, or one from the language
+// server or the link checker with its source, as the control socket
+// reports it.
+type controlDiagnostic struct {
+	Start    controlPos `json:"start"`
+	End      controlPos `json:"end"`
+	Severity string     `json:"severity"`
+	Message  string     `json:"message"`
+	Source   string     `json:"source,omitempty"`
+	Rule     string     `json:"rule,omitempty"`
+}
+
+func (m *Model) controlDiagnostics(params json.RawMessage) (any, tea.Cmd, error) {
+	diagnostics := []controlDiagnostic{}
+	doc := m.editor.GetDocument()
+	for _, d := range m.lintDiagnostics() {
+		diagnostics = append(diagnostics, controlDiagnostic{
+			Start:    toControlPos(d.Range.Start),
+			End:      toControlPos(d.Range.End),
+			Severity: d.Severity.String(),
+			Message:  d.Message,
+			Rule:     d.Rule,
+		})
+	}
+	if m.lsp.doc == doc {
+		for _, d := range m.lsp.diagnostics {
+			diagnostics = append(diagnostics, controlDiagnostic{
+				Start:    toControlPos(lsp.BufferPos(doc, d.Range.Start)),
+				End:      toControlPos(lsp.BufferPos(doc, d.Range.End)),
+				Severity: d.Severity.String(),
+				Message:  d.Message,
+				Source:   cmp.Or(d.Source, "lsp"),
+			})
+		}
+	}
+	for _, d := range m.brokenLinks() {
+		diagnostics = append(diagnostics, controlDiagnostic{
+			Start:    toControlPos(d.Range.Start),
+			End:      toControlPos(d.Range.End),
+			Severity: d.Severity.String(),
+			Message:  d.Message,
+			Source:   "links",
+		})
+	}
+	return diagnostics, nil, nil
+}
+
+// controlCommand runs params.name as if typed at the command prompt.
+func (m *Model) controlCommand(params json.RawMessage) (any, tea.Cmd, error) {
+	var p struct {
+		Name string `json:"name"`
+	}
+	if err := controlParams(params, &p); err != nil {
+		return nil, nil, err
+	}
+	cmd, ok := m.RunCommand(p.Name)
+	if !ok {
+		return nil, nil, control.InvalidParams(fmt.Errorf("unknown command %q", p.Name))
+	}
+	return nil, cmd, nil
+}
@@ -0,0 +1,85 @@
+package tui
+
+import (
+	"errors"
+
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/ofri/mde/internal/clipboard"
+)
+
+// clipboardWrittenMsg reports the outcome of a rich clipboard write.
+// When the platform has no rich clipboard tool, fallback carries the
+// content to deliver as plain text over OSC 52 instead.
+type clipboardWrittenMsg struct {
+	label    string
+	fallback string
+	err      error
+}
+
+func init() {
+	registerCommand(Command{
+		Name:        "copy-as-html",
+		Description: "Copy the selection as HTML source text",
+		Run:         (*Model).copySelectionAsHTML,
+		Markdown:    true,
+	})
+	registerCommand(Command{
+		Name:        "copy-as-rich-text",
+		Description: "Copy the selection as formatted text/html",
+		Run:         (*Model).copySelectionAsRichText,
+		Markdown:    true,
+	})
+}
+
+// selectionHTML converts the current selection from markdown to HTML.
+// Returns false if nothing is selected.
+func (m *Model) selectionHTML() (string, bool) {
+	if !m.editor.GetCursor().HasSelection() {
+		m.showMessage("Nothing selected")
+		return "", false
+	}
+	return m.convertMarkdownToHTML(m.editor.GetSelectionText()), true
+}
+
+// copySelectionAsHTML places the HTML markup on the clipboard as plain text,
+// for pasting into HTML sources or templates.
+func (m *Model) copySelectionAsHTML() tea.Cmd {
+	html, ok := m.selectionHTML()
+	if !ok {
+		return nil
+	}
+	m.showMessage("Copied as HTML")
+	return tea.SetClipboard(html)
+}
+
+// copySelectionAsRichText places the HTML on the clipboard with the
+// text/html MIME type so mail clients and word processors paste it formatted.
+func (m *Model) copySelectionAsRichText() tea.Cmd {
+	html, ok := m.selectionHTML()
+	if !ok {
+		return nil
+	}
+
+	return func() tea.Msg {
+		err := clipboard.WriteHTML(html)
+		if errors.Is(err, clipboard.ErrUnavailable) {
+			return clipboardWrittenMsg{label: "rich text", fallback: html}
+		}
+		return clipboardWrittenMsg{label: "rich text", err: err}
+	}
+}
+
+func (m *Model) handleClipboardMsg(msg clipboardWrittenMsg) (tea.Model, tea.Cmd) {
+	if msg.err != nil {
+		m.showMessage("Clipboard error: " + msg.err.Error())
+		return m, nil
+	}
+
+	if msg.fallback != "" {
+		m.showMessage("Copied as HTML (no rich clipboard tool found)")
+		return m, tea.SetClipboard(msg.fallback)
+	}
+
+	m.showMessage("Copied as " + msg.label)
+	return m, nil
+}
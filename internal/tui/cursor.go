@@ -0,0 +1,123 @@
+package tui
+
+import (
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/charmbracelet/lipgloss"
+)
+
+func init() {
+	registerCommand(Command{
+		Name:        "terminal-cursor",
+		Description: "Toggle using the terminal's own cursor instead of drawing one",
+		Run:         (*Model).toggleTerminalCursor,
+	})
+}
+
+// Cursor styles, for the cursor_style settings.
+const (
+	cursorBlock     = "block"
+	cursorBar       = "bar"
+	cursorUnderline = "underline"
+	cursorHidden    = "hidden"
+)
+
+// terminalCursorShapes are the terminal cursor shapes (DECSCUSR) for the
+// cursor styles; unknown styles are drawn as a block.
+var terminalCursorShapes = map[string]tea.CursorShape{
+	cursorBlock:     tea.CursorBlock,
+	cursorBar:       tea.CursorBar,
+	cursorUnderline: tea.CursorUnderline,
+}
+
+// toggleTerminalCursor switches between drawing the cursor into the text
+// and showing the terminal's own, for this session.
+func (m *Model) toggleTerminalCursor() tea.Cmd {
+	m.config.TerminalCursor = !m.config.TerminalCursor
+	if m.config.TerminalCursor {
+		m.showMessage("Terminal cursor")
+	} else {
+		m.showMessage("Drawn cursor")
+	}
+	return nil
+}
+
+// usesTerminalCursor reports whether the terminal's cursor stands in for
+// the drawn one. Screen readers follow it, so screen reader mode always
+// does.
+func (m *Model) usesTerminalCursor() bool {
+	return m.config.TerminalCursor || m.config.ScreenReader
+}
+
+// cursorStyle is the configured cursor style for the current mode.
+func (m *Model) cursorStyle() string {
+	switch {
+	case m.previewMode:
+		return m.config.CursorPreviewStyle
+	case m.editor.OverwriteMode():
+		return m.config.CursorOverwriteStyle
+	default:
+		return m.config.CursorStyle
+	}
+}
+
+// cursorShape is the cursor the renderer draws into the text: the
+// current style, or none while the terminal's cursor is used instead.
+func (m *Model) cursorShape() string {
+	if m.usesTerminalCursor() {
+		return cursorHidden
+	}
+	return m.cursorStyle()
+}
+
+// terminalCursor places the terminal's cursor on the editor cursor, in
+// the current style, when it is used instead of a drawn one; screen
+// readers follow it. While a prompt is open it sits at the start of the
+// prompt, and in preview at the top left unless the preview style hides
+// it. Otherwise the terminal's cursor is hidden.
+func (m *Model) terminalCursor() *tea.Cursor {
+	if !m.usesTerminalCursor() || m.err != nil {
+		return nil
+	}
+	if m.mode != ModeNormal && m.helpBarVisible() {
+		return m.styledCursor(0, m.height-lipgloss.Height(m.renderHelpBar()), m.config.CursorStyle)
+	}
+	if m.previewMode {
+		if m.config.CursorPreviewStyle == cursorHidden {
+			return nil
+		}
+		return m.styledCursor(0, 0, m.config.CursorPreviewStyle)
+	}
+	screenPos, err := m.editor.GetCursor().GetScreenPos()
+	if err != nil {
+		return nil
+	}
+	return m.styledCursor(screenPos.Col+m.textMargin(), screenPos.Row, m.cursorStyle())
+}
+
+// styledCursor returns a terminal cursor at x, y in style.
+func (m *Model) styledCursor(x, y int, style string) *tea.Cursor {
+	cursor := tea.NewCursor(x, y)
+	cursor.Shape = terminalCursorShapes[style]
+	cursor.Blink = m.config.CursorBlink
+	return cursor
+}
+
+// terminalCursorModel runs a Model with the terminal cursor it asks for.
+type terminalCursorModel struct {
+	*Model
+}
+
+// WithTerminalCursor wraps m for tea.NewProgram so the terminal's cursor
+// follows the editor cursor when it is used instead of a drawn one.
+func WithTerminalCursor(m *Model) tea.Model {
+	return terminalCursorModel{m}
+}
+
+func (c terminalCursorModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	_, cmd := c.Model.Update(msg)
+	return c, cmd
+}
+
+func (c terminalCursorModel) View() (string, *tea.Cursor) {
+	return c.Model.View(), c.Model.terminalCursor()
+}
@@ -0,0 +1,71 @@
+package tui
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/ofri/mde/internal/diagram"
+	"github.com/ofri/mde/internal/plugins/renderers"
+)
+
+// diagramTimeout bounds external diagram renderers, which may start a
+// headless browser.
+const diagramTimeout = 30 * time.Second
+
+// diagramRenderedMsg reports the outcome of an external diagram render.
+type diagramRenderedMsg struct {
+	path string
+	err  error
+}
+
+func init() {
+	registerCommand(Command{
+		Name:        "render-diagram",
+		Description: "Render the diagram fence under the cursor with diagram_command",
+		Run:         (*Model).renderDiagram,
+		Markdown:    true,
+	})
+}
+
+// renderDiagram passes the diagram fence under the cursor to the configured
+// external command, which writes an image file.
+func (m *Model) renderDiagram() tea.Cmd {
+	doc := m.editor.GetDocument()
+	block, ok := doc.FencedBlockAt(m.editor.GetCursor().GetBufferPos().Line)
+	if !ok || !renderers.IsDiagramLanguage(block.Language()) {
+		m.showMessage("No diagram fence under cursor")
+		return nil
+	}
+
+	command := m.config.DiagramCommand
+	if command == "" {
+		m.showMessage("No diagram_command configured")
+		return nil
+	}
+
+	lang := block.Language()
+	source := doc.FencedBlockSource(block)
+	output := diagram.OutputPath(lang, source, m.config.DiagramFormat)
+	m.showMessage("Rendering " + lang + " diagram...")
+
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), diagramTimeout)
+		defer cancel()
+		err := diagram.Render(ctx, command, lang, source, output)
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			err = errors.New("diagram command timed out")
+		}
+		return diagramRenderedMsg{path: output, err: err}
+	}
+}
+
+func (m *Model) handleDiagramMsg(msg diagramRenderedMsg) (tea.Model, tea.Cmd) {
+	if msg.err != nil {
+		m.showMessage("Diagram error: " + msg.err.Error())
+		return m, nil
+	}
+	m.showMessage("Diagram written to " + msg.path)
+	return m, nil
+}
@@ -0,0 +1,278 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/ofri/mde/internal/diff"
+	"github.com/ofri/mde/internal/plugins/renderers"
+	"github.com/ofri/mde/internal/theme"
+	"github.com/ofri/mde/pkg/ast"
+	"github.com/ofri/mde/pkg/plugin"
+)
+
+// diffTabWidth expands tabs before comparing, so both panes line up
+const diffTabWidth = 4
+
+// diffMarks is how one side of a diff marks changes: a gutter marker and
+// the theme elements styling it and the changed text within a line.
+type diffMarks struct {
+	marker string
+	gutter string
+	span   string
+}
+
+var (
+	diffRemoved = diffMarks{"-", theme.DiffRemoved, theme.DiffRemovedText}
+	diffAdded   = diffMarks{"+", theme.DiffAdded, theme.DiffAddedText}
+)
+
+// diffPane is one of the two files compared.
+type diffPane struct {
+	name   string
+	lines  []string
+	tokens [][]ast.Token
+}
+
+// DiffView shows two files side by side, scrolling together, with removed
+// lines marked on the left, added lines on the right and the changed text
+// within a line highlighted. Lines keep the editor's syntax highlighting.
+type DiffView struct {
+	left, right diffPane
+	rows        []diff.Row
+	changes     []int // First row of each run of changed rows
+
+	top           int
+	width, height int
+	renderer      *renderers.TerminalRenderer
+}
+
+// NewDiffView compares left and right, named leftName and rightName. The
+// names also choose the parser that highlights each side.
+func NewDiffView(leftName, left, rightName, right string) (*DiffView, error) {
+	registry := plugin.GetRegistry()
+	instance, err := registry.NewRendererInstance(registry.DefaultRendererName())
+	if err != nil {
+		return nil, err
+	}
+	renderer, ok := instance.(*renderers.TerminalRenderer)
+	if !ok {
+		return nil, fmt.Errorf("renderer is not a TerminalRenderer: got %T", instance)
+	}
+	v := &DiffView{renderer: renderer}
+	if v.left, err = newDiffPane(leftName, left); err != nil {
+		return nil, err
+	}
+	if v.right, err = newDiffPane(rightName, right); err != nil {
+		return nil, err
+	}
+
+	v.rows = diff.SideBySide(v.left.lines, v.right.lines)
+	for i, row := range v.rows {
+		if row.Op != diff.Equal && (i == 0 || v.rows[i-1].Op == diff.Equal) {
+			v.changes = append(v.changes, i)
+		}
+	}
+	return v, nil
+}
+
+// newDiffPane splits text into lines and highlights them with the parser
+// for name.
+func newDiffPane(name, text string) (diffPane, error) {
+	pane := diffPane{name: name, lines: strings.Split(strings.TrimSuffix(text, "\n"), "\n")}
+	for i, line := range pane.lines {
+		pane.lines[i] = ast.ExpandTabs(line, diffTabWidth)
+	}
+
+	parser, err := plugin.GetRegistry().GetParserForFile(name)
+	if err != nil {
+		return pane, err
+	}
+	ctx := context.Background()
+	pane.tokens = make([][]ast.Token, len(pane.lines))
+	for i, line := range pane.lines {
+		if pane.tokens[i], err = parser.GetSyntaxHighlighting(ctx, line); err != nil {
+			return pane, fmt.Errorf("%s line %d: %w", name, i+1, err)
+		}
+	}
+	return pane, nil
+}
+
+// Changes returns the number of separate changes between the files.
+func (v *DiffView) Changes() int {
+	return len(v.changes)
+}
+
+func (v *DiffView) Init() tea.Cmd {
+	return nil
+}
+
+func (v *DiffView) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		v.width, v.height = msg.Width, msg.Height
+	case tea.KeyPressMsg:
+		page := v.bodyHeight()
+		switch msg.String() {
+		case "q", "esc", "ctrl+q", "ctrl+c":
+			return v, tea.Quit
+		case "up", "k":
+			v.scrollTo(v.top - 1)
+		case "down", "j":
+			v.scrollTo(v.top + 1)
+		case "pgup", "b":
+			v.scrollTo(v.top - page)
+		case "pgdown", "space", "f":
+			v.scrollTo(v.top + page)
+		case "home", "g":
+			v.scrollTo(0)
+		case "end", "G":
+			v.scrollTo(len(v.rows))
+		case "n":
+			v.jumpToChange(1)
+		case "p", "N":
+			v.jumpToChange(-1)
+		}
+	case tea.MouseWheelMsg:
+		if msg.Button == tea.MouseWheelUp {
+			v.scrollTo(v.top - 3)
+		} else if msg.Button == tea.MouseWheelDown {
+			v.scrollTo(v.top + 3)
+		}
+	}
+	return v, nil
+}
+
+// bodyHeight is the number of rows between the header and the help line
+func (v *DiffView) bodyHeight() int {
+	return max(v.height-2, 1)
+}
+
+func (v *DiffView) scrollTo(top int) {
+	v.top = max(min(top, len(v.rows)-v.bodyHeight()), 0)
+}
+
+// jumpToChange scrolls to the next (direction 1) or previous (-1) change,
+// a few rows below the top for context.
+func (v *DiffView) jumpToChange(direction int) {
+	const context = 3
+	current := v.top + context
+	if direction > 0 {
+		for _, row := range v.changes {
+			if row > current {
+				v.scrollTo(row - context)
+				return
+			}
+		}
+	} else {
+		for i := len(v.changes) - 1; i >= 0; i-- {
+			if v.changes[i] < current {
+				v.scrollTo(v.changes[i] - context)
+				return
+			}
+		}
+	}
+}
+
+func (v *DiffView) View() string {
+	paneWidth := max((v.width-1)/2, 1)
+	header := lipgloss.NewStyle().Reverse(true).Bold(true)
+	separator := theme.Resolved().Style(theme.LineNumber).ToLipgloss().Render("│")
+
+	lines := []string{header.Render(padCells(" "+v.left.name, paneWidth)) + " " +
+		header.Render(padCells(" "+v.right.name, v.width-paneWidth-1))}
+	numberWidth := len(fmt.Sprint(max(len(v.left.lines), len(v.right.lines))))
+	for i := v.top; i < min(v.top+v.bodyHeight(), len(v.rows)); i++ {
+		left, right := v.renderRow(v.rows[i], numberWidth)
+		lines = append(lines, padCells(left, paneWidth)+separator+padCells(right, v.width-paneWidth-1))
+	}
+	for len(lines) < v.height-1 {
+		lines = append(lines, "")
+	}
+
+	help := fmt.Sprintf("%d changes | n/p: Next/previous change | q: Quit", len(v.changes))
+	if len(v.changes) == 0 {
+		help = "Files are identical | q: Quit"
+	}
+	lines = append(lines, lipgloss.NewStyle().Reverse(true).Render(padCells(help, v.width)))
+	return strings.Join(lines, "\n")
+}
+
+// renderRow renders both sides of a row, each with a line number and a
+// gutter marker.
+func (v *DiffView) renderRow(row diff.Row, numberWidth int) (string, string) {
+	var removed, added []diff.Span
+	if row.Op == diff.Change {
+		removed, added = diff.Intraline(v.left.lines[row.A], v.right.lines[row.B])
+	}
+	changed := row.Op != diff.Equal
+	return v.renderSide(v.left, row.A, numberWidth, changed, diffRemoved, removed),
+		v.renderSide(v.right, row.B, numberWidth, changed, diffAdded, added)
+}
+
+// renderSide renders line of pane through the renderer, marking it as
+// changed and highlighting spans within it with marks.
+func (v *DiffView) renderSide(pane diffPane, line, numberWidth int, changed bool, marks diffMarks, spans []diff.Span) string {
+	if line < 0 {
+		return ""
+	}
+	rendered, err := v.renderer.RenderLine(context.Background(), pane.lines[line], pane.tokens[line])
+	if err != nil {
+		rendered = plugin.RenderedLine{Content: pane.lines[line]}
+	}
+
+	gutter := " "
+	if changed {
+		gutter = marks.marker
+	}
+	prefix := fmt.Sprintf("%*d %s ", numberWidth, line+1, gutter)
+	prefixLen := len(prefix)
+	styled := theme.Resolved()
+
+	styles := make([]plugin.StyleRange, 0, len(rendered.Styles)+len(spans)+1)
+	for _, s := range withoutSpans(rendered.Styles, spans) {
+		styles = append(styles, plugin.StyleRange{Start: s.Start + prefixLen, End: s.End + prefixLen, Style: s.Style})
+	}
+	for _, span := range spans {
+		styles = append(styles, plugin.StyleRange{Start: span.Start + prefixLen, End: span.End + prefixLen, Style: styled.Style(marks.span)})
+	}
+	if changed {
+		styles = append(styles, plugin.StyleRange{Start: 0, End: prefixLen - 1, Style: styled.Style(marks.gutter)})
+	} else {
+		styles = append(styles, plugin.StyleRange{Start: 0, End: numberWidth, Style: styled.Style(theme.LineNumber)})
+	}
+	return v.renderer.RenderToString([]plugin.RenderedLine{{Content: prefix + rendered.Content, Styles: styles}})
+}
+
+// withoutSpans cuts spans out of styles, so the diff highlight replaces
+// the syntax highlighting where they overlap.
+func withoutSpans(styles []plugin.StyleRange, spans []diff.Span) []plugin.StyleRange {
+	for _, span := range spans {
+		var kept []plugin.StyleRange
+		for _, s := range styles {
+			if s.End <= span.Start || s.Start >= span.End {
+				kept = append(kept, s)
+				continue
+			}
+			if s.Start < span.Start {
+				kept = append(kept, plugin.StyleRange{Start: s.Start, End: span.Start, Style: s.Style})
+			}
+			if s.End > span.End {
+				kept = append(kept, plugin.StyleRange{Start: span.End, End: s.End, Style: s.Style})
+			}
+		}
+		styles = kept
+	}
+	return styles
+}
+
+// padCells truncates or pads s, which may contain escape sequences, to
+// exactly width cells.
+func padCells(s string, width int) string {
+	s = ansi.Truncate(s, width, "")
+	return s + strings.Repeat(" ", max(width-ansi.StringWidth(s), 0))
+}
@@ -0,0 +1,74 @@
+package tui
+
+import (
+	"bytes"
+	"strings"
+
+	"github.com/ofri/mde/internal/editorconfig"
+)
+
+// loadEditorConfig applies the .editorconfig settings for filename where
+// the user's config leaves them to their defaults, returning the function
+// that puts the user's back, or nil if none apply. With end_of_line set,
+// the buffer's lines lose any carriage returns, which saving puts back.
+func (m *Model) loadEditorConfig(filename string) (restore func()) {
+	settings, err := editorconfig.Lookup(filename)
+	if err != nil {
+		m.showMessage("EditorConfig: " + err.Error())
+		return nil
+	}
+	m.project.editorConfig = settings
+	if settings.Empty() {
+		return nil
+	}
+
+	if settings.EndOfLine != "" {
+		doc := m.editor.GetDocument()
+		modified := doc.IsModified()
+		if doc.StripCarriageReturns() > 0 {
+			if !modified {
+				doc.ClearModified() // Read as it will be written
+			}
+			cursor := m.editor.GetCursor()
+			cursor.SetBufferPos(doc.ValidatePosition(cursor.GetBufferPos()))
+		}
+	}
+	return settings.Apply(m.config)
+}
+
+// checkFinalNewline notes whether the buffer, as just read or saved,
+// lacks the line break ending its text, for the status bar. Unnamed,
+// modified and empty buffers are not files lacking one.
+func (m *Model) checkFinalNewline() {
+	doc := m.editor.GetDocument()
+	m.noEOL = doc.GetFilename() != "" && !doc.IsModified() && !doc.HasFinalNewline() &&
+		(doc.LineCount() > 1 || doc.GetLineLength(0) > 0)
+}
+
+// lineEndingEncoder returns encode, first turning the text's line breaks
+// into those end_of_line asks for.
+func (m *Model) lineEndingEncoder(encode func([]byte) ([]byte, error)) func([]byte) ([]byte, error) {
+	eol := m.project.editorConfig.LineEnding()
+	if eol == "" || eol == "\n" {
+		return encode
+	}
+	return func(text []byte) ([]byte, error) {
+		text = bytes.ReplaceAll(text, []byte("\n"), []byte(eol))
+		if encode == nil {
+			return text, nil
+		}
+		return encode(text)
+	}
+}
+
+// indentation is the text Tab inserts at the cursor: a tab, or with
+// indent_style = "space", spaces up to the next tab stop.
+func (m *Model) indentation() string {
+	if m.config.IndentStyle != "space" {
+		return "\t"
+	}
+	width := m.editor.GetViewport().GetTabWidth()
+	pos := m.editor.GetCursor().GetBufferPos()
+	col := m.editor.GetViewport().DisplayColumn(m.editor.GetDocument().GetLine(pos.Line), pos.Col)
+	return strings.Repeat(" ", width-col%width)
+}
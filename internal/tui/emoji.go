@@ -0,0 +1,47 @@
+package tui
+
+import (
+	"github.com/ofri/mde/internal/emoji"
+)
+
+// emojiPopup lists the shortcodes matching a ":name" typed before the
+// cursor. Returns nil outside a shortcode.
+func (m *Model) emojiPopup() *completionPopup {
+	pos := m.editor.GetCursor().GetBufferPos()
+	partial, ok := emoji.PartialAt(m.editor.GetDocument().GetLine(pos.Line), pos.Col)
+	if !ok {
+		return nil
+	}
+
+	matches := emoji.Complete(partial)
+	if len(matches) == 0 {
+		return nil
+	}
+
+	items := make([]popupItem, 0, len(matches))
+	for _, name := range matches {
+		glyph, _ := emoji.Lookup(name)
+		items = append(items, popupItem{label: glyph + " " + name, insert: name + ":"})
+	}
+	return &completionPopup{
+		items:   items,
+		replace: len([]rune(partial)),
+		refresh: (*Model).emojiPopup,
+	}
+}
+
+// completeEmoji completes a partially typed :shortcode at the cursor,
+// inserting a unique match directly and opening the popup otherwise.
+// Returns false if the cursor is not after a shortcode prefix.
+func (m *Model) completeEmoji() bool {
+	popup := m.emojiPopup()
+	if popup == nil {
+		return false
+	}
+
+	m.popup = popup
+	if len(popup.items) == 1 {
+		m.acceptPopup()
+	}
+	return true
+}
@@ -0,0 +1,206 @@
+package tui
+
+import (
+	"context"
+	"os"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/ofri/mde/internal/crypt"
+	"github.com/ofri/mde/pkg/ast"
+)
+
+// cryptTimeout bounds one run of the encryption tool
+const cryptTimeout = 30 * time.Second
+
+// cryptState holds the passphrase of the open encrypted note. It is kept
+// in memory only, and only for the document it was entered for.
+type cryptState struct {
+	doc        *ast.Document
+	passphrase string
+
+	// pendingOpen is the note waiting for its passphrase to be decrypted;
+	// "" when the prompt is for saving instead
+	pendingOpen string
+
+	// confirm is the new passphrase typed once, waiting to be typed again
+	// before the note is first saved
+	confirm string
+
+	// pendingDecrypt is the note queued by openEncrypted to be decrypted
+	// without a passphrase, run by startDecrypt
+	pendingDecrypt string
+}
+
+// noteDecryptedMsg carries the plaintext of an encrypted note, or why it
+// could not be read or decrypted.
+type noteDecryptedMsg struct {
+	filename   string
+	passphrase string
+	plaintext  []byte
+	readErr    error
+	err        error
+}
+
+// encrypted reports whether filename names a note kept encrypted
+func (m *Model) encrypted(filename string) bool {
+	return filename != "" && crypt.Matches(filename, m.config.EncryptedPatterns)
+}
+
+func (m *Model) cipher(filename string) crypt.Cipher {
+	return crypt.For(filename, m.config.AgeIdentity)
+}
+
+// passphrase returns the passphrase entered for the current document
+func (m *Model) passphrase() (string, bool) {
+	if m.crypt.doc != m.editor.GetDocument() {
+		return "", false
+	}
+	return m.crypt.passphrase, true
+}
+
+// openEncrypted opens an existing encrypted note, first prompting for its
+// passphrase when the cipher takes one; otherwise decrypting is queued for
+// startDecrypt. The current buffer stays until the note is decrypted.
+// Returns false for any other file.
+func (m *Model) openEncrypted(filename string) bool {
+	if !m.encrypted(filename) {
+		return false
+	}
+	if _, err := os.Stat(filename); err != nil {
+		return false // A new note, encrypted when first saved
+	}
+	if m.cipher(filename).NeedsPassphrase() {
+		m.promptPassphrase(filename)
+	} else {
+		m.crypt.pendingDecrypt = filename
+	}
+	return true
+}
+
+// startDecrypt runs the decryption queued by openEncrypted.
+func (m *Model) startDecrypt() tea.Cmd {
+	filename := m.crypt.pendingDecrypt
+	if filename == "" {
+		return nil
+	}
+	m.crypt.pendingDecrypt = ""
+	return m.decryptNote(filename, "")
+}
+
+// promptPassphrase asks for the passphrase to open filename, or to save
+// the current document when filename is "".
+func (m *Model) promptPassphrase(filename string) {
+	m.crypt.pendingOpen = filename
+	m.crypt.confirm = ""
+	m.mode = ModePassphrase
+	m.input = ""
+}
+
+// decryptNote reads and decrypts filename in the background; the
+// encryption tool may take a while.
+func (m *Model) decryptNote(filename, passphrase string) tea.Cmd {
+	cipher := m.cipher(filename)
+	m.showMessage("Decrypting " + filename + "...")
+	return func() tea.Msg {
+		msg := noteDecryptedMsg{filename: filename, passphrase: passphrase}
+		ciphertext, err := os.ReadFile(filename)
+		if err != nil {
+			msg.readErr = err
+			return msg
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), cryptTimeout)
+		defer cancel()
+		msg.plaintext, msg.err = cipher.Decrypt(ctx, ciphertext, passphrase)
+		return msg
+	}
+}
+
+// handleNoteDecrypted replaces the buffer with the decrypted note.
+func (m *Model) handleNoteDecrypted(msg noteDecryptedMsg) (tea.Model, tea.Cmd) {
+	if msg.readErr != nil {
+		m.showMessage("Error loading file: " + msg.readErr.Error())
+		return m, nil
+	}
+	if msg.err != nil {
+		m.showMessage("Cannot decrypt " + msg.filename + ": " + msg.err.Error())
+		return m, nil
+	}
+
+	m.NewBuffer(string(msg.plaintext), msg.filename)
+	m.editor.GetDocument().ClearModified()
+	m.crypt.doc = m.editor.GetDocument()
+	m.crypt.passphrase = msg.passphrase
+	m.showMessage("Decrypted " + msg.filename)
+	return m, nil
+}
+
+// saveEncoder returns how to encode the current document for saving to
+// filename: nil for plain files, encryption for notes. ok is false when a
+// passphrase has to be asked for first.
+func (m *Model) saveEncoder(filename string) (encode func([]byte) ([]byte, error), ok bool) {
+	if !m.encrypted(filename) {
+		return nil, true
+	}
+	cipher := m.cipher(filename)
+	passphrase, ok := m.passphrase()
+	if !ok && cipher.NeedsPassphrase() {
+		return nil, false
+	}
+	return func(plaintext []byte) ([]byte, error) {
+		ctx, cancel := context.WithTimeout(context.Background(), cryptTimeout)
+		defer cancel()
+		return cipher.Encrypt(ctx, plaintext, passphrase)
+	}, true
+}
+
+// handlePassphrase takes the passphrase typed at the prompt, then opens
+// the pending note or saves the document. A new passphrase for saving is
+// asked for twice, so a typo does not lock the note away.
+func (m *Model) handlePassphrase() (tea.Model, tea.Cmd) {
+	passphrase := m.input
+	filename := m.crypt.pendingOpen
+	m.input = ""
+
+	if passphrase == "" {
+		m.crypt.pendingOpen = ""
+		m.crypt.confirm = ""
+		m.mode = ModeNormal
+		m.showMessage("No passphrase entered")
+		return m, nil
+	}
+	if filename == "" && m.crypt.confirm == "" {
+		m.crypt.confirm = passphrase
+		return m, nil
+	}
+
+	confirm := m.crypt.confirm
+	m.crypt.pendingOpen = ""
+	m.crypt.confirm = ""
+	m.mode = ModeNormal
+	if filename != "" {
+		return m, m.decryptNote(filename, passphrase)
+	}
+	if passphrase != confirm {
+		m.showMessage("Passphrases do not match - not saved")
+		return m, nil
+	}
+	m.crypt.doc = m.editor.GetDocument()
+	m.crypt.passphrase = passphrase
+	return m, m.saveFile()
+}
+
+// passphrasePrompt is the help bar text of the passphrase prompt; the
+// passphrase itself is never shown.
+func (m *Model) passphrasePrompt() string {
+	filename := m.crypt.pendingOpen
+	if filename == "" {
+		filename = m.editor.GetDocument().GetFilename()
+	}
+	label := "Passphrase for "
+	if m.crypt.confirm != "" {
+		label = "Confirm passphrase for "
+	}
+	return label + filename + ": " + strings.Repeat("*", len([]rune(m.input))) + " | Enter: OK | Esc: Cancel"
+}
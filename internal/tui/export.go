@@ -0,0 +1,122 @@
+package tui
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/ofri/mde/internal/pandoc"
+)
+
+// pandocFormats are completed at the export prompt; pandoc takes others too.
+var pandocFormats = []string{"docx", "epub", "latex", "odt", "html", "rtf", "pptx", "beamer", "gfm"}
+
+// exportState is the format last exported to, offered again at the
+// prompt, and the events of the export running, nil when none is.
+type exportState struct {
+	format string
+	events chan tea.Msg
+}
+
+// exportProgressMsg carries a line of pandoc's log.
+type exportProgressMsg struct {
+	line string
+}
+
+// exportDoneMsg reports the end of an export.
+type exportDoneMsg struct {
+	output string
+	err    error
+}
+
+func init() {
+	registerCommand(Command{
+		Name:        "export-pandoc",
+		Description: "Export the document to docx, epub, latex or another format with pandoc",
+		Run:         (*Model).promptExport,
+		Markdown:    true,
+	})
+}
+
+// promptExport asks for the format to export to.
+func (m *Model) promptExport() tea.Cmd {
+	if m.export.events != nil {
+		m.showMessage("An export is still running")
+		return nil
+	}
+	if m.editor.GetDocument().GetFilename() == "" || m.readOnly {
+		m.showMessage("Save the document to a file before exporting")
+		return nil
+	}
+	m.mode = ModeExport
+	m.input = m.export.format
+	if m.input == "" {
+		m.input = "docx"
+	}
+	return nil
+}
+
+// handleExport converts the buffer, saved or not, next to its file.
+// Pandoc's log is shown in the status line as it runs.
+func (m *Model) handleExport() (tea.Model, tea.Cmd) {
+	format := strings.TrimSpace(m.input)
+	m.mode = ModeNormal
+	m.input = ""
+	if format == "" {
+		m.showMessage("No format given")
+		return m, nil
+	}
+	m.export.format = format
+
+	doc := m.editor.GetDocument()
+	filename := doc.GetFilename()
+	output := pandoc.OutputPath(filename, format)
+	if abs, err := filepath.Abs(output); err == nil {
+		output = abs
+	}
+	opts := pandoc.Options{
+		Command: m.config.PandocCommand,
+		To:      format,
+		Output:  output,
+		Dir:     filepath.Dir(filename),
+		Args:    pandoc.ArgsFor(m.config.PandocArgs, format),
+	}
+	text := doc.GetText()
+
+	events := make(chan tea.Msg, 16)
+	m.export.events = events
+	m.showMessage("Exporting to " + filepath.Base(output) + " with pandoc...")
+	go func() {
+		err := pandoc.Convert(context.Background(), text, opts, func(line string) {
+			select {
+			case events <- exportProgressMsg{line: line}:
+			default: // The status line shows the latest anyway
+			}
+		})
+		events <- exportDoneMsg{output: output, err: err}
+	}()
+	return m, waitForExport(events)
+}
+
+// waitForExport delivers the next event of a running export.
+func waitForExport(events chan tea.Msg) tea.Cmd {
+	return func() tea.Msg {
+		return <-events
+	}
+}
+
+func (m *Model) handleExportProgress(msg exportProgressMsg) (tea.Model, tea.Cmd) {
+	m.showMessage("pandoc: " + msg.line)
+	return m, waitForExport(m.export.events)
+}
+
+func (m *Model) handleExportDone(msg exportDoneMsg) (tea.Model, tea.Cmd) {
+	m.export.events = nil
+	if msg.err != nil {
+		m.showMessage("Export failed: " + msg.err.Error())
+		return m, nil
+	}
+	m.showMessage("Exported to " + msg.output)
+	return m, nil
+}
@@ -0,0 +1,52 @@
+package tui
+
+import (
+	"fmt"
+	"runtime/debug"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/ofri/mde/internal/debuglog"
+)
+
+// recoverFailure turns a panic in a plugin into an error for the caller,
+// logging the stack. Use as: defer recoverFailure(&err).
+func recoverFailure(err *error) {
+	if r := recover(); r != nil {
+		*err = fmt.Errorf("panic: %v", r)
+		debuglog.Printf("recovered %v\n%s", r, debug.Stack())
+	}
+}
+
+// reportFailure logs err and shows it in the status bar. The same failure
+// repeated on every frame is only logged once.
+func (m *Model) reportFailure(what string, err error) {
+	text := fmt.Sprintf("%s failed: %v", what, err)
+	if text != m.lastFailure {
+		m.lastFailure = text
+		debuglog.Printf("%s", text)
+	}
+	m.showMessage("Error: " + text)
+}
+
+// renderFailure stands in for the text area when rendering fails, so a
+// broken plugin costs the view rather than the editing session.
+func (m *Model) renderFailure(what string, err error) string {
+	m.reportFailure(what, err)
+
+	lines := []string{
+		fmt.Sprintf("Could not render the %s: %v", what, err),
+		"",
+		"The document is intact: editing keys still work and Ctrl+S saves.",
+		"Ctrl+P switches between editing and preview.",
+	}
+	if path := debuglog.Path(); path != "" {
+		lines = append(lines, "Details were written to "+path)
+	}
+
+	height := m.GetContentHeight()
+	for len(lines) < height {
+		lines = append(lines, "")
+	}
+	return lipgloss.NewStyle().Width(m.width).Height(height).MaxHeight(height).Render(strings.Join(lines, "\n"))
+}
@@ -1,7 +1,8 @@
 package tui
 
 import (
-	"bufio"
+	"context"
+	"errors"
 	"os"
 	"strings"
 
@@ -16,8 +17,11 @@ type fileLoadedMsg struct {
 }
 
 type fileSavedMsg struct {
-	filename string
-	err      error
+	filename   string
+	doc        *ast.Document // The document saved
+	revision   int           // Its revision when the text was taken
+	err        error
+	historyErr error // Taking the snapshot failed, though the save did not
 }
 
 type fileOpenPromptMsg struct{}
@@ -30,28 +34,26 @@ func (m *Model) openFile() tea.Cmd {
 
 func (m *Model) loadFile(filename string) tea.Cmd {
 	return func() tea.Msg {
-		file, err := os.Open(filename)
-		if err != nil {
-			return fileLoadedMsg{filename: filename, err: err}
-		}
-		defer file.Close()
-
-		var lines []string
-		scanner := bufio.NewScanner(file)
-		for scanner.Scan() {
-			lines = append(lines, scanner.Text())
-		}
-
-		if err := scanner.Err(); err != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), transferTimeout)
+		defer cancel()
+		data, err := ast.ReadFile(ctx, filename)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
 			return fileLoadedMsg{filename: filename, err: err}
 		}
 
-		if len(lines) == 0 {
-			lines = []string{""}
-		}
+		return fileLoadedMsg{filename: filename, content: splitLines(string(data))}
+	}
+}
 
-		return fileLoadedMsg{filename: filename, content: lines}
+// splitLines splits text into lines, without their line breaks, CRLF ones
+// included. A final line break leaves an empty last line, as in
+// ast.NewDocument. Lines may be of any length.
+func splitLines(text string) []string {
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimSuffix(line, "\r")
 	}
+	return lines
 }
 
 func (m *Model) saveFile() tea.Cmd {
@@ -60,35 +62,108 @@ func (m *Model) saveFile() tea.Cmd {
 		m.showMessage("No filename specified")
 		return nil
 	}
+	if m.readOnly {
+		m.showMessage(filename + " is read-only")
+		return nil
+	}
+	if m.transfer.active() {
+		m.showMessage("Wait until " + m.transfer.name + " has finished " + strings.ToLower(m.transfer.verb))
+		return nil
+	}
+	encode, ok := m.saveEncoder(filename)
+	if !ok {
+		m.promptPassphrase("")
+		return nil
+	}
+	encode = m.lineEndingEncoder(encode)
+	m.prepareSave()
+	snapshot := m.snapshotOnSave(filename)
+
+	// The text is taken now: editing goes on while it is written
+	doc := m.editor.GetDocument()
+	content, revision := []byte(doc.GetText()), doc.Revision()
+	save := func() tea.Msg {
+		err := ast.WriteFileEncoded(filename, content, encode)
+		msg := fileSavedMsg{filename: filename, doc: doc, revision: revision, err: err}
+		if err == nil && snapshot != nil {
+			msg.historyErr = snapshot()
+		}
+		return msg
+	}
+	if !remoteFile(filename) {
+		return save
+	}
+	m.transfer.start("Saving", filename)
+	m.showMessage(m.transfer.status())
+	return tea.Batch(save, transferTick())
+}
 
-	return func() tea.Msg {
-		err := m.editor.SaveFile(filename)
-		return fileSavedMsg{filename: filename, err: err}
+// prepareSave applies the on-save settings to the buffer before it is
+// written. It runs on the update goroutine, ahead of any save command.
+func (m *Model) prepareSave() {
+	if m.config.NumberHeadings && !m.plainText() {
+		m.editor.NumberHeadings(-1)
+	}
+	if m.config.TOCOnSave && !m.plainText() {
+		m.editor.UpdateTOC(m.config.TOCStartMarker, m.config.TOCEndMarker, false)
+	}
+	if m.config.StripTrailingWhitespace {
+		m.editor.StripTrailingWhitespace(!m.plainText())
+	}
+	if final, ok := m.finalNewline(); ok {
+		m.editor.SetFinalNewline(final)
 	}
 }
 
+// finalNewline returns whether saving adds the line break ending the text
+// or removes it, or false for ok when the text is saved as it is. The
+// user's insert_final_newline wins over the .editorconfig's.
+func (m *Model) finalNewline() (final, ok bool) {
+	if m.config.InsertFinalNewline || m.config.IsSet("insert_final_newline") {
+		return m.config.InsertFinalNewline, m.config.InsertFinalNewline
+	}
+	if final := m.project.editorConfig.InsertFinalNewline; final != nil {
+		return *final, true
+	}
+	return false, false
+}
+
 
 func (m *Model) handleFileMsg(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case fileLoadedMsg:
+		m.transfer = transferState{}
 		if msg.err != nil {
-			m.showMessage("Error loading file: " + msg.err.Error())
+			m.showMessage(transferError("loading", msg.err))
 			return m, nil
 		}
 		// Load content into editor
-		content := strings.Join(msg.content, "\n")
-		m.editor = ast.NewEditorWithContent(content)
-		m.editor.GetDocument().SetFilename(msg.filename)
+		m.NewBuffer(strings.Join(msg.content, "\n"), msg.filename)
 		m.editor.GetDocument().ClearModified()
+		m.checkFinalNewline()
 		m.showMessage("Loaded " + msg.filename)
+		if readOnlyURL(msg.filename) {
+			m.readOnly = true
+			m.setPreviewMode(true)
+		}
 		return m, nil
 
 	case fileSavedMsg:
+		m.transfer = transferState{}
 		if msg.err != nil {
-			m.showMessage("Error saving file: " + msg.err.Error())
+			m.showMessage(transferError("saving", msg.err))
 			return m, nil
 		}
+		// Edits made while the text was written are still unsaved
+		if msg.doc == m.editor.GetDocument() && msg.revision == msg.doc.Revision() {
+			msg.doc.ClearModified()
+		}
+		m.checkFinalNewline()
 		m.showMessage("Saved " + msg.filename)
+		if msg.historyErr != nil {
+			m.showMessage("Saved " + msg.filename + ", but not to its history: " + msg.historyErr.Error())
+		}
+		m.saveMovedComments()
 		return m, nil
 
 	case fileOpenPromptMsg:
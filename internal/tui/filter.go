@@ -0,0 +1,117 @@
+package tui
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/ofri/mde/internal/filter"
+	"github.com/ofri/mde/pkg/ast"
+)
+
+// filterState remembers the last filter command, offered again at the
+// prompt, and whether one is running.
+type filterState struct {
+	last    string
+	running bool
+}
+
+// filterDoneMsg reports the output of a filter command for the range it
+// was given, as of the document's revision then.
+type filterDoneMsg struct {
+	doc      *ast.Document
+	revision int
+	target   ast.Range
+	command  string
+	output   string
+	err      error
+}
+
+func init() {
+	registerCommand(Command{
+		Name:        "filter-selection",
+		Description: "Replace the selection, or the whole document, with its output through a shell command",
+		Run:         (*Model).promptFilter,
+	})
+}
+
+// promptFilter asks for the command to pipe the text through.
+func (m *Model) promptFilter() tea.Cmd {
+	if m.readOnly {
+		m.showMessage("The document is read-only")
+		return nil
+	}
+	if m.filter.running {
+		m.showMessage("A filter command is still running")
+		return nil
+	}
+	m.mode = ModeFilter
+	m.input = m.filter.last
+	return nil
+}
+
+// filterTarget returns the selection, or else the whole document.
+func (m *Model) filterTarget() ast.Range {
+	if selection := m.editor.GetCursor().GetSelection(); selection != nil {
+		start, end := selection.Start, selection.End
+		if start.Line > end.Line || (start.Line == end.Line && start.Col > end.Col) {
+			start, end = end, start
+		}
+		return ast.Range{Start: start, End: end}
+	}
+	doc := m.editor.GetDocument()
+	last := doc.LineCount() - 1
+	return ast.Range{End: ast.BufferPos{Line: last, Col: doc.GetLineLength(last)}}
+}
+
+// handleFilter starts the command from the prompt in the background;
+// handleFilterDone replaces the text with its output.
+func (m *Model) handleFilter() (tea.Model, tea.Cmd) {
+	command := strings.TrimSpace(m.input)
+	m.mode = ModeNormal
+	m.input = ""
+	if command == "" {
+		m.showMessage("No command given")
+		return m, nil
+	}
+	m.filter.last = command
+	m.filter.running = true
+
+	doc := m.editor.GetDocument()
+	target := m.filterTarget()
+	input := doc.GetSelectionText(&ast.Selection{Start: target.Start, End: target.End})
+	timeout := time.Duration(max(m.config.FilterTimeout, 1)) * time.Second
+	m.showMessage("Running " + command + "...")
+	revision := doc.Revision()
+	return m, func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+		output, err := filter.Run(ctx, command, input)
+		return filterDoneMsg{doc: doc, revision: revision, target: target, command: command, output: output, err: err}
+	}
+}
+
+// handleFilterDone replaces the filtered text with the command's output,
+// unless the command failed or the text changed while it ran.
+func (m *Model) handleFilterDone(msg filterDoneMsg) (tea.Model, tea.Cmd) {
+	m.filter.running = false
+	switch {
+	case errors.Is(msg.err, filter.ErrTimeout):
+		m.showMessage(fmt.Sprintf("%s timed out after %ds", msg.command, max(m.config.FilterTimeout, 1)))
+		return m, nil
+	case msg.err != nil:
+		m.showMessage(msg.command + " failed: " + msg.err.Error())
+		return m, nil
+	case msg.doc != m.editor.GetDocument() || msg.revision != msg.doc.Revision():
+		m.showMessage(msg.command + " output discarded: the text changed meanwhile")
+		return m, nil
+	}
+
+	m.editor.ReplaceRange(msg.target, msg.output)
+	m.editor.AdjustViewPort()
+	m.showMessage(fmt.Sprintf("Filtered through %s (%d lines)", msg.command, strings.Count(msg.output, "\n")+1))
+	return m, nil
+}
@@ -0,0 +1,157 @@
+package tui
+
+import (
+	"errors"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/ofri/mde/internal/browser"
+	"github.com/ofri/mde/internal/links"
+	"github.com/ofri/mde/internal/wiki"
+)
+
+// linkOpenedMsg reports the outcome of opening a URL externally.
+type linkOpenedMsg struct {
+	url string
+	err error
+}
+
+var externalSchemeRe = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9+.\-]*:`)
+
+func init() {
+	registerCommand(Command{
+		Name:        "follow-link",
+		Description: "Open the link under the cursor (Alt+Enter)",
+		Run:         (*Model).followLink,
+	})
+}
+
+// followLink opens whatever link is under the cursor: wiki links open
+// notes, URLs open in the browser, relative paths open in the editor and
+// #anchors jump to the matching heading.
+func (m *Model) followLink() tea.Cmd {
+	pos := m.editor.GetCursor().GetBufferPos()
+	doc := m.editor.GetDocument()
+	line := doc.GetLine(pos.Line)
+
+	if _, ok := wiki.LinkAt(line, pos.Col); ok && m.config.WikiLinks {
+		return m.followWikiLink()
+	}
+
+	link, ok := links.At(line, pos.Col)
+	if !ok {
+		m.showMessage("No link under cursor")
+		return nil
+	}
+
+	if link.Kind == links.FootnoteRef || link.Kind == links.FootnoteDefinition {
+		m.jumpToFootnote(link)
+		return nil
+	}
+
+	target := link.URL
+	if link.Kind == links.Reference {
+		defs := links.Definitions(strings.Split(doc.GetText(), "\n"))
+		target, ok = defs[links.NormalizeLabel(link.Label)]
+		if !ok {
+			m.showMessage("Undefined link reference [" + link.Label + "]")
+			return nil
+		}
+	}
+
+	return m.openLinkTarget(target)
+}
+
+// openLinkTarget dispatches a resolved link destination.
+func (m *Model) openLinkTarget(target string) tea.Cmd {
+	switch {
+	case strings.HasPrefix(target, "#"):
+		m.jumpToAnchor(strings.TrimPrefix(target, "#"))
+		return nil
+	case externalSchemeRe.MatchString(target) && !isWindowsPath(target):
+		m.showMessage("Opening " + target)
+		return func() tea.Msg {
+			return linkOpenedMsg{url: target, err: browser.Open(target)}
+		}
+	}
+
+	path := target
+	if i := strings.IndexByte(path, '#'); i >= 0 {
+		path = path[:i]
+	}
+	if unescaped, err := url.PathUnescape(path); err == nil {
+		path = unescaped
+	}
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(m.workspaceRoot(), path)
+	}
+
+	if _, err := os.Stat(path); err != nil {
+		m.showMessage("Link target not found: " + target)
+		return nil
+	}
+	if m.editor.GetDocument().IsModified() {
+		m.showMessage("Unsaved changes - save (^S) before following links")
+		return nil
+	}
+
+	m.SetFilename(path)
+	m.showMessage("Opened " + filepath.Base(path))
+	return m.startDecrypt()
+}
+
+// jumpToAnchor moves the cursor to the heading whose GitHub-style slug
+// matches anchor.
+func (m *Model) jumpToAnchor(anchor string) {
+	for _, heading := range m.editor.GetDocument().Headings() {
+		if heading.Anchor == strings.ToLower(anchor) {
+			m.editor.GotoLine(heading.Line + 1)
+			m.showMessage("Jumped to #" + anchor)
+			return
+		}
+	}
+	m.showMessage("No heading #" + anchor)
+}
+
+// jumpToFootnote moves from a footnote reference to its definition, and
+// from a definition back to the first reference.
+func (m *Model) jumpToFootnote(link links.Link) {
+	lines := strings.Split(m.editor.GetDocument().GetText(), "\n")
+	if link.Kind == links.FootnoteRef {
+		if line := links.FootnoteLine(lines, link.Label); line >= 0 {
+			m.editor.GotoLine(line + 1)
+			return
+		}
+		m.showMessage("Undefined footnote [^" + link.Label + "]")
+		return
+	}
+
+	for i, line := range lines {
+		for _, ref := range links.Find(line) {
+			if ref.Kind == links.FootnoteRef && ref.Label == link.Label {
+				m.editor.GotoLine(i + 1)
+				return
+			}
+		}
+	}
+	m.showMessage("Footnote [^" + link.Label + "] is never referenced")
+}
+
+// isWindowsPath keeps "C:\notes\a.md" from looking like a URL scheme.
+func isWindowsPath(target string) bool {
+	return len(target) > 2 && target[1] == ':' && (target[2] == '\\' || target[2] == '/')
+}
+
+func (m *Model) handleLinkOpenedMsg(msg linkOpenedMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case errors.Is(msg.err, browser.ErrUnavailable):
+		m.showMessage("No browser available to open " + msg.url)
+	case msg.err != nil:
+		m.showMessage("Open failed: " + msg.err.Error())
+	}
+	return m, nil
+}
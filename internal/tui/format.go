@@ -0,0 +1,103 @@
+package tui
+
+import (
+	"strconv"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/ofri/mde/internal/config"
+	"github.com/ofri/mde/internal/links"
+	"github.com/ofri/mde/pkg/ast"
+)
+
+func init() {
+	for _, format := range []struct {
+		name, marker, description string
+	}{
+		{"bold", ast.BoldMarker, "Toggle **bold** on the selection or word (Ctrl+B)"},
+		{"italic", ast.ItalicMarker, "Toggle *italic* on the selection or word (Ctrl+I or Alt+I)"},
+		{"inline-code", ast.CodeMarker, "Toggle `code` on the selection or word (Ctrl+`)"},
+	} {
+		registerCommand(Command{
+			Name:        format.name,
+			Description: format.description,
+			Run:         func(m *Model) tea.Cmd { m.editor.ToggleFormat(format.marker); return nil },
+			Markdown:    true,
+		})
+	}
+	registerCommand(Command{
+		Name:        "link",
+		Description: "Turn the selection or word into a link, or remove the link under the cursor (Ctrl+K)",
+		Run:         (*Model).toggleLink,
+		Markdown:    true,
+	})
+	registerCommand(Command{
+		Name:        "reflow-paragraph",
+		Description: "Rewrap the paragraph, or the selected paragraphs, to line_width columns",
+		Run:         (*Model).reflowParagraph,
+		Markdown:    true,
+	})
+}
+
+// reflowParagraph rewraps the paragraph at the cursor to line_width.
+func (m *Model) reflowParagraph() tea.Cmd {
+	width := m.config.LineWidth
+	if width < 1 {
+		width = config.Default().LineWidth
+	}
+	if !m.editor.ReflowParagraph(width) {
+		m.showMessage("No paragraph to reflow here")
+	}
+	return nil
+}
+
+// toggleLink unwraps the inline link under the cursor to its text, or
+// links the selection or word, inline or by reference as link_style says.
+// A URL on the clipboard becomes the target; otherwise the cursor is left
+// where one is typed.
+func (m *Model) toggleLink() tea.Cmd {
+	pos := m.editor.GetCursor().GetBufferPos()
+	if selection := m.editor.GetCursor().GetSelection(); selection != nil && selection.End.Line == selection.Start.Line {
+		pos.Col = min(selection.Start.Col, selection.End.Col)
+	}
+	line := m.editor.GetDocument().GetLine(pos.Line)
+
+	if link, ok := links.At(line, pos.Col); ok && link.Kind == links.Inline && line[link.Start] != '!' {
+		start, end := ast.RuneOffset(line, link.Start), ast.RuneOffset(line, link.End)
+		text := link.Text(line)
+		m.editor.ReplaceRange(ast.Range{Start: ast.BufferPos{Line: pos.Line, Col: start}, End: ast.BufferPos{Line: pos.Line, Col: end}}, text)
+		return nil
+	}
+
+	url := ""
+	if clip := strings.TrimSpace(m.editor.Clipboard()); looksLikeURL(clip) {
+		url = clip
+	}
+	var linked bool
+	if m.config.LinkStyle == "reference" {
+		linked = m.editor.WrapReferenceLink(m.nextReferenceLabel(), url)
+	} else {
+		linked = m.editor.WrapLink(url)
+	}
+	if !linked {
+		m.showMessage("Select text to link")
+	}
+	return nil
+}
+
+// nextReferenceLabel returns the lowest number not yet defined as a link
+// reference in the document.
+func (m *Model) nextReferenceLabel() string {
+	definitions := links.Definitions(strings.Split(m.editor.GetDocument().GetText(), "\n"))
+	for n := 1; ; n++ {
+		if _, ok := definitions[strconv.Itoa(n)]; !ok {
+			return strconv.Itoa(n)
+		}
+	}
+}
+
+// looksLikeURL reports whether text is a single absolute web address
+func looksLikeURL(text string) bool {
+	return (strings.HasPrefix(text, "https://") || strings.HasPrefix(text, "http://")) &&
+		len(text) > len("https://") && !strings.ContainsAny(text, " \t\n")
+}
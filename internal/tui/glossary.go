@@ -0,0 +1,191 @@
+package tui
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+	"unicode/utf8"
+
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/ofri/mde/internal/glossary"
+	"github.com/ofri/mde/internal/lint"
+	"github.com/ofri/mde/internal/plugins/renderers"
+	"github.com/ofri/mde/internal/theme"
+	"github.com/ofri/mde/pkg/ast"
+	"github.com/ofri/mde/pkg/plugin"
+)
+
+// glossaryState caches the glossary file's terms until the file changes,
+// and the glossary they make with the document's own until those change.
+type glossaryState struct {
+	highlight bool
+
+	path    string
+	found   bool
+	modTime time.Time
+	file    []glossary.Term
+	err     error
+
+	doc      *ast.Document
+	revision int
+	own      []glossary.Term
+	merged   *glossary.Glossary
+}
+
+func init() {
+	registerCommand(Command{
+		Name:        "highlight-glossary",
+		Description: "Toggle highlighting the terms the glossary and the document define",
+		Run:         (*Model).toggleGlossaryHighlight,
+		Markdown:    true,
+	})
+}
+
+// glossaryPath is where the glossary setting points for the open
+// document, or "" when it is unset.
+func (m *Model) glossaryPath() string {
+	path := m.config.Glossary
+	if path == "" || filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(m.workspaceRoot(), path)
+}
+
+// glossary returns the terms of the glossary file and of the document,
+// the document's replacing the file's. It returns nil for plain text, and
+// unless a glossary is set or highlighted, sparing large documents the
+// search for terms of their own.
+func (m *Model) glossary() *glossary.Glossary {
+	if m.plainText() || (m.config.Glossary == "" && !m.glossaryTerms.highlight) {
+		return nil
+	}
+	state := &m.glossaryTerms
+	stale := false
+
+	path := m.glossaryPath()
+	var modTime time.Time
+	var err error
+	if path != "" {
+		var info os.FileInfo
+		if info, err = os.Stat(path); err == nil {
+			modTime = info.ModTime()
+		}
+	}
+	if found := path != "" && err == nil; path != state.path || found != state.found || !modTime.Equal(state.modTime) {
+		state.path, state.found, state.modTime, state.file, state.err = path, found, modTime, nil, err
+		if found {
+			state.file, state.err = glossary.Load(path)
+		}
+		stale = true
+	}
+
+	doc := m.editor.GetDocument()
+	if doc != state.doc || doc.Revision() != state.revision {
+		own := glossary.Parse(doc.GetText())
+		stale = stale || !slices.Equal(own, state.own)
+		state.doc, state.revision, state.own = doc, doc.Revision(), own
+	}
+	if stale || state.merged == nil {
+		state.merged = glossary.New(append(slices.Clone(state.file), state.own...))
+	}
+	return state.merged
+}
+
+func (m *Model) toggleGlossaryHighlight() tea.Cmd {
+	state := &m.glossaryTerms
+	state.highlight = !state.highlight
+	switch count := m.glossary().Len(); {
+	case !state.highlight:
+		m.showMessage("Glossary highlighting off")
+	case state.err != nil:
+		m.showMessage("Glossary: " + state.err.Error())
+	case count == 0:
+		m.showMessage("No glossary terms (set glossary, or define *[TERM]: in the document)")
+	default:
+		m.showMessage("Highlighting " + plural(count, "glossary term"))
+	}
+	return nil
+}
+
+// markGlossaryTerms styles the defined terms on screen when highlighting
+// is on.
+func (m *Model) markGlossaryTerms(lines []plugin.RenderedLine) {
+	if !m.glossaryTerms.highlight {
+		return
+	}
+	g := m.glossary()
+	if g.Len() == 0 {
+		return
+	}
+	doc := m.editor.GetDocument()
+	viewport := m.editor.GetViewport()
+	top, left := viewport.GetTopLine(), viewport.GetLeftColumn()
+	prefix := 0
+	if m.editor.ShowLineNumbers() {
+		prefix = viewport.GetLineNumberWidth()
+	}
+	style := theme.Resolved().Style(theme.GlossaryTerm)
+
+	fences := doc.FencedBlocks()
+	for i := range lines {
+		line := top + i
+		if line >= doc.LineCount() {
+			break
+		}
+		for len(fences) > 0 && fences[0].EndLine < line {
+			fences = fences[1:]
+		}
+		if len(fences) > 0 && fences[0].Contains(line) {
+			continue
+		}
+		text := doc.GetLine(line)
+		length := utf8.RuneCountInString(lines[i].Content)
+		for _, match := range g.Find(text) {
+			from := max(viewport.DisplayColumn(text, ast.RuneOffset(text, match.Start))-left, 0) + prefix
+			to := min(max(viewport.DisplayColumn(text, ast.RuneOffset(text, match.End))-left, 0)+prefix, length)
+			lines[i].Styles = renderers.Overlay(lines[i].Styles, from, to, style)
+		}
+	}
+}
+
+// glossaryHover defines the term under rune column col of line, or
+// returns "".
+func (m *Model) glossaryHover(line string, col int) string {
+	match, ok := m.glossary().At(line, col)
+	if !ok {
+		return ""
+	}
+	return match.Term.Name + ": " + match.Term.Definition
+}
+
+// glossaryAtCursor defines the term under the cursor for the status line,
+// or returns "".
+func (m *Model) glossaryAtCursor() string {
+	if m.glossary().Len() == 0 {
+		return ""
+	}
+	pos := m.editor.GetCursor().GetBufferPos()
+	line := m.editor.GetDocument().GetLine(pos.Line)
+	if text := m.glossaryHover(line, pos.Col); text != "" {
+		return text
+	}
+	if pos.Col > 0 {
+		return m.glossaryHover(line, pos.Col-1) // Just after the term
+	}
+	return ""
+}
+
+// lintDiagnostics returns lint's findings in the document, with its
+// undefined acronyms when a glossary is set, less the rules turned off.
+func (m *Model) lintDiagnostics() []lint.Diagnostic {
+	if m.plainText() {
+		return nil
+	}
+	doc := m.editor.GetDocument()
+	diagnostics := lint.Check(doc)
+	if m.config.Glossary != "" {
+		diagnostics = append(diagnostics, m.glossary().Check(doc)...)
+	}
+	return lint.Filter(diagnostics, m.config.Lint)
+}
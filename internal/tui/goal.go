@@ -0,0 +1,70 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ofri/mde/internal/session"
+	"github.com/ofri/mde/pkg/ast"
+)
+
+// goalBarWidth is the number of cells in the status bar progress meter
+const goalBarWidth = 10
+
+// goalState tracks progress toward word_goal for the current document.
+type goalState struct {
+	doc      *ast.Document // The document baseline and reached refer to
+	baseline int           // Words in doc when today's first session began
+	words    int           // Words counted toward the goal
+	reached  bool          // Whether the goal has been celebrated
+	counter  ast.SectionCounter
+}
+
+// trackWordGoal recounts the document's words and celebrates the moment
+// the goal is reached. A document that already meets it on opening is not
+// celebrated.
+func (m *Model) trackWordGoal() {
+	if m.config.WordGoal <= 0 {
+		return
+	}
+	doc := m.editor.GetDocument()
+	total := m.goal.counter.Total(doc).Words
+
+	if m.goal.doc != doc {
+		m.goal = goalState{doc: doc, baseline: total, counter: m.goal.counter}
+		if m.config.WordGoalScope == "session" {
+			m.goal.baseline = session.Baseline(doc.GetFilename(), time.Now(), total)
+		}
+		m.goal.reached = m.goalWords(total) >= m.config.WordGoal
+	}
+
+	m.goal.words = m.goalWords(total)
+	if !m.goal.reached && m.goal.words >= m.config.WordGoal {
+		m.goal.reached = true
+		m.showMessage(fmt.Sprintf("Goal reached: %d words!", m.goal.words))
+	}
+}
+
+// goalWords is the part of total that counts toward the goal
+func (m *Model) goalWords(total int) int {
+	if m.config.WordGoalScope == "session" {
+		return max(total-m.goal.baseline, 0)
+	}
+	return total
+}
+
+// goalProgress formats progress for the status bar: the count, the goal
+// and a meter (a percentage for screen readers), or "" without a goal.
+func (m *Model) goalProgress() string {
+	goal := m.config.WordGoal
+	if goal <= 0 || m.goal.doc != m.editor.GetDocument() {
+		return ""
+	}
+	if m.config.ScreenReader {
+		return fmt.Sprintf("%d/%d words, %d%%", m.goal.words, goal, m.goal.words*100/goal)
+	}
+	filled := min(m.goal.words*goalBarWidth/goal, goalBarWidth)
+	return fmt.Sprintf("%d/%d words %s%s", m.goal.words, goal,
+		strings.Repeat("█", filled), strings.Repeat("░", goalBarWidth-filled))
+}
@@ -0,0 +1,157 @@
+package tui
+
+import (
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/ofri/mde/pkg/ast"
+)
+
+func init() {
+	registerCommand(Command{
+		Name:        "promote-heading",
+		Description: "Raise the heading on the cursor line one level (## → #)",
+		Run:         (*Model).promoteHeading,
+		Markdown:    true,
+	})
+	registerCommand(Command{
+		Name:        "demote-heading",
+		Description: "Lower the heading on the cursor line one level (# → ##)",
+		Run:         (*Model).demoteHeading,
+		Markdown:    true,
+	})
+	registerCommand(Command{
+		Name:        "promote-section",
+		Description: "Raise the current section's heading and all its subheadings one level",
+		Run:         (*Model).promoteSection,
+		Markdown:    true,
+	})
+	registerCommand(Command{
+		Name:        "demote-section",
+		Description: "Lower the current section's heading and all its subheadings one level",
+		Run:         (*Model).demoteSection,
+		Markdown:    true,
+	})
+	registerCommand(Command{
+		Name:        "move-section-up",
+		Description: "Move the current section above its previous sibling",
+		Run:         (*Model).moveSectionUp,
+		Markdown:    true,
+	})
+	registerCommand(Command{
+		Name:        "move-section-down",
+		Description: "Move the current section below its next sibling",
+		Run:         (*Model).moveSectionDown,
+		Markdown:    true,
+	})
+	registerCommand(Command{
+		Name:        "number-headings",
+		Description: "Number the headings hierarchically (2.3.1), or bring their numbers up to date",
+		Run:         (*Model).numberHeadings,
+		Markdown:    true,
+	})
+	registerCommand(Command{
+		Name:        "unnumber-headings",
+		Description: "Remove the numbers before the headings",
+		Run:         (*Model).unnumberHeadings,
+		Markdown:    true,
+	})
+}
+
+func (m *Model) promoteHeading() tea.Cmd { return m.shiftHeading(-1) }
+func (m *Model) demoteHeading() tea.Cmd  { return m.shiftHeading(1) }
+func (m *Model) promoteSection() tea.Cmd { return m.shiftSection(-1) }
+func (m *Model) demoteSection() tea.Cmd  { return m.shiftSection(1) }
+
+func (m *Model) shiftHeading(delta int) tea.Cmd {
+	doc := m.editor.GetDocument()
+	if doc.HeadingLevel(m.editor.GetCursor().GetBufferPos().Line) == 0 {
+		m.showMessage("No heading on this line")
+		return nil
+	}
+	if !m.editor.ShiftHeading(delta) {
+		m.showMessage(headingLimitMessage(delta))
+	}
+	return nil
+}
+
+func (m *Model) shiftSection(delta int) tea.Cmd {
+	if _, ok := m.editor.GetDocument().SectionAt(m.editor.GetCursor().GetBufferPos().Line); !ok {
+		m.showMessage("No heading above cursor")
+		return nil
+	}
+	if !m.editor.ShiftSection(delta) {
+		m.showMessage(headingLimitMessage(delta))
+	}
+	return nil
+}
+
+func (m *Model) moveSectionUp() tea.Cmd   { return m.moveSection(-1) }
+func (m *Model) moveSectionDown() tea.Cmd { return m.moveSection(1) }
+
+func (m *Model) moveSection(direction int) tea.Cmd {
+	if _, ok := m.editor.GetDocument().SectionAt(m.editor.GetCursor().GetBufferPos().Line); !ok {
+		m.showMessage("No heading above cursor")
+		return nil
+	}
+	if !m.editor.MoveSection(direction) {
+		m.showMessage("No sibling section to swap with")
+	}
+	return nil
+}
+
+// headingLimitMessage explains a shift refused at the outermost levels.
+func headingLimitMessage(delta int) string {
+	if delta < 0 {
+		return "Already at heading level 1"
+	}
+	return "Already at heading level 6"
+}
+
+func (m *Model) numberHeadings() tea.Cmd {
+	if len(m.editor.GetDocument().Headings()) == 0 {
+		m.showMessage("No headings to number")
+		return nil
+	}
+	if changed := m.editor.NumberHeadings(-1); changed > 0 {
+		m.showMessage("Headings numbered")
+	} else {
+		m.showMessage("Heading numbers already in order")
+	}
+	return nil
+}
+
+func (m *Model) unnumberHeadings() tea.Cmd {
+	if m.config.NumberHeadings {
+		m.showMessage("number_headings is on and would number them again")
+		return nil
+	}
+	if changed := m.editor.UnnumberHeadings(); changed > 0 {
+		m.showMessage("Heading numbers removed")
+	} else {
+		m.showMessage("No heading numbers to remove")
+	}
+	return nil
+}
+
+// numberingState is where heading numbers were last brought up to date.
+type numberingState struct {
+	doc      *ast.Document
+	revision int
+	line     int
+}
+
+// autoNumberHeadings applies the number_headings setting whenever the text
+// changed or the cursor left its line, leaving a new heading unnumbered
+// while the cursor is still on it.
+func (m *Model) autoNumberHeadings() {
+	if !m.config.NumberHeadings || m.plainText() || m.readOnly {
+		return
+	}
+	doc := m.editor.GetDocument()
+	line := m.editor.GetCursor().GetBufferPos().Line
+	state := numberingState{doc: doc, revision: doc.Revision(), line: line}
+	if m.numbering == state {
+		return
+	}
+	m.editor.NumberHeadings(line)
+	m.numbering = numberingState{doc: doc, revision: doc.Revision(), line: line}
+}
@@ -0,0 +1,191 @@
+package tui
+
+import (
+	"fmt"
+	"path/filepath"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/ofri/mde/internal/assets"
+	"github.com/ofri/mde/internal/history"
+	"github.com/ofri/mde/pkg/ast"
+)
+
+// snapshotState is the history picker: the snapshots saved of the open
+// file, the one picked, and its diff against the text while that is
+// shown.
+type snapshotState struct {
+	list     []history.Snapshot
+	selected int
+	diff     *DiffView
+}
+
+func init() {
+	registerCommand(Command{
+		Name:        "history",
+		Description: "Browse the snapshots taken of the file at each save, to compare one with the text or restore it",
+		Run:         (*Model).promptHistory,
+	})
+}
+
+// snapshotOnSave returns the function that records the text as it is
+// about to be saved to filename in its history, or nil if the file keeps
+// none: history is off, or the file is remote or encrypted, whose plain
+// text must not end up on disk. The function may run off the update
+// goroutine.
+func (m *Model) snapshotOnSave(filename string) func() error {
+	dir := m.config.HistoryDir
+	if dir == "" || remoteFile(filename) || m.encrypted(filename) {
+		return nil
+	}
+	content := []byte(m.editor.GetDocument().GetText())
+	limits := m.historyLimits()
+	return func() error {
+		_, err := history.Save(dir, filename, content, limits)
+		return err
+	}
+}
+
+// historyLimits is how much history each file keeps.
+func (m *Model) historyLimits() history.Limits {
+	return history.Limits{Count: m.config.HistorySnapshots, Bytes: int64(m.config.HistoryMaxMB) << 20}
+}
+
+// promptHistory opens the history picker on the latest snapshot.
+func (m *Model) promptHistory() tea.Cmd {
+	filename := m.editor.GetDocument().GetFilename()
+	switch {
+	case m.config.HistoryDir == "":
+		m.showMessage("History is off (set history_dir)")
+		return nil
+	case filename == "" || remoteFile(filename) || m.encrypted(filename):
+		m.showMessage("Only local, unencrypted files keep a history")
+		return nil
+	}
+	snapshots, err := history.List(m.config.HistoryDir, filename)
+	if err != nil {
+		m.showMessage("History: " + err.Error())
+		return nil
+	}
+	if len(snapshots) == 0 {
+		m.showMessage("No snapshots of " + filepath.Base(filename) + " yet: one is taken at each save")
+		return nil
+	}
+	m.snapshots = snapshotState{list: snapshots}
+	m.mode = ModeHistory
+	return nil
+}
+
+// handleHistoryKey moves through the snapshots or opens the diff against
+// the one picked.
+func (m *Model) handleHistoryKey(key string) {
+	state := &m.snapshots
+	switch key {
+	case "up", "k":
+		state.selected = max(state.selected-1, 0)
+	case "down", "j":
+		state.selected = min(state.selected+1, len(state.list)-1)
+	case "home", "g":
+		state.selected = 0
+	case "end", "G":
+		state.selected = len(state.list) - 1
+	case "d", "D":
+		m.diffSnapshot()
+	}
+}
+
+// diffSnapshot shows the snapshot picked beside the text, full screen.
+func (m *Model) diffSnapshot() {
+	snapshot := m.snapshots.list[m.snapshots.selected]
+	content, err := snapshot.Read()
+	if err != nil {
+		m.showMessage("Reading snapshot: " + err.Error())
+		return
+	}
+	// The names end with the file's, whose extension picks the parser
+	name := filepath.Base(m.editor.GetDocument().GetFilename())
+	view, err := NewDiffView(snapshotTime(snapshot.Time)+"  "+name, string(content), "Now  "+name, m.editor.GetDocument().GetText())
+	if err != nil {
+		m.showMessage("Diff: " + err.Error())
+		return
+	}
+	view.Update(tea.WindowSizeMsg{Width: m.width, Height: m.height})
+	m.snapshots.diff = view
+}
+
+// handleSnapshotDiffKey scrolls the diff, or closes it back to the
+// picker.
+func (m *Model) handleSnapshotDiffKey(msg tea.KeyPressMsg) {
+	switch msg.String() {
+	case "q", "esc", "ctrl+q", "ctrl+c":
+		m.snapshots.diff = nil
+	default:
+		m.snapshots.diff.Update(msg)
+	}
+}
+
+// restoreSnapshot replaces the text with the snapshot picked, keeping the
+// text it replaces as the latest snapshot, to restore in turn; the file
+// itself changes only once saved.
+func (m *Model) restoreSnapshot() (tea.Model, tea.Cmd) {
+	snapshot := m.snapshots.list[m.snapshots.selected]
+	m.snapshots = snapshotState{}
+	m.mode = ModeNormal
+	m.input = ""
+
+	content, err := snapshot.Read()
+	if err != nil {
+		m.showMessage("Reading snapshot: " + err.Error())
+		return m, nil
+	}
+	doc := m.editor.GetDocument()
+	if doc.GetText() == string(content) {
+		m.showMessage("The text is the same as the snapshot of " + snapshotTime(snapshot.Time))
+		return m, nil
+	}
+	if _, err := history.Save(m.config.HistoryDir, doc.GetFilename(), []byte(doc.GetText()), m.historyLimits()); err != nil {
+		m.showMessage("Not restored: keeping the text first failed: " + err.Error())
+		return m, nil
+	}
+	cursor := m.editor.GetCursor()
+	pos := cursor.GetBufferPos()
+	last := doc.LineCount() - 1
+	m.editor.ReplaceRange(ast.Range{End: ast.BufferPos{Line: last, Col: doc.GetLineLength(last)}}, string(content))
+	cursor.SetBufferPos(doc.ValidatePosition(pos))
+	m.showMessage("Restored the snapshot of " + snapshotTime(snapshot.Time) + " (the text before it is the latest snapshot; save to keep the restore)")
+	return m, nil
+}
+
+// historyPrompt is the help bar of the history picker.
+func (m *Model) historyPrompt() string {
+	return fmt.Sprintf("Snapshot %d of %d | ↑/↓ Pick  Enter: Restore  d: Diff | Esc: Cancel", m.snapshots.selected+1, len(m.snapshots.list))
+}
+
+// overlayHistoryPanel lists the snapshots down the right edge of content
+// while the picker is open, the latest first.
+func (m *Model) overlayHistoryPanel(content string) string {
+	if m.mode != ModeHistory {
+		return content
+	}
+	return m.overlayPanel(content, func(height int) []string {
+		rows := make([]string, len(m.snapshots.list))
+		for i, snapshot := range m.snapshots.list {
+			rows[i] = fmt.Sprintf("%-15s %9s", snapshotTime(snapshot.Time), assets.FormatSize(snapshot.Size))
+		}
+		header := "History of " + filepath.Base(m.editor.GetDocument().GetFilename())
+		return renderPanel(header, rows, m.snapshots.selected, height)
+	})
+}
+
+// snapshotTime formats when a snapshot was taken: the time alone today,
+// the date too before.
+func snapshotTime(t time.Time) string {
+	now := time.Now()
+	if y, m, d := t.Date(); y == now.Year() && m == now.Month() && d == now.Day() {
+		return t.Format("15:04:05")
+	}
+	if t.Year() == now.Year() {
+		return t.Format("Jan 2 15:04")
+	}
+	return t.Format("Jan 2 2006")
+}
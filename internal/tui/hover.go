@@ -0,0 +1,89 @@
+package tui
+
+import (
+	"context"
+	"strings"
+
+	"github.com/ofri/mde/internal/links"
+	"github.com/ofri/mde/internal/wiki"
+	"github.com/ofri/mde/pkg/ast"
+	"github.com/ofri/mde/pkg/plugin"
+)
+
+// hoverText returns the tooltip for the screen cell (x, y): a link's
+// destination, a footnote's text, a cited reference or a glossary term's
+// definition, or "" when the cell holds none.
+// The line is hit-tested against freshly computed tokens since the cached
+// ones are only refreshed on load.
+func (m *Model) hoverText(x, y int) string {
+	if y < 0 || y >= m.GetContentHeight() || x < m.textMargin() {
+		return ""
+	}
+	pos := m.screenToBufferSafe(y, x)
+	if pos.Line != m.editor.GetViewport().GetTopLine()+y {
+		return "" // Below the last line
+	}
+
+	doc := m.editor.GetDocument()
+	line := doc.GetLine(pos.Line)
+	token, ok := tokenAt(doc.GetFilename(), line, pos.Col)
+	if !ok {
+		return m.glossaryHover(line, pos.Col)
+	}
+
+	switch token.Kind() {
+	case ast.TokenCitation:
+		return m.citationHover(line, pos.Col)
+	case ast.TokenWikiLink:
+		if link, ok := wiki.LinkAt(line, pos.Col); ok {
+			return "[[" + link.Target + "]]"
+		}
+	case ast.TokenLinkText, ast.TokenLinkURL, ast.TokenLinkReference, ast.TokenLinkDefinition,
+		ast.TokenAutolink, ast.TokenFootnoteRef:
+		link, ok := links.At(line, pos.Col)
+		if !ok {
+			return ""
+		}
+		lines := strings.Split(doc.GetText(), "\n")
+		switch link.Kind {
+		case links.Reference:
+			if url, ok := links.Definitions(lines)[links.NormalizeLabel(link.Label)]; ok {
+				return url
+			}
+			return "Undefined link reference [" + link.Label + "]"
+		case links.FootnoteRef:
+			if text, ok := links.Footnotes(lines)[link.Label]; ok {
+				return "[^" + link.Label + "]: " + text
+			}
+			return "Undefined footnote [^" + link.Label + "]"
+		default:
+			return link.URL
+		}
+	}
+	return m.glossaryHover(line, pos.Col)
+}
+
+// tokenAt returns the innermost syntax token covering rune column col,
+// as parsed for a file named filename.
+func tokenAt(filename, line string, col int) (ast.Token, bool) {
+	parser, err := plugin.GetRegistry().GetParserForFile(filename)
+	if err != nil {
+		return ast.Token{}, false
+	}
+	tokens, err := parser.GetSyntaxHighlighting(context.Background(), line)
+	if err != nil {
+		return ast.Token{}, false
+	}
+
+	var hit ast.Token
+	found := false
+	for _, token := range tokens {
+		if col < token.Start() || col >= token.End() {
+			continue
+		}
+		if !found || token.End()-token.Start() < hit.End()-hit.Start() {
+			hit, found = token, true
+		}
+	}
+	return hit, found
+}
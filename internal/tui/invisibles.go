@@ -0,0 +1,42 @@
+package tui
+
+import (
+	tea "github.com/charmbracelet/bubbletea/v2"
+)
+
+func init() {
+	registerCommand(Command{
+		Name:        "show-invisibles",
+		Description: "Toggle markers for tabs, trailing spaces and non-breaking spaces",
+		Run:         (*Model).toggleInvisibles,
+	})
+	registerCommand(Command{
+		Name:        "indent-guides",
+		Description: "Toggle vertical guides at each indentation level",
+		Run:         (*Model).toggleIndentGuides,
+	})
+}
+
+// toggleInvisibles flips whitespace markers for this session; the
+// show_invisibles setting chooses the initial state.
+func (m *Model) toggleInvisibles() tea.Cmd {
+	m.config.ShowInvisibles = !m.config.ShowInvisibles
+	if m.config.ShowInvisibles {
+		m.showMessage("Invisibles shown")
+	} else {
+		m.showMessage("Invisibles hidden")
+	}
+	return nil
+}
+
+// toggleIndentGuides flips indentation guides for this session; the
+// indent_guides setting chooses the initial state.
+func (m *Model) toggleIndentGuides() tea.Cmd {
+	m.config.IndentGuides = !m.config.IndentGuides
+	if m.config.IndentGuides {
+		m.showMessage("Indent guides shown")
+	} else {
+		m.showMessage("Indent guides hidden")
+	}
+	return nil
+}
@@ -0,0 +1,98 @@
+package tui
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/ofri/mde/internal/links"
+	"github.com/ofri/mde/pkg/ast"
+)
+
+// titleTimeout bounds fetching a page title; offline, the link falls back
+// to the URL as its text.
+const titleTimeout = 5 * time.Second
+
+// titleFetchedMsg reports the title fetched for a URL.
+type titleFetchedMsg struct {
+	doc   *ast.Document
+	url   string
+	title string
+	err   error
+}
+
+var linkTextEscaper = strings.NewReplacer(`\`, `\\`, "[", `\[`, "]", `\]`)
+
+func init() {
+	registerCommand(Command{
+		Name:        "insert-link-title",
+		Description: "Link the URL under the cursor or on the clipboard with its page title as text",
+		Run:         (*Model).insertLinkTitle,
+		Markdown:    true,
+	})
+}
+
+// urlAtCursor returns the bare URL or autolink under the cursor, with its
+// rune range on the line.
+func (m *Model) urlAtCursor() (string, ast.Range, bool) {
+	pos := m.editor.GetCursor().GetBufferPos()
+	line := m.editor.GetDocument().GetLine(pos.Line)
+	link, ok := links.At(line, pos.Col)
+	if !ok || (link.Kind != links.Bare && link.Kind != links.Autolink) || !looksLikeURL(link.URL) {
+		return "", ast.Range{}, false
+	}
+	return link.URL, ast.Range{
+		Start: ast.BufferPos{Line: pos.Line, Col: ast.RuneOffset(line, link.Start)},
+		End:   ast.BufferPos{Line: pos.Line, Col: ast.RuneOffset(line, link.End)},
+	}, true
+}
+
+// insertLinkTitle fetches the page title of the URL under the cursor, or
+// else on the clipboard, in the background. handleTitleFetchedMsg inserts
+// the link.
+func (m *Model) insertLinkTitle() tea.Cmd {
+	url, _, ok := m.urlAtCursor()
+	if !ok {
+		url = strings.TrimSpace(m.editor.Clipboard())
+		if !looksLikeURL(url) {
+			m.showMessage("No URL under cursor or on clipboard")
+			return nil
+		}
+	}
+
+	doc := m.editor.GetDocument()
+	m.showMessage("Fetching title of " + url + "...")
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), titleTimeout)
+		defer cancel()
+		title, err := links.FetchTitle(ctx, url)
+		return titleFetchedMsg{doc: doc, url: url, title: title, err: err}
+	}
+}
+
+// handleTitleFetchedMsg replaces the URL if it is still under the cursor,
+// or inserts the link at the cursor. Without a title the URL is the text.
+func (m *Model) handleTitleFetchedMsg(msg titleFetchedMsg) (tea.Model, tea.Cmd) {
+	if msg.doc != m.editor.GetDocument() {
+		return m, nil // The buffer was replaced meanwhile
+	}
+
+	text := msg.title
+	switch {
+	case msg.err != nil:
+		text = msg.url
+		m.showMessage("No title fetched: " + msg.err.Error())
+	case text == "":
+		text = msg.url
+		m.showMessage("Page has no title")
+	}
+	link := "[" + linkTextEscaper.Replace(text) + "](" + msg.url + ")"
+
+	if url, r, ok := m.urlAtCursor(); ok && url == msg.url {
+		m.editor.ReplaceRange(r, link)
+	} else {
+		m.editor.InsertText(link)
+	}
+	return m, nil
+}
@@ -0,0 +1,280 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"time"
+	"unicode/utf8"
+
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/ofri/mde/internal/linkcheck"
+	"github.com/ofri/mde/internal/lint"
+	"github.com/ofri/mde/internal/lsp"
+	"github.com/ofri/mde/internal/plugins/renderers"
+	"github.com/ofri/mde/internal/theme"
+	"github.com/ofri/mde/pkg/ast"
+	"github.com/ofri/mde/pkg/plugin"
+)
+
+// linkCheckState holds the broken links last found in a document. Each
+// remembers the text of its line, so it can follow the line as the
+// document is edited and be dropped once the line changes.
+type linkCheckState struct {
+	running     bool
+	doc         *ast.Document
+	revision    int
+	diagnostics []lint.Diagnostic
+	lines       []string
+}
+
+// linksCheckedMsg reports the broken links among those of doc at
+// revision.
+type linksCheckedMsg struct {
+	doc         *ast.Document
+	revision    int
+	checked     int
+	lines       []string
+	diagnostics []lint.Diagnostic
+}
+
+func init() {
+	registerCommand(Command{
+		Name:        "check-links",
+		Description: "Check that the files relative links point to exist, underlining broken ones",
+		Run:         func(m *Model) tea.Cmd { return m.checkLinks(false) },
+		Markdown:    true,
+	})
+	registerCommand(Command{
+		Name:        "check-links-online",
+		Description: "Check relative links and request every web link, underlining broken ones",
+		Run:         func(m *Model) tea.Cmd { return m.checkLinks(true) },
+		Markdown:    true,
+	})
+	registerCommand(Command{
+		Name:        "next-diagnostic",
+		Description: "Jump to the next problem: a lint finding, a language server diagnostic or a broken link (F8)",
+		Run:         func(m *Model) tea.Cmd { m.jumpToDiagnostic(1); return nil },
+	})
+	registerCommand(Command{
+		Name:        "previous-diagnostic",
+		Description: "Jump to the previous problem (Shift+F8)",
+		Run:         func(m *Model) tea.Cmd { m.jumpToDiagnostic(-1); return nil },
+	})
+}
+
+// checkLinks checks the document's links in the background, requesting
+// web pages too when online is set.
+func (m *Model) checkLinks(online bool) tea.Cmd {
+	if m.linkCheck.running {
+		m.showMessage("Links are still being checked")
+		return nil
+	}
+	doc := m.editor.GetDocument()
+	targets := linkcheck.Targets(doc)
+	lines := make([]string, len(targets))
+	for i, target := range targets {
+		lines[i] = doc.GetLine(target.Range.Start.Line)
+	}
+	opts := linkcheck.Options{
+		Dir:         m.workspaceRoot(),
+		External:    online,
+		Concurrency: m.config.LinkCheckConcurrency,
+		Timeout:     time.Duration(max(m.config.LinkCheckTimeout, 1)) * time.Second,
+	}
+	if abs, err := filepath.Abs(opts.Dir); err == nil {
+		opts.Dir = abs
+	}
+	revision := doc.Revision()
+
+	m.linkCheck.running = true
+	m.showMessage(fmt.Sprintf("Checking %s...", plural(len(targets), "link")))
+	return func() tea.Msg {
+		diagnostics := linkcheck.Check(context.Background(), targets, opts)
+		broken := make([]string, len(diagnostics))
+		for i, d := range diagnostics {
+			for j, target := range targets {
+				if target.Range == d.Range {
+					broken[i] = lines[j]
+					break
+				}
+			}
+		}
+		return linksCheckedMsg{doc: doc, revision: revision, checked: len(targets), lines: broken, diagnostics: diagnostics}
+	}
+}
+
+func (m *Model) handleLinksChecked(msg linksCheckedMsg) (tea.Model, tea.Cmd) {
+	m.linkCheck = linkCheckState{
+		doc:         msg.doc,
+		revision:    msg.revision,
+		diagnostics: msg.diagnostics,
+		lines:       msg.lines,
+	}
+	if msg.doc != m.editor.GetDocument() {
+		return m, nil
+	}
+	switch broken := len(m.brokenLinks()); {
+	case msg.checked == 0:
+		m.showMessage("No links to check")
+	case broken == 0:
+		m.showMessage(fmt.Sprintf("All %s work", plural(msg.checked, "link")))
+	default:
+		m.showMessage(fmt.Sprintf("%s of %d broken (F8: next)", plural(broken, "link"), msg.checked))
+	}
+	return m, nil
+}
+
+// brokenLinks returns the broken links found in the open document, moved
+// along with their lines as the text was edited since the check. A link
+// whose line no longer reads as it did is dropped.
+func (m *Model) brokenLinks() []lint.Diagnostic {
+	state := &m.linkCheck
+	doc := m.editor.GetDocument()
+	if state.doc != doc {
+		return nil
+	}
+	if state.revision == doc.Revision() {
+		return state.diagnostics
+	}
+
+	var diagnostics []lint.Diagnostic
+	var lines []string
+	for i, d := range state.diagnostics {
+		line, ok := nearestLine(doc, d.Range.Start.Line, state.lines[i])
+		if !ok {
+			continue
+		}
+		d.Range.End.Line += line - d.Range.Start.Line
+		d.Range.Start.Line = line
+		diagnostics = append(diagnostics, d)
+		lines = append(lines, state.lines[i])
+	}
+	state.diagnostics, state.lines, state.revision = diagnostics, lines, doc.Revision()
+	return diagnostics
+}
+
+// nearestLine returns the line reading text closest to line.
+func nearestLine(doc *ast.Document, line int, text string) (int, bool) {
+	count := doc.LineCount()
+	for distance := 0; distance < count; distance++ {
+		if up := line - distance; up >= 0 && up < count && doc.GetLine(up) == text {
+			return up, true
+		}
+		if down := line + distance; down >= 0 && down < count && doc.GetLine(down) == text {
+			return down, true
+		}
+		if line-distance < 0 && line+distance >= count {
+			break
+		}
+	}
+	return 0, false
+}
+
+// markBrokenLinks underlines the broken links in the diagnostic styles.
+func (m *Model) markBrokenLinks(lines []plugin.RenderedLine) {
+	broken := m.brokenLinks()
+	if len(broken) == 0 {
+		return
+	}
+	doc := m.editor.GetDocument()
+	viewport := m.editor.GetViewport()
+	top, left := viewport.GetTopLine(), viewport.GetLeftColumn()
+	prefix := 0
+	if m.editor.ShowLineNumbers() {
+		prefix = viewport.GetLineNumberWidth()
+	}
+
+	for _, d := range broken {
+		i := d.Range.Start.Line - top
+		if i < 0 || i >= len(lines) {
+			continue
+		}
+		style := theme.Resolved().Style(theme.DiagnosticError)
+		if d.Severity == lint.Warning {
+			style = theme.Resolved().Style(theme.DiagnosticWarn)
+		}
+		text := doc.GetLine(d.Range.Start.Line)
+		length := utf8.RuneCountInString(lines[i].Content)
+		from := max(viewport.DisplayColumn(text, d.Range.Start.Col)-left, 0) + prefix
+		to := min(max(viewport.DisplayColumn(text, d.Range.End.Col)-left, 0)+prefix, length)
+		lines[i].Styles = renderers.Overlay(lines[i].Styles, from, to, style)
+	}
+}
+
+// brokenLinkAtCursor returns the broken link on the cursor line for the
+// status line, and whether the cursor is within it.
+func (m *Model) brokenLinkAtCursor() (string, bool) {
+	pos := m.editor.GetCursor().GetBufferPos()
+	found := ""
+	for _, d := range m.brokenLinks() {
+		if d.Range.Start.Line != pos.Line {
+			continue
+		}
+		message := d.Severity.String() + ": " + d.Message
+		if pos.Col >= d.Range.Start.Col && pos.Col <= d.Range.End.Col {
+			return message, true
+		}
+		if found == "" {
+			found = message
+		}
+	}
+	return found, false
+}
+
+// jumpToDiagnostic moves the cursor to the start of the next (direction
+// 1) or previous (-1) problem in the document, wrapping around, and shows
+// what the problem is.
+func (m *Model) jumpToDiagnostic(direction int) {
+	type problem struct {
+		start   ast.BufferPos
+		message string
+	}
+	doc := m.editor.GetDocument()
+	var problems []problem
+	for _, d := range m.lintDiagnostics() {
+		problems = append(problems, problem{d.Range.Start, d.Severity.String() + ": " + d.Message})
+	}
+	if m.lsp.doc == doc {
+		for _, d := range m.lsp.diagnostics {
+			message := d.Message
+			if d.Severity != 0 {
+				message = d.Severity.String() + ": " + message
+			}
+			problems = append(problems, problem{lsp.BufferPos(doc, d.Range.Start), message})
+		}
+	}
+	for _, d := range m.brokenLinks() {
+		problems = append(problems, problem{d.Range.Start, d.Severity.String() + ": " + d.Message})
+	}
+	if len(problems) == 0 {
+		m.showMessage("No problems found")
+		return
+	}
+	sort.SliceStable(problems, func(i, j int) bool { return problems[i].start.Before(problems[j].start) })
+
+	cursor := m.editor.GetCursor()
+	pos := cursor.GetBufferPos()
+	target := problems[0]
+	if direction < 0 {
+		target = problems[len(problems)-1]
+		for i := len(problems) - 1; i >= 0; i-- {
+			if problems[i].start.Before(pos) {
+				target = problems[i]
+				break
+			}
+		}
+	} else {
+		for _, p := range problems {
+			if pos.Before(p.start) {
+				target = p
+				break
+			}
+		}
+	}
+	cursor.ClearSelection()
+	cursor.SetBufferPos(doc.ValidatePosition(target.start))
+	m.editor.AdjustViewPort()
+	m.showMessage(target.message)
+}
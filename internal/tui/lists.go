@@ -0,0 +1,42 @@
+package tui
+
+import (
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/ofri/mde/pkg/ast"
+)
+
+func init() {
+	registerCommand(Command{
+		Name:        "renumber-list",
+		Description: "Renumber the ordered list under the cursor, keeping its nesting",
+		Run:         (*Model).renumberList,
+		Markdown:    true,
+	})
+}
+
+func (m *Model) renumberList() tea.Cmd {
+	if changed := m.editor.RenumberList(); changed > 0 {
+		m.showMessage("List renumbered")
+	} else {
+		m.showMessage("List numbers already in order")
+	}
+	return nil
+}
+
+// autoRenumberList applies the auto_renumber_lists setting after a key
+// press: when the line count changed since lineCount, or the cursor just
+// finished typing a new item's marker ("3. ").
+func (m *Model) autoRenumberList(lineCount int) {
+	if !m.config.AutoRenumberLists || m.plainText() {
+		return
+	}
+	doc := m.editor.GetDocument()
+	if doc.LineCount() == lineCount {
+		pos := m.editor.GetCursor().GetBufferPos()
+		item, ok := ast.ParseListItem(doc.GetLine(pos.Line))
+		if !ok || !item.Ordered || pos.Col != item.MarkerEnd+1 {
+			return
+		}
+	}
+	m.editor.RenumberList()
+}
@@ -0,0 +1,517 @@
+package tui
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/ofri/mde/internal/config"
+	"github.com/ofri/mde/internal/links"
+	"github.com/ofri/mde/internal/lsp"
+	"github.com/ofri/mde/internal/plugins/renderers"
+	"github.com/ofri/mde/internal/theme"
+	"github.com/ofri/mde/pkg/ast"
+	"github.com/ofri/mde/pkg/plugin"
+)
+
+// lspTimeout bounds starting a language server and each request to it.
+const lspTimeout = 5 * time.Second
+
+// lspState is the language server for the open file, as configured under
+// language_servers.
+type lspState struct {
+	client   *lsp.Client
+	server   string // Command line of the running server
+	starting string // Command line of a server being started
+	failed   string // Command line of a server that failed; not retried
+
+	// The document open on the server and the revision it was sent at
+	path     string
+	doc      *ast.Document
+	revision int
+
+	diagnostics []lsp.Diagnostic
+}
+
+// lspStartedMsg reports a language server started in the background.
+type lspStartedMsg struct {
+	server string
+	client *lsp.Client
+	err    error
+}
+
+// lspDiagnosticsMsg reports that client published diagnostics, or exited.
+type lspDiagnosticsMsg struct {
+	client *lsp.Client
+	exited bool
+}
+
+type lspCompletionMsg struct {
+	doc   *ast.Document
+	pos   ast.BufferPos
+	items []lsp.CompletionItem
+	err   error
+}
+
+type lspHoverMsg struct {
+	text string
+	err  error
+}
+
+type lspRenameMsg struct {
+	doc  *ast.Document
+	path string
+	edit lsp.WorkspaceEdit
+	err  error
+}
+
+func init() {
+	registerCommand(Command{
+		Name:        "lsp-complete",
+		Description: "Complete the link or heading at the cursor from the language server",
+		Run:         (*Model).lspComplete,
+	})
+	registerCommand(Command{
+		Name:        "lsp-rename",
+		Description: "Rename the reference link or heading at the cursor through the language server",
+		Run:         (*Model).promptRename,
+	})
+	registerCommand(Command{
+		Name:        "lsp-hover",
+		Description: "Show the language server's information about the text at the cursor",
+		Run:         (*Model).lspHover,
+	})
+}
+
+// languageServer returns the server configured for path. Remote and
+// encrypted files are not shared with servers.
+func (m *Model) languageServer(path string) (config.LanguageServer, bool) {
+	if path == "" || remoteFile(path) || m.encrypted(path) {
+		return config.LanguageServer{}, false
+	}
+	server, ok := m.config.LanguageServers[strings.ToLower(filepath.Ext(path))]
+	return server, ok && server.Command != ""
+}
+
+// commandLine identifies a server, so files that use the same one share it.
+func commandLine(server config.LanguageServer) string {
+	return strings.Join(append([]string{server.Command}, server.Args...), " ")
+}
+
+// syncLanguageServer keeps the server in step with the editor after each
+// update: it starts the server the open file needs, and sends the text
+// whenever it changed.
+func (m *Model) syncLanguageServer() tea.Cmd {
+	doc := m.editor.GetDocument()
+	path := doc.GetFilename()
+	server, ok := m.languageServer(path)
+	if m.lsp.path != "" && m.lsp.path != path {
+		m.lsp.client.DidClose(m.lsp.path)
+		m.lsp.path, m.lsp.doc, m.lsp.diagnostics = "", nil, nil
+	}
+	if m.lsp.client != nil && (!ok || m.lsp.server != commandLine(server)) {
+		m.stopLanguageServer()
+	}
+	if !ok {
+		return nil
+	}
+
+	line := commandLine(server)
+	switch {
+	case m.lsp.client == nil:
+		if m.lsp.starting == line || m.lsp.failed == line {
+			return nil
+		}
+		m.lsp.starting = line
+		root := filepath.Dir(path)
+		if abs, err := filepath.Abs(root); err == nil {
+			root = abs
+		}
+		return func() tea.Msg {
+			ctx, cancel := context.WithTimeout(context.Background(), lspTimeout)
+			defer cancel()
+			client, err := lsp.Start(ctx, root, server.Command, server.Args...)
+			return lspStartedMsg{server: line, client: client, err: err}
+		}
+	case m.lsp.path == "":
+		languageID := cmp.Or(server.LanguageID, "markdown")
+		m.lsp.client.DidOpen(path, languageID, doc.GetText())
+	case m.lsp.doc != doc || m.lsp.revision != doc.Revision():
+		m.lsp.client.DidChange(path, doc.GetText())
+	default:
+		return nil
+	}
+	m.lsp.path, m.lsp.doc, m.lsp.revision = path, doc, doc.Revision()
+	return nil
+}
+
+// stopLanguageServer shuts the server down in the background.
+func (m *Model) stopLanguageServer() {
+	client := m.lsp.client
+	m.lsp = lspState{failed: m.lsp.failed}
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), lspTimeout)
+		defer cancel()
+		client.Shutdown(ctx)
+	}()
+}
+
+// Shutdown stops the language server and the preview server, if
+// running. Call it once the program has exited.
+func (m *Model) Shutdown() {
+	m.stopPreviewServer()
+	m.leaveShare("")
+	if m.lsp.client == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), lspTimeout)
+	defer cancel()
+	m.lsp.client.Shutdown(ctx)
+	m.lsp.client = nil
+}
+
+func (m *Model) handleLSPStarted(msg lspStartedMsg) (tea.Model, tea.Cmd) {
+	if msg.server != m.lsp.starting {
+		if msg.client != nil {
+			go msg.client.Shutdown(context.Background()) // No longer wanted
+		}
+		return m, nil
+	}
+	m.lsp.starting = ""
+	if msg.err != nil {
+		m.lsp.failed = msg.server
+		m.showMessage("Language server: " + msg.err.Error())
+		return m, nil
+	}
+	m.lsp.client, m.lsp.server = msg.client, msg.server
+	return m, waitForDiagnostics(msg.client)
+}
+
+// waitForDiagnostics waits for client to publish diagnostics or exit.
+func waitForDiagnostics(client *lsp.Client) tea.Cmd {
+	return func() tea.Msg {
+		select {
+		case <-client.Changed():
+			return lspDiagnosticsMsg{client: client}
+		case <-client.Done():
+			return lspDiagnosticsMsg{client: client, exited: true}
+		}
+	}
+}
+
+func (m *Model) handleLSPDiagnostics(msg lspDiagnosticsMsg) (tea.Model, tea.Cmd) {
+	if msg.client != m.lsp.client {
+		return m, nil // A server since stopped
+	}
+	if msg.exited {
+		m.lsp = lspState{failed: m.lsp.server}
+		m.showMessage("Language server exited")
+		return m, nil
+	}
+	m.lsp.diagnostics = msg.client.Diagnostics(m.lsp.path)
+	return m, waitForDiagnostics(msg.client)
+}
+
+// languageClient returns the server holding the open file, explaining
+// why there is none.
+func (m *Model) languageClient() (*lsp.Client, bool) {
+	switch {
+	case m.lsp.client != nil && m.lsp.path != "":
+		return m.lsp.client, true
+	case m.lsp.starting != "":
+		m.showMessage("Language server is starting")
+	default:
+		m.showMessage("No language server for this file (see language_servers in the config)")
+	}
+	return nil, false
+}
+
+// cursorPosition returns the cursor as a protocol position.
+func (m *Model) cursorPosition() lsp.Position {
+	pos := m.editor.GetCursor().GetBufferPos()
+	return lsp.ToPosition(pos.Line, m.editor.GetDocument().GetLine(pos.Line), pos.Col)
+}
+
+// lspComplete asks the server for completions at the cursor;
+// handleLSPCompletion shows them.
+func (m *Model) lspComplete() tea.Cmd {
+	client, ok := m.languageClient()
+	if !ok {
+		return nil
+	}
+	doc, path := m.editor.GetDocument(), m.lsp.path
+	pos, at := m.editor.GetCursor().GetBufferPos(), m.cursorPosition()
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), lspTimeout)
+		defer cancel()
+		items, err := client.Completion(ctx, path, at)
+		return lspCompletionMsg{doc: doc, pos: pos, items: items, err: err}
+	}
+}
+
+// handleLSPCompletion opens the popup with the candidates, or inserts the
+// only one, unless the cursor moved meanwhile.
+func (m *Model) handleLSPCompletion(msg lspCompletionMsg) (tea.Model, tea.Cmd) {
+	if msg.doc != m.editor.GetDocument() || msg.pos != m.editor.GetCursor().GetBufferPos() {
+		return m, nil
+	}
+	if msg.err != nil {
+		m.showMessage("Completion failed: " + msg.err.Error())
+		return m, nil
+	}
+	if len(msg.items) == 0 {
+		m.showMessage("No completions")
+		return m, nil
+	}
+
+	line := msg.doc.GetLine(msg.pos.Line)
+	popup := &completionPopup{replace: wordPrefix(line, msg.pos.Col)}
+	for _, item := range msg.items {
+		insert := cmp.Or(item.InsertText, item.Label)
+		if edit := item.TextEdit; edit != nil && edit.Range.Start.Line == msg.pos.Line {
+			insert = edit.NewText
+			popup.replace = max(msg.pos.Col-lsp.FromPosition(line, edit.Range.Start), 0)
+		}
+		popup.items = append(popup.items, popupItem{label: item.Label, insert: insert})
+	}
+	m.popup = popup
+	if len(popup.items) == 1 {
+		m.acceptPopup()
+	}
+	return m, nil
+}
+
+// wordPrefix returns the length in runes of the word ending at col, the
+// text a completion without its own range replaces.
+func wordPrefix(line string, col int) int {
+	runes := []rune(line)
+	start := min(col, len(runes))
+	for start > 0 {
+		r := runes[start-1]
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '-' && r != '_' {
+			break
+		}
+		start--
+	}
+	return min(col, len(runes)) - start
+}
+
+// lspHover shows the server's hover text for the cursor position in the
+// status line.
+func (m *Model) lspHover() tea.Cmd {
+	client, ok := m.languageClient()
+	if !ok {
+		return nil
+	}
+	path, at := m.lsp.path, m.cursorPosition()
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), lspTimeout)
+		defer cancel()
+		text, err := client.Hover(ctx, path, at)
+		return lspHoverMsg{text: text, err: err}
+	}
+}
+
+func (m *Model) handleLSPHover(msg lspHoverMsg) (tea.Model, tea.Cmd) {
+	switch text := strings.Join(strings.Fields(msg.text), " "); {
+	case msg.err != nil:
+		m.showMessage("Hover failed: " + msg.err.Error())
+	case text == "":
+		m.showMessage("Nothing to show here")
+	default:
+		m.showMessage(text)
+	}
+	return m, nil
+}
+
+// promptRename asks for the new name of the symbol at the cursor,
+// starting from a reference link's label.
+func (m *Model) promptRename() tea.Cmd {
+	if _, ok := m.languageClient(); !ok {
+		return nil
+	}
+	if m.readOnly {
+		m.showMessage("The document is read-only")
+		return nil
+	}
+	pos := m.editor.GetCursor().GetBufferPos()
+	m.input = ""
+	if link, ok := links.At(m.editor.GetDocument().GetLine(pos.Line), pos.Col); ok && link.Kind == links.Reference {
+		m.input = link.Label
+	}
+	m.mode = ModeRename
+	return nil
+}
+
+// handleRename sends the rename from the prompt; handleLSPRename applies
+// the edits.
+func (m *Model) handleRename() (tea.Model, tea.Cmd) {
+	newName := strings.TrimSpace(m.input)
+	m.mode = ModeNormal
+	m.input = ""
+	if newName == "" {
+		m.showMessage("Rename cancelled")
+		return m, nil
+	}
+	client, ok := m.languageClient()
+	if !ok {
+		return m, nil
+	}
+
+	doc, path, at := m.editor.GetDocument(), m.lsp.path, m.cursorPosition()
+	return m, func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), lspTimeout)
+		defer cancel()
+		edit, err := client.Rename(ctx, path, at, newName)
+		return lspRenameMsg{doc: doc, path: path, edit: edit, err: err}
+	}
+}
+
+// handleLSPRename applies the rename to the open document. Edits to other
+// files are left to the user, as only this one is open.
+func (m *Model) handleLSPRename(msg lspRenameMsg) (tea.Model, tea.Cmd) {
+	doc := m.editor.GetDocument()
+	switch {
+	case msg.err != nil:
+		m.showMessage("Rename failed: " + msg.err.Error())
+		return m, nil
+	case msg.doc != doc || msg.doc.Revision() != m.lsp.revision:
+		m.showMessage("Rename skipped: the document changed meanwhile")
+		return m, nil
+	}
+
+	uri := lsp.FileURI(msg.path)
+	edits := msg.edit.Edits(uri)
+	others := 0
+	for file := range msg.edit.Changes {
+		if file != uri {
+			others++
+		}
+	}
+	for _, change := range msg.edit.DocumentChanges {
+		if change.TextDocument.URI != uri {
+			others++
+		}
+	}
+
+	// Apply from the end so earlier ranges stay where the server saw them
+	ranges := make([]ast.Range, len(edits))
+	for i, edit := range edits {
+		ranges[i] = ast.Range{Start: lsp.BufferPos(doc, edit.Range.Start), End: lsp.BufferPos(doc, edit.Range.End)}
+	}
+	order := make([]int, len(edits))
+	for i := range order {
+		order[i] = i
+	}
+	slices.SortStableFunc(order, func(a, b int) int {
+		return cmp.Or(ranges[b].Start.Line-ranges[a].Start.Line, ranges[b].Start.Col-ranges[a].Start.Col)
+	})
+	cursor := m.editor.GetCursor().GetBufferPos()
+	for _, i := range order {
+		m.editor.ReplaceRange(ranges[i], edits[i].NewText)
+	}
+	m.editor.GetCursor().SetBufferPos(doc.ValidatePosition(cursor))
+	m.editor.AdjustViewPort()
+
+	message := fmt.Sprintf("Renamed %d occurrence(s)", len(edits))
+	if others > 0 {
+		message += fmt.Sprintf("; %d other file(s) not changed", others)
+	}
+	m.showMessage(message)
+	return m, nil
+}
+
+// diagnosticStyle returns the theme style for severity; servers that omit
+// it are taken to report errors.
+func diagnosticStyle(severity lsp.Severity) plugin.Style {
+	switch severity {
+	case lsp.SeverityWarning:
+		return theme.Resolved().Style(theme.DiagnosticWarn)
+	case lsp.SeverityInformation, lsp.SeverityHint:
+		return theme.Resolved().Style(theme.DiagnosticInfo)
+	default:
+		return theme.Resolved().Style(theme.DiagnosticError)
+	}
+}
+
+// markDiagnostics underlines the ranges the language server reported and
+// colors their line numbers in the gutter.
+func (m *Model) markDiagnostics(lines []plugin.RenderedLine) {
+	if len(m.lsp.diagnostics) == 0 || m.lsp.doc != m.editor.GetDocument() {
+		return
+	}
+	doc := m.editor.GetDocument()
+	viewport := m.editor.GetViewport()
+	top, left := viewport.GetTopLine(), viewport.GetLeftColumn()
+	prefix := 0
+	if m.editor.ShowLineNumbers() {
+		prefix = viewport.GetLineNumberWidth()
+	}
+
+	for _, d := range m.lsp.diagnostics {
+		style := diagnosticStyle(d.Severity)
+		gutter := style
+		gutter.Underline = false
+		first := max(d.Range.Start.Line, top)
+		last := min(d.Range.End.Line, top+len(lines)-1, doc.LineCount()-1)
+		for line := first; line <= last; line++ {
+			i := line - top
+			text := doc.GetLine(line)
+			from, to := 0, utf8.RuneCountInString(text)
+			if line == d.Range.Start.Line {
+				from = lsp.FromPosition(text, d.Range.Start)
+			}
+			if line == d.Range.End.Line {
+				to = lsp.FromPosition(text, d.Range.End)
+			}
+			if to <= from {
+				to = from + 1 // Mark empty ranges by one cell
+			}
+
+			length := utf8.RuneCountInString(lines[i].Content)
+			from = max(viewport.DisplayColumn(text, from)-left, 0) + prefix
+			to = min(max(viewport.DisplayColumn(text, to)-left, 0)+prefix, length)
+			lines[i].Styles = renderers.Overlay(lines[i].Styles, from, to, style)
+			if prefix > 3 {
+				lines[i].Styles = renderers.Overlay(lines[i].Styles, 0, prefix-3, gutter) // The digits before " │ "
+			}
+		}
+	}
+}
+
+// diagnosticAtCursor returns the diagnostic or broken link on the cursor
+// line, preferring one whose range holds the cursor, for the status line;
+// "" when none.
+func (m *Model) diagnosticAtCursor() string {
+	found, inRange := m.brokenLinkAtCursor()
+	if inRange || m.lsp.doc != m.editor.GetDocument() {
+		return found
+	}
+	pos := m.editor.GetCursor().GetBufferPos()
+	at := m.cursorPosition()
+	for _, d := range m.lsp.diagnostics {
+		if pos.Line < d.Range.Start.Line || pos.Line > d.Range.End.Line {
+			continue
+		}
+		message := d.Severity.String() + ": " + d.Message
+		if d.Severity == 0 {
+			message = d.Message
+		}
+		inRange := (pos.Line > d.Range.Start.Line || at.Character >= d.Range.Start.Character) &&
+			(pos.Line < d.Range.End.Line || at.Character <= d.Range.End.Character)
+		if inRange {
+			return message
+		}
+		if found == "" {
+			found = message
+		}
+	}
+	return found
+}
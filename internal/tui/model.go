@@ -1,24 +1,29 @@
 package tui
 
 import (
-	"bytes"
 	"context"
 	"fmt"
+	gohtml "html"
 	"strings"
+	"time"
 
 	tea "github.com/charmbracelet/bubbletea/v2"
 	"github.com/charmbracelet/lipgloss"
-	"github.com/yuin/goldmark"
-	"github.com/yuin/goldmark/extension"
-	"github.com/yuin/goldmark/parser"
-	"github.com/yuin/goldmark/renderer/html"
+	"github.com/charmbracelet/x/ansi"
 	"github.com/ofri/mde/pkg/ast"
 	"github.com/ofri/mde/pkg/plugin"
+	"github.com/ofri/mde/internal/assets"
+	"github.com/ofri/mde/internal/config"
+	"github.com/ofri/mde/internal/htmlterm"
+	"github.com/ofri/mde/internal/mdhtml"
+	"github.com/ofri/mde/internal/plugins"
 	"github.com/ofri/mde/internal/plugins/renderers"
+	"github.com/ofri/mde/internal/templates"
 )
 
 type Model struct {
 	editor       *ast.Editor
+	config       *config.Config
 	width        int
 	height       int
 	message      string
@@ -33,13 +38,105 @@ type Model struct {
 	
 	// Save prompt context
 	savePromptContext string
+	pendingPaste      string // Delimited text awaiting the paste prompt
+	
+	// Passphrase of the open encrypted note
+	crypt cryptState
+	
+	// Remote file being loaded or saved
+	transfer transferState
+	readOnly bool // The document came from a URL and stays in preview
+	noEOL    bool // The file as last read or saved lacks a final newline
 	
 	// Preview mode
 	previewMode  bool
+	preview      previewState
+	
+	// Distraction-free writing mode
+	zen zenState
+	
+	// Modal editing state when keymap = "vim"
+	vim vimState
+	
+	// Last text searched for, repeated by vim's n and N
+	lastSearch string
+	search     searchState
+	
+	// Abbreviations expanded while typing
+	abbrev abbrevState
+	
+	// Completion popup shown under the cursor, nil when closed
+	popup *completionPopup
+	
+	// Language server for the open file
+	lsp lspState
+	
+	// Shell command the text was last filtered through
+	filter filterState
+	
+	// Code blocks run by their interpreters
+	runBlocks runBlockState
+	
+	// Export through pandoc
+	export exportState
+	
+	// Live HTML preview served to the browser
+	previewServer previewServerState
+	
+	// Unused assets awaiting the clean-assets prompt's answer
+	unusedAssets assets.Report
+	
+	// Broken links found by check-links
+	linkCheck linkCheckState
+	
+	// Where number_headings last numbered the headings
+	numbering numberingState
+	
+	// The bibliography citations are completed from
+	bib bibliographyState
+	
+	// The glossary terms are defined from, and whether they are highlighted
+	glossaryTerms glossaryState
+	
+	// Review comments kept beside the document
+	review reviewState
+	
+	// Suggestion mode and the changes suggested in the document
+	suggest suggestState
+	
+	// The history picker's snapshots of the file
+	snapshots snapshotState
+	
+	// The shared editing session the buffer is in
+	share shareState
+	
+	// The project settings applied over the user's
+	project projectState
+	
+	// The model's own renderer instance and the editor state it was last
+	// configured with
+	ownRenderer  ownRenderer
+	rendererView rendererView
+	
+	// Timings shown by the performance HUD
+	perf perfStats
+	
+	// Word counts by heading shown by the section stats panel
+	sectionStats sectionStatsPanel
+	
+	// Progress toward word_goal
+	goal goalState
+	
+	// The terminal reported a light background, for theme = "auto"
+	lightBackground bool
+	
+	// Last failure logged, so a failure repeated every frame logs once
+	lastFailure string
 	
 	// Mouse state tracking
 	mouseStartPos *ast.BufferPos // Starting position for drag selection
 	isDragging    bool            // Whether we're currently dragging
+	hover         string          // Tooltip for the link under the mouse
 }
 
 type EditorMode int
@@ -50,15 +147,69 @@ const (
 	ModeReplace
 	ModeGoto
 	ModeSavePrompt
+	ModeCommand
+	ModeTemplate
+	ModePastePrompt
+	ModePassphrase
+	ModeTheme
+	ModeRename
+	ModeFilter
+	ModeRunPrompt
+	ModeExport
+	ModeAssetsPrompt
+	ModeComment
+	ModeHistory
+	ModeJoin
 )
 
 func New() *Model {
 	return &Model{
 		editor: ast.NewEditor(),
+		config: config.Default(),
+	}
+}
+
+// SetConfig applies user configuration to the model.
+func (m *Model) SetConfig(cfg *config.Config) {
+	m.config = cfg
+	m.project = projectState{}
+	m.abbrev = abbrevState{}
+	m.goal = goalState{}
+	m.editor.SetTypewriterMode(cfg.TypewriterScrolling)
+	if err := plugins.Configure(cfg); err != nil {
+		m.showMessage("Error: " + err.Error())
+	}
+	m.applyTheme()
+	
+	// A new renderer instance picks up the new settings
+	m.ownRenderer = ownRenderer{}
+}
+
+// NewBuffer replaces the current document with unsaved content.
+// The filename may be empty for an untitled buffer.
+func (m *Model) NewBuffer(content, filename string) {
+	m.editor = ast.NewEditorWithContent(content)
+	m.editor.GetDocument().SetFilename(filename)
+	m.readOnly = false
+	m.editor.SetTypewriterMode(m.config.TypewriterScrolling)
+	if content != "" {
+		m.editor.GetDocument().MarkModified()
 	}
+	if m.width > 0 {
+		m.resizeEditor()
+	}
+	
+	m.parseDocument()
 }
 
 func (m *Model) SetFilename(filename string) {
+	if remoteFile(filename) {
+		m.openRemote(filename)
+		return
+	}
+	if m.openEncrypted(filename) {
+		return
+	}
 	err := m.editor.LoadFile(filename)
 	if err != nil {
 		m.err = err
@@ -79,7 +230,12 @@ func (m *Model) IsPreviewMode() bool {
 }
 
 func (m *Model) TogglePreviewMode() {
-	m.previewMode = !m.previewMode
+	m.setPreviewMode(!m.previewMode)
+}
+
+// GetPreviewTopLine returns the document line at the top of the preview.
+func (m *Model) GetPreviewTopLine() int {
+	return m.preview.top
 }
 
 func (m *Model) ConvertMarkdownToHTML(markdownText string) string {
@@ -91,14 +247,13 @@ func (m *Model) ConvertHTMLToTerminalText(htmlContent string) string {
 }
 
 func (m *Model) Init() tea.Cmd {
-	return tea.RequestKeyReleases
+	return tea.Batch(tea.RequestKeyReleases, tea.RequestBackgroundColor, m.startTransfer(), m.startDecrypt(), m.listenShare())
 }
 
 // GetContentHeight returns the available height for editor content.
-// Terminal height minus UI chrome (status bar + help bar = 2 lines).
+// Terminal height minus the visible UI chrome (status bar, help bar).
 func (m *Model) GetContentHeight() int {
-	const uiChromeHeight = 2 // status bar (1) + help bar (1)
-	contentHeight := m.height - uiChromeHeight
+	contentHeight := m.height - m.chromeHeight()
 	if contentHeight < 1 {
 		contentHeight = 1 // minimum height
 	}
@@ -109,42 +264,59 @@ func (m *Model) View() string {
 	if m.err != nil {
 		return m.err.Error()
 	}
+	if m.snapshots.diff != nil {
+		return m.snapshots.diff.View()
+	}
 
 	// Viewport is now updated only when window size changes
 	
 	// Render content based on mode
-	var content string
-	if m.previewMode {
-		content = m.renderPreviewContent()
-	} else {
-		content = m.renderEditorContent()
-	}
+	content := m.measureFrame(func() string {
+		if m.previewMode {
+			return m.renderPreviewContent()
+		}
+		return m.renderEditorContent()
+	})
 	
-	statusBar := m.renderStatusBar()
-	helpBar := m.renderHelpBar()
+	sections := []string{m.overlayPerfHUD(m.overlayHistoryPanel(m.overlayChangesPanel(m.overlaySectionStats(content))))}
+	if m.statusBarVisible() {
+		sections = append(sections, m.renderStatusBar())
+	}
+	if m.helpBarVisible() {
+		sections = append(sections, m.renderHelpBar())
+	}
 	
 	// No background styling - use terminal's default
 	editorStyle := lipgloss.NewStyle().Width(m.width).Height(m.height)
-	return editorStyle.Render(lipgloss.JoinVertical(lipgloss.Top, content, statusBar, helpBar))
+	return editorStyle.Render(lipgloss.JoinVertical(lipgloss.Top, sections...))
 }
 
 // renderEditorContent renders the editor content with syntax highlighting
 // IMPORTANT: This uses the internal plugin system for modularization.
-// Plugins are compiled into the binary and cannot fail at runtime unless there's a programming error.
-// Any plugin errors indicate a bug and should crash with explicit errors.
+// Plugins are compiled into the binary, so an error here is a bug; it is
+// shown in place of the text (see renderFailure) instead of ending the
+// session.
 func (m *Model) renderEditorContent() string {
+	content, err := m.editorContent()
+	if err != nil {
+		return m.renderFailure("editor", err)
+	}
+	return content
+}
+
+func (m *Model) editorContent() (_ string, err error) {
+	defer recoverFailure(&err)
 	editorHeight := m.GetContentHeight()
 	
 	// Get renderer plugin - must exist as it's compiled into the binary
-	registry := plugin.GetRegistry()
-	renderer, err := registry.GetDefaultRenderer()
+	renderer, err := m.renderer()
 	if err != nil {
-		panic(fmt.Sprintf("FATAL: Failed to get default renderer plugin: %v\nThis is a programming error - renderer plugin must be registered at startup", err))
+		return "", fmt.Errorf("no renderer: %w", err)
 	}
 	
 	// Configure renderer to match editor settings
 	if err := m.configureRenderer(renderer); err != nil {
-		panic(fmt.Sprintf("FATAL: Failed to configure renderer: %v\nThis is a programming error - renderer configuration should never fail", err))
+		return "", fmt.Errorf("configuring renderer: %w", err)
 	}
 	
 	// Create render context with viewport information
@@ -161,11 +333,24 @@ func (m *Model) renderEditorContent() string {
 	ctx := context.Background()
 	renderedLines, err := renderer.RenderVisible(ctx, renderCtx)
 	if err != nil {
-		panic(fmt.Sprintf("FATAL: Renderer failed to render visible content: %v\nThis is a programming error - internal renderer should never fail", err))
+		return "", err
 	}
 	
+	// Zen mode dims all but the text being written
+	m.dimUnfocused(renderedLines)
+	m.markGlossaryTerms(renderedLines)
+	m.markSelection(renderedLines)
+	m.markDiagnostics(renderedLines)
+	m.markBrokenLinks(renderedLines)
+	m.markComments(renderedLines)
+	m.markPeers(renderedLines)
+	m.pinStickyHeading(renderedLines)
+	
 	// Convert rendered lines to string and add cursor
-	content := m.renderLinesWithCursor(renderedLines, renderer)
+	content, err := m.renderLinesWithCursor(renderedLines, renderer)
+	if err != nil {
+		return "", err
+	}
 	
 	// Pad to fill editor height
 	lines := strings.Split(content, "\n")
@@ -178,36 +363,44 @@ func (m *Model) renderEditorContent() string {
 		lines = lines[:editorHeight]
 	}
 	
-	result := strings.Join(lines, "\n")
+	result := m.indentText(m.overlayPopup(m.overlayComments(strings.Join(lines, "\n"))))
 	
 	// No background styling - use terminal's default
 	editorStyle := lipgloss.NewStyle().Width(m.width).Height(editorHeight)
-	return editorStyle.Render(result)
+	return editorStyle.Render(result), nil
 }
 
 
 // renderPreviewContent renders the markdown content in preview mode
 // Uses the internal plugin system for consistent rendering
 func (m *Model) renderPreviewContent() string {
+	content, err := m.previewContent()
+	if err != nil {
+		return m.renderFailure("preview", err)
+	}
+	return content
+}
+
+func (m *Model) previewContent() (_ string, err error) {
+	defer recoverFailure(&err)
 	editorHeight := m.GetContentHeight()
 	
 	// Get renderer plugin - must exist as it's compiled into the binary
-	registry := plugin.GetRegistry()
-	renderer, err := registry.GetDefaultRenderer()
+	renderer, err := m.renderer()
 	if err != nil {
-		panic(fmt.Sprintf("FATAL: Failed to get default renderer plugin: %v\nThis is a programming error - renderer plugin must be registered at startup", err))
+		return "", fmt.Errorf("no renderer: %w", err)
 	}
 	
 	// Configure renderer to match editor settings
 	if err := m.configureRenderer(renderer); err != nil {
-		panic(fmt.Sprintf("FATAL: Failed to configure renderer: %v\nThis is a programming error - renderer configuration should never fail", err))
+		return "", fmt.Errorf("configuring renderer: %w", err)
 	}
 	
 	// Create render context for preview mode
 	// Preview mode doesn't show line numbers but still respects viewport boundaries
 	renderCtx := &plugin.RenderContext{
 		Document:        m.editor.GetDocument(),
-		Viewport:        m.editor.GetViewport(),
+		Viewport:        m.previewViewport(),
 		ShowLineNumbers: false, // Preview mode never shows line numbers
 	}
 	
@@ -216,7 +409,7 @@ func (m *Model) renderPreviewContent() string {
 	ctx := context.Background()
 	renderedLines, err := renderer.RenderPreviewVisible(ctx, renderCtx)
 	if err != nil {
-		panic(fmt.Sprintf("FATAL: Renderer failed to render preview content: %v\nThis is a programming error - internal renderer should never fail", err))
+		return "", err
 	}
 	
 	// The renderer now returns only visible lines, so we can use them directly
@@ -234,42 +427,24 @@ func (m *Model) renderPreviewContent() string {
 	// The renderer MUST be a TerminalRenderer as it's the only implementation
 	terminalRenderer, ok := renderer.(*renderers.TerminalRenderer)
 	if !ok {
-		panic(fmt.Sprintf("FATAL: Renderer is not a TerminalRenderer: got %T\nThis is a programming error - only TerminalRenderer is supported", renderer))
+		return "", fmt.Errorf("renderer is not a TerminalRenderer: got %T", renderer)
 	}
-	content := terminalRenderer.RenderToString(renderedLines)
+	content := m.indentText(terminalRenderer.RenderToString(renderedLines))
 	
 	// No background styling - use terminal's default
 	editorStyle := lipgloss.NewStyle().Width(m.width).Height(editorHeight)
-	return editorStyle.Render(content)
+	return editorStyle.Render(content), nil
 }
 
 // convertMarkdownToHTML converts markdown text to HTML using goldmark
 func (m *Model) convertMarkdownToHTML(markdownText string) string {
-	var buf bytes.Buffer
-	
-	// Create goldmark instance with extensions
-	md := goldmark.New(
-		goldmark.WithExtensions(
-			extension.GFM,
-			extension.Table,
-			extension.Strikethrough,
-			extension.Linkify,
-			extension.TaskList,
-		),
-		goldmark.WithParserOptions(
-			parser.WithAutoHeadingID(),
-		),
-		goldmark.WithRendererOptions(
-			html.WithHardWraps(),
-			html.WithXHTML(),
-		),
-	)
-	
-	if err := md.Convert([]byte(markdownText), &buf); err != nil {
-		panic(fmt.Sprintf("FATAL: Failed to convert markdown to HTML: %v\nThis is a programming error - goldmark should never fail", err))
+	out, err := mdhtml.Convert([]byte(markdownText), mdhtml.Options{Typographer: m.config.Typographer})
+	if err != nil {
+		// goldmark only fails on writer errors, impossible with a buffer
+		m.reportFailure("HTML conversion", err)
+		return "<pre>" + gohtml.EscapeString(markdownText) + "</pre>"
 	}
-	
-	return buf.String()
+	return string(out)
 }
 
 // convertMarkdownToHTMLLazy converts only the visible portion of markdown for large documents
@@ -297,132 +472,83 @@ func (m *Model) convertMarkdownToHTMLLazy(markdownText string) string {
 	return m.convertMarkdownToHTML(visibleText)
 }
 
-// convertHTMLToTerminalText converts HTML to terminal-friendly text
+// convertHTMLToTerminalText converts HTML to styled terminal text
 func (m *Model) convertHTMLToTerminalText(htmlContent string) string {
-	// Simple HTML to text conversion for terminal display
-	// This is a basic implementation - could be enhanced with proper HTML parsing
-	
-	result := htmlContent
-	
-	// Remove HTML tags (basic implementation)
-	result = strings.ReplaceAll(result, "<p>", "")
-	result = strings.ReplaceAll(result, "</p>", "\n")
-	result = strings.ReplaceAll(result, "<br>", "\n")
-	result = strings.ReplaceAll(result, "<br/>", "\n")
-	result = strings.ReplaceAll(result, "<br />", "\n")
-	
-	// Handle headings (with and without IDs)
-	for i := 1; i <= 6; i++ {
-		h := fmt.Sprintf("h%d", i)
-		// Handle headings with IDs using regex-like replacement
-		idPattern := "<" + h + " id=\""
-		endPattern := "\">"
-		if strings.Contains(result, idPattern) {
-			// Find all occurrences and replace them
-			for strings.Contains(result, idPattern) {
-				start := strings.Index(result, idPattern)
-				if start != -1 {
-					end := strings.Index(result[start:], endPattern)
-					if end != -1 {
-						// Replace the entire ID portion
-						before := result[:start]
-						after := result[start+end+len(endPattern):]
-						result = before + strings.Repeat("#", i) + " " + after
-					}
-				}
-			}
-		}
-		// Handle simple headings
-		result = strings.ReplaceAll(result, "<"+h+">", strings.Repeat("#", i)+" ")
-		result = strings.ReplaceAll(result, "</"+h+">", "\n\n")
-	}
-	
-	// Handle emphasis
-	result = strings.ReplaceAll(result, "<strong>", "**")
-	result = strings.ReplaceAll(result, "</strong>", "**")
-	result = strings.ReplaceAll(result, "<em>", "*")
-	result = strings.ReplaceAll(result, "</em>", "*")
-	result = strings.ReplaceAll(result, "<b>", "**")
-	result = strings.ReplaceAll(result, "</b>", "**")
-	result = strings.ReplaceAll(result, "<i>", "*")
-	result = strings.ReplaceAll(result, "</i>", "*")
-	
-	// Handle code
-	result = strings.ReplaceAll(result, "<code>", "`")
-	result = strings.ReplaceAll(result, "</code>", "`")
-	result = strings.ReplaceAll(result, "<pre>", "```\n")
-	result = strings.ReplaceAll(result, "</pre>", "\n```\n")
-	
-	// Handle lists
-	result = strings.ReplaceAll(result, "<ul>", "")
-	result = strings.ReplaceAll(result, "</ul>", "\n")
-	result = strings.ReplaceAll(result, "<ol>", "")
-	result = strings.ReplaceAll(result, "</ol>", "\n")
-	result = strings.ReplaceAll(result, "<li>", "• ")
-	result = strings.ReplaceAll(result, "</li>", "\n")
-	
-	// Handle blockquotes
-	result = strings.ReplaceAll(result, "<blockquote>", "> ")
-	result = strings.ReplaceAll(result, "</blockquote>", "\n")
-	
-	// Handle links - show as [text](url)
-	// This is a basic implementation - more robust regex could be used
-	result = strings.ReplaceAll(result, "<a href=\"", "[")
-	result = strings.ReplaceAll(result, "\">", "](")
-	result = strings.ReplaceAll(result, "</a>", ")")
-	
-	// Clean up extra whitespace
-	lines := strings.Split(result, "\n")
-	var cleanLines []string
-	for _, line := range lines {
-		cleanLines = append(cleanLines, strings.TrimSpace(line))
-	}
-	
-	return strings.Join(cleanLines, "\n")
-}
-
-// parseDocument parses the current document content for syntax highlighting
+	text, err := htmlterm.Render(htmlContent)
+	if err != nil {
+		// The HTML parser only fails on reader errors, which cannot
+		// happen with an in-memory string; fall back to the raw HTML
+		return htmlContent
+	}
+	return text
+}
+
+// parseDocument parses the current document content for syntax highlighting.
+// A parser failure is reported and leaves the text unhighlighted.
 func (m *Model) parseDocument() {
+	start := time.Now()
+	if err := m.parseDocumentTokens(); err != nil {
+		m.reportFailure("Parsing", err)
+	}
+	m.perf.parse = time.Since(start)
+}
+
+func (m *Model) parseDocumentTokens() (err error) {
+	defer recoverFailure(&err)
 	registry := plugin.GetRegistry()
-	parser, err := registry.GetDefaultParser()
+	parser, err := registry.GetParserForFile(m.editor.GetDocument().GetFilename())
 	if err != nil {
-		panic(fmt.Sprintf("FATAL: Failed to get default parser plugin: %v\nThis is a programming error - parser plugin must be registered at startup", err))
+		return err
 	}
 	
 	ctx := context.Background()
 	_, err = parser.Parse(ctx, m.editor.GetDocument().GetText())
 	if err != nil {
-		panic(fmt.Sprintf("FATAL: Parser failed to parse document: %v\nThis is a programming error - internal parser should never fail", err))
+		return err
 	}
 	
 	// For large documents, only parse visible lines for performance
 	doc := m.editor.GetDocument()
 	lineCount := doc.LineCount()
 	
+	// Lines scrolled into view later, or whose tokens the document's token
+	// cache evicted, are tokenized on demand
+	doc.SetTokenizer(func(line string) []ast.Token {
+		tokens, err := parser.GetSyntaxHighlighting(ctx, line)
+		if err != nil {
+			m.reportFailure("Highlighting", err)
+			return nil
+		}
+		return tokens
+	})
+	
 	// Optimize for large documents (> 1000 lines)
 	if lineCount > 1000 {
-		m.parseVisibleLines(parser, ctx)
-	} else {
-		// Parse all lines for smaller documents
-		m.parseAllLines(parser, ctx)
+		return m.parseVisibleLines(parser, ctx)
 	}
+	// Parse all lines for smaller documents
+	return m.parseAllLines(parser, ctx)
 }
 
 // parseAllLines parses all lines in the document
-func (m *Model) parseAllLines(parser plugin.ParserPlugin, ctx context.Context) {
+func (m *Model) parseAllLines(parser plugin.ParserPlugin, ctx context.Context) error {
 	doc := m.editor.GetDocument()
 	for i := 0; i < doc.LineCount(); i++ {
+		if doc.IsLongLine(i) || doc.HasLineTokens(i) {
+			continue // Too long to highlight, or already done
+		}
 		line := doc.GetLine(i)
 		tokens, err := parser.GetSyntaxHighlighting(ctx, line)
 		if err != nil {
-			panic(fmt.Sprintf("FATAL: Parser failed to get syntax highlighting for line %d: %v\nThis is a programming error - internal parser should never fail", i, err))
+			return fmt.Errorf("line %d: %w", i+1, err)
 		}
 		doc.SetLineTokens(i, tokens)
 	}
+	return nil
 }
 
 // parseVisibleLines parses only the visible lines and a buffer around them
-func (m *Model) parseVisibleLines(parser plugin.ParserPlugin, ctx context.Context) {
+func (m *Model) parseVisibleLines(parser plugin.ParserPlugin, ctx context.Context) error {
 	doc := m.editor.GetDocument()
 	viewport := m.editor.GetViewport()
 	
@@ -441,21 +567,40 @@ func (m *Model) parseVisibleLines(parser plugin.ParserPlugin, ctx context.Contex
 	
 	// Parse lines in the visible range
 	for i := startLine; i < endLine; i++ {
+		if doc.IsLongLine(i) || doc.HasLineTokens(i) {
+			continue // Too long to highlight, or already done
+		}
 		line := doc.GetLine(i)
 		tokens, err := parser.GetSyntaxHighlighting(ctx, line)
 		if err != nil {
-			panic(fmt.Sprintf("FATAL: Parser failed to get syntax highlighting for line %d: %v\nThis is a programming error - internal parser should never fail", i, err))
+			return fmt.Errorf("line %d: %w", i+1, err)
 		}
 		doc.SetLineTokens(i, tokens)
 	}
+	return nil
+}
+
+// tokenizeVisible makes sure the lines on screen have tokens, tokenizing
+// the ones scrolled into view since the last parse or evicted from the
+// token cache.
+func (m *Model) tokenizeVisible() {
+	if m.editor == nil {
+		return
+	}
+	doc := m.editor.GetDocument()
+	viewport := m.editor.GetViewport()
+	end := min(viewport.GetTopLine()+viewport.GetHeight(), doc.LineCount())
+	for i := max(viewport.GetTopLine(), 0); i < end; i++ {
+		doc.GetLineTokens(i)
+	}
 }
 
 // renderLinesWithCursor converts rendered lines to display string with cursor
-func (m *Model) renderLinesWithCursor(renderedLines []plugin.RenderedLine, renderer plugin.RendererPlugin) string {
+func (m *Model) renderLinesWithCursor(renderedLines []plugin.RenderedLine, renderer plugin.RendererPlugin) (string, error) {
 	// The renderer MUST be a TerminalRenderer as it's the only implementation
 	terminalRenderer, ok := renderer.(*renderers.TerminalRenderer)
 	if !ok {
-		panic(fmt.Sprintf("FATAL: Renderer is not a TerminalRenderer: got %T\nThis is a programming error - only TerminalRenderer is supported", renderer))
+		return "", fmt.Errorf("renderer is not a TerminalRenderer: got %T", renderer)
 	}
 	
 	// Get cursor position and viewport for calculation
@@ -467,11 +612,7 @@ func (m *Model) renderLinesWithCursor(renderedLines []plugin.RenderedLine, rende
 	if cursorPos.Line < viewport.GetTopLine() || 
 	   cursorPos.Line >= viewport.GetTopLine() + viewport.GetHeight() {
 		// Cursor is outside the visible area - render without cursor
-		lines := make([]string, len(renderedLines))
-		for i, line := range renderedLines {
-			lines[i] = line.Content
-		}
-		return strings.Join(lines, "\n")
+		return terminalRenderer.RenderToString(renderedLines), nil
 	}
 	
 	// Calculate cursor position relative to the rendered lines
@@ -488,7 +629,7 @@ func (m *Model) renderLinesWithCursor(renderedLines []plugin.RenderedLine, rende
 	}
 	
 	// Render with cursor at the viewport-relative position
-	return terminalRenderer.RenderToStringWithCursor(renderedLines, cursorRow, cursorCol)
+	return terminalRenderer.RenderToStringWithCursor(renderedLines, cursorRow, cursorCol), nil
 }
 
 // configureRenderer synchronizes the renderer configuration with the editor's settings.
@@ -501,15 +642,72 @@ func (m *Model) renderLinesWithCursor(renderedLines []plugin.RenderedLine, rende
 //
 // SOLUTION: Ensure renderer.config.ShowLineNumbers matches editor.ShowLineNumbers()
 // before using the renderer for cursor positioning.
+//
+// Only this view state is synchronized here, and only when it changes;
+// user settings reach the plugins once, through plugins.Configure.
 func (m *Model) configureRenderer(renderer plugin.RendererPlugin) error {
+	view := rendererView{
+		renderer:        renderer,
+		showLineNumbers: m.editor.ShowLineNumbers(),
+		lineNumberWidth: m.editor.GetLineNumberWidth(),
+		cursorShape:     m.cursorShape(),
+		showInvisibles:  m.config.ShowInvisibles,
+		indentGuides:    m.config.IndentGuides,
+		screenReader:    m.config.ScreenReader,
+	}
+	if view == m.rendererView {
+		return nil
+	}
+	
 	// Synchronize renderer configuration with editor settings
 	config := map[string]interface{}{
-		"showLineNumbers":  m.editor.ShowLineNumbers(),
-		"lineNumberWidth": m.editor.GetLineNumberWidth(),
+		"showLineNumbers": view.showLineNumbers,
+		"lineNumberWidth": view.lineNumberWidth,
+		"cursorShape":     view.cursorShape,
+		"showInvisibles":  view.showInvisibles,
+		"indentGuides":    view.indentGuides,
+		"screenReader":    view.screenReader,
+	}
+	if err := renderer.Configure(config); err != nil {
+		return err
+	}
+	m.rendererView = view
+	return nil
+}
+
+// ownRenderer is a renderer instance private to the model, tied to the
+// registry it came from.
+type ownRenderer struct {
+	registry *plugin.Registry
+	renderer plugin.RendererPlugin
+}
+
+// renderer returns the model's own instance of the default renderer, so
+// the view state configureRenderer pushes never reaches other consumers
+// of the shared plugin.
+func (m *Model) renderer() (plugin.RendererPlugin, error) {
+	registry := plugin.GetRegistry()
+	if m.ownRenderer.renderer != nil && m.ownRenderer.registry == registry {
+		return m.ownRenderer.renderer, nil
 	}
 	
-	// Configure the renderer to match editor settings
-	return renderer.Configure(config)
+	renderer, err := registry.NewRendererInstance(registry.DefaultRendererName())
+	if err != nil {
+		return nil, err
+	}
+	m.ownRenderer = ownRenderer{registry: registry, renderer: renderer}
+	return renderer, nil
+}
+
+// rendererView is the editor state last pushed to a renderer.
+type rendererView struct {
+	renderer        plugin.RendererPlugin
+	showLineNumbers bool
+	lineNumberWidth int
+	cursorShape     string
+	showInvisibles  bool
+	indentGuides    bool
+	screenReader    bool
 }
 
 func (m *Model) renderStatusBar() string {
@@ -520,15 +718,51 @@ func (m *Model) renderStatusBar() string {
 	if m.editor.GetDocument().IsModified() {
 		filename += " [Modified]"
 	}
+	if m.readOnly {
+		filename += " [Read-only]"
+	}
+	if m.noEOL {
+		filename += " [No EOL]"
+	}
+	
+	pos := m.editor.GetCursor().GetBufferPos()
+	position := fmt.Sprintf("Ln %d, Col %d", pos.Line+1, pos.Col+1)
+	if m.editor.OverwriteMode() {
+		position = "OVR  " + position
+	}
+	if m.suggest.on {
+		position = "SUGGEST  " + position
+	}
+	if status := m.shareStatus(); status != "" {
+		position = status + "  " + position
+	}
+	if status := m.vimStatus(); m.vimEnabled() && status != "" {
+		position = status + "  " + position
+	}
+	if progress := m.goalProgress(); progress != "" {
+		position = progress + "  " + position
+	}
+	
+	if m.config.ScreenReader {
+		return lipgloss.NewStyle().Width(m.width).Render(m.screenReaderStatus(filename))
+	}
 	
 	status := filename
+	if crumb := m.breadcrumb(m.width - lipgloss.Width(filename) - lipgloss.Width(position) - 3); crumb != "" {
+		status = filename + "  " + crumb
+	}
 	if m.message != "" {
 		status = m.message
+	} else if m.hover != "" {
+		status = ansi.Truncate(m.hover, max(m.width-lipgloss.Width(position)-1, 1), "…")
+	} else if diagnostic := m.diagnosticAtCursor(); diagnostic != "" {
+		status = ansi.Truncate(diagnostic, max(m.width-lipgloss.Width(position)-1, 1), "…")
+	} else if term := m.glossaryAtCursor(); term != "" {
+		status = ansi.Truncate(term, max(m.width-lipgloss.Width(position)-1, 1), "…")
+	} else if comment := m.commentStatus(); comment != "" {
+		status = ansi.Truncate(comment, max(m.width-lipgloss.Width(position)-1, 1), "…")
 	}
 	
-	pos := m.editor.GetCursor().GetBufferPos()
-	position := fmt.Sprintf("Ln %d, Col %d", pos.Line+1, pos.Col+1)
-	
 	gap := m.width - lipgloss.Width(status) - lipgloss.Width(position)
 	if gap < 1 {
 		gap = 1
@@ -549,15 +783,58 @@ func (m *Model) renderHelpBar() string {
 	switch m.mode {
 	case ModeFind:
 		help = "Find: " + m.input + " | Enter: Search | Esc: Cancel"
+		if m.search.backward {
+			help = "Find backward: " + m.input + " | Enter: Search | Esc: Cancel"
+		}
 	case ModeReplace:
 		help = "Replace: " + m.input + " with: " + m.replaceText + " | Enter: Replace | Esc: Cancel"
 	case ModeGoto:
 		help = "Goto line: " + m.input + " | Enter: Go | Esc: Cancel"
+	case ModeCommand:
+		help = "Command: " + m.input + " | Tab: Complete | Enter: Run | Esc: Cancel"
+	case ModeTemplate:
+		names := strings.Join(templates.List(m.config.TemplatesDir), ", ")
+		help = "Template: " + m.input + " [" + names + "] | Tab: Complete | Enter: Create | Esc: Cancel"
+	case ModeTheme:
+		names := strings.Join(themeChoices(), ", ")
+		help = "Theme: " + m.input + " [" + names + "] | Tab: Complete | Enter: Use | Esc: Cancel"
+	case ModeRename:
+		help = "Rename to: " + m.input + " | Enter: Rename | Esc: Cancel"
+	case ModeComment:
+		help = "Comment: " + m.input + " | Enter: Add | Esc: Cancel"
+	case ModeFilter:
+		help = "Filter through: " + m.input + " | Enter: Run | Esc: Cancel"
+	case ModeExport:
+		help = "Export to: " + m.input + " [" + strings.Join(pandocFormats, ", ") + "] | Tab: Complete | Enter: Export | Esc: Cancel"
+	case ModeAssetsPrompt:
+		help = m.assetsPrompt()
+	case ModeHistory:
+		help = m.historyPrompt()
+	case ModeJoin:
+		help = "Join session at: " + m.input + " | Enter: Join | Esc: Cancel"
+	case ModeRunPrompt:
+		help = "Run " + m.runBlocks.pending.Language() + " code unsandboxed, with your permissions? (y/n) | Esc: Cancel"
 	case ModeSavePrompt:
 		filename := m.editor.GetDocument().GetFilename()
 		help = fmt.Sprintf("Save changes to %s? (y/n/c)", filename)
+	case ModePastePrompt:
+		help = "Paste as a table? (y/n) | Esc: Cancel"
+	case ModePassphrase:
+		help = m.passphrasePrompt()
 	default:
-		help = "^O Open  ^S Save  ^Q Quit  ^C Copy  ^V Paste  ^X Cut  ^A Select All  ^L Line Numbers  ^F Find  ^H Replace  ^G Goto  ^P Preview"
+		if m.popup != nil {
+			help = "↑/↓ Select | Tab/Enter: Insert | Esc: Close"
+			break
+		}
+		if m.previewMode && m.readOnly {
+			help = "j/k Scroll  Space/b Page  g/G Top/Bottom  n/p Next/Prev Heading  Read-only  ^Q Quit"
+			break
+		}
+		if m.previewMode {
+			help = "j/k Scroll  Space/b Page  g/G Top/Bottom  n/p Next/Prev Heading  ^P Edit  ^Q Quit"
+			break
+		}
+		help = "^O Open  ^S Save  ^Q Quit  ^C Copy  ^V Paste  ^X Cut  ^A Select All  ^L Line Numbers  ^F Find  ^H Replace  ^G Goto  ^P Preview  M-x Command"
 	}
 	
 	// Help bar style - use reverse for background like status bar
@@ -565,6 +842,10 @@ func (m *Model) renderHelpBar() string {
 		Reverse(true).
 		Width(m.width).
 		Align(lipgloss.Center)
+	if m.config.ScreenReader {
+		// Prompts start at the left edge, where the terminal cursor is
+		helpBarStyle = lipgloss.NewStyle().Width(m.width)
+	}
 	
 	helpBar := helpBarStyle.Render(help)
 	
@@ -584,9 +865,15 @@ func (m *Model) screenToBufferSafe(row, col int) ast.BufferPos {
 		row = 0
 	}
 	
-	// Use viewport's safe transformation
+	// Zen mode centers the text column
+	col = max(col-m.textMargin(), 0)
+	
+	// Use viewport's safe transformation, hit-testing the characters as
+	// drawn so clicks after a tab land where they appear
+	viewport := m.editor.GetViewport()
 	screenPos := ast.ScreenPos{Row: row, Col: col}
-	bufferPos := m.editor.GetViewport().ScreenToBuffer(screenPos)
+	line := m.editor.GetDocument().GetLine(viewport.GetTopLine() + row)
+	bufferPos := viewport.ScreenToBufferOnLine(screenPos, line)
 	
 	// Apply document bounds validation using existing ValidatePosition
 	return m.editor.GetDocument().ValidatePosition(bufferPos)
@@ -0,0 +1,26 @@
+package tui
+
+import (
+	tea "github.com/charmbracelet/bubbletea/v2"
+)
+
+func init() {
+	registerCommand(Command{
+		Name:        "overwrite-mode",
+		Description: "Toggle replacing text as you type (Insert)",
+		Run:         (*Model).toggleOverwrite,
+	})
+}
+
+// toggleOverwrite switches typing between inserting and overwriting. The
+// status bar shows OVR and the cursor takes the overwrite style, an
+// underline by default, while overwriting.
+func (m *Model) toggleOverwrite() tea.Cmd {
+	m.editor.SetOverwriteMode(!m.editor.OverwriteMode())
+	if m.editor.OverwriteMode() {
+		m.showMessage("Overwrite mode")
+	} else {
+		m.showMessage("Insert mode")
+	}
+	return nil
+}
@@ -0,0 +1,56 @@
+package tui
+
+import (
+	"strings"
+
+	"github.com/ofri/mde/pkg/ast"
+)
+
+// paste inserts text from the clipboard or the terminal. A URL pasted
+// over selected text links it when smart_link_paste is set, and tab- or
+// comma-separated data may become a pipe table instead, as the
+// paste_tables setting chooses.
+func (m *Model) paste(text string) {
+	if text == "" {
+		return
+	}
+	if m.suggest.on {
+		m.suggestInsertion(text)
+		return
+	}
+	if url := strings.TrimSpace(text); m.config.SmartLinkPaste && !m.plainText() && looksLikeURL(url) && m.editor.GetSelectionText() != "" {
+		m.editor.WrapLink(url)
+		return
+	}
+	if m.plainText() || m.config.PasteTables == "never" {
+		m.editor.InsertText(text)
+		return
+	}
+	table, ok := ast.TableFromDelimited(text)
+	switch {
+	case !ok:
+		m.editor.InsertText(text)
+	case m.config.PasteTables == "always":
+		m.editor.InsertTable(table)
+	default:
+		m.pendingPaste = text
+		m.mode = ModePastePrompt
+	}
+}
+
+// handlePastePrompt answers the paste prompt: y pastes a table, n the
+// text as it was.
+func (m *Model) handlePastePrompt(key string) {
+	text := m.pendingPaste
+	switch key {
+	case "y", "Y":
+		table, _ := ast.TableFromDelimited(text)
+		m.editor.InsertTable(table)
+	case "n", "N":
+		m.editor.InsertText(text)
+	default:
+		return
+	}
+	m.pendingPaste = ""
+	m.mode = ModeNormal
+}
@@ -0,0 +1,126 @@
+package tui
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/ofri/mde/internal/clipboard"
+	"github.com/ofri/mde/pkg/ast"
+)
+
+// clipboardImageMsg carries what the system clipboard held when Ctrl+V
+// was pressed: an image, or the text to paste instead.
+type clipboardImageMsg struct {
+	doc      *ast.Document
+	revision int
+	text     string
+	image    []byte
+	ext      string
+	err      error
+}
+
+// pasteClipboard pastes the clipboard for Ctrl+V. In a markdown file, an
+// image on the system clipboard is saved under assets_dir and linked
+// instead, when platform tooling can read it; checking takes a moment,
+// so it happens in the background.
+func (m *Model) pasteClipboard() tea.Cmd {
+	text := m.editor.Clipboard()
+	doc := m.editor.GetDocument()
+	if m.plainText() || m.readOnly || !clipboard.CanReadImage() {
+		m.paste(text)
+		return nil
+	}
+	revision := doc.Revision()
+	return func() tea.Msg {
+		image, ext, err := clipboard.ReadImage()
+		return clipboardImageMsg{doc: doc, revision: revision, text: text, image: image, ext: ext, err: err}
+	}
+}
+
+// handleClipboardImage links the image read by pasteClipboard, or pastes
+// the text when there was none, unless the buffer changed meanwhile.
+func (m *Model) handleClipboardImage(msg clipboardImageMsg) (tea.Model, tea.Cmd) {
+	doc := m.editor.GetDocument()
+	if msg.doc != doc || msg.revision != doc.Revision() || m.mode != ModeNormal || m.previewMode {
+		return m, nil
+	}
+	switch {
+	case errors.Is(msg.err, clipboard.ErrNoImage) || errors.Is(msg.err, clipboard.ErrUnavailable):
+		m.paste(msg.text)
+		return m, nil
+	case msg.err != nil:
+		m.reportFailure("Reading the clipboard image", msg.err)
+		return m, nil
+	case doc.GetFilename() == "" || remoteFile(doc.GetFilename()):
+		m.showMessage("Save the document to a file before pasting an image")
+		return m, nil
+	}
+
+	path, err := saveAsset(m.assetsDir(), msg.image, msg.ext, time.Now())
+	if err != nil {
+		m.reportFailure("Saving the clipboard image", err)
+		return m, nil
+	}
+	m.editor.InsertText("![](" + imageDestination(filepath.Dir(doc.GetFilename()), path) + ")")
+	m.showMessage("Pasted image saved to " + path)
+	return m, nil
+}
+
+// assetsDir returns the directory pasted images are saved in.
+func (m *Model) assetsDir() string {
+	dir := m.config.AssetsDir
+	if dir == "" {
+		dir = "assets"
+	}
+	if filepath.IsAbs(dir) {
+		return dir
+	}
+	return filepath.Join(filepath.Dir(m.editor.GetDocument().GetFilename()), dir)
+}
+
+// saveAsset writes an image into dir as img-YYYYMMDD-HHMMSS.ext, numbering
+// it when several are pasted within a second, and returns its path.
+func saveAsset(dir string, image []byte, ext string, now time.Time) (string, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", err
+	}
+	base := "img-" + now.Format("20060102-150405")
+	for n := 1; ; n++ {
+		name := base + ext
+		if n > 1 {
+			name = fmt.Sprintf("%s-%d%s", base, n, ext)
+		}
+		path := filepath.Join(dir, name)
+		file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+		if errors.Is(err, os.ErrExist) {
+			continue
+		}
+		if err != nil {
+			return "", err
+		}
+		if _, err := file.Write(image); err != nil {
+			file.Close()
+			return "", err
+		}
+		return path, file.Close()
+	}
+}
+
+// imageDestination returns the link to path from a document in docDir:
+// relative and slash separated where possible, in angle brackets when it
+// has spaces.
+func imageDestination(docDir, path string) string {
+	dest := path
+	if rel, err := filepath.Rel(docDir, path); err == nil {
+		dest = filepath.ToSlash(rel)
+	}
+	if strings.ContainsAny(dest, " ()") {
+		return "<" + dest + ">"
+	}
+	return dest
+}
@@ -0,0 +1,112 @@
+package tui
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+func init() {
+	registerCommand(Command{
+		Name:        "perf-hud",
+		Description: "Toggle an overlay with frame render and parse times, document size and allocations",
+		Run:         (*Model).togglePerfHUD,
+	})
+}
+
+// perfStats holds the measurements shown by the performance HUD.
+type perfStats struct {
+	enabled bool
+
+	render time.Duration // Rendering the text area in the last frame
+	parse  time.Duration // The last full parse of the document
+
+	// Heap allocations made while rendering the last frame
+	allocs     uint64
+	allocBytes uint64
+}
+
+var perfHUDStyle = lipgloss.NewStyle().Reverse(true)
+
+// togglePerfHUD shows or hides the performance overlay.
+func (m *Model) togglePerfHUD() tea.Cmd {
+	m.perf.enabled = !m.perf.enabled
+	if m.perf.enabled {
+		m.showMessage("Performance HUD on")
+	} else {
+		m.showMessage("Performance HUD off")
+	}
+	return nil
+}
+
+// measureFrame runs render, recording its duration and allocations when
+// the HUD is on. Reading memory statistics costs a little, so nothing is
+// measured otherwise.
+func (m *Model) measureFrame(render func() string) string {
+	if !m.perf.enabled {
+		return render()
+	}
+
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	start := time.Now()
+	content := render()
+	m.perf.render = time.Since(start)
+	runtime.ReadMemStats(&after)
+
+	m.perf.allocs = after.Mallocs - before.Mallocs
+	m.perf.allocBytes = after.TotalAlloc - before.TotalAlloc
+	return content
+}
+
+// overlayPerfHUD draws the HUD over the top-right corner of content.
+func (m *Model) overlayPerfHUD(content string) string {
+	if !m.perf.enabled {
+		return content
+	}
+
+	hud := perfHUDStyle.Render(m.perfSummary())
+	lines := strings.Split(content, "\n")
+	col := max(m.width-ansi.StringWidth(hud), 0)
+	lines[0] = overlayLine(lines[0], hud, col)
+	return strings.Join(lines, "\n")
+}
+
+// perfSummary formats the measurements on one line.
+func (m *Model) perfSummary() string {
+	doc := m.editor.GetDocument()
+	size := 0
+	for i := 0; i < doc.LineCount(); i++ {
+		size += len(doc.GetLine(i)) + 1
+	}
+
+	return fmt.Sprintf(" render %s  parse %s  %d lines %s  %d allocs %s ",
+		formatDuration(m.perf.render),
+		formatDuration(m.perf.parse),
+		doc.LineCount(),
+		formatBytes(uint64(max(size-1, 0))),
+		m.perf.allocs,
+		formatBytes(m.perf.allocBytes))
+}
+
+// formatDuration shows d in milliseconds with microsecond precision.
+func formatDuration(d time.Duration) string {
+	return fmt.Sprintf("%.2fms", float64(d.Microseconds())/1000)
+}
+
+// formatBytes shows n in B, KB or MB.
+func formatBytes(n uint64) string {
+	switch {
+	case n >= 1<<20:
+		return fmt.Sprintf("%.1fMB", float64(n)/(1<<20))
+	case n >= 1<<10:
+		return fmt.Sprintf("%.1fKB", float64(n)/(1<<10))
+	default:
+		return fmt.Sprintf("%dB", n)
+	}
+}
@@ -0,0 +1,21 @@
+package tui
+
+import (
+	"github.com/ofri/mde/pkg/plugin"
+)
+
+// plainText reports whether the current file is opened with the plain
+// parser (.txt, .log, ...), where markdown features are turned off.
+func (m *Model) plainText() bool {
+	return plugin.GetRegistry().ParserNameForFile(m.editor.GetDocument().GetFilename()) == "plain"
+}
+
+// requireMarkdown reports whether a markdown-only feature may run,
+// explaining why not for plain-text files.
+func (m *Model) requireMarkdown(feature string) bool {
+	if m.plainText() {
+		m.showMessage(feature + " is only available in markdown files")
+		return false
+	}
+	return true
+}
@@ -0,0 +1,180 @@
+package tui
+
+import (
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// maxPopupItems bounds the popup height; longer lists scroll.
+const maxPopupItems = 8
+
+// popupItem is one completion candidate.
+type popupItem struct {
+	label  string // Shown in the popup
+	insert string // Replaces the typed prefix when accepted
+}
+
+// completionPopup is a list of candidates drawn under the cursor.
+// Accepting an item replaces the replace runes before the cursor.
+type completionPopup struct {
+	items    []popupItem
+	selected int
+	replace  int
+
+	// refresh recomputes the popup after an edit, or returns nil once the
+	// cursor has left the completion context
+	refresh func(m *Model) *completionPopup
+}
+
+// handlePopupKey handles navigation keys while the popup is open.
+// Returns false for keys the editor should process instead.
+func (m *Model) handlePopupKey(msg tea.KeyPressMsg) bool {
+	p := m.popup
+	switch msg.String() {
+	case "up", "ctrl+p":
+		p.selected = (p.selected - 1 + len(p.items)) % len(p.items)
+	case "down", "ctrl+n":
+		p.selected = (p.selected + 1) % len(p.items)
+	case "tab", "enter":
+		m.acceptPopup()
+	case "esc":
+		m.popup = nil
+	default:
+		return false
+	}
+	return true
+}
+
+// acceptPopup inserts the selected item and closes the popup.
+func (m *Model) acceptPopup() {
+	p := m.popup
+	m.popup = nil
+	m.replaceBeforeCursor(p.replace, p.items[p.selected].insert)
+}
+
+// refreshPopup re-runs the popup's source after an edit so the list
+// narrows as the user keeps typing.
+func (m *Model) refreshPopup(p *completionPopup) {
+	if p.refresh == nil {
+		return
+	}
+	if next := p.refresh(m); next != nil && len(next.items) > 0 {
+		m.popup = next
+	}
+}
+
+// render returns the popup box lines, scrolled to keep the selected
+// item visible.
+func (p *completionPopup) render() []string {
+	first := 0
+	if p.selected >= maxPopupItems {
+		first = p.selected - maxPopupItems + 1
+	}
+	last := min(first+maxPopupItems, len(p.items))
+
+	width := 0
+	for _, item := range p.items[first:last] {
+		width = max(width, ansi.StringWidth(item.label))
+	}
+
+	normal := lipgloss.NewStyle().Reverse(true)
+	selected := lipgloss.NewStyle().Bold(true)
+
+	lines := make([]string, 0, last-first)
+	for i := first; i < last; i++ {
+		label := p.items[i].label
+		text := " " + label + strings.Repeat(" ", width-ansi.StringWidth(label)) + " "
+		if i == p.selected {
+			lines = append(lines, selected.Render(text))
+		} else {
+			lines = append(lines, normal.Render(text))
+		}
+	}
+	return lines
+}
+
+// overlayPopup draws the popup onto content, below the cursor row when it
+// fits and above it otherwise.
+func (m *Model) overlayPopup(content string) string {
+	if m.popup == nil {
+		return content
+	}
+
+	screen, err := m.editor.GetCursor().GetScreenPos()
+	if err != nil {
+		return content
+	}
+
+	return overlayBox(content, m.popup.render(), screen.Row, max(screen.Col-m.popup.replace-1, 0))
+}
+
+// overlayBox draws box onto content from column col, below row when it
+// fits and above it otherwise.
+func overlayBox(content string, box []string, row, col int) string {
+	lines := strings.Split(content, "\n")
+	top := row + 1
+	if top+len(box) > len(lines) && row-len(box) >= 0 {
+		top = row - len(box)
+	}
+	for i, boxLine := range box {
+		if top+i < 0 || top+i >= len(lines) {
+			continue
+		}
+		lines[top+i] = overlayLine(lines[top+i], boxLine, col)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// overlayLine replaces the cells of line starting at col with overlay,
+// padding short lines with spaces.
+func overlayLine(line, overlay string, col int) string {
+	left := ansi.Truncate(line, col, "")
+	if width := ansi.StringWidth(left); width < col {
+		left += strings.Repeat(" ", col-width)
+	}
+	right := ansi.TruncateLeft(line, col+ansi.StringWidth(overlay), "")
+	return left + overlay + right
+}
+
+// overlayPanel draws the panel render formats, given the height of
+// content, down the right edge of content.
+func (m *Model) overlayPanel(content string, render func(height int) []string) string {
+	lines := strings.Split(content, "\n")
+	for i, panelLine := range render(len(lines)) {
+		lines[i] = overlayLine(lines[i], panelLine, max(m.width-ansi.StringWidth(panelLine), 0))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// renderPanel formats a panel of at most height lines: a header, then
+// as many rows as fit, scrolled to keep the current one in view and
+// highlighted. current is -1 when no row is.
+func renderPanel(header string, rows []string, current, height int) []string {
+	if height < 2 {
+		return nil
+	}
+	width := ansi.StringWidth(header)
+	for _, row := range rows {
+		width = max(width, ansi.StringWidth(row))
+	}
+	pad := func(text string) string {
+		return " " + text + strings.Repeat(" ", width-ansi.StringWidth(text)) + " "
+	}
+	normal := lipgloss.NewStyle().Reverse(true)
+	selected := lipgloss.NewStyle().Bold(true)
+
+	panel := []string{normal.Bold(true).Render(pad(header))}
+	first := max(current-(height-1)+1, 0)
+	last := min(first+height-1, len(rows))
+	for i := first; i < last; i++ {
+		if i == current {
+			panel = append(panel, selected.Render(pad(rows[i])))
+		} else {
+			panel = append(panel, normal.Render(pad(rows[i])))
+		}
+	}
+	return panel
+}
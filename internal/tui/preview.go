@@ -0,0 +1,176 @@
+package tui
+
+import (
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/ofri/mde/pkg/ast"
+)
+
+// previewState is the preview's own scroll position. Preview scrolls
+// independently of the editor so reading never moves the edit viewport.
+type previewState struct {
+	top int // Document line at the top of the preview
+
+	// Cursor position when preview was last left; if the cursor has not
+	// moved since, re-entering preview restores top instead of syncing
+	leftAt *ast.BufferPos
+}
+
+// setPreviewMode switches between editing and preview. Entering preview
+// scrolls it to the block containing the cursor unless the cursor is where
+// it was when preview was last closed, in which case the reading position
+// is kept.
+func (m *Model) setPreviewMode(enabled bool) {
+	if enabled == m.previewMode {
+		return
+	}
+	m.previewMode = enabled
+
+	cursor := m.editor.GetCursor().GetBufferPos()
+	if !enabled {
+		m.preview.leftAt = &cursor
+		return
+	}
+	if m.preview.leftAt == nil || *m.preview.leftAt != cursor {
+		m.syncPreviewToCursor()
+	}
+	m.clampPreviewTop()
+}
+
+// syncPreviewToCursor scrolls preview so the start of the block holding
+// the cursor is visible, keeping the editor's scroll position when it
+// already shows the block.
+func (m *Model) syncPreviewToCursor() {
+	top := m.editor.GetViewport().GetTopLine()
+	start := blockStart(m.editor.GetDocument(), m.editor.GetCursor().GetBufferPos().Line)
+	m.preview.top = min(top, start)
+}
+
+// handlePreviewKey handles reading navigation while previewing. Returns
+// false for the global keys (saving, quitting, toggling preview, commands)
+// that work as in the editor; every other key is swallowed so reading
+// never edits the document or moves the cursor.
+func (m *Model) handlePreviewKey(msg tea.KeyPressMsg) bool {
+	page := max(m.GetContentHeight()-1, 1)
+
+	switch msg.String() {
+	case "j", "down":
+		m.scrollPreview(1)
+	case "k", "up":
+		m.scrollPreview(-1)
+	case "space", "pgdown", "ctrl+d":
+		m.scrollPreview(page)
+	case "b", "shift+space", "pgup", "ctrl+u":
+		m.scrollPreview(-page)
+	case "g", "home":
+		m.preview.top = 0
+	case "G", "end":
+		m.preview.top = max(m.editor.GetDocument().LineCount()-m.GetContentHeight(), 0)
+	case "n":
+		m.jumpPreviewHeading(1)
+	case "p":
+		m.jumpPreviewHeading(-1)
+	case "ctrl+p", "ctrl+q", "ctrl+c", "ctrl+s", "ctrl+o", "ctrl+l", "alt+x", "alt+z":
+		return false
+	default:
+		// Editing and cursor keys do nothing while reading
+	}
+	return true
+}
+
+// jumpPreviewHeading scrolls preview so the next (dir 1) or previous
+// (dir -1) heading after the top line becomes the top line.
+func (m *Model) jumpPreviewHeading(dir int) {
+	headings := headingLines(m.editor.GetDocument())
+	if dir > 0 {
+		for _, line := range headings {
+			if line > m.preview.top {
+				m.preview.top = line
+				m.clampPreviewTop()
+				return
+			}
+		}
+		m.showMessage("No next heading")
+		return
+	}
+	for i := len(headings) - 1; i >= 0; i-- {
+		if headings[i] < m.preview.top {
+			m.preview.top = headings[i]
+			return
+		}
+	}
+	m.showMessage("No previous heading")
+}
+
+// headingLines returns the lines of ATX and setext headings outside
+// fenced code, in order. Setext headings are reported at their text line.
+func headingLines(doc *ast.Document) []int {
+	fences := doc.FencedBlocks()
+	var lines []int
+	for i := 0; i < doc.LineCount(); i++ {
+		inFence := false
+		for _, fence := range fences {
+			if fence.Contains(i) {
+				inFence = true
+				break
+			}
+		}
+		if inFence {
+			continue
+		}
+		if level, underline := doc.SetextHeadingAt(i); level > 0 {
+			if !underline {
+				lines = append(lines, i)
+			}
+			continue
+		}
+		text := strings.TrimSpace(doc.GetLine(i))
+		if level := len(text) - len(strings.TrimLeft(text, "#")); level >= 1 && level <= 6 &&
+			(len(text) == level || text[level] == ' ') {
+			lines = append(lines, i)
+		}
+	}
+	return lines
+}
+
+// scrollPreview moves the preview by lines, negative scrolling up.
+func (m *Model) scrollPreview(lines int) {
+	m.preview.top += lines
+	m.clampPreviewTop()
+}
+
+func (m *Model) clampPreviewTop() {
+	maxTop := m.editor.GetDocument().LineCount() - 1
+	m.preview.top = max(0, min(m.preview.top, maxTop))
+}
+
+// previewViewport is the editor viewport scrolled to the preview position.
+func (m *Model) previewViewport() *ast.Viewport {
+	return m.editor.GetViewport().WithTopLine(m.preview.top)
+}
+
+// blockStart returns the first line of the markdown block containing line:
+// the opening fence of a code block, or the first line of the paragraph,
+// list or quote run it belongs to.
+func blockStart(doc *ast.Document, line int) int {
+	fences := doc.FencedBlocks()
+	for _, fence := range fences {
+		if fence.Contains(line) {
+			return fence.StartLine
+		}
+	}
+	if strings.TrimSpace(doc.GetLine(line)) == "" {
+		return line
+	}
+
+	for line > 0 && strings.TrimSpace(doc.GetLine(line-1)) != "" {
+		for _, fence := range fences {
+			if fence.Contains(line - 1) {
+				return line
+			}
+		}
+		line--
+	}
+	return line
+}
@@ -0,0 +1,58 @@
+package tui
+
+import (
+	"path/filepath"
+
+	"github.com/ofri/mde/internal/config"
+	"github.com/ofri/mde/internal/editorconfig"
+)
+
+// projectState is the project settings applied over the user's: those of
+// the .mde.toml found for the open file, if any, over those of its
+// .editorconfig.
+type projectState struct {
+	filename     string // The file they were looked up for
+	restore      func() // Puts the user's settings back, nil if none apply
+	editorConfig editorconfig.Settings
+}
+
+// syncProject applies the settings of the open file's project when
+// another file is opened, putting the user's back first and noting
+// whether the file ends with a newline, and sets the editor's tab width
+// to the one in effect.
+func (m *Model) syncProject() {
+	if filename := m.editor.GetDocument().GetFilename(); filename != m.project.filename {
+		m.loadProject(filename)
+		m.checkFinalNewline()
+	}
+	m.editor.SetTabWidth(m.config.TabWidth)
+}
+
+// loadProject applies the settings of filename's project, found by
+// walking up from its directory. Remote files have none.
+func (m *Model) loadProject(filename string) {
+	if m.project.restore != nil {
+		m.project.restore()
+	}
+	m.project = projectState{filename: filename}
+	if filename == "" || remoteFile(filename) {
+		return
+	}
+	m.project.restore = m.loadEditorConfig(filename)
+	path := config.FindProject(filepath.Dir(filename))
+	if path == "" {
+		return
+	}
+	project, err := config.LoadProject(path)
+	if err != nil {
+		m.showMessage("Project settings: " + err.Error())
+		return
+	}
+	restoreEditorConfig, restoreProject := m.project.restore, project.Apply(m.config)
+	m.project.restore = func() {
+		restoreProject()
+		if restoreEditorConfig != nil {
+			restoreEditorConfig()
+		}
+	}
+}
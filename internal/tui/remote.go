@@ -0,0 +1,97 @@
+package tui
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/ofri/mde/internal/remote"
+	"github.com/ofri/mde/pkg/ast"
+)
+
+// transferTimeout bounds loading a remote file
+const transferTimeout = 2 * time.Minute
+
+var spinnerFrames = []rune("⠋⠙⠹⠸⠼⠴⠦⠧⠇⠏")
+
+// transferState tracks a remote file being loaded or saved in the
+// background. The status line shows a spinner until it finishes.
+type transferState struct {
+	verb    string // "Loading" or "Saving"; "" when idle
+	name    string
+	started time.Time
+	frame   int
+
+	// pending is a load requested before the program started, run by Init
+	pending string
+}
+
+type transferTickMsg struct{}
+
+func (t *transferState) active() bool {
+	return t.verb != ""
+}
+
+func (t *transferState) start(verb, name string) {
+	*t = transferState{verb: verb, name: name, started: time.Now()}
+}
+
+func (t *transferState) status() string {
+	return fmt.Sprintf("%s %s %c %ds", t.verb, t.name, spinnerFrames[t.frame%len(spinnerFrames)],
+		int(time.Since(t.started).Seconds()))
+}
+
+// remoteFile reports whether filename is a URL served by a file backend
+func remoteFile(filename string) bool {
+	return ast.FileScheme(filename) != ""
+}
+
+// readOnlyURL reports whether filename is a web URL, which is opened for
+// reading in preview
+func readOnlyURL(filename string) bool {
+	scheme := ast.FileScheme(filename)
+	return scheme == "http" || scheme == "https"
+}
+
+// openRemote queues loading filename; the buffer is replaced when it
+// arrives. Init starts the load when the program starts.
+func (m *Model) openRemote(filename string) {
+	m.transfer.start("Loading", filename)
+	m.transfer.pending = filename
+	m.showMessage(m.transfer.status())
+}
+
+// startTransfer runs the load queued by openRemote.
+func (m *Model) startTransfer() tea.Cmd {
+	filename := m.transfer.pending
+	if filename == "" {
+		return nil
+	}
+	m.transfer.pending = ""
+	return tea.Batch(m.loadFile(filename), transferTick())
+}
+
+func transferTick() tea.Cmd {
+	return tea.Tick(100*time.Millisecond, func(time.Time) tea.Msg {
+		return transferTickMsg{}
+	})
+}
+
+func (m *Model) handleTransferTick() (tea.Model, tea.Cmd) {
+	if !m.transfer.active() {
+		return m, nil
+	}
+	m.transfer.frame++
+	m.showMessage(m.transfer.status())
+	return m, transferTick()
+}
+
+// transferError describes a failed transfer, noting that the buffer is
+// kept when the connection dropped.
+func transferError(verb string, err error) string {
+	if errors.Is(err, remote.ErrConnection) {
+		return "Error " + verb + " file: " + err.Error() + " (buffer kept, try again)"
+	}
+	return "Error " + verb + " file: " + err.Error()
+}
@@ -0,0 +1,199 @@
+package tui
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/ofri/mde/internal/filter"
+	"github.com/ofri/mde/pkg/ast"
+)
+
+// outputLanguage is the info string of the fence run-block writes its
+// output into, below the code.
+const outputLanguage = "output"
+
+// runBlockState tracks running code blocks: whether running unsandboxed
+// code was confirmed this session, and the block awaiting confirmation.
+type runBlockState struct {
+	confirmed bool
+	pending   ast.FencedBlock
+	running   bool
+}
+
+// blockRanMsg reports the output of a code block, run as of the
+// document's revision then.
+type blockRanMsg struct {
+	doc      *ast.Document
+	revision int
+	block    ast.FencedBlock
+	output   string
+	err      error
+}
+
+func init() {
+	registerCommand(Command{
+		Name:        "run-block",
+		Description: "Run the code block under the cursor with its interpreter and show the output below it",
+		Run:         (*Model).runBlock,
+		Markdown:    true,
+	})
+}
+
+// runBlock runs the fenced block under the cursor with the interpreter
+// configured for its language, asking first, once a session, since the
+// code runs with the user's permissions.
+func (m *Model) runBlock() tea.Cmd {
+	doc := m.editor.GetDocument()
+	block, ok := doc.FencedBlockAt(m.editor.GetCursor().GetBufferPos().Line)
+	lang := block.Language()
+	switch {
+	case !ok || lang == outputLanguage:
+		m.showMessage("No code block under cursor")
+		return nil
+	case !block.Closed:
+		m.showMessage("Close the code fence before running it")
+		return nil
+	case lang == "":
+		m.showMessage("The code block names no language")
+		return nil
+	case m.config.Interpreters[lang] == "":
+		m.showMessage("No interpreter for " + lang + " (see interpreters in the config)")
+		return nil
+	case m.readOnly:
+		m.showMessage("The document is read-only")
+		return nil
+	case m.runBlocks.running:
+		m.showMessage("A code block is still running")
+		return nil
+	}
+
+	if !m.runBlocks.confirmed {
+		m.runBlocks.pending = block
+		m.mode = ModeRunPrompt
+		return nil
+	}
+	return m.startBlock(block)
+}
+
+// handleRunPrompt answers the confirmation: y runs the block and stops
+// asking for the rest of the session, n cancels.
+func (m *Model) handleRunPrompt(key string) tea.Cmd {
+	switch key {
+	case "y", "Y":
+		m.mode = ModeNormal
+		m.runBlocks.confirmed = true
+		return m.startBlock(m.runBlocks.pending)
+	case "n", "N":
+		m.mode = ModeNormal
+		m.showMessage("Code block not run")
+	}
+	return nil
+}
+
+// startBlock runs block in the background; handleBlockRan writes the
+// output.
+func (m *Model) startBlock(block ast.FencedBlock) tea.Cmd {
+	doc := m.editor.GetDocument()
+	lang := block.Language()
+	interpreter := m.config.Interpreters[lang]
+	source := doc.FencedBlockSource(block) + "\n"
+	timeout := time.Duration(max(m.config.RunTimeout, 1)) * time.Second
+	revision := doc.Revision()
+
+	m.runBlocks.running = true
+	m.showMessage("Running " + lang + " block...")
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+		output, err := filter.Capture(ctx, interpreter, source, "."+lang)
+		return blockRanMsg{doc: doc, revision: revision, block: block, output: output, err: err}
+	}
+}
+
+// handleBlockRan writes the output into the output block under the code,
+// replacing the one from an earlier run, unless the text changed while
+// the code ran.
+func (m *Model) handleBlockRan(msg blockRanMsg) (tea.Model, tea.Cmd) {
+	m.runBlocks.running = false
+	doc := m.editor.GetDocument()
+	if msg.doc != doc || msg.revision != doc.Revision() {
+		m.showMessage("Code block output discarded: the text changed meanwhile")
+		return m, nil
+	}
+
+	text := outputBlock(msg.output, msg.err, max(m.config.RunTimeout, 1))
+	target := ast.BufferPos{Line: msg.block.EndLine, Col: doc.GetLineLength(msg.block.EndLine)}
+	r := ast.Range{Start: target, End: target}
+	if previous, ok := outputBlockAfter(doc, msg.block); ok {
+		r = ast.Range{
+			Start: ast.BufferPos{Line: previous.StartLine},
+			End:   ast.BufferPos{Line: previous.EndLine, Col: doc.GetLineLength(previous.EndLine)},
+		}
+	} else {
+		text = "\n\n" + text
+	}
+
+	cursor := m.editor.GetCursor().GetBufferPos()
+	m.editor.ReplaceRange(r, text)
+	m.editor.GetCursor().SetBufferPos(doc.ValidatePosition(cursor))
+	m.editor.AdjustViewPort()
+
+	switch {
+	case errors.Is(msg.err, filter.ErrTimeout):
+		m.showMessage("Code block timed out")
+	case msg.err != nil:
+		m.showMessage("Code block failed: " + msg.err.Error())
+	default:
+		m.showMessage("Code block ran")
+	}
+	return m, nil
+}
+
+// outputBlockAfter returns the output block of an earlier run of block:
+// an output fence right below it, or after one blank line.
+func outputBlockAfter(doc *ast.Document, block ast.FencedBlock) (ast.FencedBlock, bool) {
+	for _, next := range doc.FencedBlocks() {
+		if next.StartLine <= block.EndLine {
+			continue
+		}
+		gap := next.StartLine - block.EndLine - 1
+		adjacent := gap == 0 || (gap == 1 && strings.TrimSpace(doc.GetLine(block.EndLine+1)) == "")
+		return next, adjacent && next.Closed && next.Language() == outputLanguage
+	}
+	return ast.FencedBlock{}, false
+}
+
+// outputBlock fences the output of a run, noting how it failed, with a
+// fence longer than any run of backticks in it.
+func outputBlock(output string, err error, timeout int) string {
+	lines := []string{}
+	if output = strings.TrimRight(output, "\n"); output != "" {
+		lines = append(lines, output)
+	}
+	switch {
+	case errors.Is(err, filter.ErrTimeout):
+		lines = append(lines, fmt.Sprintf("[timed out after %ds]", timeout))
+	case err != nil:
+		lines = append(lines, "["+err.Error()+"]")
+	}
+	body := strings.Join(lines, "\n")
+
+	longest, run := 0, 0
+	for _, r := range body {
+		if r == '`' {
+			run++
+			longest = max(longest, run)
+		} else {
+			run = 0
+		}
+	}
+	fence := strings.Repeat("`", max(3, longest+1))
+	if body == "" {
+		return fence + outputLanguage + "\n" + fence
+	}
+	return fence + outputLanguage + "\n" + body + "\n" + fence
+}
@@ -0,0 +1,110 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/charmbracelet/x/ansi"
+)
+
+func init() {
+	registerCommand(Command{
+		Name:        "screen-reader",
+		Description: "Toggle plain output for terminal screen readers",
+		Run:         (*Model).toggleScreenReader,
+	})
+}
+
+// toggleScreenReader flips screen reader mode for this session; the
+// screen_reader setting chooses the initial state.
+func (m *Model) toggleScreenReader() tea.Cmd {
+	m.config.ScreenReader = !m.config.ScreenReader
+	if m.config.ScreenReader {
+		m.showMessage("Screen reader mode on")
+	} else {
+		m.showMessage("Screen reader mode off")
+	}
+	return nil
+}
+
+// modeNames are read out for the prompts in screen reader mode.
+var modeNames = map[EditorMode]string{
+	ModeFind:        "Find",
+	ModeReplace:     "Replace",
+	ModeGoto:        "Go to line",
+	ModeCommand:     "Command",
+	ModeTemplate:    "Template",
+	ModeSavePrompt:  "Save changes",
+	ModePastePrompt: "Paste as table",
+	ModePassphrase:  "Passphrase",
+	ModeTheme:       "Theme",
+	ModeRename:      "Rename",
+	ModeFilter:      "Filter through command",
+	ModeRunPrompt:   "Run code",
+	ModeExport:      "Export to format",
+
+	ModeAssetsPrompt: "Clean unused assets",
+	ModeComment:      "Comment",
+	ModeHistory:      "History",
+	ModeJoin:         "Join session",
+}
+
+// vimModeNames are read out for vim's modes in screen reader mode.
+var vimModeNames = map[vimMode]string{
+	vimNormal:     "Normal",
+	vimInsert:     "Insert",
+	vimVisual:     "Visual",
+	vimVisualLine: "Visual line",
+}
+
+// modeName names what keys do right now: the open prompt, preview, or the
+// kind of editing.
+func (m *Model) modeName() string {
+	if name, ok := modeNames[m.mode]; ok {
+		return name
+	}
+	switch {
+	case m.previewMode:
+		return "Preview"
+	case m.vimEnabled():
+		return vimModeNames[m.vim.mode]
+	case m.suggest.on:
+		return "Suggest"
+	case m.editor.OverwriteMode():
+		return "Overwrite"
+	default:
+		return "Edit"
+	}
+}
+
+// screenReaderStatus is the status bar in screen reader mode: plain fields
+// in a fixed order, as "Edit | notes.md [Modified] | Line 3, Column 5 |
+// Saved", so a change of mode or a message is read out the same way each
+// time.
+func (m *Model) screenReaderStatus(filename string) string {
+	pos := m.editor.GetCursor().GetBufferPos()
+	fields := []string{m.modeName(), filename}
+	if crumb := m.breadcrumb(m.width); crumb != "" {
+		fields = append(fields, crumb)
+	}
+	fields = append(fields, fmt.Sprintf("Line %d, Column %d", pos.Line+1, pos.Col+1))
+	if pending := m.vim.pending; m.vimEnabled() && pending != "" {
+		fields = append(fields, "Pending "+pending)
+	}
+	if progress := m.goalProgress(); progress != "" {
+		fields = append(fields, progress)
+	}
+	if m.message != "" {
+		fields = append(fields, m.message)
+	} else if m.hover != "" {
+		fields = append(fields, m.hover)
+	} else if diagnostic := m.diagnosticAtCursor(); diagnostic != "" {
+		fields = append(fields, diagnostic)
+	} else if term := m.glossaryAtCursor(); term != "" {
+		fields = append(fields, term)
+	} else if comment := m.commentStatus(); comment != "" {
+		fields = append(fields, comment)
+	}
+	return ansi.Truncate(strings.Join(fields, " | "), m.width, "")
+}
@@ -0,0 +1,38 @@
+package tui
+
+import (
+	tea "github.com/charmbracelet/bubbletea/v2"
+)
+
+// wheelDelta converts a wheel event into a scroll distance in columns and
+// lines, positive meaning right and down. scroll_lines and scroll_columns
+// set the step, natural_scroll inverts it, Shift turns vertical wheel
+// motion sideways (shift_wheel_horizontal) and Alt scrolls a page at a time.
+func (m *Model) wheelDelta(mouse tea.Mouse) (dx, dy int) {
+	switch mouse.Button {
+	case tea.MouseWheelUp:
+		dy = -1
+	case tea.MouseWheelDown:
+		dy = 1
+	case tea.MouseWheelLeft:
+		dx = -1
+	case tea.MouseWheelRight:
+		dx = 1
+	default:
+		return 0, 0
+	}
+
+	if m.config.NaturalScroll {
+		dx, dy = -dx, -dy
+	}
+	if mouse.Mod.Contains(tea.ModShift) && m.config.ShiftWheelHorizontal {
+		dx, dy = dx+dy, 0
+	}
+
+	lines, cols := max(m.config.ScrollLines, 1), max(m.config.ScrollColumns, 1)
+	if mouse.Mod.Contains(tea.ModAlt) {
+		lines = max(m.GetContentHeight()-1, 1)
+		cols = max(m.textWidth()/2, 1)
+	}
+	return dx * cols, dy * lines
+}
@@ -0,0 +1,84 @@
+package tui
+
+import (
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/ofri/mde/pkg/ast"
+)
+
+// searchState is the find prompt in progress: where it was opened, which
+// the cursor returns to on Escape, and the direction searched.
+type searchState struct {
+	origin   ast.BufferPos
+	backward bool
+}
+
+func init() {
+	registerCommand(Command{
+		Name:        "find-next",
+		Description: "Repeat the last search forward",
+		Run:         func(m *Model) tea.Cmd { m.searchAgain(false); return nil },
+	})
+	registerCommand(Command{
+		Name:        "find-previous",
+		Description: "Repeat the last search backward",
+		Run:         func(m *Model) tea.Cmd { m.searchAgain(true); return nil },
+	})
+}
+
+// startFind opens the find prompt, searching backward from the cursor or
+// forward from it.
+func (m *Model) startFind(backward bool) {
+	m.mode = ModeFind
+	m.input = ""
+	m.caseSensitive = false
+	m.search = searchState{origin: m.editor.GetCursor().GetBufferPos(), backward: backward}
+}
+
+// findFromOrigin moves the cursor to the match of the find prompt's input
+// nearest where the prompt was opened, reporting whether there is one.
+func (m *Model) findFromOrigin() bool {
+	r, ok := m.editor.GetDocument().Find(m.input, m.search.origin,
+		ast.SearchOptions{CaseSensitive: m.caseSensitive, Backward: m.search.backward})
+	if !ok {
+		return false
+	}
+	m.editor.GetCursor().SetBufferPos(r.Start)
+	m.editor.AdjustViewPort()
+	return true
+}
+
+// incrementalFind follows the find prompt as it is typed, going back to
+// the origin when the input is cleared or no longer matches.
+func (m *Model) incrementalFind() {
+	if m.input == "" || !m.findFromOrigin() {
+		m.cancelFind()
+	}
+}
+
+// cancelFind puts the cursor back where the find prompt was opened.
+func (m *Model) cancelFind() {
+	m.editor.GetCursor().SetBufferPos(m.search.origin)
+	m.editor.AdjustViewPort()
+}
+
+// searchAgain repeats the last search from the cursor, in the direction it
+// was made or, with reverse, the other way.
+func (m *Model) searchAgain(reverse bool) {
+	if m.lastSearch == "" {
+		m.showMessage("No previous search")
+		return
+	}
+	backward := m.search.backward != reverse
+	doc := m.editor.GetDocument()
+	from := m.editor.GetCursor().GetBufferPos()
+	if !backward {
+		from = doc.MoveCursorRight(from) // Past the match under the cursor
+	}
+	r, ok := doc.Find(m.lastSearch, from, ast.SearchOptions{CaseSensitive: m.caseSensitive, Backward: backward})
+	if !ok {
+		m.showMessage("Not found: " + m.lastSearch)
+		return
+	}
+	m.editor.GetCursor().SetBufferPos(r.Start)
+	m.editor.AdjustViewPort()
+}
@@ -0,0 +1,127 @@
+package tui
+
+import (
+	"unicode/utf8"
+
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/ofri/mde/internal/plugins/renderers"
+	"github.com/ofri/mde/internal/theme"
+	"github.com/ofri/mde/pkg/plugin"
+)
+
+func init() {
+	registerCommand(Command{
+		Name:        "select-word",
+		Description: "Select the word under the cursor",
+		Run:         (*Model).selectWord,
+	})
+	registerCommand(Command{
+		Name:        "select-line",
+		Description: "Select the current line (Alt+L)",
+		Run:         (*Model).selectLine,
+	})
+	registerCommand(Command{
+		Name:        "select-paragraph",
+		Description: "Select the paragraph under the cursor (Alt+P)",
+		Run:         (*Model).selectParagraph,
+	})
+	registerCommand(Command{
+		Name:        "select-list-item",
+		Description: "Select the list item under the cursor",
+		Run:         (*Model).selectListItem,
+		Markdown:    true,
+	})
+	registerCommand(Command{
+		Name:        "select-section",
+		Description: "Select the heading section under the cursor",
+		Run:         (*Model).selectSection,
+		Markdown:    true,
+	})
+	registerCommand(Command{
+		Name:        "expand-selection",
+		Description: "Grow the selection: word, sentence, paragraph, section (Ctrl+W)",
+		Run:         (*Model).expandSelection,
+	})
+}
+
+func (m *Model) selectWord() tea.Cmd {
+	if !m.editor.SelectWord() {
+		m.showMessage("No word under cursor")
+	}
+	return nil
+}
+
+func (m *Model) selectLine() tea.Cmd {
+	m.editor.SelectLine()
+	return nil
+}
+
+func (m *Model) selectParagraph() tea.Cmd {
+	if !m.editor.SelectParagraph() {
+		m.showMessage("No paragraph under cursor")
+	}
+	return nil
+}
+
+func (m *Model) selectListItem() tea.Cmd {
+	if !m.editor.SelectListItem() {
+		m.showMessage("No list item under cursor")
+	}
+	return nil
+}
+
+func (m *Model) selectSection() tea.Cmd {
+	if !m.editor.SelectSection() {
+		m.showMessage("No heading above cursor")
+	}
+	return nil
+}
+
+func (m *Model) expandSelection() tea.Cmd {
+	if !m.editor.ExpandSelection() {
+		m.showMessage("Whole document selected")
+	}
+	return nil
+}
+
+// markSelection draws the selection over the rendered editor lines, in
+// the theme's selection style, reverse video by default.
+func (m *Model) markSelection(lines []plugin.RenderedLine) {
+	selection := m.editor.GetCursor().GetSelection()
+	if selection == nil {
+		return
+	}
+	start, end := selection.Start, selection.End
+	if start.Line > end.Line || (start.Line == end.Line && start.Col > end.Col) {
+		start, end = end, start
+	}
+
+	style := theme.Resolved().Style(theme.Selection)
+	doc := m.editor.GetDocument()
+	viewport := m.editor.GetViewport()
+	left := viewport.GetLeftColumn()
+	prefix := 0
+	if m.editor.ShowLineNumbers() {
+		prefix = viewport.GetLineNumberWidth()
+	}
+	for i := range lines {
+		line := viewport.GetTopLine() + i
+		if line < start.Line || line > end.Line {
+			continue
+		}
+		text := doc.GetLine(line)
+		from, to := 0, utf8.RuneCountInString(text)
+		if line == start.Line {
+			from = start.Col
+		}
+		if line == end.Line {
+			to = end.Col
+		}
+
+		// Document runes to columns of the rendered content
+		length := utf8.RuneCountInString(lines[i].Content)
+		from = max(viewport.DisplayColumn(text, from)-left, 0) + prefix
+		to = min(max(viewport.DisplayColumn(text, to)-left, 0)+prefix, length)
+		lines[i].Styles = renderers.Overlay(lines[i].Styles, from, to, style)
+	}
+}
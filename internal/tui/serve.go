@@ -0,0 +1,100 @@
+package tui
+
+import (
+	"path/filepath"
+
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/ofri/mde/internal/browser"
+	"github.com/ofri/mde/internal/preview"
+	"github.com/ofri/mde/pkg/ast"
+)
+
+// previewServerState is the live HTML preview server, nil when stopped,
+// and the document revision it last showed.
+type previewServerState struct {
+	server   *preview.Server
+	doc      *ast.Document
+	revision int
+}
+
+func init() {
+	registerCommand(Command{
+		Name:        "preview-server",
+		Description: "Serve the document as HTML in the browser, reloading as you edit; again to stop",
+		Run:         (*Model).togglePreviewServer,
+		Markdown:    true,
+	})
+}
+
+// togglePreviewServer starts the preview server and opens it in the
+// browser, or stops it.
+func (m *Model) togglePreviewServer() tea.Cmd {
+	if m.previewServer.server != nil {
+		m.stopPreviewServer()
+		m.showMessage("Preview server stopped")
+		return nil
+	}
+	url, err := m.StartPreviewServer()
+	if err != nil {
+		m.showMessage("Preview server failed: " + err.Error())
+		return nil
+	}
+	return func() tea.Msg {
+		return linkOpenedMsg{url: url, err: browser.Open(url)}
+	}
+}
+
+// StartPreviewServer serves the document on preview_address, returning
+// the URL to open, which the status line shows. The page follows every
+// change to the buffer.
+func (m *Model) StartPreviewServer() (string, error) {
+	if m.previewServer.server != nil {
+		return m.previewServer.server.URL(), nil
+	}
+	doc := m.editor.GetDocument()
+	server, err := preview.Start(m.config.PreviewAddress, m.previewDocument(doc))
+	if err != nil {
+		return "", err
+	}
+	m.previewServer = previewServerState{server: server, doc: doc, revision: doc.Revision()}
+	m.showMessage("Previewing at " + server.URL())
+	return server.URL(), nil
+}
+
+func (m *Model) stopPreviewServer() {
+	if m.previewServer.server == nil {
+		return
+	}
+	_ = m.previewServer.server.Close()
+	m.previewServer = previewServerState{}
+}
+
+// syncPreviewServer sends the buffer to the preview server when it
+// changed, or another file was opened, since last sent.
+func (m *Model) syncPreviewServer() {
+	doc := m.editor.GetDocument()
+	state := &m.previewServer
+	if state.server == nil || (state.doc == doc && state.revision == doc.Revision()) {
+		return
+	}
+	state.server.Update(m.previewDocument(doc))
+	state.doc, state.revision = doc, doc.Revision()
+}
+
+// previewDocument renders doc for the preview server.
+func (m *Model) previewDocument(doc *ast.Document) preview.Document {
+	title := "Untitled"
+	dir := ""
+	if filename := doc.GetFilename(); filename != "" && !remoteFile(filename) {
+		title = filepath.Base(filename)
+		dir = filepath.Dir(filename)
+		if abs, err := filepath.Abs(dir); err == nil {
+			dir = abs
+		}
+	}
+	return preview.Document{
+		Title:   title,
+		Content: []byte(m.convertMarkdownToHTML(doc.GetText())),
+		Dir:     dir,
+	}
+}
@@ -0,0 +1,397 @@
+package tui
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/ofri/mde/internal/crdt"
+	"github.com/ofri/mde/internal/plugins/renderers"
+	"github.com/ofri/mde/internal/share"
+	"github.com/ofri/mde/internal/theme"
+	"github.com/ofri/mde/pkg/ast"
+	"github.com/ofri/mde/pkg/plugin"
+)
+
+// shareState is the shared editing session the buffer is in: the replica
+// of its text kept in step with the others, and where the others' cursors
+// are. A guest has no replica until one is built from the host's welcome.
+type shareState struct {
+	session  *share.Session
+	site     string
+	replica  *crdt.Document
+	early    []crdt.Op // Operations received before the replica was built
+	peers    map[string]*sharePeer
+	revision int // Of the document when last committed
+	cursor   int // Rune offset of the cursor last sent, -1 to send it
+}
+
+// sharePeer is another participant.
+type sharePeer struct {
+	name   string
+	cursor int // Rune offset, -1 until known
+}
+
+// shareMsg is a message from the session, or its end.
+type shareMsg struct {
+	session *share.Session
+	msg     share.Message
+	ended   bool
+}
+
+// shareWelcomeMsg carries the replica built from the host's welcome.
+type shareWelcomeMsg struct {
+	session *share.Session
+	welcome share.Message
+	text    *crdt.Text
+	content string // The replica's visible text
+}
+
+func init() {
+	registerCommand(Command{
+		Name:        "share",
+		Description: "Host a shared session on share_address, in which others who join edit the buffer with you",
+		Run:         (*Model).promptShare,
+	})
+	registerCommand(Command{
+		Name:        "join",
+		Description: "Join a shared session, editing its buffer with the others in it",
+		Run:         (*Model).promptJoin,
+	})
+	registerCommand(Command{
+		Name:        "leave",
+		Description: "Leave the shared session, ending it for everyone if you host it",
+		Run:         (*Model).leaveCommand,
+	})
+}
+
+func (m *Model) promptShare() tea.Cmd {
+	if m.share.session != nil {
+		m.showMessage("Already in a shared session (leave to end it)")
+		return nil
+	}
+	if _, err := m.StartShare(); err != nil {
+		m.showMessage("Sharing failed: " + err.Error())
+		return nil
+	}
+	return waitForShare(m.share.session)
+}
+
+// StartShare hosts a session sharing the buffer on share_address,
+// returning the address others join at, which the status line shows.
+func (m *Model) StartShare() (string, error) {
+	if m.share.session != nil {
+		return "", errors.New("already in a shared session")
+	}
+	session, err := share.Host(m.config.ShareAddress)
+	if err != nil {
+		return "", err
+	}
+	site := crdt.NewSite()
+	doc := m.editor.GetDocument()
+	m.share = shareState{
+		session:  session,
+		site:     site,
+		replica:  crdt.NewDocument(doc, site),
+		peers:    make(map[string]*sharePeer),
+		revision: doc.Revision(),
+		cursor:   -1,
+	}
+	m.showMessage("Sharing at " + session.Addr() + ": others run mde join " + session.Addr())
+	return session.Addr(), nil
+}
+
+func (m *Model) promptJoin() tea.Cmd {
+	switch {
+	case m.share.session != nil:
+		m.showMessage("Already in a shared session (leave to end it)")
+		return nil
+	case m.editor.GetDocument().IsModified():
+		m.showMessage("Save the changes first: joining replaces the buffer")
+		return nil
+	}
+	m.mode = ModeJoin
+	m.input = m.config.ShareAddress
+	return nil
+}
+
+// handleJoin joins the session at the address typed at the prompt.
+func (m *Model) handleJoin() (tea.Model, tea.Cmd) {
+	addr := strings.TrimSpace(m.input)
+	m.mode = ModeNormal
+	m.input = ""
+	if addr == "" {
+		m.showMessage("Join cancelled")
+		return m, nil
+	}
+	if err := m.JoinShare(addr); err != nil {
+		m.showMessage("Joining failed: " + err.Error())
+		return m, nil
+	}
+	return m, waitForShare(m.share.session)
+}
+
+// JoinShare joins the session hosted at addr. The buffer is replaced by
+// the shared one once the host answers.
+func (m *Model) JoinShare(addr string) error {
+	if m.share.session != nil {
+		return errors.New("already in a shared session")
+	}
+	session, err := share.Join(addr)
+	if err != nil {
+		return err
+	}
+	site := crdt.NewSite()
+	m.share = shareState{session: session, site: site, peers: make(map[string]*sharePeer), cursor: -1}
+	session.Send(share.Message{Site: site, Name: m.author()})
+	m.showMessage("Joining the session at " + addr + "...")
+	return nil
+}
+
+func (m *Model) leaveCommand() tea.Cmd {
+	switch {
+	case m.share.session == nil:
+		m.showMessage("Not in a shared session")
+	case m.share.session.Hosting():
+		m.leaveShare("Ended the shared session")
+	default:
+		m.leaveShare("Left the shared session")
+	}
+	return nil
+}
+
+// leaveShare leaves the session, keeping the buffer as it is.
+func (m *Model) leaveShare(message string) {
+	if m.share.session == nil {
+		return
+	}
+	m.share.session.Close()
+	m.share = shareState{}
+	if message != "" {
+		m.showMessage(message)
+	}
+}
+
+// listenShare waits for the session started before the program ran.
+func (m *Model) listenShare() tea.Cmd {
+	if m.share.session == nil {
+		return nil
+	}
+	return waitForShare(m.share.session)
+}
+
+// waitForShare waits for the next message from session, or its end.
+func waitForShare(session *share.Session) tea.Cmd {
+	return func() tea.Msg {
+		msg, ok := <-session.Incoming()
+		return shareMsg{session: session, msg: msg, ended: !ok}
+	}
+}
+
+func (m *Model) handleShareMsg(msg shareMsg) (tea.Model, tea.Cmd) {
+	state := &m.share
+	if msg.session != state.session {
+		return m, nil // A session since left
+	}
+	if msg.ended {
+		m.share = shareState{}
+		m.showMessage("The shared session ended")
+		return m, nil
+	}
+
+	in := msg.msg
+	switch {
+	case in.Left:
+		if peer, ok := state.peers[in.Site]; ok {
+			delete(state.peers, in.Site)
+			m.showMessage(sharePeerName(peer.name) + " left")
+		}
+	case state.replica == nil && in.To == state.site && in.Snapshot != nil:
+		return m, tea.Batch(buildReplica(state.session, state.site, in), waitForShare(state.session))
+	case state.replica == nil:
+		state.early = append(state.early, in.Ops...)
+		m.sharePeer(in)
+	default:
+		if in.Name != "" && in.To == "" && in.Ops == nil && in.Cursor == nil {
+			// Joining: introduce ourselves, and the text when hosting
+			if state.session.Hosting() {
+				snapshot := state.replica.Snapshot()
+				state.session.Send(share.Message{
+					Site:     state.site,
+					To:       in.Site,
+					Name:     m.author(),
+					Path:     m.editor.GetDocument().GetFilename(),
+					Snapshot: &snapshot,
+				})
+			}
+			state.cursor = -1
+			m.showMessage(sharePeerName(in.Name) + " joined")
+		}
+		m.sharePeer(in)
+		m.applyShared(in.Ops)
+	}
+	return m, waitForShare(state.session)
+}
+
+// buildReplica builds the replica site from the snapshot in the host's
+// welcome in the background; a long text takes a while.
+func buildReplica(session *share.Session, site string, welcome share.Message) tea.Cmd {
+	return func() tea.Msg {
+		text := crdt.FromSnapshot(site, *welcome.Snapshot)
+		return shareWelcomeMsg{session: session, welcome: welcome, text: text, content: text.String()}
+	}
+}
+
+// handleShareWelcome replaces the buffer with the host's text, to edit
+// with the others from now on. Operations received while the replica was
+// being built are applied to it.
+func (m *Model) handleShareWelcome(msg shareWelcomeMsg) (tea.Model, tea.Cmd) {
+	state := &m.share
+	if msg.session != state.session || state.replica != nil {
+		return m, nil // A session since left
+	}
+	in := msg.welcome
+	m.NewBuffer(msg.content, "")
+	doc := m.editor.GetDocument()
+	state.replica = crdt.JoinDocument(doc, msg.text)
+	m.sharePeer(in)
+	m.applyShared(state.early)
+	state.early = nil
+	m.editor.GetCursor().SetBufferPos(ast.BufferPos{})
+	doc.ClearModified()
+	state.revision = doc.Revision()
+
+	name := "an unnamed buffer"
+	if in.Path != "" {
+		name = filepath.Base(in.Path)
+	}
+	m.showMessage(fmt.Sprintf("Joined %s's session on %s", sharePeerName(in.Name), name))
+	return m, nil
+}
+
+// sharePeer records what a message tells of its sender.
+func (m *Model) sharePeer(in share.Message) {
+	peer, ok := m.share.peers[in.Site]
+	if !ok {
+		peer = &sharePeer{cursor: -1}
+		m.share.peers[in.Site] = peer
+	}
+	if in.Name != "" {
+		peer.name = in.Name
+	}
+	if in.Cursor != nil {
+		peer.cursor = *in.Cursor
+	}
+}
+
+// sharePeerName is how a participant is named to the others.
+func sharePeerName(name string) string {
+	if name == "" {
+		return "Someone"
+	}
+	return name
+}
+
+// applyShared applies operations from the others to the buffer, moving
+// the cursors to stay on the text they were on.
+func (m *Model) applyShared(ops []crdt.Op) {
+	if len(ops) == 0 {
+		return
+	}
+	doc := m.editor.GetDocument()
+	cursor := m.editor.GetCursor()
+	offset := crdt.OffsetAt(doc, cursor.GetBufferPos())
+	var selection *[2]int
+	if s := cursor.GetSelection(); s != nil {
+		selection = &[2]int{crdt.OffsetAt(doc, s.Start), crdt.OffsetAt(doc, s.End)}
+	}
+
+	for _, change := range m.share.replica.Apply(ops) {
+		offset = change.Shift(offset)
+		if selection != nil {
+			selection[0], selection[1] = change.Shift(selection[0]), change.Shift(selection[1])
+		}
+		for _, peer := range m.share.peers {
+			if peer.cursor >= 0 {
+				peer.cursor = change.Shift(peer.cursor)
+			}
+		}
+	}
+
+	cursor.SetBufferPos(crdt.PosAt(doc, offset))
+	if selection != nil {
+		cursor.SetSelection(&ast.Selection{Start: crdt.PosAt(doc, selection[0]), End: crdt.PosAt(doc, selection[1])})
+	}
+}
+
+// syncShare sends the edits made to the buffer since last sent, and where
+// the cursor moved, to the others.
+func (m *Model) syncShare() {
+	state := &m.share
+	if state.replica == nil {
+		return
+	}
+	doc := m.editor.GetDocument()
+	if state.replica.Doc() != doc {
+		m.leaveShare("Left the shared session: another file was opened")
+		return
+	}
+	if revision := doc.Revision(); revision != state.revision {
+		state.revision = revision
+		if ops := state.replica.Commit(); len(ops) > 0 {
+			state.session.Send(share.Message{Site: state.site, Ops: ops})
+		}
+	}
+	if cursor := crdt.OffsetAt(doc, m.editor.GetCursor().GetBufferPos()); cursor != state.cursor {
+		state.cursor = cursor
+		state.session.Send(share.Message{Site: state.site, Name: m.author(), Cursor: &cursor})
+	}
+}
+
+// shareStatus is the status bar's note of the session: how many are in
+// it, this editor included.
+func (m *Model) shareStatus() string {
+	if m.share.session == nil {
+		return ""
+	}
+	if m.share.replica == nil {
+		return "JOINING"
+	}
+	return fmt.Sprintf("SHARED %d", len(m.share.peers)+1)
+}
+
+// markPeers shows where the others' cursors are.
+func (m *Model) markPeers(lines []plugin.RenderedLine) {
+	if m.share.replica == nil || len(m.share.peers) == 0 {
+		return
+	}
+	doc := m.editor.GetDocument()
+	viewport := m.editor.GetViewport()
+	top, left := viewport.GetTopLine(), viewport.GetLeftColumn()
+	prefix := 0
+	if m.editor.ShowLineNumbers() {
+		prefix = viewport.GetLineNumberWidth()
+	}
+	style := theme.Resolved().Style(theme.RemoteCursor)
+
+	for _, peer := range m.share.peers {
+		if peer.cursor < 0 {
+			continue
+		}
+		pos := crdt.PosAt(doc, peer.cursor)
+		i := pos.Line - top
+		col := viewport.DisplayColumn(doc.GetLine(pos.Line), pos.Col) - left
+		if i < 0 || i >= len(lines) || col < 0 || col >= viewport.GetWidth()-prefix {
+			continue
+		}
+		col += prefix
+		// A cursor at the end of the line is on the cell after it
+		if length := utf8.RuneCountInString(lines[i].Content); col >= length {
+			lines[i].Content += strings.Repeat(" ", col+1-length)
+		}
+		lines[i].Styles = renderers.Overlay(lines[i].Styles, col, col+1, style)
+	}
+}
@@ -0,0 +1,99 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/ofri/mde/pkg/ast"
+)
+
+// maxStatsTitleWidth bounds the heading column of the section stats panel
+const maxStatsTitleWidth = 32
+
+func init() {
+	registerCommand(Command{
+		Name:        "section-stats",
+		Description: "Toggle a panel with word and character counts for each section",
+		Run:         (*Model).toggleSectionStats,
+		Markdown:    true,
+	})
+}
+
+// sectionStatsPanel is the section stats overlay. The counter keeps line
+// counts between frames, so redrawing after an edit is cheap.
+type sectionStatsPanel struct {
+	enabled bool
+	counter ast.SectionCounter
+}
+
+// toggleSectionStats shows or hides the section stats panel.
+func (m *Model) toggleSectionStats() tea.Cmd {
+	m.sectionStats.enabled = !m.sectionStats.enabled
+	if m.sectionStats.enabled {
+		m.showMessage("Section stats on")
+	} else {
+		m.showMessage("Section stats off")
+	}
+	return nil
+}
+
+// overlaySectionStats draws the panel down the right edge of content,
+// scrolled to keep the section under the cursor visible.
+func (m *Model) overlaySectionStats(content string) string {
+	if !m.sectionStats.enabled || m.plainText() {
+		return content
+	}
+	lines := strings.Split(content, "\n")
+	panel := m.renderSectionStats(len(lines))
+	for i, panelLine := range panel {
+		lines[i] = overlayLine(lines[i], panelLine, max(m.width-ansi.StringWidth(panelLine), 0))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// renderSectionStats formats at most height panel lines: a header, then
+// each section's heading, indented by level, with its total counts.
+func (m *Model) renderSectionStats(height int) []string {
+	sections := m.sectionStats.counter.Sections(m.editor.GetDocument())
+	if height < 2 || len(sections) == 0 {
+		return nil
+	}
+
+	cursorLine := m.editor.GetCursor().GetBufferPos().Line
+	current := 0
+	titles := make([]string, len(sections))
+	width := len("Section")
+	for i, s := range sections {
+		title := "(before first heading)"
+		if s.Heading.Level > 0 {
+			title = strings.Repeat("  ", s.Heading.Level-1) + s.Heading.Text
+			if s.Heading.Line <= cursorLine {
+				current = i
+			}
+		}
+		titles[i] = ansi.Truncate(title, maxStatsTitleWidth, "…")
+		width = max(width, ansi.StringWidth(titles[i]))
+	}
+
+	row := func(title string, words, chars any) string {
+		return fmt.Sprintf(" %s%s %7v %8v ", title, strings.Repeat(" ", width-ansi.StringWidth(title)), words, chars)
+	}
+	normal := lipgloss.NewStyle().Reverse(true)
+	selected := lipgloss.NewStyle().Bold(true)
+
+	first := max(current-(height-1)+1, 0)
+	last := min(first+height-1, len(sections))
+	panel := []string{normal.Bold(true).Render(row("Section", "Words", "Chars"))}
+	for i := first; i < last; i++ {
+		text := row(titles[i], sections[i].Total.Words, sections[i].Total.Chars)
+		if i == current {
+			panel = append(panel, selected.Render(text))
+		} else {
+			panel = append(panel, normal.Render(text))
+		}
+	}
+	return panel
+}
@@ -0,0 +1,62 @@
+package tui
+
+import (
+	"fmt"
+	"unicode/utf8"
+
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/ofri/mde/internal/theme"
+	"github.com/ofri/mde/pkg/ast"
+	"github.com/ofri/mde/pkg/plugin"
+)
+
+func init() {
+	registerCommand(Command{
+		Name:        "sticky-heading",
+		Description: "Toggle pinning the current section's heading above the text",
+		Run:         (*Model).toggleStickyHeading,
+		Markdown:    true,
+	})
+}
+
+// toggleStickyHeading flips the pinned heading for this session; the
+// sticky_heading setting chooses the initial state.
+func (m *Model) toggleStickyHeading() tea.Cmd {
+	m.config.StickyHeading = !m.config.StickyHeading
+	if m.config.StickyHeading {
+		m.showMessage("Sticky heading on")
+	} else {
+		m.showMessage("Sticky heading off")
+	}
+	return nil
+}
+
+// pinStickyHeading replaces the first of the rendered lines with the
+// heading of the section the viewport's top line is in, when that heading
+// has scrolled out of view. The cursor's row is never covered.
+func (m *Model) pinStickyHeading(lines []plugin.RenderedLine) {
+	if !m.config.StickyHeading || len(lines) < 2 || m.plainText() || m.config.ScreenReader {
+		return
+	}
+	viewport := m.editor.GetViewport()
+	top := viewport.GetTopLine()
+	if m.editor.GetCursor().GetBufferPos().Line == top {
+		return
+	}
+	doc := m.editor.GetDocument()
+	heading, ok := doc.HeadingAbove(top)
+	if !ok || heading == top {
+		return
+	}
+
+	content := ast.ExpandTabs(doc.GetLine(heading), viewport.GetTabWidth())
+	if m.editor.ShowLineNumbers() {
+		content = fmt.Sprintf("%*d│ ", viewport.GetLineNumberWidth()-2, heading+1) + content
+	}
+	content = ansi.Truncate(content, viewport.GetWidth(), "…")
+	lines[0] = plugin.RenderedLine{
+		Content: content,
+		Styles:  []plugin.StyleRange{{Start: 0, End: utf8.RuneCountInString(content), Style: theme.Resolved().Style(theme.StickyHeading)}},
+	}
+}
@@ -0,0 +1,440 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/ofri/mde/internal/critic"
+	"github.com/ofri/mde/pkg/ast"
+)
+
+// maxChangeWidth bounds the text of a change in the changes panel.
+const maxChangeWidth = 40
+
+// noChanges fills the changes panel of a document without any.
+const noChanges = "No suggested changes"
+
+// suggestState is suggestion mode, in which edits are recorded as
+// CriticMarkup instead of made, and the changes found in the document at
+// revision.
+type suggestState struct {
+	on    bool
+	panel bool // The changes panel is shown
+
+	doc      *ast.Document
+	revision int
+	marks    []critic.Mark
+}
+
+func init() {
+	registerCommand(Command{
+		Name:        "suggest",
+		Description: "Toggle suggestion mode: record edits as {++insertions++} and {--deletions--} instead of making them",
+		Run:         (*Model).toggleSuggest,
+	})
+	registerCommand(Command{
+		Name:        "accept-change",
+		Description: "Accept the suggested change at the cursor, or resolve the comment",
+		Run:         func(m *Model) tea.Cmd { m.resolveChange(true); return nil },
+	})
+	registerCommand(Command{
+		Name:        "reject-change",
+		Description: "Reject the suggested change at the cursor, or resolve the comment",
+		Run:         func(m *Model) tea.Cmd { m.resolveChange(false); return nil },
+	})
+	registerCommand(Command{
+		Name:        "accept-all-changes",
+		Description: "Accept every suggested change in the document",
+		Run:         func(m *Model) tea.Cmd { m.resolveAllChanges(true); return nil },
+	})
+	registerCommand(Command{
+		Name:        "reject-all-changes",
+		Description: "Reject every suggested change in the document",
+		Run:         func(m *Model) tea.Cmd { m.resolveAllChanges(false); return nil },
+	})
+	registerCommand(Command{
+		Name:        "next-change",
+		Description: "Jump to the next suggested change",
+		Run:         func(m *Model) tea.Cmd { m.jumpToChange(1); return nil },
+	})
+	registerCommand(Command{
+		Name:        "previous-change",
+		Description: "Jump to the previous suggested change",
+		Run:         func(m *Model) tea.Cmd { m.jumpToChange(-1); return nil },
+	})
+	registerCommand(Command{
+		Name:        "changes-panel",
+		Description: "Toggle a panel summarizing the suggested changes",
+		Run:         (*Model).toggleChangesPanel,
+	})
+}
+
+func (m *Model) toggleSuggest() tea.Cmd {
+	m.suggest.on = !m.suggest.on
+	if m.suggest.on {
+		m.showMessage("Suggesting: edits are recorded as changes to accept or reject")
+	} else {
+		m.showMessage("Editing directly")
+	}
+	return nil
+}
+
+func (m *Model) toggleChangesPanel() tea.Cmd {
+	m.suggest.panel = !m.suggest.panel
+	if m.suggest.panel {
+		m.showMessage("Changes panel on")
+	} else {
+		m.showMessage("Changes panel off")
+	}
+	return nil
+}
+
+// changes returns the suggested changes in the open document.
+func (m *Model) changes() []critic.Mark {
+	state := &m.suggest
+	doc := m.editor.GetDocument()
+	if doc != state.doc || doc.Revision() != state.revision || state.marks == nil {
+		state.doc, state.revision, state.marks = doc, doc.Revision(), critic.Find(doc)
+		if state.marks == nil {
+			state.marks = []critic.Mark{}
+		}
+	}
+	return state.marks
+}
+
+// handleSuggestionKey records the edit key makes as a change, reporting
+// false for keys that make none.
+func (m *Model) handleSuggestionKey(msg tea.KeyPressMsg) bool {
+	switch key := msg.String(); key {
+	case "backspace":
+		m.suggestDeletion(-1)
+	case "delete":
+		m.suggestDeletion(1)
+	case "enter":
+		m.suggestInsertion("\n")
+	case "space":
+		m.suggestInsertion(" ")
+	case "tab":
+		m.suggestInsertion(m.indentation())
+	case "ctrl+x":
+		if m.editor.GetCursor().HasSelection() {
+			m.editor.Copy()
+			if m.suggestDeletion(0) {
+				m.showMessage("Cut")
+			}
+		}
+	default:
+		if !isPrintableCharacter(key) {
+			return false
+		}
+		m.suggestInsertion(key)
+	}
+	return true
+}
+
+// suggestInsertion inserts text as a suggestion: within the insertion the
+// cursor is in, or else as a new one, leaving the cursor inside it so
+// typing goes on adding to it. A selection is suggested for deletion
+// first, the insertion replacing it.
+func (m *Model) suggestInsertion(text string) {
+	cursor := m.editor.GetCursor()
+	if cursor.HasSelection() && !m.suggestDeletion(0) {
+		return
+	}
+	pos := cursor.GetBufferPos()
+	for _, mark := range m.changes() {
+		inner := mark.Inner()
+		if mark.Kind == critic.Insertion && !pos.Before(mark.Range.Start) && !mark.Range.End.Before(pos) {
+			// Within an insertion, or at either end of it
+			switch {
+			case pos.Before(inner.Start):
+				cursor.SetBufferPos(inner.Start)
+			case inner.End.Before(pos):
+				cursor.SetBufferPos(inner.End)
+			}
+			m.editor.InsertText(text)
+			return
+		}
+		if mark.Range.Start.Before(pos) && pos.Before(mark.Range.End) {
+			pos = mark.Range.End // Not within another mark's text or delimiters
+			cursor.SetBufferPos(pos)
+		}
+	}
+	m.editor.InsertText(critic.Wrap(critic.Insertion, text))
+	end := cursor.GetBufferPos()
+	cursor.SetBufferPos(ast.BufferPos{Line: end.Line, Col: end.Col - len(critic.Close(critic.Insertion))})
+}
+
+// suggestDeletion suggests deleting the selection, or the character
+// before the cursor (direction -1) or after it (1). Text the suggester
+// inserted is deleted outright; other text is marked as deleted, joining
+// the deletions beside it, and the cursor steps over it. Reports false
+// when nothing could be deleted.
+func (m *Model) suggestDeletion(direction int) bool {
+	cursor := m.editor.GetCursor()
+	if selection := cursor.GetSelection(); selection != nil {
+		cursor.ClearSelection()
+		return m.suggestDeletionOf(orderedRange(selection.Start, selection.End), direction >= 0)
+	}
+	if direction == 0 {
+		return false
+	}
+
+	pos := cursor.GetBufferPos()
+	for moved := true; moved; {
+		moved = false
+		for _, mark := range m.changes() {
+			inner := mark.Inner()
+			switch {
+			case mark.Kind == critic.Insertion && direction < 0 && inner.Start.Before(pos) && !inner.End.Before(pos),
+				mark.Kind == critic.Insertion && direction > 0 && !pos.Before(inner.Start) && pos.Before(inner.End):
+				cursor.SetBufferPos(pos)
+				if direction < 0 {
+					m.editor.DeleteText(1)
+				} else {
+					m.editor.DeleteTextForward(1)
+				}
+				m.dropEmptyInsertion()
+				return true
+			case mark.Kind == critic.Insertion && direction < 0 && pos == mark.Range.End:
+				pos, moved = inner.End, true
+			case mark.Kind == critic.Insertion && direction > 0 && pos == mark.Range.Start:
+				pos, moved = inner.Start, true
+			case direction < 0 && mark.Range.Start.Before(pos) && !mark.Range.End.Before(pos):
+				pos, moved = mark.Range.Start, true
+			case direction > 0 && !pos.Before(mark.Range.Start) && pos.Before(mark.Range.End):
+				pos, moved = mark.Range.End, true
+			}
+			if moved {
+				break
+			}
+		}
+	}
+
+	doc := m.editor.GetDocument()
+	r := ast.Range{Start: pos, End: pos}
+	if direction < 0 {
+		switch {
+		case pos.Col > 0:
+			r.Start.Col--
+		case pos.Line > 0:
+			r.Start = ast.BufferPos{Line: pos.Line - 1, Col: doc.GetLineLength(pos.Line - 1)}
+		default:
+			cursor.SetBufferPos(pos)
+			return false
+		}
+	} else {
+		switch {
+		case pos.Col < doc.GetLineLength(pos.Line):
+			r.End.Col++
+		case pos.Line < doc.LineCount()-1:
+			r.End = ast.BufferPos{Line: pos.Line + 1}
+		default:
+			cursor.SetBufferPos(pos)
+			return false
+		}
+	}
+	return m.suggestDeletionOf(r, direction > 0)
+}
+
+// suggestDeletionOf marks the text in r as deleted, joining the deletions
+// either side of it, and leaves the cursor after the deletion when
+// forward is set and before it otherwise.
+func (m *Model) suggestDeletionOf(r ast.Range, forward bool) bool {
+	cursor := m.editor.GetCursor()
+	for _, mark := range m.changes() {
+		if !mark.Range.Start.Before(r.End) || !r.Start.Before(mark.Range.End) {
+			continue
+		}
+		if inner := mark.Inner(); mark.Kind == critic.Insertion && !r.Start.Before(inner.Start) && !inner.End.Before(r.End) {
+			m.editor.ReplaceRange(r, "") // The suggester's own text
+			m.dropEmptyInsertion()
+			return true
+		}
+		m.showMessage("The selection overlaps a suggested change: accept or reject it first")
+		return false
+	}
+	if r.Start == r.End {
+		return false
+	}
+
+	doc := m.editor.GetDocument()
+	text := doc.GetSelectionText(&ast.Selection{Start: r.Start, End: r.End})
+	opening, closing := critic.Open(critic.Deletion), critic.Close(critic.Deletion)
+	from, to := r.Start, r.End
+	for _, mark := range m.changes() {
+		if mark.Kind != critic.Deletion {
+			continue
+		}
+		if mark.Range.End == r.Start {
+			from.Col -= len(closing) // Its closing delimiter goes, and our opening one
+			opening = ""
+		}
+		if mark.Range.Start == r.End {
+			to.Col += len(opening) // Its opening delimiter goes, and our closing one
+			closing = ""
+		}
+	}
+	m.editor.ReplaceRange(ast.Range{Start: from, End: to}, opening+text+closing)
+
+	// Step over the deletion, which stays in the text
+	if mark, ok := critic.At(m.changes(), from); ok {
+		if forward {
+			cursor.SetBufferPos(mark.Range.End)
+		} else {
+			cursor.SetBufferPos(mark.Range.Start)
+		}
+	}
+	return true
+}
+
+// dropEmptyInsertion removes the insertion the cursor is in once all its
+// text is deleted.
+func (m *Model) dropEmptyInsertion() {
+	pos := m.editor.GetCursor().GetBufferPos()
+	for _, mark := range m.changes() {
+		if mark.Kind == critic.Insertion && mark.Text == "" && !pos.Before(mark.Range.Start) && !mark.Range.End.Before(pos) {
+			m.editor.ReplaceRange(mark.Range, "")
+			return
+		}
+	}
+}
+
+// resolveChange accepts or rejects the change at the cursor.
+func (m *Model) resolveChange(accept bool) {
+	mark, ok := critic.At(m.changes(), m.editor.GetCursor().GetBufferPos())
+	if !ok {
+		m.showMessage("No suggested change at the cursor")
+		return
+	}
+	m.editor.ReplaceRange(mark.Range, mark.Resolve(accept))
+	verb := "Rejected"
+	switch {
+	case mark.Kind == critic.Comment:
+		verb = "Resolved"
+	case accept:
+		verb = "Accepted"
+	}
+	m.showMessage(fmt.Sprintf("%s %s (%s left)", verb, changeName(mark.Kind), plural(len(m.changes()), "change")))
+}
+
+// resolveAllChanges accepts or rejects every change, last first so the
+// earlier ones stay where they were found.
+func (m *Model) resolveAllChanges(accept bool) {
+	marks := m.changes()
+	if len(marks) == 0 {
+		m.showMessage(noChanges)
+		return
+	}
+	cursor := m.editor.GetCursor()
+	pos := cursor.GetBufferPos()
+	for i := len(marks) - 1; i >= 0; i-- {
+		m.editor.ReplaceRange(marks[i].Range, marks[i].Resolve(accept))
+	}
+	cursor.SetBufferPos(m.editor.GetDocument().ValidatePosition(pos))
+	verb := "Rejected"
+	if accept {
+		verb = "Accepted"
+	}
+	m.showMessage(fmt.Sprintf("%s %s", verb, plural(len(marks), "change")))
+}
+
+// jumpToChange moves the cursor to the start of the next (direction 1) or
+// previous (-1) change, wrapping around, and describes it.
+func (m *Model) jumpToChange(direction int) {
+	marks := m.changes()
+	if len(marks) == 0 {
+		m.showMessage(noChanges)
+		return
+	}
+	cursor := m.editor.GetCursor()
+	pos := cursor.GetBufferPos()
+	target := marks[0]
+	if direction < 0 {
+		target = marks[len(marks)-1]
+		for i := len(marks) - 1; i >= 0; i-- {
+			if marks[i].Range.Start.Before(pos) {
+				target = marks[i]
+				break
+			}
+		}
+	} else {
+		for _, mark := range marks {
+			if pos.Before(mark.Range.Start) {
+				target = mark
+				break
+			}
+		}
+	}
+	cursor.ClearSelection()
+	cursor.SetBufferPos(target.Range.Start)
+	m.showMessage(changeSummary(target))
+}
+
+// changeName names a kind of change.
+func changeName(kind critic.Kind) string {
+	switch kind {
+	case critic.Insertion:
+		return "insertion"
+	case critic.Deletion:
+		return "deletion"
+	default:
+		return "comment"
+	}
+}
+
+// changeSigns mark each kind of change in its summary.
+var changeSigns = map[critic.Kind]string{
+	critic.Insertion: "+",
+	critic.Deletion:  "−",
+	critic.Comment:   "»",
+}
+
+// changeSummary describes a change on one line, as "+ new text".
+func changeSummary(mark critic.Mark) string {
+	sign := changeSigns[mark.Kind]
+	text := strings.ReplaceAll(mark.Text, "\n", "¶")
+	return sign + " " + ansi.Truncate(text, maxChangeWidth, "…")
+}
+
+// overlayChangesPanel draws the changes panel down the right edge of
+// content: a count of each kind, then each change with its line, the one
+// at or after the cursor highlighted.
+func (m *Model) overlayChangesPanel(content string) string {
+	if !m.suggest.panel {
+		return content
+	}
+	return m.overlayPanel(content, m.renderChangesPanel)
+}
+
+// renderChangesPanel formats at most height panel lines.
+func (m *Model) renderChangesPanel(height int) []string {
+	marks := m.changes()
+	counts := map[critic.Kind]int{}
+	for _, mark := range marks {
+		counts[mark.Kind]++
+	}
+	pos := m.editor.GetCursor().GetBufferPos()
+	current := len(marks) - 1
+	for i, mark := range marks {
+		if !mark.Range.End.Before(pos) {
+			current = i
+			break
+		}
+	}
+
+	header := fmt.Sprintf("Changes  +%d −%d", counts[critic.Insertion], counts[critic.Deletion])
+	if counts[critic.Comment] > 0 {
+		header += fmt.Sprintf(" »%d", counts[critic.Comment])
+	}
+	rows := make([]string, len(marks))
+	for i, mark := range marks {
+		rows[i] = fmt.Sprintf("%4d %s", mark.Range.Start.Line+1, changeSummary(mark))
+	}
+	if len(rows) == 0 {
+		rows, current = []string{noChanges}, -1
+	}
+	return renderPanel(header, rows, current, height)
+}
@@ -0,0 +1,73 @@
+package tui
+
+import (
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/ofri/mde/pkg/ast"
+)
+
+func init() {
+	registerCommand(Command{
+		Name:        "table-format",
+		Description: "Realign the pipes of the table under the cursor",
+		Run:         (*Model).formatTable,
+		Markdown:    true,
+	})
+	registerCommand(Command{
+		Name:        "table-insert-column",
+		Description: "Add an empty table column right of the cursor",
+		Run:         (*Model).tableInsertColumn,
+		Markdown:    true,
+	})
+	registerCommand(Command{
+		Name:        "table-delete-column",
+		Description: "Delete the table column under the cursor",
+		Run:         (*Model).tableDeleteColumn,
+		Markdown:    true,
+	})
+	for _, align := range []struct {
+		name  string
+		align ast.Alignment
+	}{
+		{"left", ast.AlignLeft},
+		{"center", ast.AlignCenter},
+		{"right", ast.AlignRight},
+		{"none", ast.AlignNone},
+	} {
+		registerCommand(Command{
+			Name:        "table-align-" + align.name,
+			Description: "Align the table column under the cursor: " + align.name,
+			Run:         func(m *Model) tea.Cmd { return m.tableAlignColumn(align.align) },
+			Markdown:    true,
+		})
+	}
+}
+
+func (m *Model) formatTable() tea.Cmd {
+	if !m.editor.FormatTable() {
+		m.showMessage("No table under cursor")
+	}
+	return nil
+}
+
+func (m *Model) tableInsertColumn() tea.Cmd {
+	if !m.editor.TableInsertColumn() {
+		m.showMessage("No table under cursor")
+	}
+	return nil
+}
+
+func (m *Model) tableDeleteColumn() tea.Cmd {
+	if _, ok := m.editor.GetDocument().TableAt(m.editor.GetCursor().GetBufferPos().Line); !ok {
+		m.showMessage("No table under cursor")
+	} else if !m.editor.TableDeleteColumn() {
+		m.showMessage("Cannot delete the only column")
+	}
+	return nil
+}
+
+func (m *Model) tableAlignColumn(align ast.Alignment) tea.Cmd {
+	if !m.editor.TableAlignColumn(align) {
+		m.showMessage("No table under cursor")
+	}
+	return nil
+}
@@ -0,0 +1,67 @@
+package tui
+
+import (
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/ofri/mde/internal/templates"
+)
+
+func init() {
+	registerCommand(Command{
+		Name:        "new-from-template",
+		Description: "Create a new buffer from a document template",
+		Run:         (*Model).promptTemplate,
+	})
+}
+
+// promptTemplate opens the template picker.
+// Refuses while the current buffer has unsaved changes.
+func (m *Model) promptTemplate() tea.Cmd {
+	if m.editor.GetDocument().IsModified() {
+		m.showMessage("Unsaved changes - save (^S) before creating a new document")
+		return nil
+	}
+	m.mode = ModeTemplate
+	m.input = ""
+	return nil
+}
+
+// handleTemplate creates a buffer from the picker input.
+// Input format: "<template> [title words...]".
+func (m *Model) handleTemplate() (tea.Model, tea.Cmd) {
+	fields := strings.Fields(m.input)
+	m.mode = ModeNormal
+	m.input = ""
+
+	if len(fields) == 0 {
+		m.showMessage("No template selected")
+		return m, nil
+	}
+
+	name := fields[0]
+	title := strings.Join(fields[1:], " ")
+
+	content, err := m.RenderTemplate(name, title)
+	if err != nil {
+		m.showMessage("Template error: " + err.Error())
+		return m, nil
+	}
+
+	m.NewBuffer(content, "")
+	m.showMessage("New document from template " + name)
+	return m, nil
+}
+
+// RenderTemplate expands a named template using the configured
+// templates directory and author.
+func (m *Model) RenderTemplate(name, title string) (string, error) {
+	vars := templates.NewVars(title, m.config.Author, time.Now())
+	return templates.Render(m.config.TemplatesDir, name, vars)
+}
+
+// TemplateNames lists the templates available to RenderTemplate.
+func (m *Model) TemplateNames() []string {
+	return templates.List(m.config.TemplatesDir)
+}
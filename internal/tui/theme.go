@@ -0,0 +1,138 @@
+package tui
+
+import (
+	"path/filepath"
+
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/ofri/mde/internal/theme"
+)
+
+func init() {
+	registerCommand(Command{
+		Name:        "theme",
+		Description: "Pick a theme, previewing each as its name is typed",
+		Run:         (*Model).promptTheme,
+	})
+	registerCommand(Command{
+		Name:        "theme-light",
+		Description: "Use the light theme, whatever the terminal background",
+		Run:         func(m *Model) tea.Cmd { return m.setTheme(m.config.LightTheme) },
+	})
+	registerCommand(Command{
+		Name:        "theme-dark",
+		Description: "Use the dark theme, whatever the terminal background",
+		Run:         func(m *Model) tea.Cmd { return m.setTheme(m.config.DarkTheme) },
+	})
+	registerCommand(Command{
+		Name:        "theme-auto",
+		Description: "Pick the light or dark theme by the terminal background",
+		Run:         func(m *Model) tea.Cmd { return m.setTheme(autoTheme) },
+	})
+	registerCommand(Command{
+		Name:        "export-theme",
+		Description: "Write the current theme to the themes directory for editing",
+		Run:         (*Model).exportTheme,
+	})
+}
+
+// autoTheme is the theme setting that follows the terminal background.
+const autoTheme = "auto"
+
+// applyTheme activates the configured theme. With "auto" it is the light
+// or dark theme for the background the terminal reported, dark until it
+// answers. Reports whether it succeeded, showing the error if not.
+func (m *Model) applyTheme() bool {
+	name := m.config.Theme
+	if name == autoTheme {
+		name = m.config.DarkTheme
+		if m.lightBackground {
+			name = m.config.LightTheme
+		}
+	}
+	if err := theme.SetActive(name); err != nil {
+		m.showMessage("Error: " + err.Error())
+		return false
+	}
+	return true
+}
+
+// setBackground records the background color the terminal reported,
+// which picks the "auto" theme and the colors any theme is adjusted to
+// for contrast.
+func (m *Model) setBackground(msg tea.BackgroundColorMsg) {
+	m.lightBackground = !msg.IsDark()
+	theme.SetBackground(msg.Color)
+	m.applyTheme()
+}
+
+// setTheme switches to the theme named name, or to "auto", for this
+// session; the theme setting chooses the initial one. Switching to "auto"
+// asks the terminal for its background again, in case it changed. An
+// unknown name keeps the current theme.
+func (m *Model) setTheme(name string) tea.Cmd {
+	previous := m.config.Theme
+	m.config.Theme = name
+	if !m.applyTheme() {
+		m.config.Theme = previous
+		m.applyTheme()
+		return nil
+	}
+	if name == autoTheme {
+		m.showMessage("Theme follows the terminal background")
+		return tea.RequestBackgroundColor
+	}
+	m.showMessage("Theme: " + theme.Active().Name)
+	return nil
+}
+
+// themeChoices returns the names the theme picker offers: the registered
+// themes and "auto".
+func themeChoices() []string {
+	return append(theme.Names(), autoTheme)
+}
+
+// promptTheme opens the theme picker.
+func (m *Model) promptTheme() tea.Cmd {
+	m.mode = ModeTheme
+	m.input = ""
+	return nil
+}
+
+// previewTheme shows the theme the picker's input names, if any, without
+// changing the theme setting, so Escape goes back to it.
+func (m *Model) previewTheme() {
+	if _, ok := theme.Get(m.input); ok {
+		theme.SetActive(m.input)
+	}
+}
+
+// handleTheme switches to the theme picked.
+func (m *Model) handleTheme() tea.Cmd {
+	name := m.input
+	m.mode = ModeNormal
+	m.input = ""
+	if name == "" {
+		m.applyTheme()
+		m.showMessage("No theme selected")
+		return nil
+	}
+	return m.setTheme(name)
+}
+
+// exportTheme saves every style of the active theme to <name>.toml in the
+// themes directory, where it is loaded on the next start. An existing file
+// is left alone, so edits are never lost.
+func (m *Model) exportTheme() tea.Cmd {
+	if m.config.ThemesDir == "" {
+		m.showMessage("Error: no themes directory")
+		return nil
+	}
+	t := theme.Active()
+	path := filepath.Join(m.config.ThemesDir, t.Name+".toml")
+	if err := t.Save(path); err != nil {
+		m.showMessage("Error: " + err.Error())
+		return nil
+	}
+	m.showMessage("Theme saved to " + path)
+	return nil
+}
@@ -0,0 +1,31 @@
+package tui
+
+import (
+	tea "github.com/charmbracelet/bubbletea/v2"
+)
+
+func init() {
+	registerCommand(Command{
+		Name:        "toc",
+		Description: "Insert a table of contents above the cursor, or refresh the existing one",
+		Run:         (*Model).updateTOC,
+		Markdown:    true,
+	})
+}
+
+// updateTOC regenerates the table of contents between the configured
+// markers, inserting one with its markers when the document has none.
+func (m *Model) updateTOC() tea.Cmd {
+	if len(m.editor.GetDocument().Headings()) == 0 {
+		m.showMessage("No headings for a table of contents")
+		return nil
+	}
+	if _, _, ok := m.editor.GetDocument().TOCBlock(m.config.TOCStartMarker, m.config.TOCEndMarker); ok {
+		m.editor.UpdateTOC(m.config.TOCStartMarker, m.config.TOCEndMarker, false)
+		m.showMessage("Table of contents updated")
+		return nil
+	}
+	m.editor.UpdateTOC(m.config.TOCStartMarker, m.config.TOCEndMarker, true)
+	m.showMessage("Table of contents inserted")
+	return nil
+}
@@ -0,0 +1,26 @@
+package tui
+
+import (
+	tea "github.com/charmbracelet/bubbletea/v2"
+)
+
+func init() {
+	registerCommand(Command{
+		Name:        "typewriter-mode",
+		Description: "Toggle keeping the cursor line vertically centered",
+		Run:         (*Model).toggleTypewriter,
+	})
+}
+
+// toggleTypewriter flips typewriter scrolling for this session; the
+// typewriter_scrolling setting chooses the initial state.
+func (m *Model) toggleTypewriter() tea.Cmd {
+	m.config.TypewriterScrolling = !m.config.TypewriterScrolling
+	m.editor.SetTypewriterMode(m.config.TypewriterScrolling)
+	if m.config.TypewriterScrolling {
+		m.showMessage("Typewriter scrolling on")
+	} else {
+		m.showMessage("Typewriter scrolling off")
+	}
+	return nil
+}
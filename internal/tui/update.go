@@ -6,10 +6,27 @@ import (
 	"unicode"
 	
 	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/ofri/mde/internal/assets"
+	"github.com/ofri/mde/internal/templates"
 	"github.com/ofri/mde/pkg/terminal"
 )
 
 func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	model, cmd := m.update(msg)
+	m.syncProject()
+	m.autoNumberHeadings()
+	m.trackWordGoal()
+	cmd = tea.Batch(cmd, m.syncLanguageServer())
+	m.syncPreviewServer()
+	m.syncShare()
+	
+	// Prompts and popups may have shown or hidden chrome
+	m.syncLayout()
+	m.tokenizeVisible()
+	return model, cmd
+}
+
+func (m *Model) update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	if m.messageTimer > 0 {
 		m.messageTimer--
 		if m.messageTimer == 0 {
@@ -23,18 +40,38 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.height = msg.Height
 		
 		// Update editor viewport with content height (terminal height - UI chrome)
-		if m.editor != nil {
-			m.editor.SetViewPort(msg.Width, m.GetContentHeight())
+		m.resizeEditor()
+		if m.snapshots.diff != nil {
+			m.snapshots.diff.Update(msg)
 		}
 		
 		return m, nil
 
 	case tea.KeyPressMsg:
+		if m.snapshots.diff != nil {
+			m.handleSnapshotDiffKey(msg)
+			return m, nil
+		}
+		if popup := m.popup; popup != nil {
+			if m.handlePopupKey(msg) {
+				return m, nil
+			}
+			// Any other key closes the popup; typing more of the
+			// prefix reopens it with the narrowed list
+			m.popup = nil
+			model, cmd := m.handleKeyInput(msg)
+			m.refreshPopup(popup)
+			return model, cmd
+		}
 		return m.handleKeyInput(msg)
 		
 	case tea.KeyboardEnhancementsMsg:
 		return m, nil
 		
+	case tea.BackgroundColorMsg:
+		m.setBackground(msg)
+		return m, nil
+		
 	case tea.MouseClickMsg:
 		return m.handleMouseClick(msg)
 		
@@ -45,10 +82,83 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m.handleMouseMotion(msg)
 		
 	case tea.MouseWheelMsg:
+		if m.snapshots.diff != nil {
+			m.snapshots.diff.Update(msg)
+			return m, nil
+		}
 		return m.handleMouseWheel(msg)
 		
+	case tea.PasteMsg:
+		if m.mode == ModeNormal && !m.previewMode {
+			m.paste(string(msg))
+		}
+		return m, nil
+		
 	case fileLoadedMsg, fileSavedMsg, fileOpenPromptMsg:
 		return m.handleFileMsg(msg)
+		
+	case clipboardWrittenMsg:
+		return m.handleClipboardMsg(msg)
+		
+	case clipboardImageMsg:
+		return m.handleClipboardImage(msg)
+		
+	case assetsScannedMsg:
+		return m.handleAssetsScanned(msg)
+		
+	case linksCheckedMsg:
+		return m.handleLinksChecked(msg)
+		
+	case diagramRenderedMsg:
+		return m.handleDiagramMsg(msg)
+		
+	case linkOpenedMsg:
+		return m.handleLinkOpenedMsg(msg)
+		
+	case titleFetchedMsg:
+		return m.handleTitleFetchedMsg(msg)
+		
+	case transferTickMsg:
+		return m.handleTransferTick()
+		
+	case controlMsg:
+		return m.handleControlMsg(msg)
+		
+	case exportProgressMsg:
+		return m.handleExportProgress(msg)
+		
+	case exportDoneMsg:
+		return m.handleExportDone(msg)
+		
+	case blockRanMsg:
+		return m.handleBlockRan(msg)
+		
+	case filterDoneMsg:
+		return m.handleFilterDone(msg)
+		
+	case lspStartedMsg:
+		return m.handleLSPStarted(msg)
+		
+	case lspDiagnosticsMsg:
+		return m.handleLSPDiagnostics(msg)
+		
+	case shareMsg:
+		return m.handleShareMsg(msg)
+		
+	case shareWelcomeMsg:
+		return m.handleShareWelcome(msg)
+		
+	case lspCompletionMsg:
+		return m.handleLSPCompletion(msg)
+		
+	case lspHoverMsg:
+		return m.handleLSPHover(msg)
+		
+	case lspRenameMsg:
+		return m.handleLSPRename(msg)
+		
+	case noteDecryptedMsg:
+		return m.handleNoteDecrypted(msg)
 	}
 
 	return m, nil
@@ -60,6 +170,30 @@ func (m *Model) handleKeyInput(msg tea.KeyPressMsg) (tea.Model, tea.Cmd) {
 		return m.handleModalKeyInput(msg)
 	}
 	
+	// Preview has its own reading navigation
+	if m.previewMode && m.handlePreviewKey(msg) {
+		return m, nil
+	}
+	
+	// Backspace straight after an abbreviation expands undoes it
+	lastExpansion := m.abbrev.last
+	m.abbrev.last = nil
+	
+	// The vim keymap takes keys before the regular bindings
+	if m.vimEnabled() {
+		if handled, cmd := m.handleVimKey(msg); handled {
+			return m, cmd
+		}
+	}
+	
+	// Suggestion mode records edits as changes instead of making them
+	if m.suggest.on && m.handleSuggestionKey(msg) {
+		return m, nil
+	}
+	
+	// Edits that add or remove lines may leave list numbers out of order
+	lineCount := m.editor.GetDocument().LineCount()
+	
 	// Handle Alt+Arrow keys for word movement
 	if left, right := terminal.IsWordMovement(msg); left || right {
 		if left {
@@ -94,8 +228,14 @@ func (m *Model) handleKeyInput(msg tea.KeyPressMsg) (tea.Model, tea.Cmd) {
 	case "ctrl+o":
 		return m, m.openFile()
 
+	case "f8":
+		m.jumpToDiagnostic(1)
+
+	case "shift+f8":
+		m.jumpToDiagnostic(-1)
+
 	case "ctrl+v":
-		m.editor.Paste()
+		return m, m.pasteClipboard()
 
 	case "ctrl+x":
 		if m.editor.GetCursor().HasSelection() {
@@ -143,6 +283,60 @@ func (m *Model) handleKeyInput(msg tea.KeyPressMsg) (tea.Model, tea.Cmd) {
 		m.editor.MoveCursorRight()
 		m.editor.GetCursor().ExtendSelection()
 
+	case "shift+home":
+		m.editor.SelectToLineStart()
+
+	case "shift+end":
+		m.editor.SelectToLineEnd()
+
+	case "ctrl+shift+left", "alt+shift+left":
+		m.editor.SelectWordLeft()
+
+	case "ctrl+shift+right", "alt+shift+right":
+		m.editor.SelectWordRight()
+
+	case "ctrl+shift+home":
+		m.editor.SelectToDocumentStart()
+
+	case "ctrl+shift+end":
+		m.editor.SelectToDocumentEnd()
+
+	case "alt+up":
+		m.editor.MoveCursorParagraphUp()
+
+	case "alt+down":
+		m.editor.MoveCursorParagraphDown()
+
+	case "alt+shift+up":
+		m.editor.SelectParagraphUp()
+
+	case "alt+shift+down":
+		m.editor.SelectParagraphDown()
+
+	case "alt+a":
+		m.editor.MoveCursorSentenceLeft()
+
+	case "alt+e":
+		m.editor.MoveCursorSentenceRight()
+
+	case "alt+shift+a", "alt+A":
+		m.editor.SelectSentenceLeft()
+
+	case "alt+shift+e", "alt+E":
+		m.editor.SelectSentenceRight()
+
+	case "insert":
+		return m, m.toggleOverwrite()
+
+	case "alt+p":
+		return m, m.selectParagraph()
+
+	case "alt+l":
+		return m, m.selectLine()
+
+	case "ctrl+w":
+		return m, m.expandSelection()
+
 	case "ctrl+a":
 		// Select all
 		m.editor.GetCursor().StartSelection()
@@ -166,9 +360,7 @@ func (m *Model) handleKeyInput(msg tea.KeyPressMsg) (tea.Model, tea.Cmd) {
 		
 	case "ctrl+f":
 		// Enter find mode
-		m.mode = ModeFind
-		m.input = ""
-		m.caseSensitive = false
+		m.startFind(false)
 		
 	case "ctrl+h":
 		// Enter replace mode
@@ -182,9 +374,21 @@ func (m *Model) handleKeyInput(msg tea.KeyPressMsg) (tea.Model, tea.Cmd) {
 		m.mode = ModeGoto
 		m.input = ""
 		
+	case "alt+x":
+		// Enter command mode
+		m.mode = ModeCommand
+		m.input = ""
+		
 	case "ctrl+p":
 		// Toggle preview mode
-		m.previewMode = !m.previewMode
+		if m.readOnly {
+			m.showMessage(m.editor.GetDocument().GetFilename() + " is read-only")
+			break
+		}
+		if !m.previewMode && !m.requireMarkdown("Preview") {
+			break
+		}
+		m.setPreviewMode(!m.previewMode)
 		if m.previewMode {
 			m.showMessage("Preview mode enabled")
 		} else {
@@ -197,30 +401,94 @@ func (m *Model) handleKeyInput(msg tea.KeyPressMsg) (tea.Model, tea.Cmd) {
 	case "end":
 		m.editor.MoveCursorToLineEnd()
 
+	// Scrolling moves the view, not the cursor
+	case "ctrl+up":
+		m.editor.ScrollViewportUp(1)
+
+	case "ctrl+down":
+		m.editor.ScrollViewportDown(1)
+
+	case "ctrl+u":
+		m.editor.ScrollHalfPageUp()
+
+	case "ctrl+d":
+		m.editor.ScrollHalfPageDown()
+
+	case "pgup":
+		m.editor.ScrollPageUp()
+
+	case "pgdown":
+		m.editor.ScrollPageDown()
+
+	case "ctrl+home":
+		m.editor.ScrollToTop()
+
+	case "ctrl+end":
+		m.editor.ScrollToBottom()
+
 	case "backspace":
-		m.editor.DeleteText(1)
+		if !m.undoAbbreviation(lastExpansion) {
+			m.editor.DeleteText(1)
+		}
 
 	case "delete":
-		pos := m.editor.GetCursor().GetBufferPos()
-		m.editor.MoveCursorRight()
-		m.editor.DeleteText(1)
-		m.editor.GetCursor().SetBufferPos(pos)
+		m.editor.DeleteTextForward(1)
 
 	case "enter":
-		m.editor.InsertText("\n")
+		m.expandWordAbbreviation()
+		if m.plainText() {
+			m.editor.InsertText("\n")
+		} else if !m.editor.TableNewRow() {
+			m.editor.InsertNewline()
+		}
 
 	case "space":
-		m.editor.InsertText(" ")
+		m.editor.TypeText(" ")
+		m.expandAbbreviation()
 
 	case "tab":
-		m.editor.InsertText("\t")
+		if m.plainText() || (!m.completeWikiLink() && !m.completeCitation() && !m.completeEmoji() && !m.editor.TableNextCell()) {
+			m.editor.InsertText(m.indentation())
+		}
+		
+	case "shift+tab":
+		if !m.plainText() {
+			m.editor.TablePrevCell()
+		}
+		
+	case "alt+enter":
+		return m, m.followLink()
+		
+	case "ctrl+b":
+		cmd, _ := m.RunCommand("bold")
+		return m, cmd
+		
+	case "ctrl+i", "alt+i": // Terminals without key disambiguation send ctrl+i as tab
+		cmd, _ := m.RunCommand("italic")
+		return m, cmd
+		
+	case "ctrl+`", "ctrl+space": // Terminals without key disambiguation send ctrl+` as ctrl+space
+		cmd, _ := m.RunCommand("inline-code")
+		return m, cmd
+		
+	case "ctrl+k":
+		cmd, _ := m.RunCommand("link")
+		return m, cmd
+		
+	case "alt+z":
+		return m, m.toggleZen()
+		
+	case "esc":
+		m.review.open = false // Closes the comments popup
 
 	default:
 		// Handle regular character input
 		if isPrintableCharacter(msg.String()) {
-			m.editor.InsertText(msg.String())
+			m.editor.TypeText(msg.String())
+			m.expandAbbreviation()
 		}
 	}
+	m.autoRenumberList(lineCount)
 
 	return m, nil
 }
@@ -240,12 +508,23 @@ func isPrintableCharacter(s string) bool {
 }
 func (m *Model) handleModalKeyInput(msg tea.KeyPressMsg) (tea.Model, tea.Cmd) {
 	switch msg.String() {
-	case "escape":
+	case "esc", "escape":
 		// Exit modal mode
+		if m.mode == ModeFind {
+			m.cancelFind()
+		}
+		if m.mode == ModeTheme {
+			m.applyTheme() // Back from the theme being previewed
+		}
 		m.mode = ModeNormal
 		m.input = ""
 		m.replaceText = ""
 		m.savePromptContext = ""
+		m.pendingPaste = ""
+		m.crypt.pendingOpen = ""
+		m.crypt.confirm = ""
+		m.unusedAssets = assets.Report{}
+		m.snapshots = snapshotState{}
 		return m, nil
 		
 	case "enter":
@@ -256,6 +535,41 @@ func (m *Model) handleModalKeyInput(msg tea.KeyPressMsg) (tea.Model, tea.Cmd) {
 			return m.handleReplace()
 		case ModeGoto:
 			return m.handleGoto()
+		case ModeCommand:
+			return m.handleCommand()
+		case ModeTemplate:
+			return m.handleTemplate()
+		case ModeTheme:
+			return m, m.handleTheme()
+		case ModePassphrase:
+			return m.handlePassphrase()
+		case ModeRename:
+			return m.handleRename()
+		case ModeComment:
+			return m.handleComment()
+		case ModeFilter:
+			return m.handleFilter()
+		case ModeExport:
+			return m.handleExport()
+		case ModeHistory:
+			return m.restoreSnapshot()
+		case ModeJoin:
+			return m.handleJoin()
+		}
+		return m, nil
+		
+	case "tab":
+		// Complete names at the command, template, theme and export prompts
+		switch m.mode {
+		case ModeCommand:
+			m.input = completePrefix(m.input, CommandNames())
+		case ModeTemplate:
+			m.input = completePrefix(m.input, templates.List(m.config.TemplatesDir))
+		case ModeTheme:
+			m.input = completePrefix(m.input, themeChoices())
+			m.previewTheme()
+		case ModeExport:
+			m.input = completePrefix(m.input, pandocFormats)
 		}
 		return m, nil
 		
@@ -264,11 +578,20 @@ func (m *Model) handleModalKeyInput(msg tea.KeyPressMsg) (tea.Model, tea.Cmd) {
 		if len(m.input) > 0 {
 			m.input = m.input[:len(m.input)-1]
 		}
+		if m.mode == ModeFind {
+			m.incrementalFind()
+		}
+		if m.mode == ModeTheme {
+			m.previewTheme()
+		}
 		return m, nil
 		
 	case "space":
 		// Add space to input
 		m.input += " "
+		if m.mode == ModeFind {
+			m.incrementalFind()
+		}
 		return m, nil
 		
 	default:
@@ -276,9 +599,30 @@ func (m *Model) handleModalKeyInput(msg tea.KeyPressMsg) (tea.Model, tea.Cmd) {
 		if m.mode == ModeSavePrompt {
 			return m.handleSavePrompt(msg.String())
 		}
+		if m.mode == ModePastePrompt {
+			m.handlePastePrompt(msg.String())
+			return m, nil
+		}
+		if m.mode == ModeRunPrompt {
+			return m, m.handleRunPrompt(msg.String())
+		}
+		if m.mode == ModeAssetsPrompt {
+			m.handleAssetsPrompt(msg.String())
+			return m, nil
+		}
+		if m.mode == ModeHistory {
+			m.handleHistoryKey(msg.String())
+			return m, nil
+		}
 		// Add character to input for other modes
 		if isPrintableCharacter(msg.String()) {
 			m.input += msg.String()
+			if m.mode == ModeFind {
+				m.incrementalFind()
+			}
+			if m.mode == ModeTheme {
+				m.previewTheme()
+			}
 		}
 		return m, nil
 	}
@@ -291,12 +635,11 @@ func (m *Model) handleFind() (tea.Model, tea.Cmd) {
 		return m, nil
 	}
 	
-	pos := m.editor.FindText(m.input, m.caseSensitive)
-	if pos == nil {
-		m.showMessage("Not found: " + m.input)
-	} else {
-		m.editor.GetCursor().SetBufferPos(*pos)
+	m.lastSearch = m.input
+	if m.findFromOrigin() {
 		m.showMessage("Found: " + m.input)
+	} else {
+		m.showMessage("Not found: " + m.input)
 	}
 	
 	m.mode = ModeNormal
@@ -348,13 +691,27 @@ func (m *Model) handleSavePrompt(key string) (tea.Model, tea.Cmd) {
 	switch key {
 	case "y", "Y":
 		// Save and execute context action
-		if err := m.editor.SaveFile(""); err != nil {
+		m.prepareSave()
+		snapshot := m.snapshotOnSave(m.editor.GetDocument().GetFilename())
+		encode, ok := m.saveEncoder(m.editor.GetDocument().GetFilename())
+		if !ok {
+			m.showMessage("Save with Ctrl+S first to set a passphrase")
+			m.mode = ModeNormal
+			m.savePromptContext = ""
+			return m, nil
+		}
+		if err := m.editor.SaveFileEncoded("", encode); err != nil {
 			m.showMessage("Error saving file: " + err.Error())
 			m.mode = ModeNormal
 			m.savePromptContext = ""
 			return m, nil
 		}
 		m.showMessage("File saved")
+		if snapshot != nil {
+			if err := snapshot(); err != nil {
+				m.showMessage("File saved, but not to its history: " + err.Error())
+			}
+		}
 		m.mode = ModeNormal
 		
 		// Execute context action
@@ -393,8 +750,8 @@ func (m *Model) handleSavePrompt(key string) (tea.Model, tea.Cmd) {
 
 
 func (m *Model) handleMouseClick(msg tea.MouseClickMsg) (tea.Model, tea.Cmd) {
-	// Only handle mouse events in normal mode
-	if m.mode != ModeNormal {
+	// Only handle mouse events in normal mode; preview never moves the cursor
+	if m.mode != ModeNormal || m.previewMode {
 		return m, nil
 	}
 	
@@ -408,6 +765,11 @@ func (m *Model) handleMouseClick(msg tea.MouseClickMsg) (tea.Model, tea.Cmd) {
 	// Position cursor at click location
 	bufferPos := m.screenToBufferSafe(mouse.Y, mouse.X)
 	
+	// A click on a comment's gutter marker opens or closes its popup
+	if m.editor.ShowLineNumbers() && mouse.X < m.textMargin()+m.editor.GetViewport().GetLineNumberWidth() {
+		m.toggleCommentsAt(bufferPos.Line)
+	}
+	
 	// Clear any existing selection and move cursor
 	m.editor.GetCursor().ClearSelection()
 	m.editor.GetCursor().SetBufferPos(bufferPos)
@@ -432,16 +794,19 @@ func (m *Model) handleMouseRelease(msg tea.MouseReleaseMsg) (tea.Model, tea.Cmd)
 }
 
 func (m *Model) handleMouseMotion(msg tea.MouseMotionMsg) (tea.Model, tea.Cmd) {
-	// Only handle mouse events in normal mode
-	if m.mode != ModeNormal {
+	// Only handle mouse events in normal mode; preview never moves the cursor
+	if m.mode != ModeNormal || m.previewMode {
 		return m, nil
 	}
 	
+	mouse := msg.Mouse()
+	
 	if m.mouseStartPos == nil {
+		// No button held: the pointer is just hovering
+		m.hover = m.hoverText(mouse.X, mouse.Y)
 		return m, nil
 	}
-	
-	mouse := msg.Mouse()
+	m.hover = ""
 	
 	// Convert screen coordinates to buffer position
 	bufferPos := m.screenToBufferSafe(mouse.Y, mouse.X)
@@ -465,26 +830,23 @@ func (m *Model) handleMouseWheel(msg tea.MouseWheelMsg) (tea.Model, tea.Cmd) {
 		return m, nil
 	}
 	
-	const scrollAmount = 3 // Standard amount - matches vim and bubbles/viewport
+	dx, dy := m.wheelDelta(msg.Mouse())
 	
-	mouse := msg.Mouse()
+	// Preview keeps its own scroll offset and never scrolls sideways
+	if m.previewMode {
+		m.scrollPreview(dy)
+		return m, nil
+	}
 	
-	switch mouse.Button {
-	case tea.MouseWheelUp:
-		// Scroll viewport up 3 lines (standard) - content moves down
-		m.editor.ScrollViewportUp(scrollAmount)
-		
-	case tea.MouseWheelDown:
-		// Scroll viewport down 3 lines (standard) - content moves up
-		m.editor.ScrollViewportDown(scrollAmount)
-		
-	case tea.MouseWheelLeft:
-		// Horizontal scroll left - typically 2 columns is standard
-		m.editor.ScrollViewportLeft(2)
-		
-	case tea.MouseWheelRight:
-		// Horizontal scroll right - typically 2 columns is standard
-		m.editor.ScrollViewportRight(2)
+	switch {
+	case dy < 0:
+		m.editor.ScrollViewportUp(-dy)
+	case dy > 0:
+		m.editor.ScrollViewportDown(dy)
+	case dx < 0:
+		m.editor.ScrollViewportLeft(-dx)
+	case dx > 0:
+		m.editor.ScrollViewportRight(dx)
 	}
 	
 	return m, nil
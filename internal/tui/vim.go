@@ -0,0 +1,521 @@
+package tui
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/ofri/mde/pkg/ast"
+)
+
+// vimMode is the current mode of the vim keymap.
+type vimMode int
+
+const (
+	vimNormal vimMode = iota
+	vimInsert
+	vimVisual
+	vimVisualLine
+)
+
+// vimState holds the modal keymap enabled with keymap = "vim".
+type vimState struct {
+	mode vimMode
+
+	count   int    // Count typed before a command, 0 when none
+	pending string // Operator or prefix waiting for its next key: d, y, c or g
+
+	// linewise is set when the clipboard holds whole lines (dd, yy, V),
+	// which p and P put on their own lines
+	linewise bool
+
+	// visualAnchor is the line where V started
+	visualAnchor int
+}
+
+// motions are the commands that only move the cursor, which the vim keys
+// run to move it and to select the targets of d, y and c.
+var motions = []struct {
+	name, description string
+	move              func(e *ast.Editor)
+}{
+	{"cursor-left", "Move the cursor one character left", (*ast.Editor).MoveCursorLeft},
+	{"cursor-right", "Move the cursor one character right", (*ast.Editor).MoveCursorRight},
+	{"cursor-down", "Move the cursor one line down", (*ast.Editor).MoveCursorDown},
+	{"cursor-up", "Move the cursor one line up", (*ast.Editor).MoveCursorUp},
+	{"word-right", "Move the cursor to the start of the next word", (*ast.Editor).MoveCursorWordRight},
+	{"word-left", "Move the cursor to the start of the word", (*ast.Editor).MoveCursorWordLeft},
+	{"word-end", "Move the cursor past the end of the word", moveToWordEnd},
+	{"line-start", "Move the cursor to the start of the line", (*ast.Editor).MoveCursorToLineStart},
+	{"line-end", "Move the cursor to the end of the line", (*ast.Editor).MoveCursorToLineEnd},
+	{"first-non-blank", "Move the cursor to the first non-blank character of the line", moveToFirstNonBlank},
+	{"paragraph-up", "Move the cursor to the previous paragraph", (*ast.Editor).MoveCursorParagraphUp},
+	{"paragraph-down", "Move the cursor to the next paragraph", (*ast.Editor).MoveCursorParagraphDown},
+	{"sentence-left", "Move the cursor to the start of the sentence", (*ast.Editor).MoveCursorSentenceLeft},
+	{"sentence-right", "Move the cursor to the start of the next sentence", (*ast.Editor).MoveCursorSentenceRight},
+}
+
+// vimMotions are the motion commands of the normal and visual mode keys,
+// which d, y and c also take.
+var vimMotions = map[string]string{
+	"h": "cursor-left", "left": "cursor-left", "backspace": "cursor-left",
+	"l": "cursor-right", "right": "cursor-right", "space": "cursor-right",
+	"j": "cursor-down", "down": "cursor-down", "enter": "cursor-down",
+	"k": "cursor-up", "up": "cursor-up",
+	"w": "word-right",
+	"b": "word-left",
+	"0": "line-start", "home": "line-start",
+	"$": "line-end", "end": "line-end",
+	"^": "first-non-blank",
+	"{": "paragraph-up",
+	"}": "paragraph-down",
+	"(": "sentence-left",
+	")": "sentence-right",
+}
+
+// vimOperators are the operator commands of d, y and c.
+var vimOperators = map[string]string{"d": "vim-delete", "y": "vim-yank", "c": "vim-change"}
+
+func init() {
+	registerCommand(Command{
+		Name:        "vim-mode",
+		Description: "Toggle the modal vim keymap",
+		Run:         (*Model).toggleVim,
+	})
+	for _, motion := range motions {
+		move := motion.move
+		registerCommand(Command{
+			Name:        motion.name,
+			Description: motion.description,
+			Run: func(m *Model) tea.Cmd {
+				move(m.editor)
+				return nil
+			},
+		})
+	}
+	registerCommand(Command{
+		Name:        "vim-delete",
+		Description: "Cut the selection, or in vim normal mode the text the next motion moves over",
+		Run:         func(m *Model) tea.Cmd { return m.vimOperatorCommand("d") },
+	})
+	registerCommand(Command{
+		Name:        "vim-yank",
+		Description: "Copy the selection, or in vim normal mode the text the next motion moves over",
+		Run:         func(m *Model) tea.Cmd { return m.vimOperatorCommand("y") },
+	})
+	registerCommand(Command{
+		Name:        "vim-change",
+		Description: "Cut the selection and insert, or in vim normal mode do so to the text the next motion moves over",
+		Run:         func(m *Model) tea.Cmd { return m.vimOperatorCommand("c") },
+	})
+}
+
+func (m *Model) toggleVim() tea.Cmd {
+	if m.vimEnabled() {
+		m.config.Keymap = "default"
+		m.editor.GetCursor().ClearSelection()
+		m.showMessage("Vim keymap off")
+	} else {
+		m.config.Keymap = "vim"
+		m.showMessage("Vim keymap on (i to insert, Esc for normal mode)")
+	}
+	m.vim = vimState{}
+	return nil
+}
+
+func (m *Model) vimEnabled() bool {
+	return m.config.Keymap == "vim"
+}
+
+// handleVimKey runs key through the vim keymap. Returns false for keys the
+// regular bindings should handle: everything in insert mode except Esc,
+// and Ctrl/Alt chords in every mode, so saving, quitting and the command
+// prompt work as usual.
+func (m *Model) handleVimKey(msg tea.KeyPressMsg) (bool, tea.Cmd) {
+	key := msg.String()
+	if strings.HasPrefix(key, "ctrl+") || strings.HasPrefix(key, "alt+") {
+		return false, nil
+	}
+
+	switch m.vim.mode {
+	case vimInsert:
+		if key != "esc" {
+			return false, nil
+		}
+		m.vim.mode = vimNormal
+		if m.editor.GetCursor().GetBufferPos().Col > 0 {
+			m.editor.MoveCursorLeft()
+		}
+		return true, nil
+	case vimVisual, vimVisualLine:
+		return true, m.vimVisualKey(key)
+	}
+	return true, m.vimNormalKey(key)
+}
+
+// vimNormalKey handles a key in normal mode.
+func (m *Model) vimNormalKey(key string) tea.Cmd {
+	// Counts: 0 is a motion unless it continues a count
+	if len(key) == 1 && key[0] >= '0' && key[0] <= '9' && (key != "0" || m.vim.count > 0) {
+		m.vim.count = m.vim.count*10 + int(key[0]-'0')
+		return nil
+	}
+	count := max(m.vim.count, 1)
+	explicitCount := m.vim.count
+	pending := m.vim.pending
+	m.vim.count, m.vim.pending = 0, ""
+
+	switch pending {
+	case "g":
+		if key == "g" {
+			m.vimGotoLine(explicitCount, 1)
+		}
+		return nil
+	case "d", "y", "c":
+		return m.vimOperator(pending, key, count)
+	}
+
+	if motion, ok := vimMotions[key]; ok {
+		for i := 0; i < count; i++ {
+			m.RunCommand(motion)
+		}
+		return nil
+	}
+	if operator, ok := vimOperators[key]; ok {
+		cmd, _ := m.RunCommand(operator)
+		m.vim.count = explicitCount
+		return cmd
+	}
+
+	editor := m.editor
+	switch key {
+	case "esc":
+		// Cancels a count or pending operator
+	case "g":
+		m.vim.pending = key
+		m.vim.count = explicitCount
+	case "G":
+		m.vimGotoLine(explicitCount, editor.GetDocument().LineCount())
+	case "i":
+		m.vim.mode = vimInsert
+	case "a":
+		if editor.GetCursor().GetBufferPos().Col < editor.GetDocument().GetLineLength(editor.GetCursor().GetBufferPos().Line) {
+			editor.MoveCursorRight()
+		}
+		m.vim.mode = vimInsert
+	case "I":
+		moveToFirstNonBlank(editor)
+		m.vim.mode = vimInsert
+	case "A":
+		editor.MoveCursorToLineEnd()
+		m.vim.mode = vimInsert
+	case "o":
+		editor.MoveCursorToLineEnd()
+		editor.InsertText("\n")
+		m.vim.mode = vimInsert
+	case "O":
+		editor.MoveCursorToLineStart()
+		editor.InsertText("\n")
+		editor.MoveCursorUp()
+		m.vim.mode = vimInsert
+	case "x", "delete":
+		// Never past the end of the line, as in vim
+		pos := editor.GetCursor().GetBufferPos()
+		if n := min(count, editor.GetDocument().GetLineLength(pos.Line)-pos.Col); n > 0 {
+			editor.DeleteTextForward(n)
+		}
+	case "D":
+		editor.SelectToLineEnd()
+		m.vimCut(false)
+	case "p":
+		m.vimPut(true)
+	case "P":
+		m.vimPut(false)
+	case "v":
+		editor.GetCursor().StartSelection()
+		m.vim.mode = vimVisual
+	case "V":
+		m.vim.visualAnchor = editor.GetCursor().GetBufferPos().Line
+		m.vim.mode = vimVisualLine
+		m.vimSelectLines()
+	case "/":
+		m.startFind(false)
+	case "?":
+		m.startFind(true)
+	case "n":
+		m.searchAgain(false)
+	case "N":
+		m.searchAgain(true)
+	case ":":
+		m.mode = ModeCommand
+		m.input = ""
+	}
+	return nil
+}
+
+// vimOperator applies d, y or c to a motion, or to whole lines when the
+// operator key is repeated (dd, yy, cc).
+func (m *Model) vimOperator(operator, key string, count int) tea.Cmd {
+	editor := m.editor
+	editor.GetCursor().ClearSelection()
+
+	if key == operator {
+		line := editor.GetCursor().GetBufferPos().Line
+		last := min(line+count-1, editor.GetDocument().LineCount()-1)
+		m.vimYankLines(line, last)
+		switch operator {
+		case "d":
+			m.vimDeleteLines(line, last)
+		case "c":
+			// The lines give way to one empty line, where it was
+			editor.GetCursor().SetSelection(&ast.Selection{
+				Start: ast.BufferPos{Line: line, Col: 0},
+				End:   ast.BufferPos{Line: last, Col: editor.GetDocument().GetLineLength(last)},
+			})
+			editor.DeleteSelection()
+			editor.GetCursor().SetBufferPos(ast.BufferPos{Line: line, Col: 0})
+			m.vim.mode = vimInsert
+		}
+		return nil
+	}
+
+	motion, ok := vimMotions[key]
+	if !ok {
+		return nil
+	}
+	if operator == "c" && key == "w" {
+		// cw changes to the end of the word, keeping the space after it
+		motion = "word-end"
+	}
+	start := editor.GetCursor().GetBufferPos()
+	for i := 0; i < count; i++ {
+		editor.SelectWith(func() { m.RunCommand(motion) })
+	}
+	switch operator {
+	case "y":
+		m.vimYank(false)
+		editor.GetCursor().SetBufferPos(start)
+	case "d":
+		m.vimCut(false)
+	case "c":
+		m.vimCut(false)
+		m.vim.mode = vimInsert
+	}
+	return nil
+}
+
+// vimVisualKey handles a key in visual and visual line mode.
+func (m *Model) vimVisualKey(key string) tea.Cmd {
+	editor := m.editor
+	linewise := m.vim.mode == vimVisualLine
+
+	if motion, ok := vimMotions[key]; ok {
+		m.RunCommand(motion)
+		if linewise {
+			m.vimSelectLines()
+		} else {
+			editor.GetCursor().ExtendSelection()
+		}
+		return nil
+	}
+	if key == "x" {
+		key = "d"
+	}
+	if operator, ok := vimOperators[key]; ok {
+		cmd, _ := m.RunCommand(operator)
+		return cmd
+	}
+
+	switch key {
+	case "esc", "v", "V":
+		editor.GetCursor().ClearSelection()
+		m.vim.mode = vimNormal
+	}
+	return nil
+}
+
+// vimOperatorCommand applies operator (d, y or c) to the selection. In
+// vim normal mode it waits for the motion or repeated key saying what to
+// apply it to instead.
+func (m *Model) vimOperatorCommand(operator string) tea.Cmd {
+	linewise := m.vim.mode == vimVisualLine
+	visual := m.vim.mode == vimVisual || linewise
+	switch {
+	case m.vimEnabled() && !visual:
+		m.vim.pending = operator
+		return nil
+	case m.editor.GetCursor().GetSelection() == nil:
+		m.showMessage("Nothing selected")
+		return nil
+	}
+
+	switch operator {
+	case "y":
+		m.vimYank(linewise)
+		m.vim.mode = vimNormal
+	case "d":
+		m.vimCut(linewise)
+		m.vim.mode = vimNormal
+	case "c":
+		m.vimCut(linewise)
+		m.vim.mode = vimInsert
+	}
+	return nil
+}
+
+// vimSelectLines selects the whole lines between the V anchor and the
+// cursor.
+func (m *Model) vimSelectLines() {
+	doc := m.editor.GetDocument()
+	first, last := m.vim.visualAnchor, m.editor.GetCursor().GetBufferPos().Line
+	if first > last {
+		first, last = last, first
+	}
+	m.editor.GetCursor().SetSelection(&ast.Selection{
+		Start: ast.BufferPos{Line: first, Col: 0},
+		End:   ast.BufferPos{Line: last, Col: doc.GetLineLength(last)},
+	})
+}
+
+// vimYank copies the selection and drops it, leaving the cursor at its
+// start as vim does.
+func (m *Model) vimYank(linewise bool) {
+	selection := m.editor.GetCursor().GetSelection()
+	if selection == nil {
+		return
+	}
+	start := selection.Start
+	if selection.End.Line < start.Line || (selection.End.Line == start.Line && selection.End.Col < start.Col) {
+		start = selection.End
+	}
+	m.editor.Copy()
+	m.editor.GetCursor().ClearSelection()
+	m.editor.GetCursor().SetBufferPos(start)
+	m.vim.linewise = linewise
+}
+
+// vimCut moves the selection to the clipboard. Linewise cuts also remove
+// the line breaks of the selected lines.
+func (m *Model) vimCut(linewise bool) {
+	selection := m.editor.GetCursor().GetSelection()
+	if selection == nil {
+		return
+	}
+	if !linewise {
+		m.editor.Cut()
+		m.vim.linewise = false
+		return
+	}
+	first, last := selection.Start.Line, selection.End.Line
+	if first > last {
+		first, last = last, first
+	}
+	m.vimYankLines(first, last)
+	m.vimDeleteLines(first, last)
+}
+
+// vimYankLines copies lines first through last without their final line
+// break.
+func (m *Model) vimYankLines(first, last int) {
+	doc := m.editor.GetDocument()
+	m.editor.GetCursor().SetSelection(&ast.Selection{
+		Start: ast.BufferPos{Line: first, Col: 0},
+		End:   ast.BufferPos{Line: last, Col: doc.GetLineLength(last)},
+	})
+	m.vimYank(true)
+}
+
+// vimDeleteLines removes lines first through last entirely.
+func (m *Model) vimDeleteLines(first, last int) {
+	doc := m.editor.GetDocument()
+	start := ast.BufferPos{Line: first, Col: 0}
+	end := ast.BufferPos{Line: last + 1, Col: 0}
+	if last == doc.LineCount()-1 {
+		// No line break after the last line; take the one before instead
+		end = ast.BufferPos{Line: last, Col: doc.GetLineLength(last)}
+		if first > 0 {
+			start = ast.BufferPos{Line: first - 1, Col: doc.GetLineLength(first - 1)}
+		}
+	}
+	m.editor.GetCursor().SetSelection(&ast.Selection{Start: start, End: end})
+	m.editor.DeleteSelection()
+	m.editor.GetCursor().SetBufferPos(ast.BufferPos{Line: min(first, doc.LineCount()-1), Col: 0})
+	moveToFirstNonBlank(m.editor)
+}
+
+// vimPut pastes after (p) or before (P) the cursor; yanked lines go on
+// their own lines below or above the current one.
+func (m *Model) vimPut(after bool) {
+	editor := m.editor
+	if !m.vim.linewise {
+		pos := editor.GetCursor().GetBufferPos()
+		if after && pos.Col < editor.GetDocument().GetLineLength(pos.Line) {
+			editor.MoveCursorRight()
+		}
+		editor.Paste()
+		return
+	}
+
+	var line int
+	if after {
+		editor.MoveCursorToLineEnd()
+		editor.InsertText("\n")
+		line = editor.GetCursor().GetBufferPos().Line
+		editor.Paste()
+	} else {
+		editor.MoveCursorToLineStart()
+		line = editor.GetCursor().GetBufferPos().Line
+		editor.Paste()
+		editor.InsertText("\n")
+	}
+	editor.GetCursor().SetBufferPos(ast.BufferPos{Line: line, Col: 0})
+	moveToFirstNonBlank(editor)
+}
+
+// vimGotoLine jumps to line count (1-based), or fallback without a count.
+func (m *Model) vimGotoLine(count, fallback int) {
+	if count == 0 {
+		count = fallback
+	}
+	m.editor.GotoLine(count)
+	moveToFirstNonBlank(m.editor)
+	m.editor.AdjustViewPort()
+}
+
+// vimStatus is the mode indicator shown in the status bar.
+func (m *Model) vimStatus() string {
+	pending := m.vim.pending
+	if m.vim.count > 0 {
+		pending = strconv.Itoa(m.vim.count) + pending
+	}
+	label := map[vimMode]string{
+		vimInsert:     "-- INSERT --",
+		vimVisual:     "-- VISUAL --",
+		vimVisualLine: "-- VISUAL LINE --",
+	}[m.vim.mode]
+	return strings.TrimSpace(label + " " + pending)
+}
+
+// moveToWordEnd moves the cursor just past the end of the word it is on,
+// or of the next word.
+func moveToWordEnd(e *ast.Editor) {
+	doc := e.GetDocument()
+	pos := e.GetCursor().GetBufferPos()
+	if unicode.IsSpace(doc.GetCharAt(pos)) || pos.Col >= doc.GetLineLength(pos.Line) {
+		pos = doc.MoveCursorWordRight(pos)
+	}
+	end := doc.FindWordEnd(pos)
+	e.GetCursor().SetBufferPos(end)
+	e.GetCursor().SetDesiredColumn(end.Col)
+}
+
+// moveToFirstNonBlank moves the cursor to the first non-blank character
+// of its line.
+func moveToFirstNonBlank(e *ast.Editor) {
+	pos := e.GetCursor().GetBufferPos()
+	line := e.GetDocument().GetLine(pos.Line)
+	indent := len([]rune(line)) - len([]rune(strings.TrimLeft(line, " \t")))
+	e.GetCursor().SetBufferPos(ast.BufferPos{Line: pos.Line, Col: indent})
+	e.GetCursor().SetDesiredColumn(indent)
+}
@@ -0,0 +1,97 @@
+package tui
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/ofri/mde/internal/wiki"
+)
+
+// workspaceRoot is the directory wiki links resolve against: the current
+// file's directory, or the working directory for untitled buffers.
+func (m *Model) workspaceRoot() string {
+	if filename := m.editor.GetDocument().GetFilename(); filename != "" {
+		return filepath.Dir(filename)
+	}
+	if cwd, err := os.Getwd(); err == nil {
+		return cwd
+	}
+	return "."
+}
+
+// followWikiLink opens the note targeted by the link under the cursor.
+// Missing targets are created as new unsaved buffers titled after the link.
+func (m *Model) followWikiLink() tea.Cmd {
+	if !m.config.WikiLinks {
+		m.showMessage("Wiki links are disabled (set wiki_links = true)")
+		return nil
+	}
+
+	pos := m.editor.GetCursor().GetBufferPos()
+	link, ok := wiki.LinkAt(m.editor.GetDocument().GetLine(pos.Line), pos.Col)
+	if !ok {
+		m.showMessage("No wiki link under cursor")
+		return nil
+	}
+
+	if m.editor.GetDocument().IsModified() {
+		m.showMessage("Unsaved changes - save (^S) before following links")
+		return nil
+	}
+
+	root := m.workspaceRoot()
+	if path, found := wiki.Resolve(root, link.Target); found {
+		m.SetFilename(path)
+		m.showMessage("Opened " + link.Target)
+		return m.startDecrypt()
+	}
+
+	path := wiki.NewNotePath(root, link.Target)
+	m.NewBuffer("# "+link.Target+"\n\n", path)
+	m.showMessage("New note " + filepath.Base(path) + " (unsaved)")
+	return nil
+}
+
+// completeWikiLink completes a partially typed [[target at the cursor.
+// Returns false if the cursor is not inside an unclosed wiki link.
+func (m *Model) completeWikiLink() bool {
+	if !m.config.WikiLinks {
+		return false
+	}
+
+	pos := m.editor.GetCursor().GetBufferPos()
+	partial, ok := wiki.PartialAt(m.editor.GetDocument().GetLine(pos.Line), pos.Col)
+	if !ok {
+		return false
+	}
+
+	var matches []string
+	for _, name := range wiki.NoteNames(m.workspaceRoot()) {
+		if strings.HasPrefix(strings.ToLower(name), strings.ToLower(partial)) {
+			matches = append(matches, name)
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		m.showMessage("No notes match " + partial)
+	case 1:
+		m.replaceBeforeCursor(len([]rune(partial)), matches[0]+"]]")
+	default:
+		common := completePrefix("", matches)
+		if len(common) > len(partial) {
+			m.replaceBeforeCursor(len([]rune(partial)), common)
+		}
+		m.showMessage("Notes: " + strings.Join(matches, ", "))
+	}
+	return true
+}
+
+// replaceBeforeCursor deletes count characters before the cursor and
+// inserts text in their place.
+func (m *Model) replaceBeforeCursor(count int, text string) {
+	m.editor.DeleteText(count)
+	m.editor.InsertText(text)
+}
@@ -0,0 +1,160 @@
+package tui
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/ofri/mde/internal/theme"
+	"github.com/ofri/mde/pkg/ast"
+	"github.com/ofri/mde/pkg/plugin"
+)
+
+// zenState tracks distraction-free writing mode.
+type zenState struct {
+	enabled bool
+
+	// lineNumbers remembers whether line numbers were on before zen mode
+	// hid them, so leaving restores them
+	lineNumbers bool
+}
+
+func init() {
+	registerCommand(Command{
+		Name:        "zen-mode",
+		Description: "Toggle distraction-free writing (Alt+Z)",
+		Run:         (*Model).toggleZen,
+	})
+}
+
+// toggleZen enters or leaves zen mode: a centered column of zen_width,
+// no line numbers or help bar, and everything but the text under the
+// cursor dimmed.
+func (m *Model) toggleZen() tea.Cmd {
+	m.zen.enabled = !m.zen.enabled
+	if m.zen.enabled {
+		m.zen.lineNumbers = m.editor.ShowLineNumbers()
+		if m.zen.lineNumbers {
+			m.editor.ToggleLineNumbers()
+		}
+		m.showMessage("Zen mode (Alt+Z to leave)")
+	} else {
+		if m.zen.lineNumbers != m.editor.ShowLineNumbers() {
+			m.editor.ToggleLineNumbers()
+		}
+		m.showMessage("Zen mode off")
+	}
+	m.resizeEditor()
+	return nil
+}
+
+// IsZenMode reports whether distraction-free mode is on.
+func (m *Model) IsZenMode() bool {
+	return m.zen.enabled
+}
+
+// resizeEditor fits the editor viewport to the text area.
+func (m *Model) resizeEditor() {
+	if m.editor != nil {
+		m.editor.SetViewPort(m.textWidth(), m.GetContentHeight())
+	}
+}
+
+// textWidth is the width of the text column: the terminal width, or
+// zen_width in zen mode when the terminal is wider.
+func (m *Model) textWidth() int {
+	if m.zen.enabled && m.config.ZenWidth > 0 && m.config.ZenWidth < m.width {
+		return m.config.ZenWidth
+	}
+	return m.width
+}
+
+// textMargin is the blank space left of the centered zen column.
+func (m *Model) textMargin() int {
+	return (m.width - m.textWidth()) / 2
+}
+
+// indentText shifts rendered content right by the zen margin.
+func (m *Model) indentText(content string) string {
+	margin := m.textMargin()
+	if margin <= 0 {
+		return content
+	}
+	pad := strings.Repeat(" ", margin)
+	return pad + strings.ReplaceAll(content, "\n", "\n"+pad)
+}
+
+// dimUnfocused dims the rendered editor lines outside the paragraph or
+// sentence holding the cursor, as chosen by zen_focus.
+func (m *Model) dimUnfocused(lines []plugin.RenderedLine) {
+	if !m.zen.enabled || m.config.ZenFocus == "none" {
+		return
+	}
+
+	doc := m.editor.GetDocument()
+	cursor := m.editor.GetCursor().GetBufferPos()
+	first := blockStart(doc, cursor.Line)
+	last := blockEnd(doc, cursor.Line)
+
+	// Focus spans [first:startCol, last:endCol) in document runes
+	startCol, endCol := 0, utf8.RuneCountInString(doc.GetLine(last))
+	if _, inFence := doc.FencedBlockAt(cursor.Line); m.config.ZenFocus == "sentence" && !inFence {
+		if sentence, ok := doc.SentenceAt(cursor); ok {
+			first, startCol = sentence.Start.Line, sentence.Start.Col
+			last, endCol = sentence.End.Line, sentence.End.Col
+		}
+	}
+
+	// Text outside the focus is dimmed
+	dimStyle := theme.Resolved().Style(theme.ZenDim)
+	viewport := m.editor.GetViewport()
+	left := viewport.GetLeftColumn()
+	for i := range lines {
+		line := viewport.GetTopLine() + i
+		text := doc.GetLine(line)
+		length := utf8.RuneCountInString(lines[i].Content)
+		dim := func(from, to int) {
+			from, to = viewport.DisplayColumn(text, from), viewport.DisplayColumn(text, to)
+			from, to = max(from-left, 0), min(to-left, length)
+			if from < to {
+				lines[i].Styles = append(lines[i].Styles, plugin.StyleRange{Start: from, End: to, Style: dimStyle})
+			}
+		}
+
+		switch {
+		case line < first || line > last:
+			lines[i].Styles = []plugin.StyleRange{{Start: 0, End: length, Style: dimStyle}}
+		default:
+			if line == first {
+				dim(0, startCol)
+			}
+			if line == last {
+				dim(endCol, length+left)
+			}
+		}
+	}
+}
+
+// blockEnd returns the last line of the block containing line, the
+// counterpart of blockStart.
+func blockEnd(doc *ast.Document, line int) int {
+	fences := doc.FencedBlocks()
+	for _, fence := range fences {
+		if fence.Contains(line) {
+			return fence.EndLine
+		}
+	}
+	if strings.TrimSpace(doc.GetLine(line)) == "" {
+		return line
+	}
+
+	for line < doc.LineCount()-1 && strings.TrimSpace(doc.GetLine(line+1)) != "" {
+		for _, fence := range fences {
+			if fence.Contains(line + 1) {
+				return line
+			}
+		}
+		line++
+	}
+	return line
+}
@@ -0,0 +1,166 @@
+// Package wiki implements [[Page Name]] style links between notes.
+//
+// A wiki link names another note in the workspace (the directory tree of
+// the current file). Targets may carry an alias or a heading anchor:
+//
+//	[[Page Name]]              → Page Name.md
+//	[[Page Name|shown text]]   → Page Name.md, displayed as "shown text"
+//	[[Page Name#Setup]]        → Page Name.md, heading "Setup"
+//
+// Name matching is case-insensitive and treats spaces, dashes and
+// underscores as equivalent, so [[my note]] also finds my-note.md.
+package wiki
+
+import (
+	"io/fs"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/ofri/mde/pkg/ast"
+)
+
+// maxWalkFiles bounds workspace scans so huge trees cannot stall the UI.
+const maxWalkFiles = 10000
+
+var linkRe = regexp.MustCompile(`\[\[([^\[\]|#]+)(?:#([^\[\]|]*))?(?:\|([^\[\]]*))?\]\]`)
+
+// Link is a wiki link occurrence within a line.
+// Start and End are byte offsets of the full "[[...]]" text.
+type Link struct {
+	Start   int
+	End     int
+	Target  string
+	Heading string
+	Alias   string
+}
+
+// FindLinks returns every wiki link in line.
+func FindLinks(line string) []Link {
+	var links []Link
+	for _, m := range linkRe.FindAllStringSubmatchIndex(line, -1) {
+		link := Link{
+			Start:  m[0],
+			End:    m[1],
+			Target: strings.TrimSpace(line[m[2]:m[3]]),
+		}
+		if m[4] >= 0 {
+			link.Heading = strings.TrimSpace(line[m[4]:m[5]])
+		}
+		if m[6] >= 0 {
+			link.Alias = strings.TrimSpace(line[m[6]:m[7]])
+		}
+		links = append(links, link)
+	}
+	return links
+}
+
+// LinkAt returns the link under the rune column col, if any.
+func LinkAt(line string, col int) (Link, bool) {
+	offset := ast.ByteOffset(line, col)
+	for _, link := range FindLinks(line) {
+		if offset >= link.Start && offset < link.End {
+			return link, true
+		}
+	}
+	return Link{}, false
+}
+
+// PartialAt reports whether rune column col sits right after an unclosed
+// "[[" on line, returning the partially typed target name.
+func PartialAt(line string, col int) (string, bool) {
+	before := line[:ast.ByteOffset(line, col)]
+	open := strings.LastIndex(before, "[[")
+	if open < 0 {
+		return "", false
+	}
+	partial := before[open+2:]
+	if strings.ContainsAny(partial, "[]|#") {
+		return "", false
+	}
+	return partial, true
+}
+
+// NoteNames lists the names (file base without .md) of all markdown notes
+// under root, sorted.
+func NoteNames(root string) []string {
+	var names []string
+	walkNotes(root, func(path string) bool {
+		names = append(names, strings.TrimSuffix(filepath.Base(path), filepath.Ext(path)))
+		return true
+	})
+	sort.Strings(names)
+	return names
+}
+
+// Resolve finds the note file for target under root.
+// Returns false if no matching note exists.
+func Resolve(root, target string) (string, bool) {
+	want := normalize(target)
+	var found string
+	walkNotes(root, func(path string) bool {
+		name := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+		if normalize(name) == want {
+			found = path
+			return false
+		}
+		return true
+	})
+	return found, found != ""
+}
+
+// NewNotePath returns where a missing target should be created.
+func NewNotePath(root, target string) string {
+	name := strings.Map(func(r rune) rune {
+		if r == '/' || r == '\\' || r == 0 {
+			return '-'
+		}
+		return r
+	}, strings.TrimSpace(target))
+	return filepath.Join(root, name+".md")
+}
+
+// walkNotes calls fn for each markdown file under root, skipping hidden
+// directories. Walking stops when fn returns false.
+func walkNotes(root string, fn func(path string) bool) {
+	if root == "" {
+		root = "."
+	}
+	count := 0
+	_ = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if d.IsDir() {
+			if path != root && strings.HasPrefix(d.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if count++; count > maxWalkFiles {
+			return filepath.SkipAll
+		}
+		if !isMarkdown(path) {
+			return nil
+		}
+		if !fn(path) {
+			return filepath.SkipAll
+		}
+		return nil
+	})
+}
+
+func isMarkdown(path string) bool {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".md", ".markdown":
+		return true
+	}
+	return false
+}
+
+// normalize folds case and separators for name comparison.
+func normalize(name string) string {
+	name = strings.ToLower(strings.TrimSpace(name))
+	return strings.NewReplacer("-", " ", "_", " ").Replace(name)
+}
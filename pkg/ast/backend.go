@@ -0,0 +1,73 @@
+package ast
+
+import (
+	"context"
+	"os"
+	"strings"
+	"sync"
+)
+
+// FileBackend reads and writes the files an Editor loads and saves, named
+// by local paths or URLs like "ssh://host/notes.md". ReadFile returns an
+// error wrapping os.ErrNotExist for a file that does not exist yet.
+type FileBackend interface {
+	ReadFile(ctx context.Context, name string) ([]byte, error)
+	WriteFile(ctx context.Context, name string, data []byte) error
+}
+
+// LocalFiles is the local file system, the backend until SetFileBackend
+// installs another.
+var LocalFiles FileBackend = localBackend{}
+
+var (
+	backendMu sync.RWMutex
+	backend   = LocalFiles
+)
+
+// SetFileBackend routes every file read and write through b; nil restores
+// LocalFiles. Package plugin installs its storage plugins this way.
+func SetFileBackend(b FileBackend) {
+	backendMu.Lock()
+	defer backendMu.Unlock()
+	if b == nil {
+		b = LocalFiles
+	}
+	backend = b
+}
+
+func fileBackend() FileBackend {
+	backendMu.RLock()
+	defer backendMu.RUnlock()
+	return backend
+}
+
+// FileScheme returns the scheme of a name like "ssh://host/notes.md", or ""
+// for a local path.
+func FileScheme(name string) string {
+	scheme, _, ok := strings.Cut(name, "://")
+	if !ok || scheme == "" || strings.ContainsAny(scheme, `/\`) {
+		return ""
+	}
+	return scheme
+}
+
+// ReadFile reads name through the file backend.
+func ReadFile(ctx context.Context, name string) ([]byte, error) {
+	return fileBackend().ReadFile(ctx, name)
+}
+
+// WriteFile writes data to name through the file backend.
+func WriteFile(ctx context.Context, name string, data []byte) error {
+	return fileBackend().WriteFile(ctx, name, data)
+}
+
+// localBackend is the local file system.
+type localBackend struct{}
+
+func (localBackend) ReadFile(_ context.Context, name string) ([]byte, error) {
+	return os.ReadFile(name)
+}
+
+func (localBackend) WriteFile(_ context.Context, name string, data []byte) error {
+	return os.WriteFile(name, data, 0644)
+}
@@ -0,0 +1,59 @@
+package ast
+
+import "strings"
+
+// codeIndent is the indentation, in columns, that makes an indented code
+// block in Markdown
+const codeIndent = 4
+
+// QuotePrefix returns the blockquote markers that open line, with their
+// indentation and following space, e.g. "> > " for "> > text". Returns ""
+// for lines outside a blockquote.
+func QuotePrefix(line string) string {
+	_, _, content := QuoteDepth(line)
+	return line[:content]
+}
+
+// LeadingWhitespace returns the spaces and tabs that indent line.
+func LeadingWhitespace(line string) string {
+	return line[:len(line)-len(strings.TrimLeft(line, " \t"))]
+}
+
+// InsertNewline breaks the line at the cursor like InsertText("\n"), then
+// continues the block the cursor is in on the new line:
+//   - in a blockquote the "> " markers are repeated; on a quote line with
+//     no text the markers are removed instead, ending the quote with a
+//     blank line so the next paragraph is not a lazy continuation
+//   - in a fenced or indented code block the indentation is kept
+//
+// The line is simply broken when the cursor is within the markers or
+// indentation themselves.
+func (e *Editor) InsertNewline() {
+	e.deleteSelection()
+	pos := e.cursorManager.GetBufferPos()
+	line := e.document.GetLine(pos.Line)
+
+	quote := QuotePrefix(line)
+	_, inFence := e.document.FencedBlockAt(pos.Line)
+
+	prefix := ""
+	switch {
+	case inFence:
+		prefix = LeadingWhitespace(line)
+	case quote != "":
+		prefix = quote
+	case DisplayColumn(line, len([]rune(LeadingWhitespace(line))), codeIndent) >= codeIndent:
+		prefix = LeadingWhitespace(line)
+	}
+	if pos.Col < len([]rune(prefix)) {
+		prefix = ""
+	}
+
+	// An empty quote line ends the quote
+	if !inFence && prefix == quote && prefix != "" && strings.TrimSpace(line) == strings.TrimSpace(quote) {
+		e.document.DeleteRange(BufferPos{Line: pos.Line}, BufferPos{Line: pos.Line, Col: e.document.GetLineLength(pos.Line)})
+		e.cursorManager.SetBufferPos(BufferPos{Line: pos.Line})
+		prefix = ""
+	}
+	e.InsertText("\n" + prefix)
+}
@@ -45,6 +45,11 @@ func (p BufferPos) IsValid() bool {
 	return p.Line >= 0 && p.Col >= 0
 }
 
+// Before reports whether p comes before other in the document.
+func (p BufferPos) Before(other BufferPos) bool {
+	return p.Line < other.Line || (p.Line == other.Line && p.Col < other.Col)
+}
+
 // ScreenPos represents a position on the terminal screen.
 // DERIVED: Always computed from BufferPos via viewport.BufferToScreen()
 // NEVER create directly - use viewport transformation
@@ -64,10 +64,19 @@ func (c *CursorManager) GetBufferPos() BufferPos {
 
 // GetScreenPos returns the current cursor position in screen coordinates.
 // Returns error if position is not visible in current viewport.
+// Tabs before the cursor are expanded when the validator is a Document.
 func (c *CursorManager) GetScreenPos() (ScreenPos, error) {
+	if lines, ok := c.validator.(lineSource); ok {
+		return c.viewport.BufferToScreenOnLine(c.bufferPos, lines.GetLine(c.bufferPos.Line))
+	}
 	return c.viewport.BufferToScreen(c.bufferPos)
 }
 
+// lineSource provides line text for tab-aware screen positions
+type lineSource interface {
+	GetLine(lineNum int) string
+}
+
 // SetBufferPos sets the cursor position in buffer coordinates.
 // VALIDATES: Position against document bounds
 // UPDATES: Desired column for vertical movement
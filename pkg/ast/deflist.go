@@ -0,0 +1,50 @@
+package ast
+
+import (
+	"strings"
+)
+
+// DefinitionMarker reports whether text is a definition-list definition
+// (": text", indented at most 3 spaces), returning the byte offset of the
+// colon and of the definition text.
+func DefinitionMarker(text string) (colon, content int, ok bool) {
+	indent := len(text) - len(strings.TrimLeft(text, " "))
+	if indent > 3 || !strings.HasPrefix(text[indent:], ":") {
+		return 0, 0, false
+	}
+
+	rest := text[indent+1:]
+	trimmed := strings.TrimLeft(rest, " \t")
+	if trimmed == "" || len(trimmed) == len(rest) {
+		return 0, 0, false
+	}
+	return indent, len(text) - len(trimmed), true
+}
+
+// IsDefinitionTerm reports whether line is the term of a definition list:
+// a non-blank line directly followed by a definition, optionally with one
+// blank line in between.
+func (d *Document) IsDefinitionTerm(line int) bool {
+	if line < 0 || line >= len(d.lines) {
+		return false
+	}
+
+	text := d.lines[line].text
+	if strings.TrimSpace(text) == "" {
+		return false
+	}
+	if _, _, ok := DefinitionMarker(text); ok {
+		return false
+	}
+
+	for next := line + 1; next < len(d.lines) && next <= line+2; next++ {
+		following := d.lines[next].text
+		if _, _, ok := DefinitionMarker(following); ok {
+			return true
+		}
+		if strings.TrimSpace(following) != "" {
+			return false
+		}
+	}
+	return false
+}
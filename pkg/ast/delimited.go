@@ -0,0 +1,54 @@
+package ast
+
+import (
+	"encoding/csv"
+	"strings"
+)
+
+// TableFromDelimited recognizes tab- or comma-separated data: at least
+// two rows of at least two fields, every row with the same number of
+// fields. Comma-separated data may quote fields as in CSV. The first row
+// becomes the header. ok is false for anything else, such as prose.
+func TableFromDelimited(text string) (Table, bool) {
+	text = strings.TrimRight(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+	if !strings.Contains(text, "\n") {
+		return Table{}, false
+	}
+
+	reader := csv.NewReader(strings.NewReader(text))
+	reader.FieldsPerRecord = 0 // Every row as long as the first
+	if firstLine, _, _ := strings.Cut(text, "\n"); strings.Contains(firstLine, "\t") {
+		reader.Comma = '\t'
+		reader.LazyQuotes = true
+	}
+	rows, err := reader.ReadAll()
+	if err != nil || len(rows) < 2 || len(rows[0]) < 2 {
+		return Table{}, false
+	}
+
+	escaper := strings.NewReplacer("|", `\|`, "\n", " ")
+	table := Table{Align: make([]Alignment, len(rows[0]))}
+	for _, row := range rows {
+		for i, cell := range row {
+			row[i] = escaper.Replace(strings.TrimSpace(cell))
+		}
+		table.Rows = append(table.Rows, row)
+	}
+	table.End = len(table.Rows)
+	return table, true
+}
+
+// InsertTable inserts table, formatted, on lines of its own at the cursor,
+// replacing the selection, and leaves the cursor after it.
+func (e *Editor) InsertTable(table Table) {
+	e.deleteSelection()
+	pos := e.cursorManager.GetBufferPos()
+	text := strings.Join(table.Format(), "\n")
+	if pos.Col > 0 {
+		text = "\n" + text
+	}
+	if pos.Col < e.document.GetLineLength(pos.Line) {
+		text += "\n"
+	}
+	e.InsertText(text)
+}
@@ -10,20 +10,29 @@ type Document struct {
 	lines    []Line
 	filename string
 	modified bool
+	tokens   tokenCache
+	index    lineIndex // Offset of each line, for OffsetToPos and PosToOffset
+	revision int       // Counts edits, so caches of derived structure can tell they are stale
+	outline  outlineCache
+	blocks   spanCache
+	html     htmlCache
+	fences   fenceCache
 }
 
 // Line represents a single line of text with metadata
 type Line struct {
 	text    string
 	length  int
-	tokens  []Token // For future syntax highlighting
+	tokens  *tokenEntry // Syntax highlighting, held in the document's token cache
 }
 
-// Token represents a syntax token for highlighting
+// Token represents a syntax token for highlighting. Start and end are
+// rune offsets within the line.
 type Token struct {
 	start int
 	end   int
 	kind  TokenKind
+	depth int // Nesting level for quotes and lists (1 = outermost)
 }
 
 // NewToken creates a new token
@@ -35,6 +44,16 @@ func NewToken(start, end int, kind TokenKind) Token {
 	}
 }
 
+// NewNestedToken creates a token annotated with its nesting depth
+func NewNestedToken(start, end int, kind TokenKind, depth int) Token {
+	return Token{
+		start: start,
+		end:   end,
+		kind:  kind,
+		depth: depth,
+	}
+}
+
 // TokenKind represents different types of tokens
 type TokenKind int
 
@@ -58,14 +77,33 @@ const (
 	TokenList
 	TokenTable
 	TokenDelimiter
+	TokenWikiLink
+	TokenMath      // Inline $...$ math
+	TokenMathBlock // Display $$...$$ math
+	TokenEmoji     // :shortcode: emoji
+	TokenDefinitionTerm
+	TokenDefinition
+	TokenStrikethrough      // ~~text~~
+	TokenHighlight          // ==text==
+	TokenLinkReference      // id in [text][id]
+	TokenLinkDefinition     // id in [id]: url
+	TokenAutolink           // <https://…> and bare URLs
+	TokenHTMLInline         // Inline tag or <!-- comment -->
+	TokenHTMLBlock          // Line of a raw HTML block
+	TokenFootnoteRef        // [^label]
+	TokenFootnoteDefinition // label in [^label]: text
+	TokenCitation           // @key in [@key, p. 3]
+	TokenInsertion          // Text in a {++suggested insertion++}
+	TokenDeletion           // Text in a {--suggested deletion--}
+	TokenCriticComment      // Text in a {>>review comment<<}
 )
 
-// Start returns the start position of the token
+// Start returns the start rune offset of the token
 func (t Token) Start() int {
 	return t.start
 }
 
-// End returns the end position of the token
+// End returns the end rune offset of the token (exclusive)
 func (t Token) End() int {
 	return t.end
 }
@@ -75,6 +113,11 @@ func (t Token) Kind() TokenKind {
 	return t.kind
 }
 
+// Depth returns the nesting depth of quote and list tokens (0 if not nested)
+func (t Token) Depth() int {
+	return t.depth
+}
+
 
 // Selection is defined in cursor.go as part of the CursorManager architecture
 
@@ -148,6 +191,7 @@ func (d *Document) InsertChar(pos BufferPos, ch rune) BufferPos {
 	line.text = string(newRunes)
 	line.length = len(newRunes)
 	d.modified = true
+	d.linesChanged(pos.Line)
 	
 	return BufferPos{Line: pos.Line, Col: pos.Col + 1}
 }
@@ -173,6 +217,7 @@ func (d *Document) DeleteChar(pos BufferPos) BufferPos {
 	line.text = string(newRunes)
 	line.length = len(newRunes)
 	d.modified = true
+	d.linesChanged(pos.Line)
 	
 	return BufferPos{Line: pos.Line, Col: pos.Col - 1}
 }
@@ -214,6 +259,7 @@ func (d *Document) InsertNewline(pos BufferPos) BufferPos {
 	
 	d.lines = newLines
 	d.modified = true
+	d.linesChanged(pos.Line)
 	
 	return BufferPos{Line: pos.Line + 1, Col: 0}
 }
@@ -240,10 +286,37 @@ func (d *Document) DeleteLine(pos BufferPos) BufferPos {
 	
 	d.lines = newLines
 	d.modified = true
+	d.linesChanged(pos.Line - 1)
 	
 	return BufferPos{Line: pos.Line - 1, Col: newCol}
 }
 
+// DeleteRange removes the text between two positions, given in either
+// order, joining the lines at its ends. Returns the position where the
+// removed text began.
+func (d *Document) DeleteRange(start, end BufferPos) BufferPos {
+	if start.Line > end.Line || (start.Line == end.Line && start.Col > end.Col) {
+		start, end = end, start
+	}
+	start = d.ValidatePosition(start)
+	end = d.ValidatePosition(end)
+	if start == end {
+		return start
+	}
+	
+	first := []rune(d.lines[start.Line].text)
+	last := []rune(d.lines[end.Line].text)
+	joined := string(first[:start.Col]) + string(last[end.Col:])
+	
+	d.lines[start.Line].text = joined
+	d.lines[start.Line].length = len([]rune(joined))
+	d.lines = append(d.lines[:start.Line+1], d.lines[end.Line+1:]...)
+	d.modified = true
+	d.linesChanged(start.Line)
+	
+	return start
+}
+
 // GetText returns the full text content of the document
 func (d *Document) GetText() string {
 	lines := make([]string, len(d.lines))
@@ -268,11 +341,22 @@ func (d *Document) IsModified() bool {
 	return d.modified
 }
 
+// Revision returns a number that changes with every edit, so callers can
+// tell whether the text changed since they last looked.
+func (d *Document) Revision() int {
+	return d.revision
+}
+
 // ClearModified clears the modified flag
 func (d *Document) ClearModified() {
 	d.modified = false
 }
 
+// MarkModified flags the document as having unsaved changes
+func (d *Document) MarkModified() {
+	d.modified = true
+}
+
 // ValidatePosition ensures a position is within document bounds
 func (d *Document) ValidatePosition(pos BufferPos) BufferPos {
 	if pos.Line < 0 {
@@ -327,22 +411,37 @@ func (d *Document) GetCharAt(pos BufferPos) rune {
 	return runes[pos.Col]
 }
 
-// SetLineTokens sets syntax highlighting tokens for a specific line
+// SetLineTokens sets syntax highlighting tokens for a specific line.
+// They are cached for the line's current text, within the token budget.
 func (d *Document) SetLineTokens(lineNum int, tokens []Token) {
 	if lineNum < 0 || lineNum >= len(d.lines) {
 		return
 	}
 	
-	d.lines[lineNum].tokens = tokens
+	line := &d.lines[lineNum]
+	d.tokens.remove(line.tokens)
+	line.tokens = d.tokens.add(line.text, tokens)
 }
 
-// GetLineTokens returns syntax highlighting tokens for a specific line
+// GetLineTokens returns syntax highlighting tokens for a specific line.
+// Lines whose tokens were evicted or went stale are tokenized again with
+// the tokenizer, if one is set; otherwise they have none.
 func (d *Document) GetLineTokens(lineNum int) []Token {
 	if lineNum < 0 || lineNum >= len(d.lines) {
 		return nil
 	}
 	
-	return d.lines[lineNum].tokens
+	if d.HasLineTokens(lineNum) {
+		entry := d.lines[lineNum].tokens
+		d.tokens.touch(entry)
+		return entry.tokens
+	}
+	if d.tokens.tokenize == nil || d.IsLongLine(lineNum) {
+		return nil
+	}
+	tokens := d.tokens.tokenize(d.lines[lineNum].text)
+	d.SetLineTokens(lineNum, tokens)
+	return tokens
 }
 
 // FindWordStart finds the start of the word at the given position
@@ -607,6 +706,99 @@ func (d *Document) MoveCursorWordRight(pos BufferPos) BufferPos {
 	}
 }
 
+// MoveCursorParagraphDown moves cursor to the start of the next paragraph,
+// or to the end of the document after the last one.
+func (d *Document) MoveCursorParagraphDown(pos BufferPos) BufferPos {
+	pos = d.ValidatePosition(pos)
+	
+	// Leave the current paragraph, then skip the blank lines after it
+	line := pos.Line
+	for line < len(d.lines) && !isBlankLine(d.lines[line].text) {
+		line++
+	}
+	for line < len(d.lines) && isBlankLine(d.lines[line].text) {
+		line++
+	}
+	
+	if line >= len(d.lines) {
+		return d.MoveCursorToDocumentEnd(pos)
+	}
+	return BufferPos{Line: line, Col: 0}
+}
+
+// MoveCursorParagraphUp moves cursor to the start of the current paragraph,
+// or of the previous one when already there.
+func (d *Document) MoveCursorParagraphUp(pos BufferPos) BufferPos {
+	pos = d.ValidatePosition(pos)
+	
+	if first, _, ok := d.ParagraphAt(pos.Line); ok && (first < pos.Line || pos.Col > 0) {
+		return BufferPos{Line: first, Col: 0}
+	}
+	
+	line := pos.Line - 1
+	for line >= 0 && isBlankLine(d.lines[line].text) {
+		line--
+	}
+	if line < 0 {
+		return BufferPos{Line: 0, Col: 0}
+	}
+	first, _, _ := d.ParagraphAt(line)
+	return BufferPos{Line: first, Col: 0}
+}
+
+// MoveCursorSentenceRight moves cursor to the start of the next sentence,
+// continuing into the next paragraph after the last sentence of one.
+func (d *Document) MoveCursorSentenceRight(pos BufferPos) BufferPos {
+	pos = d.ValidatePosition(pos)
+	
+	sentence, ok := d.SentenceAt(pos)
+	if !ok {
+		return d.MoveCursorParagraphDown(pos)
+	}
+	
+	// Skip the whitespace after the sentence, staying in the paragraph
+	_, last, _ := d.ParagraphAt(pos.Line)
+	next := sentence.End
+	for {
+		runes := []rune(d.lines[next.Line].text)
+		for next.Col < len(runes) && unicode.IsSpace(runes[next.Col]) {
+			next.Col++
+		}
+		if next.Col < len(runes) {
+			return next
+		}
+		if next.Line == last {
+			return d.MoveCursorParagraphDown(pos)
+		}
+		next = BufferPos{Line: next.Line + 1, Col: 0}
+	}
+}
+
+// MoveCursorSentenceLeft moves cursor to the start of the current sentence,
+// or of the previous one (possibly in an earlier paragraph) when already
+// there.
+func (d *Document) MoveCursorSentenceLeft(pos BufferPos) BufferPos {
+	pos = d.ValidatePosition(pos)
+	
+	if sentence, ok := d.SentenceAt(pos); ok && sentence.Start.Before(pos) {
+		return sentence.Start
+	}
+	
+	// Step back over whitespace and blank lines into the previous sentence
+	prev := pos
+	for {
+		if prev.Line == 0 && prev.Col == 0 {
+			return prev
+		}
+		prev = d.MoveCursorLeft(prev)
+		if ch := d.GetCharAt(prev); ch != 0 && !unicode.IsSpace(ch) {
+			break
+		}
+	}
+	sentence, _ := d.SentenceAt(prev)
+	return sentence.Start
+}
+
 // GetSelectionText returns the text content of a selection.
 func (d *Document) GetSelectionText(selection *Selection) string {
 	if selection == nil {
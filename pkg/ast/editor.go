@@ -1,9 +1,10 @@
 package ast
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"os"
-	"strings"
 	"unicode/utf8"
 )
 
@@ -15,6 +16,8 @@ type Editor struct {
 	clipboard     string
 	lineNumbers   bool
 	viewport      *Viewport
+	typewriter    bool // Keep the cursor line vertically centered
+	overwrite     bool // Typed text replaces the characters after the cursor
 }
 
 // GetViewport returns the current viewport
@@ -83,10 +86,58 @@ func (e *Editor) SetViewPort(width, height int) {
 	e.viewport = newViewport
 	e.cursorManager.UpdateViewport(newViewport)
 	
+	// A wider screen may show the ends of the visible lines without
+	// scrolling as far right
+	if e.viewport.GetLeftColumn() > e.maxLeftColumn() {
+		e.scrollTo(e.viewport.GetTopLine(), e.maxLeftColumn())
+	}
+	
 	// Ensure cursor is still visible after resize
 	e.AdjustViewPort()
 }
 
+// SetTabWidth sets how many columns a tab stop spans. Widths below 1 are
+// ignored.
+func (e *Editor) SetTabWidth(width int) {
+	if width < 1 || width == e.viewport.GetTabWidth() {
+		return
+	}
+	newViewport := NewViewport(
+		e.viewport.GetTopLine(),
+		e.viewport.GetLeftColumn(),
+		e.viewport.GetWidth(),
+		e.viewport.GetHeight(),
+		e.viewport.GetLineNumberWidth(),
+		width,
+	)
+	e.viewport = newViewport
+	e.cursorManager.UpdateViewport(newViewport)
+	e.AdjustViewPort()
+}
+
+// SetTypewriterMode turns typewriter scrolling on or off. In typewriter
+// mode the viewport scrolls so the cursor line stays vertically centered.
+func (e *Editor) SetTypewriterMode(enabled bool) {
+	e.typewriter = enabled
+	e.AdjustViewPort()
+}
+
+// SetOverwriteMode switches between inserting typed text and overwriting
+// the characters after the cursor.
+func (e *Editor) SetOverwriteMode(enabled bool) {
+	e.overwrite = enabled
+}
+
+// OverwriteMode reports whether typed text overwrites
+func (e *Editor) OverwriteMode() bool {
+	return e.overwrite
+}
+
+// TypewriterMode reports whether typewriter scrolling is on
+func (e *Editor) TypewriterMode() bool {
+	return e.typewriter
+}
+
 // ToggleLineNumbers toggles line number display
 func (e *Editor) ToggleLineNumbers() {
 	e.lineNumbers = !e.lineNumbers
@@ -163,16 +214,11 @@ func (e *Editor) FormatLineNumber(lineNum int) string {
 
 // LoadFile loads a file into the editor
 func (e *Editor) LoadFile(filename string) error {
-	var content []byte
-	
-	if _, err := os.Stat(filename); os.IsNotExist(err) {
+	content, err := ReadFile(context.Background(), filename)
+	if errors.Is(err, os.ErrNotExist) {
 		content = []byte{}
-	} else {
-		var err error
-		content, err = os.ReadFile(filename)
-		if err != nil {
-			return fmt.Errorf("failed to read file %s: %w", filename, err)
-		}
+	} else if err != nil {
+		return fmt.Errorf("failed to read file %s: %w", filename, err)
 	}
 	
 	e.document = NewDocument(string(content))
@@ -188,6 +234,12 @@ func (e *Editor) LoadFile(filename string) error {
 
 // SaveFile saves the document to a file
 func (e *Editor) SaveFile(filename string) error {
+	return e.SaveFileEncoded(filename, nil)
+}
+
+// SaveFileEncoded saves like SaveFile, passing the text through encode
+// first when it is not nil; only what encode returns reaches the disk.
+func (e *Editor) SaveFileEncoded(filename string, encode func([]byte) ([]byte, error)) error {
 	if filename == "" {
 		filename = e.document.GetFilename()
 	}
@@ -196,10 +248,8 @@ func (e *Editor) SaveFile(filename string) error {
 		return fmt.Errorf("no filename specified")
 	}
 	
-	content := e.document.GetText()
-	err := os.WriteFile(filename, []byte(content), 0644)
-	if err != nil {
-		return fmt.Errorf("failed to write file %s: %w", filename, err)
+	if err := WriteFileEncoded(filename, []byte(e.document.GetText()), encode); err != nil {
+		return err
 	}
 	
 	e.document.SetFilename(filename)
@@ -208,11 +258,49 @@ func (e *Editor) SaveFile(filename string) error {
 	return nil
 }
 
-// InsertText inserts text at the current cursor position
+// WriteFileEncoded writes content to filename, passing it through encode
+// first when it is not nil. Unlike SaveFileEncoded it touches no document,
+// so it may run off the goroutine editing one, on text taken beforehand.
+func WriteFileEncoded(filename string, content []byte, encode func([]byte) ([]byte, error)) error {
+	if encode != nil {
+		var err error
+		if content, err = encode(content); err != nil {
+			return fmt.Errorf("failed to encode %s: %w", filename, err)
+		}
+	}
+	if err := WriteFile(context.Background(), filename, content); err != nil {
+		return fmt.Errorf("failed to write file %s: %w", filename, err)
+	}
+	return nil
+}
+
+// TypeText enters text typed by the user. It is inserted like InsertText,
+// except in overwrite mode where it replaces as many characters after the
+// cursor, never joining lines.
+func (e *Editor) TypeText(text string) {
+	if e.overwrite && !e.deleteSelection() {
+		pos := e.cursorManager.GetBufferPos()
+		end := pos
+		for _, ch := range text {
+			if ch == '\n' {
+				break
+			}
+			if end.Col < e.document.GetLineLength(end.Line) {
+				end.Col++
+			}
+		}
+		e.document.DeleteRange(pos, end)
+	}
+	e.InsertText(text)
+}
+
+// InsertText inserts text at the current cursor position, replacing the
+// selection if there is one
 func (e *Editor) InsertText(text string) {
 	if text == "" {
 		return
 	}
+	e.deleteSelection()
 	
 	pos := e.cursorManager.GetBufferPos()
 	
@@ -226,14 +314,15 @@ func (e *Editor) InsertText(text string) {
 		}
 	}
 	
-	// Update cursor position
+	// Update cursor position and keep it in view
 	e.cursorManager.SetBufferPos(newPos)
-	
+	e.AdjustViewPort()
 }
 
-// DeleteText deletes text at the current cursor position
+// DeleteText deletes count characters before the cursor, or just the
+// selection if there is one
 func (e *Editor) DeleteText(count int) {
-	if count <= 0 {
+	if count <= 0 || e.deleteSelection() {
 		return
 	}
 	
@@ -250,9 +339,25 @@ func (e *Editor) DeleteText(count int) {
 		}
 	}
 	
-	// Update cursor position
+	// Update cursor position and keep it in view
 	e.cursorManager.SetBufferPos(deletePos)
+	e.AdjustViewPort()
+}
+
+// DeleteTextForward deletes count characters after the cursor, or just
+// the selection if there is one
+func (e *Editor) DeleteTextForward(count int) {
+	if count <= 0 || e.deleteSelection() {
+		return
+	}
 	
+	pos := e.cursorManager.GetBufferPos()
+	end := pos
+	for i := 0; i < count; i++ {
+		end = e.document.MoveCursorRight(end)
+	}
+	e.document.DeleteRange(pos, end)
+	e.AdjustViewPort()
 }
 
 // Copy copies the selected text to clipboard
@@ -270,6 +375,11 @@ func (e *Editor) Cut() {
 	}
 }
 
+// Clipboard returns the text Paste would insert
+func (e *Editor) Clipboard() string {
+	return e.clipboard
+}
+
 // Paste pastes text from clipboard
 func (e *Editor) Paste() {
 	if e.clipboard != "" {
@@ -279,43 +389,29 @@ func (e *Editor) Paste() {
 
 // DeleteSelection deletes the selected text
 func (e *Editor) DeleteSelection() {
-	if !e.cursorManager.HasSelection() {
-		return
-	}
-	
+	e.deleteSelection()
+}
+
+// deleteSelection removes the selected text, leaving the cursor where it
+// began. Reports false when nothing was selected; an empty selection is
+// simply cleared.
+func (e *Editor) deleteSelection() bool {
 	selection := e.cursorManager.GetSelection()
-	start := selection.Start
-	end := selection.End
-	
-	// Ensure start is before end
-	if start.Line > end.Line || (start.Line == end.Line && start.Col > end.Col) {
-		start, end = end, start
+	if selection == nil {
+		return false
 	}
-	
-	// Get selected text
-	selectedText := e.GetSelectionText()
-	
-	// Delete the selected text
-	// This is a simplified implementation - in practice you'd want to
-	// delete the entire selection range more efficiently
-	e.cursorManager.SetBufferPos(start)
 	e.cursorManager.ClearSelection()
-	
-	for range selectedText {
-		if e.cursorManager.GetBufferPos().Col > 0 || e.cursorManager.GetBufferPos().Line > 0 {
-			pos := e.cursorManager.GetBufferPos()
-			if pos.Col > 0 {
-				pos = e.document.DeleteChar(pos)
-			} else if pos.Line > 0 {
-				pos = e.document.DeleteLine(pos)
-			}
-			e.cursorManager.SetBufferPos(pos)
-		}
+	if selection.Start == selection.End {
+		return false
 	}
 	
+	pos := e.document.DeleteRange(selection.Start, selection.End)
+	e.cursorManager.SetBufferPos(pos)
+	e.cursorManager.SetDesiredColumn(pos.Col)
+	e.AdjustViewPort()
+	return true
 }
 
-
 // GetVisibleLines returns the lines that should be visible in the viewport
 func (e *Editor) GetVisibleLines() []string {
 	lines := make([]string, 0, e.viewport.GetHeight())
@@ -348,7 +444,14 @@ func (e *Editor) AdjustViewPort() {
 	newLeftColumn := e.viewport.GetLeftColumn()
 	
 	// Adjust vertical position
-	if pos.Line < newTopLine {
+	if e.typewriter {
+		// Center the cursor line; the top of the document cannot scroll
+		// further down than line 0
+		newTopLine = pos.Line - e.viewport.GetHeight()/2
+		if newTopLine < 0 {
+			newTopLine = 0
+		}
+	} else if pos.Line < newTopLine {
 		newTopLine = pos.Line
 	} else if pos.Line >= newTopLine+e.viewport.GetHeight() {
 		newTopLine = pos.Line - e.viewport.GetHeight() + 1
@@ -357,11 +460,12 @@ func (e *Editor) AdjustViewPort() {
 		}
 	}
 	
-	// Adjust horizontal position
-	if pos.Col < newLeftColumn {
-		newLeftColumn = pos.Col
-	} else if pos.Col >= newLeftColumn+e.viewport.GetWidth()-e.viewport.GetLineNumberWidth() {
-		newLeftColumn = pos.Col - e.viewport.GetWidth() + e.viewport.GetLineNumberWidth() + 1
+	// Adjust horizontal position, in display columns
+	col := e.viewport.DisplayColumn(e.document.GetLine(pos.Line), pos.Col)
+	if col < newLeftColumn {
+		newLeftColumn = col
+	} else if col >= newLeftColumn+e.viewport.GetWidth()-e.viewport.GetLineNumberWidth() {
+		newLeftColumn = col - e.viewport.GetWidth() + e.viewport.GetLineNumberWidth() + 1
 		if newLeftColumn < 0 {
 			newLeftColumn = 0
 		}
@@ -386,64 +490,100 @@ func (e *Editor) AdjustViewPort() {
 // ScrollViewportUp scrolls the viewport up by the specified number of lines
 // without moving the cursor position. The cursor remains at the same buffer position.
 func (e *Editor) ScrollViewportUp(lines int) {
-	newTopLine := e.viewport.GetTopLine() - lines
-	if newTopLine < 0 {
-		newTopLine = 0
-	}
-	
-	if newTopLine != e.viewport.GetTopLine() {
-		newViewport := e.viewport.WithTopLine(newTopLine)
-		e.viewport = newViewport
-		e.cursorManager.UpdateViewport(newViewport)
-	}
+	e.scrollTo(e.viewport.GetTopLine()-lines, e.viewport.GetLeftColumn())
 }
 
 // ScrollViewportDown scrolls the viewport down by the specified number of lines
 // without moving the cursor position. The cursor remains at the same buffer position.
 func (e *Editor) ScrollViewportDown(lines int) {
-	maxTopLine := e.document.LineCount() - 1
-	newTopLine := e.viewport.GetTopLine() + lines
-	
-	// Don't scroll past the end of the document
-	if newTopLine > maxTopLine {
-		newTopLine = maxTopLine
-	}
-	
-	if newTopLine != e.viewport.GetTopLine() {
-		newViewport := e.viewport.WithTopLine(newTopLine)
-		e.viewport = newViewport
-		e.cursorManager.UpdateViewport(newViewport)
-	}
+	e.scrollTo(e.viewport.GetTopLine()+lines, e.viewport.GetLeftColumn())
 }
 
 // ScrollViewportLeft scrolls the viewport left by the specified number of columns
 // without moving the cursor position. The cursor remains at the same buffer position.
 func (e *Editor) ScrollViewportLeft(cols int) {
-	newLeftColumn := e.viewport.GetLeftColumn() - cols
-	if newLeftColumn < 0 {
-		newLeftColumn = 0
-	}
-	
-	if newLeftColumn != e.viewport.GetLeftColumn() {
-		newViewport := e.viewport.WithLeftColumn(newLeftColumn)
-		e.viewport = newViewport
-		e.cursorManager.UpdateViewport(newViewport)
-	}
+	e.scrollTo(e.viewport.GetTopLine(), e.viewport.GetLeftColumn()-cols)
 }
 
 // ScrollViewportRight scrolls the viewport right by the specified number of columns
-// without moving the cursor position. The cursor remains at the same buffer position.
+// without moving the cursor position. Scrolling stops once the end of the
+// longest visible line is in view.
 func (e *Editor) ScrollViewportRight(cols int) {
-	newLeftColumn := e.viewport.GetLeftColumn() + cols
+	left := e.viewport.GetLeftColumn()
+	if left+cols > e.maxLeftColumn() {
+		// Never scroll back left because of the clamp
+		cols = max(e.maxLeftColumn()-left, 0)
+	}
+	e.scrollTo(e.viewport.GetTopLine(), left+cols)
+}
+
+// ScrollHalfPageUp scrolls the viewport up by half its height.
+func (e *Editor) ScrollHalfPageUp() {
+	e.ScrollViewportUp(max(e.viewport.GetHeight()/2, 1))
+}
+
+// ScrollHalfPageDown scrolls the viewport down by half its height.
+func (e *Editor) ScrollHalfPageDown() {
+	e.ScrollViewportDown(max(e.viewport.GetHeight()/2, 1))
+}
+
+// ScrollPageUp scrolls the viewport up by a page, keeping one line of the
+// previous page in view for context.
+func (e *Editor) ScrollPageUp() {
+	e.ScrollViewportUp(max(e.viewport.GetHeight()-1, 1))
+}
+
+// ScrollPageDown scrolls the viewport down by a page, keeping one line of
+// the previous page in view for context.
+func (e *Editor) ScrollPageDown() {
+	e.ScrollViewportDown(max(e.viewport.GetHeight()-1, 1))
+}
+
+// ScrollToTop scrolls the viewport to the first line of the document.
+func (e *Editor) ScrollToTop() {
+	e.scrollTo(0, e.viewport.GetLeftColumn())
+}
+
+// ScrollToBottom scrolls the viewport so the last line of the document is
+// at the bottom of the screen.
+func (e *Editor) ScrollToBottom() {
+	e.scrollTo(e.document.LineCount()-e.viewport.GetHeight(), e.viewport.GetLeftColumn())
+}
+
+// scrollTo moves the viewport without moving the cursor. The top line is
+// clamped to the document, allowing the last line to scroll up to the top,
+// and the left column is kept non-negative.
+func (e *Editor) scrollTo(topLine, leftColumn int) {
+	topLine = max(0, min(topLine, e.document.LineCount()-1))
+	leftColumn = max(leftColumn, 0)
 	
-	// We don't limit horizontal scrolling as lines can be arbitrarily long
-	if newLeftColumn != e.viewport.GetLeftColumn() {
-		newViewport := e.viewport.WithLeftColumn(newLeftColumn)
+	if topLine != e.viewport.GetTopLine() || leftColumn != e.viewport.GetLeftColumn() {
+		newViewport := e.viewport.WithTopLine(topLine).WithLeftColumn(leftColumn)
 		e.viewport = newViewport
 		e.cursorManager.UpdateViewport(newViewport)
 	}
 }
 
+// LongestVisibleLine returns the display width of the longest line in the
+// viewport.
+func (e *Editor) LongestVisibleLine() int {
+	longest := 0
+	bottom := min(e.viewport.GetTopLine()+e.viewport.GetHeight(), e.document.LineCount())
+	for i := e.viewport.GetTopLine(); i < bottom; i++ {
+		line := e.document.GetLine(i)
+		longest = max(longest, e.viewport.DisplayColumn(line, utf8.RuneCountInString(line)))
+	}
+	return longest
+}
+
+// maxLeftColumn is the furthest right the viewport can scroll: the column
+// that brings the end of the longest visible line, plus room for the
+// cursor after it, to the right edge.
+func (e *Editor) maxLeftColumn() int {
+	textWidth := e.viewport.GetWidth() - e.viewport.GetLineNumberWidth()
+	return max(e.LongestVisibleLine()-textWidth+1, 0)
+}
+
 // GetCursorBufferPosition returns the cursor position in buffer coordinates
 func (e *Editor) GetCursorBufferPosition() BufferPos {
 	return e.cursorManager.GetBufferPos()
@@ -559,6 +699,33 @@ func (e *Editor) MoveCursorWordRight() {
 	e.AdjustViewPort()
 }
 
+// MoveCursorParagraphUp moves cursor to the start of the paragraph.
+func (e *Editor) MoveCursorParagraphUp() {
+	e.moveCursorTo(e.document.MoveCursorParagraphUp(e.cursorManager.GetBufferPos()))
+}
+
+// MoveCursorParagraphDown moves cursor to the start of the next paragraph.
+func (e *Editor) MoveCursorParagraphDown() {
+	e.moveCursorTo(e.document.MoveCursorParagraphDown(e.cursorManager.GetBufferPos()))
+}
+
+// MoveCursorSentenceLeft moves cursor to the start of the sentence.
+func (e *Editor) MoveCursorSentenceLeft() {
+	e.moveCursorTo(e.document.MoveCursorSentenceLeft(e.cursorManager.GetBufferPos()))
+}
+
+// MoveCursorSentenceRight moves cursor to the start of the next sentence.
+func (e *Editor) MoveCursorSentenceRight() {
+	e.moveCursorTo(e.document.MoveCursorSentenceRight(e.cursorManager.GetBufferPos()))
+}
+
+// moveCursorTo places the cursor after a horizontal-style motion.
+func (e *Editor) moveCursorTo(pos BufferPos) {
+	e.cursorManager.SetBufferPos(pos)
+	e.cursorManager.SetDesiredColumn(pos.Col)
+	e.AdjustViewPort()
+}
+
 // GetSelectionText returns the text content of the current selection.
 // This method properly implements the document-centric architecture where
 // the Editor orchestrates between Document (content) and CursorManager (selection state).
@@ -569,77 +736,68 @@ func (e *Editor) GetSelectionText() string {
 
 // FindText searches for text in the document starting from current cursor position
 func (e *Editor) FindText(searchText string, caseSensitive bool) *BufferPos {
-	if searchText == "" {
+	return e.findText(searchText, SearchOptions{CaseSensitive: caseSensitive})
+}
+
+// FindTextBackward searches for text before the cursor, wrapping around
+// to the end of the document
+func (e *Editor) FindTextBackward(searchText string, caseSensitive bool) *BufferPos {
+	return e.findText(searchText, SearchOptions{CaseSensitive: caseSensitive, Backward: true})
+}
+
+func (e *Editor) findText(searchText string, opts SearchOptions) *BufferPos {
+	r, ok := e.document.Find(searchText, e.cursorManager.GetBufferPos(), opts)
+	if !ok {
 		return nil
 	}
-	
-	pos := e.cursorManager.GetBufferPos()
-	text := e.document.GetText()
-	
-	if !caseSensitive {
-		searchText = strings.ToLower(searchText)
-		text = strings.ToLower(text)
-	}
-	
-	// Convert position to text offset
-	lines := strings.Split(text, "\n")
-	offset := 0
-	for i := 0; i < pos.Line && i < len(lines); i++ {
-		offset += len(lines[i]) + 1 // +1 for newline
-	}
-	offset += pos.Col
-	
-	// Search from current position
-	index := strings.Index(text[offset:], searchText)
-	if index == -1 {
-		// Wrap around search
-		index = strings.Index(text, searchText)
-		if index == -1 {
-			return nil
-		}
-	} else {
-		index += offset
-	}
-	
-	// Convert back to position
-	return e.offsetToPosition(index)
+	return &r.Start
 }
 
 // ReplaceText replaces text at the current cursor position
 func (e *Editor) ReplaceText(oldText, newText string, caseSensitive bool) bool {
-	if oldText == "" {
+	r, ok := e.document.MatchAt(oldText, e.cursorManager.GetBufferPos(), caseSensitive)
+	if !ok {
 		return false
 	}
-	
-	pos := e.cursorManager.GetBufferPos()
-	text := e.document.GetText()
-	
-	searchText := oldText
-	if !caseSensitive {
-		searchText = strings.ToLower(oldText)
-		text = strings.ToLower(text)
+	e.ReplaceRange(r, newText)
+	return true
+}
+
+// StripTrailingWhitespace removes trailing whitespace from every line, as
+// Document.StripTrailingWhitespace does, keeping the cursor and any
+// selection within the shortened lines. Returns the number of lines changed.
+func (e *Editor) StripTrailingWhitespace(keepHardBreaks bool) int {
+	changed := e.document.StripTrailingWhitespace(keepHardBreaks)
+	if changed == 0 {
+		return 0
 	}
 	
-	// Convert position to text offset
-	lines := strings.Split(text, "\n")
-	offset := 0
-	for i := 0; i < pos.Line && i < len(lines); i++ {
-		offset += len(lines[i]) + 1 // +1 for newline
+	e.validateCursor()
+	return changed
+}
+
+// SetFinalNewline adds or removes the line break ending the text, as
+// Document.SetFinalNewline does, keeping the cursor and any selection
+// within the text. Reports whether the text changed.
+func (e *Editor) SetFinalNewline(final bool) bool {
+	if !e.document.SetFinalNewline(final) {
+		return false
 	}
-	offset += pos.Col
-	
-	// Check if text at cursor matches
-	if offset+len(searchText) <= len(text) && text[offset:offset+len(searchText)] == searchText {
-		// Delete old text
-		for i := 0; i < len(oldText); i++ {
-			e.DeleteText(1)
-		}
-		// Insert new text
-		e.InsertText(newText)
-		return true
+	e.validateCursor()
+	return true
+}
+
+// validateCursor moves the cursor and any selection back within the text
+// after lines were shortened or removed.
+func (e *Editor) validateCursor() {
+	if selection := e.cursorManager.GetSelection(); selection != nil {
+		e.cursorManager.SetSelection(&Selection{
+			Start: e.document.ValidatePosition(selection.Start),
+			End:   e.document.ValidatePosition(selection.End),
+		})
 	}
-	
-	return false
+	pos := e.document.ValidatePosition(e.cursorManager.GetBufferPos())
+	e.cursorManager.SetBufferPosWithDesiredColumn(pos, true)
 }
 
 // GotoLine moves cursor to specified line
@@ -654,26 +812,3 @@ func (e *Editor) GotoLine(lineNum int) {
 	newPos := BufferPos{Line: lineNum - 1, Col: 0}
 	e.cursorManager.SetBufferPos(newPos)
 }
-
-// offsetToPosition converts text offset to BufferPos
-func (e *Editor) offsetToPosition(offset int) *BufferPos {
-	text := e.document.GetText()
-	lines := strings.Split(text, "\n")
-	
-	currentOffset := 0
-	for lineNum, line := range lines {
-		if currentOffset+len(line) >= offset {
-			return &BufferPos{
-				Line: lineNum,
-				Col:  offset - currentOffset,
-			}
-		}
-		currentOffset += len(line) + 1 // +1 for newline
-	}
-	
-	// If we get here, offset is at end of document
-	return &BufferPos{
-		Line: len(lines) - 1,
-		Col:  len(lines[len(lines)-1]),
-	}
-}
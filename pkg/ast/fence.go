@@ -0,0 +1,144 @@
+package ast
+
+import (
+	"slices"
+	"strings"
+)
+
+// FencedBlock describes a fenced code block (``` or ~~~) in the document.
+// Line numbers are 0-indexed and inclusive of both fence lines.
+type FencedBlock struct {
+	StartLine int    // Line of the opening fence
+	EndLine   int    // Line of the closing fence (last line if unclosed)
+	Info      string // Info string after the opening fence, e.g. "go" or "mermaid"
+	Closed    bool   // Whether a closing fence was found
+}
+
+// Language returns the first word of the info string, lowercased.
+func (b FencedBlock) Language() string {
+	fields := strings.Fields(b.Info)
+	if len(fields) == 0 {
+		return ""
+	}
+	return strings.ToLower(fields[0])
+}
+
+// Contains reports whether line falls within the block, fences included.
+func (b FencedBlock) Contains(line int) bool {
+	return line >= b.StartLine && line <= b.EndLine
+}
+
+// FencedBlocks lists the document's fenced code blocks, found again only
+// after an edit.
+// Follows CommonMark: a fence is 3+ backticks or tildes indented at most
+// 3 spaces, closed by a fence of the same character at least as long.
+func (d *Document) FencedBlocks() []FencedBlock {
+	return slices.Clone(d.fencedBlocks())
+}
+
+// fenceCache holds the fenced blocks of the document as of an edit
+// revision.
+type fenceCache struct {
+	revision int
+	valid    bool
+	blocks   []FencedBlock
+}
+
+// fencedBlocks returns the cached fenced blocks; callers must not modify
+// the result.
+func (d *Document) fencedBlocks() []FencedBlock {
+	if d.fences.valid && d.fences.revision == d.revision {
+		return d.fences.blocks
+	}
+
+	var blocks []FencedBlock
+	var open *FencedBlock
+	var openChar byte
+	var openLen int
+
+	for i, line := range d.lines {
+		char, length, info, ok := parseFence(line.text)
+		if !ok {
+			continue
+		}
+
+		if open == nil {
+			// Backtick fences may not contain backticks in the info string
+			if char == '`' && strings.Contains(info, "`") {
+				continue
+			}
+			open = &FencedBlock{StartLine: i, Info: info}
+			openChar, openLen = char, length
+			continue
+		}
+
+		if char == openChar && length >= openLen && info == "" {
+			open.EndLine = i
+			open.Closed = true
+			blocks = append(blocks, *open)
+			open = nil
+		}
+	}
+
+	if open != nil {
+		open.EndLine = len(d.lines) - 1
+		blocks = append(blocks, *open)
+	}
+
+	d.fences = fenceCache{revision: d.revision, valid: true, blocks: blocks}
+	return blocks
+}
+
+// FencedBlockAt returns the fenced block containing line, if any.
+func (d *Document) FencedBlockAt(line int) (FencedBlock, bool) {
+	for _, block := range d.fencedBlocks() {
+		if block.Contains(line) {
+			return block, true
+		}
+		if block.StartLine > line {
+			break
+		}
+	}
+	return FencedBlock{}, false
+}
+
+// FencedBlockSource returns the text between the fences of block.
+func (d *Document) FencedBlockSource(block FencedBlock) string {
+	end := block.EndLine
+	if !block.Closed {
+		end++
+	}
+
+	var lines []string
+	for i := block.StartLine + 1; i < end && i < len(d.lines); i++ {
+		lines = append(lines, d.lines[i].text)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// parseFence recognizes a fence line, returning its character, run length
+// and trimmed info string.
+func parseFence(line string) (byte, int, string, bool) {
+	indent := 0
+	for indent < len(line) && line[indent] == ' ' {
+		indent++
+	}
+	if indent > 3 || indent >= len(line) {
+		return 0, 0, "", false
+	}
+
+	char := line[indent]
+	if char != '`' && char != '~' {
+		return 0, 0, "", false
+	}
+
+	length := 0
+	for indent+length < len(line) && line[indent+length] == char {
+		length++
+	}
+	if length < 3 {
+		return 0, 0, "", false
+	}
+
+	return char, length, strings.TrimSpace(line[indent+length:]), true
+}
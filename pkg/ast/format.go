@@ -0,0 +1,213 @@
+package ast
+
+import "strings"
+
+// Inline markers accepted by ToggleFormat.
+const (
+	BoldMarker   = "**"
+	ItalicMarker = "*"
+	CodeMarker   = "`"
+)
+
+// formatTarget returns the range an inline format applies to: the
+// selection, or the word under the cursor. selected reports which.
+func (e *Editor) formatTarget() (r Range, selected, ok bool) {
+	if selection := e.cursorManager.GetSelection(); selection != nil && selection.Start != selection.End {
+		start, end := selection.Start, selection.End
+		if end.Before(start) {
+			start, end = end, start
+		}
+		return Range{start, end}, true, true
+	}
+	r, ok = e.document.WordAt(e.cursorManager.GetBufferPos())
+	return r, false, ok
+}
+
+// ToggleFormat applies or removes an inline format (BoldMarker,
+// ItalicMarker or CodeMarker) on the selection, or on the word under the
+// cursor. Markers are recognized just outside the range or, for a
+// selection, as its first and last characters; "*" and "_" are treated
+// alike, so toggling bold on ***text*** leaves *text*. Without a word the
+// markers are inserted in a pair with the cursor between them. The inner
+// text stays selected afterwards.
+func (e *Editor) ToggleFormat(marker string) {
+	r, selected, ok := e.formatTarget()
+	if !ok {
+		pos := e.cursorManager.GetBufferPos()
+		e.InsertText(marker + marker)
+		e.cursorManager.SetBufferPos(BufferPos{Line: pos.Line, Col: pos.Col + len(marker)})
+		e.cursorManager.SetDesiredColumn(pos.Col + len(marker))
+		return
+	}
+	first := []rune(e.document.GetLine(r.Start.Line))
+	last := []rune(e.document.GetLine(r.End.Line))
+	sameLine := r.Start.Line == r.End.Line
+	start, end := r.Start.Col, r.End.Col
+
+	outer := formatMarkers(marker, first[:start], last[end:])
+	inner := 0
+	if selected {
+		// Swapped, so the runs matched are the selection's last and first
+		// characters rather than those around it
+		inner = formatMarkers(marker, last[:end], first[start:])
+		if sameLine && 2*inner >= end-start {
+			inner = 0 // The markers would be all there is
+		}
+	}
+
+	switch {
+	case outer > 0:
+		e.document.DeleteRange(r.End, BufferPos{r.End.Line, end + outer})
+		e.document.DeleteRange(BufferPos{r.Start.Line, start - outer}, r.Start)
+		start -= outer
+		if sameLine {
+			end -= outer
+		}
+	case inner > 0:
+		e.document.DeleteRange(BufferPos{r.End.Line, end - inner}, r.End)
+		e.document.DeleteRange(r.Start, BufferPos{r.Start.Line, start + inner})
+		end -= inner
+		if sameLine {
+			end -= inner
+		}
+	default:
+		open, close := marker, marker
+		if marker == CodeMarker && strings.Contains(e.document.GetSelectionText(&Selection{r.Start, r.End}), "`") {
+			open, close = "`` ", " ``"
+		}
+		e.insertString(r.End, close)
+		e.insertString(r.Start, open)
+		start += len(open)
+		if sameLine {
+			end += len(open)
+		}
+	}
+
+	newStart, newEnd := BufferPos{r.Start.Line, start}, BufferPos{r.End.Line, end}
+	if selected {
+		e.cursorManager.SetSelection(&Selection{Start: newStart, End: newEnd})
+		e.cursorManager.SetBufferPos(newEnd)
+	} else {
+		cursor := e.cursorManager.GetBufferPos()
+		e.cursorManager.SetBufferPos(BufferPos{cursor.Line, newStart.Col + cursor.Col - r.Start.Col})
+	}
+	e.cursorManager.SetDesiredColumn(e.cursorManager.GetBufferPos().Col)
+	e.AdjustViewPort()
+}
+
+// formatMarkers reports how many characters to remove from each side to
+// undo marker, given the text before and after the formatted range, or 0
+// when the range is not formatted that way.
+func formatMarkers(marker string, before, after []rune) int {
+	if marker == CodeMarker {
+		open, close := markerRun(before, '`', true), markerRun(after, '`', false)
+		if open == 0 || open != close {
+			return 0
+		}
+		return open
+	}
+	for _, char := range []rune{'*', '_'} {
+		open, close := markerRun(before, char, true), markerRun(after, char, false)
+		run := min(open, close)
+		switch {
+		case marker == BoldMarker && run >= 2,
+			marker == ItalicMarker && (run == 1 || run == 3):
+			return len(marker)
+		}
+	}
+	return 0
+}
+
+// markerRun counts the run of char at the end of text (or its start, when
+// atEnd is false).
+func markerRun(text []rune, char rune, atEnd bool) int {
+	n := 0
+	for n < len(text) {
+		i := n
+		if atEnd {
+			i = len(text) - 1 - n
+		}
+		if text[i] != char {
+			break
+		}
+		n++
+	}
+	return n
+}
+
+// insertString inserts text at pos without touching the cursor
+func (e *Editor) insertString(pos BufferPos, text string) {
+	for _, ch := range text {
+		pos = e.document.InsertChar(pos, ch)
+	}
+}
+
+// ReplaceRange replaces the text in r with text, leaving the cursor after
+// it and clearing the selection.
+func (e *Editor) ReplaceRange(r Range, text string) {
+	e.cursorManager.ClearSelection()
+	pos := e.document.DeleteRange(r.Start, r.End)
+	e.cursorManager.SetBufferPos(pos)
+	e.InsertText(text)
+}
+
+// WrapReferenceLink turns the selection, or the word under the cursor,
+// into a reference link, [text][label], defining label as url on a line
+// of its own at the end of the document. With an empty url the cursor is
+// left at the end of the definition to type one; otherwise it goes after
+// the link. Reports false when there is nothing to link.
+func (e *Editor) WrapReferenceLink(label, url string) bool {
+	r, _, ok := e.formatTarget()
+	if !ok {
+		return false
+	}
+	text := e.document.GetSelectionText(&Selection{r.Start, r.End})
+	e.ReplaceRange(r, "["+text+"]["+label+"]")
+	after := e.cursorManager.GetBufferPos()
+
+	// Definitions gather after a blank line at the end
+	last := e.document.LineCount() - 1
+	lastLine := e.document.GetLine(last)
+	separator := "\n"
+	switch {
+	case strings.TrimSpace(lastLine) == "":
+		separator = ""
+	case !isReferenceDefinition(lastLine):
+		separator = "\n\n"
+	}
+	end := BufferPos{last, e.document.GetLineLength(last)}
+	e.ReplaceRange(Range{end, end}, separator+"["+label+"]: "+url)
+	if url == "" {
+		after = e.cursorManager.GetBufferPos()
+	}
+	e.cursorManager.SetBufferPos(after)
+	e.cursorManager.SetDesiredColumn(after.Col)
+	e.AdjustViewPort()
+	return true
+}
+
+// isReferenceDefinition reports whether line defines a link reference, as
+// in "[label]: url".
+func isReferenceDefinition(line string) bool {
+	label, _, ok := strings.Cut(strings.TrimSpace(line), "]:")
+	return ok && strings.HasPrefix(label, "[") && !strings.HasPrefix(label, "[^")
+}
+
+// WrapLink turns the selection, or the word under the cursor, into an
+// inline link to url. With an empty url the cursor is left between the
+// parentheses to type one; otherwise it goes after the link. Reports false
+// when there is nothing to link.
+func (e *Editor) WrapLink(url string) bool {
+	r, _, ok := e.formatTarget()
+	if !ok {
+		return false
+	}
+	text := e.document.GetSelectionText(&Selection{r.Start, r.End})
+	e.ReplaceRange(r, "["+text+"]("+url+")")
+	if url == "" {
+		pos := e.cursorManager.GetBufferPos()
+		e.cursorManager.SetBufferPos(BufferPos{pos.Line, pos.Col - 1})
+		e.cursorManager.SetDesiredColumn(pos.Col - 1)
+	}
+	return true
+}
@@ -0,0 +1,258 @@
+package ast
+
+import (
+	"slices"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// maxHeadingLevel is the deepest heading, "######"
+const maxHeadingLevel = 6
+
+// ShiftHeading changes the level of the heading on the cursor line by
+// delta: -1 promotes it (one "#" fewer), 1 demotes it. Setext headings
+// keep their underline while they stay at level 1 or 2 and become ATX
+// headings beyond. Returns false, changing nothing, if the line is not a
+// heading or the new level would fall outside 1–6.
+func (e *Editor) ShiftHeading(delta int) bool {
+	line := e.cursorManager.GetBufferPos().Line
+	level := e.document.HeadingLevel(line)
+	if level == 0 || !validHeadingLevel(level+delta) {
+		return false
+	}
+	e.remapPositions(e.document.shiftHeading(line, delta))
+	e.AdjustViewPort()
+	return true
+}
+
+// ShiftSection shifts the heading of the section holding the cursor and
+// every subheading within it by delta, so the outline below keeps its
+// shape. Returns false, changing nothing, if the cursor is above the
+// first heading or any heading would fall outside levels 1–6.
+func (e *Editor) ShiftSection(delta int) bool {
+	section, ok := e.document.SectionAt(e.cursorManager.GetBufferPos().Line)
+	if !ok {
+		return false
+	}
+
+	var headings []int
+	for i := section.Start.Line; i <= section.End.Line; i++ {
+		if level := e.document.HeadingLevel(i); level > 0 {
+			if !validHeadingLevel(level + delta) {
+				return false
+			}
+			headings = append(headings, i)
+		}
+	}
+
+	// Bottom up, so removing a setext underline leaves the headings above
+	// where they were found
+	for i := len(headings) - 1; i >= 0; i-- {
+		e.remapPositions(e.document.shiftHeading(headings[i], delta))
+	}
+	e.AdjustViewPort()
+	return true
+}
+
+// remapPositions moves the cursor and selection through an edit.
+func (e *Editor) remapPositions(remap func(BufferPos) BufferPos) {
+	if selection := e.cursorManager.GetSelection(); selection != nil {
+		e.cursorManager.SetSelection(&Selection{Start: remap(selection.Start), End: remap(selection.End)})
+	}
+	e.cursorManager.SetBufferPos(remap(e.cursorManager.GetBufferPos()))
+}
+
+func validHeadingLevel(level int) bool {
+	return level >= 1 && level <= maxHeadingLevel
+}
+
+// shiftHeading rewrites the heading on line at its level plus delta and
+// returns how positions in the document move with the edit. The new level
+// must be valid.
+func (d *Document) shiftHeading(line, delta int) func(BufferPos) BufferPos {
+	level := d.HeadingLevel(line)
+	text := d.lines[line].text
+
+	if setext, _ := d.SetextHeadingAt(line); setext > 0 {
+		underline := line + 1
+		if level+delta <= 2 {
+			char := "="
+			if level+delta == 2 {
+				char = "-"
+			}
+			marks := strings.TrimSpace(d.lines[underline].text)
+			d.setLine(underline, strings.Repeat(char, len(marks)))
+			return func(pos BufferPos) BufferPos {
+				if pos.Line == underline {
+					pos.Col = min(pos.Col, d.lines[underline].length)
+				}
+				return pos
+			}
+		}
+
+		// Beyond level 2 only ATX headings exist
+		indent := utf8.RuneCountInString(text) - utf8.RuneCountInString(strings.TrimLeft(text, " "))
+		marker := strings.Repeat("#", level+delta) + " "
+		d.setLine(line, marker+strings.TrimSpace(text))
+		d.lines = append(d.lines[:underline], d.lines[underline+1:]...)
+		return func(pos BufferPos) BufferPos {
+			switch {
+			case pos.Line == line:
+				pos.Col = min(max(pos.Col-indent, 0)+len(marker), d.lines[line].length)
+			case pos.Line == underline:
+				pos = BufferPos{Line: line, Col: d.lines[line].length}
+			case pos.Line > underline:
+				pos.Line--
+			}
+			return pos
+		}
+	}
+
+	start := len(text) - len(strings.TrimLeft(text, " "))
+	d.setLine(line, text[:start]+strings.Repeat("#", level+delta)+text[start+level:])
+	return func(pos BufferPos) BufferPos {
+		if pos.Line == line && pos.Col > start {
+			if pos.Col >= start+level {
+				pos.Col += delta
+			} else {
+				pos.Col = min(pos.Col, start+level+delta)
+			}
+		}
+		return pos
+	}
+}
+
+// setLine replaces the text of line.
+func (d *Document) setLine(line int, text string) {
+	d.lines[line].text = text
+	d.lines[line].length = utf8.RuneCountInString(text)
+	d.modified = true
+	d.linesChanged(line)
+}
+
+// MoveSection swaps the section holding the cursor with its previous
+// sibling (direction -1) or next one (1): the neighbouring section at the
+// same level under the same parent heading. Sections move whole, with
+// their subsections, as one change to the document (one revision, not an
+// edit per line); blank lines separating them stay in place. There is no
+// undo: moving the section back the other way restores the text. The
+// cursor moves with its section. Returns false when the cursor is above
+// the first heading or there is no sibling that way.
+func (e *Editor) MoveSection(direction int) bool {
+	pos := e.cursorManager.GetBufferPos()
+	first, level := e.document.headingAbove(pos.Line)
+	if level == 0 {
+		return false
+	}
+
+	end := e.document.sectionEnd(first, level)
+	var remap func(int) int
+	if direction < 0 {
+		// Skip the previous sibling's subsections to reach its heading
+		prev, prevLevel := e.document.headingAbove(first - 1)
+		for prevLevel > level {
+			prev, prevLevel = e.document.headingAbove(prev - 1)
+		}
+		if prevLevel != level {
+			return false
+		}
+		remap = e.document.swapSections(prev, first, end)
+	} else {
+		if end >= len(e.document.lines) || e.document.HeadingLevel(end) != level {
+			return false
+		}
+		remap = e.document.swapSections(first, end, e.document.sectionEnd(end, level))
+	}
+
+	e.cursorManager.ClearSelection()
+	e.cursorManager.SetBufferPos(BufferPos{Line: remap(pos.Line), Col: pos.Col})
+	e.AdjustViewPort()
+	return true
+}
+
+// HeadingAbove returns the line of the nearest heading at or above line:
+// the heading of the section line is in. ok is false above the first
+// heading.
+func (d *Document) HeadingAbove(line int) (int, bool) {
+	headings := d.headings()
+	i := sort.Search(len(headings), func(i int) bool { return headings[i].Line > line }) - 1
+	if i < 0 {
+		return 0, false
+	}
+	return headings[i].Line, true
+}
+
+// HeadingPath returns the headings enclosing line, outermost first: the
+// heading of its section, then that section's parent and so on up to a
+// top-level heading.
+func (d *Document) HeadingPath(line int) []Heading {
+	headings := d.headings()
+	i := sort.Search(len(headings), func(i int) bool { return headings[i].Line > line }) - 1
+	var path []Heading
+	for ; i >= 0 && (len(path) == 0 || path[len(path)-1].Level > 1); i-- {
+		if len(path) == 0 || headings[i].Level < path[len(path)-1].Level {
+			path = append(path, headings[i])
+		}
+	}
+	slices.Reverse(path)
+	return path
+}
+
+// headingAbove returns the nearest heading at or above line and its level,
+// or level 0 when there is none.
+func (d *Document) headingAbove(line int) (int, int) {
+	for ; line >= 0; line-- {
+		if level := d.HeadingLevel(line); level > 0 {
+			return line, level
+		}
+	}
+	return 0, 0
+}
+
+// sectionEnd returns the line after the section whose heading is on
+// first: the next heading of the same or a higher level, or the line
+// count. Unlike SectionAt, trailing blank lines are included.
+func (d *Document) sectionEnd(first, level int) int {
+	for i := first + 1; i < len(d.lines); i++ {
+		if next := d.HeadingLevel(i); next > 0 && next <= level {
+			return i
+		}
+	}
+	return len(d.lines)
+}
+
+// swapSections swaps the adjacent sections [a, b) and [b, end), keeping
+// the blank lines that follow each section's text where they were, and
+// returns where each old line now is.
+func (d *Document) swapSections(a, b, end int) func(int) int {
+	bodyEnd := func(start, stop int) int {
+		for stop > start+1 && isBlankLine(d.lines[stop-1].text) {
+			stop--
+		}
+		return stop
+	}
+	aBody, bBody := bodyEnd(a, b), bodyEnd(b, end)
+	firstBody := bBody - b
+	gap := b - aBody
+
+	swapped := make([]Line, 0, end-a)
+	swapped = append(swapped, d.lines[b:bBody]...)
+	swapped = append(swapped, d.lines[aBody:b]...)
+	swapped = append(swapped, d.lines[a:aBody]...)
+	copy(d.lines[a:bBody], swapped)
+	d.modified = true
+	d.linesChanged(a)
+
+	return func(line int) int {
+		switch {
+		case line >= b && line < bBody:
+			return a + line - b
+		case line >= aBody && line < b:
+			return a + firstBody + line - aBody
+		case line >= a && line < aBody:
+			return a + firstBody + gap + line - a
+		}
+		return line
+	}
+}
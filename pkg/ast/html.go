@@ -0,0 +1,226 @@
+package ast
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// HTMLTag is a raw HTML tag or comment within a line. Start and End are
+// byte offsets; Name is lowercased and empty for comments.
+type HTMLTag struct {
+	Start       int
+	End         int
+	Name        string
+	Closing     bool // </name>
+	SelfClosing bool // <name/>
+	Comment     bool // <!-- ... -->
+}
+
+// HTMLBlock describes a run of raw HTML lines, following CommonMark's
+// HTML block rules. Line numbers are 0-indexed and inclusive.
+type HTMLBlock struct {
+	StartLine int
+	EndLine   int
+	Raw       bool // <script>, <style>, <pre> or <textarea>: contents are verbatim
+}
+
+// Contains reports whether line falls within the block.
+func (b HTMLBlock) Contains(line int) bool {
+	return line >= b.StartLine && line <= b.EndLine
+}
+
+var (
+	htmlTagRe  = regexp.MustCompile(`<!--.*?-->|</([A-Za-z][A-Za-z0-9-]*)\s*>|<([A-Za-z][A-Za-z0-9-]*)(?:\s+[A-Za-z_:][A-Za-z0-9_.:-]*(?:\s*=\s*(?:[^\s"'=<>` + "`" + `]+|'[^']*'|"[^"]*"))?)*\s*(/?)>`)
+	codeSpanRe = regexp.MustCompile("`+[^`]+`+")
+
+	rawBlockStartRe = regexp.MustCompile(`(?i)^<(script|pre|style|textarea)(?:\s|>|$)`)
+	blockTagRe      = regexp.MustCompile(`(?i)^</?([a-z][a-z0-9]*)(?:\s|/?>|$)`)
+)
+
+// htmlBlockTags are the tag names that start a CommonMark type 6 HTML
+// block, which ends at the next blank line.
+var htmlBlockTags = map[string]bool{
+	"address": true, "article": true, "aside": true, "base": true, "basefont": true,
+	"blockquote": true, "body": true, "caption": true, "center": true, "col": true,
+	"colgroup": true, "dd": true, "details": true, "dialog": true, "dir": true,
+	"div": true, "dl": true, "dt": true, "fieldset": true, "figcaption": true,
+	"figure": true, "footer": true, "form": true, "frame": true, "frameset": true,
+	"h1": true, "h2": true, "h3": true, "h4": true, "h5": true, "h6": true,
+	"head": true, "header": true, "hr": true, "html": true, "iframe": true,
+	"legend": true, "li": true, "link": true, "main": true, "menu": true,
+	"menuitem": true, "nav": true, "noframes": true, "ol": true, "optgroup": true,
+	"option": true, "p": true, "param": true, "search": true, "section": true,
+	"summary": true, "table": true, "tbody": true, "td": true, "tfoot": true,
+	"th": true, "thead": true, "title": true, "tr": true, "track": true, "ul": true,
+}
+
+// FindHTMLTags returns the raw HTML tags and comments in line, skipping
+// any inside `code spans`. Autolinks such as <https://x.io> are not tags.
+func FindHTMLTags(line string) []HTMLTag {
+	code := codeSpanRe.FindAllStringIndex(line, -1)
+	inCode := func(pos int) bool {
+		for _, span := range code {
+			if pos >= span[0] && pos < span[1] {
+				return true
+			}
+		}
+		return false
+	}
+
+	var tags []HTMLTag
+	for _, m := range htmlTagRe.FindAllStringSubmatchIndex(line, -1) {
+		if inCode(m[0]) {
+			continue
+		}
+		tag := HTMLTag{Start: m[0], End: m[1]}
+		switch {
+		case m[2] >= 0:
+			tag.Name = strings.ToLower(line[m[2]:m[3]])
+			tag.Closing = true
+		case m[4] >= 0:
+			tag.Name = strings.ToLower(line[m[4]:m[5]])
+			tag.SelfClosing = m[7] > m[6]
+		default:
+			tag.Comment = true
+		}
+		tags = append(tags, tag)
+	}
+	return tags
+}
+
+// HTMLBlocks lists the document's raw HTML blocks outside fenced code.
+// They are found again only after an edit.
+func (d *Document) HTMLBlocks() []HTMLBlock {
+	return slices.Clone(d.htmlBlocks())
+}
+
+// htmlCache holds the HTML blocks of the document as of an edit revision.
+type htmlCache struct {
+	revision int
+	valid    bool
+	blocks   []HTMLBlock
+}
+
+// htmlBlocks returns the cached HTML blocks; callers must not modify the
+// result.
+func (d *Document) htmlBlocks() []HTMLBlock {
+	if d.html.valid && d.html.revision == d.revision {
+		return d.html.blocks
+	}
+
+	fences := d.fencedBlocks()
+	inFence := func(line int) bool {
+		for _, fence := range fences {
+			if fence.Contains(line) {
+				return true
+			}
+		}
+		return false
+	}
+
+	var blocks []HTMLBlock
+	for i := 0; i < len(d.lines); i++ {
+		if inFence(i) {
+			continue
+		}
+
+		text := d.lines[i].text
+		trimmed := strings.TrimLeft(text, " ")
+		if len(text)-len(trimmed) > 3 || !strings.HasPrefix(trimmed, "<") {
+			continue
+		}
+
+		afterBlock := len(blocks) > 0 && blocks[len(blocks)-1].EndLine == i-1
+		end, raw, ok := d.htmlBlockEnd(i, trimmed, afterBlock)
+		if !ok {
+			continue
+		}
+		blocks = append(blocks, HTMLBlock{StartLine: i, EndLine: end, Raw: raw})
+		i = end
+	}
+	d.html = htmlCache{revision: d.revision, valid: true, blocks: blocks}
+	return blocks
+}
+
+// HTMLBlockAt returns the HTML block containing line, if any.
+func (d *Document) HTMLBlockAt(line int) (HTMLBlock, bool) {
+	for _, block := range d.htmlBlocks() {
+		if block.Contains(line) {
+			return block, true
+		}
+		if block.StartLine > line {
+			break
+		}
+	}
+	return HTMLBlock{}, false
+}
+
+// htmlBlockEnd reports whether the line at start opens an HTML block and
+// where it ends. trimmed is the line without its leading indentation;
+// afterBlock is set when the previous line closed another HTML block.
+func (d *Document) htmlBlockEnd(start int, trimmed string, afterBlock bool) (end int, raw bool, ok bool) {
+	if m := rawBlockStartRe.FindStringSubmatch(trimmed); m != nil {
+		return d.lineContaining(start, "</"+strings.ToLower(m[1])+">", true), true, true
+	}
+
+	switch {
+	case strings.HasPrefix(trimmed, "<!--"):
+		return d.lineContaining(start, "-->", false), false, true
+	case strings.HasPrefix(trimmed, "<?"):
+		return d.lineContaining(start, "?>", false), false, true
+	case strings.HasPrefix(trimmed, "<![CDATA["):
+		return d.lineContaining(start, "]]>", false), false, true
+	case len(trimmed) > 2 && trimmed[1] == '!' && isASCIILetter(trimmed[2]):
+		return d.lineContaining(start, ">", false), false, true
+	}
+
+	if m := blockTagRe.FindStringSubmatch(trimmed); m != nil && htmlBlockTags[strings.ToLower(m[1])] {
+		return d.blankLineBefore(start), false, true
+	}
+
+	// Any other complete tag alone on its line, unless it would interrupt
+	// a paragraph
+	if tags := FindHTMLTags(trimmed); len(tags) == 1 && !tags[0].Comment &&
+		tags[0].Start == 0 && strings.TrimSpace(trimmed[tags[0].End:]) == "" {
+		if start > 0 && !afterBlock && isParagraphText(d.lines[start-1].text) {
+			return 0, false, false
+		}
+		return d.blankLineBefore(start), false, true
+	}
+
+	return 0, false, false
+}
+
+// lineContaining returns the first line from start holding marker, or the
+// last line of the document if none does.
+func (d *Document) lineContaining(start int, marker string, foldCase bool) int {
+	for i := start; i < len(d.lines); i++ {
+		text := d.lines[i].text
+		if foldCase {
+			text = strings.ToLower(text)
+		}
+		// The end marker may not be the opening "<!--" itself
+		if i == start && marker == "-->" {
+			text = strings.Replace(text, "<!--", "    ", 1)
+		}
+		if strings.Contains(text, marker) {
+			return i
+		}
+	}
+	return len(d.lines) - 1
+}
+
+// blankLineBefore returns the last line before the next blank line.
+func (d *Document) blankLineBefore(start int) int {
+	for i := start + 1; i < len(d.lines); i++ {
+		if strings.TrimSpace(d.lines[i].text) == "" {
+			return i - 1
+		}
+	}
+	return len(d.lines) - 1
+}
+
+func isASCIILetter(b byte) bool {
+	return (b >= 'a' && b <= 'z') || (b >= 'A' && b <= 'Z')
+}
@@ -0,0 +1,98 @@
+package ast
+
+import "sort"
+
+// lineIndex holds the byte offset at which each line starts in the text
+// returned by GetText, and the rune offset. Edits mark the entries after
+// the first changed line stale; they are recomputed on the next lookup, so
+// a burst of typing costs nothing until an offset is needed.
+type lineIndex struct {
+	starts     []int
+	runeStarts []int
+	valid      int // Leading entries of starts and runeStarts that are up to date
+}
+
+// linesChanged records that line first, and possibly every line after it,
+// changed or moved. The start of first itself depends only on the lines
+// before it and stays valid.
+func (d *Document) linesChanged(first int) {
+	d.revision++
+	d.index.valid = min(d.index.valid, max(first+1, 0))
+}
+
+// lineStarts brings the index up to date and returns it.
+func (d *Document) lineStarts() []int {
+	index := &d.index
+	if index.valid == len(d.lines) && len(index.starts) == len(d.lines) {
+		return index.starts
+	}
+	index.valid = min(index.valid, len(d.lines))
+	index.starts = growIndex(index.starts, index.valid, len(d.lines))
+	index.runeStarts = growIndex(index.runeStarts, index.valid, len(d.lines))
+	if index.valid == 0 && len(d.lines) > 0 {
+		index.starts[0] = 0
+		index.runeStarts[0] = 0
+		index.valid = 1
+	}
+	for i := index.valid; i < len(d.lines); i++ {
+		index.starts[i] = index.starts[i-1] + len(d.lines[i-1].text) + 1
+		index.runeStarts[i] = index.runeStarts[i-1] + d.lines[i-1].length + 1
+	}
+	index.valid = len(d.lines)
+	return index.starts
+}
+
+// growIndex returns starts sized for lines lines, keeping its first valid
+// entries.
+func growIndex(starts []int, valid, lines int) []int {
+	if cap(starts) < lines {
+		grown := make([]int, lines, lines+lines/4)
+		copy(grown, starts[:valid])
+		return grown
+	}
+	return starts[:lines]
+}
+
+// TextLength returns the length in bytes of the text returned by GetText.
+func (d *Document) TextLength() int {
+	starts := d.lineStarts()
+	last := len(starts) - 1
+	return starts[last] + len(d.lines[last].text)
+}
+
+// OffsetToPos converts a byte offset into the text returned by GetText to
+// a buffer position, as for the byte offsets found by strings.Index or
+// goldmark's segments. Offsets are clamped to the text, and converted within
+// the line as RuneOffset does.
+func (d *Document) OffsetToPos(offset int) BufferPos {
+	starts := d.lineStarts()
+	offset = max(min(offset, d.TextLength()), 0)
+	line := sort.Search(len(starts), func(i int) bool { return starts[i] > offset }) - 1
+	return BufferPos{Line: line, Col: RuneOffset(d.lines[line].text, offset-starts[line])}
+}
+
+// PosToOffset converts a buffer position to a byte offset into the text
+// returned by GetText. The position is validated first.
+func (d *Document) PosToOffset(pos BufferPos) int {
+	pos = d.ValidatePosition(pos)
+	return d.lineStarts()[pos.Line] + ByteOffset(d.lines[pos.Line].text, pos.Col)
+}
+
+// RuneOffsetToPos converts a rune offset into the text returned by GetText,
+// counting each line break as one rune, to a buffer position. Offsets are
+// clamped to the text.
+func (d *Document) RuneOffsetToPos(offset int) BufferPos {
+	d.lineStarts()
+	starts := d.index.runeStarts
+	line := max(sort.Search(len(starts), func(i int) bool { return starts[i] > offset })-1, 0)
+	return BufferPos{Line: line, Col: min(max(offset-starts[line], 0), d.lines[line].length)}
+}
+
+// PosToRuneOffset converts a buffer position to a rune offset into the
+// text returned by GetText, the inverse of RuneOffsetToPos. The position
+// is validated first.
+func (d *Document) PosToRuneOffset(pos BufferPos) int {
+	pos = d.ValidatePosition(pos)
+	d.lineStarts()
+	return d.index.runeStarts[pos.Line] + pos.Col
+}
@@ -0,0 +1,38 @@
+package ast
+
+import "strings"
+
+// LongLineRunes is the length from which a line counts as long. Long lines,
+// such as minified content on a single line, are rendered a visible window
+// at a time and not syntax highlighted.
+const LongLineRunes = 10000
+
+// IsLongLine reports whether line lineNum is at least LongLineRunes long.
+func (d *Document) IsLongLine(lineNum int) bool {
+	return d.GetLineLength(lineNum) >= LongLineRunes
+}
+
+// VisibleWindow returns the cells of line from display column left, at
+// most width of them, with tabs expanded. A tab cut by the left edge keeps
+// its visible cells. Runes past the window are never examined, so the cost
+// depends on left+width rather than on the length of the line.
+func VisibleWindow(line string, left, width, tabWidth int) string {
+	right := left + width
+	var window strings.Builder
+	display := 0
+	for _, r := range line {
+		if display >= right {
+			break
+		}
+		next := nextCell(display, r, tabWidth)
+		if next > left {
+			if r == '\t' {
+				window.WriteString(strings.Repeat(" ", min(next, right)-max(display, left)))
+			} else {
+				window.WriteRune(r)
+			}
+		}
+		display = next
+	}
+	return window.String()
+}
@@ -0,0 +1,51 @@
+package ast
+
+import (
+	"strings"
+)
+
+// MathBlock describes a display math block delimited by lines holding
+// only "$$". Line numbers are 0-indexed and include both delimiters.
+type MathBlock struct {
+	StartLine int
+	EndLine   int  // Closing delimiter (last line if unclosed)
+	Closed    bool // Whether a closing delimiter was found
+}
+
+// Contains reports whether line falls within the block, delimiters included.
+func (b MathBlock) Contains(line int) bool {
+	return line >= b.StartLine && line <= b.EndLine
+}
+
+// MathBlocks scans the document for $$ display math blocks, ignoring
+// delimiters inside fenced code blocks.
+func (d *Document) MathBlocks() []MathBlock {
+	fences := d.fencedBlocks()
+	inFence := func(line int) bool {
+		for _, fence := range fences {
+			if fence.Contains(line) {
+				return true
+			}
+		}
+		return false
+	}
+
+	var blocks []MathBlock
+	open := -1
+	for i, line := range d.lines {
+		if strings.TrimSpace(line.text) != "$$" || inFence(i) {
+			continue
+		}
+		if open < 0 {
+			open = i
+			continue
+		}
+		blocks = append(blocks, MathBlock{StartLine: open, EndLine: i, Closed: true})
+		open = -1
+	}
+
+	if open >= 0 {
+		blocks = append(blocks, MathBlock{StartLine: open, EndLine: len(d.lines) - 1})
+	}
+	return blocks
+}
@@ -0,0 +1,143 @@
+package ast
+
+import (
+	"strings"
+)
+
+// QuoteDepth counts the blockquote markers opening text ("> > x" and
+// ">> x" are both depth 2). Returns the depth, the byte offsets of each
+// marker and the offset where the quoted content starts.
+func QuoteDepth(text string) (depth int, markers []int, content int) {
+	pos := 0
+	for {
+		indent := pos
+		for indent < len(text) && text[indent] == ' ' && indent-pos < 3 {
+			indent++
+		}
+		if indent >= len(text) || text[indent] != '>' {
+			break
+		}
+		markers = append(markers, indent)
+		pos = indent + 1
+		if pos < len(text) && text[pos] == ' ' {
+			pos++
+		}
+	}
+	return len(markers), markers, pos
+}
+
+// ListItem describes a list item marker line.
+type ListItem struct {
+	Indent    int  // Column of the marker (tabs expanded to 4)
+	Marker    int  // Byte offset of the marker
+	MarkerEnd int  // Byte offset just past the marker
+	Ordered   bool // "1." rather than "-", "*" or "+"
+}
+
+// ParseListItem recognizes a bullet ("- ", "* ", "+ ") or ordered ("1. ",
+// "1) ") list item.
+func ParseListItem(text string) (ListItem, bool) {
+	column := 0
+	offset := 0
+	for offset < len(text) && (text[offset] == ' ' || text[offset] == '\t') {
+		if text[offset] == '\t' {
+			column += 4 - column%4
+		} else {
+			column++
+		}
+		offset++
+	}
+	if offset >= len(text) {
+		return ListItem{}, false
+	}
+
+	item := ListItem{Indent: column, Marker: offset}
+	switch text[offset] {
+	case '-', '*', '+':
+		item.MarkerEnd = offset + 1
+	default:
+		digits := offset
+		for digits < len(text) && digits-offset < 9 && text[digits] >= '0' && text[digits] <= '9' {
+			digits++
+		}
+		if digits == offset || digits >= len(text) || (text[digits] != '.' && text[digits] != ')') {
+			return ListItem{}, false
+		}
+		item.MarkerEnd = digits + 1
+		item.Ordered = true
+	}
+
+	// The marker must be followed by whitespace or end the line
+	if item.MarkerEnd < len(text) && text[item.MarkerEnd] != ' ' && text[item.MarkerEnd] != '\t' {
+		return ListItem{}, false
+	}
+	return item, true
+}
+
+// ListDepth returns the nesting depth of the list item at line (1 for a
+// top-level item), or 0 if the line is not a list item. Depth follows the
+// indentation of the enclosing items above, so 2- and 4-space nesting
+// styles both count one level per step.
+func (d *Document) ListDepth(line int) int {
+	if line < 0 || line >= len(d.lines) {
+		return 0
+	}
+	target, ok := ParseListItem(d.lines[line].text)
+	if !ok {
+		return 0
+	}
+
+	// Find where this list starts: just after the nearest unindented
+	// non-list text above, or the document start
+	start := line
+	for i := line - 1; i >= 0 && !endsList(d.lines[i].text); i-- {
+		start = i
+	}
+
+	var stack []int
+	for i := start; i < line; i++ {
+		if item, isItem := ParseListItem(d.lines[i].text); isItem {
+			stack = pushIndent(stack, item.Indent)
+		}
+	}
+	return len(pushIndent(stack, target.Indent))
+}
+
+// ListDepths returns ListDepth for every line in a single pass.
+func (d *Document) ListDepths() []int {
+	depths := make([]int, len(d.lines))
+	var stack []int
+	for i, line := range d.lines {
+		if endsList(line.text) {
+			stack = nil
+			continue
+		}
+		if item, ok := ParseListItem(line.text); ok {
+			stack = pushIndent(stack, item.Indent)
+			depths[i] = len(stack)
+		}
+	}
+	return depths
+}
+
+// endsList reports whether text is unindented non-list content, which
+// closes any open list.
+func endsList(text string) bool {
+	if strings.TrimSpace(text) == "" || text[0] == ' ' || text[0] == '\t' {
+		return false
+	}
+	_, isItem := ParseListItem(text)
+	return !isItem
+}
+
+// pushIndent updates a stack of enclosing item indents with a new item:
+// shallower or equal items close deeper ones, siblings share a level.
+func pushIndent(stack []int, indent int) []int {
+	for len(stack) > 0 && stack[len(stack)-1] > indent {
+		stack = stack[:len(stack)-1]
+	}
+	if len(stack) > 0 && stack[len(stack)-1] == indent {
+		return stack
+	}
+	return append(stack, indent)
+}
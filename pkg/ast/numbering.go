@@ -0,0 +1,138 @@
+package ast
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// headingNumberRe matches a hierarchical number leading a heading's text,
+// as in "2.3.1 " or "2.3.1. ".
+var headingNumberRe = regexp.MustCompile(`^\d+(?:\.\d+)*\.?[ \t]+`)
+
+// HeadingNumbers returns the hierarchical number of each heading, as
+// "2.3.1", counting from the shallowest level. A lone shallowest heading
+// leading the document is its title and gets "", numbering starting a
+// level below it. A skipped level counts as 0, as in "1.0.1".
+func HeadingNumbers(headings []Heading) []string {
+	numbers := make([]string, len(headings))
+	if len(headings) == 0 {
+		return numbers
+	}
+	top, count := maxHeadingLevel, 0
+	for _, heading := range headings {
+		if heading.Level < top {
+			top, count = heading.Level, 0
+		}
+		if heading.Level == top {
+			count++
+		}
+	}
+	first := 0
+	if count == 1 && headings[0].Level == top && len(headings) > 1 {
+		first, top = 1, top+1 // The title
+	}
+
+	var counters [maxHeadingLevel + 1]int
+	for i := first; i < len(headings); i++ {
+		level := headings[i].Level
+		counters[level]++
+		for deeper := level + 1; deeper <= maxHeadingLevel; deeper++ {
+			counters[deeper] = 0
+		}
+		parts := make([]string, 0, level-top+1)
+		for l := top; l <= level; l++ {
+			parts = append(parts, strconv.Itoa(counters[l]))
+		}
+		numbers[i] = strings.Join(parts, ".")
+	}
+	return numbers
+}
+
+// NumberHeadings writes each heading's number from HeadingNumbers before
+// its text, replacing any number already there, so the numbers follow
+// sections as they are added, removed or moved. The heading on line skip
+// is left out while it has no number, as it is likely being typed; -1
+// skips none. Returns the number of lines changed.
+func (e *Editor) NumberHeadings(skip int) int {
+	var headings []Heading
+	for _, heading := range e.document.Headings() {
+		if start, end := e.document.headingNumberAt(heading.Line); heading.Line != skip || end > start {
+			headings = append(headings, heading)
+		}
+	}
+	numbers := HeadingNumbers(headings)
+	changed := 0
+	for i, heading := range headings {
+		if numbers[i] == "" {
+			continue // The title
+		}
+		start, end := e.document.headingNumberAt(heading.Line)
+		number := numbers[i] + " "
+		if text := e.document.GetLine(heading.Line); start == len(text) && strings.HasSuffix(text, "#") {
+			number = " " + number // "##" alone, its space yet to be typed
+		}
+		if e.replaceInLine(heading.Line, start, end, number) {
+			changed++
+		}
+	}
+	if changed > 0 {
+		e.AdjustViewPort()
+	}
+	return changed
+}
+
+// UnnumberHeadings removes the numbers leading headings' text. Returns
+// the number of lines changed.
+func (e *Editor) UnnumberHeadings() int {
+	changed := 0
+	for _, heading := range e.document.Headings() {
+		if start, end := e.document.headingNumberAt(heading.Line); end > start {
+			e.replaceInLine(heading.Line, start, end, "")
+			changed++
+		}
+	}
+	if changed > 0 {
+		e.AdjustViewPort()
+	}
+	return changed
+}
+
+// headingNumberAt returns where the number leading the text of the
+// heading on line starts and ends; start == end when it has none, start
+// being where the text begins.
+func (d *Document) headingNumberAt(line int) (start, end int) {
+	text := d.lines[line].text
+	start = len(text) - len(strings.TrimLeft(text, " "))
+	if isATXHeading(text[start:]) {
+		start += len(text[start:]) - len(strings.TrimLeft(text[start:], "#"))
+		start += len(text[start:]) - len(strings.TrimLeft(text[start:], " \t"))
+	}
+	if m := headingNumberRe.FindStringIndex(text[start:]); m != nil {
+		return start, start + m[1]
+	}
+	return start, start
+}
+
+// replaceInLine replaces bytes [start, end) of line, which hold ASCII
+// only, with text, moving the cursor and selection along. Returns false
+// when the line already read so.
+func (e *Editor) replaceInLine(line, start, end int, text string) bool {
+	old := e.document.lines[line].text
+	if old[start:end] == text {
+		return false
+	}
+	e.document.setLine(line, old[:start]+text+old[end:])
+	delta := len(text) - (end - start)
+	e.remapPositions(func(pos BufferPos) BufferPos {
+		if pos.Line == line && pos.Col > start {
+			if pos.Col >= end {
+				pos.Col += delta
+			} else {
+				pos.Col = min(pos.Col, start+len(text))
+			}
+		}
+		return pos
+	})
+	return true
+}
@@ -0,0 +1,64 @@
+package ast
+
+import "unicode/utf8"
+
+// Offsets within a line are rune offsets throughout the editor:
+// BufferPos.Col, Token start and end, and the renderer's style ranges all
+// count characters, so multibyte text such as "日本語" or "👍" lines up
+// with the cursor. Searching text with regexp or strings.Index yields byte
+// offsets; convert them with RuneOffset (or RuneTokens) where they are
+// produced, and back with ByteOffset before slicing the string.
+
+// RuneOffset converts byte offset i of line to a rune offset, clamped to
+// the line. An offset inside a character counts that character; invalid
+// bytes count as one rune each, as in []rune(line).
+func RuneOffset(line string, i int) int {
+	if i <= 0 {
+		return 0
+	}
+	if i >= len(line) {
+		return utf8.RuneCountInString(line)
+	}
+	n := 0
+	for offset := range line {
+		if offset >= i {
+			break
+		}
+		n++
+	}
+	return n
+}
+
+// ByteOffset converts rune offset col of line to a byte offset, clamped
+// to the line.
+func ByteOffset(line string, col int) int {
+	offset := 0
+	for i := 0; i < col && offset < len(line); i++ {
+		_, size := utf8.DecodeRuneInString(line[offset:])
+		offset += size
+	}
+	return offset
+}
+
+// RuneTokens converts tokens found at byte offsets of line to rune
+// offsets, in place.
+func RuneTokens(line string, tokens []Token) []Token {
+	if isASCII(line) {
+		return tokens
+	}
+	for i, token := range tokens {
+		tokens[i].start = RuneOffset(line, token.start)
+		tokens[i].end = RuneOffset(line, token.end)
+	}
+	return tokens
+}
+
+// isASCII reports whether byte and rune offsets of s coincide.
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= utf8.RuneSelf {
+			return false
+		}
+	}
+	return true
+}
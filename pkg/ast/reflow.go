@@ -0,0 +1,208 @@
+package ast
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// ReflowParagraph rewraps the paragraph at the cursor, or the paragraphs
+// the selection touches, filling lines up to width columns. The cursor
+// stays on the character it was on. Reports false when there is no text
+// to reflow: on a blank line or in a fenced code block.
+func (e *Editor) ReflowParagraph(width int) bool {
+	start := e.cursorManager.GetBufferPos()
+	end := start
+	if selection := e.cursorManager.GetSelection(); selection != nil {
+		r := orderedSelection(selection)
+		start, end = r.Start, r.End
+	}
+	first, _, ok := e.document.ParagraphAt(start.Line)
+	if !ok {
+		first = start.Line
+	}
+	_, last, ok := e.document.ParagraphAt(end.Line)
+	if !ok {
+		last = end.Line
+	}
+	for first <= last && isBlankLine(e.document.GetLine(first)) {
+		first++
+	}
+	if first > last {
+		return false
+	}
+	if _, inFence := e.document.FencedBlockAt(first); inFence {
+		return false
+	}
+
+	var lines []string
+	for i := first; i <= last; i++ {
+		if _, inFence := e.document.FencedBlockAt(i); inFence {
+			last = i - 1 // Code ends the text reflowed
+			break
+		}
+		lines = append(lines, e.document.GetLine(i))
+	}
+	r := Range{BufferPos{first, 0}, BufferPos{last, e.document.GetLineLength(last)}}
+	text := strings.Join(lines, "\n")
+	reflowed := strings.Join(Reflow(lines, width), "\n")
+	if reflowed == text {
+		return true
+	}
+
+	// The cursor goes back after as many non-space characters, and on to
+	// the next if it was on one
+	pos := e.cursorManager.GetBufferPos()
+	inside := r.Contains(Range{pos, pos})
+	before := countNonSpace(e.document.GetSelectionText(&Selection{r.Start, pos}))
+	line := []rune(e.document.GetLine(pos.Line))
+	onWord := pos.Col < len(line) && !unicode.IsSpace(line[pos.Col])
+	e.cursorManager.ClearSelection()
+	e.ReplaceRange(r, reflowed)
+	if inside {
+		e.cursorManager.SetBufferPos(afterNonSpace(reflowed, r.Start, before, onWord))
+		e.AdjustViewPort()
+	}
+	return true
+}
+
+// orderedSelection returns the selection as a range from its earlier end.
+func orderedSelection(s *Selection) Range {
+	if s.End.Before(s.Start) {
+		return Range{s.End, s.Start}
+	}
+	return Range{s.Start, s.End}
+}
+
+// countNonSpace returns how many characters of text are not whitespace.
+func countNonSpace(text string) int {
+	n := 0
+	for _, r := range text {
+		if !unicode.IsSpace(r) {
+			n++
+		}
+	}
+	return n
+}
+
+// afterNonSpace returns the position in text, which starts at start, just
+// after its first n non-space characters, or with toNext, at the next.
+func afterNonSpace(text string, start BufferPos, n int, toNext bool) BufferPos {
+	pos := start
+	for _, r := range text {
+		if n == 0 && (!toNext || !unicode.IsSpace(r)) {
+			break
+		}
+		if r == '\n' {
+			pos = BufferPos{Line: pos.Line + 1}
+		} else {
+			pos.Col++
+		}
+		if !unicode.IsSpace(r) {
+			n--
+		}
+	}
+	return pos
+}
+
+// Reflow rewraps Markdown text lines so none runs past width columns
+// where its words allow, keeping blockquote markers and list item
+// indentation on every line. Blank lines, headings, table rows and HTML
+// lines are kept as they are, list items and hard line breaks end the
+// lines before them.
+func Reflow(lines []string, width int) []string {
+	var out []string
+	var words []string
+	var prefix, continuation string
+	flush := func(hardBreak string) {
+		if len(words) > 0 {
+			out = append(out, fill(words, prefix, continuation, width)...)
+			out[len(out)-1] += hardBreak
+		}
+		words = nil
+	}
+
+	for i, line := range lines {
+		quote := QuotePrefix(line)
+		body := line[len(quote):]
+		content := strings.TrimLeft(body, " \t")
+		if keepLine(content) || (i+1 < len(lines) && isSetextUnderline(lines[i+1])) {
+			flush("")
+			out = append(out, line)
+			continue
+		}
+
+		if item, ok := ParseListItem(body); ok && item.MarkerEnd < len(body) && (body[item.MarkerEnd] == ' ' || body[item.MarkerEnd] == '\t') {
+			flush("")
+			marker := item.MarkerEnd + 1
+			for marker < len(body) && body[marker] == ' ' {
+				marker++
+			}
+			prefix = quote + body[:marker]
+			continuation = quote + strings.Repeat(" ", utf8.RuneCountInString(body[:marker]))
+			if box := taskBox(body[marker:]); box != "" {
+				prefix += box
+				content = body[marker+len(box):]
+			} else {
+				content = body[marker:]
+			}
+		} else if len(words) == 0 {
+			prefix = quote + LeadingWhitespace(body)
+			continuation = prefix
+		}
+		words = append(words, strings.Fields(content)...)
+
+		if strings.HasSuffix(line, "  ") {
+			flush("  ")
+		}
+	}
+	flush("")
+	return out
+}
+
+// fill lays words out in lines of up to width columns, the first starting
+// with prefix and the others with continuation. A word longer than a line
+// gets a line of its own.
+func fill(words []string, prefix, continuation string, width int) []string {
+	var lines []string
+	line := prefix
+	start := true
+	for _, word := range words {
+		if !start && utf8.RuneCountInString(line)+1+utf8.RuneCountInString(word) > width {
+			lines = append(lines, line)
+			line = continuation
+			start = true
+		}
+		if !start {
+			line += " "
+		}
+		line += word
+		start = false
+	}
+	return append(lines, line)
+}
+
+// keepLine reports whether a line with content, less its blockquote
+// markers and indentation, is kept as it is rather than reflowed.
+func keepLine(content string) bool {
+	return content == "" || strings.HasPrefix(content, "#") || strings.HasPrefix(content, "|") ||
+		strings.HasPrefix(content, "<") || isSetextUnderline(content)
+}
+
+// isSetextUnderline reports whether line underlines a setext heading, or
+// is a thematic break.
+func isSetextUnderline(line string) bool {
+	trimmed := strings.TrimSpace(line)
+	return trimmed != "" && (strings.Trim(trimmed, "=") == "" || strings.Trim(trimmed, "-") == "")
+}
+
+// taskBox returns the task list checkbox opening text, with the space
+// after it, or "".
+func taskBox(text string) string {
+	for _, box := range []string{"[ ] ", "[x] ", "[X] "} {
+		if strings.HasPrefix(text, box) {
+			return box
+		}
+	}
+	return ""
+}
@@ -0,0 +1,124 @@
+package ast
+
+import "strconv"
+
+// listLevel is an open list while renumbering: its items' indent and
+// marker kind, and the number the next item gets.
+type listLevel struct {
+	indent  int
+	ordered bool
+	delim   byte
+	next    int
+}
+
+// RenumberList rewrites the numbers of the ordered lists around the cursor
+// so each counts up from its first item, at every nesting level. Items
+// keep their content, and a sublist restarts under each parent item.
+// Returns the number of lines changed.
+func (e *Editor) RenumberList() int {
+	changed, remap := e.document.renumberList(e.cursorManager.GetBufferPos().Line)
+	if changed > 0 {
+		e.remapPositions(remap)
+	}
+	return changed
+}
+
+// renumberList renumbers the lists in the block holding line: everything
+// between the nearest unindented non-list lines around it. Returns the
+// lines changed and how positions move with the edit.
+func (d *Document) renumberList(line int) (int, func(BufferPos) BufferPos) {
+	if line < 0 || line >= len(d.lines) || endsList(d.lines[line].text) {
+		return 0, nil
+	}
+	start, end := line, line
+	for start > 0 && !endsList(d.lines[start-1].text) {
+		start--
+	}
+	for end+1 < len(d.lines) && !endsList(d.lines[end+1].text) {
+		end++
+	}
+
+	if !d.hasOrderedItem(start, end) {
+		return 0, nil
+	}
+
+	type edit struct{ line, col, from, to int }
+	var edits []edit
+	var stack []listLevel
+	fences := d.fencedBlocks()
+	for i := start; i <= end; i++ {
+		if inFences(fences, i) {
+			continue
+		}
+		text := d.lines[i].text
+		item, ok := ParseListItem(text)
+		if !ok {
+			continue
+		}
+
+		for len(stack) > 0 && stack[len(stack)-1].indent > item.Indent {
+			stack = stack[:len(stack)-1]
+		}
+		digits := text[item.Marker : item.MarkerEnd-1]
+		delim := text[item.MarkerEnd-1]
+		top := len(stack) - 1
+		if top < 0 || stack[top].indent != item.Indent || stack[top].ordered != item.Ordered || stack[top].delim != delim {
+			// A deeper item or a change of marker starts a new list,
+			// numbered from its first item
+			level := listLevel{indent: item.Indent, ordered: item.Ordered, delim: delim}
+			level.next, _ = strconv.Atoi(digits)
+			if top >= 0 && stack[top].indent == item.Indent {
+				stack[top] = level
+			} else {
+				stack = append(stack, level)
+				top++
+			}
+		}
+		if !item.Ordered {
+			continue
+		}
+
+		number := strconv.Itoa(stack[top].next)
+		stack[top].next++
+		if number == digits {
+			continue
+		}
+		d.setLine(i, text[:item.Marker]+number+text[item.MarkerEnd-1:])
+		edits = append(edits, edit{line: i, col: RuneOffset(text, item.Marker), from: len(digits), to: len(number)})
+	}
+
+	return len(edits), func(pos BufferPos) BufferPos {
+		for _, e := range edits {
+			if pos.Line != e.line || pos.Col <= e.col {
+				continue
+			}
+			if pos.Col >= e.col+e.from {
+				pos.Col += e.to - e.from
+			} else {
+				pos.Col = min(pos.Col, e.col+e.to)
+			}
+		}
+		return pos
+	}
+}
+
+// inFences reports whether line lies within one of blocks.
+func inFences(blocks []FencedBlock, line int) bool {
+	for _, block := range blocks {
+		if block.Contains(line) {
+			return true
+		}
+	}
+	return false
+}
+
+// hasOrderedItem reports whether any of lines [start, end] is a numbered
+// list item.
+func (d *Document) hasOrderedItem(start, end int) bool {
+	for i := start; i <= end; i++ {
+		if item, ok := ParseListItem(d.lines[i].text); ok && item.Ordered {
+			return true
+		}
+	}
+	return false
+}
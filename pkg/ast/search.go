@@ -0,0 +1,170 @@
+package ast
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// SearchOptions controls Document.Find.
+type SearchOptions struct {
+	CaseSensitive bool
+	Backward      bool
+}
+
+// Find returns the next match of query from pos, wrapping around the end
+// (or, searching backward, the start) of the document. Searching forward, a
+// match starting at pos counts; backward, a match must start before pos.
+// The query may span lines. Lines are searched in place, so no copy of the
+// document or of the query is made per line.
+func (d *Document) Find(query string, pos BufferPos, opts SearchOptions) (Range, bool) {
+	if query == "" {
+		return Range{}, false
+	}
+	pos = d.ValidatePosition(pos)
+	parts := strings.Split(query, "\n")
+	fold := !opts.CaseSensitive
+	split := ByteOffset(d.lines[pos.Line].text, pos.Col)
+	n := len(d.lines)
+
+	// Start offsets may reach the end of the line, where a query beginning
+	// with a line break matches
+	try := func(line, lo, hi int) (Range, bool) {
+		return d.findInLine(line, lo, hi, parts, fold, opts.Backward)
+	}
+	if !opts.Backward {
+		if r, ok := try(pos.Line, split, len(d.lines[pos.Line].text)+1); ok {
+			return r, true
+		}
+		for i := 1; i < n; i++ {
+			line := (pos.Line + i) % n
+			if r, ok := try(line, 0, len(d.lines[line].text)+1); ok {
+				return r, true
+			}
+		}
+		return try(pos.Line, 0, split)
+	}
+
+	if r, ok := try(pos.Line, 0, split); ok {
+		return r, true
+	}
+	for i := 1; i < n; i++ {
+		line := (pos.Line - i + n) % n
+		if r, ok := try(line, 0, len(d.lines[line].text)+1); ok {
+			return r, true
+		}
+	}
+	return try(pos.Line, split, len(d.lines[pos.Line].text)+1)
+}
+
+// MatchAt reports whether query matches at pos, returning the range it
+// covers.
+func (d *Document) MatchAt(query string, pos BufferPos, caseSensitive bool) (Range, bool) {
+	if query == "" {
+		return Range{}, false
+	}
+	pos = d.ValidatePosition(pos)
+	start := ByteOffset(d.lines[pos.Line].text, pos.Col)
+	end, ok := d.matchFrom(pos.Line, start, strings.Split(query, "\n"), !caseSensitive)
+	return Range{Start: pos, End: end}, ok
+}
+
+// findInLine looks for a match of parts starting at a byte offset of line
+// in [lo, hi), nearest lo or, backward, nearest hi.
+func (d *Document) findInLine(line, lo, hi int, parts []string, fold, backward bool) (Range, bool) {
+	text := d.lines[line].text
+	at := func(i int) (Range, bool) {
+		if i < len(text) && !utf8.RuneStart(text[i]) {
+			return Range{}, false
+		}
+		end, ok := d.matchFrom(line, i, parts, fold)
+		return Range{Start: BufferPos{Line: line, Col: RuneOffset(text, i)}, End: end}, ok
+	}
+
+	if !backward && !fold && len(parts) == 1 {
+		window := text[:min(hi+len(parts[0])-1, len(text))]
+		for lo < len(window) {
+			i := strings.Index(window[lo:], parts[0])
+			if i < 0 {
+				break
+			}
+			if r, ok := at(lo + i); ok {
+				return r, true
+			}
+			lo += i + 1
+		}
+		return Range{}, false
+	}
+	if backward {
+		for i := hi - 1; i >= lo; i-- {
+			if r, ok := at(i); ok {
+				return r, true
+			}
+		}
+		return Range{}, false
+	}
+	for i := lo; i < hi; i++ {
+		if r, ok := at(i); ok {
+			return r, true
+		}
+	}
+	return Range{}, false
+}
+
+// matchFrom matches parts, the lines of a query, from byte offset start of
+// line. Every part but the last must reach the end of its line. Returns
+// where the match ends.
+func (d *Document) matchFrom(line, start int, parts []string, fold bool) (BufferPos, bool) {
+	if line+len(parts) > len(d.lines) {
+		return BufferPos{}, false
+	}
+	offset := start
+	for k, part := range parts {
+		text := d.lines[line+k].text
+		end, ok := matchPrefix(text[offset:], part, fold)
+		if !ok {
+			return BufferPos{}, false
+		}
+		end += offset
+		if k == len(parts)-1 {
+			return BufferPos{Line: line + k, Col: RuneOffset(text, end)}, true
+		}
+		if end != len(text) {
+			return BufferPos{}, false
+		}
+		offset = 0
+	}
+	return BufferPos{}, false // Unreachable: parts is never empty
+}
+
+// matchPrefix reports whether s starts with prefix, ignoring case when fold
+// is set, and returns the length of s the match covers. With folding that
+// length can differ from len(prefix), as in "K" (Kelvin) against "k".
+func matchPrefix(s, prefix string, fold bool) (int, bool) {
+	if !fold {
+		return len(prefix), strings.HasPrefix(s, prefix)
+	}
+	i := 0
+	for _, want := range prefix {
+		if i >= len(s) {
+			return 0, false
+		}
+		got, size := utf8.DecodeRuneInString(s[i:])
+		if got != want && !equalFold(got, want) {
+			return 0, false
+		}
+		i += size
+	}
+	return i, true
+}
+
+// equalFold reports whether a and b are the same letter under Unicode
+// simple case folding.
+func equalFold(a, b rune) bool {
+	for r := unicode.SimpleFold(a); r != a; r = unicode.SimpleFold(r) {
+		if r == b {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,175 @@
+package ast
+
+import (
+	"strings"
+)
+
+// ============================================================================
+// SELECTION METHODS
+// ============================================================================
+//
+// Selection commands run a cursor motion and extend the selection to where
+// it lands, starting a selection at the cursor when none is active. The
+// anchor stays put, so chaining commands grows or shrinks one selection.
+
+// SelectWith extends the selection over the motion performed by move,
+// starting one at the cursor when none is active.
+func (e *Editor) SelectWith(move func()) {
+	if !e.cursorManager.HasSelection() {
+		e.cursorManager.StartSelection()
+	}
+	move()
+	e.cursorManager.ExtendSelection()
+}
+
+// SelectToLineStart extends the selection to the start of the line.
+func (e *Editor) SelectToLineStart() {
+	e.SelectWith(e.MoveCursorToLineStart)
+}
+
+// SelectToLineEnd extends the selection to the end of the line.
+func (e *Editor) SelectToLineEnd() {
+	e.SelectWith(e.MoveCursorToLineEnd)
+}
+
+// SelectWordLeft extends the selection to the start of the previous word.
+func (e *Editor) SelectWordLeft() {
+	e.SelectWith(e.MoveCursorWordLeft)
+}
+
+// SelectWordRight extends the selection to the start of the next word.
+func (e *Editor) SelectWordRight() {
+	e.SelectWith(e.MoveCursorWordRight)
+}
+
+// SelectToDocumentStart extends the selection to the start of the document.
+func (e *Editor) SelectToDocumentStart() {
+	e.SelectWith(e.MoveCursorToDocumentStart)
+}
+
+// SelectToDocumentEnd extends the selection to the end of the document.
+func (e *Editor) SelectToDocumentEnd() {
+	e.SelectWith(e.MoveCursorToDocumentEnd)
+}
+
+// SelectParagraphUp extends the selection to the start of the paragraph.
+func (e *Editor) SelectParagraphUp() {
+	e.SelectWith(e.MoveCursorParagraphUp)
+}
+
+// SelectParagraphDown extends the selection to the next paragraph.
+func (e *Editor) SelectParagraphDown() {
+	e.SelectWith(e.MoveCursorParagraphDown)
+}
+
+// SelectSentenceLeft extends the selection to the start of the sentence.
+func (e *Editor) SelectSentenceLeft() {
+	e.SelectWith(e.MoveCursorSentenceLeft)
+}
+
+// SelectSentenceRight extends the selection to the next sentence.
+func (e *Editor) SelectSentenceRight() {
+	e.SelectWith(e.MoveCursorSentenceRight)
+}
+
+// SelectParagraph selects the paragraph holding the cursor, leaving the
+// cursor at its end. Returns false when the cursor is on a blank line.
+func (e *Editor) SelectParagraph() bool {
+	return e.selectStructure(e.document.ParagraphRange(e.cursorManager.GetBufferPos().Line))
+}
+
+// SelectWord selects the word under the cursor.
+func (e *Editor) SelectWord() bool {
+	return e.selectStructure(e.document.WordAt(e.cursorManager.GetBufferPos()))
+}
+
+// SelectLine selects the cursor line including its line break.
+func (e *Editor) SelectLine() {
+	e.selectRange(e.document.LineRange(e.cursorManager.GetBufferPos().Line))
+}
+
+// SelectListItem selects the list item holding the cursor, with its
+// continuation lines and nested items.
+func (e *Editor) SelectListItem() bool {
+	return e.selectStructure(e.document.ListItemAt(e.cursorManager.GetBufferPos().Line))
+}
+
+// SelectSection selects the heading above the cursor and its content up
+// to the next heading of the same or a higher level.
+func (e *Editor) SelectSection() bool {
+	return e.selectStructure(e.document.SectionAt(e.cursorManager.GetBufferPos().Line))
+}
+
+// ExpandSelection grows the selection to the next enclosing unit: word,
+// sentence, paragraph, section, then the whole document. Returns false
+// when the whole document is already selected.
+func (e *Editor) ExpandSelection() bool {
+	pos := e.cursorManager.GetBufferPos()
+	current := Range{Start: pos, End: pos}
+	if selection := e.cursorManager.GetSelection(); selection != nil {
+		current = Range{Start: selection.Start, End: selection.End}
+		if current.End.Before(current.Start) {
+			current.Start, current.End = current.End, current.Start
+		}
+	}
+
+	// Units are tried from the selection start so a selection spanning
+	// several sentences still grows to its paragraph
+	at := current.Start
+	candidates := []func() (Range, bool){
+		func() (Range, bool) { return e.document.WordAt(at) },
+		func() (Range, bool) { return e.document.SentenceAt(at) },
+		func() (Range, bool) { return e.document.ParagraphRange(at.Line) },
+		func() (Range, bool) { return e.document.SectionAt(at.Line) },
+		func() (Range, bool) {
+			last := len(e.document.lines) - 1
+			return Range{End: BufferPos{last, e.document.GetLineLength(last)}}, true
+		},
+	}
+	for _, candidate := range candidates {
+		r, ok := candidate()
+		if ok && r.Contains(current) && r != current {
+			e.selectRange(r)
+			return true
+		}
+	}
+	return false
+}
+
+// selectStructure selects r if ok, reporting ok.
+func (e *Editor) selectStructure(r Range, ok bool) bool {
+	if ok {
+		e.selectRange(r)
+	}
+	return ok
+}
+
+// selectRange replaces the selection with r, cursor at its end.
+func (e *Editor) selectRange(r Range) {
+	e.cursorManager.SetBufferPos(r.Start)
+	e.cursorManager.StartSelection()
+	e.cursorManager.SetBufferPos(r.End)
+	e.cursorManager.SetDesiredColumn(r.End.Col)
+	e.cursorManager.ExtendSelection()
+	e.AdjustViewPort()
+}
+
+// ParagraphAt returns the first and last lines of the paragraph holding
+// line: the run of non-blank lines around it. ok is false on a blank line.
+func (d *Document) ParagraphAt(line int) (first, last int, ok bool) {
+	if line < 0 || line >= len(d.lines) || isBlankLine(d.lines[line].text) {
+		return line, line, false
+	}
+	first, last = line, line
+	for first > 0 && !isBlankLine(d.lines[first-1].text) {
+		first--
+	}
+	for last < len(d.lines)-1 && !isBlankLine(d.lines[last+1].text) {
+		last++
+	}
+	return first, last, true
+}
+
+func isBlankLine(text string) bool {
+	return strings.TrimSpace(text) == ""
+}
@@ -0,0 +1,95 @@
+package ast
+
+import (
+	"strings"
+)
+
+// SetextUnderline reports the heading level a setext underline line
+// denotes: 1 for "===", 2 for "---", 0 if text is not an underline.
+func SetextUnderline(text string) int {
+	trimmed := strings.TrimRight(text, " \t")
+	indent := len(trimmed) - len(strings.TrimLeft(trimmed, " "))
+	if indent > 3 || indent == len(trimmed) {
+		return 0
+	}
+
+	run := trimmed[indent:]
+	switch {
+	case strings.Trim(run, "=") == "":
+		return 1
+	case strings.Trim(run, "-") == "":
+		return 2
+	}
+	return 0
+}
+
+// SetextHeadingAt reports whether line belongs to a setext heading,
+// returning its level and whether line is the underline (rather than the
+// heading text). Level is 0 if line is not part of one.
+func (d *Document) SetextHeadingAt(line int) (level int, underline bool) {
+	if line < 0 || line >= len(d.lines) {
+		return 0, false
+	}
+
+	if level := d.setextLevel(line); level > 0 {
+		return level, false
+	}
+	if line > 0 {
+		if level := d.setextLevel(line - 1); level > 0 {
+			return level, true
+		}
+	}
+	return 0, false
+}
+
+// setextLevel returns the level of the setext heading whose text is on
+// line, or 0 if line is not heading text.
+func (d *Document) setextLevel(line int) int {
+	if line+1 >= len(d.lines) || !isParagraphText(d.lines[line].text) {
+		return 0
+	}
+
+	level := SetextUnderline(d.lines[line+1].text)
+	if level == 0 {
+		return 0
+	}
+	// A lone "-" could be an empty list item; require a run of two
+	if level == 2 && strings.Count(d.lines[line+1].text, "-") < 2 {
+		return 0
+	}
+	if _, inFence := d.FencedBlockAt(line); inFence {
+		return 0
+	}
+	return level
+}
+
+// isParagraphText reports whether text could be a paragraph line, as
+// opposed to blank or a block construct with its own syntax.
+func isParagraphText(text string) bool {
+	trimmed := strings.TrimSpace(text)
+	if trimmed == "" || strings.HasPrefix(text, "    ") || strings.HasPrefix(text, "\t") {
+		return false
+	}
+	if isATXHeading(trimmed) || strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+		return false
+	}
+	if depth, _, _ := QuoteDepth(text); depth > 0 {
+		return false
+	}
+	if _, ok := ParseListItem(text); ok {
+		return false
+	}
+	if _, _, ok := DefinitionMarker(text); ok {
+		return false
+	}
+	return SetextUnderline(text) == 0
+}
+
+// isATXHeading reports whether trimmed text is a "# Heading" line.
+func isATXHeading(trimmed string) bool {
+	hashes := len(trimmed) - len(strings.TrimLeft(trimmed, "#"))
+	if hashes == 0 || hashes > 6 {
+		return false
+	}
+	return hashes == len(trimmed) || trimmed[hashes] == ' ' || trimmed[hashes] == '\t'
+}
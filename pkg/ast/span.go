@@ -0,0 +1,159 @@
+package ast
+
+import (
+	"cmp"
+	"slices"
+	"unicode/utf8"
+)
+
+// SpanKind is the kind of block a Span covers.
+type SpanKind int
+
+const (
+	SpanFence SpanKind = iota // Fenced code block, fences included
+	SpanMath                  // Display math between $$ lines, delimiters included
+	SpanHTML                  // Raw HTML block
+	SpanQuote                 // Run of blockquote lines at one nesting depth
+)
+
+// String returns the kind's name, as in "fence".
+func (k SpanKind) String() string {
+	switch k {
+	case SpanFence:
+		return "fence"
+	case SpanMath:
+		return "math"
+	case SpanHTML:
+		return "html"
+	case SpanQuote:
+		return "quote"
+	default:
+		return "unknown"
+	}
+}
+
+// Span is a block running across lines, from Start to End (exclusive),
+// which per-line tokens cannot describe: a fenced block, display math,
+// an HTML block or a blockquote chain. Columns are runes; End is the end
+// of the block's last line.
+type Span struct {
+	Kind  SpanKind
+	Start BufferPos
+	End   BufferPos
+	Depth int    // Quote nesting depth, 1 for the outermost; 0 for other kinds
+	Info  string // Fence info string, e.g. "go"
+}
+
+// Contains reports whether line falls within the span.
+func (s Span) Contains(line int) bool {
+	return line >= s.Start.Line && line <= s.End.Line
+}
+
+// spanCache holds the spans of the document as of an edit revision.
+type spanCache struct {
+	revision int
+	valid    bool
+	spans    []Span
+}
+
+// Spans returns the document's block spans, ordered by start, a span
+// before the ones nested in it. They are found again only after an edit.
+func (d *Document) Spans() []Span {
+	return slices.Clone(d.spans())
+}
+
+// SpansAt returns the spans containing line, outermost first.
+func (d *Document) SpansAt(line int) []Span {
+	var at []Span
+	for _, span := range d.spans() {
+		if span.Start.Line > line {
+			break
+		}
+		if span.Contains(line) {
+			at = append(at, span)
+		}
+	}
+	return at
+}
+
+// spans returns the cached spans; callers must not modify the result.
+func (d *Document) spans() []Span {
+	if d.blocks.valid && d.blocks.revision == d.revision {
+		return d.blocks.spans
+	}
+
+	var spans []Span
+	fences := d.fencedBlocks()
+	for _, fence := range fences {
+		spans = append(spans, d.lineSpan(SpanFence, fence.StartLine, fence.EndLine, fence.Info))
+	}
+	for _, block := range d.MathBlocks() {
+		spans = append(spans, d.lineSpan(SpanMath, block.StartLine, block.EndLine, ""))
+	}
+	for _, block := range d.htmlBlocks() {
+		spans = append(spans, d.lineSpan(SpanHTML, block.StartLine, block.EndLine, ""))
+	}
+	spans = append(spans, d.quoteSpans(fences)...)
+
+	slices.SortStableFunc(spans, func(a, b Span) int {
+		return cmp.Or(
+			a.Start.Line-b.Start.Line, a.Start.Col-b.Start.Col,
+			b.End.Line-a.End.Line, b.End.Col-a.End.Col,
+		)
+	})
+	d.blocks = spanCache{revision: d.revision, valid: true, spans: spans}
+	return spans
+}
+
+// lineSpan returns a span of kind over lines first to last, starting at
+// the first line's indentation.
+func (d *Document) lineSpan(kind SpanKind, first, last int, info string) Span {
+	text := d.lines[first].text
+	indent := 0
+	for indent < len(text) && text[indent] == ' ' {
+		indent++
+	}
+	return Span{
+		Kind:  kind,
+		Start: BufferPos{Line: first, Col: indent},
+		End:   BufferPos{Line: last, Col: d.lines[last].length},
+		Info:  info,
+	}
+}
+
+// quoteSpans returns a span for each run of consecutive lines quoted at
+// least depth deep, for every depth, starting at that depth's marker.
+// Quote markers inside fenced blocks are code, not quotes.
+func (d *Document) quoteSpans(fences []FencedBlock) []Span {
+	var spans []Span
+	var open []Span // Open spans by depth - 1
+	closeFrom := func(depth, line int) {
+		for len(open) > depth {
+			span := open[len(open)-1]
+			span.End = BufferPos{Line: line, Col: d.lines[line].length}
+			spans = append(spans, span)
+			open = open[:len(open)-1]
+		}
+	}
+
+	for i, line := range d.lines {
+		for len(fences) > 0 && fences[0].EndLine < i {
+			fences = fences[1:]
+		}
+		depth, markers := 0, []int(nil)
+		if len(fences) == 0 || !fences[0].Contains(i) {
+			depth, markers, _ = QuoteDepth(line.text)
+		}
+		if i > 0 {
+			closeFrom(depth, i-1)
+		}
+		for len(open) < depth {
+			col := utf8.RuneCountInString(line.text[:markers[len(open)]])
+			open = append(open, Span{Kind: SpanQuote, Start: BufferPos{Line: i, Col: col}, Depth: len(open) + 1})
+		}
+	}
+	if len(d.lines) > 0 {
+		closeFrom(0, len(d.lines)-1)
+	}
+	return spans
+}
@@ -0,0 +1,121 @@
+package ast
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// TextCount tallies words and characters.
+type TextCount struct {
+	Words int
+	Chars int // Every character except line breaks
+}
+
+func (c *TextCount) add(other TextCount) {
+	c.Words += other.Words
+	c.Chars += other.Chars
+}
+
+// SectionStats is the size of one section of the outline.
+type SectionStats struct {
+	Heading Heading   // Level 0 for the text before the first heading
+	Own     TextCount // The body up to the next heading of any level
+	Total   TextCount // Own plus every subsection
+}
+
+// SectionCounter breaks a document's size down by heading. It remembers
+// the count of each line's text, so after an edit only the lines that
+// changed are counted again. The zero value is ready to use.
+type SectionCounter struct {
+	counts map[string]TextCount
+}
+
+// Sections returns the statistics of each section of d in document order.
+// Text before the first heading is a section of its own when it has words.
+func (c *SectionCounter) Sections(d *Document) []SectionStats {
+	counts := make(map[string]TextCount, len(c.counts))
+	count := func(first, end int) TextCount {
+		return c.countLines(d, first, end, counts)
+	}
+
+	headings := d.Headings()
+	var sections []SectionStats
+	end := len(d.lines)
+	if len(headings) > 0 {
+		end = headings[0].Line
+	}
+	if preamble := count(0, end); preamble.Words > 0 {
+		sections = append(sections, SectionStats{Own: preamble, Total: preamble})
+	}
+
+	for i, h := range headings {
+		first, end := h.Line+1, len(d.lines)
+		if level, _ := d.SetextHeadingAt(h.Line); level > 0 {
+			first++ // Skip the underline
+		}
+		if i+1 < len(headings) {
+			end = headings[i+1].Line
+		}
+		own := count(min(first, end), end)
+		sections = append(sections, SectionStats{Heading: h, Own: own, Total: own})
+	}
+
+	// Add each section to the totals of the sections enclosing it, which
+	// are on the stack
+	var open []int
+	for i := range sections {
+		level := sections[i].Heading.Level
+		if level == 0 {
+			continue
+		}
+		for len(open) > 0 && sections[open[len(open)-1]].Heading.Level >= level {
+			open = open[:len(open)-1]
+		}
+		for _, j := range open {
+			sections[j].Total.add(sections[i].Own)
+		}
+		open = append(open, i)
+	}
+
+	c.counts = counts
+	return sections
+}
+
+// Total counts the whole document, headings included.
+func (c *SectionCounter) Total(d *Document) TextCount {
+	counts := make(map[string]TextCount, len(c.counts))
+	total := c.countLines(d, 0, len(d.lines), counts)
+	c.counts = counts
+	return total
+}
+
+// countLines totals lines first to end-1, taking known counts from the
+// last pass and recording every count in next.
+func (c *SectionCounter) countLines(d *Document, first, end int, next map[string]TextCount) TextCount {
+	var total TextCount
+	for i := first; i < end; i++ {
+		text := d.lines[i].text
+		n, ok := next[text]
+		if !ok {
+			if n, ok = c.counts[text]; !ok {
+				n = countText(text)
+			}
+			next[text] = n
+		}
+		total.add(n)
+	}
+	return total
+}
+
+// countText counts the words, runs of text containing a letter or digit,
+// and the characters in a line.
+func countText(line string) TextCount {
+	count := TextCount{Chars: utf8.RuneCountInString(line)}
+	for _, field := range strings.Fields(line) {
+		if strings.IndexFunc(field, func(r rune) bool { return unicode.IsLetter(r) || unicode.IsDigit(r) }) >= 0 {
+			count.Words++
+		}
+	}
+	return count
+}
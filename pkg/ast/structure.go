@@ -0,0 +1,227 @@
+package ast
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Range is a span of the document from Start up to, not including, End.
+type Range struct {
+	Start BufferPos
+	End   BufferPos
+}
+
+// Contains reports whether r covers all of other.
+func (r Range) Contains(other Range) bool {
+	return !other.Start.Before(r.Start) && !r.End.Before(other.End)
+}
+
+// WordAt returns the word (letters, digits and underscores) under pos, or
+// the one ending at pos when the cursor sits just after it.
+func (d *Document) WordAt(pos BufferPos) (Range, bool) {
+	pos = d.ValidatePosition(pos)
+	runes := []rune(d.GetLine(pos.Line))
+	isWord := func(col int) bool {
+		return col >= 0 && col < len(runes) && (unicode.IsLetter(runes[col]) || unicode.IsDigit(runes[col]) || runes[col] == '_')
+	}
+
+	col := pos.Col
+	if !isWord(col) {
+		if !isWord(col - 1) {
+			return Range{}, false
+		}
+		col--
+	}
+	start, end := col, col+1
+	for isWord(start - 1) {
+		start--
+	}
+	for isWord(end) {
+		end++
+	}
+	return Range{BufferPos{pos.Line, start}, BufferPos{pos.Line, end}}, true
+}
+
+// LineRange returns line including its line break, so deleting it removes
+// the line. The last line has no break and ends at its last character.
+func (d *Document) LineRange(line int) Range {
+	if line < len(d.lines)-1 {
+		return Range{BufferPos{line, 0}, BufferPos{line + 1, 0}}
+	}
+	return Range{BufferPos{line, 0}, BufferPos{line, d.GetLineLength(line)}}
+}
+
+// ParagraphRange returns the paragraph holding line, from the start of
+// its first line to the end of its last.
+func (d *Document) ParagraphRange(line int) (Range, bool) {
+	first, last, ok := d.ParagraphAt(line)
+	if !ok {
+		return Range{}, false
+	}
+	return Range{BufferPos{first, 0}, BufferPos{last, d.GetLineLength(last)}}, true
+}
+
+// SentenceAt returns the sentence around pos within its paragraph. A
+// sentence ends after ".", "!" or "?" followed by whitespace, or at the
+// end of the paragraph; leading whitespace is not part of it.
+func (d *Document) SentenceAt(pos BufferPos) (Range, bool) {
+	pos = d.ValidatePosition(pos)
+	first, last, ok := d.ParagraphAt(pos.Line)
+	if !ok {
+		return Range{}, false
+	}
+	var paragraph []string
+	for i := first; i <= last; i++ {
+		paragraph = append(paragraph, d.lines[i].text)
+	}
+
+	startLine, startCol, endLine, endCol := sentenceAt(paragraph, pos.Line-first, pos.Col)
+	return Range{BufferPos{first + startLine, startCol}, BufferPos{first + endLine, endCol}}, true
+}
+
+// sentenceAt finds the sentence around (line, col) in paragraph, returning
+// its start and end positions; the end column is exclusive.
+func sentenceAt(paragraph []string, line, col int) (startLine, startCol, endLine, endCol int) {
+	text := []rune(strings.Join(paragraph, "\n"))
+
+	offset := col
+	for i := 0; i < line; i++ {
+		offset += utf8.RuneCountInString(paragraph[i]) + 1
+	}
+	offset = min(offset, len(text))
+
+	isEnd := func(i int) bool {
+		return strings.ContainsRune(".!?", text[i]) && (i+1 == len(text) || unicode.IsSpace(text[i+1]))
+	}
+
+	start := 0
+	for i := offset - 1; i >= 0; i-- {
+		if isEnd(i) {
+			start = i + 1
+			break
+		}
+	}
+	for start < offset && unicode.IsSpace(text[start]) {
+		start++
+	}
+
+	end := len(text)
+	for i := offset; i < len(text); i++ {
+		if isEnd(i) {
+			end = i + 1
+			break
+		}
+	}
+
+	startLine, startCol = paragraphPosition(paragraph, start)
+	endLine, endCol = paragraphPosition(paragraph, end)
+	return startLine, startCol, endLine, endCol
+}
+
+// paragraphPosition converts a rune offset in the newline-joined paragraph
+// into a line and column.
+func paragraphPosition(paragraph []string, offset int) (int, int) {
+	for i, line := range paragraph {
+		length := utf8.RuneCountInString(line)
+		if offset <= length {
+			return i, offset
+		}
+		offset -= length + 1
+	}
+	last := len(paragraph) - 1
+	return last, utf8.RuneCountInString(paragraph[last])
+}
+
+// ListItemAt returns the list item holding line: its marker line, any
+// continuation lines and nested items, up to the next item at the same or
+// a shallower indent or a blank line.
+func (d *Document) ListItemAt(line int) (Range, bool) {
+	if line < 0 || line >= len(d.lines) {
+		return Range{}, false
+	}
+
+	first := line
+	item, ok := ParseListItem(d.lines[first].text)
+	for !ok {
+		if first == 0 || isBlankLine(d.lines[first-1].text) {
+			return Range{}, false
+		}
+		first--
+		item, ok = ParseListItem(d.lines[first].text)
+	}
+
+	last := first
+	for last+1 < len(d.lines) && !isBlankLine(d.lines[last+1].text) {
+		if next, ok := ParseListItem(d.lines[last+1].text); ok && next.Indent <= item.Indent {
+			break
+		}
+		last++
+	}
+	return Range{BufferPos{first, 0}, BufferPos{last, d.GetLineLength(last)}}, true
+}
+
+// HeadingLevel returns the level of the ATX or setext heading whose text
+// is on line, or 0. Lines inside fenced code are never headings.
+func (d *Document) HeadingLevel(line int) int {
+	if line < 0 || line >= len(d.lines) {
+		return 0
+	}
+	if level, underline := d.SetextHeadingAt(line); level > 0 {
+		if underline {
+			return 0
+		}
+		return level
+	}
+
+	level := atxLevel(d.lines[line].text)
+	if level == 0 {
+		return 0
+	}
+	if _, inFence := d.FencedBlockAt(line); inFence {
+		return 0
+	}
+	return level
+}
+
+// atxLevel returns the level of the ATX heading on line, which may be
+// inside a code block, or 0 if it is not one.
+func atxLevel(line string) int {
+	text := strings.TrimLeft(line, " ")
+	if len(line)-len(text) > 3 {
+		return 0
+	}
+	level := len(text) - len(strings.TrimLeft(text, "#"))
+	if level < 1 || level > 6 || (len(text) > level && text[level] != ' ' && text[level] != '\t') {
+		return 0
+	}
+	return level
+}
+
+// SectionAt returns the section holding line: the nearest heading at or
+// above it and everything up to the next heading of the same or a higher
+// level. ok is false above the first heading.
+func (d *Document) SectionAt(line int) (Range, bool) {
+	first, level := line, 0
+	for ; first >= 0; first-- {
+		if level = d.HeadingLevel(first); level > 0 {
+			break
+		}
+	}
+	if first < 0 {
+		return Range{}, false
+	}
+
+	last := len(d.lines) - 1
+	for i := first + 1; i < len(d.lines); i++ {
+		if next := d.HeadingLevel(i); next > 0 && next <= level {
+			last = i - 1
+			break
+		}
+	}
+	// Trailing blank lines separate sections rather than belong to them
+	for last > first && isBlankLine(d.lines[last].text) {
+		last--
+	}
+	return Range{BufferPos{first, 0}, BufferPos{last, d.GetLineLength(last)}}, true
+}
@@ -0,0 +1,413 @@
+package ast
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"github.com/charmbracelet/x/ansi"
+)
+
+// Alignment is a pipe table column's alignment, from its delimiter row.
+type Alignment int
+
+const (
+	AlignNone   Alignment = iota // "---"
+	AlignLeft                    // ":--"
+	AlignCenter                  // ":-:"
+	AlignRight                   // "--:"
+)
+
+// Table is a GitHub-flavored pipe table: a header row, a delimiter row
+// setting column alignment, and any number of body rows.
+type Table struct {
+	Start  int         // Line of the header row
+	End    int         // Last line of the table, inclusive
+	Indent string      // Whitespace before the rows
+	Rows   [][]string  // Trimmed cell text; Rows[0] is the header
+	Align  []Alignment // One per column
+}
+
+// Columns returns the number of columns.
+func (t Table) Columns() int {
+	return len(t.Align)
+}
+
+// Line returns the line holding row; the delimiter row is not a row.
+func (t Table) Line(row int) int {
+	if row == 0 {
+		return t.Start
+	}
+	return t.Start + row + 1
+}
+
+// Row returns the row on line, counting the delimiter row as the header.
+func (t Table) Row(line int) int {
+	return max(line-t.Start-1, 0)
+}
+
+// TableAt returns the pipe table holding line. Rows are consecutive lines
+// containing "|", and the table starts with the header just above the
+// first delimiter row among them.
+func (d *Document) TableAt(line int) (Table, bool) {
+	if line < 0 || line >= len(d.lines) || !isTableLine(d.lines[line].text) {
+		return Table{}, false
+	}
+	if _, inFence := d.FencedBlockAt(line); inFence {
+		return Table{}, false
+	}
+
+	first, last := line, line
+	for first > 0 && isTableLine(d.lines[first-1].text) {
+		first--
+	}
+	for last+1 < len(d.lines) && isTableLine(d.lines[last+1].text) {
+		last++
+	}
+
+	start := -1
+	for i := first + 1; i <= last; i++ {
+		if isDelimiterRow(d.lines[i].text) && len(splitRow(d.lines[i].text)) == len(splitRow(d.lines[i-1].text)) {
+			start = i - 1
+			break
+		}
+	}
+	if start < 0 || line < start {
+		return Table{}, false
+	}
+
+	table := Table{Start: start, End: last, Indent: LeadingWhitespace(d.lines[start].text)}
+	for _, cell := range splitRow(d.lines[start+1].text) {
+		table.Align = append(table.Align, parseAlignment(cell))
+	}
+	for i := start; i <= last; i++ {
+		if i == start+1 {
+			continue
+		}
+		cells := splitRow(d.lines[i].text)
+		for len(table.Align) < len(cells) {
+			table.Align = append(table.Align, AlignNone)
+		}
+		table.Rows = append(table.Rows, cells)
+	}
+	for i := range table.Rows {
+		for len(table.Rows[i]) < table.Columns() {
+			table.Rows[i] = append(table.Rows[i], "")
+		}
+	}
+	return table, true
+}
+
+// Format lays the table out with its pipes aligned: each column padded to
+// its widest cell, following the column's alignment.
+func (t Table) Format() []string {
+	widths := t.widths()
+	lines := make([]string, 0, len(t.Rows)+1)
+	for r, row := range t.Rows {
+		cells := make([]string, len(row))
+		for c, cell := range row {
+			cells[c] = padCell(cell, widths[c], t.Align[c])
+		}
+		lines = append(lines, t.Indent+"| "+strings.Join(cells, " | ")+" |")
+
+		if r == 0 {
+			delimiters := make([]string, len(widths))
+			for c, width := range widths {
+				delimiters[c] = delimiterCell(width, t.Align[c])
+			}
+			lines = append(lines, t.Indent+"| "+strings.Join(delimiters, " | ")+" |")
+		}
+	}
+	return lines
+}
+
+// widths returns each column's width in terminal cells: its widest cell,
+// at least three so the delimiter row can show any alignment. Wide
+// characters such as CJK and emoji take two cells.
+func (t Table) widths() []int {
+	widths := make([]int, t.Columns())
+	for c := range widths {
+		widths[c] = 3
+		for _, row := range t.Rows {
+			widths[c] = max(widths[c], ansi.StringWidth(row[c]))
+		}
+	}
+	return widths
+}
+
+// cellColumn returns the rune offset of a cell's text within a line laid
+// out by Format.
+func (t Table) cellColumn(row, col int) int {
+	widths := t.widths()
+	offset := utf8.RuneCountInString(t.Indent) + 2
+	for c := 0; c < col; c++ {
+		cell := t.Rows[row][c]
+		offset += utf8.RuneCountInString(cell) + widths[c] - ansi.StringWidth(cell) + 3
+	}
+	space := widths[col] - ansi.StringWidth(t.Rows[row][col])
+	switch t.Align[col] {
+	case AlignRight:
+		offset += space
+	case AlignCenter:
+		offset += space / 2
+	}
+	return offset
+}
+
+func padCell(cell string, width int, align Alignment) string {
+	space := width - ansi.StringWidth(cell)
+	switch align {
+	case AlignRight:
+		return strings.Repeat(" ", space) + cell
+	case AlignCenter:
+		return strings.Repeat(" ", space/2) + cell + strings.Repeat(" ", space-space/2)
+	}
+	return cell + strings.Repeat(" ", space)
+}
+
+func delimiterCell(width int, align Alignment) string {
+	switch align {
+	case AlignLeft:
+		return ":" + strings.Repeat("-", width-1)
+	case AlignCenter:
+		return ":" + strings.Repeat("-", width-2) + ":"
+	case AlignRight:
+		return strings.Repeat("-", width-1) + ":"
+	}
+	return strings.Repeat("-", width)
+}
+
+func parseAlignment(cell string) Alignment {
+	left, right := strings.HasPrefix(cell, ":"), strings.HasSuffix(cell, ":")
+	switch {
+	case left && right:
+		return AlignCenter
+	case left:
+		return AlignLeft
+	case right:
+		return AlignRight
+	}
+	return AlignNone
+}
+
+// isTableLine reports whether text could be a table row.
+func isTableLine(text string) bool {
+	return strings.Contains(text, "|") && strings.TrimSpace(text) != ""
+}
+
+// isDelimiterRow reports whether text is a row of "---", ":--", "--:" or
+// ":-:" cells.
+func isDelimiterRow(text string) bool {
+	if !strings.Contains(text, "-") {
+		return false
+	}
+	cells := splitRow(text)
+	for _, cell := range cells {
+		dashes := strings.TrimSuffix(strings.TrimPrefix(cell, ":"), ":")
+		if dashes == "" || strings.Trim(dashes, "-") != "" {
+			return false
+		}
+	}
+	return len(cells) > 0
+}
+
+// splitRow splits a table row into trimmed cells at unescaped pipes,
+// dropping the optional leading and trailing pipe.
+func splitRow(text string) []string {
+	bounds := cellBounds(text)
+	cells := make([]string, len(bounds))
+	for i, b := range bounds {
+		cells[i] = strings.TrimSpace(text[b[0]:b[1]])
+	}
+	return cells
+}
+
+// cellBounds returns the byte range of each cell of a table row, between
+// its pipes.
+func cellBounds(text string) [][2]int {
+	trimmed := strings.TrimSpace(text)
+	start := len(text) - len(strings.TrimLeft(text, " \t"))
+	end := start + len(trimmed)
+	if strings.HasPrefix(trimmed, "|") {
+		start++
+	}
+	if strings.HasSuffix(trimmed, "|") && !strings.HasSuffix(trimmed, `\|`) && end > start {
+		end--
+	}
+
+	var bounds [][2]int
+	cell := start
+	for i := start; i < end; i++ {
+		switch text[i] {
+		case '\\':
+			i++
+		case '|':
+			bounds = append(bounds, [2]int{cell, i})
+			cell = i + 1
+		}
+	}
+	return append(bounds, [2]int{cell, end})
+}
+
+// tableCell finds the cell holding pos in table and the cursor's offset
+// within the cell's trimmed text.
+func (d *Document) tableCell(table Table, pos BufferPos) (row, col, offset int) {
+	row = table.Row(pos.Line)
+	if pos.Line == table.Start+1 {
+		return row, 0, 0
+	}
+
+	text := d.lines[pos.Line].text
+	at := ByteOffset(text, pos.Col)
+	bounds := cellBounds(text)
+	for col < len(bounds)-1 && at > bounds[col][1] {
+		col++
+	}
+	cell := text[bounds[col][0]:bounds[col][1]]
+	content := bounds[col][0] + len(cell) - len(strings.TrimLeft(cell, " \t"))
+	offset = RuneOffset(text, max(at, content)) - RuneOffset(text, content)
+	return row, min(col, table.Columns()-1), offset
+}
+
+// writeTable replaces the table's lines with its formatted rows and puts
+// the cursor offset runes into the given cell.
+func (e *Editor) writeTable(table Table, oldEnd, row, col, offset int) {
+	e.document.replaceLines(table.Start, oldEnd+1, table.Format())
+	offset = min(offset, utf8.RuneCountInString(table.Rows[row][col]))
+	e.cursorManager.ClearSelection()
+	e.cursorManager.SetBufferPos(BufferPos{Line: table.Line(row), Col: table.cellColumn(row, col) + offset})
+	e.AdjustViewPort()
+}
+
+// tableAtCursor returns the table holding the cursor and the cursor's cell.
+func (e *Editor) tableAtCursor() (table Table, row, col, offset int, ok bool) {
+	pos := e.cursorManager.GetBufferPos()
+	table, ok = e.document.TableAt(pos.Line)
+	if !ok {
+		return table, 0, 0, 0, false
+	}
+	row, col, offset = e.document.tableCell(table, pos)
+	return table, row, col, offset, true
+}
+
+// FormatTable realigns the pipes of the table holding the cursor, keeping
+// the cursor in its cell. Returns false outside a table.
+func (e *Editor) FormatTable() bool {
+	table, row, col, offset, ok := e.tableAtCursor()
+	if ok {
+		e.writeTable(table, table.End, row, col, offset)
+	}
+	return ok
+}
+
+// TableNextCell realigns the table and moves to the end of the next cell's
+// text, adding a row after the last cell. Returns false outside a table.
+func (e *Editor) TableNextCell() bool {
+	table, row, col, _, ok := e.tableAtCursor()
+	if !ok {
+		return false
+	}
+	end := table.End
+	if col++; col == table.Columns() {
+		row, col = row+1, 0
+	}
+	if row == len(table.Rows) {
+		table.Rows = append(table.Rows, make([]string, table.Columns()))
+		table.End++
+	}
+	e.writeTable(table, end, row, col, utf8.RuneCountInString(table.Rows[row][col]))
+	return true
+}
+
+// TablePrevCell realigns the table and moves to the end of the previous
+// cell's text. Returns false outside a table.
+func (e *Editor) TablePrevCell() bool {
+	table, row, col, offset, ok := e.tableAtCursor()
+	if !ok {
+		return false
+	}
+	if row > 0 || col > 0 {
+		if col--; col < 0 {
+			row, col = row-1, table.Columns()-1
+		}
+		offset = utf8.RuneCountInString(table.Rows[row][col])
+	}
+	e.writeTable(table, table.End, row, col, offset)
+	return true
+}
+
+// TableNewRow adds an empty row below the cursor's row (below the
+// delimiter from the header) and moves to its first cell. On an empty last
+// row it ends the table instead, replacing the row with a blank line.
+// Returns false outside a table.
+func (e *Editor) TableNewRow() bool {
+	table, row, _, _, ok := e.tableAtCursor()
+	if !ok {
+		return false
+	}
+	end := table.End
+	if row > 0 && row == len(table.Rows)-1 && strings.Join(table.Rows[row], "") == "" {
+		table.Rows = table.Rows[:row]
+		e.document.replaceLines(table.Start, end+1, append(table.Format(), ""))
+		e.cursorManager.ClearSelection()
+		e.cursorManager.SetBufferPos(BufferPos{Line: end})
+		e.AdjustViewPort()
+		return true
+	}
+
+	row++
+	table.Rows = append(table.Rows[:row], append([][]string{make([]string, table.Columns())}, table.Rows[row:]...)...)
+	table.End++
+	e.writeTable(table, end, row, 0, 0)
+	return true
+}
+
+// TableInsertColumn adds an empty column after the cursor's and moves
+// into it. Returns false outside a table.
+func (e *Editor) TableInsertColumn() bool {
+	table, row, col, _, ok := e.tableAtCursor()
+	if !ok {
+		return false
+	}
+	col++
+	table.Align = insertAt(table.Align, col, AlignNone)
+	for r := range table.Rows {
+		table.Rows[r] = insertAt(table.Rows[r], col, "")
+	}
+	e.writeTable(table, table.End, row, col, 0)
+	return true
+}
+
+// TableDeleteColumn removes the cursor's column. Returns false outside a
+// table or when it is the only column.
+func (e *Editor) TableDeleteColumn() bool {
+	table, row, col, _, ok := e.tableAtCursor()
+	if !ok || table.Columns() == 1 {
+		return false
+	}
+	table.Align = append(table.Align[:col], table.Align[col+1:]...)
+	for r := range table.Rows {
+		table.Rows[r] = append(table.Rows[r][:col], table.Rows[r][col+1:]...)
+	}
+	e.writeTable(table, table.End, row, min(col, table.Columns()-1), 0)
+	return true
+}
+
+// TableAlignColumn sets the alignment of the cursor's column. Returns
+// false outside a table.
+func (e *Editor) TableAlignColumn(align Alignment) bool {
+	table, row, col, offset, ok := e.tableAtCursor()
+	if !ok {
+		return false
+	}
+	table.Align[col] = align
+	e.writeTable(table, table.End, row, col, offset)
+	return true
+}
+
+func insertAt[T any](s []T, i int, v T) []T {
+	var zero T
+	s = append(s, zero)
+	copy(s[i+1:], s[i:])
+	s[i] = v
+	return s
+}
@@ -0,0 +1,202 @@
+package ast
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"unicode/utf8"
+)
+
+// Heading is an entry in the document outline.
+type Heading struct {
+	Line   int    // Line of the heading text
+	Level  int    // 1 to 6
+	Text   string // Heading text without its # markers or underline
+	Anchor string // GitHub-style anchor, unique within the document
+}
+
+// Headings lists the document's ATX and setext headings in order.
+// Repeated anchors get "-1", "-2", ... suffixes, as on GitHub.
+func (d *Document) Headings() []Heading {
+	return slices.Clone(d.headings())
+}
+
+// outlineCache holds the headings of the document as of an edit revision.
+type outlineCache struct {
+	revision int
+	valid    bool
+	headings []Heading
+}
+
+// headings returns the document's headings, found again only after an
+// edit. Callers must not modify the result.
+func (d *Document) headings() []Heading {
+	if d.outline.valid && d.outline.revision == d.revision {
+		return d.outline.headings
+	}
+
+	var headings []Heading
+	seen := make(map[string]int)
+	fences := d.fencedBlocks()
+	for i := range d.lines {
+		level, underline := d.SetextHeadingAt(i)
+		if underline {
+			continue
+		}
+		if level == 0 {
+			// Fences are in line order, so those ending above i are done
+			for len(fences) > 0 && fences[0].EndLine < i {
+				fences = fences[1:]
+			}
+			if level = atxLevel(d.lines[i].text); level == 0 || (len(fences) > 0 && fences[0].Contains(i)) {
+				continue
+			}
+		}
+		text := headingText(d.lines[i].text)
+		anchor := HeadingSlug(text)
+		if n := seen[anchor]; n > 0 {
+			seen[anchor]++
+			anchor = fmt.Sprintf("%s-%d", anchor, n)
+		} else {
+			seen[anchor] = 1
+		}
+		headings = append(headings, Heading{Line: i, Level: level, Text: text, Anchor: anchor})
+	}
+	d.outline = outlineCache{revision: d.revision, valid: true, headings: headings}
+	return headings
+}
+
+// HeadingSlug mirrors GitHub's heading anchors: lowercase, punctuation
+// removed, spaces turned into dashes.
+func HeadingSlug(heading string) string {
+	var slug strings.Builder
+	for _, r := range strings.ToLower(strings.TrimSpace(heading)) {
+		switch {
+		case r == ' ':
+			slug.WriteRune('-')
+		case r == '-' || r == '_' || r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || r >= utf8.RuneSelf:
+			slug.WriteRune(r)
+		}
+	}
+	return slug.String()
+}
+
+// headingText strips the ATX markers, including an optional closing
+// sequence, from a heading line; setext heading text is only trimmed.
+func headingText(line string) string {
+	text := strings.TrimSpace(line)
+	if !isATXHeading(text) {
+		return text
+	}
+	text = strings.TrimSpace(strings.TrimLeft(text, "#"))
+	if closing := strings.TrimRight(text, "#"); closing != text && (closing == "" || strings.HasSuffix(closing, " ")) {
+		text = strings.TrimSpace(closing)
+	}
+	return text
+}
+
+// TableOfContents builds a nested Markdown list linking to headings,
+// indented relative to the shallowest one.
+func TableOfContents(headings []Heading) []string {
+	top := maxHeadingLevel
+	for _, heading := range headings {
+		top = min(top, heading.Level)
+	}
+
+	escaper := strings.NewReplacer("[", `\[`, "]", `\]`)
+	lines := make([]string, len(headings))
+	for i, heading := range headings {
+		indent := strings.Repeat("  ", heading.Level-top)
+		lines[i] = fmt.Sprintf("%s- [%s](#%s)", indent, escaper.Replace(heading.Text), heading.Anchor)
+	}
+	return lines
+}
+
+// TOCBlock finds a table of contents between lines holding exactly the
+// start and end markers, outside fenced code. ok is false if either
+// marker is missing.
+func (d *Document) TOCBlock(startMarker, endMarker string) (start, end int, ok bool) {
+	start = -1
+	for i := range d.lines {
+		text := strings.TrimSpace(d.lines[i].text)
+		if text != startMarker && text != endMarker {
+			continue
+		}
+		if _, inFence := d.FencedBlockAt(i); inFence {
+			continue
+		}
+		if start < 0 && text == startMarker {
+			start = i
+		} else if start >= 0 && text == endMarker {
+			return start, i, true
+		}
+	}
+	return 0, 0, false
+}
+
+// UpdateTOC regenerates the table of contents between the markers from
+// the document's headings. Without markers it inserts a new table, with
+// its markers, above the cursor line when insert is set. Returns false if
+// there was nothing to update.
+func (e *Editor) UpdateTOC(startMarker, endMarker string, insert bool) bool {
+	toc := TableOfContents(e.document.Headings())
+
+	start, end, ok := e.document.TOCBlock(startMarker, endMarker)
+	if !ok {
+		if !insert {
+			return false
+		}
+		line := e.cursorManager.GetBufferPos().Line
+		block := append(append([]string{startMarker}, toc...), endMarker)
+		e.remapLines(e.document.replaceLines(line, line, block))
+		return true
+	}
+
+	if slices.Equal(toc, e.document.lineTexts(start+1, end)) {
+		return true
+	}
+	e.remapLines(e.document.replaceLines(start+1, end, toc))
+	return true
+}
+
+// remapLines moves the cursor and selection through an edit of whole
+// lines, given where each old line now is.
+func (e *Editor) remapLines(remap func(int) int) {
+	e.remapPositions(func(pos BufferPos) BufferPos {
+		pos.Line = remap(pos.Line)
+		return e.document.ValidatePosition(pos)
+	})
+	e.AdjustViewPort()
+}
+
+// lineTexts returns the text of lines [first, end).
+func (d *Document) lineTexts(first, end int) []string {
+	texts := make([]string, 0, end-first)
+	for _, line := range d.lines[first:end] {
+		texts = append(texts, line.text)
+	}
+	return texts
+}
+
+// replaceLines replaces lines [first, end) with lines and returns where
+// each old line now is; replaced lines map to first.
+func (d *Document) replaceLines(first, end int, lines []string) func(int) int {
+	replacement := make([]Line, len(lines))
+	for i, text := range lines {
+		replacement[i] = Line{text: text, length: utf8.RuneCountInString(text)}
+	}
+	d.lines = append(d.lines[:first], append(replacement, d.lines[end:]...)...)
+	d.modified = true
+	d.linesChanged(first)
+
+	shift := len(lines) - (end - first)
+	return func(line int) int {
+		switch {
+		case line >= end:
+			return line + shift
+		case line >= first:
+			return first
+		}
+		return line
+	}
+}
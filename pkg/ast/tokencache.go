@@ -0,0 +1,100 @@
+package ast
+
+import (
+	"container/list"
+	"unsafe"
+)
+
+// DefaultTokenBudget is the memory a document spends on syntax tokens
+// until SetTokenBudget changes it.
+const DefaultTokenBudget = 8 << 20
+
+// tokenSize is the memory one token takes
+const tokenSize = int(unsafe.Sizeof(Token{}))
+
+// tokenEntry holds the tokens of one line, computed for its text at the
+// time. Tokens for other text are stale and never returned.
+type tokenEntry struct {
+	text   string
+	tokens []Token
+	elem   *list.Element // Position in the cache; nil once evicted
+}
+
+// tokenCache keeps the tokens of the most recently used lines within a
+// memory budget, evicting the least recently used ones beyond it. Evicted
+// lines are tokenized again when next asked for. The zero value is ready
+// to use, with DefaultTokenBudget.
+type tokenCache struct {
+	budget   int // Bytes; 0 means DefaultTokenBudget
+	size     int
+	lru      list.List // *tokenEntry, most recently used first
+	tokenize func(line string) []Token
+}
+
+func (c *tokenCache) limit() int {
+	if c.budget > 0 {
+		return c.budget
+	}
+	return DefaultTokenBudget
+}
+
+// add caches tokens computed for text and returns their entry
+func (c *tokenCache) add(text string, tokens []Token) *tokenEntry {
+	entry := &tokenEntry{text: text, tokens: tokens}
+	entry.elem = c.lru.PushFront(entry)
+	c.size += len(tokens) * tokenSize
+	c.evict()
+	return entry
+}
+
+// touch marks entry as just used
+func (c *tokenCache) touch(entry *tokenEntry) {
+	c.lru.MoveToFront(entry.elem)
+}
+
+// remove drops entry from the cache
+func (c *tokenCache) remove(entry *tokenEntry) {
+	if entry == nil || entry.elem == nil {
+		return
+	}
+	c.lru.Remove(entry.elem)
+	c.size -= len(entry.tokens) * tokenSize
+	entry.elem = nil
+	entry.tokens = nil
+}
+
+// evict removes the least recently used entries until the cache fits its
+// budget, always keeping the newest.
+func (c *tokenCache) evict() {
+	for c.size > c.limit() && c.lru.Len() > 1 {
+		c.remove(c.lru.Back().Value.(*tokenEntry))
+	}
+}
+
+// SetTokenizer sets how GetLineTokens computes tokens for lines that have
+// none cached. Long lines are never tokenized.
+func (d *Document) SetTokenizer(tokenize func(line string) []Token) {
+	d.tokens.tokenize = tokenize
+}
+
+// SetTokenBudget bounds the memory spent on cached tokens, in bytes,
+// evicting the least recently used lines' tokens to fit.
+func (d *Document) SetTokenBudget(bytes int) {
+	d.tokens.budget = bytes
+	d.tokens.evict()
+}
+
+// CachedTokenBytes returns the memory taken by cached tokens.
+func (d *Document) CachedTokenBytes() int {
+	return d.tokens.size
+}
+
+// HasLineTokens reports whether tokens for the current text of line
+// lineNum are cached.
+func (d *Document) HasLineTokens(lineNum int) bool {
+	if lineNum < 0 || lineNum >= len(d.lines) {
+		return false
+	}
+	entry := d.lines[lineNum].tokens
+	return entry != nil && entry.elem != nil && entry.text == d.lines[lineNum].text
+}
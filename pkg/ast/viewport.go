@@ -14,11 +14,18 @@
 //
 // TRANSFORMATION FORMULA:
 //   screenRow = bufferPos.Line - viewport.topLine
-//   screenCol = bufferPos.Col - viewport.leftColumn + viewport.lineNumberWidth
+//   screenCol = displayCol - viewport.leftColumn + viewport.lineNumberWidth
+//
+// TABS:
+//   A tab fills the cells up to the next multiple of tabWidth, so a buffer
+//   column (a rune offset) and its display column differ after a tab.
+//   leftColumn and screen columns are display columns; use the *OnLine
+//   methods, which see the line's text, wherever the line may hold tabs.
 package ast
 
 import (
 	"fmt"
+	"strings"
 )
 
 // Viewport represents the visible area of the document and provides
@@ -61,6 +68,87 @@ func (v *Viewport) BufferToScreen(pos BufferPos) (ScreenPos, error) {
 	return ScreenPos{Row: screenRow, Col: screenCol}, nil
 }
 
+// BufferToScreenOnLine converts a buffer position on line (the text of
+// pos.Line) to a screen position, expanding tabs before pos.Col.
+// RETURNS: ScreenPos if visible, ErrPositionNotVisible if off-screen
+func (v *Viewport) BufferToScreenOnLine(pos BufferPos, line string) (ScreenPos, error) {
+	return v.BufferToScreen(BufferPos{Line: pos.Line, Col: v.DisplayColumn(line, pos.Col)})
+}
+
+// DisplayColumn returns the display column of buffer column col in line:
+// the number of cells before it once tabs are expanded.
+func (v *Viewport) DisplayColumn(line string, col int) int {
+	return DisplayColumn(line, col, v.tabWidth)
+}
+
+// BufferColumn returns the buffer column whose cell covers display column
+// displayCol in line. A column inside a tab maps to the tab; columns past
+// the end of the line map past its last rune one for one.
+func (v *Viewport) BufferColumn(line string, displayCol int) int {
+	return BufferColumn(line, displayCol, v.tabWidth)
+}
+
+// DisplayColumn returns the display column of buffer column col in line,
+// with tab stops every tabWidth cells. Columns past the end of the line
+// count one cell each.
+func DisplayColumn(line string, col, tabWidth int) int {
+	display := 0
+	i := 0
+	for _, r := range line {
+		if i >= col {
+			return display
+		}
+		display = nextCell(display, r, tabWidth)
+		i++
+	}
+	return display + max(col-i, 0)
+}
+
+// BufferColumn is the inverse of DisplayColumn: the buffer column whose
+// cell covers displayCol.
+func BufferColumn(line string, displayCol, tabWidth int) int {
+	display := 0
+	i := 0
+	for _, r := range line {
+		next := nextCell(display, r, tabWidth)
+		if displayCol < next {
+			return i
+		}
+		display = next
+		i++
+	}
+	return i + max(displayCol-display, 0)
+}
+
+// ExpandTabs replaces each tab in line with the spaces up to the next tab
+// stop. A tabWidth of 0 or less leaves line unchanged.
+func ExpandTabs(line string, tabWidth int) string {
+	if tabWidth <= 0 || !strings.ContainsRune(line, '\t') {
+		return line
+	}
+	
+	var result strings.Builder
+	display := 0
+	for _, r := range line {
+		next := nextCell(display, r, tabWidth)
+		if r == '\t' {
+			result.WriteString(strings.Repeat(" ", next-display))
+		} else {
+			result.WriteRune(r)
+		}
+		display = next
+	}
+	return result.String()
+}
+
+// nextCell returns the display column after rune r drawn at column display.
+func nextCell(display int, r rune, tabWidth int) int {
+	if r == '\t' && tabWidth > 0 {
+		return display + tabWidth - display%tabWidth
+	}
+	return display + 1
+}
+
 // isVisible checks if a buffer position is visible in the current viewport.
 func (v *Viewport) isVisible(pos BufferPos) bool {
 	// Check vertical bounds
@@ -168,4 +256,13 @@ func (v *Viewport) ScreenToBuffer(pos ScreenPos) BufferPos {
 	}
 	
 	return BufferPos{Line: bufferLine, Col: bufferCol}
+}
+
+// ScreenToBufferOnLine converts a screen position to a buffer position on
+// line, the text of the document line at that row, so clicks land on the
+// character drawn under them even after tabs.
+func (v *Viewport) ScreenToBufferOnLine(pos ScreenPos, line string) BufferPos {
+	bufferPos := v.ScreenToBuffer(pos)
+	bufferPos.Col = v.BufferColumn(line, bufferPos.Col)
+	return bufferPos
 }
\ No newline at end of file
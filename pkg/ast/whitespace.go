@@ -0,0 +1,76 @@
+package ast
+
+import "strings"
+
+// TrailingWhitespaceStart returns the rune offset where the run of spaces
+// and tabs ending line begins, or the line length if there is none.
+func TrailingWhitespaceStart(line string) int {
+	trimmed := strings.TrimRight(line, " \t")
+	return RuneOffset(line, len(trimmed))
+}
+
+// StripTrailingWhitespace removes spaces and tabs from the end of every
+// line and returns the number of lines changed. With keepHardBreaks, a
+// Markdown hard line break (two or more spaces after text) is kept as
+// exactly two spaces rather than removed.
+func (d *Document) StripTrailingWhitespace(keepHardBreaks bool) int {
+	changed := 0
+	for i := range d.lines {
+		line := d.lines[i].text
+		start := TrailingWhitespaceStart(line)
+		end := d.lines[i].length
+		if keepHardBreaks && start > 0 && strings.HasSuffix(line, "  ") {
+			start += 2
+		}
+		if start >= end {
+			continue
+		}
+		d.DeleteRange(BufferPos{Line: i, Col: start}, BufferPos{Line: i, Col: end})
+		changed++
+	}
+	return changed
+}
+
+// HasFinalNewline reports whether the text ends with a line break, as an
+// empty last line.
+func (d *Document) HasFinalNewline() bool {
+	return len(d.lines) > 1 && d.lines[len(d.lines)-1].text == ""
+}
+
+// SetFinalNewline makes the text end with exactly one line break, or with
+// none, reporting whether it changed. An empty document is left empty.
+func (d *Document) SetFinalNewline(final bool) bool {
+	last := len(d.lines) - 1
+	if final {
+		if d.lines[last].text == "" {
+			return false
+		}
+		d.InsertNewline(BufferPos{Line: last, Col: d.lines[last].length})
+		return true
+	}
+	if !d.HasFinalNewline() {
+		return false
+	}
+	end := last
+	for end > 0 && d.lines[end-1].text == "" {
+		end--
+	}
+	if end == 0 {
+		end = 1 // Only line breaks: keep the first line
+	}
+	d.DeleteRange(BufferPos{Line: end - 1, Col: d.lines[end-1].length}, BufferPos{Line: last})
+	return true
+}
+
+// StripCarriageReturns removes the carriage return ending each line of
+// text read with CRLF line breaks, returning the number of lines changed.
+func (d *Document) StripCarriageReturns() int {
+	changed := 0
+	for i := range d.lines {
+		if length := d.lines[i].length; strings.HasSuffix(d.lines[i].text, "\r") {
+			d.DeleteRange(BufferPos{Line: i, Col: length - 1}, BufferPos{Line: i, Col: length})
+			changed++
+		}
+	}
+	return changed
+}
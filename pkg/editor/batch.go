@@ -0,0 +1,227 @@
+package editor
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/ofri/mde/pkg/ast"
+)
+
+// Command is one step of a batch script, such as replace-all:foo:bar.
+type Command struct {
+	Name string
+	Args []string
+}
+
+// batchCommand is a command a batch script can run.
+type batchCommand struct {
+	usage       string // Arguments, as in ":<old>:<new>"
+	description string
+	minArgs     int
+	maxArgs     int
+	run         func(e *Editor, args []string) error
+}
+
+// batchCommands holds the commands scripts can run, keyed by name.
+var batchCommands = map[string]batchCommand{
+	"replace-all": {
+		usage:       ":<old>:<new>",
+		description: "Replace every match of old, case-sensitively",
+		minArgs:     2,
+		maxArgs:     2,
+		run: func(e *Editor, args []string) error {
+			e.ReplaceAll(args[0], args[1], true)
+			return nil
+		},
+	},
+	"format-tables": {
+		description: "Realign the pipes of every table",
+		run: func(e *Editor, args []string) error {
+			e.FormatTables()
+			return nil
+		},
+	},
+	"strip-trailing-whitespace": {
+		description: "Remove trailing whitespace, keeping markdown hard breaks",
+		run: func(e *Editor, args []string) error {
+			e.ed.StripTrailingWhitespace(true)
+			return nil
+		},
+	},
+	"update-toc": {
+		usage:       "[:<start>:<end>]",
+		description: "Regenerate the table of contents between the markers",
+		maxArgs:     2,
+		run: func(e *Editor, args []string) error {
+			start, end := "<!-- toc -->", "<!-- /toc -->"
+			switch len(args) {
+			case 1:
+				return fmt.Errorf("needs both markers")
+			case 2:
+				start, end = args[0], args[1]
+			}
+			if !e.ed.UpdateTOC(start, end, false) {
+				return fmt.Errorf("no %s marker", start)
+			}
+			return nil
+		},
+	},
+	"goto": {
+		usage:       ":<line>",
+		description: "Move the cursor to the start of a line, counting from 1",
+		minArgs:     1,
+		maxArgs:     1,
+		run: func(e *Editor, args []string) error {
+			line, err := strconv.Atoi(args[0])
+			if err != nil || line < 1 {
+				return fmt.Errorf("bad line number %q", args[0])
+			}
+			e.SetCursor(Pos{Line: line - 1})
+			return nil
+		},
+	},
+	"insert": {
+		usage:       ":<text>",
+		description: `Insert text at the cursor; "\n" starts a new line`,
+		minArgs:     1,
+		maxArgs:     1,
+		run: func(e *Editor, args []string) error {
+			e.Insert(strings.ReplaceAll(args[0], `\n`, "\n"))
+			return nil
+		},
+	},
+	"save": {
+		usage:       "[:<file>]",
+		description: "Write the document, to file if given",
+		maxArgs:     1,
+		run: func(e *Editor, args []string) error {
+			filename := ""
+			if len(args) > 0 {
+				filename = args[0]
+			}
+			return e.Save(filename)
+		},
+	},
+}
+
+// BatchCommands returns a line of usage for each command a batch script
+// can run, sorted by name.
+func BatchCommands() []string {
+	var lines []string
+	for name, cmd := range batchCommands {
+		lines = append(lines, fmt.Sprintf("%-36s %s", name+cmd.usage, cmd.description))
+	}
+	sort.Strings(lines)
+	return lines
+}
+
+// ParseScript splits a batch script into commands. Commands are separated
+// by ";" or newlines and their arguments by ":"; a backslash escapes
+// either, or itself. Names are trimmed but arguments are taken as written.
+// Blank lines and lines starting with "#" are skipped.
+func ParseScript(script string) ([]Command, error) {
+	var commands []Command
+	var parts []string
+	var part strings.Builder
+	lineStart := true
+	end := func() error {
+		parts = append(parts, part.String())
+		part.Reset()
+		name := strings.TrimSpace(parts[0])
+		if name == "" && len(parts) == 1 {
+			parts = nil
+			return nil
+		}
+		cmd, ok := batchCommands[name]
+		if !ok {
+			return fmt.Errorf("unknown command %q", name)
+		}
+		args := parts[1:]
+		if len(args) < cmd.minArgs || len(args) > cmd.maxArgs {
+			return fmt.Errorf("%s: wrong number of arguments, want %s%s", name, name, cmd.usage)
+		}
+		commands = append(commands, Command{Name: name, Args: args})
+		parts = nil
+		return nil
+	}
+
+	runes := []rune(script)
+	for i := 0; i < len(runes); i++ {
+		ch := runes[i]
+		if lineStart && ch == '#' {
+			for i+1 < len(runes) && runes[i+1] != '\n' {
+				i++
+			}
+			continue
+		}
+		lineStart = ch == '\n' || (lineStart && (ch == ' ' || ch == '\t'))
+
+		switch {
+		case ch == '\\' && i+1 < len(runes) && strings.ContainsRune(`\:;`, runes[i+1]):
+			i++
+			part.WriteRune(runes[i])
+		case ch == ':':
+			parts = append(parts, part.String())
+			part.Reset()
+		case ch == ';' || ch == '\n':
+			if err := end(); err != nil {
+				return nil, err
+			}
+		default:
+			part.WriteRune(ch)
+		}
+	}
+	if err := end(); err != nil {
+		return nil, err
+	}
+	return commands, nil
+}
+
+// Run runs one batch command.
+func (e *Editor) Run(cmd Command) error {
+	batch, ok := batchCommands[cmd.Name]
+	if !ok {
+		return fmt.Errorf("unknown command %q", cmd.Name)
+	}
+	if err := batch.run(e, cmd.Args); err != nil {
+		return fmt.Errorf("%s: %w", cmd.Name, err)
+	}
+	return nil
+}
+
+// RunScript parses script and runs its commands in order, stopping at the
+// first that fails.
+func (e *Editor) RunScript(script string) error {
+	commands, err := ParseScript(script)
+	if err != nil {
+		return err
+	}
+	for _, cmd := range commands {
+		if err := e.Run(cmd); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// FormatTables realigns the pipes of every table in the document, leaving
+// the cursor where it was, and returns the number of tables.
+func (e *Editor) FormatTables() int {
+	doc := e.ed.GetDocument()
+	cursor := e.Cursor()
+	count := 0
+	for line := 0; line < doc.LineCount(); line++ {
+		table, ok := doc.TableAt(line)
+		if !ok {
+			continue
+		}
+		e.SetCursor(ast.BufferPos{Line: table.Start})
+		e.ed.FormatTable()
+		count++
+		line = table.End
+	}
+	e.SetCursor(cursor)
+	return count
+}
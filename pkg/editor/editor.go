@@ -0,0 +1,289 @@
+// Package editor is a headless markdown editor for Go programs that build
+// markdown tooling on mde without its terminal UI.
+//
+// An Editor holds one document and a cursor. It opens and saves files,
+// edits at the cursor or in a range, searches, and renders the document
+// to a string the way the editor would show it:
+//
+//	ed, err := editor.Open("notes.md")
+//	if err != nil {
+//	    return err
+//	}
+//	ed.ReplaceAll("TODO", "DONE", true)
+//	out, err := ed.Render(editor.RenderOptions{Width: 80, Preview: true})
+//
+// Positions are 0-indexed lines and rune columns, as in package ast. The
+// API here is kept stable; Document and AST reach the underlying types,
+// which may change between releases.
+package editor
+
+import (
+	"context"
+	"strings"
+
+	"github.com/ofri/mde/internal/plugins/parsers"
+	"github.com/ofri/mde/internal/plugins/renderers"
+	"github.com/ofri/mde/pkg/ast"
+	"github.com/ofri/mde/pkg/plugin"
+)
+
+// Pos is a position in the document: a 0-indexed line and rune column.
+type Pos = ast.BufferPos
+
+// Range is the text from Start up to, not including, End.
+type Range = ast.Range
+
+// Editor is a document with a cursor and selection. It is not safe for
+// concurrent use.
+type Editor struct {
+	ed       *ast.Editor
+	parser   plugin.ParserPlugin
+	renderer *renderers.TerminalRenderer
+}
+
+// New returns an editor holding text, with the cursor at the start.
+func New(text string) *Editor {
+	return wrap(ast.NewEditorWithContent(text))
+}
+
+// Open returns an editor holding the named file, which may be a local path
+// or a URL the registered storage plugins serve. A file that does not
+// exist yet opens empty and is created by Save.
+func Open(filename string) (*Editor, error) {
+	ed := ast.NewEditor()
+	if err := ed.LoadFile(filename); err != nil {
+		return nil, err
+	}
+	return wrap(ed), nil
+}
+
+func wrap(ed *ast.Editor) *Editor {
+	return &Editor{
+		ed:       ed,
+		parser:   parsers.NewCommonMarkParser(),
+		renderer: renderers.NewTerminalRenderer(),
+	}
+}
+
+// Save writes the document to filename, or back to the file it was opened
+// from or last saved to when filename is "".
+func (e *Editor) Save(filename string) error {
+	return e.ed.SaveFile(filename)
+}
+
+// Filename returns the file the document was opened from or last saved
+// to, or "" for one never saved.
+func (e *Editor) Filename() string {
+	return e.ed.GetDocument().GetFilename()
+}
+
+// Modified reports whether the document changed since it was opened or
+// last saved.
+func (e *Editor) Modified() bool {
+	return e.ed.GetDocument().IsModified()
+}
+
+// Text returns the whole document.
+func (e *Editor) Text() string {
+	return e.ed.GetDocument().GetText()
+}
+
+// LineCount returns the number of lines, at least 1.
+func (e *Editor) LineCount() int {
+	return e.ed.GetDocument().LineCount()
+}
+
+// Line returns line i without its newline, or "" out of range.
+func (e *Editor) Line(i int) string {
+	return e.ed.GetDocument().GetLine(i)
+}
+
+// Cursor returns the cursor position.
+func (e *Editor) Cursor() Pos {
+	return e.ed.GetCursor().GetBufferPos()
+}
+
+// SetCursor moves the cursor to pos, clamped to the document, and clears
+// the selection.
+func (e *Editor) SetCursor(pos Pos) {
+	cursor := e.ed.GetCursor()
+	cursor.ClearSelection()
+	cursor.SetBufferPos(e.ed.GetDocument().ValidatePosition(pos))
+}
+
+// Select selects r, clamped to the document, leaving the cursor at its end.
+func (e *Editor) Select(r Range) {
+	doc := e.ed.GetDocument()
+	r = Range{Start: doc.ValidatePosition(r.Start), End: doc.ValidatePosition(r.End)}
+	if r.End.Before(r.Start) {
+		r.Start, r.End = r.End, r.Start
+	}
+	cursor := e.ed.GetCursor()
+	cursor.SetBufferPos(r.End)
+	cursor.SetSelection(&ast.Selection{Start: r.Start, End: r.End})
+}
+
+// Selection returns the selected range, if any.
+func (e *Editor) Selection() (Range, bool) {
+	selection := e.ed.GetCursor().GetSelection()
+	if selection == nil {
+		return Range{}, false
+	}
+	return Range{Start: selection.Start, End: selection.End}, true
+}
+
+// SelectedText returns the selected text, or "" with nothing selected.
+func (e *Editor) SelectedText() string {
+	return e.ed.GetSelectionText()
+}
+
+// Insert inserts text at the cursor, replacing the selection if there is
+// one, and leaves the cursor after it.
+func (e *Editor) Insert(text string) {
+	e.ed.InsertText(text)
+}
+
+// Delete deletes count characters before the cursor, as backspace does,
+// or after it for a negative count. With a selection only the selection
+// is deleted.
+func (e *Editor) Delete(count int) {
+	if count < 0 {
+		e.ed.DeleteTextForward(-count)
+		return
+	}
+	e.ed.DeleteText(count)
+}
+
+// Replace replaces the text in r with text, leaving the cursor after it.
+func (e *Editor) Replace(r Range, text string) {
+	doc := e.ed.GetDocument()
+	e.ed.ReplaceRange(Range{Start: doc.ValidatePosition(r.Start), End: doc.ValidatePosition(r.End)}, text)
+}
+
+// SearchOptions controls Find.
+type SearchOptions = ast.SearchOptions
+
+// Find returns the next match of query from the cursor, wrapping around
+// the document, without moving the cursor. The query may span lines.
+func (e *Editor) Find(query string, opts SearchOptions) (Range, bool) {
+	if query == "" {
+		return Range{}, false
+	}
+	return e.ed.GetDocument().Find(query, e.Cursor(), opts)
+}
+
+// FindAll returns every match of query in document order, not overlapping.
+func (e *Editor) FindAll(query string, caseSensitive bool) []Range {
+	if query == "" {
+		return nil
+	}
+	doc := e.ed.GetDocument()
+	opts := SearchOptions{CaseSensitive: caseSensitive}
+	var matches []Range
+	pos := Pos{}
+	for {
+		r, ok := doc.Find(query, pos, opts)
+		// Find wraps around, so a match before pos means every one was seen
+		if !ok || r.Start.Before(pos) {
+			return matches
+		}
+		matches = append(matches, r)
+		pos = r.End
+	}
+}
+
+// ReplaceAll replaces every match of query with text and returns how many
+// were replaced. The cursor is left after the last replacement.
+func (e *Editor) ReplaceAll(query, text string, caseSensitive bool) int {
+	matches := e.FindAll(query, caseSensitive)
+	if len(matches) == 0 {
+		return 0
+	}
+
+	// The last replacement ends where the last match did, moved by how
+	// much each replacement grows or shrinks the text
+	doc := e.ed.GetDocument()
+	end := doc.PosToOffset(matches[len(matches)-1].End)
+	for _, r := range matches {
+		end += len(text) - (doc.PosToOffset(r.End) - doc.PosToOffset(r.Start))
+	}
+
+	// Last first, so the earlier ranges stay valid
+	for i := len(matches) - 1; i >= 0; i-- {
+		e.ed.ReplaceRange(matches[i], text)
+	}
+	e.ed.GetCursor().SetBufferPos(doc.OffsetToPos(end))
+	return len(matches)
+}
+
+// RenderOptions controls Render.
+type RenderOptions struct {
+	Width       int  // Columns available, for layout such as tables; 80 when 0
+	LineNumbers bool // Prefix each line with its number
+	Preview     bool // Render markdown formatted, as preview mode does
+	Plain       bool // Leave out ANSI styling
+}
+
+// Render returns the whole document rendered as the editor shows it, one
+// line per document line, styled with ANSI escapes unless opts.Plain.
+func (e *Editor) Render(opts RenderOptions) (string, error) {
+	ctx := context.Background()
+	doc := e.ed.GetDocument()
+	doc.SetTokenizer(func(line string) []ast.Token {
+		tokens, _ := e.parser.GetSyntaxHighlighting(ctx, line)
+		return tokens
+	})
+
+	width := opts.Width
+	if width <= 0 {
+		width = 80
+	}
+	lineNumberWidth := 0
+	if opts.LineNumbers {
+		lineNumberWidth = e.ed.GetLineNumberWidth()
+	}
+	if err := e.renderer.Configure(map[string]interface{}{
+		"maxWidth":        width,
+		"showLineNumbers": opts.LineNumbers,
+		"lineNumberWidth": lineNumberWidth,
+	}); err != nil {
+		return "", err
+	}
+
+	renderCtx := &plugin.RenderContext{
+		Document:        doc,
+		Viewport:        ast.NewViewport(0, 0, width, doc.LineCount(), lineNumberWidth, 4),
+		ShowLineNumbers: opts.LineNumbers,
+	}
+	var lines []plugin.RenderedLine
+	var err error
+	if opts.Preview {
+		lines, err = e.renderer.RenderPreviewVisible(ctx, renderCtx)
+	} else {
+		lines, err = e.renderer.RenderVisible(ctx, renderCtx)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	if opts.Plain {
+		text := make([]string, len(lines))
+		for i, line := range lines {
+			text[i] = line.Content
+		}
+		return strings.Join(text, "\n"), nil
+	}
+	return e.renderer.RenderToString(lines), nil
+}
+
+// Document returns the underlying document, for what this package does
+// not cover. Its API may change between releases.
+func (e *Editor) Document() *ast.Document {
+	return e.ed.GetDocument()
+}
+
+// AST returns the underlying editor, for what this package does not
+// cover. Its API may change between releases.
+func (e *Editor) AST() *ast.Editor {
+	return e.ed
+}
@@ -13,17 +13,21 @@
 //
 // # Plugin Types
 //
-// Two types of plugins are supported:
+// Three types of plugins are supported:
 //   - Parser plugins: Convert raw text to AST with syntax tokens
 //   - Renderer plugins: Convert AST to terminal output with ANSI colors
+//   - Storage plugins: Read and write documents for URL schemes, so
+//     Editor.LoadFile and SaveFile reach local files, remote hosts or memory
 //
 // # Error Handling
 //
 // Since all plugins are internal and compiled into the binary:
 //   - Plugin registration failures indicate programming errors
 //   - Plugin execution failures indicate bugs in the implementation
-//   - All plugin errors should cause explicit failures with detailed error messages
-//   - There are NO fallback mechanisms - the system must work correctly
+//   - Plugin errors are returned with detailed messages, never swallowed
+//   - The TUI shows a render or parse failure in place of the text and logs
+//     it (see internal/debuglog), keeping the editor usable so work can be
+//     saved; there is no silent fallback to other plugins
 //
 // # Registration
 //
@@ -41,7 +45,7 @@
 //
 //	renderer, err := registry.GetDefaultRenderer()
 //	if err != nil {
-//	    panic("FATAL: No renderer registered - programming error")
+//	    return fmt.Errorf("no renderer registered: %w", err)
 //	}
 //
 // # Design Rationale
@@ -7,7 +7,7 @@ type PluginError struct {
 	// Plugin name
 	Plugin string
 	
-	// Plugin type (parser, renderer)
+	// Plugin type (parser, renderer, storage)
 	Type string
 	
 	// Operation that failed
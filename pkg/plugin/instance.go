@@ -0,0 +1,71 @@
+package plugin
+
+import "fmt"
+
+// ParserInstancer is implemented by parsers that can create independent
+// instances, so each consumer holds its own configuration instead of
+// reconfiguring the shared, registered one.
+type ParserInstancer interface {
+	NewInstance() ParserPlugin
+}
+
+// RendererInstancer is the renderer counterpart of ParserInstancer.
+type RendererInstancer interface {
+	NewInstance() RendererPlugin
+}
+
+// NewParserInstance returns a parser of the named plugin for a single
+// consumer, configured with everything passed to Registry.Configure so far.
+// Parsers that cannot create instances are returned shared.
+func (r *Registry) NewParserInstance(name string) (ParserPlugin, error) {
+	r.mu.RLock()
+	parser, exists := r.parsers[name]
+	options := r.options[name]
+	r.mu.RUnlock()
+
+	if !exists {
+		return nil, fmt.Errorf("parser plugin '%s' not found", name)
+	}
+	instancer, ok := parser.(ParserInstancer)
+	if !ok {
+		return parser, nil
+	}
+
+	instance := instancer.NewInstance()
+	if err := instance.Configure(options); err != nil {
+		return nil, NewPluginError("parser", name, "configure", err)
+	}
+	return instance, nil
+}
+
+// NewRendererInstance returns a renderer of the named plugin for a single
+// consumer, configured with everything passed to Registry.Configure so far.
+// Renderers that cannot create instances are returned shared.
+func (r *Registry) NewRendererInstance(name string) (RendererPlugin, error) {
+	r.mu.RLock()
+	renderer, exists := r.renderers[name]
+	options := r.options[name]
+	r.mu.RUnlock()
+
+	if !exists {
+		return nil, fmt.Errorf("renderer plugin '%s' not found", name)
+	}
+	instancer, ok := renderer.(RendererInstancer)
+	if !ok {
+		return renderer, nil
+	}
+
+	instance := instancer.NewInstance()
+	if err := instance.Configure(options); err != nil {
+		return nil, NewPluginError("renderer", name, "configure", err)
+	}
+	return instance, nil
+}
+
+// DefaultRendererName returns the name of the default renderer, or ""
+// when none is registered.
+func (r *Registry) DefaultRendererName() string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.defaultRenderer
+}
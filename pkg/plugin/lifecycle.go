@@ -0,0 +1,234 @@
+package plugin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// Initializer is implemented by plugins that need setup before first use.
+// Registry.Init calls it once, after the plugin's dependencies.
+type Initializer interface {
+	Init(ctx context.Context) error
+}
+
+// Shutdowner is implemented by plugins that hold resources to release.
+// Registry.Shutdown calls it in reverse initialization order.
+type Shutdowner interface {
+	Shutdown(ctx context.Context) error
+}
+
+// Dependent is implemented by plugins that must be initialized after
+// other registered plugins, named by their plugin names.
+type Dependent interface {
+	Dependencies() []string
+}
+
+// lifecycleEntry is a registered plugin of either type
+type lifecycleEntry struct {
+	kind   string // "parser", "renderer" or "storage"
+	name   string
+	plugin interface{}
+}
+
+// Init initializes every registered plugin, dependencies first. If a
+// plugin fails, the ones already initialized are shut down again.
+func (r *Registry) Init(ctx context.Context) error {
+	r.mu.Lock()
+	if r.started != nil {
+		r.mu.Unlock()
+		return fmt.Errorf("plugins already initialized")
+	}
+	order, err := initOrder(r.entries())
+	if err != nil {
+		r.mu.Unlock()
+		return err
+	}
+	r.started = []lifecycleEntry{}
+	r.mu.Unlock()
+
+	// Hooks run unlocked so plugins may use the registry
+	var started []lifecycleEntry
+	for _, entry := range order {
+		if initializer, ok := entry.plugin.(Initializer); ok {
+			if err := initializer.Init(ctx); err != nil {
+				shutdownErr := shutdown(ctx, started)
+				r.mu.Lock()
+				r.started = nil
+				r.mu.Unlock()
+				return errors.Join(NewPluginError(entry.kind, entry.name, "init", err), shutdownErr)
+			}
+		}
+		started = append(started, entry)
+	}
+
+	r.mu.Lock()
+	r.started = started
+	r.mu.Unlock()
+	return nil
+}
+
+// Shutdown shuts down the initialized plugins in reverse order, reporting
+// every failure rather than stopping at the first.
+func (r *Registry) Shutdown(ctx context.Context) error {
+	r.mu.Lock()
+	started := r.started
+	r.started = nil
+	r.mu.Unlock()
+
+	return shutdown(ctx, started)
+}
+
+// InitOrder returns the plugin names in initialization order.
+func (r *Registry) InitOrder() ([]string, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	order, err := initOrder(r.entries())
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, len(order))
+	for i, entry := range order {
+		names[i] = entry.name
+	}
+	return names, nil
+}
+
+// Configure applies options to plugins by name in a single pass, so
+// configuration is loaded once rather than re-applied by each consumer.
+// A name matching plugins of several types configures each. The
+// options are remembered for instances created afterwards.
+func (r *Registry) Configure(options map[string]map[string]interface{}) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	names := make([]string, 0, len(options))
+	for name := range options {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		parser, isParser := r.parsers[name]
+		renderer, isRenderer := r.renderers[name]
+		storage, isStorage := r.storages[name]
+		if !isParser && !isRenderer && !isStorage {
+			return fmt.Errorf("cannot configure plugin '%s': not registered", name)
+		}
+		if isParser {
+			if err := parser.Configure(options[name]); err != nil {
+				return NewPluginError("parser", name, "configure", err)
+			}
+		}
+		if isRenderer {
+			if err := renderer.Configure(options[name]); err != nil {
+				return NewPluginError("renderer", name, "configure", err)
+			}
+		}
+		if isStorage {
+			if err := storage.Configure(options[name]); err != nil {
+				return NewPluginError("storage", name, "configure", err)
+			}
+		}
+
+		merged := make(map[string]interface{}, len(r.options[name])+len(options[name]))
+		for key, value := range r.options[name] {
+			merged[key] = value
+		}
+		for key, value := range options[name] {
+			merged[key] = value
+		}
+		r.options[name] = merged
+	}
+	return nil
+}
+
+// entries lists the registered plugins sorted by name. Callers hold r.mu.
+func (r *Registry) entries() []lifecycleEntry {
+	entries := make([]lifecycleEntry, 0, len(r.parsers)+len(r.renderers)+len(r.storages))
+	for name, p := range r.parsers {
+		entries = append(entries, lifecycleEntry{kind: "parser", name: name, plugin: p})
+	}
+	for name, p := range r.renderers {
+		entries = append(entries, lifecycleEntry{kind: "renderer", name: name, plugin: p})
+	}
+	for name, p := range r.storages {
+		entries = append(entries, lifecycleEntry{kind: "storage", name: name, plugin: p})
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].name != entries[j].name {
+			return entries[i].name < entries[j].name
+		}
+		return entries[i].kind < entries[j].kind
+	})
+	return entries
+}
+
+// initOrder sorts entries so each follows its dependencies, keeping the
+// name order otherwise. Unknown dependencies and cycles are errors.
+func initOrder(entries []lifecycleEntry) ([]lifecycleEntry, error) {
+	byName := make(map[string][]int)
+	for i, entry := range entries {
+		byName[entry.name] = append(byName[entry.name], i)
+	}
+
+	const (
+		unvisited = iota
+		visiting
+		done
+	)
+	state := make([]int, len(entries))
+	order := make([]lifecycleEntry, 0, len(entries))
+
+	var visit func(i int) error
+	visit = func(i int) error {
+		switch state[i] {
+		case done:
+			return nil
+		case visiting:
+			return fmt.Errorf("plugin dependency cycle through '%s'", entries[i].name)
+		}
+		state[i] = visiting
+
+		if dependent, ok := entries[i].plugin.(Dependent); ok {
+			for _, dep := range dependent.Dependencies() {
+				indexes, exists := byName[dep]
+				if !exists {
+					return NewRegistrationError(entries[i].kind, entries[i].name, fmt.Sprintf("depends on unregistered plugin '%s'", dep))
+				}
+				for _, j := range indexes {
+					if err := visit(j); err != nil {
+						return err
+					}
+				}
+			}
+		}
+
+		state[i] = done
+		order = append(order, entries[i])
+		return nil
+	}
+
+	for i := range entries {
+		if err := visit(i); err != nil {
+			return nil, err
+		}
+	}
+	return order, nil
+}
+
+// shutdown runs Shutdown hooks over started in reverse.
+func shutdown(ctx context.Context, started []lifecycleEntry) error {
+	var errs []error
+	for i := len(started) - 1; i >= 0; i-- {
+		entry := started[i]
+		if shutdowner, ok := entry.plugin.(Shutdowner); ok {
+			if err := shutdowner.Shutdown(ctx); err != nil {
+				errs = append(errs, NewPluginError(entry.kind, entry.name, "shutdown", err))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -2,6 +2,8 @@ package plugin
 
 import (
 	"fmt"
+	"path/filepath"
+	"strings"
 	"sync"
 )
 
@@ -12,10 +14,23 @@ type Registry struct {
 	// Registered plugins
 	parsers   map[string]ParserPlugin
 	renderers map[string]RendererPlugin
+	storages  map[string]StoragePlugin
+	
+	// Storage names by URL scheme ("" for local files)
+	schemes map[string]string
 	
 	// Default plugins
 	defaultParser   string
 	defaultRenderer string
+	
+	// Parser names by lowercase file extension (".md")
+	fileTypes map[string]string
+	
+	// Plugins initialized by Init, in order; nil before Init
+	started []lifecycleEntry
+	
+	// Options passed to Configure, by plugin name, for new instances
+	options map[string]map[string]interface{}
 }
 
 // NewRegistry creates a new plugin registry
@@ -23,6 +38,10 @@ func NewRegistry() *Registry {
 	return &Registry{
 		parsers:   make(map[string]ParserPlugin),
 		renderers: make(map[string]RendererPlugin),
+		storages:  make(map[string]StoragePlugin),
+		schemes:   make(map[string]string),
+		fileTypes: make(map[string]string),
+		options:   make(map[string]map[string]interface{}),
 	}
 }
 
@@ -117,6 +136,50 @@ func (r *Registry) GetDefaultRenderer() (RendererPlugin, error) {
 }
 
 
+// RegisterFileType makes files with extension ext (".txt" or "txt",
+// in any case) open with the named parser, replacing any earlier mapping
+func (r *Registry) RegisterFileType(ext, parser string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	
+	if _, exists := r.parsers[parser]; !exists {
+		return fmt.Errorf("parser plugin '%s' not registered", parser)
+	}
+	
+	r.fileTypes[normalizeExt(ext)] = parser
+	return nil
+}
+
+// ParserNameForFile returns the name of the parser for filename: the one
+// registered for its extension, or the default parser
+func (r *Registry) ParserNameForFile(filename string) string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	
+	if name, ok := r.fileTypes[normalizeExt(filepath.Ext(filename))]; ok {
+		return name
+	}
+	return r.defaultParser
+}
+
+// GetParserForFile returns the parser for filename, falling back to the
+// default parser for unknown extensions and unnamed buffers
+func (r *Registry) GetParserForFile(filename string) (ParserPlugin, error) {
+	name := r.ParserNameForFile(filename)
+	if name == "" {
+		return nil, fmt.Errorf("no default parser registered")
+	}
+	return r.GetParser(name)
+}
+
+// normalizeExt lowercases ext and ensures it starts with a dot
+func normalizeExt(ext string) string {
+	if ext == "" {
+		return ""
+	}
+	return "." + strings.ToLower(strings.TrimPrefix(ext, "."))
+}
+
 // SetDefaultParser sets the default parser plugin
 func (r *Registry) SetDefaultParser(name string) error {
 	r.mu.Lock()
@@ -171,26 +234,33 @@ func (r *Registry) ListRenderers() []string {
 }
 
 
-// Global registry instance
-var globalRegistry = NewRegistry()
+// Global registry instance, guarded by globalMu since tests replace it
+var (
+	globalMu       sync.RWMutex
+	globalRegistry = NewRegistry()
+)
 
 // RegisterParser registers a parser plugin globally
 func RegisterParser(name string, plugin ParserPlugin) error {
-	return globalRegistry.RegisterParser(name, plugin)
+	return GetRegistry().RegisterParser(name, plugin)
 }
 
 // RegisterRenderer registers a renderer plugin globally
 func RegisterRenderer(name string, plugin RendererPlugin) error {
-	return globalRegistry.RegisterRenderer(name, plugin)
+	return GetRegistry().RegisterRenderer(name, plugin)
 }
 
 
 // GetRegistry returns the global registry instance
 func GetRegistry() *Registry {
+	globalMu.RLock()
+	defer globalMu.RUnlock()
 	return globalRegistry
 }
 
 // ResetRegistry resets the global registry (for testing)
 func ResetRegistry() {
+	globalMu.Lock()
+	defer globalMu.Unlock()
 	globalRegistry = NewRegistry()
 }
\ No newline at end of file
@@ -76,12 +76,13 @@ type RenderedLine struct {
 	Metadata map[string]interface{}
 }
 
-// StyleRange represents a range of characters with specific styling
+// StyleRange represents a range of characters with specific styling.
+// Positions are rune offsets into RenderedLine.Content.
 type StyleRange struct {
-	// Start position in the line
+	// Start rune offset in the line
 	Start int
 	
-	// End position in the line
+	// End rune offset in the line (exclusive)
 	End int
 	
 	// Style to apply
@@ -107,6 +108,13 @@ type Style struct {
 	
 	// Strikethrough text
 	Strikethrough bool
+	
+	// Faint (dimmed) text
+	Faint bool
+	
+	// Reverse swaps foreground and background, as the cursor and
+	// selection are drawn
+	Reverse bool
 }
 
 // ToLipgloss converts a Style to a lipgloss.Style
@@ -137,6 +145,14 @@ func (s Style) ToLipgloss() lipgloss.Style {
 		style = style.Strikethrough(true)
 	}
 	
+	if s.Faint {
+		style = style.Faint(true)
+	}
+	
+	if s.Reverse {
+		style = style.Reverse(true)
+	}
+	
 	return style
 }
 
@@ -0,0 +1,73 @@
+package integration
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/ofri/mde/internal/config"
+	"github.com/ofri/mde/internal/plugins"
+	"github.com/ofri/mde/internal/tui"
+	"github.com/ofri/mde/pkg/ast"
+	"github.com/ofri/mde/pkg/plugin"
+	"github.com/ofri/mde/test/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func abbrevModel(t *testing.T, content string) *tui.Model {
+	plugin.ResetRegistry()
+	require.NoError(t, plugins.InitializePlugins())
+
+	cfg := config.Default()
+	cfg.Abbreviations = map[string]string{"btw": "by the way"}
+	model := tui.New()
+	model.SetConfig(cfg)
+	testutils.LoadContentIntoModel(model, content)
+	testutils.SetModelSize(model, 80, 20)
+	return model
+}
+
+func TestAbbrev_ExpandsWhileTyping(t *testing.T) {
+	model := abbrevModel(t, "")
+	doc := model.GetEditor().GetDocument()
+
+	typeKeys(model, "a -> b (c) btw.")
+	assert.Equal(t, "a → b © by the way.", doc.GetText())
+
+	model.Update(tea.KeyPressMsg(tea.Key{Code: tea.KeyEnter}))
+	typeKeys(model, "btw")
+	model.Update(tea.KeyPressMsg(tea.Key{Code: tea.KeyEnter}))
+	assert.Equal(t, "a → b © by the way.\nby the way\n", doc.GetText())
+}
+
+func TestAbbrev_BackspaceKeepsLiteral(t *testing.T) {
+	model := abbrevModel(t, "")
+	doc := model.GetEditor().GetDocument()
+	backspace := tea.KeyPressMsg(tea.Key{Code: tea.KeyBackspace})
+
+	typeKeys(model, "x ->")
+	model.Update(backspace)
+	assert.Equal(t, "x ->", doc.GetText())
+
+	model.Update(backspace)
+	assert.Equal(t, "x -", doc.GetText(), "a second backspace deletes normally")
+}
+
+func TestAbbrev_NotInCode(t *testing.T) {
+	model := abbrevModel(t, "```\n")
+	doc := model.GetEditor().GetDocument()
+	model.GetEditor().GetCursor().SetBufferPos(ast.BufferPos{Line: 1, Col: 0})
+
+	typeKeys(model, "a -> b")
+	assert.Equal(t, "```\na -> b", doc.GetText())
+
+	model = abbrevModel(t, "")
+	typeKeys(model, "`a -> b` ->")
+	assert.Equal(t, "`a -> b` →", model.GetEditor().GetDocument().GetText())
+
+	cfg := config.Default()
+	cfg.AutoReplace = false
+	model.SetConfig(cfg)
+	typeKeys(model, " ->")
+	assert.Equal(t, "`a -> b` → ->", model.GetEditor().GetDocument().GetText())
+}
@@ -0,0 +1,45 @@
+package integration
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/ofri/mde/internal/plugins"
+	"github.com/ofri/mde/internal/tui"
+	"github.com/ofri/mde/pkg/plugin"
+	"github.com/ofri/mde/test/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAutoIndent_EnterContinuesQuote(t *testing.T) {
+	plugin.ResetRegistry()
+	require.NoError(t, plugins.InitializePlugins())
+
+	model := tui.New()
+	model.NewBuffer("", "quote.md")
+	testutils.SetModelSize(model, 80, 10)
+
+	typeText(model, "> one")
+	model.Update(tea.KeyPressMsg(tea.Key{Code: tea.KeyEnter}))
+	typeText(model, "two")
+	model.Update(tea.KeyPressMsg(tea.Key{Code: tea.KeyEnter}))
+	model.Update(tea.KeyPressMsg(tea.Key{Code: tea.KeyEnter}))
+	typeText(model, "after")
+
+	assert.Equal(t, "> one\n> two\n\nafter", model.GetEditor().GetDocument().GetText())
+}
+
+func TestAutoIndent_PlainTextBreaksLines(t *testing.T) {
+	plugin.ResetRegistry()
+	require.NoError(t, plugins.InitializePlugins())
+
+	model := tui.New()
+	model.NewBuffer("", "notes.txt")
+	testutils.SetModelSize(model, 80, 10)
+
+	typeText(model, "> one")
+	model.Update(tea.KeyPressMsg(tea.Key{Code: tea.KeyEnter}))
+
+	assert.Equal(t, "> one\n", model.GetEditor().GetDocument().GetText())
+}
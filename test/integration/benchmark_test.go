@@ -0,0 +1,171 @@
+//go:build !race
+
+package integration
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ofri/mde/internal/plugins"
+	"github.com/ofri/mde/internal/tui"
+	"github.com/ofri/mde/pkg/ast"
+	"github.com/ofri/mde/pkg/plugin"
+	"github.com/ofri/mde/test/testutils"
+	"github.com/stretchr/testify/require"
+)
+
+// benchmarkSizes are the generated document sizes, in lines
+var benchmarkSizes = []int{10_000, 100_000}
+
+// performanceBaselines are per-operation budgets, by workload and
+// document size, checked by the benchmarks. They leave several times the
+// measured cost as headroom (about 2µs per edit, 0.7ms per frame, 0.7s
+// and 8s to tokenize, and 10ms and 0.5s to render the preview) so slow
+// machines pass, while an accidental O(n) per keystroke or a
+// whole-document render per frame still fails. Editing and rendering an
+// editor frame must not depend on document size; tokenizing and the
+// preview currently scale with it. The race detector slows the workloads
+// several times over, so the file builds without it.
+var performanceBaselines = map[string]map[int]time.Duration{
+	"DocumentEdit":   {10_000: time.Millisecond, 100_000: time.Millisecond},
+	"ViewportRender": {10_000: 20 * time.Millisecond, 100_000: 20 * time.Millisecond},
+	"Tokenize":       {10_000: 3 * time.Second, 100_000: 30 * time.Second},
+	"PreviewRender":  {10_000: 200 * time.Millisecond, 100_000: 5 * time.Second},
+}
+
+func BenchmarkDocumentEdit(b *testing.B) {
+	runSized(b, "DocumentEdit", documentEditOp)
+}
+
+func BenchmarkViewportRender(b *testing.B) {
+	runSized(b, "ViewportRender", viewportRenderOp)
+}
+
+func BenchmarkTokenize(b *testing.B) {
+	runSized(b, "Tokenize", tokenizeOp)
+}
+
+func BenchmarkPreviewRender(b *testing.B) {
+	runSized(b, "PreviewRender", previewRenderOp)
+}
+
+// runSized runs a benchmark operation once per document size, failing if
+// an operation takes longer than its baseline budget.
+func runSized(b *testing.B, name string, setup func(tb testing.TB, lines int) func()) {
+	for _, lines := range benchmarkSizes {
+		b.Run(fmt.Sprintf("%dlines", lines), func(b *testing.B) {
+			op := setup(b, lines)
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				op()
+			}
+			b.StopTimer()
+
+			if average := b.Elapsed() / time.Duration(b.N); average > performanceBaselines[name][lines] {
+				b.Errorf("%s on %d lines regressed: %v per op, budget %v", name, lines, average, performanceBaselines[name][lines])
+			}
+		})
+	}
+}
+
+// documentEditOp types and deletes a character in the middle of the
+// document, leaving it unchanged for the next run.
+func documentEditOp(tb testing.TB, lines int) func() {
+	doc := ast.NewDocument(generateMarkdownDocument(lines))
+	pos := ast.BufferPos{Line: lines / 2, Col: 2}
+	return func() {
+		after := doc.InsertChar(pos, 'x')
+		doc.DeleteChar(after)
+	}
+}
+
+// viewportRenderOp renders an editor frame scrolled to the middle of the
+// document.
+func viewportRenderOp(tb testing.TB, lines int) func() {
+	model := benchmarkModel(tb, lines)
+	model.GetEditor().GetCursor().SetBufferPos(ast.BufferPos{Line: lines / 2})
+	model.View()
+	return func() {
+		model.GetEditor().MoveCursorDown()
+		model.GetEditor().MoveCursorUp()
+		model.View()
+	}
+}
+
+// tokenizeOp parses the whole document, which highlights every line.
+func tokenizeOp(tb testing.TB, lines int) func() {
+	initBenchmarkPlugins(tb)
+	parser, err := plugin.GetRegistry().GetParserForFile("bench.md")
+	require.NoError(tb, err)
+
+	text := generateMarkdownDocument(lines)
+	ctx := context.Background()
+	return func() {
+		doc, err := parser.Parse(ctx, text)
+		require.NoError(tb, err)
+		require.Equal(tb, lines, doc.LineCount())
+	}
+}
+
+// previewRenderOp renders a preview frame at the middle of the document.
+func previewRenderOp(tb testing.TB, lines int) func() {
+	model := benchmarkModel(tb, lines)
+	model.GetEditor().GetCursor().SetBufferPos(ast.BufferPos{Line: lines / 2})
+	model.TogglePreviewMode()
+	return func() {
+		model.View()
+	}
+}
+
+func benchmarkModel(tb testing.TB, lines int) *tui.Model {
+	initBenchmarkPlugins(tb)
+	model := tui.New()
+	model.NewBuffer(generateMarkdownDocument(lines), "bench.md")
+	testutils.SetModelSize(model, 120, 40)
+	return model
+}
+
+func initBenchmarkPlugins(tb testing.TB) {
+	plugin.ResetRegistry()
+	require.NoError(tb, plugins.InitializePlugins())
+}
+
+// generateMarkdownDocument builds a document of the given line count that
+// mixes the constructs the renderers handle: headings, emphasis, links,
+// lists, quotes, tables and fenced code.
+func generateMarkdownDocument(lines int) string {
+	block := []string{
+		"## Section %d",
+		"",
+		"Some *emphasis*, **strong** text, `code` and a [link](https://example.com/%d).",
+		"- a list item with ~~strikethrough~~",
+		"  1. a nested ordered item",
+		"> a quoted line",
+		"",
+		"| column | value |",
+		"|--------|------:|",
+		"| row    | %d |",
+		"",
+		"```go",
+		"func f() int { return %d }",
+		"```",
+		"",
+	}
+
+	var sb strings.Builder
+	for i := 0; i < lines; i++ {
+		if i > 0 {
+			sb.WriteByte('\n')
+		}
+		line := block[i%len(block)]
+		if strings.Contains(line, "%d") {
+			line = fmt.Sprintf(line, i)
+		}
+		sb.WriteString(line)
+	}
+	return sb.String()
+}
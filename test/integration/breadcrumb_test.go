@@ -0,0 +1,49 @@
+package integration
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ofri/mde/internal/config"
+	"github.com/ofri/mde/internal/plugins"
+	"github.com/ofri/mde/internal/tui"
+	"github.com/ofri/mde/pkg/ast"
+	"github.com/ofri/mde/pkg/plugin"
+	"github.com/ofri/mde/test/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// statusBar returns the status bar row of the model's view, without
+// escape sequences.
+func statusBar(model *tui.Model) string {
+	for _, row := range strings.Split(testutils.StripAnsiEscapes(model.View()), "\n") {
+		if strings.Contains(row, "Ln ") {
+			return row
+		}
+	}
+	return ""
+}
+
+func TestBreadcrumb(t *testing.T) {
+	plugin.ResetRegistry()
+	require.NoError(t, plugins.InitializePlugins())
+
+	model := tui.New()
+	model.NewBuffer("# Chapter 2\n## Setup\n### Install\nrun it\n## Usage\nuse it", "book.md")
+	testutils.SetModelSize(model, 80, 10)
+
+	model.GetEditor().GetCursor().SetBufferPos(ast.BufferPos{Line: 3})
+	assert.Contains(t, statusBar(model), "]  Chapter 2 ▸ Setup ▸ Install")
+	model.GetEditor().GetCursor().SetBufferPos(ast.BufferPos{Line: 5})
+	assert.Contains(t, statusBar(model), "]  Chapter 2 ▸ Usage")
+
+	testutils.SetModelSize(model, 50, 10)
+	model.GetEditor().GetCursor().SetBufferPos(ast.BufferPos{Line: 3})
+	assert.Contains(t, statusBar(model), "]  … ▸ Install", "Outer headings give way first")
+
+	cfg := config.Default()
+	cfg.Breadcrumb = false
+	model.SetConfig(cfg)
+	assert.NotContains(t, statusBar(model), "Install")
+}
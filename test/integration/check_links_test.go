@@ -0,0 +1,56 @@
+package integration
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/ofri/mde/internal/config"
+	"github.com/ofri/mde/internal/plugins"
+	"github.com/ofri/mde/internal/tui"
+	"github.com/ofri/mde/pkg/ast"
+	"github.com/ofri/mde/pkg/plugin"
+	"github.com/ofri/mde/test/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckLinks_ReportsAndJumps(t *testing.T) {
+	plugin.ResetRegistry()
+	require.NoError(t, plugins.InitializePlugins())
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "there.md"), []byte("# There\n"), 0o644))
+	path := filepath.Join(dir, "notes.md")
+	content := "# Notes\n\nSee [there](there.md) and [gone](gone.md).\n\n![missing](img/missing.png)\n"
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+
+	model := tui.New()
+	model.SetConfig(config.Default())
+	model.SetFilename(path)
+	testutils.SetModelSize(model, 100, 12)
+
+	cmd, ok := model.RunCommand("check-links")
+	require.True(t, ok)
+	require.NotNil(t, cmd)
+	model.Update(cmd())
+	assert.Contains(t, model.View(), "2 links of 3 broken (F8: next)")
+
+	editor := model.GetEditor()
+	model.Update(tea.KeyPressMsg(tea.Key{Code: tea.KeyF8}))
+	assert.Equal(t, ast.BufferPos{Line: 2, Col: 26}, editor.GetCursor().GetBufferPos())
+	assert.Contains(t, model.View(), "error: File not found: gone.md")
+
+	// Broken links follow their lines through edits above them
+	editor.GetCursor().SetBufferPos(ast.BufferPos{Line: 0, Col: 7})
+	model.Update(tea.KeyPressMsg(tea.Key{Code: tea.KeyEnter}))
+	model.Update(tea.KeyPressMsg(tea.Key{Code: tea.KeyF8}))
+	assert.Equal(t, ast.BufferPos{Line: 3, Col: 26}, editor.GetCursor().GetBufferPos())
+	model.Update(tea.KeyPressMsg(tea.Key{Code: tea.KeyF8}))
+	assert.Equal(t, ast.BufferPos{Line: 5, Col: 0}, editor.GetCursor().GetBufferPos())
+	assert.Contains(t, model.View(), "File not found: img/missing.png")
+
+	model.Update(tea.KeyPressMsg(tea.Key{Code: tea.KeyF8, Mod: tea.ModShift}))
+	assert.Equal(t, ast.BufferPos{Line: 3, Col: 26}, editor.GetCursor().GetBufferPos())
+}
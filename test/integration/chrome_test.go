@@ -0,0 +1,58 @@
+package integration
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/ofri/mde/internal/config"
+	"github.com/ofri/mde/internal/plugins"
+	"github.com/ofri/mde/internal/tui"
+	"github.com/ofri/mde/pkg/plugin"
+	"github.com/ofri/mde/test/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChrome_ToggleBars(t *testing.T) {
+	plugin.ResetRegistry()
+	require.NoError(t, plugins.InitializePlugins())
+
+	model := tui.New()
+	testutils.LoadContentIntoModel(model, "text")
+	testutils.SetModelSize(model, 80, 10)
+	require.Equal(t, 8, model.GetContentHeight())
+
+	_, ok := model.RunCommand("toggle-help-bar")
+	require.True(t, ok)
+	assert.Equal(t, 9, model.GetContentHeight())
+	assert.Equal(t, 9, model.GetEditor().GetViewport().GetHeight(), "Viewport grows into the freed row")
+	assert.NotContains(t, model.View(), "^O Open")
+
+	model.RunCommand("toggle-status-bar")
+	assert.Equal(t, 10, model.GetContentHeight())
+	assert.NotContains(t, model.View(), "Ln 1, Col 1")
+	assert.Len(t, strings.Split(model.View(), "\n"), 10)
+}
+
+func TestChrome_PromptShowsHiddenHelpBar(t *testing.T) {
+	plugin.ResetRegistry()
+	require.NoError(t, plugins.InitializePlugins())
+
+	cfg := config.Default()
+	cfg.ShowHelpBar = false
+	model := tui.New()
+	model.SetConfig(cfg)
+	testutils.LoadContentIntoModel(model, "text")
+	testutils.SetModelSize(model, 80, 10)
+	require.Equal(t, 9, model.GetEditor().GetViewport().GetHeight())
+
+	model.Update(tea.KeyPressMsg(tea.Key{Code: 'g', Mod: tea.ModCtrl}))
+	assert.Contains(t, model.View(), "Goto line:")
+	assert.Equal(t, 8, model.GetEditor().GetViewport().GetHeight())
+
+	// Enter on an empty prompt closes it
+	model.Update(tea.KeyPressMsg(tea.Key{Code: tea.KeyEnter}))
+	assert.NotContains(t, model.View(), "Goto line:")
+	assert.Equal(t, 9, model.GetEditor().GetViewport().GetHeight())
+}
@@ -0,0 +1,110 @@
+package integration
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/ofri/mde/internal/config"
+	"github.com/ofri/mde/internal/plugins"
+	"github.com/ofri/mde/internal/tui"
+	"github.com/ofri/mde/pkg/ast"
+	"github.com/ofri/mde/pkg/plugin"
+	"github.com/ofri/mde/test/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testBibliography = `@article{smith2020,
+  author = {Smith, Jane},
+  title = {Citing Things},
+  journal = {Journal of Examples},
+  year = 2020
+}
+@book{smithers1999, author = {Ann Smithers}, title = {Books}, year = 1999}
+@misc{doe2021, author = {John Doe}, title = {Smith Revisited}, year = 2021}
+`
+
+// citationModel opens content in a directory holding refs.bib, the
+// relative bibliography setting.
+func citationModel(t *testing.T, content string) *tui.Model {
+	plugin.ResetRegistry()
+	require.NoError(t, plugins.InitializePlugins())
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "refs.bib"), []byte(testBibliography), 0o644))
+
+	cfg := config.Default()
+	cfg.Bibliography = "refs.bib"
+	model := tui.New()
+	model.SetConfig(cfg)
+	model.NewBuffer(content, filepath.Join(dir, "paper.md"))
+	testutils.SetModelSize(model, 100, 20)
+	if model.GetEditor().ShowLineNumbers() {
+		model.GetEditor().ToggleLineNumbers()
+	}
+	return model
+}
+
+func TestCitation_TabCompletesKey(t *testing.T) {
+	model := citationModel(t, "")
+
+	typeText(model, "As argued [@smith")
+	model.Update(tea.KeyPressMsg(tea.Key{Code: tea.KeyTab}))
+	view := model.View()
+	assert.Contains(t, view, "smith2020  Smith (2020). Citing Things. Journal of Examples")
+	assert.Contains(t, view, "smithers1999")
+	assert.Contains(t, view, "doe2021", "Titles match too")
+
+	typeText(model, "e")
+	assert.NotContains(t, model.View(), "smith2020  Smith")
+	model.Update(tea.KeyPressMsg(tea.Key{Code: tea.KeyEnter}))
+	assert.Equal(t, "As argued [@smithers1999]", model.GetEditor().GetDocument().GetLine(0))
+}
+
+func TestCitation_InsertCitationPicker(t *testing.T) {
+	model := citationModel(t, "Text")
+	model.GetEditor().GetCursor().SetBufferPos(ast.BufferPos{Line: 0, Col: 4})
+
+	_, ok := model.RunCommand("insert-citation")
+	require.True(t, ok)
+	assert.Equal(t, "Text[@", model.GetEditor().GetDocument().GetLine(0))
+	assert.Contains(t, model.View(), "doe2021")
+
+	typeText(model, "doe")
+	model.Update(tea.KeyPressMsg(tea.Key{Code: tea.KeyEnter}))
+	assert.Equal(t, "Text[@doe2021]", model.GetEditor().GetDocument().GetLine(0))
+
+	// Within a citation, another key is added to it
+	model.GetEditor().GetCursor().SetBufferPos(ast.BufferPos{Line: 0, Col: 7})
+	_, ok = model.RunCommand("insert-citation")
+	require.True(t, ok)
+	typeText(model, "smith2")
+	model.Update(tea.KeyPressMsg(tea.Key{Code: tea.KeyTab}))
+	assert.Equal(t, "Text[@doe2021; @smith2020]", model.GetEditor().GetDocument().GetLine(0))
+}
+
+func TestCitation_HoverShowsReference(t *testing.T) {
+	model := citationModel(t, "See [@smith2020; @nobody].\n")
+
+	model.Update(tea.MouseMotionMsg(tea.Mouse{X: 8, Y: 0}))
+	assert.Contains(t, statusLine(model), "@smith2020: Smith (2020). Citing Things")
+
+	model.Update(tea.MouseMotionMsg(tea.Mouse{X: 20, Y: 0}))
+	assert.Contains(t, statusLine(model), "Unknown citation @nobody")
+}
+
+func TestCitation_NoBibliography(t *testing.T) {
+	plugin.ResetRegistry()
+	require.NoError(t, plugins.InitializePlugins())
+
+	model := tui.New()
+	model.NewBuffer("", "paper.md")
+	testutils.SetModelSize(model, 80, 20)
+
+	_, ok := model.RunCommand("insert-citation")
+	require.True(t, ok)
+	assert.Contains(t, model.View(), "No bibliography (set bibliography)")
+	assert.Equal(t, "", model.GetEditor().GetDocument().GetText())
+}
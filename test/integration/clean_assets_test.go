@@ -0,0 +1,74 @@
+package integration
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/ofri/mde/internal/config"
+	"github.com/ofri/mde/internal/plugins"
+	"github.com/ofri/mde/internal/tui"
+	"github.com/ofri/mde/pkg/plugin"
+	"github.com/ofri/mde/test/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCleanAssets_DeletesAfterReport(t *testing.T) {
+	plugin.ResetRegistry()
+	require.NoError(t, plugins.InitializePlugins())
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "notes.md")
+	require.NoError(t, os.WriteFile(path, []byte("![](assets/kept.png)\n"), 0o644))
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "assets"), 0o755))
+	for _, name := range []string{"kept.png", "old.png"} {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "assets", name), []byte("PNG"), 0o644))
+	}
+
+	model := tui.New()
+	model.SetConfig(config.Default())
+	model.SetFilename(path)
+	testutils.SetModelSize(model, 120, 10)
+
+	cmd, ok := model.RunCommand("clean-assets")
+	require.True(t, ok)
+	require.NotNil(t, cmd)
+	model.Update(cmd())
+	view := model.View()
+	assert.Contains(t, view, "1 asset unused in assets (3 B): old.png")
+	assert.Contains(t, view, "d: Delete")
+	assert.FileExists(t, filepath.Join(dir, "assets", "old.png"), "Nothing is deleted before the answer")
+
+	model.Update(tea.KeyPressMsg(tea.Key{Code: 'd', Text: "d"}))
+	assert.Contains(t, model.View(), "Deleted 1 unused asset (3 B)")
+	assert.NoFileExists(t, filepath.Join(dir, "assets", "old.png"))
+	assert.FileExists(t, filepath.Join(dir, "assets", "kept.png"))
+}
+
+func TestCleanAssets_MovesAside(t *testing.T) {
+	plugin.ResetRegistry()
+	require.NoError(t, plugins.InitializePlugins())
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "notes.md")
+	require.NoError(t, os.WriteFile(path, []byte("No images\n"), 0o644))
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "assets"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "assets", "old.png"), []byte("PNG"), 0o644))
+
+	model := tui.New()
+	model.SetConfig(config.Default())
+	model.SetFilename(path)
+	testutils.SetModelSize(model, 120, 10)
+
+	cmd, _ := model.RunCommand("clean-assets")
+	model.Update(cmd())
+	model.Update(tea.KeyPressMsg(tea.Key{Code: 'm', Text: "m"}))
+	assert.Contains(t, model.View(), "Moved 1 unused asset to assets/.unused")
+	assert.FileExists(t, filepath.Join(dir, "assets", ".unused", "old.png"))
+
+	cmd, _ = model.RunCommand("clean-assets")
+	model.Update(cmd())
+	assert.Contains(t, model.View(), "No unused assets among the 0 in assets")
+}
@@ -0,0 +1,68 @@
+package integration
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/ofri/mde/internal/plugins"
+	"github.com/ofri/mde/internal/tui"
+	"github.com/ofri/mde/pkg/ast"
+	"github.com/ofri/mde/pkg/plugin"
+	"github.com/ofri/mde/test/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCommands_Registered(t *testing.T) {
+	names := tui.CommandNames()
+	assert.Contains(t, names, "copy-as-html")
+	assert.Contains(t, names, "copy-as-rich-text")
+}
+
+func TestCommands_UnknownCommand(t *testing.T) {
+	model := tui.New()
+	_, ok := model.RunCommand("no-such-command")
+	assert.False(t, ok, "Unknown commands should not run")
+}
+
+func TestCommands_CopyAsHTMLRequiresSelection(t *testing.T) {
+	model := tui.New()
+	testutils.LoadContentIntoModel(model, "**bold** text")
+
+	cmd, ok := model.RunCommand("copy-as-html")
+	assert.True(t, ok)
+	assert.Nil(t, cmd, "Nothing to copy without a selection")
+}
+
+func TestCommands_CopyAsHTMLWithSelection(t *testing.T) {
+	model := tui.New()
+	testutils.LoadContentIntoModel(model, "**bold** text")
+
+	cursor := model.GetEditor().GetCursor()
+	cursor.SetSelection(&ast.Selection{
+		Start: ast.BufferPos{Line: 0, Col: 0},
+		End:   ast.BufferPos{Line: 0, Col: 8},
+	})
+
+	cmd, ok := model.RunCommand("copy-as-html")
+	assert.True(t, ok)
+	assert.NotNil(t, cmd, "Should produce a clipboard command")
+}
+
+func TestCommands_PromptRunsCommand(t *testing.T) {
+	plugin.ResetRegistry()
+	require.NoError(t, plugins.InitializePlugins())
+
+	model := tui.New()
+	testutils.LoadContentIntoModel(model, "text")
+	testutils.SetModelSize(model, 120, 10)
+
+	model.Update(tea.KeyPressMsg(tea.Key{Code: 'x', Mod: tea.ModAlt}))
+	for _, r := range "copy-as-h" {
+		model.Update(tea.KeyPressMsg(tea.Key{Code: r, Text: string(r)}))
+	}
+	model.Update(tea.KeyPressMsg(tea.Key{Code: tea.KeyTab}))
+	model.Update(tea.KeyPressMsg(tea.Key{Code: tea.KeyEnter}))
+
+	assert.Contains(t, model.View(), "Nothing selected", "Completed command should run and report")
+}
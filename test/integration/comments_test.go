@@ -0,0 +1,121 @@
+package integration
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/ofri/mde/internal/annotations"
+	"github.com/ofri/mde/internal/config"
+	"github.com/ofri/mde/internal/plugins"
+	"github.com/ofri/mde/internal/tui"
+	"github.com/ofri/mde/pkg/ast"
+	"github.com/ofri/mde/pkg/plugin"
+	"github.com/ofri/mde/test/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// commentModel opens content as notes.md in a temporary directory,
+// returning the model and the document's path.
+func commentModel(t *testing.T, content string) (*tui.Model, string) {
+	plugin.ResetRegistry()
+	require.NoError(t, plugins.InitializePlugins())
+
+	path := filepath.Join(t.TempDir(), "notes.md")
+	cfg := config.Default()
+	cfg.Author = "Ann"
+	model := tui.New()
+	model.SetConfig(cfg)
+	model.NewBuffer(content, path)
+	testutils.SetModelSize(model, 100, 20)
+	return model, path
+}
+
+func TestComments_AddShowAndDelete(t *testing.T) {
+	model, path := commentModel(t, "# Notes\nThe quick brown fox")
+	model.GetEditor().GetCursor().SetBufferPos(ast.BufferPos{Line: 1, Col: 6})
+	model.GetEditor().GetDocument().ClearModified()
+
+	_, ok := model.RunCommand("add-comment")
+	require.True(t, ok)
+	typeText(model, "Which one?")
+	model.Update(tea.KeyPressMsg(tea.Key{Code: tea.KeyEnter}))
+
+	doc := model.GetEditor().GetDocument()
+	assert.Equal(t, "# Notes\nThe quick brown fox", doc.GetText())
+	assert.False(t, doc.IsModified(), "Comments leave the document as it was")
+	comments, err := annotations.Load(path)
+	require.NoError(t, err)
+	require.Len(t, comments, 1)
+	assert.Equal(t, "quick", comments[0].Quote)
+	assert.Equal(t, "Which one?", comments[0].Text)
+	assert.Equal(t, "Ann", comments[0].Author)
+
+	assert.Contains(t, model.View(), "●", "The line is marked in the gutter")
+
+	_, ok = model.RunCommand("show-comments")
+	require.True(t, ok)
+	model.GetEditor().GetCursor().SetBufferPos(ast.BufferPos{Line: 1, Col: 0})
+	view := model.View()
+	assert.Contains(t, view, " Ann, ")
+	assert.Contains(t, view, " Which one? ")
+
+	model.Update(tea.KeyPressMsg(tea.Key{Code: tea.KeyEscape}))
+	assert.NotContains(t, model.View(), " Which one? ")
+
+	_, ok = model.RunCommand("delete-comment")
+	require.True(t, ok)
+	assert.NoFileExists(t, path+annotations.Suffix)
+	assert.NotContains(t, model.View(), "●")
+}
+
+func TestComments_FollowEditsAndJump(t *testing.T) {
+	plugin.ResetRegistry()
+	require.NoError(t, plugins.InitializePlugins())
+
+	path := filepath.Join(t.TempDir(), "notes.md")
+	content := "Intro\nThe quick brown fox\njumps over the dog"
+	doc := ast.NewDocument(content)
+	require.NoError(t, annotations.Save(path, []annotations.Comment{
+		annotations.New(doc, ast.Range{Start: ast.BufferPos{Line: 1, Col: 4}, End: ast.BufferPos{Line: 1, Col: 9}}, "First", "", time.Time{}),
+		annotations.New(doc, ast.Range{Start: ast.BufferPos{Line: 2, Col: 15}, End: ast.BufferPos{Line: 2, Col: 18}}, "Second", "", time.Time{}),
+	}))
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+
+	model := tui.New()
+	model.NewBuffer(content, path)
+	testutils.SetModelSize(model, 100, 20)
+
+	// A line typed above moves the comments down with their text
+	typeText(model, "Title")
+	model.Update(tea.KeyPressMsg(tea.Key{Code: tea.KeyEnter}))
+
+	_, ok := model.RunCommand("next-comment")
+	require.True(t, ok)
+	assert.Equal(t, ast.BufferPos{Line: 2, Col: 4}, model.GetEditor().GetCursor().GetBufferPos())
+	assert.Contains(t, model.View(), " First ")
+	_, ok = model.RunCommand("next-comment")
+	require.True(t, ok)
+	assert.Equal(t, ast.BufferPos{Line: 3, Col: 15}, model.GetEditor().GetCursor().GetBufferPos())
+	_, ok = model.RunCommand("previous-comment")
+	require.True(t, ok)
+	assert.Equal(t, ast.BufferPos{Line: 2, Col: 4}, model.GetEditor().GetCursor().GetBufferPos())
+
+	// With the popup closed, the status line shows the comment
+	model.Update(tea.KeyPressMsg(tea.Key{Code: tea.KeyEscape}))
+	view := model.View()
+	assert.Contains(t, view, "jumps over the dog", "The popup no longer covers the next line")
+	assert.Contains(t, view, "Comment: First")
+
+	// Saving the document records where the comments moved to
+	_, cmd := model.Update(tea.KeyPressMsg(tea.Key{Code: 's', Mod: tea.ModCtrl}))
+	runCmd(model, cmd)
+	comments, err := annotations.Load(path)
+	require.NoError(t, err)
+	require.Len(t, comments, 2)
+	assert.Equal(t, 3, comments[0].Line)
+	assert.Equal(t, 4, comments[1].Line)
+}
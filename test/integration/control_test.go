@@ -0,0 +1,53 @@
+package integration
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/ofri/mde/internal/plugins"
+	"github.com/ofri/mde/internal/tui"
+	"github.com/ofri/mde/pkg/plugin"
+	"github.com/ofri/mde/test/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestControl_DrivesModel(t *testing.T) {
+	plugin.ResetRegistry()
+	require.NoError(t, plugins.InitializePlugins())
+
+	path := filepath.Join(t.TempDir(), "notes.md")
+	require.NoError(t, os.WriteFile(path, []byte("# Notes\n\nSee [there](#nowhere)"), 0o644))
+	model := tui.New()
+	testutils.SetModelSize(model, 80, 10)
+	handler := tui.ControlHandler(func(msg tea.Msg) { model.Update(msg) })
+	call := func(method, params string) (string, error) {
+		result, err := handler(context.Background(), method, json.RawMessage(params))
+		data, _ := json.Marshal(result)
+		return string(data), err
+	}
+
+	_, err := call("open", `{"path":"`+path+`"}`)
+	require.NoError(t, err)
+	result, err := call("goto", `{"line":2}`)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"line":2,"column":1}`, result)
+	_, err = call("insert", `{"text":"Hello"}`)
+	require.NoError(t, err)
+	assert.Equal(t, "# Notes\nHello\nSee [there](#nowhere)", model.GetEditor().GetDocument().GetText())
+
+	result, err = call("diagnostics", ``)
+	require.NoError(t, err)
+	assert.JSONEq(t, `[{"start":{"line":3,"column":5},"end":{"line":3,"column":22},"severity":"error","message":"No heading with anchor #nowhere","rule":"heading-anchor"}]`, result)
+
+	_, err = call("open", `{"path":"`+path+`"}`)
+	assert.ErrorContains(t, err, "unsaved changes")
+	_, err = call("command", `{"name":"frobnicate"}`)
+	assert.ErrorContains(t, err, "unknown command")
+	_, err = call("teleport", ``)
+	assert.Error(t, err)
+}
@@ -0,0 +1,120 @@
+package integration
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+	"github.com/ofri/mde/internal/config"
+	"github.com/ofri/mde/internal/plugins"
+	"github.com/ofri/mde/internal/tui"
+	"github.com/ofri/mde/pkg/ast"
+	"github.com/ofri/mde/pkg/plugin"
+	"github.com/ofri/mde/test/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func cursorStyleModel(t *testing.T, cfg *config.Config) *tui.Model {
+	t.Helper()
+	plugin.ResetRegistry()
+	require.NoError(t, plugins.InitializePlugins())
+
+	model := tui.New()
+	model.SetConfig(cfg)
+	model.NewBuffer("hello", "notes.md")
+	testutils.SetModelSize(model, 80, 10)
+	return model
+}
+
+func TestCursorStyle_DrawnGlyphs(t *testing.T) {
+	cfg := config.Default()
+	cfg.CursorStyle = "bar"
+	cfg.CursorOverwriteStyle = "block"
+	model := cursorStyleModel(t, cfg)
+
+	view := model.View()
+	assert.Contains(t, view, "▏")
+	assert.NotContains(t, view, "█")
+
+	model.Update(tea.KeyPressMsg(tea.Key{Code: tea.KeyInsert}))
+	view = model.View()
+	assert.Contains(t, view, "█", "Overwriting has its own style")
+	assert.NotContains(t, view, "▏")
+}
+
+func TestCursorStyle_TerminalCursor(t *testing.T) {
+	cfg := config.Default()
+	cfg.CursorStyle = "bar"
+	cfg.CursorBlink = false
+	model := cursorStyleModel(t, cfg)
+	program, ok := tui.WithTerminalCursor(model).(interface {
+		View() (string, *tea.Cursor)
+	})
+	require.True(t, ok)
+
+	_, cursor := program.View()
+	assert.Nil(t, cursor, "The cursor is drawn by default")
+
+	_, ok = model.RunCommand("terminal-cursor")
+	require.True(t, ok)
+	view, cursor := program.View()
+	require.NotNil(t, cursor)
+	assert.Equal(t, tea.CursorBar, cursor.Shape)
+	assert.False(t, cursor.Blink)
+	assert.NotContains(t, view, "▏", "No glyph hides a character")
+
+	model.Update(tea.KeyPressMsg(tea.Key{Code: tea.KeyInsert}))
+	_, cursor = program.View()
+	require.NotNil(t, cursor)
+	assert.Equal(t, tea.CursorUnderline, cursor.Shape, "Underlined while overwriting")
+
+	model.Update(tea.KeyPressMsg(tea.Key{Code: 'p', Mod: tea.ModCtrl}))
+	require.True(t, model.IsPreviewMode())
+	_, cursor = program.View()
+	assert.Nil(t, cursor, "Hidden in preview by default")
+
+	cfg.CursorPreviewStyle = "block"
+	_, cursor = program.View()
+	require.NotNil(t, cursor)
+	assert.Equal(t, tea.CursorBlock, cursor.Shape)
+	assert.Equal(t, tea.Position{X: 0, Y: 0}, cursor.Position)
+}
+
+// withAttributes renders text attributes for the rest of the test, as on
+// a terminal.
+func withAttributes(t *testing.T) {
+	t.Helper()
+	profile := lipgloss.ColorProfile()
+	lipgloss.SetColorProfile(termenv.ANSI)
+	t.Cleanup(func() { lipgloss.SetColorProfile(profile) })
+}
+
+func TestCursorStyle_ReverseVideoKeepsCharacter(t *testing.T) {
+	withAttributes(t)
+	model := cursorStyleModel(t, config.Default())
+	model.GetEditor().GetCursor().SetBufferPos(ast.BufferPos{Line: 0, Col: 1})
+
+	view := model.View()
+	assert.Contains(t, view, "h\x1b[7me\x1b[0mllo", "The cursor reverses the e beneath it")
+	assert.NotContains(t, view, "█")
+
+	model.Update(tea.KeyPressMsg(tea.Key{Code: tea.KeyInsert}))
+	assert.Regexp(t, `h\x1b\[4(;4)?me\x1b\[0mllo`, model.View(), "Underlined while overwriting")
+}
+
+func TestCursorStyle_SelectionInReverseVideo(t *testing.T) {
+	withAttributes(t)
+	model := cursorStyleModel(t, config.Default())
+	model.GetEditor().GetCursor().SetBufferPos(ast.BufferPos{Line: 0, Col: 3})
+	model.GetEditor().GetCursor().SetSelection(&ast.Selection{
+		Start: ast.BufferPos{Line: 0, Col: 0},
+		End:   ast.BufferPos{Line: 0, Col: 3},
+	})
+
+	assert.Contains(t, model.View(), "\x1b[7mhel\x1b[0m\x1b[7ml\x1b[0mo", "The selection is reversed up to the cursor")
+
+	model.GetEditor().GetCursor().SetBufferPos(ast.BufferPos{Line: 0, Col: 1})
+	assert.Contains(t, model.View(), "\x1b[7mh\x1b[0me\x1b[7ml\x1b[0mlo", "The cursor shows inside the selection")
+}
@@ -0,0 +1,60 @@
+package integration
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/ofri/mde/internal/plugins"
+	"github.com/ofri/mde/internal/tui"
+	"github.com/ofri/mde/pkg/plugin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiffView_SideBySide(t *testing.T) {
+	plugin.ResetRegistry()
+	require.NoError(t, plugins.InitializePlugins())
+
+	view, err := tui.NewDiffView("a.md", "# Title\nsame\nold text\n", "b.md", "# Title\nsame\nnew text\nadded\n")
+	require.NoError(t, err)
+	assert.Equal(t, 1, view.Changes())
+	view.Update(tea.WindowSizeMsg{Width: 41, Height: 8})
+
+	lines := strings.Split(ansi.Strip(view.View()), "\n")
+	require.Len(t, lines, 8)
+	assert.Equal(t, " a.md                 b.md               ", lines[0])
+	assert.Equal(t, "1   # Title         │1   # Title         ", lines[1])
+	assert.Equal(t, "3 - old text        │3 + new text        ", lines[3])
+	assert.Equal(t, "                    │4 + added           ", lines[4])
+	assert.Contains(t, lines[7], "1 changes")
+
+	_, cmd := view.Update(tea.KeyPressMsg(tea.Key{Code: 'q', Text: "q"}))
+	require.NotNil(t, cmd)
+	assert.IsType(t, tea.QuitMsg{}, cmd())
+}
+
+func TestDiffView_NextChange(t *testing.T) {
+	plugin.ResetRegistry()
+	require.NoError(t, plugins.InitializePlugins())
+
+	var a, b strings.Builder
+	for i := 1; i <= 40; i++ {
+		fmt.Fprintf(&a, "line %d\n", i)
+		if i == 30 {
+			b.WriteString("changed\n")
+		} else {
+			fmt.Fprintf(&b, "line %d\n", i)
+		}
+	}
+	view, err := tui.NewDiffView("a.md", a.String(), "b.md", b.String())
+	require.NoError(t, err)
+	view.Update(tea.WindowSizeMsg{Width: 60, Height: 10})
+	assert.NotContains(t, view.View(), "changed")
+
+	view.Update(tea.KeyPressMsg(tea.Key{Code: 'n', Text: "n"}))
+	lines := strings.Split(ansi.Strip(view.View()), "\n")
+	assert.Contains(t, lines[4], "30 + changed", "The change shows below a few rows of context")
+}
@@ -0,0 +1,80 @@
+package integration
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/ofri/mde/internal/config"
+	"github.com/ofri/mde/internal/editorconfig"
+	"github.com/ofri/mde/internal/plugins"
+	"github.com/ofri/mde/internal/tui"
+	"github.com/ofri/mde/pkg/ast"
+	"github.com/ofri/mde/pkg/plugin"
+	"github.com/ofri/mde/test/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// editorConfigModel opens the file content is written to, beside an
+// .editorconfig, with cfg.
+func editorConfigModel(t *testing.T, cfg *config.Config, settings, content string) (*tui.Model, string) {
+	plugin.ResetRegistry()
+	require.NoError(t, plugins.InitializePlugins())
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, editorconfig.Filename), []byte(settings), 0o644))
+	path := filepath.Join(dir, "notes.md")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+
+	model := tui.New()
+	model.SetConfig(cfg)
+	model.SetFilename(path)
+	testutils.SetModelSize(model, 80, 10)
+	return model, path
+}
+
+// save saves the buffer, returning what reached the disk.
+func save(t *testing.T, model *tui.Model, path string) string {
+	_, cmd := model.Update(tea.KeyPressMsg(tea.Key{Code: 's', Mod: tea.ModCtrl}))
+	require.NotNil(t, cmd)
+	model.Update(cmd())
+	saved, err := os.ReadFile(path)
+	require.NoError(t, err)
+	return string(saved)
+}
+
+func TestEditorConfig_AppliedOnOpenAndSave(t *testing.T) {
+	model, path := editorConfigModel(t, config.Default(), `root = true
+
+[*.md]
+indent_style = space
+indent_size = 2
+trim_trailing_whitespace = true
+insert_final_newline = true
+end_of_line = crlf
+`, "# Notes \t\r\n\r\ntext")
+	doc := model.GetEditor().GetDocument()
+	assert.Equal(t, "# Notes \t\n\ntext", doc.GetText(), "The line breaks read as lines")
+	assert.False(t, doc.IsModified())
+	assert.Equal(t, 2, model.GetEditor().GetViewport().GetTabWidth())
+
+	model.GetEditor().GetCursor().SetBufferPos(ast.BufferPos{Line: 2, Col: 1})
+	model.Update(tea.KeyPressMsg(tea.Key{Code: tea.KeyTab}))
+	assert.Equal(t, "t ext", doc.GetLine(2), "Tab inserts spaces up to the next tab stop")
+
+	assert.Equal(t, "# Notes\r\n\r\nt ext\r\n", save(t, model, path))
+}
+
+func TestEditorConfig_UserConfigWins(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.toml")
+	require.NoError(t, os.WriteFile(configPath, []byte("indent_style = \"tab\"\nstrip_trailing_whitespace = false\n"), 0o644))
+	cfg, err := config.LoadFile(configPath)
+	require.NoError(t, err)
+
+	model, path := editorConfigModel(t, cfg, "[*]\nindent_style = space\ntrim_trailing_whitespace = true\ninsert_final_newline = false\n", "text \n\n")
+	model.GetEditor().GetCursor().SetBufferPos(ast.BufferPos{})
+	model.Update(tea.KeyPressMsg(tea.Key{Code: tea.KeyTab}))
+	assert.Equal(t, "\ttext ", save(t, model, path))
+}
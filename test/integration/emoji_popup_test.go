@@ -0,0 +1,59 @@
+package integration
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/ofri/mde/internal/plugins"
+	"github.com/ofri/mde/internal/tui"
+	"github.com/ofri/mde/pkg/plugin"
+	"github.com/ofri/mde/test/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func typeText(model *tui.Model, text string) {
+	for _, r := range text {
+		model.Update(tea.KeyPressMsg(tea.Key{Code: r, Text: string(r)}))
+	}
+}
+
+func TestEmojiPopup_CompletesShortcode(t *testing.T) {
+	plugin.ResetRegistry()
+	require.NoError(t, plugins.InitializePlugins())
+
+	model := tui.New()
+	testutils.LoadContentIntoModel(model, "")
+	testutils.SetModelSize(model, 80, 20)
+
+	typeText(model, "hi :smil")
+	model.Update(tea.KeyPressMsg(tea.Key{Code: tea.KeyTab}))
+
+	view := model.View()
+	assert.Contains(t, view, "smile", "Popup should list candidates")
+	assert.Contains(t, view, "smiley")
+
+	// Typing narrows the list and keeps the popup open
+	typeText(model, "ey")
+	assert.NotContains(t, model.View(), "smile_cat")
+
+	model.Update(tea.KeyPressMsg(tea.Key{Code: tea.KeyEnter}))
+	assert.Equal(t, "hi :smiley:", model.GetEditor().GetDocument().GetLine(0))
+}
+
+func TestEmojiPopup_EscCloses(t *testing.T) {
+	plugin.ResetRegistry()
+	require.NoError(t, plugins.InitializePlugins())
+
+	model := tui.New()
+	testutils.LoadContentIntoModel(model, "")
+	testutils.SetModelSize(model, 80, 20)
+
+	typeText(model, ":thumbs")
+	model.Update(tea.KeyPressMsg(tea.Key{Code: tea.KeyTab}))
+	model.Update(tea.KeyPressMsg(tea.Key{Code: tea.KeyEscape}))
+	model.Update(tea.KeyPressMsg(tea.Key{Code: tea.KeyEnter}))
+
+	assert.Equal(t, ":thumbs", model.GetEditor().GetDocument().GetLine(0), "Enter after Esc is a newline")
+	assert.Equal(t, 2, model.GetEditor().GetDocument().LineCount())
+}
@@ -0,0 +1,92 @@
+package integration
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/ofri/mde/internal/plugins"
+	"github.com/ofri/mde/internal/tui"
+	"github.com/ofri/mde/pkg/plugin"
+	"github.com/ofri/mde/test/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// runKey sends a key and runs the command it returns, feeding the result
+// back to the model.
+func runKey(model *tui.Model, key tea.Key) {
+	_, cmd := model.Update(tea.KeyPressMsg(key))
+	if cmd != nil {
+		if msg := cmd(); msg != nil {
+			model.Update(msg)
+		}
+	}
+}
+
+func TestEncryptedNote_SaveAndReopen(t *testing.T) {
+	if _, err := exec.LookPath("gpg"); err != nil {
+		t.Skip("gpg not installed")
+	}
+	plugin.ResetRegistry()
+	require.NoError(t, plugins.InitializePlugins())
+	t.Setenv("GNUPGHOME", t.TempDir())
+	path := filepath.Join(t.TempDir(), "diary.md.gpg")
+	enter := tea.Key{Code: tea.KeyEnter}
+
+	// A new note opens empty and asks for a passphrase, twice, on its
+	// first save
+	model := tui.New()
+	model.SetFilename(path)
+	testutils.SetModelSize(model, 100, 10)
+	typeText(model, "Dear diary")
+	runKey(model, tea.Key{Code: 's', Mod: tea.ModCtrl})
+	assert.Contains(t, ansi.Strip(model.View()), "Passphrase for "+path)
+	typeText(model, "hunter2")
+	assert.Contains(t, ansi.Strip(model.View()), "*******")
+	assert.NotContains(t, ansi.Strip(model.View()), "hunter2")
+	runKey(model, enter)
+	assert.Contains(t, ansi.Strip(model.View()), "Confirm passphrase for "+path)
+	typeText(model, "hunter3")
+	runKey(model, enter)
+	assert.Contains(t, ansi.Strip(model.View()), "Passphrases do not match")
+	assert.NoFileExists(t, path)
+
+	runKey(model, tea.Key{Code: 's', Mod: tea.ModCtrl})
+	typeText(model, "hunter2")
+	runKey(model, enter)
+	typeText(model, "hunter2")
+	runKey(model, enter)
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "Dear diary")
+	assert.False(t, model.GetEditor().GetDocument().IsModified())
+
+	// Reopening asks for the passphrase before replacing the buffer
+	model = tui.New()
+	testutils.SetModelSize(model, 100, 10)
+	model.SetFilename(path)
+	assert.Contains(t, ansi.Strip(model.View()), "Passphrase for "+path)
+	typeText(model, "wrong")
+	runKey(model, enter)
+	assert.Contains(t, ansi.Strip(model.View()), "Cannot decrypt")
+	assert.Empty(t, model.GetEditor().GetDocument().GetText())
+
+	model.SetFilename(path)
+	typeText(model, "hunter2")
+	runKey(model, enter)
+	assert.Equal(t, "Dear diary", model.GetEditor().GetDocument().GetText())
+	assert.False(t, model.GetEditor().GetDocument().IsModified())
+
+	// Saving again reuses the passphrase
+	typeText(model, "!")
+	runKey(model, tea.Key{Code: 's', Mod: tea.ModCtrl})
+	assert.False(t, model.GetEditor().GetDocument().IsModified())
+	data, err = os.ReadFile(path)
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "Dear diary")
+}
@@ -0,0 +1,49 @@
+package integration
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/ofri/mde/internal/config"
+	"github.com/ofri/mde/internal/plugins"
+	"github.com/ofri/mde/internal/tui"
+	"github.com/ofri/mde/pkg/plugin"
+	"github.com/ofri/mde/test/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExport_PandocFromPrompt(t *testing.T) {
+	plugin.ResetRegistry()
+	require.NoError(t, plugins.InitializePlugins())
+
+	dir := t.TempDir()
+	fake := filepath.Join(dir, "pandoc")
+	script := "#!/bin/sh\nwhile [ $# -gt 0 ]; do [ \"$1\" = --output ] && out=\"$2\"; shift; done\n" +
+		"echo '[INFO] Converting' >&2\ncat > \"$out\"\n"
+	require.NoError(t, os.WriteFile(fake, []byte(script), 0o755))
+
+	cfg := config.Default()
+	cfg.PandocCommand = fake
+	model := tui.New()
+	model.SetConfig(cfg)
+	model.NewBuffer("# Unsaved edits export too\n", filepath.Join(dir, "notes.md"))
+	testutils.SetModelSize(model, 100, 10)
+
+	_, ok := model.RunCommand("export-pandoc")
+	require.True(t, ok)
+	assert.Contains(t, model.View(), "Export to: docx", "The prompt offers docx first")
+
+	// Deliver the export's events until it is done
+	_, cmd := model.Update(tea.KeyPressMsg(tea.Key{Code: tea.KeyEnter}))
+	for cmd != nil {
+		_, cmd = model.Update(cmd())
+	}
+	assert.Contains(t, model.View(), "Exported to")
+
+	data, err := os.ReadFile(filepath.Join(dir, "notes.docx"))
+	require.NoError(t, err)
+	assert.Equal(t, "# Unsaved edits export too\n", string(data))
+}
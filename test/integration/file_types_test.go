@@ -0,0 +1,32 @@
+package integration
+
+import (
+	"testing"
+
+	"github.com/ofri/mde/internal/plugins"
+	"github.com/ofri/mde/internal/plugins/parsers"
+	"github.com/ofri/mde/pkg/plugin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileTypes_ParserForFile(t *testing.T) {
+	plugin.ResetRegistry()
+	require.NoError(t, plugins.InitializePlugins())
+	registry := plugin.GetRegistry()
+
+	assert.Equal(t, "commonmark", registry.ParserNameForFile("notes/README.MD"))
+	assert.Equal(t, "commonmark", registry.ParserNameForFile("unknown.xyz"), "Unknown extensions use the default")
+	assert.Equal(t, "commonmark", registry.ParserNameForFile(""), "Unnamed buffers use the default")
+
+	require.NoError(t, registry.RegisterParser("alt", parsers.NewCommonMarkParser()))
+	require.NoError(t, plugins.RegisterFileTypes(map[string]string{"MDX": "alt"}))
+	assert.Equal(t, "alt", registry.ParserNameForFile("page.mdx"))
+
+	parser, err := registry.GetParserForFile("page.mdx")
+	require.NoError(t, err)
+	assert.NotNil(t, parser)
+
+	err = plugins.RegisterFileTypes(map[string]string{".csv": "missing"})
+	assert.ErrorContains(t, err, "parser plugin 'missing' not registered")
+}
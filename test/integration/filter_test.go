@@ -0,0 +1,63 @@
+package integration
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/ofri/mde/internal/plugins"
+	"github.com/ofri/mde/internal/tui"
+	"github.com/ofri/mde/pkg/ast"
+	"github.com/ofri/mde/pkg/plugin"
+	"github.com/ofri/mde/test/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// runFilter filters the model's text through command at the prompt and
+// delivers the result.
+func runFilter(t *testing.T, model *tui.Model, command string) {
+	_, ok := model.RunCommand("filter-selection")
+	require.True(t, ok)
+	for range 20 { // Clear the last command, offered again
+		model.Update(tea.KeyPressMsg(tea.Key{Code: tea.KeyBackspace}))
+	}
+	for _, r := range command {
+		if r == ' ' {
+			model.Update(tea.KeyPressMsg(tea.Key{Code: tea.KeySpace, Text: " "}))
+			continue
+		}
+		model.Update(tea.KeyPressMsg(tea.Key{Code: r, Text: string(r)}))
+	}
+	_, cmd := model.Update(tea.KeyPressMsg(tea.Key{Code: tea.KeyEnter}))
+	require.NotNil(t, cmd)
+	model.Update(cmd())
+}
+
+func TestFilter_Selection(t *testing.T) {
+	model := tui.New()
+	testutils.LoadContentIntoModel(model, "# Fruit\npear\napple\nfig\n\nEnd")
+	testutils.SetModelSize(model, 80, 10)
+
+	model.GetEditor().GetCursor().SetSelection(&ast.Selection{
+		Start: ast.BufferPos{Line: 1, Col: 0},
+		End:   ast.BufferPos{Line: 4, Col: 0},
+	})
+	runFilter(t, model, "sort")
+	assert.Equal(t, "# Fruit\napple\nfig\npear\n\nEnd", model.GetEditor().GetDocument().GetText())
+}
+
+func TestFilter_WholeDocumentAndErrors(t *testing.T) {
+	plugin.ResetRegistry()
+	require.NoError(t, plugins.InitializePlugins())
+
+	model := tui.New()
+	testutils.LoadContentIntoModel(model, "one two")
+	testutils.SetModelSize(model, 80, 10)
+
+	runFilter(t, model, "tr a-z A-Z")
+	assert.Equal(t, "ONE TWO", model.GetEditor().GetDocument().GetText(), "Without a selection the whole text is filtered")
+
+	runFilter(t, model, "false")
+	assert.Equal(t, "ONE TWO", model.GetEditor().GetDocument().GetText(), "A failing command leaves the text alone")
+	assert.Contains(t, model.View(), "false failed")
+}
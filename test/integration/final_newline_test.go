@@ -0,0 +1,108 @@
+package integration
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/ofri/mde/internal/config"
+	"github.com/ofri/mde/internal/editorconfig"
+	"github.com/ofri/mde/internal/plugins"
+	"github.com/ofri/mde/internal/tui"
+	"github.com/ofri/mde/pkg/ast"
+	"github.com/ofri/mde/pkg/plugin"
+	"github.com/ofri/mde/test/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// finalNewlineModel opens a file holding content, beside an .editorconfig
+// if there is one, with the config read from settings.
+func finalNewlineModel(t *testing.T, settings, editorConfig, content string) (*tui.Model, string) {
+	plugin.ResetRegistry()
+	require.NoError(t, plugins.InitializePlugins())
+
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "config.toml")
+	require.NoError(t, os.WriteFile(configPath, []byte(settings), 0o644))
+	cfg, err := config.LoadFile(configPath)
+	require.NoError(t, err)
+	if editorConfig != "" {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, editorconfig.Filename), []byte(editorConfig), 0o644))
+	}
+	path := filepath.Join(dir, "notes.md")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+
+	model := tui.New()
+	model.SetConfig(cfg)
+	model.SetFilename(path)
+	testutils.SetModelSize(model, 160, 10) // Room for the temporary path
+	return model, path
+}
+
+// fileStatus returns the status bar's note of the file, once any message
+// shown over it has gone.
+func fileStatus(model *tui.Model, path string) string {
+	model.GetEditor().GetCursor().SetBufferPos(ast.BufferPos{})
+	for range 100 {
+		if status := statusLine(model); strings.HasPrefix(status, path) {
+			return status
+		}
+		model.Update(tea.WindowSizeMsg{Width: 160, Height: 10})
+	}
+	return statusLine(model)
+}
+
+func TestFinalNewline_KeptAsReadByDefault(t *testing.T) {
+	model, path := finalNewlineModel(t, "", "", "text")
+	assert.Contains(t, fileStatus(model, path), "notes.md [No EOL]")
+
+	typeText(model, "!")
+	assert.Equal(t, "!text", save(t, model, path))
+	assert.Contains(t, fileStatus(model, path), "[No EOL]")
+
+	model, path = finalNewlineModel(t, "", "", "text\n")
+	assert.NotContains(t, fileStatus(model, path), "[No EOL]")
+}
+
+func TestFinalNewline_KeptWhenOpenedInBackground(t *testing.T) {
+	plugin.ResetRegistry()
+	require.NoError(t, plugins.InitializePlugins())
+	path := filepath.Join(t.TempDir(), "notes.md")
+	require.NoError(t, os.WriteFile(path, []byte("# Notes\nbody\n"), 0o644))
+
+	// The control socket's open loads the file as the open prompt does
+	model := tui.New()
+	testutils.SetModelSize(model, 160, 10)
+	handler := tui.ControlHandler(func(msg tea.Msg) { model.Update(msg) })
+	_, err := handler(context.Background(), "open", json.RawMessage(`{"path":"`+path+`"}`))
+	require.NoError(t, err)
+	assert.Equal(t, "# Notes\nbody\n", model.GetEditor().GetDocument().GetText())
+	assert.NotContains(t, fileStatus(model, path), "[No EOL]")
+
+	typeText(model, "x")
+	assert.Equal(t, "x# Notes\nbody\n", save(t, model, path))
+}
+
+func TestFinalNewline_InsertedOnSave(t *testing.T) {
+	model, path := finalNewlineModel(t, "insert_final_newline = true\n", "", "text")
+	assert.Contains(t, fileStatus(model, path), "[No EOL]")
+
+	assert.Equal(t, "text\n", save(t, model, path))
+	assert.NotContains(t, fileStatus(model, path), "[No EOL]")
+}
+
+func TestFinalNewline_EditorConfigRemovesIt(t *testing.T) {
+	model, path := finalNewlineModel(t, "", "[*]\ninsert_final_newline = false\n", "text\n\n")
+	assert.Equal(t, "text", save(t, model, path))
+	assert.Contains(t, fileStatus(model, path), "[No EOL]")
+}
+
+func TestFinalNewline_UserConfigWinsOverEditorConfig(t *testing.T) {
+	model, path := finalNewlineModel(t, "insert_final_newline = false\n", "[*]\ninsert_final_newline = true\n", "text")
+	assert.Equal(t, "text", save(t, model, path), "Set to false, the text is saved as it is")
+}
@@ -0,0 +1,51 @@
+package integration
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/ofri/mde/internal/plugins"
+	"github.com/ofri/mde/internal/tui"
+	"github.com/ofri/mde/pkg/ast"
+	"github.com/ofri/mde/pkg/plugin"
+	"github.com/ofri/mde/test/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFollowLink_OpensRelativeFile(t *testing.T) {
+	plugin.ResetRegistry()
+	require.NoError(t, plugins.InitializePlugins())
+
+	dir := t.TempDir()
+	index := filepath.Join(dir, "index.md")
+	require.NoError(t, os.WriteFile(index, []byte("See [the guide](my%20guide.md)\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "my guide.md"), []byte("# Guide\n"), 0644))
+
+	model := tui.New()
+	testutils.SetModelSize(model, 80, 20)
+	model.SetFilename(index)
+	model.GetEditor().GetCursor().SetBufferPos(ast.BufferPos{Line: 0, Col: 6})
+
+	model.Update(tea.KeyPressMsg(tea.Key{Code: tea.KeyEnter, Mod: tea.ModAlt}))
+
+	doc := model.GetEditor().GetDocument()
+	assert.Equal(t, filepath.Join(dir, "my guide.md"), doc.GetFilename())
+	assert.Equal(t, "# Guide", doc.GetLine(0))
+}
+
+func TestFollowLink_JumpsToAnchor(t *testing.T) {
+	plugin.ResetRegistry()
+	require.NoError(t, plugins.InitializePlugins())
+
+	model := tui.New()
+	testutils.LoadContentIntoModel(model, "[jump][setup]\n\n# Intro\n\n## Getting Set-up!\n\n[setup]: #getting-set-up")
+	testutils.SetModelSize(model, 80, 20)
+	model.GetEditor().GetCursor().SetBufferPos(ast.BufferPos{Line: 0, Col: 1})
+
+	model.Update(tea.KeyPressMsg(tea.Key{Code: tea.KeyEnter, Mod: tea.ModAlt}))
+
+	assert.Equal(t, 4, model.GetEditor().GetCursor().GetBufferPos().Line)
+}
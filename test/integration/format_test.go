@@ -0,0 +1,129 @@
+package integration
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/ofri/mde/internal/config"
+	"github.com/ofri/mde/internal/plugins"
+	"github.com/ofri/mde/internal/tui"
+	"github.com/ofri/mde/pkg/ast"
+	"github.com/ofri/mde/pkg/plugin"
+	"github.com/ofri/mde/test/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormatKeys(t *testing.T) {
+	plugin.ResetRegistry()
+	require.NoError(t, plugins.InitializePlugins())
+
+	model := tui.New()
+	model.NewBuffer("say hello", "format.md")
+	testutils.SetModelSize(model, 80, 10)
+	model.GetEditor().GetCursor().SetBufferPos(ast.BufferPos{Line: 0, Col: 5})
+
+	model.Update(tea.KeyPressMsg(tea.Key{Code: 'b', Mod: tea.ModCtrl}))
+	model.Update(tea.KeyPressMsg(tea.Key{Code: 'i', Mod: tea.ModCtrl}))
+	assert.Equal(t, "say ***hello***", model.GetEditor().GetDocument().GetText())
+
+	model.Update(tea.KeyPressMsg(tea.Key{Code: 'b', Mod: tea.ModCtrl}))
+	assert.Equal(t, "say *hello*", model.GetEditor().GetDocument().GetText())
+
+	model.Update(tea.KeyPressMsg(tea.Key{Code: 'i', Mod: tea.ModCtrl}))
+	model.Update(tea.KeyPressMsg(tea.Key{Code: '`', Mod: tea.ModCtrl}))
+	assert.Equal(t, "say `hello`", model.GetEditor().GetDocument().GetText())
+
+	// Without key disambiguation Ctrl+I arrives as Tab
+	model.Update(tea.KeyPressMsg(tea.Key{Code: '`', Mod: tea.ModCtrl}))
+	model.Update(tea.KeyPressMsg(tea.Key{Code: 'i', Mod: tea.ModAlt}))
+	assert.Equal(t, "say *hello*", model.GetEditor().GetDocument().GetText())
+}
+
+func TestLinkToggle(t *testing.T) {
+	plugin.ResetRegistry()
+	require.NoError(t, plugins.InitializePlugins())
+
+	model := tui.New()
+	model.NewBuffer("https://example.com see docs", "link.md")
+	testutils.SetModelSize(model, 80, 10)
+
+	// Cut the URL, then link a word to it
+	model.GetEditor().GetCursor().SetSelection(&ast.Selection{Start: ast.BufferPos{Line: 0, Col: 0}, End: ast.BufferPos{Line: 0, Col: 20}})
+	model.GetEditor().Cut()
+	model.GetEditor().GetCursor().SetBufferPos(ast.BufferPos{Line: 0, Col: 5})
+	model.Update(tea.KeyPressMsg(tea.Key{Code: 'k', Mod: tea.ModCtrl}))
+	assert.Equal(t, "see [docs](https://example.com)", model.GetEditor().GetDocument().GetText())
+
+	// Again on the link removes it
+	model.GetEditor().GetCursor().SetBufferPos(ast.BufferPos{Line: 0, Col: 6})
+	model.Update(tea.KeyPressMsg(tea.Key{Code: 'k', Mod: tea.ModCtrl}))
+	assert.Equal(t, "see docs", model.GetEditor().GetDocument().GetText())
+}
+
+func TestFormatKeys_PlainText(t *testing.T) {
+	plugin.ResetRegistry()
+	require.NoError(t, plugins.InitializePlugins())
+
+	model := tui.New()
+	model.NewBuffer("say hello", "notes.txt")
+	testutils.SetModelSize(model, 80, 10)
+
+	model.Update(tea.KeyPressMsg(tea.Key{Code: 'b', Mod: tea.ModCtrl}))
+	assert.Equal(t, "say hello", model.GetEditor().GetDocument().GetText())
+}
+
+func TestSmartLinkPaste(t *testing.T) {
+	plugin.ResetRegistry()
+	require.NoError(t, plugins.InitializePlugins())
+
+	model := tui.New()
+	model.NewBuffer("see docs", "link.md")
+	testutils.SetModelSize(model, 80, 10)
+	selectDocs := func() {
+		model.GetEditor().GetCursor().SetSelection(&ast.Selection{Start: ast.BufferPos{Line: 0, Col: 4}, End: ast.BufferPos{Line: 0, Col: 8}})
+	}
+
+	selectDocs()
+	model.Update(tea.PasteMsg("https://example.com"))
+	assert.Equal(t, "see [docs](https://example.com)", model.GetEditor().GetDocument().GetText())
+
+	cfg := config.Default()
+	cfg.SmartLinkPaste = false
+	model.SetConfig(cfg)
+	model.NewBuffer("see docs", "link.md")
+	selectDocs()
+	model.Update(tea.PasteMsg("https://example.com"))
+	assert.Equal(t, "see https://example.com", model.GetEditor().GetDocument().GetText())
+}
+
+func TestInsertLinkTitle(t *testing.T) {
+	plugin.ResetRegistry()
+	require.NoError(t, plugins.InitializePlugins())
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("<title>Docs [v2]</title>"))
+	}))
+	defer server.Close()
+
+	model := tui.New()
+	model.NewBuffer("see "+server.URL+" now", "link.md")
+	testutils.SetModelSize(model, 80, 10)
+	model.GetEditor().GetCursor().SetBufferPos(ast.BufferPos{Line: 0, Col: 6})
+
+	cmd, ok := model.RunCommand("insert-link-title")
+	require.True(t, ok)
+	require.NotNil(t, cmd)
+	model.Update(cmd())
+	assert.Equal(t, "see [Docs \\[v2\\]]("+server.URL+") now", model.GetEditor().GetDocument().GetText())
+
+	// Offline, the URL itself becomes the text
+	server.Close()
+	model.NewBuffer(server.URL, "link.md")
+	cmd, _ = model.RunCommand("insert-link-title")
+	require.NotNil(t, cmd)
+	model.Update(cmd())
+	assert.Equal(t, "["+server.URL+"]("+server.URL+")", model.GetEditor().GetDocument().GetText())
+}
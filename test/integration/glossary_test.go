@@ -0,0 +1,73 @@
+package integration
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/ofri/mde/internal/config"
+	"github.com/ofri/mde/internal/plugins"
+	"github.com/ofri/mde/internal/tui"
+	"github.com/ofri/mde/pkg/ast"
+	"github.com/ofri/mde/pkg/plugin"
+	"github.com/ofri/mde/test/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// glossaryModel opens content in a directory holding glossary.md, the
+// relative glossary setting.
+func glossaryModel(t *testing.T, content string) *tui.Model {
+	plugin.ResetRegistry()
+	require.NoError(t, plugins.InitializePlugins())
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "glossary.md"),
+		[]byte("*[API]: Application Programming Interface\n\nEvent loop\n: Runs queued callbacks\n"), 0o644))
+
+	cfg := config.Default()
+	cfg.Glossary = "glossary.md"
+	model := tui.New()
+	model.SetConfig(cfg)
+	model.NewBuffer(content, filepath.Join(dir, "notes.md"))
+	testutils.SetModelSize(model, 100, 20)
+	if model.GetEditor().ShowLineNumbers() {
+		model.GetEditor().ToggleLineNumbers()
+	}
+	return model
+}
+
+func TestGlossary_HoverAndStatusLine(t *testing.T) {
+	model := glossaryModel(t, "The API drives the event loop.\n*[CLI]: Command Line Interface\nA CLI.")
+
+	model.Update(tea.MouseMotionMsg(tea.Mouse{X: 5, Y: 0}))
+	assert.Contains(t, statusLine(model), "API: Application Programming Interface")
+	model.Update(tea.MouseMotionMsg(tea.Mouse{X: 22, Y: 0}))
+	assert.Contains(t, statusLine(model), "Event loop: Runs queued callbacks")
+
+	// The cursor on a term, even one the document defines, defines it too
+	model.Update(tea.MouseMotionMsg(tea.Mouse{X: 0, Y: 10}))
+	model.GetEditor().GetCursor().SetBufferPos(ast.BufferPos{Line: 2, Col: 3})
+	assert.Contains(t, model.View(), "CLI: Command Line Interface")
+}
+
+func TestGlossary_UndefinedAcronymsAreWarnings(t *testing.T) {
+	model := glossaryModel(t, "The API speaks HTTP.")
+
+	model.Update(tea.KeyPressMsg(tea.Key{Code: tea.KeyF8}))
+	assert.Equal(t, ast.BufferPos{Line: 0, Col: 15}, model.GetEditor().GetCursor().GetBufferPos())
+	assert.Contains(t, model.View(), "warning: Undefined acronym HTTP")
+}
+
+func TestGlossary_Highlight(t *testing.T) {
+	model := glossaryModel(t, "The API")
+
+	_, ok := model.RunCommand("highlight-glossary")
+	require.True(t, ok)
+	assert.Contains(t, model.View(), "Highlighting 2 glossary terms")
+
+	_, ok = model.RunCommand("highlight-glossary")
+	require.True(t, ok)
+	assert.Contains(t, model.View(), "Glossary highlighting off")
+}
@@ -0,0 +1,63 @@
+package integration
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/ofri/mde/internal/config"
+	"github.com/ofri/mde/internal/plugins"
+	"github.com/ofri/mde/internal/tui"
+	"github.com/ofri/mde/pkg/ast"
+	"github.com/ofri/mde/pkg/plugin"
+	"github.com/ofri/mde/test/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNumberHeadings_Automatic(t *testing.T) {
+	plugin.ResetRegistry()
+	require.NoError(t, plugins.InitializePlugins())
+
+	cfg := config.Default()
+	cfg.NumberHeadings = true
+	model := tui.New()
+	model.SetConfig(cfg)
+	model.NewBuffer("# Guide\n\n## 1 Setup\n\n## 2 Usage", "guide.md")
+	testutils.SetModelSize(model, 80, 10)
+
+	// A heading being typed is left alone until the cursor leaves it
+	model.GetEditor().GetCursor().SetBufferPos(ast.BufferPos{Line: 1})
+	model.Update(tea.KeyPressMsg(tea.Key{Code: tea.KeyEnter}))
+	typeText(model, "## Intro")
+	assert.Equal(t, "# Guide\n\n## Intro\n## 1 Setup\n\n## 2 Usage", model.GetEditor().GetDocument().GetText())
+
+	model.Update(tea.KeyPressMsg(tea.Key{Code: tea.KeyDown}))
+	assert.Equal(t, "# Guide\n\n## 1 Intro\n## 2 Setup\n\n## 3 Usage", model.GetEditor().GetDocument().GetText())
+
+	// Removing a section renumbers those after it
+	model.GetEditor().SelectLine()
+	model.Update(tea.KeyPressMsg(tea.Key{Code: tea.KeyBackspace}))
+	assert.Equal(t, "# Guide\n\n## 1 Intro\n\n## 2 Usage", model.GetEditor().GetDocument().GetText())
+
+	_, ok := model.RunCommand("unnumber-headings")
+	require.True(t, ok)
+	assert.Equal(t, "# Guide\n\n## 1 Intro\n\n## 2 Usage", model.GetEditor().GetDocument().GetText(),
+		"Numbers stay while number_headings is on")
+}
+
+func TestNumberHeadings_Commands(t *testing.T) {
+	plugin.ResetRegistry()
+	require.NoError(t, plugins.InitializePlugins())
+
+	model := tui.New()
+	model.NewBuffer("## Setup\n### Install\n## Usage", "guide.md")
+	testutils.SetModelSize(model, 80, 10)
+
+	_, ok := model.RunCommand("number-headings")
+	require.True(t, ok)
+	assert.Equal(t, "## 1 Setup\n### 1.1 Install\n## 2 Usage", model.GetEditor().GetDocument().GetText())
+
+	_, ok = model.RunCommand("unnumber-headings")
+	require.True(t, ok)
+	assert.Equal(t, "## Setup\n### Install\n## Usage", model.GetEditor().GetDocument().GetText())
+}
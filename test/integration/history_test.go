@@ -0,0 +1,69 @@
+package integration
+
+import (
+	"path/filepath"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/ofri/mde/internal/config"
+	"github.com/ofri/mde/internal/history"
+	"github.com/ofri/mde/internal/plugins"
+	"github.com/ofri/mde/internal/tui"
+	"github.com/ofri/mde/pkg/plugin"
+	"github.com/ofri/mde/test/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHistory_SnapshotDiffAndRestore(t *testing.T) {
+	plugin.ResetRegistry()
+	require.NoError(t, plugins.InitializePlugins())
+
+	path := filepath.Join(t.TempDir(), "notes.md")
+	cfg := config.Default()
+	cfg.HistoryDir = t.TempDir()
+	model := tui.New()
+	model.SetConfig(cfg)
+	model.NewBuffer("First draft", path)
+	testutils.SetModelSize(model, 100, 20)
+
+	save := func() {
+		_, cmd := model.Update(tea.KeyPressMsg(tea.Key{Code: 's', Mod: tea.ModCtrl}))
+		runCmd(model, cmd)
+	}
+	save()
+	typeText(model, "Second ")
+	save()
+	snapshots, err := history.List(cfg.HistoryDir, path)
+	require.NoError(t, err)
+	require.Len(t, snapshots, 2, "Each save takes a snapshot")
+
+	typeText(model, "unsaved ")
+	_, ok := model.RunCommand("history")
+	require.True(t, ok)
+	view := model.View()
+	assert.Contains(t, view, "History of notes.md")
+	assert.Contains(t, view, "Snapshot 1 of 2")
+
+	// The oldest snapshot, beside the text
+	model.Update(tea.KeyPressMsg(tea.Key{Code: tea.KeyDown}))
+	model.Update(tea.KeyPressMsg(tea.Key{Code: 'd', Text: "d"}))
+	view = model.View()
+	assert.Contains(t, view, "Now  notes.md")
+	assert.Contains(t, view, "1 changes")
+	model.Update(tea.KeyPressMsg(tea.Key{Code: 'q', Text: "q"}))
+	assert.Contains(t, model.View(), "Snapshot 2 of 2", "Quitting the diff goes back to the picker")
+
+	model.Update(tea.KeyPressMsg(tea.Key{Code: tea.KeyEnter}))
+	doc := model.GetEditor().GetDocument()
+	assert.Equal(t, "First draft", doc.GetText())
+	assert.True(t, doc.IsModified(), "Restoring changes the text, not the file")
+
+	// The text replaced is kept, to go back to
+	snapshots, err = history.List(cfg.HistoryDir, path)
+	require.NoError(t, err)
+	require.Len(t, snapshots, 3)
+	content, err := snapshots[0].Read()
+	require.NoError(t, err)
+	assert.Equal(t, "Second unsaved First draft", string(content))
+}
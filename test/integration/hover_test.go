@@ -0,0 +1,65 @@
+package integration
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/ofri/mde/internal/plugins"
+	"github.com/ofri/mde/internal/tui"
+	"github.com/ofri/mde/pkg/plugin"
+	"github.com/ofri/mde/test/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func hoverModel(t *testing.T, content string) *tui.Model {
+	plugin.ResetRegistry()
+	require.NoError(t, plugins.InitializePlugins())
+
+	model := tui.New()
+	testutils.SetModelSize(model, 80, 20)
+	model.NewBuffer(content, "")
+	if model.GetEditor().ShowLineNumbers() {
+		model.GetEditor().ToggleLineNumbers()
+	}
+	return model
+}
+
+// statusLine returns the status bar row of the rendered view.
+func statusLine(model *tui.Model) string {
+	for _, line := range strings.Split(model.View(), "\n") {
+		if strings.Contains(line, "Ln 1, Col 1") {
+			return line
+		}
+	}
+	return ""
+}
+
+func TestHover_ShowsLinkURL(t *testing.T) {
+	model := hoverModel(t, "See [the docs](https://example.com/docs) here\n")
+
+	model.Update(tea.MouseMotionMsg(tea.Mouse{X: 7, Y: 0}))
+	assert.Contains(t, statusLine(model), "https://example.com/docs")
+
+	model.Update(tea.MouseMotionMsg(tea.Mouse{X: 1, Y: 0}))
+	assert.NotContains(t, statusLine(model), "https://example.com/docs", "Leaving the link clears the tooltip")
+}
+
+func TestHover_ResolvesReferenceLinks(t *testing.T) {
+	model := hoverModel(t, "Read [it][ref]\n\n[ref]: https://example.com/ref\n")
+
+	model.Update(tea.MouseMotionMsg(tea.Mouse{X: 6, Y: 0}))
+	assert.Contains(t, statusLine(model), "https://example.com/ref")
+}
+
+func TestHover_ShowsFootnoteText(t *testing.T) {
+	model := hoverModel(t, "A claim[^1] stands.\n\n[^1]: Source of the claim\n")
+
+	model.Update(tea.MouseMotionMsg(tea.Mouse{X: 8, Y: 0}))
+	assert.Contains(t, statusLine(model), "[^1]: Source of the claim")
+
+	// Plain text has no tooltip
+	model.Update(tea.MouseMotionMsg(tea.Mouse{X: 2, Y: 0}))
+	assert.NotContains(t, statusLine(model), "Source of the claim")
+}
@@ -0,0 +1,80 @@
+package integration
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/ofri/mde/internal/config"
+	"github.com/ofri/mde/internal/plugins"
+	"github.com/ofri/mde/internal/tui"
+	"github.com/ofri/mde/pkg/ast"
+	"github.com/ofri/mde/pkg/plugin"
+	"github.com/ofri/mde/test/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInvisibles_Toggle(t *testing.T) {
+	plugin.ResetRegistry()
+	require.NoError(t, plugins.InitializePlugins())
+
+	model := tui.New()
+	model.NewBuffer("\tx = 1  \nplain", "ws.md")
+	testutils.SetModelSize(model, 80, 10)
+	model.GetEditor().GetCursor().SetBufferPos(ast.BufferPos{Line: 1})
+
+	first := strings.Split(testutils.StripAnsiEscapes(model.View()), "\n")[0]
+	assert.Equal(t, " 1│     x = 1", strings.TrimRight(first, " "))
+
+	_, ok := model.RunCommand("show-invisibles")
+	require.True(t, ok)
+	first = strings.Split(testutils.StripAnsiEscapes(model.View()), "\n")[0]
+	assert.True(t, strings.HasPrefix(first, " 1│ →   x = 1··"), "Tab and trailing spaces are marked: %q", first)
+
+	model.RunCommand("show-invisibles")
+	assert.NotContains(t, model.View(), "→")
+}
+
+func TestInvisibles_StripTrailingWhitespaceOnSave(t *testing.T) {
+	plugin.ResetRegistry()
+	require.NoError(t, plugins.InitializePlugins())
+
+	path := filepath.Join(t.TempDir(), "ws.md")
+	cfg := config.Default()
+	cfg.StripTrailingWhitespace = true
+	model := tui.New()
+	model.SetConfig(cfg)
+	model.NewBuffer("title \t\nhard break   \nnext", path)
+	testutils.SetModelSize(model, 80, 10)
+	model.GetEditor().GetCursor().SetBufferPos(ast.BufferPos{Line: 0, Col: 7})
+
+	_, cmd := model.Update(tea.KeyPressMsg(tea.Key{Code: 's', Mod: tea.ModCtrl}))
+	require.NotNil(t, cmd)
+	cmd()
+
+	saved, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "title\nhard break  \nnext", string(saved), "Hard breaks keep two spaces")
+	assert.Equal(t, ast.BufferPos{Line: 0, Col: 5}, model.GetEditor().GetCursor().GetBufferPos())
+}
+
+func TestIndentGuides(t *testing.T) {
+	plugin.ResetRegistry()
+	require.NoError(t, plugins.InitializePlugins())
+
+	model := tui.New()
+	model.NewBuffer("- a\n    - b\n\n        - c\n\tcode", "list.md")
+	testutils.SetModelSize(model, 80, 10)
+	model.GetEditor().GetCursor().SetBufferPos(ast.BufferPos{Line: 0, Col: 3})
+
+	_, ok := model.RunCommand("indent-guides")
+	require.True(t, ok)
+	lines := strings.Split(testutils.StripAnsiEscapes(model.View()), "\n")
+	assert.Equal(t, " 2│ ▏   - b", strings.TrimRight(lines[1], " "))
+	assert.Equal(t, " 3│ ▏", strings.TrimRight(lines[2], " "), "Blank lines continue the guides")
+	assert.Equal(t, " 4│ ▏   ▏   - c", strings.TrimRight(lines[3], " "))
+	assert.Equal(t, " 5│ ▏   code", strings.TrimRight(lines[4], " "), "Tabs are indentation too")
+}
@@ -0,0 +1,56 @@
+package integration
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/ofri/mde/internal/config"
+	"github.com/ofri/mde/internal/plugins"
+	"github.com/ofri/mde/internal/tui"
+	"github.com/ofri/mde/pkg/ast"
+	"github.com/ofri/mde/pkg/plugin"
+	"github.com/ofri/mde/test/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAutoRenumberLists(t *testing.T) {
+	plugin.ResetRegistry()
+	require.NoError(t, plugins.InitializePlugins())
+
+	cfg := config.Default()
+	cfg.AutoRenumberLists = true
+	model := tui.New()
+	model.SetConfig(cfg)
+	model.NewBuffer("1. a\n2. b\n3. c", "list.md")
+	testutils.SetModelSize(model, 80, 10)
+
+	// Deleting the second item's line renumbers the rest
+	model.GetEditor().GetCursor().SetBufferPos(ast.BufferPos{Line: 1})
+	model.GetEditor().SelectLine()
+	model.Update(tea.KeyPressMsg(tea.Key{Code: tea.KeyBackspace}))
+	assert.Equal(t, "1. a\n2. c", model.GetEditor().GetDocument().GetText())
+
+	// Typing a new item's marker in the middle numbers it in sequence
+	model.GetEditor().GetCursor().SetBufferPos(ast.BufferPos{Line: 0, Col: 4})
+	model.Update(tea.KeyPressMsg(tea.Key{Code: tea.KeyEnter}))
+	typeText(model, "1. b")
+	assert.Equal(t, "1. a\n2. b\n3. c", model.GetEditor().GetDocument().GetText())
+}
+
+func TestAutoRenumberLists_Off(t *testing.T) {
+	plugin.ResetRegistry()
+	require.NoError(t, plugins.InitializePlugins())
+
+	model := tui.New()
+	model.NewBuffer("1. a\n2. b\n3. c", "list.md")
+	testutils.SetModelSize(model, 80, 10)
+	model.GetEditor().GetCursor().SetBufferPos(ast.BufferPos{Line: 1, Col: 4})
+	model.GetEditor().SelectLine()
+	model.Update(tea.KeyPressMsg(tea.Key{Code: tea.KeyDelete}))
+	assert.Equal(t, "1. a\n3. c", model.GetEditor().GetDocument().GetText())
+
+	_, ok := model.RunCommand("renumber-list")
+	require.True(t, ok)
+	assert.Equal(t, "1. a\n2. c", model.GetEditor().GetDocument().GetText())
+}
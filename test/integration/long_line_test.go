@@ -0,0 +1,59 @@
+package integration
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/ofri/mde/internal/plugins"
+	"github.com/ofri/mde/internal/plugins/storage"
+	"github.com/ofri/mde/internal/tui"
+	"github.com/ofri/mde/pkg/plugin"
+	"github.com/ofri/mde/test/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLongLine_RendersVisibleWindow(t *testing.T) {
+	plugin.ResetRegistry()
+	require.NoError(t, plugins.InitializePlugins())
+
+	// A minified file: one line of several megabytes
+	line := strings.Repeat("{\"key\":\"value\"},", 200000) + "END"
+	model := tui.New()
+	model.NewBuffer("# Data\n"+line, "data.md")
+	testutils.SetModelSize(model, 60, 10)
+
+	rows := strings.Split(ansi.Strip(model.View()), "\n")
+	for _, row := range rows {
+		assert.LessOrEqual(t, ansi.StringWidth(row), 60)
+	}
+	assert.Contains(t, rows[1], `{"key":"value"},{"key"`)
+	assert.NotContains(t, rows[2], "key", "The long line does not wrap into extra rows")
+	assert.Empty(t, model.GetEditor().GetDocument().GetLineTokens(1), "Long lines are not highlighted")
+
+	model.Update(tea.KeyPressMsg(tea.Key{Code: tea.KeyDown}))
+	model.Update(tea.KeyPressMsg(tea.Key{Code: tea.KeyEnd}))
+	typeText(model, "!")
+	view := ansi.Strip(model.View())
+	assert.Contains(t, view, `"value"},END!█`)
+	assert.Contains(t, view, "Ln 2, Col 3200005")
+}
+
+func TestLongLine_LoadsFromStorage(t *testing.T) {
+	plugin.ResetRegistry()
+	require.NoError(t, plugins.InitializePlugins())
+	memory := storage.NewMemory()
+	require.NoError(t, plugin.RegisterStorage(memory.Name(), memory))
+
+	line := strings.Repeat("x", 100_000)
+	require.NoError(t, memory.WriteFile(context.Background(), "mem://host/long.md", []byte("# Long\r\n"+line+"\n")))
+
+	model := tui.New()
+	testutils.SetModelSize(model, 60, 10)
+	model.SetFilename("mem://host/long.md")
+	runCmd(model, model.Init())
+	assert.Equal(t, "# Long\n"+line+"\n", model.GetEditor().GetDocument().GetText(), "Lines longer than a scanner's buffer load whole")
+}
@@ -0,0 +1,76 @@
+package integration
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/ofri/mde/internal/config"
+	"github.com/ofri/mde/internal/plugins"
+	"github.com/ofri/mde/internal/tui"
+	"github.com/ofri/mde/pkg/plugin"
+	"github.com/ofri/mde/test/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func wheelModel(t *testing.T, cfg *config.Config) *tui.Model {
+	plugin.ResetRegistry()
+	require.NoError(t, plugins.InitializePlugins())
+
+	model := tui.New()
+	model.SetConfig(cfg)
+	testutils.LoadContentIntoModel(model, strings.Repeat(strings.Repeat("x", 200)+"\n", 100))
+	testutils.SetModelSize(model, 80, 12)
+	return model
+}
+
+func TestMouseWheel_ConfiguredSpeed(t *testing.T) {
+	cfg := config.Default()
+	cfg.ScrollLines = 5
+	cfg.ScrollColumns = 4
+	model := wheelModel(t, cfg)
+	viewport := func() (int, int) {
+		v := model.GetEditor().GetViewport()
+		return v.GetTopLine(), v.GetLeftColumn()
+	}
+
+	model.Update(tea.MouseWheelMsg(tea.Mouse{Button: tea.MouseWheelDown}))
+	top, _ := viewport()
+	assert.Equal(t, 5, top)
+
+	model.Update(tea.MouseWheelMsg(tea.Mouse{Button: tea.MouseWheelRight}))
+	_, left := viewport()
+	assert.Equal(t, 4, left)
+}
+
+func TestMouseWheel_NaturalScrollInverts(t *testing.T) {
+	cfg := config.Default()
+	cfg.NaturalScroll = true
+	model := wheelModel(t, cfg)
+	model.GetEditor().ScrollViewportDown(10)
+
+	model.Update(tea.MouseWheelMsg(tea.Mouse{Button: tea.MouseWheelDown}))
+	assert.Equal(t, 7, model.GetEditor().GetViewport().GetTopLine())
+}
+
+func TestMouseWheel_ShiftScrollsHorizontally(t *testing.T) {
+	model := wheelModel(t, config.Default())
+
+	model.Update(tea.MouseWheelMsg(tea.Mouse{Button: tea.MouseWheelDown, Mod: tea.ModShift}))
+	assert.Equal(t, 0, model.GetEditor().GetViewport().GetTopLine())
+	assert.Equal(t, 2, model.GetEditor().GetViewport().GetLeftColumn())
+
+	cfg := config.Default()
+	cfg.ShiftWheelHorizontal = false
+	model = wheelModel(t, cfg)
+	model.Update(tea.MouseWheelMsg(tea.Mouse{Button: tea.MouseWheelDown, Mod: tea.ModShift}))
+	assert.Equal(t, 3, model.GetEditor().GetViewport().GetTopLine())
+}
+
+func TestMouseWheel_AltScrollsByPage(t *testing.T) {
+	model := wheelModel(t, config.Default())
+
+	model.Update(tea.MouseWheelMsg(tea.Mouse{Button: tea.MouseWheelDown, Mod: tea.ModAlt}))
+	assert.Equal(t, model.GetContentHeight()-1, model.GetEditor().GetViewport().GetTopLine())
+}
@@ -0,0 +1,35 @@
+package integration
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/ofri/mde/internal/plugins"
+	"github.com/ofri/mde/internal/tui"
+	"github.com/ofri/mde/pkg/plugin"
+	"github.com/ofri/mde/test/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOverwrite_InsertKeyToggles(t *testing.T) {
+	plugin.ResetRegistry()
+	require.NoError(t, plugins.InitializePlugins())
+
+	model := tui.New()
+	testutils.LoadContentIntoModel(model, "hello")
+	testutils.SetModelSize(model, 80, 10)
+
+	model.Update(tea.KeyPressMsg(tea.Key{Code: tea.KeyInsert}))
+	view := model.View()
+	assert.Contains(t, view, "OVR")
+	assert.Contains(t, view, "▁", "The cursor is an underline while overwriting")
+	assert.NotContains(t, view, "█")
+
+	model.Update(tea.KeyPressMsg(tea.Key{Code: 'j', Text: "j"}))
+	assert.Equal(t, "jello", model.GetEditor().GetDocument().GetText())
+
+	model.Update(tea.KeyPressMsg(tea.Key{Code: tea.KeyInsert}))
+	assert.NotContains(t, model.View(), "OVR")
+	assert.Contains(t, model.View(), "█")
+}
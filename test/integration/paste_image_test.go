@@ -0,0 +1,86 @@
+package integration
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/ofri/mde/internal/config"
+	"github.com/ofri/mde/internal/tui"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeXclip puts an xclip on PATH whose clipboard holds a PNG when image
+// is true, and text otherwise.
+func fakeXclip(t *testing.T, image bool) {
+	if runtime.GOOS != "linux" {
+		t.Skip("fake xclip needs a shell and X11 tooling lookup")
+	}
+	bin := t.TempDir()
+	targets := "TARGETS\nUTF8_STRING"
+	if image {
+		targets = "TARGETS\nimage/png"
+	}
+	script := "#!/bin/sh\ncase \"$*\" in\n" +
+		"*TARGETS*) printf '" + targets + "\\n' ;;\n" +
+		"*image/png*) printf 'PNGDATA' ;;\n" +
+		"*) exit 1 ;;\nesac\n"
+	require.NoError(t, os.WriteFile(filepath.Join(bin, "xclip"), []byte(script), 0o755))
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+	t.Setenv("DISPLAY", ":0")
+	t.Setenv("WAYLAND_DISPLAY", "")
+}
+
+func pasteWithCtrlV(model *tui.Model) {
+	_, cmd := model.Update(tea.KeyPressMsg(tea.Key{Code: 'v', Mod: tea.ModCtrl}))
+	for cmd != nil {
+		_, cmd = model.Update(cmd())
+	}
+}
+
+func TestPasteImage_SavedToAssets(t *testing.T) {
+	fakeXclip(t, true)
+	dir := t.TempDir()
+	path := filepath.Join(dir, "notes.md")
+	require.NoError(t, os.WriteFile(path, []byte("Look: "), 0o644))
+
+	model := tui.New()
+	model.SetConfig(config.Default())
+	model.SetFilename(path)
+	model.Update(tea.KeyPressMsg(tea.Key{Code: tea.KeyEnd}))
+
+	pasteWithCtrlV(model)
+	pasteWithCtrlV(model)
+
+	text := model.GetEditor().GetDocument().GetText()
+	links := regexp.MustCompile(`!\[\]\((assets/img-\d{8}-\d{6}(?:-2)?\.png)\)`).FindAllStringSubmatch(text, -1)
+	require.Len(t, links, 2, text)
+	assert.NotEqual(t, links[0][1], links[1][1], "Each paste gets its own file")
+	for _, link := range links {
+		data, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(link[1])))
+		require.NoError(t, err)
+		assert.Equal(t, "PNGDATA", string(data))
+	}
+}
+
+func TestPasteImage_TextClipboardPastesText(t *testing.T) {
+	fakeXclip(t, false)
+	path := filepath.Join(t.TempDir(), "notes.md")
+	require.NoError(t, os.WriteFile(path, []byte("word"), 0o644))
+
+	model := tui.New()
+	model.SetConfig(config.Default())
+	model.SetFilename(path)
+	editor := model.GetEditor()
+	editor.SelectToLineEnd()
+	editor.Cut()
+
+	pasteWithCtrlV(model)
+	pasteWithCtrlV(model)
+	assert.Equal(t, "wordword", editor.GetDocument().GetText())
+	assert.NoDirExists(t, filepath.Join(filepath.Dir(path), "assets"))
+}
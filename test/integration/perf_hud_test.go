@@ -0,0 +1,37 @@
+package integration
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/x/ansi"
+	"github.com/ofri/mde/internal/plugins"
+	"github.com/ofri/mde/internal/tui"
+	"github.com/ofri/mde/pkg/plugin"
+	"github.com/ofri/mde/test/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPerfHUD_Toggle(t *testing.T) {
+	plugin.ResetRegistry()
+	require.NoError(t, plugins.InitializePlugins())
+
+	model := tui.New()
+	testutils.LoadContentIntoModel(model, strings.Repeat("some text\n", 99)+"end")
+	testutils.SetModelSize(model, 100, 10)
+	assert.NotContains(t, model.View(), "allocs")
+
+	_, ok := model.RunCommand("perf-hud")
+	require.True(t, ok)
+	model.View() // The first measured frame
+	firstLine := strings.Split(ansi.Strip(model.View()), "\n")[0]
+
+	hud := regexp.MustCompile(`render \d+\.\d\dms  parse \d+\.\d\dms  100 lines 993B  \d+ allocs \d+(\.\d)?[KM]?B`)
+	assert.Regexp(t, hud, firstLine, "The HUD sits on the first line")
+	assert.True(t, strings.Contains(firstLine, "ome text"), "Text left of the HUD stays visible: %q", firstLine)
+
+	model.RunCommand("perf-hud")
+	assert.NotContains(t, model.View(), "allocs")
+}
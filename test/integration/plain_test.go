@@ -0,0 +1,57 @@
+package integration
+
+import (
+	"context"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/ofri/mde/internal/plugins"
+	"github.com/ofri/mde/internal/tui"
+	"github.com/ofri/mde/pkg/ast"
+	"github.com/ofri/mde/pkg/plugin"
+	"github.com/ofri/mde/test/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPlain_ParserHasNoTokens(t *testing.T) {
+	plugin.ResetRegistry()
+	require.NoError(t, plugins.InitializePlugins())
+
+	parser, err := plugin.GetRegistry().GetParserForFile("server.log")
+	require.NoError(t, err)
+	assert.Equal(t, "plain", parser.Name())
+
+	tokens, err := parser.GetSyntaxHighlighting(context.Background(), "# not a *heading* [link](x)")
+	require.NoError(t, err)
+	assert.Empty(t, tokens)
+
+	doc, err := parser.Parse(context.Background(), "a\nb")
+	require.NoError(t, err)
+	assert.Equal(t, 2, doc.LineCount())
+}
+
+func TestPlain_MarkdownFeaturesOff(t *testing.T) {
+	plugin.ResetRegistry()
+	require.NoError(t, plugins.InitializePlugins())
+
+	model := tui.New()
+	model.NewBuffer("# Title\n:smi", "notes.txt")
+	testutils.SetModelSize(model, 80, 10)
+
+	model.Update(tea.KeyPressMsg(tea.Key{Code: 'p', Mod: tea.ModCtrl}))
+	assert.False(t, model.IsPreviewMode())
+	assert.Contains(t, model.View(), "Preview is only available in markdown files")
+
+	_, ok := model.RunCommand("select-section")
+	assert.True(t, ok)
+	assert.False(t, model.GetEditor().GetCursor().HasSelection())
+
+	model.GetEditor().GetCursor().SetBufferPos(ast.BufferPos{Line: 1, Col: 4})
+	model.Update(tea.KeyPressMsg(tea.Key{Code: tea.KeyTab}))
+	assert.Equal(t, "# Title\n:smi\t", model.GetEditor().GetDocument().GetText(), "Tab does not complete emoji")
+
+	model.NewBuffer("# Title", "notes.md")
+	model.Update(tea.KeyPressMsg(tea.Key{Code: 'p', Mod: tea.ModCtrl}))
+	assert.True(t, model.IsPreviewMode())
+}
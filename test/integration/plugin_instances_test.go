@@ -0,0 +1,78 @@
+package integration
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/ofri/mde/internal/plugins"
+	"github.com/ofri/mde/pkg/ast"
+	"github.com/ofri/mde/pkg/plugin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPluginInstances_Isolated(t *testing.T) {
+	plugin.ResetRegistry()
+	require.NoError(t, plugins.InitializePlugins())
+	registry := plugin.GetRegistry()
+	require.NoError(t, registry.Configure(map[string]map[string]interface{}{"terminal": {"mathUnicode": false}}))
+
+	first, err := registry.NewRendererInstance("terminal")
+	require.NoError(t, err)
+	second, err := registry.NewRendererInstance("terminal")
+	require.NoError(t, err)
+	shared, err := registry.GetRenderer("terminal")
+	require.NoError(t, err)
+	assert.NotSame(t, first, second)
+	assert.NotSame(t, shared, first)
+
+	render := func(r plugin.RendererPlugin) string {
+		lines, err := r.RenderPreviewVisible(context.Background(), &plugin.RenderContext{
+			Document: ast.NewDocument(`$\alpha$`),
+			Viewport: ast.NewViewport(0, 0, 80, 5, 0, 4),
+		})
+		require.NoError(t, err)
+		return lines[0].Content
+	}
+	assert.Equal(t, `\alpha`, render(first), "Instances start from the registry's configuration")
+
+	require.NoError(t, first.Configure(map[string]interface{}{"mathUnicode": true}))
+	assert.Equal(t, "α", render(first))
+	assert.Equal(t, `\alpha`, render(second), "Configuring one instance leaves the others alone")
+	assert.Equal(t, `\alpha`, render(shared))
+
+	_, err = registry.NewParserInstance("missing")
+	assert.Error(t, err)
+}
+
+func TestPluginInstances_ConcurrentUse(t *testing.T) {
+	plugin.ResetRegistry()
+	require.NoError(t, plugins.InitializePlugins())
+	registry := plugin.GetRegistry()
+	renderer, err := registry.GetRenderer("terminal")
+	require.NoError(t, err)
+	parser, err := registry.GetParser("commonmark")
+	require.NoError(t, err)
+
+	doc := ast.NewDocument("# Title\n\nSome *text* with [[wiki]] and $x$")
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				on := (i+j)%2 == 0
+				_ = renderer.Configure(map[string]interface{}{"wikiLinks": on, "showLineNumbers": on})
+				_ = parser.Configure(map[string]interface{}{"wiki_links": on})
+				_, _ = renderer.RenderPreviewVisible(context.Background(), &plugin.RenderContext{
+					Document: doc,
+					Viewport: ast.NewViewport(0, 0, 80, 5, 0, 4),
+				})
+				_, _ = parser.GetSyntaxHighlighting(context.Background(), doc.GetLine(2))
+				_ = plugin.GetRegistry().ListParsers()
+			}
+		}(i)
+	}
+	wg.Wait()
+}
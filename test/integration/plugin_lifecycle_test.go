@@ -0,0 +1,89 @@
+package integration
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/ofri/mde/pkg/plugin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// lifecycleParser records its hooks in a shared log.
+type lifecycleParser struct {
+	MockParser
+	name    string
+	deps    []string
+	log     *[]string
+	initErr error
+	options map[string]interface{}
+}
+
+func (p *lifecycleParser) Name() string           { return p.name }
+func (p *lifecycleParser) Dependencies() []string { return p.deps }
+
+func (p *lifecycleParser) Init(ctx context.Context) error {
+	*p.log = append(*p.log, "init "+p.name)
+	return p.initErr
+}
+
+func (p *lifecycleParser) Shutdown(ctx context.Context) error {
+	*p.log = append(*p.log, "shutdown "+p.name)
+	return nil
+}
+
+func (p *lifecycleParser) Configure(options map[string]interface{}) error {
+	p.options = options
+	return nil
+}
+
+func TestPluginLifecycle_OrderedInitAndShutdown(t *testing.T) {
+	var log []string
+	registry := plugin.NewRegistry()
+	require.NoError(t, registry.RegisterParser("a", &lifecycleParser{name: "a", deps: []string{"c"}, log: &log}))
+	require.NoError(t, registry.RegisterParser("b", &lifecycleParser{name: "b", log: &log}))
+	require.NoError(t, registry.RegisterParser("c", &lifecycleParser{name: "c", deps: []string{"b"}, log: &log}))
+
+	order, err := registry.InitOrder()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"b", "c", "a"}, order)
+
+	require.NoError(t, registry.Init(context.Background()))
+	assert.Error(t, registry.Init(context.Background()), "Init runs once")
+	require.NoError(t, registry.Shutdown(context.Background()))
+	assert.Equal(t, []string{"init b", "init c", "init a", "shutdown a", "shutdown c", "shutdown b"}, log)
+}
+
+func TestPluginLifecycle_Errors(t *testing.T) {
+	var log []string
+	registry := plugin.NewRegistry()
+	require.NoError(t, registry.RegisterParser("a", &lifecycleParser{name: "a", log: &log}))
+	require.NoError(t, registry.RegisterParser("b", &lifecycleParser{name: "b", log: &log, initErr: errors.New("boom")}))
+
+	err := registry.Init(context.Background())
+	assert.ErrorContains(t, err, "plugin error [parser/b]: init failed: boom")
+	assert.Equal(t, []string{"init a", "init b", "shutdown a"}, log, "Started plugins are shut down again")
+
+	cyclic := plugin.NewRegistry()
+	require.NoError(t, cyclic.RegisterParser("x", &lifecycleParser{name: "x", deps: []string{"y"}, log: &log}))
+	require.NoError(t, cyclic.RegisterParser("y", &lifecycleParser{name: "y", deps: []string{"x"}, log: &log}))
+	assert.ErrorContains(t, cyclic.Init(context.Background()), "dependency cycle")
+
+	missing := plugin.NewRegistry()
+	require.NoError(t, missing.RegisterParser("x", &lifecycleParser{name: "x", deps: []string{"nope"}, log: &log}))
+	assert.ErrorContains(t, missing.Init(context.Background()), "unregistered plugin 'nope'")
+}
+
+func TestPluginLifecycle_Configure(t *testing.T) {
+	var log []string
+	parser := &lifecycleParser{name: "a", log: &log}
+	registry := plugin.NewRegistry()
+	require.NoError(t, registry.RegisterParser("a", parser))
+
+	require.NoError(t, registry.Configure(map[string]map[string]interface{}{"a": {"wiki_links": true}}))
+	assert.Equal(t, true, parser.options["wiki_links"])
+
+	err := registry.Configure(map[string]map[string]interface{}{"nope": {}})
+	assert.ErrorContains(t, err, "not registered")
+}
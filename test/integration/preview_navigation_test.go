@@ -0,0 +1,74 @@
+package integration
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/ofri/mde/internal/plugins"
+	"github.com/ofri/mde/internal/tui"
+	"github.com/ofri/mde/pkg/ast"
+	"github.com/ofri/mde/pkg/plugin"
+	"github.com/ofri/mde/test/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func pressKey(model *tui.Model, code rune, text string) {
+	model.Update(tea.KeyPressMsg(tea.Key{Code: code, Text: text}))
+}
+
+func TestPreviewNavigation_KeysScrollWithoutMovingCursor(t *testing.T) {
+	plugin.ResetRegistry()
+	require.NoError(t, plugins.InitializePlugins())
+
+	model := tui.New()
+	testutils.LoadContentIntoModel(model, previewSyncDoc())
+	testutils.SetModelSize(model, 80, 12)
+	model.GetEditor().GetCursor().SetBufferPos(ast.BufferPos{Line: 0, Col: 0})
+	model.TogglePreviewMode()
+
+	pressKey(model, 'j', "j")
+	pressKey(model, 'j', "j")
+	assert.Equal(t, 2, model.GetPreviewTopLine())
+	pressKey(model, 'k', "k")
+	assert.Equal(t, 1, model.GetPreviewTopLine())
+
+	pressKey(model, tea.KeySpace, " ")
+	assert.Equal(t, 10, model.GetPreviewTopLine(), "Space pages down by a screen less one line")
+
+	pressKey(model, 'G', "G")
+	lines := model.GetEditor().GetDocument().LineCount()
+	assert.Equal(t, lines-10, model.GetPreviewTopLine())
+	pressKey(model, 'g', "g")
+	assert.Equal(t, 0, model.GetPreviewTopLine())
+
+	// Typing and Enter neither edit nor move the cursor
+	pressKey(model, 'x', "x")
+	pressKey(model, tea.KeyEnter, "")
+	pressKey(model, tea.KeyDown, "")
+	assert.Equal(t, lines, model.GetEditor().GetDocument().LineCount())
+	assert.Equal(t, "para 0 a", model.GetEditor().GetDocument().GetLine(0))
+	assert.Equal(t, ast.BufferPos{Line: 0, Col: 0}, model.GetEditor().GetCursor().GetBufferPos())
+}
+
+func TestPreviewNavigation_HeadingJumps(t *testing.T) {
+	plugin.ResetRegistry()
+	require.NoError(t, plugins.InitializePlugins())
+
+	content := strings.Join([]string{
+		"# One", "text", "```", "# not a heading", "```", "Two", "---", "text", "## Three", "text",
+	}, "\n")
+	model := tui.New()
+	testutils.LoadContentIntoModel(model, content)
+	testutils.SetModelSize(model, 80, 6)
+	model.TogglePreviewMode()
+
+	pressKey(model, 'n', "n")
+	assert.Equal(t, 5, model.GetPreviewTopLine(), "Fenced lines are skipped; setext headings count")
+	pressKey(model, 'n', "n")
+	assert.Equal(t, 8, model.GetPreviewTopLine())
+	pressKey(model, 'p', "p")
+	pressKey(model, 'p', "p")
+	assert.Equal(t, 0, model.GetPreviewTopLine())
+}
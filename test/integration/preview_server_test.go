@@ -0,0 +1,60 @@
+package integration
+
+import (
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/ofri/mde/internal/config"
+	"github.com/ofri/mde/internal/plugins"
+	"github.com/ofri/mde/internal/tui"
+	"github.com/ofri/mde/pkg/plugin"
+	"github.com/ofri/mde/test/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPreviewServer_FollowsBuffer(t *testing.T) {
+	plugin.ResetRegistry()
+	require.NoError(t, plugins.InitializePlugins())
+	t.Setenv("BROWSER", "true") // Opening the page does nothing
+
+	path := filepath.Join(t.TempDir(), "notes.md")
+	require.NoError(t, os.WriteFile(path, []byte("# Draft\n"), 0o644))
+	cfg := config.Default()
+	cfg.PreviewAddress = "127.0.0.1:0"
+	model := tui.New()
+	model.SetConfig(cfg)
+	model.SetFilename(path)
+	testutils.SetModelSize(model, 100, 10)
+	defer model.Shutdown()
+
+	_, ok := model.RunCommand("preview-server")
+	require.True(t, ok)
+	url, err := model.StartPreviewServer() // Already running: only the URL
+	require.NoError(t, err)
+	assert.Contains(t, model.View(), "Previewing at "+url)
+
+	content := func() string {
+		resp, err := http.Get(url + "content")
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		return string(body)
+	}
+	assert.Contains(t, content(), `<h1 id="draft">Draft</h1>`)
+
+	model.Update(tea.KeyPressMsg(tea.Key{Code: tea.KeyEnd}))
+	model.Update(tea.KeyPressMsg(tea.Key{Code: 's', Text: "s"}))
+	assert.Contains(t, content(), `<h1 id="drafts">Drafts</h1>`, "Edits reach the page unsaved")
+
+	_, ok = model.RunCommand("preview-server")
+	require.True(t, ok)
+	assert.Contains(t, model.View(), "Preview server stopped")
+	_, err = http.Get(url + "content")
+	assert.Error(t, err)
+}
@@ -0,0 +1,72 @@
+package integration
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/ofri/mde/internal/plugins"
+	"github.com/ofri/mde/internal/tui"
+	"github.com/ofri/mde/pkg/ast"
+	"github.com/ofri/mde/pkg/plugin"
+	"github.com/ofri/mde/test/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// previewSyncDoc has 40 paragraphs of three lines separated by blank lines.
+func previewSyncDoc() string {
+	var paragraphs []string
+	for i := 0; i < 40; i++ {
+		paragraphs = append(paragraphs, fmt.Sprintf("para %d a\npara %d b\npara %d c", i, i, i))
+	}
+	return strings.Join(paragraphs, "\n\n")
+}
+
+func TestPreviewSync_ScrollsToCursorBlock(t *testing.T) {
+	plugin.ResetRegistry()
+	require.NoError(t, plugins.InitializePlugins())
+
+	model := tui.New()
+	testutils.LoadContentIntoModel(model, previewSyncDoc())
+	testutils.SetModelSize(model, 80, 12)
+
+	// Line 82 is the middle line of paragraph 20, which starts at line 80
+	model.GetEditor().GotoLine(83)
+	model.GetEditor().AdjustViewPort()
+	editorTop := model.GetEditor().GetViewport().GetTopLine()
+	require.Greater(t, editorTop, 0)
+
+	model.Update(tea.KeyPressMsg(tea.Key{Code: 'p', Mod: tea.ModCtrl}))
+	require.True(t, model.IsPreviewMode())
+	assert.Equal(t, min(editorTop, 80), model.GetPreviewTopLine())
+	assert.Contains(t, model.View(), "para 20 a", "Preview shows the start of the cursor's paragraph")
+}
+
+func TestPreviewSync_RemembersPreviewOffset(t *testing.T) {
+	plugin.ResetRegistry()
+	require.NoError(t, plugins.InitializePlugins())
+
+	model := tui.New()
+	testutils.LoadContentIntoModel(model, previewSyncDoc())
+	testutils.SetModelSize(model, 80, 12)
+
+	model.TogglePreviewMode()
+	for i := 0; i < 5; i++ {
+		model.Update(tea.MouseWheelMsg(tea.Mouse{Button: tea.MouseWheelDown}))
+	}
+	assert.Equal(t, 15, model.GetPreviewTopLine())
+	assert.Equal(t, 0, model.GetEditor().GetViewport().GetTopLine(), "Preview scrolling leaves the editor viewport alone")
+
+	// Leaving and re-entering without moving the cursor keeps the offset
+	model.TogglePreviewMode()
+	model.TogglePreviewMode()
+	assert.Equal(t, 15, model.GetPreviewTopLine())
+
+	// Moving the cursor re-syncs preview to it
+	model.TogglePreviewMode()
+	model.GetEditor().GetCursor().SetBufferPos(ast.BufferPos{Line: 1, Col: 0})
+	model.TogglePreviewMode()
+	assert.Equal(t, 0, model.GetPreviewTopLine())
+}
@@ -0,0 +1,68 @@
+package integration
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/ofri/mde/internal/config"
+	"github.com/ofri/mde/internal/plugins"
+	"github.com/ofri/mde/internal/tui"
+	"github.com/ofri/mde/pkg/ast"
+	"github.com/ofri/mde/pkg/plugin"
+	"github.com/ofri/mde/test/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// projectModel opens content as a file in a project whose .mde.toml holds
+// settings, returning the model and the project's directory.
+func projectModel(t *testing.T, settings, content string) (*tui.Model, string) {
+	plugin.ResetRegistry()
+	require.NoError(t, plugins.InitializePlugins())
+
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "docs"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, config.ProjectFile), []byte(settings), 0o644))
+
+	model := tui.New()
+	model.SetConfig(config.Default())
+	model.NewBuffer(content, filepath.Join(dir, "docs", "notes.md"))
+	testutils.SetModelSize(model, 100, 20)
+	return model, dir
+}
+
+func TestProject_SettingsApplyToItsFiles(t *testing.T) {
+	model, _ := projectModel(t, "tab_width = 2\nline_width = 20\nlink_style = \"reference\"\n\n[lint]\nfootnote = false\n",
+		"one two three four five six seven\n\nSee[^1] mde")
+	editor := model.GetEditor()
+	assert.Equal(t, 2, editor.GetViewport().GetTabWidth())
+
+	_, ok := model.RunCommand("reflow-paragraph")
+	require.True(t, ok)
+	assert.Equal(t, "one two three four\nfive six seven", editor.GetDocument().GetText()[:33])
+
+	model.Update(tea.KeyPressMsg(tea.Key{Code: tea.KeyF8}))
+	assert.Contains(t, statusLine(model), "No problems", "The project turned the footnote rule off")
+
+	editor.GetCursor().SetBufferPos(ast.BufferPos{Line: 3, Col: 9})
+	_, ok = model.RunCommand("link")
+	require.True(t, ok)
+	assert.Equal(t, "See[^1] [mde][1]\n\n[1]: ", editor.GetDocument().GetText()[35:])
+}
+
+func TestProject_SettingsStayWithTheProject(t *testing.T) {
+	model, _ := projectModel(t, "tab_width = 2\n", "text")
+	assert.Equal(t, 2, model.GetEditor().GetViewport().GetTabWidth())
+
+	model.NewBuffer("text", filepath.Join(t.TempDir(), "elsewhere.md"))
+	model.Update(tea.WindowSizeMsg{Width: 100, Height: 20})
+	assert.Equal(t, 4, model.GetEditor().GetViewport().GetTabWidth())
+}
+
+func TestProject_DisallowedSettingsAreReported(t *testing.T) {
+	model, _ := projectModel(t, "tab_width = 2\npandoc_command = \"sh\"\n", "text")
+	assert.Contains(t, statusLine(model), "cannot set pandoc_command")
+	assert.Equal(t, 4, model.GetEditor().GetViewport().GetTabWidth(), "Nothing applies from a project in error")
+}
@@ -0,0 +1,170 @@
+package integration
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/ofri/mde/internal/plugins"
+	"github.com/ofri/mde/internal/plugins/storage"
+	"github.com/ofri/mde/internal/remote"
+	"github.com/ofri/mde/internal/tui"
+	"github.com/ofri/mde/pkg/ast"
+	"github.com/ofri/mde/pkg/plugin"
+	"github.com/ofri/mde/test/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// flakyStorage is in-memory storage whose connection can drop: while
+// offline every transfer fails.
+type flakyStorage struct {
+	*storage.Memory
+	offline atomic.Bool
+}
+
+func (s *flakyStorage) ReadFile(ctx context.Context, name string) ([]byte, error) {
+	if s.offline.Load() {
+		return nil, fmt.Errorf("mem: %w", remote.ErrConnection)
+	}
+	return s.Memory.ReadFile(ctx, name)
+}
+
+func (s *flakyStorage) WriteFile(ctx context.Context, name string, data []byte) error {
+	if s.offline.Load() {
+		return fmt.Errorf("mem: %w", remote.ErrConnection)
+	}
+	return s.Memory.WriteFile(ctx, name, data)
+}
+
+// readFile returns the content of name in storage
+func readFile(t *testing.T, storage ast.FileBackend, name string) string {
+	data, err := storage.ReadFile(context.Background(), name)
+	require.NoError(t, err)
+	return string(data)
+}
+
+// runCmd runs cmd, and each command of a batch, feeding the messages to
+// the model. Commands the model returns are not run.
+func runCmd(model *tui.Model, cmd tea.Cmd) {
+	if cmd == nil {
+		return
+	}
+	switch msg := cmd().(type) {
+	case tea.BatchMsg:
+		for _, c := range msg {
+			runCmd(model, c)
+		}
+	case nil:
+	default:
+		model.Update(msg)
+	}
+}
+
+func TestRemoteFile_LoadAndSave(t *testing.T) {
+	plugin.ResetRegistry()
+	require.NoError(t, plugins.InitializePlugins())
+	memory := storage.NewMemory()
+	require.NoError(t, plugin.RegisterStorage(memory.Name(), memory))
+	require.NoError(t, memory.WriteFile(context.Background(), "mem://host/a.md", []byte("# Remote\n\nText")))
+
+	model := tui.New()
+	testutils.SetModelSize(model, 80, 10)
+	model.SetFilename("mem://host/a.md")
+	assert.Empty(t, model.GetEditor().GetDocument().GetText(), "The file loads in the background")
+
+	runCmd(model, model.Init())
+	doc := model.GetEditor().GetDocument()
+	assert.Equal(t, "# Remote\n\nText", doc.GetText())
+	assert.Equal(t, "mem://host/a.md", doc.GetFilename())
+	assert.False(t, doc.IsModified())
+
+	typeText(model, "New ")
+	_, cmd := model.Update(tea.KeyPressMsg(tea.Key{Code: 's', Mod: tea.ModCtrl}))
+	assert.Contains(t, ansi.Strip(model.View()), "Saving mem://host/a.md")
+	runCmd(model, cmd)
+	assert.Equal(t, "New # Remote\n\nText", readFile(t, memory, "mem://host/a.md"))
+	assert.Contains(t, ansi.Strip(model.View()), "Saved mem://host/a.md")
+}
+
+func TestRemoteFile_EditsDuringSaveStayUnsaved(t *testing.T) {
+	plugin.ResetRegistry()
+	require.NoError(t, plugins.InitializePlugins())
+	memory := storage.NewMemory()
+	require.NoError(t, plugin.RegisterStorage(memory.Name(), memory))
+
+	model := tui.New()
+	testutils.SetModelSize(model, 80, 10)
+	model.SetFilename("mem://host/a.md")
+	runCmd(model, model.Init())
+
+	typeText(model, "saved")
+	_, cmd := model.Update(tea.KeyPressMsg(tea.Key{Code: 's', Mod: tea.ModCtrl}))
+	typeText(model, " later")
+	runCmd(model, cmd)
+	assert.Equal(t, "saved", readFile(t, memory, "mem://host/a.md"), "The text as it was when the save began")
+	assert.True(t, model.GetEditor().GetDocument().IsModified(), "Edits made during the transfer are not saved")
+
+	_, cmd = model.Update(tea.KeyPressMsg(tea.Key{Code: 's', Mod: tea.ModCtrl}))
+	runCmd(model, cmd)
+	assert.Equal(t, "saved later", readFile(t, memory, "mem://host/a.md"))
+	assert.False(t, model.GetEditor().GetDocument().IsModified())
+}
+
+func TestRemoteFile_ConnectionLost(t *testing.T) {
+	plugin.ResetRegistry()
+	require.NoError(t, plugins.InitializePlugins())
+	flaky := &flakyStorage{Memory: storage.NewMemory()}
+	require.NoError(t, plugin.RegisterStorage(flaky.Name(), flaky))
+
+	model := tui.New()
+	testutils.SetModelSize(model, 120, 10)
+	model.SetFilename("mem://host/new.md")
+	runCmd(model, model.Init())
+	assert.Equal(t, "mem://host/new.md", model.GetEditor().GetDocument().GetFilename(), "A missing file opens empty")
+
+	typeText(model, "draft")
+	flaky.offline.Store(true)
+	_, cmd := model.Update(tea.KeyPressMsg(tea.Key{Code: 's', Mod: tea.ModCtrl}))
+	runCmd(model, cmd)
+	assert.Contains(t, ansi.Strip(model.View()), "connection lost (buffer kept, try again)")
+	assert.True(t, model.GetEditor().GetDocument().IsModified())
+
+	flaky.offline.Store(false)
+	_, cmd = model.Update(tea.KeyPressMsg(tea.Key{Code: 's', Mod: tea.ModCtrl}))
+	runCmd(model, cmd)
+	assert.Equal(t, "draft", readFile(t, flaky, "mem://host/new.md"))
+}
+
+func TestRemoteFile_URLOpensReadOnlyPreview(t *testing.T) {
+	plugin.ResetRegistry()
+	require.NoError(t, plugins.InitializePlugins())
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "# Project\n\nRead me.\n")
+	}))
+	defer srv.Close()
+	url := srv.URL + "/README.md"
+
+	model := tui.New()
+	testutils.SetModelSize(model, 100, 10)
+	model.SetFilename(url)
+	assert.Contains(t, ansi.Strip(model.View()), "Loading "+url)
+
+	runCmd(model, model.Init())
+	assert.Equal(t, "# Project\n\nRead me.\n", model.GetEditor().GetDocument().GetText())
+	assert.True(t, model.IsPreviewMode())
+	assert.Contains(t, ansi.Strip(model.View()), "Read-only  ^Q Quit")
+
+	typeText(model, "x")
+	model.Update(tea.KeyPressMsg(tea.Key{Code: 'p', Mod: tea.ModCtrl}))
+	assert.True(t, model.IsPreviewMode(), "Preview stays on")
+	_, cmd := model.Update(tea.KeyPressMsg(tea.Key{Code: 's', Mod: tea.ModCtrl}))
+	assert.Nil(t, cmd)
+	assert.Contains(t, ansi.Strip(model.View()), url+" is read-only")
+	assert.False(t, model.GetEditor().GetDocument().IsModified())
+}
@@ -0,0 +1,63 @@
+package integration
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/ofri/mde/internal/plugins/parsers"
+	"github.com/ofri/mde/internal/tui"
+	"github.com/ofri/mde/pkg/plugin"
+	"github.com/ofri/mde/test/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// brokenRenderer fails in edit mode and panics in preview.
+type brokenRenderer struct {
+	MockRenderer
+}
+
+func (r *brokenRenderer) RenderVisible(ctx context.Context, renderCtx *plugin.RenderContext) ([]plugin.RenderedLine, error) {
+	return nil, errors.New("style table corrupt")
+}
+
+func (r *brokenRenderer) RenderPreviewVisible(ctx context.Context, renderCtx *plugin.RenderContext) ([]plugin.RenderedLine, error) {
+	panic("index out of range")
+}
+
+func TestRenderFailure_ShownNotFatal(t *testing.T) {
+	logPath := filepath.Join(t.TempDir(), "debug.log")
+	t.Setenv("MDE_DEBUG_LOG", logPath)
+
+	plugin.ResetRegistry()
+	registry := plugin.GetRegistry()
+	require.NoError(t, registry.RegisterParser("commonmark", parsers.NewCommonMarkParser()))
+	require.NoError(t, registry.RegisterRenderer("broken", &brokenRenderer{MockRenderer{name: "broken"}}))
+
+	model := tui.New()
+	testutils.LoadContentIntoModel(model, "hello")
+	testutils.SetModelSize(model, 80, 10)
+
+	view := model.View()
+	assert.Contains(t, view, "Could not render the editor: style table corrupt")
+	assert.Contains(t, view, logPath)
+	model.View()
+
+	// Editing keeps working behind the error screen
+	model.Update(tea.KeyPressMsg(tea.Key{Code: '!', Text: "!"}))
+	assert.Equal(t, "!hello", model.GetEditor().GetDocument().GetText())
+
+	model.TogglePreviewMode()
+	assert.Contains(t, model.View(), "Could not render the preview: panic: index out of range")
+
+	data, err := os.ReadFile(logPath)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "editor failed: style table corrupt")
+	assert.Contains(t, string(data), "recovered index out of range")
+	assert.Equal(t, 1, strings.Count(string(data), "editor failed"), "Repeated frames log once")
+}
@@ -0,0 +1,75 @@
+package integration
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/ofri/mde/internal/config"
+	"github.com/ofri/mde/internal/plugins"
+	"github.com/ofri/mde/internal/tui"
+	"github.com/ofri/mde/pkg/ast"
+	"github.com/ofri/mde/pkg/plugin"
+	"github.com/ofri/mde/test/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newRunBlockModel(t *testing.T, content string) *tui.Model {
+	plugin.ResetRegistry()
+	require.NoError(t, plugins.InitializePlugins())
+
+	model := tui.New()
+	cfg := config.Default()
+	cfg.Interpreters = map[string]string{"sh": "sh"}
+	model.SetConfig(cfg)
+	testutils.LoadContentIntoModel(model, content)
+	testutils.SetModelSize(model, 80, 12)
+	model.GetEditor().GetCursor().SetBufferPos(ast.BufferPos{Line: 1})
+	return model
+}
+
+// deliver runs cmd and passes its message to the model.
+func deliver(t *testing.T, model *tui.Model, cmd tea.Cmd) {
+	require.NotNil(t, cmd)
+	model.Update(cmd())
+}
+
+func TestRunBlock_InsertsThenUpdatesOutput(t *testing.T) {
+	model := newRunBlockModel(t, "```sh\necho hello\n```\n\nAfter")
+	doc := model.GetEditor().GetDocument()
+
+	cmd, ok := model.RunCommand("run-block")
+	require.True(t, ok)
+	assert.Nil(t, cmd, "The first run asks before running anything")
+	assert.Contains(t, model.View(), "unsandboxed")
+
+	_, cmd = model.Update(tea.KeyPressMsg(tea.Key{Code: 'y', Text: "y"}))
+	deliver(t, model, cmd)
+	assert.Equal(t, "```sh\necho hello\n```\n\n```output\nhello\n```\n\nAfter", doc.GetText())
+	assert.Equal(t, ast.BufferPos{Line: 1}, model.GetEditor().GetCursor().GetBufferPos(), "The cursor stays in the code")
+
+	// Later runs are not confirmed again and replace the earlier output
+	editor := model.GetEditor()
+	editor.ReplaceRange(ast.Range{Start: ast.BufferPos{Line: 1}, End: ast.BufferPos{Line: 1, Col: 10}}, "echo bye; exit 2")
+	cmd, _ = model.RunCommand("run-block")
+	deliver(t, model, cmd)
+	assert.Equal(t, "```sh\necho bye; exit 2\n```\n\n```output\nbye\n[exit status 2]\n```\n\nAfter", doc.GetText())
+}
+
+func TestRunBlock_RefusesWithoutInterpreter(t *testing.T) {
+	model := newRunBlockModel(t, "```python\nprint(1)\n```")
+
+	cmd, _ := model.RunCommand("run-block")
+	assert.Nil(t, cmd)
+	assert.Contains(t, model.View(), "No interpreter for python")
+	assert.Equal(t, "```python\nprint(1)\n```", model.GetEditor().GetDocument().GetText())
+}
+
+func TestRunBlock_DeclinedDoesNotRun(t *testing.T) {
+	model := newRunBlockModel(t, "```sh\necho hello\n```")
+
+	model.RunCommand("run-block")
+	_, cmd := model.Update(tea.KeyPressMsg(tea.Key{Code: 'n', Text: "n"}))
+	assert.Nil(t, cmd)
+	assert.Equal(t, "```sh\necho hello\n```", model.GetEditor().GetDocument().GetText())
+}
@@ -0,0 +1,101 @@
+package integration
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/ofri/mde/internal/config"
+	"github.com/ofri/mde/internal/plugins"
+	"github.com/ofri/mde/internal/tui"
+	"github.com/ofri/mde/pkg/ast"
+	"github.com/ofri/mde/pkg/plugin"
+	"github.com/ofri/mde/test/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func screenReaderModel(t *testing.T, content string) *tui.Model {
+	t.Helper()
+	plugin.ResetRegistry()
+	require.NoError(t, plugins.InitializePlugins())
+
+	cfg := config.Default()
+	cfg.ScreenReader = true
+	model := tui.New()
+	model.SetConfig(cfg)
+	model.NewBuffer(content, "notes.md")
+	testutils.SetModelSize(model, 80, 10)
+	return model
+}
+
+// screenReaderStatus returns the status bar of view, the line that gives
+// the cursor position.
+func screenReaderStatus(view string) string {
+	for _, line := range strings.Split(view, "\n") {
+		if strings.Contains(line, "Column") {
+			return strings.TrimRight(line, " ")
+		}
+	}
+	return ""
+}
+
+func TestScreenReader_PlainText(t *testing.T) {
+	model := screenReaderModel(t, "# Title\n\nSome **bold** text")
+	model.GetEditor().GetCursor().SetBufferPos(ast.BufferPos{Line: 2, Col: 5})
+
+	view := model.View()
+	assert.Equal(t, testutils.StripAnsiEscapes(view), view, "No colors or styles")
+	assert.NotContains(t, view, "│")
+	assert.NotContains(t, view, "█", "No cursor glyph")
+	assert.Contains(t, view, " 3: Some **bold** text")
+
+	assert.Equal(t, "Edit | notes.md [Modified] | Title | Line 3, Column 6", screenReaderStatus(view))
+}
+
+func TestScreenReader_AnnouncesModes(t *testing.T) {
+	model := screenReaderModel(t, "text")
+	statusBar := func() string { return screenReaderStatus(model.View()) }
+
+	model.Update(tea.KeyPressMsg(tea.Key{Code: 'f', Mod: tea.ModCtrl}))
+	assert.True(t, strings.HasPrefix(statusBar(), "Find | "), statusBar())
+	pressEsc(model)
+	assert.True(t, strings.HasPrefix(statusBar(), "Edit | "), statusBar())
+
+	model.Update(tea.KeyPressMsg(tea.Key{Code: tea.KeyInsert}))
+	assert.True(t, strings.HasPrefix(statusBar(), "Overwrite | "), statusBar())
+
+	model.Update(tea.KeyPressMsg(tea.Key{Code: 'p', Mod: tea.ModCtrl}))
+	assert.True(t, strings.HasPrefix(statusBar(), "Preview | "), statusBar())
+}
+
+func TestScreenReader_TerminalCursorFollowsEditor(t *testing.T) {
+	model := screenReaderModel(t, "hello\nworld")
+	model.GetEditor().GetCursor().SetBufferPos(ast.BufferPos{Line: 1, Col: 2})
+	program, ok := tui.WithTerminalCursor(model).(interface {
+		View() (string, *tea.Cursor)
+	})
+	require.True(t, ok)
+
+	_, cursor := program.View()
+	require.NotNil(t, cursor)
+	screenPos, err := model.GetEditor().GetCursor().GetScreenPos()
+	require.NoError(t, err)
+	assert.Equal(t, tea.Position{X: screenPos.Col, Y: 1}, cursor.Position)
+
+	_, ok = model.RunCommand("screen-reader")
+	require.True(t, ok)
+	_, cursor = program.View()
+	assert.Nil(t, cursor, "The cursor is drawn in the text again")
+	assert.Contains(t, model.View(), "│")
+}
+
+func TestScreenReader_PreviewWithoutBoxDrawing(t *testing.T) {
+	model := screenReaderModel(t, "> quoted\n\nTitle\n=====")
+	model.Update(tea.KeyPressMsg(tea.Key{Code: 'p', Mod: tea.ModCtrl}))
+
+	view := model.View()
+	assert.Contains(t, view, "> quoted")
+	assert.NotContains(t, view, "│")
+	assert.NotContains(t, view, "═")
+}
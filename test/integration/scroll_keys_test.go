@@ -0,0 +1,32 @@
+package integration
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/ofri/mde/internal/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScrollKeys_MoveViewportNotCursor(t *testing.T) {
+	model := wheelModel(t, config.Default())
+	height := model.GetContentHeight()
+	top := func() int { return model.GetEditor().GetViewport().GetTopLine() }
+
+	model.Update(tea.KeyPressMsg(tea.Key{Code: tea.KeyPgDown}))
+	assert.Equal(t, height-1, top())
+
+	model.Update(tea.KeyPressMsg(tea.Key{Code: tea.KeyUp, Mod: tea.ModCtrl}))
+	assert.Equal(t, height-2, top())
+
+	model.Update(tea.KeyPressMsg(tea.Key{Code: 'd', Mod: tea.ModCtrl}))
+	assert.Equal(t, height-2+height/2, top())
+
+	model.Update(tea.KeyPressMsg(tea.Key{Code: tea.KeyEnd, Mod: tea.ModCtrl}))
+	assert.Equal(t, model.GetEditor().GetDocument().LineCount()-height, top())
+
+	model.Update(tea.KeyPressMsg(tea.Key{Code: tea.KeyHome, Mod: tea.ModCtrl}))
+	assert.Equal(t, 0, top())
+
+	assert.Equal(t, 0, model.GetEditor().GetCursor().GetBufferPos().Line)
+}
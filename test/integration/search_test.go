@@ -0,0 +1,44 @@
+package integration
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/ofri/mde/pkg/ast"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSearch_Incremental(t *testing.T) {
+	model := vimModel(t, "alpha\nbeta\nalphabet\ngamma")
+	model.GetEditor().GetCursor().SetBufferPos(ast.BufferPos{Line: 1, Col: 0})
+	cursor := func() ast.BufferPos { return model.GetEditor().GetCursor().GetBufferPos() }
+
+	typeKeys(model, "/alp")
+	assert.Equal(t, ast.BufferPos{Line: 2, Col: 0}, cursor(), "The cursor follows the input")
+	typeKeys(model, "x")
+	assert.Equal(t, ast.BufferPos{Line: 1, Col: 0}, cursor(), "Back to the origin without a match")
+	model.Update(tea.KeyPressMsg(tea.Key{Code: tea.KeyBackspace}))
+	typeKeys(model, "habet")
+	assert.Equal(t, ast.BufferPos{Line: 2, Col: 0}, cursor())
+
+	pressEsc(model)
+	assert.Equal(t, ast.BufferPos{Line: 1, Col: 0}, cursor(), "Escape returns to the origin")
+	assert.NotContains(t, model.View(), "Find:", "The prompt is closed")
+}
+
+func TestSearch_BackwardAndRepeat(t *testing.T) {
+	model := vimModel(t, "cat one\ncat two\ncat three")
+	model.GetEditor().GetCursor().SetBufferPos(ast.BufferPos{Line: 1, Col: 4})
+	cursor := func() ast.BufferPos { return model.GetEditor().GetCursor().GetBufferPos() }
+
+	typeKeys(model, "?cat")
+	model.Update(tea.KeyPressMsg(tea.Key{Code: tea.KeyEnter}))
+	assert.Equal(t, ast.BufferPos{Line: 1, Col: 0}, cursor())
+
+	typeKeys(model, "n")
+	assert.Equal(t, ast.BufferPos{Line: 0, Col: 0}, cursor(), "n keeps searching backward")
+	typeKeys(model, "n")
+	assert.Equal(t, ast.BufferPos{Line: 2, Col: 0}, cursor(), "Wrapping around the start")
+	typeKeys(model, "N")
+	assert.Equal(t, ast.BufferPos{Line: 0, Col: 0}, cursor(), "N reverses the direction")
+}
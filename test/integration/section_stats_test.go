@@ -0,0 +1,41 @@
+package integration
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/x/ansi"
+	"github.com/ofri/mde/internal/plugins"
+	"github.com/ofri/mde/internal/tui"
+	"github.com/ofri/mde/pkg/ast"
+	"github.com/ofri/mde/pkg/plugin"
+	"github.com/ofri/mde/test/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSectionStats_Panel(t *testing.T) {
+	plugin.ResetRegistry()
+	require.NoError(t, plugins.InitializePlugins())
+
+	model := tui.New()
+	model.NewBuffer("# Chapter one\nA few words here.\n## Scene\nMore.\n# Chapter two\nShort.", "book.md")
+	testutils.SetModelSize(model, 100, 10)
+	assert.NotContains(t, model.View(), "Words")
+
+	_, ok := model.RunCommand("section-stats")
+	require.True(t, ok)
+	lines := strings.Split(ansi.Strip(model.View()), "\n")
+	assert.Regexp(t, `Section\s+Words\s+Chars $`, lines[0])
+	assert.Regexp(t, `Chapter one\s+5\s+22 $`, lines[1], "Totals include subsections")
+	assert.Regexp(t, `  Scene\s+1\s+5 $`, lines[2])
+	assert.Regexp(t, `Chapter two\s+1\s+6 $`, lines[3])
+
+	model.GetEditor().GetCursor().SetBufferPos(ast.BufferPos{Line: 1})
+	typeText(model, "Two ")
+	lines = strings.Split(ansi.Strip(model.View()), "\n")
+	assert.Regexp(t, `Chapter one\s+6\s+26 $`, lines[1], "Counts follow edits")
+
+	model.RunCommand("section-stats")
+	assert.NotContains(t, model.View(), "Words")
+}
@@ -0,0 +1,68 @@
+package integration
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/ofri/mde/internal/plugins"
+	"github.com/ofri/mde/internal/tui"
+	"github.com/ofri/mde/pkg/ast"
+	"github.com/ofri/mde/pkg/plugin"
+	"github.com/ofri/mde/test/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSelectionKeys(t *testing.T) {
+	plugin.ResetRegistry()
+	require.NoError(t, plugins.InitializePlugins())
+
+	model := tui.New()
+	testutils.LoadContentIntoModel(model, "alpha beta gamma\n\nnext paragraph")
+	testutils.SetModelSize(model, 80, 20)
+	editor := model.GetEditor()
+	reset := func(col int) {
+		editor.GetCursor().ClearSelection()
+		editor.GetCursor().SetBufferPos(ast.BufferPos{Line: 0, Col: col})
+	}
+
+	reset(6)
+	model.Update(tea.KeyPressMsg(tea.Key{Code: tea.KeyEnd, Mod: tea.ModShift}))
+	assert.Equal(t, "beta gamma", editor.GetSelectionText())
+
+	reset(6)
+	model.Update(tea.KeyPressMsg(tea.Key{Code: tea.KeyHome, Mod: tea.ModShift}))
+	assert.Equal(t, "alpha ", editor.GetSelectionText())
+
+	reset(0)
+	model.Update(tea.KeyPressMsg(tea.Key{Code: tea.KeyRight, Mod: tea.ModCtrl | tea.ModShift}))
+	model.Update(tea.KeyPressMsg(tea.Key{Code: tea.KeyRight, Mod: tea.ModAlt | tea.ModShift}))
+	assert.Equal(t, "alpha beta ", editor.GetSelectionText())
+
+	reset(6)
+	model.Update(tea.KeyPressMsg(tea.Key{Code: tea.KeyEnd, Mod: tea.ModCtrl | tea.ModShift}))
+	assert.Equal(t, "beta gamma\n\nnext paragraph", editor.GetSelectionText())
+
+	reset(3)
+	model.Update(tea.KeyPressMsg(tea.Key{Code: 'p', Mod: tea.ModAlt}))
+	assert.Equal(t, "alpha beta gamma", editor.GetSelectionText())
+}
+
+func TestMotionKeys_ParagraphAndSentence(t *testing.T) {
+	plugin.ResetRegistry()
+	require.NoError(t, plugins.InitializePlugins())
+
+	model := tui.New()
+	testutils.LoadContentIntoModel(model, "One. Two.\n\nThree.")
+	testutils.SetModelSize(model, 80, 20)
+	cursor := func() ast.BufferPos { return model.GetEditor().GetCursor().GetBufferPos() }
+
+	model.Update(tea.KeyPressMsg(tea.Key{Code: tea.KeyDown, Mod: tea.ModAlt}))
+	assert.Equal(t, ast.BufferPos{Line: 2, Col: 0}, cursor())
+
+	model.Update(tea.KeyPressMsg(tea.Key{Code: 'a', Mod: tea.ModAlt}))
+	assert.Equal(t, ast.BufferPos{Line: 0, Col: 5}, cursor())
+
+	model.Update(tea.KeyPressMsg(tea.Key{Code: 'e', Mod: tea.ModAlt | tea.ModShift}))
+	assert.Equal(t, "Two.\n\n", model.GetEditor().GetSelectionText())
+}
@@ -0,0 +1,184 @@
+package integration
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/ofri/mde/internal/config"
+	"github.com/ofri/mde/internal/plugins"
+	"github.com/ofri/mde/internal/tui"
+	"github.com/ofri/mde/pkg/plugin"
+	"github.com/ofri/mde/test/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// sharer runs the commands of a model in a shared session, queueing the
+// messages they return for the test to feed to it.
+type sharer struct {
+	model *tui.Model
+	msgs  chan tea.Msg
+}
+
+func (s *sharer) run(cmd tea.Cmd) {
+	if cmd == nil {
+		return
+	}
+	go func() {
+		switch msg := cmd().(type) {
+		case tea.BatchMsg:
+			for _, c := range msg {
+				s.run(c)
+			}
+		case nil:
+		default:
+			s.msgs <- msg
+		}
+	}()
+}
+
+// settle feeds the sharers their messages until none has come for a
+// while.
+func settle(sharers ...*sharer) {
+	for {
+		select {
+		case msg := <-sharers[0].msgs:
+			_, cmd := sharers[0].model.Update(msg)
+			sharers[0].run(cmd)
+		case msg := <-sharers[1].msgs:
+			_, cmd := sharers[1].model.Update(msg)
+			sharers[1].run(cmd)
+		case <-time.After(300 * time.Millisecond):
+			return
+		}
+	}
+}
+
+// shareStatus returns the status bar.
+func shareStatus(model *tui.Model) string {
+	for _, line := range strings.Split(model.View(), "\n") {
+		if strings.Contains(line, "Ln ") {
+			return line
+		}
+	}
+	return ""
+}
+
+func TestShare_HostAndGuestEditTogether(t *testing.T) {
+	plugin.ResetRegistry()
+	require.NoError(t, plugins.InitializePlugins())
+
+	cfg := config.Default()
+	cfg.ShareAddress = filepath.Join(t.TempDir(), "share.sock")
+	cfg.Author = "Ann"
+	host := &sharer{model: tui.New(), msgs: make(chan tea.Msg, 64)}
+	host.model.SetConfig(cfg)
+	host.model.NewBuffer("Hello world", filepath.Join(t.TempDir(), "notes.md"))
+	testutils.SetModelSize(host.model, 80, 10)
+	cmd, ok := host.model.RunCommand("share")
+	require.True(t, ok)
+	host.run(cmd)
+
+	guestCfg := *cfg
+	guestCfg.Author = "Bob"
+	guest := &sharer{model: tui.New(), msgs: make(chan tea.Msg, 64)}
+	guest.model.SetConfig(&guestCfg)
+	testutils.SetModelSize(guest.model, 80, 10)
+	guest.model.RunCommand("join")
+	_, cmd = guest.model.Update(tea.KeyPressMsg(tea.Key{Code: tea.KeyEnter}))
+	guest.run(cmd)
+	settle(host, guest)
+
+	guestDoc := guest.model.GetEditor().GetDocument()
+	require.Equal(t, "Hello world", guestDoc.GetText(), "The guest gets the host's text")
+	assert.Contains(t, shareStatus(guest.model), "SHARED 2")
+	assert.Contains(t, shareStatus(host.model), "SHARED 2")
+
+	// Both type at once, in different places
+	host.model.Update(tea.KeyPressMsg(tea.Key{Code: tea.KeyEnd}))
+	typeText(host.model, "!")
+	typeText(guest.model, "Oh, ")
+	settle(host, guest)
+	hostDoc := host.model.GetEditor().GetDocument()
+	assert.Equal(t, "Oh, Hello world!", hostDoc.GetText())
+	assert.Equal(t, hostDoc.GetText(), guestDoc.GetText(), "The edits merge the same way for both")
+	assert.Equal(t, 16, host.model.GetEditor().GetCursor().GetBufferPos().Col, "The host's cursor stays after its text")
+	assert.Equal(t, 4, guest.model.GetEditor().GetCursor().GetBufferPos().Col)
+
+	// Both insert at the same place
+	typeText(host.model, "?")
+	guest.model.Update(tea.KeyPressMsg(tea.Key{Code: tea.KeyEnd}))
+	typeText(guest.model, ".")
+	settle(host, guest)
+	assert.Equal(t, hostDoc.GetText(), guestDoc.GetText())
+	assert.Len(t, hostDoc.GetText(), 18)
+
+	_, ok = guest.model.RunCommand("leave")
+	require.True(t, ok)
+	settle(host, guest)
+	assert.Contains(t, shareStatus(host.model), "SHARED 1", "The host hears the guest left")
+	assert.NotContains(t, shareStatus(guest.model), "SHARED")
+	host.model.Shutdown()
+}
+
+func TestShare_GuestJoinsLongDocument(t *testing.T) {
+	plugin.ResetRegistry()
+	require.NoError(t, plugins.InitializePlugins())
+
+	// About the size of a long README, with text deleted before joining
+	var text strings.Builder
+	for i := range 2000 {
+		fmt.Fprintf(&text, "## Section %d\n\nSome words of a paragraph, with `code` and *emphasis* in it.\n", i)
+	}
+	cfg := config.Default()
+	cfg.ShareAddress = filepath.Join(t.TempDir(), "share.sock")
+	cfg.Author = "Ann"
+	host := &sharer{model: tui.New(), msgs: make(chan tea.Msg, 64)}
+	host.model.SetConfig(cfg)
+	host.model.NewBuffer(text.String(), filepath.Join(t.TempDir(), "long.md"))
+	testutils.SetModelSize(host.model, 80, 10)
+	cmd, ok := host.model.RunCommand("share")
+	require.True(t, ok)
+	host.run(cmd)
+	host.model.Update(tea.KeyPressMsg(tea.Key{Code: tea.KeyDelete}))
+	host.model.Update(tea.KeyPressMsg(tea.Key{Code: tea.KeyDelete}))
+
+	guestCfg := *cfg
+	guestCfg.Author = "Bob"
+	guest := &sharer{model: tui.New(), msgs: make(chan tea.Msg, 64)}
+	guest.model.SetConfig(&guestCfg)
+	testutils.SetModelSize(guest.model, 80, 10)
+	guest.model.RunCommand("join")
+	_, cmd = guest.model.Update(tea.KeyPressMsg(tea.Key{Code: tea.KeyEnter}))
+	guest.run(cmd)
+
+	// The host edits as soon as it has welcomed the guest, while the
+	// guest may still be building its replica
+	for !strings.Contains(shareStatus(host.model), "SHARED 2") {
+		_, cmd := host.model.Update(<-host.msgs)
+		host.run(cmd)
+	}
+	host.model.Update(tea.KeyPressMsg(tea.Key{Code: tea.KeyDown}))
+	typeText(host.model, "host ")
+	settle(host, guest)
+	for deadline := time.Now().Add(time.Minute); strings.Contains(shareStatus(guest.model), "JOINING") && time.Now().Before(deadline); {
+		settle(host, guest) // The replica takes longer to build under the race detector
+	}
+
+	hostDoc := host.model.GetEditor().GetDocument()
+	guestDoc := guest.model.GetEditor().GetDocument()
+	require.Equal(t, hostDoc.GetText(), guestDoc.GetText(), "The guest gets the host's text and its later edits")
+	assert.True(t, strings.HasPrefix(guestDoc.GetText(), " Section 0\nhost \n"))
+	assert.False(t, guestDoc.IsModified())
+
+	typeText(guest.model, "guest")
+	typeText(host.model, "again ")
+	settle(host, guest)
+	assert.Equal(t, hostDoc.GetText(), guestDoc.GetText())
+	assert.True(t, strings.HasPrefix(hostDoc.GetText(), "guest Section 0\nhost again \n"))
+	host.model.Shutdown()
+}
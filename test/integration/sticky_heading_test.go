@@ -0,0 +1,49 @@
+package integration
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/ofri/mde/internal/plugins"
+	"github.com/ofri/mde/internal/tui"
+	"github.com/ofri/mde/pkg/ast"
+	"github.com/ofri/mde/pkg/plugin"
+	"github.com/ofri/mde/test/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStickyHeading(t *testing.T) {
+	plugin.ResetRegistry()
+	require.NoError(t, plugins.InitializePlugins())
+
+	lines := []string{"# Guide", "", "## Setup"}
+	for i := 1; i <= 40; i++ {
+		lines = append(lines, fmt.Sprintf("step %d", i))
+	}
+	model := tui.New()
+	model.NewBuffer(strings.Join(lines, "\n"), "guide.md")
+	testutils.SetModelSize(model, 80, 12)
+	firstRow := func() string {
+		return strings.TrimRight(strings.Split(testutils.StripAnsiEscapes(model.View()), "\n")[0], " ")
+	}
+
+	model.GetEditor().GetCursor().SetBufferPos(ast.BufferPos{Line: 25})
+	model.GetEditor().AdjustViewPort()
+	top := model.GetEditor().GetViewport().GetTopLine()
+	require.Greater(t, top, 2)
+	assert.Equal(t, "  3│ ## Setup", firstRow(), "The section's heading is pinned")
+
+	model.GetEditor().GetCursor().SetBufferPos(ast.BufferPos{Line: top})
+	assert.Equal(t, fmt.Sprintf("%3d│ █tep %d", top+1, top-2), firstRow(), "The cursor's row is never covered")
+
+	model.GetEditor().ScrollToTop()
+	assert.Equal(t, "  1│ # Guide", firstRow(), "Nothing is pinned while the heading is in view")
+
+	model.GetEditor().GetCursor().SetBufferPos(ast.BufferPos{Line: 25})
+	model.GetEditor().AdjustViewPort()
+	_, ok := model.RunCommand("sticky-heading")
+	require.True(t, ok)
+	assert.Equal(t, fmt.Sprintf("%3d│ step %d", top+1, top-2), firstRow())
+}
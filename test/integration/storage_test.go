@@ -0,0 +1,78 @@
+package integration
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ofri/mde/internal/plugins"
+	"github.com/ofri/mde/internal/plugins/storage"
+	"github.com/ofri/mde/pkg/ast"
+	"github.com/ofri/mde/pkg/plugin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStorage_RegistryDispatchesByScheme(t *testing.T) {
+	registry := plugin.NewRegistry()
+	require.NoError(t, registry.RegisterStorage("local", storage.NewLocal()))
+	require.NoError(t, registry.RegisterStorage("memory", storage.NewMemory()))
+	assert.ErrorContains(t, registry.RegisterStorage("other", storage.NewMemory()), "scheme 'mem' already served by 'memory'")
+
+	for name, want := range map[string]string{
+		"notes/a.md":       "local",
+		"file:///tmp/a.md": "local",
+		"mem://scratch.md": "memory",
+	} {
+		s, err := registry.GetStorageForFile(name)
+		require.NoError(t, err, name)
+		assert.Equal(t, want, s.Name(), name)
+	}
+	_, err := registry.GetStorageForFile("ftp://host/a.md")
+	assert.ErrorContains(t, err, "no storage plugin for ftp:// URLs")
+}
+
+func TestStorage_EditorUsesMemoryStorage(t *testing.T) {
+	plugin.ResetRegistry()
+	require.NoError(t, plugins.InitializePlugins())
+	memory := storage.NewMemory()
+	require.NoError(t, plugin.RegisterStorage(memory.Name(), memory))
+
+	editor := ast.NewEditor()
+	require.NoError(t, editor.LoadFile("mem://draft.md"), "A missing file loads empty")
+	editor.InsertText("# Draft")
+	require.NoError(t, editor.SaveFile(""))
+	assert.Equal(t, "# Draft", readFile(t, memory, "mem://draft.md"))
+
+	other := ast.NewEditor()
+	require.NoError(t, other.LoadFile("mem://draft.md"))
+	assert.Equal(t, "# Draft", other.GetDocument().GetText())
+
+	assert.ErrorContains(t, editor.SaveFile("ftp://host/a.md"), "no storage plugin for ftp:// URLs")
+}
+
+func TestStorage_LocalFilesAndConfigure(t *testing.T) {
+	plugin.ResetRegistry()
+	require.NoError(t, plugins.InitializePlugins())
+	path := filepath.Join(t.TempDir(), "a.md")
+
+	editor := ast.NewEditorWithContent("local")
+	require.NoError(t, editor.SaveFile("file://"+path))
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "local", string(data))
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(make([]byte, 2048))
+	}))
+	defer srv.Close()
+	_, err = ast.ReadFile(context.Background(), srv.URL+"/a.md")
+	require.NoError(t, err)
+
+	require.NoError(t, plugin.GetRegistry().Configure(map[string]map[string]interface{}{"http": {"maxBytes": int64(1024)}}))
+	_, err = ast.ReadFile(context.Background(), srv.URL+"/a.md")
+	assert.ErrorContains(t, err, "larger than 1 KB", "Configure reaches the storage")
+}
@@ -0,0 +1,137 @@
+package integration
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/ofri/mde/internal/plugins"
+	"github.com/ofri/mde/internal/tui"
+	"github.com/ofri/mde/pkg/ast"
+	"github.com/ofri/mde/pkg/plugin"
+	"github.com/ofri/mde/test/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// suggestModel opens content with the cursor at pos and suggestion mode
+// on.
+func suggestModel(t *testing.T, content string, pos ast.BufferPos) *tui.Model {
+	plugin.ResetRegistry()
+	require.NoError(t, plugins.InitializePlugins())
+
+	model := tui.New()
+	testutils.LoadContentIntoModel(model, content)
+	testutils.SetModelSize(model, 100, 20)
+	model.GetEditor().GetCursor().SetBufferPos(pos)
+	_, ok := model.RunCommand("suggest")
+	require.True(t, ok)
+	return model
+}
+
+func press(model *tui.Model, code rune, times int) {
+	for i := 0; i < times; i++ {
+		model.Update(tea.KeyPressMsg(tea.Key{Code: code}))
+	}
+}
+
+func TestSuggest_TypingIsAnInsertion(t *testing.T) {
+	model := suggestModel(t, "The fox", ast.BufferPos{Line: 0, Col: 4})
+	doc := model.GetEditor().GetDocument()
+
+	typeText(model, "quick ")
+	assert.Equal(t, "The {++quick ++}fox", doc.GetLine(0))
+	assert.Contains(t, model.View(), "SUGGEST")
+
+	// Backspace takes back the suggester's own text, and the mark with it
+	press(model, tea.KeyBackspace, 2)
+	assert.Equal(t, "The {++quic++}fox", doc.GetLine(0))
+	press(model, tea.KeyBackspace, 4)
+	assert.Equal(t, "The fox", doc.GetLine(0))
+	assert.Equal(t, ast.BufferPos{Line: 0, Col: 4}, model.GetEditor().GetCursor().GetBufferPos())
+}
+
+func TestSuggest_DeletingMarksText(t *testing.T) {
+	model := suggestModel(t, "The quick fox", ast.BufferPos{Line: 0, Col: 9})
+	doc := model.GetEditor().GetDocument()
+
+	press(model, tea.KeyBackspace, 3)
+	assert.Equal(t, "The qu{--ick--} fox", doc.GetLine(0), "Deletions beside each other join")
+	assert.Equal(t, ast.BufferPos{Line: 0, Col: 6}, model.GetEditor().GetCursor().GetBufferPos())
+
+	typeText(model, "een")
+	assert.Equal(t, "The qu{++een++}{--ick--} fox", doc.GetLine(0))
+
+	model.GetEditor().GetCursor().SetBufferPos(ast.BufferPos{Line: 0, Col: 24})
+	press(model, tea.KeyDelete, 2)
+	assert.Equal(t, "The qu{++een++}{--ick f--}ox", doc.GetLine(0))
+	assert.Equal(t, ast.BufferPos{Line: 0, Col: 26}, model.GetEditor().GetCursor().GetBufferPos())
+}
+
+func TestSuggest_ReplacingASelection(t *testing.T) {
+	model := suggestModel(t, "A red fox", ast.BufferPos{Line: 0, Col: 2})
+	model.GetEditor().GetCursor().SetSelection(&ast.Selection{Start: ast.BufferPos{Line: 0, Col: 2}, End: ast.BufferPos{Line: 0, Col: 5}})
+
+	typeText(model, "blue")
+	assert.Equal(t, "A {--red--}{++blue++} fox", model.GetEditor().GetDocument().GetLine(0))
+}
+
+func TestSuggest_AcceptRejectAndPanel(t *testing.T) {
+	plugin.ResetRegistry()
+	require.NoError(t, plugins.InitializePlugins())
+
+	model := tui.New()
+	testutils.LoadContentIntoModel(model, "A {--red--}{++blue++} fox\n{++Jumps.++}")
+	testutils.SetModelSize(model, 100, 20)
+	doc := model.GetEditor().GetDocument()
+
+	_, ok := model.RunCommand("changes-panel")
+	require.True(t, ok)
+	view := model.View()
+	assert.Contains(t, view, "Changes  +2 −1")
+	assert.Contains(t, view, "1 − red")
+	assert.Contains(t, view, "2 + Jumps.")
+
+	_, ok = model.RunCommand("next-change")
+	require.True(t, ok)
+	assert.Equal(t, ast.BufferPos{Line: 0, Col: 2}, model.GetEditor().GetCursor().GetBufferPos())
+	_, ok = model.RunCommand("next-change")
+	require.True(t, ok)
+	assert.Equal(t, ast.BufferPos{Line: 0, Col: 11}, model.GetEditor().GetCursor().GetBufferPos())
+	_, ok = model.RunCommand("reject-change")
+	require.True(t, ok)
+	assert.Equal(t, "A {--red--} fox", doc.GetLine(0))
+
+	model.GetEditor().GetCursor().SetBufferPos(ast.BufferPos{Line: 0, Col: 4})
+	_, ok = model.RunCommand("reject-change")
+	require.True(t, ok)
+	assert.Equal(t, "A red fox", doc.GetLine(0))
+
+	_, ok = model.RunCommand("accept-all-changes")
+	require.True(t, ok)
+	assert.Equal(t, "A red fox\nJumps.", doc.GetText())
+	assert.Contains(t, model.View(), "No suggested changes")
+}
+
+func TestSuggest_ResolvingCommentsWithoutSuggesting(t *testing.T) {
+	plugin.ResetRegistry()
+	require.NoError(t, plugins.InitializePlugins())
+
+	model := tui.New()
+	testutils.LoadContentIntoModel(model, "Fix it{>>Why?<<} {--now--}")
+	testutils.SetModelSize(model, 100, 20)
+	doc := model.GetEditor().GetDocument()
+
+	_, ok := model.RunCommand("changes-panel")
+	require.True(t, ok)
+	assert.Contains(t, model.View(), "Changes  +0 −1 »1")
+
+	model.GetEditor().GetCursor().SetBufferPos(ast.BufferPos{Line: 0, Col: 8})
+	_, ok = model.RunCommand("accept-change")
+	require.True(t, ok)
+	assert.Equal(t, "Fix it {--now--}", doc.GetLine(0), "Resolving a comment drops it")
+
+	model.GetEditor().GetCursor().SetBufferPos(ast.BufferPos{Line: 0, Col: 7})
+	_, ok = model.RunCommand("reject-change")
+	require.True(t, ok)
+	assert.Equal(t, "Fix it now", doc.GetLine(0))
+}
@@ -0,0 +1,72 @@
+package integration
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/ofri/mde/internal/plugins"
+	"github.com/ofri/mde/internal/tui"
+	"github.com/ofri/mde/pkg/ast"
+	"github.com/ofri/mde/pkg/plugin"
+	"github.com/ofri/mde/test/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTableEditing_Keys(t *testing.T) {
+	plugin.ResetRegistry()
+	require.NoError(t, plugins.InitializePlugins())
+
+	model := tui.New()
+	model.NewBuffer("|a|b|\n|-|-|", "table.md")
+	testutils.SetModelSize(model, 80, 10)
+	model.GetEditor().GetCursor().SetBufferPos(ast.BufferPos{Line: 0, Col: 2})
+
+	model.Update(tea.KeyPressMsg(tea.Key{Code: tea.KeyEnter}))
+	typeText(model, "one")
+	model.Update(tea.KeyPressMsg(tea.Key{Code: tea.KeyTab}))
+	typeText(model, "two")
+	model.Update(tea.KeyPressMsg(tea.Key{Code: tea.KeyTab, Mod: tea.ModShift}))
+
+	assert.Equal(t, "| a   | b   |\n| --- | --- |\n| one | two |", model.GetEditor().GetDocument().GetText())
+	assert.Equal(t, ast.BufferPos{Line: 2, Col: 5}, model.GetEditor().GetCursor().GetBufferPos())
+
+	_, ok := model.RunCommand("table-align-right")
+	require.True(t, ok)
+	assert.Equal(t, "|   a | b   |\n| --: | --- |\n| one | two |", model.GetEditor().GetDocument().GetText())
+}
+
+func TestTableEditing_TabOutsideTable(t *testing.T) {
+	plugin.ResetRegistry()
+	require.NoError(t, plugins.InitializePlugins())
+
+	model := tui.New()
+	model.NewBuffer("a | b", "table.md")
+	testutils.SetModelSize(model, 80, 10)
+
+	model.Update(tea.KeyPressMsg(tea.Key{Code: tea.KeyTab}))
+	assert.Equal(t, "\ta | b", model.GetEditor().GetDocument().GetText())
+}
+
+func TestPasteAsTable(t *testing.T) {
+	plugin.ResetRegistry()
+	require.NoError(t, plugins.InitializePlugins())
+
+	model := tui.New()
+	model.NewBuffer("before", "table.md")
+	testutils.SetModelSize(model, 80, 10)
+	model.GetEditor().GetCursor().SetBufferPos(ast.BufferPos{Col: 6})
+
+	model.Update(tea.PasteMsg("a,b\n1,2"))
+	assert.Contains(t, testutils.StripAnsiEscapes(model.View()), "Paste as a table? (y/n)")
+	model.Update(tea.KeyPressMsg(tea.Key{Code: 'y', Text: "y"}))
+	assert.Equal(t, "before\n| a   | b   |\n| --- | --- |\n| 1   | 2   |", model.GetEditor().GetDocument().GetText())
+
+	model.Update(tea.PasteMsg("\nx,y\n3,4"))
+	model.Update(tea.KeyPressMsg(tea.Key{Code: 'n', Text: "n"}))
+	assert.Equal(t, "before\n| a   | b   |\n| --- | --- |\n| 1   | 2   |\nx,y\n3,4", model.GetEditor().GetDocument().GetText(),
+		"n pastes the text as it was")
+
+	model.Update(tea.PasteMsg(" plain text"))
+	assert.Equal(t, "before\n| a   | b   |\n| --- | --- |\n| 1   | 2   |\nx,y\n3,4 plain text", model.GetEditor().GetDocument().GetText())
+}
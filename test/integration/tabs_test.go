@@ -0,0 +1,46 @@
+package integration
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/ofri/mde/internal/plugins"
+	"github.com/ofri/mde/internal/tui"
+	"github.com/ofri/mde/pkg/ast"
+	"github.com/ofri/mde/pkg/plugin"
+	"github.com/ofri/mde/test/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTabs_CursorDrawnAfterTab(t *testing.T) {
+	plugin.ResetRegistry()
+	require.NoError(t, plugins.InitializePlugins())
+
+	model := tui.New()
+	model.NewBuffer("\tx = 1\nplain", "tabs.md")
+	testutils.SetModelSize(model, 80, 10)
+	model.GetEditor().GetCursor().SetBufferPos(ast.BufferPos{Line: 0, Col: 1})
+
+	first := strings.Split(testutils.StripAnsiEscapes(model.View()), "\n")[0]
+	assert.True(t, strings.HasPrefix(first, " 1│     █ = 1"), "Cursor covers the x after the tab: %q", first)
+}
+
+func TestTabs_ClickAfterTab(t *testing.T) {
+	plugin.ResetRegistry()
+	require.NoError(t, plugins.InitializePlugins())
+
+	model := tui.New()
+	model.NewBuffer("\tx = 1\nplain", "tabs.md")
+	testutils.SetModelSize(model, 80, 10)
+	width := model.GetEditor().GetLineNumberWidth()
+
+	// "x" is drawn four cells into the text
+	model.Update(tea.MouseClickMsg(tea.Mouse{X: width + 4, Y: 0, Button: tea.MouseLeft}))
+	assert.Equal(t, ast.BufferPos{Line: 0, Col: 1}, model.GetEditor().GetCursor().GetBufferPos())
+
+	// Inside the tab's spaces selects the tab
+	model.Update(tea.MouseClickMsg(tea.Mouse{X: width + 2, Y: 0, Button: tea.MouseLeft}))
+	assert.Equal(t, ast.BufferPos{Line: 0, Col: 0}, model.GetEditor().GetCursor().GetBufferPos())
+}
@@ -0,0 +1,118 @@
+package integration
+
+import (
+	"image/color"
+	"path/filepath"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/ofri/mde/internal/config"
+	"github.com/ofri/mde/internal/plugins"
+	"github.com/ofri/mde/internal/theme"
+	"github.com/ofri/mde/internal/tui"
+	"github.com/ofri/mde/pkg/plugin"
+	"github.com/ofri/mde/test/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTheme_ExportCommand(t *testing.T) {
+	plugin.ResetRegistry()
+	require.NoError(t, plugins.InitializePlugins())
+
+	cfg := config.Default()
+	cfg.ThemesDir = t.TempDir()
+	model := tui.New()
+	model.SetConfig(cfg)
+	model.NewBuffer("# Title", "theme.md")
+	testutils.SetModelSize(model, 80, 10)
+
+	_, ok := model.RunCommand("export-theme")
+	require.True(t, ok)
+	path := filepath.Join(cfg.ThemesDir, "default.toml")
+	assert.Contains(t, testutils.StripAnsiEscapes(model.View()), "Theme saved to")
+
+	exported, err := theme.Load(path)
+	require.NoError(t, err)
+	assert.Equal(t, theme.Active().Styles, exported.Styles)
+
+	model.RunCommand("export-theme")
+	assert.Contains(t, testutils.StripAnsiEscapes(model.View()), "Error:", "An edited theme is not overwritten")
+}
+
+func TestTheme_UnknownThemeIsReported(t *testing.T) {
+	plugin.ResetRegistry()
+	require.NoError(t, plugins.InitializePlugins())
+
+	cfg := config.Default()
+	cfg.Theme = "no-such-theme"
+	model := tui.New()
+	model.SetConfig(cfg)
+	model.NewBuffer("text", "theme.md")
+	testutils.SetModelSize(model, 80, 10)
+
+	assert.Contains(t, testutils.StripAnsiEscapes(model.View()), `unknown theme "no-such-theme"`)
+	assert.Equal(t, theme.Default, theme.Active().Name)
+}
+
+func TestTheme_FollowsTerminalBackground(t *testing.T) {
+	plugin.ResetRegistry()
+	require.NoError(t, plugins.InitializePlugins())
+	t.Cleanup(func() { theme.SetActive(theme.Default) })
+
+	model := tui.New()
+	model.SetConfig(config.Default())
+	model.NewBuffer("# Title", "theme.md")
+	testutils.SetModelSize(model, 80, 10)
+	assert.Equal(t, theme.Default, theme.Active().Name, "Dark until the terminal answers")
+
+	t.Cleanup(func() { theme.SetBackground(nil) })
+	model.Update(tea.BackgroundColorMsg{Color: color.White})
+	assert.Equal(t, theme.Light, theme.Active().Name)
+	model.Update(tea.BackgroundColorMsg{Color: color.Black})
+	assert.Equal(t, theme.Default, theme.Active().Name)
+
+	_, ok := model.RunCommand("theme-light")
+	require.True(t, ok)
+	model.Update(tea.BackgroundColorMsg{Color: color.Black})
+	assert.Equal(t, theme.Light, theme.Active().Name, "An explicit choice overrides the background")
+
+	cmd, _ := model.RunCommand("theme-auto")
+	assert.NotNil(t, cmd, "The background is queried again")
+	assert.Equal(t, theme.Default, theme.Active().Name)
+}
+
+func TestTheme_PickerPreviewsLive(t *testing.T) {
+	plugin.ResetRegistry()
+	require.NoError(t, plugins.InitializePlugins())
+	t.Cleanup(func() { theme.SetActive(theme.Default) })
+
+	cfg := config.Default()
+	cfg.Theme = theme.Default
+	model := tui.New()
+	model.SetConfig(cfg)
+	model.NewBuffer("# Title", "theme.md")
+	testutils.SetModelSize(model, 120, 10)
+
+	model.RunCommand("theme")
+	assert.Contains(t, testutils.StripAnsiEscapes(model.View()), "high-contrast")
+	typeKeys(model, "high")
+	model.Update(tea.KeyPressMsg(tea.Key{Code: tea.KeyTab}))
+	assert.Equal(t, theme.HighContrast, theme.Active().Name, "Completing a name previews it")
+
+	pressEsc(model)
+	assert.Equal(t, theme.Default, theme.Active().Name, "Escape goes back")
+
+	model.RunCommand("theme")
+	typeKeys(model, "deuteranopia")
+	assert.Equal(t, theme.Deuteranopia, theme.Active().Name)
+	model.Update(tea.KeyPressMsg(tea.Key{Code: tea.KeyEnter}))
+	assert.Equal(t, theme.Deuteranopia, theme.Active().Name)
+	assert.Equal(t, theme.Deuteranopia, cfg.Theme)
+
+	model.RunCommand("theme")
+	typeKeys(model, "nope")
+	model.Update(tea.KeyPressMsg(tea.Key{Code: tea.KeyEnter}))
+	assert.Contains(t, testutils.StripAnsiEscapes(model.View()), `unknown theme "nope"`)
+	assert.Equal(t, theme.Deuteranopia, theme.Active().Name, "An unknown name keeps the theme")
+}
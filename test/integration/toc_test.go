@@ -0,0 +1,51 @@
+package integration
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/ofri/mde/internal/config"
+	"github.com/ofri/mde/internal/plugins"
+	"github.com/ofri/mde/internal/tui"
+	"github.com/ofri/mde/pkg/plugin"
+	"github.com/ofri/mde/test/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTOC_RefreshedOnSave(t *testing.T) {
+	plugin.ResetRegistry()
+	require.NoError(t, plugins.InitializePlugins())
+
+	path := filepath.Join(t.TempDir(), "toc.md")
+	cfg := config.Default()
+	cfg.TOCOnSave = true
+	model := tui.New()
+	model.SetConfig(cfg)
+	model.NewBuffer("<!-- toc -->\n- stale\n<!-- /toc -->\n# Title", path)
+	testutils.SetModelSize(model, 80, 10)
+
+	_, cmd := model.Update(tea.KeyPressMsg(tea.Key{Code: 's', Mod: tea.ModCtrl}))
+	require.NotNil(t, cmd)
+	cmd()
+
+	saved, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "<!-- toc -->\n- [Title](#title)\n<!-- /toc -->\n# Title", string(saved))
+}
+
+func TestTOC_InsertedByCommand(t *testing.T) {
+	plugin.ResetRegistry()
+	require.NoError(t, plugins.InitializePlugins())
+
+	model := tui.New()
+	model.NewBuffer("# Title\n## Part", "toc.md")
+	testutils.SetModelSize(model, 80, 10)
+
+	_, ok := model.RunCommand("toc")
+	require.True(t, ok)
+	assert.Equal(t, "<!-- toc -->\n- [Title](#title)\n  - [Part](#part)\n<!-- /toc -->\n# Title\n## Part",
+		model.GetEditor().GetDocument().GetText())
+}
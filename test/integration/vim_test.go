@@ -0,0 +1,187 @@
+package integration
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/ofri/mde/internal/config"
+	"github.com/ofri/mde/internal/plugins"
+	"github.com/ofri/mde/internal/tui"
+	"github.com/ofri/mde/pkg/ast"
+	"github.com/ofri/mde/pkg/plugin"
+	"github.com/ofri/mde/test/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func vimModel(t *testing.T, content string) *tui.Model {
+	plugin.ResetRegistry()
+	require.NoError(t, plugins.InitializePlugins())
+
+	cfg := config.Default()
+	cfg.Keymap = "vim"
+	model := tui.New()
+	model.SetConfig(cfg)
+	testutils.LoadContentIntoModel(model, content)
+	testutils.SetModelSize(model, 80, 20)
+	model.GetEditor().GetCursor().SetBufferPos(ast.BufferPos{Line: 0, Col: 0})
+	return model
+}
+
+// typeKeys sends each rune of keys as a key press.
+func typeKeys(model *tui.Model, keys string) {
+	for _, r := range keys {
+		model.Update(tea.KeyPressMsg(tea.Key{Code: r, Text: string(r)}))
+	}
+}
+
+func pressEsc(model *tui.Model) {
+	model.Update(tea.KeyPressMsg(tea.Key{Code: tea.KeyEscape}))
+}
+
+func TestVim_MotionsAndCounts(t *testing.T) {
+	model := vimModel(t, "one two three\nline 2\nline 3\nline 4")
+	cursor := func() ast.BufferPos { return model.GetEditor().GetCursor().GetBufferPos() }
+
+	typeKeys(model, "2w")
+	assert.Equal(t, ast.BufferPos{Line: 0, Col: 8}, cursor())
+	typeKeys(model, "jh")
+	assert.Equal(t, ast.BufferPos{Line: 1, Col: 5}, cursor())
+	typeKeys(model, "0")
+	assert.Equal(t, 0, cursor().Col)
+	typeKeys(model, "G")
+	assert.Equal(t, 3, cursor().Line)
+	typeKeys(model, "2gg")
+	assert.Equal(t, 1, cursor().Line)
+	typeKeys(model, "$")
+	assert.Equal(t, 6, cursor().Col)
+
+	assert.Equal(t, "one two three\nline 2\nline 3\nline 4", model.GetEditor().GetDocument().GetText(),
+		"Normal mode keys never insert text")
+}
+
+func TestVim_InsertMode(t *testing.T) {
+	model := vimModel(t, "world")
+
+	typeKeys(model, "ihello ")
+	assert.Contains(t, model.View(), "-- INSERT --")
+	pressEsc(model)
+	assert.NotContains(t, model.View(), "-- INSERT --")
+	assert.Equal(t, "hello world", model.GetEditor().GetDocument().GetText())
+
+	typeKeys(model, "A!")
+	pressEsc(model)
+	typeKeys(model, "onext")
+	pressEsc(model)
+	assert.Equal(t, "hello world!\nnext", model.GetEditor().GetDocument().GetText())
+}
+
+func TestVim_DeleteYankPut(t *testing.T) {
+	model := vimModel(t, "a\nb\nc\nd")
+	doc := model.GetEditor().GetDocument()
+
+	typeKeys(model, "2dd")
+	assert.Equal(t, "c\nd", doc.GetText())
+	typeKeys(model, "p")
+	assert.Equal(t, "c\na\nb\nd", doc.GetText(), "p puts deleted lines below")
+
+	typeKeys(model, "Gyyggp")
+	assert.Equal(t, "c\nd\na\nb\nd", doc.GetText())
+
+	typeKeys(model, "Gdd")
+	assert.Equal(t, "c\nd\na\nb", doc.GetText(), "Deleting the last line drops its line break")
+
+	typeKeys(model, "ggxdw")
+	assert.Equal(t, "d\na\nb", doc.GetText(), "dw on an empty line joins it with the next")
+}
+
+func TestVim_ChangeLines(t *testing.T) {
+	model := vimModel(t, "a\nb")
+	doc := model.GetEditor().GetDocument()
+
+	typeKeys(model, "GccX")
+	pressEsc(model)
+	assert.Equal(t, "a\nX", doc.GetText(), "cc on the last line changes that line")
+
+	typeKeys(model, "gg2ccY")
+	pressEsc(model)
+	assert.Equal(t, "Y", doc.GetText())
+}
+
+func TestVim_DeleteCharStaysOnLine(t *testing.T) {
+	model := vimModel(t, "one\n\ntwo")
+	doc := model.GetEditor().GetDocument()
+
+	typeKeys(model, "jx")
+	assert.Equal(t, "one\n\ntwo", doc.GetText(), "x on an empty line deletes nothing")
+
+	model = vimModel(t, "ab\ncd")
+	doc = model.GetEditor().GetDocument()
+	typeKeys(model, "5x")
+	assert.Equal(t, "\ncd", doc.GetText(), "A count stops at the end of the line")
+}
+
+func TestVim_OperatorMotions(t *testing.T) {
+	model := vimModel(t, "keep drop this one")
+
+	typeKeys(model, "wdw")
+	assert.Equal(t, "keep this one", model.GetEditor().GetDocument().GetText())
+
+	typeKeys(model, "cwthat")
+	pressEsc(model)
+	assert.Equal(t, "keep that one", model.GetEditor().GetDocument().GetText(), "cw keeps the space after the word")
+
+	typeKeys(model, "0d$")
+	assert.Equal(t, "", model.GetEditor().GetDocument().GetText())
+}
+
+func TestVim_MotionsAndOperatorsAreCommands(t *testing.T) {
+	model := vimModel(t, "keep drop this one")
+	doc := model.GetEditor().GetDocument()
+
+	_, ok := model.RunCommand("word-right")
+	require.True(t, ok)
+	assert.Equal(t, 5, model.GetEditor().GetCursor().GetBufferPos().Col)
+	_, ok = model.RunCommand("vim-delete")
+	require.True(t, ok)
+	typeKeys(model, "w")
+	assert.Equal(t, "keep this one", doc.GetText(), "The operator command waits for its motion")
+
+	// Without the vim keymap the operators act on the selection
+	_, ok = model.RunCommand("vim-mode")
+	require.True(t, ok)
+	model.RunCommand("line-start")
+	model.GetEditor().SelectWith(func() { model.RunCommand("word-right") })
+	model.RunCommand("vim-delete")
+	assert.Equal(t, "this one", doc.GetText())
+	model.RunCommand("vim-yank")
+	assert.Contains(t, model.View(), "Nothing selected")
+}
+
+func TestVim_VisualMode(t *testing.T) {
+	model := vimModel(t, "select me please\nsecond")
+
+	typeKeys(model, "wvey")
+	assert.NotContains(t, model.View(), "-- VISUAL --")
+	assert.False(t, model.GetEditor().GetCursor().HasSelection())
+
+	typeKeys(model, "vlld")
+	assert.Equal(t, "select  please\nsecond", model.GetEditor().GetDocument().GetText())
+
+	typeKeys(model, "Vjd")
+	assert.Equal(t, "", model.GetEditor().GetDocument().GetText())
+}
+
+func TestVim_SearchAndCommandPrompt(t *testing.T) {
+	model := vimModel(t, "alpha\nbeta target\ngamma target")
+
+	typeKeys(model, "/target")
+	model.Update(tea.KeyPressMsg(tea.Key{Code: tea.KeyEnter}))
+	assert.Equal(t, ast.BufferPos{Line: 1, Col: 5}, model.GetEditor().GetCursor().GetBufferPos())
+
+	typeKeys(model, "n")
+	assert.Equal(t, ast.BufferPos{Line: 2, Col: 6}, model.GetEditor().GetCursor().GetBufferPos())
+
+	typeKeys(model, ":")
+	assert.Contains(t, model.View(), "Command:", "Colon opens the command prompt")
+}
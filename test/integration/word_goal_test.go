@@ -0,0 +1,63 @@
+package integration
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/charmbracelet/x/ansi"
+	"github.com/ofri/mde/internal/config"
+	"github.com/ofri/mde/internal/plugins"
+	"github.com/ofri/mde/internal/tui"
+	"github.com/ofri/mde/pkg/ast"
+	"github.com/ofri/mde/pkg/plugin"
+	"github.com/ofri/mde/test/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWordGoal_Document(t *testing.T) {
+	plugin.ResetRegistry()
+	require.NoError(t, plugins.InitializePlugins())
+
+	cfg := config.Default()
+	cfg.WordGoal = 4
+	model := tui.New()
+	model.SetConfig(cfg)
+	model.NewBuffer("one two", "goal.md")
+	testutils.SetModelSize(model, 100, 10)
+	model.GetEditor().GetCursor().SetBufferPos(ast.BufferPos{Col: 7})
+
+	typeText(model, " three")
+	assert.Contains(t, ansi.Strip(model.View()), "3/4 words ███████░░░")
+	assert.NotContains(t, model.View(), "Goal reached")
+
+	typeText(model, " four")
+	assert.Contains(t, ansi.Strip(model.View()), "Goal reached: 4 words!")
+	assert.Contains(t, ansi.Strip(model.View()), "4/4 words ██████████")
+}
+
+func TestWordGoal_SessionPersists(t *testing.T) {
+	plugin.ResetRegistry()
+	require.NoError(t, plugins.InitializePlugins())
+	t.Setenv("MDE_SESSION_FILE", filepath.Join(t.TempDir(), "sessions.json"))
+
+	cfg := config.Default()
+	cfg.WordGoal = 10
+	cfg.WordGoalScope = "session"
+	path := filepath.Join(t.TempDir(), "draft.md")
+
+	model := tui.New()
+	model.SetConfig(cfg)
+	model.NewBuffer("already here", path)
+	testutils.SetModelSize(model, 100, 10)
+	model.GetEditor().GetCursor().SetBufferPos(ast.BufferPos{Col: 12})
+	typeText(model, " and more")
+	assert.Contains(t, ansi.Strip(model.View()), "2/10 words")
+
+	// A later session the same day counts from the first one's start
+	model = tui.New()
+	model.SetConfig(cfg)
+	model.NewBuffer("already here and more", path)
+	testutils.SetModelSize(model, 100, 10)
+	assert.Contains(t, ansi.Strip(model.View()), "2/10 words")
+}
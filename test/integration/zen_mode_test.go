@@ -0,0 +1,48 @@
+package integration
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/ofri/mde/internal/plugins"
+	"github.com/ofri/mde/internal/tui"
+	"github.com/ofri/mde/pkg/ast"
+	"github.com/ofri/mde/pkg/plugin"
+	"github.com/ofri/mde/test/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestZenMode_CentersColumnAndHidesChrome(t *testing.T) {
+	plugin.ResetRegistry()
+	require.NoError(t, plugins.InitializePlugins())
+
+	model := tui.New()
+	testutils.LoadContentIntoModel(model, "First sentence. Second one.\n\nNext paragraph.")
+	testutils.SetModelSize(model, 120, 10)
+	require.True(t, model.GetEditor().ShowLineNumbers())
+	require.Equal(t, 8, model.GetContentHeight())
+
+	model.Update(tea.KeyPressMsg(tea.Key{Code: 'z', Mod: tea.ModAlt}))
+	require.True(t, model.IsZenMode())
+
+	assert.False(t, model.GetEditor().ShowLineNumbers(), "Zen mode hides line numbers")
+	assert.Equal(t, 9, model.GetContentHeight(), "Only the status bar remains")
+	assert.Equal(t, 80, model.GetEditor().GetViewport().GetWidth(), "Text column uses zen_width")
+
+	view := testutils.StripAnsiEscapes(model.View())
+	lines := strings.Split(view, "\n")
+	assert.True(t, strings.HasPrefix(lines[2], strings.Repeat(" ", 20)+"Next paragraph."), "Column is centered: %q", lines[2])
+	assert.NotContains(t, view, "^O Open", "Help bar is hidden")
+
+	// Clicks land in the centered column
+	model.Update(tea.MouseClickMsg(tea.Mouse{X: 25, Y: 2, Button: tea.MouseLeft}))
+	assert.Equal(t, ast.BufferPos{Line: 2, Col: 5}, model.GetEditor().GetCursor().GetBufferPos())
+
+	model.Update(tea.KeyPressMsg(tea.Key{Code: 'z', Mod: tea.ModAlt}))
+	assert.False(t, model.IsZenMode())
+	assert.True(t, model.GetEditor().ShowLineNumbers(), "Line numbers come back")
+	assert.Equal(t, 120, model.GetEditor().GetViewport().GetWidth())
+	assert.Contains(t, model.View(), "^O Open")
+}
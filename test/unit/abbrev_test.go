@@ -0,0 +1,58 @@
+package unit
+
+import (
+	"testing"
+
+	"github.com/ofri/mde/internal/abbrev"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAbbrev_Find(t *testing.T) {
+	e := abbrev.New(map[string]string{"btw": "by the way", "+-": ""}, true)
+
+	tests := []struct {
+		name      string
+		line      string
+		expansion string
+		start     int
+		end       int
+	}{
+		{"symbol expands at once", "a ->", "→", 2, 4},
+		{"longest symbol wins", "a <=>", "⇔", 2, 5},
+		{"word waits for a delimiter", "so btw ", "by the way", 3, 6},
+		{"word before punctuation", "btw,", "by the way", 0, 3},
+		{"word must be whole", "subtw ", "", 0, 0},
+		{"unfinished word", "so btw", "", 0, 0},
+		{"backslash escapes", `a \->`, "", 0, 0},
+		{"double backslash does not", `a \\->`, "→", 4, 6},
+		{"not in a code span", "use `a ->", "", 0, 0},
+		{"after a code span", "`x` ->", "→", 4, 6},
+		{"HTML comment close", "<!-- x -->", "", 0, 0},
+		{"disabled built-in", "+-", "", 0, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			match, ok := e.Find(tt.line, len([]rune(tt.line)))
+			if tt.expansion == "" {
+				assert.False(t, ok, "unexpected match %+v", match)
+				return
+			}
+			assert.True(t, ok)
+			assert.Equal(t, tt.expansion, match.Expansion)
+			assert.Equal(t, tt.start, match.Start)
+			assert.Equal(t, tt.end, match.End)
+		})
+	}
+}
+
+func TestAbbrev_FindWordAndBuiltins(t *testing.T) {
+	e := abbrev.New(map[string]string{"btw": "by the way"}, false)
+
+	match, ok := e.FindWord("é btw", 5)
+	assert.True(t, ok)
+	assert.Equal(t, 2, match.Start)
+
+	_, ok = e.Find("a ->", 4)
+	assert.False(t, ok, "built-ins are off")
+	assert.True(t, abbrev.New(nil, false).Empty())
+}
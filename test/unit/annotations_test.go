@@ -0,0 +1,62 @@
+package unit
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ofri/mde/internal/annotations"
+	"github.com/ofri/mde/pkg/ast"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAnnotationsSaveAndLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "notes.md")
+	comments, err := annotations.Load(path)
+	require.NoError(t, err)
+	assert.Empty(t, comments, "A document without a sidecar file has no comments")
+
+	doc := ast.NewDocument("# Notes\nThe quick brown fox")
+	created := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	comment := annotations.New(doc, ast.Range{Start: ast.BufferPos{Line: 1, Col: 4}, End: ast.BufferPos{Line: 1, Col: 9}}, "Which one?", "Ann", created)
+	assert.Equal(t, annotations.Comment{Line: 2, Col: 5, Quote: "quick", Text: "Which one?", Author: "Ann", Created: created}, comment)
+
+	require.NoError(t, annotations.Save(path, []annotations.Comment{comment}))
+	assert.FileExists(t, path+".comments.json")
+	comments, err = annotations.Load(path)
+	require.NoError(t, err)
+	assert.Equal(t, []annotations.Comment{comment}, comments)
+
+	require.NoError(t, annotations.Save(path, nil))
+	_, err = os.Stat(path + ".comments.json")
+	assert.True(t, os.IsNotExist(err), "The sidecar file goes with the last comment")
+}
+
+func TestAnnotationsLocateFollowsEdits(t *testing.T) {
+	original := ast.NewDocument("Intro\nThe quick brown fox\njumps over\nthe dog")
+	single := annotations.New(original, ast.Range{Start: ast.BufferPos{Line: 1, Col: 4}, End: ast.BufferPos{Line: 1, Col: 9}}, "", "", time.Time{})
+	multi := annotations.New(original, ast.Range{Start: ast.BufferPos{Line: 1, Col: 16}, End: ast.BufferPos{Line: 2, Col: 5}}, "", "", time.Time{})
+
+	r, ok := single.Locate(original)
+	require.True(t, ok)
+	assert.Equal(t, ast.Range{Start: ast.BufferPos{Line: 1, Col: 4}, End: ast.BufferPos{Line: 1, Col: 9}}, r)
+
+	edited := ast.NewDocument("Title\n\nIntro\nA quick fox. The very quick brown fox\njumps over\nthe dog")
+	r, ok = single.Locate(edited)
+	require.True(t, ok)
+	assert.Equal(t, ast.Range{Start: ast.BufferPos{Line: 3, Col: 2}, End: ast.BufferPos{Line: 3, Col: 7}}, r,
+		"The occurrence nearest where the quote was wins")
+	r, ok = multi.Locate(edited)
+	require.True(t, ok)
+	assert.Equal(t, ast.Range{Start: ast.BufferPos{Line: 3, Col: 34}, End: ast.BufferPos{Line: 4, Col: 5}}, r)
+
+	single.MoveTo(r)
+	assert.Equal(t, 4, single.Line)
+	assert.Equal(t, 35, single.Col)
+
+	r, ok = multi.Locate(ast.NewDocument("Intro\nThe quick brown cat\njumps over"))
+	assert.False(t, ok, "A comment whose text is gone is orphaned")
+	assert.Equal(t, ast.Range{Start: ast.BufferPos{Line: 1}, End: ast.BufferPos{Line: 1}}, r)
+}
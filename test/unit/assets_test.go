@@ -0,0 +1,72 @@
+package unit
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ofri/mde/internal/assets"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAssetsReferences(t *testing.T) {
+	markdown := "![shot](assets/a.png \"title\") and [spec](<assets/my spec.pdf>)\n" +
+		"![logo][logo]\n" +
+		"[logo]: assets/logo.svg\n" +
+		`<img src="assets/b.png" srcset="assets/b@2x.png 2x, assets/b@3x.png 3x">` + "\n" +
+		"[site](https://example.com/c.png)\n"
+	assert.Equal(t, []string{
+		"assets/a.png", "assets/my spec.pdf", "https://example.com/c.png",
+		"assets/logo.svg",
+		"assets/b.png", "assets/b@2x.png", "assets/b@3x.png",
+	}, assets.References(markdown))
+}
+
+func TestAssetsScan(t *testing.T) {
+	root := t.TempDir()
+	write := func(rel, content string) {
+		path := filepath.Join(root, filepath.FromSlash(rel))
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	}
+	write("notes.md", "![](assets/used.png)\n")
+	write("sub/other.md", "![](../assets/shared%20one.png)\n")
+	write(".drafts/old.md", "![](../assets/only-in-hidden.png)\n")
+	write("assets/used.png", "1")
+	write("assets/shared one.png", "22")
+	write("assets/only-in-hidden.png", "333")
+	write("assets/gone.png", "4444")
+	write("assets/nested/orphan.jpg", "55555")
+	write("assets/readme.md", "not an asset")
+	write("assets/.unused/earlier.png", "moved before")
+
+	// The buffer's unsaved text is read instead of the file
+	buffers := map[string]string{filepath.Join(root, "notes.md"): "![](assets/used.png) ![](assets/gone.png)\n"}
+	report, err := assets.Scan(root, filepath.Join(root, "assets"), buffers)
+	require.NoError(t, err)
+	assert.Equal(t, 3, report.Files, "Hidden directories are skipped")
+	assert.Equal(t, 5, report.Assets)
+	assert.Equal(t, []string{
+		filepath.Join(root, "assets", "nested", "orphan.jpg"),
+		filepath.Join(root, "assets", "only-in-hidden.png"),
+	}, report.Unused)
+	assert.Equal(t, int64(8), report.Size)
+
+	moved, err := assets.Move(report.Unused, report.Dir, filepath.Join(report.Dir, ".unused"))
+	require.NoError(t, err)
+	assert.FileExists(t, filepath.Join(root, "assets", ".unused", "nested", "orphan.jpg"))
+	assert.Len(t, moved, 2)
+	assert.NoFileExists(t, filepath.Join(root, "assets", "only-in-hidden.png"))
+
+	report, err = assets.Scan(root, filepath.Join(root, "assets"), buffers)
+	require.NoError(t, err)
+	assert.Empty(t, report.Unused, "Moved assets are not listed again")
+}
+
+func TestAssetsScanWithoutAssetsDir(t *testing.T) {
+	root := t.TempDir()
+	report, err := assets.Scan(root, filepath.Join(root, "assets"), nil)
+	require.NoError(t, err)
+	assert.Zero(t, report.Assets)
+}
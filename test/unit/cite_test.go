@@ -0,0 +1,129 @@
+package unit
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ofri/mde/internal/cite"
+	"github.com/ofri/mde/internal/plugins/parsers"
+	"github.com/ofri/mde/pkg/ast"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFindCitations(t *testing.T) {
+	line := "As shown [see @smith2020, p. 3; -@doe:99] and [@jones]."
+	citations := cite.FindCitations(line)
+	require.Len(t, citations, 2)
+
+	assert.Equal(t, "[see @smith2020, p. 3; -@doe:99]", line[citations[0].Start:citations[0].End])
+	require.Len(t, citations[0].Keys, 2)
+	assert.Equal(t, "smith2020", citations[0].Keys[0].Name)
+	assert.Equal(t, "@smith2020", line[citations[0].Keys[0].Start:citations[0].Keys[0].End])
+	assert.Equal(t, "doe:99", citations[0].Keys[1].Name, "Internal punctuation is part of a key")
+	assert.Equal(t, "jones", citations[1].Keys[0].Name, "Trailing punctuation is not")
+
+	assert.Empty(t, cite.FindCitations("[@smith](https://example.com)"), "A link is not a citation")
+	assert.Empty(t, cite.FindCitations("[mail a@example.com]"), "An email address is not a key")
+	assert.Empty(t, cite.FindCitations("@smith2020 in text"))
+}
+
+func TestCitationKeyAt(t *testing.T) {
+	line := "Ünï [@a; @bee]"
+	key, ok := cite.KeyAt(line, 10)
+	require.True(t, ok, "Columns count runes")
+	assert.Equal(t, "bee", key.Name)
+	_, ok = cite.KeyAt(line, 8)
+	assert.False(t, ok)
+}
+
+func TestCitationPartialAt(t *testing.T) {
+	partial, ok := cite.PartialAt("see [@smi", 9)
+	require.True(t, ok)
+	assert.Equal(t, cite.Partial{Key: "smi"}, partial)
+
+	partial, ok = cite.PartialAt("[@a; @jo] more", 8)
+	require.True(t, ok)
+	assert.Equal(t, cite.Partial{Key: "jo", Closed: true}, partial)
+
+	partial, ok = cite.PartialAt("[@", 2)
+	require.True(t, ok)
+	assert.Equal(t, "", partial.Key)
+
+	_, ok = cite.PartialAt("mail a@exa", 10)
+	assert.False(t, ok, "Outside brackets")
+	_, ok = cite.PartialAt("[a@exa", 6)
+	assert.False(t, ok, "An email address")
+	_, ok = cite.PartialAt("[@a] @b", 7)
+	assert.False(t, ok, "After the citation closed")
+}
+
+func TestParseBibTeX(t *testing.T) {
+	entries := cite.ParseBibTeX(`
+@string{acm = "ACM"}
+@comment{ignored, title = {No}}
+
+@Article{smith2020,
+  author  = {Smith, Jane and John {van} Doe and {World Health Organization}},
+  title   = {A {Study} of G{\"o}del \& Co},
+  journal = "Journal of " # acm,
+  year    = 2020,
+}
+
+@book(jones99, editor = "Ann Jones", title = {Books}, publisher = {Press}, date = {1999-05-01})
+`)
+	require.Len(t, entries, 2)
+
+	assert.Equal(t, cite.Entry{
+		Key:       "smith2020",
+		Type:      "article",
+		Title:     "A Study of Godel & Co",
+		Authors:   []string{"Smith", "van Doe", "World Health Organization"},
+		Year:      "2020",
+		Container: "Journal of acm",
+	}, entries[0])
+	assert.Equal(t, "Smith et al. (2020). A Study of Godel & Co. Journal of acm", entries[0].Summary())
+
+	assert.Equal(t, "jones99", entries[1].Key)
+	assert.Equal(t, []string{"Jones"}, entries[1].Authors, "Editors stand in for missing authors")
+	assert.Equal(t, "1999", entries[1].Year)
+	assert.Equal(t, "Jones (1999). Books. Press", entries[1].Summary())
+}
+
+func TestLoadCSLJSON(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "refs.json")
+	require.NoError(t, os.WriteFile(path, []byte(`[
+  {"id": "lee2018", "type": "article-journal", "title": "Trees",
+   "author": [{"family": "Lee", "given": "Kim"}, {"literal": "The Forest Group"}],
+   "issued": {"date-parts": [[2018, 3]]}, "container-title": "Nature"},
+  {"id": "bare", "title": "Untitled"}
+]`), 0o644))
+
+	entries, err := cite.Load(path)
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, "Lee and The Forest Group (2018). Trees. Nature", entries[0].Summary())
+	assert.Equal(t, "Untitled", entries[1].Summary())
+
+	require.NoError(t, os.WriteFile(path, []byte(`{"not": "a list"}`), 0o644))
+	_, err = cite.Load(path)
+	assert.Error(t, err)
+}
+
+func TestCitationTokens(t *testing.T) {
+	parser := parsers.NewCommonMarkParser()
+	tokens, err := parser.GetSyntaxHighlighting(context.Background(), "Ä [@smith2020]")
+	require.NoError(t, err)
+
+	var found []ast.Token
+	for _, token := range tokens {
+		if token.Kind() == ast.TokenCitation {
+			found = append(found, token)
+		}
+	}
+	require.Len(t, found, 1)
+	assert.Equal(t, 3, found[0].Start())
+	assert.Equal(t, 13, found[0].End())
+}
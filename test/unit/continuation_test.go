@@ -0,0 +1,46 @@
+package unit
+
+import (
+	"testing"
+
+	"github.com/ofri/mde/pkg/ast"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQuotePrefix(t *testing.T) {
+	assert.Equal(t, "> ", ast.QuotePrefix("> quote"))
+	assert.Equal(t, "> > ", ast.QuotePrefix("> > nested"))
+	assert.Equal(t, ">>", ast.QuotePrefix(">>tight"))
+	assert.Equal(t, "  > ", ast.QuotePrefix("  > indented"))
+	assert.Equal(t, "", ast.QuotePrefix("    > code"), "Four spaces make code, not a quote")
+	assert.Equal(t, "", ast.QuotePrefix("a > b"))
+}
+
+func TestInsertNewline_Continuation(t *testing.T) {
+	tests := []struct {
+		name   string
+		text   string
+		cursor ast.BufferPos
+		want   string
+		after  ast.BufferPos
+	}{
+		{"quote", "> quote", ast.BufferPos{Col: 7}, "> quote\n> ", ast.BufferPos{Line: 1, Col: 2}},
+		{"nested quote split", "> > ab", ast.BufferPos{Col: 5}, "> > a\n> > b", ast.BufferPos{Line: 1, Col: 4}},
+		{"empty quote line ends the quote", "> a\n> ", ast.BufferPos{Line: 1, Col: 2}, "> a\n\n", ast.BufferPos{Line: 2}},
+		{"cursor within markers", "> a", ast.BufferPos{Col: 0}, "\n> a", ast.BufferPos{Line: 1}},
+		{"indented code", "    x := 1", ast.BufferPos{Col: 10}, "    x := 1\n    ", ast.BufferPos{Line: 1, Col: 4}},
+		{"tab-indented code", "\tx", ast.BufferPos{Col: 2}, "\tx\n\t", ast.BufferPos{Line: 1, Col: 1}},
+		{"fenced code", "```\n  if x {\n```", ast.BufferPos{Line: 1, Col: 8}, "```\n  if x {\n  \n```", ast.BufferPos{Line: 2, Col: 2}},
+		{"fenced quote-like line", "```\n> x\n```", ast.BufferPos{Line: 1, Col: 3}, "```\n> x\n\n```", ast.BufferPos{Line: 2}},
+		{"paragraph", "  text", ast.BufferPos{Col: 6}, "  text\n", ast.BufferPos{Line: 1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			editor := ast.NewEditorWithContent(tt.text)
+			editor.GetCursor().SetBufferPos(tt.cursor)
+			editor.InsertNewline()
+			assert.Equal(t, tt.want, editor.GetDocument().GetText())
+			assert.Equal(t, tt.after, editor.GetCursor().GetBufferPos())
+		})
+	}
+}
@@ -0,0 +1,61 @@
+package unit
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"path/filepath"
+	"testing"
+
+	"github.com/ofri/mde/internal/control"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestControlServer(t *testing.T) {
+	var notified []string
+	path := filepath.Join(t.TempDir(), "mde.sock")
+	server, err := control.Listen(path, func(ctx context.Context, method string, params json.RawMessage) (any, error) {
+		switch method {
+		case "echo":
+			return params, nil
+		case "fail":
+			return nil, errors.New("it broke")
+		case "strict":
+			return nil, control.InvalidParams(errors.New("bad line"))
+		case "note":
+			notified = append(notified, string(params))
+			return nil, nil
+		}
+		return nil, control.ErrMethodNotFound
+	})
+	require.NoError(t, err)
+	defer server.Close()
+
+	_, err = control.Listen(path, nil)
+	assert.ErrorContains(t, err, "in use", "A live socket is not taken over")
+
+	conn, err := net.Dial("unix", path)
+	require.NoError(t, err)
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+	call := func(request string) string {
+		_, err := conn.Write([]byte(request + "\n"))
+		require.NoError(t, err)
+		line, err := reader.ReadString('\n')
+		require.NoError(t, err)
+		return line
+	}
+
+	assert.JSONEq(t, `{"jsonrpc":"2.0","id":1,"result":{"a":1}}`, call(`{"jsonrpc":"2.0","id":1,"method":"echo","params":{"a":1}}`))
+	_, err = conn.Write([]byte(`{"jsonrpc":"2.0","method":"note","params":"x"}` + "\n"))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"jsonrpc":"2.0","id":"b","error":{"code":-32000,"message":"it broke"}}`, call(`{"jsonrpc":"2.0","id":"b","method":"fail"}`))
+	assert.JSONEq(t, `{"jsonrpc":"2.0","id":3,"error":{"code":-32602,"message":"bad line"}}`, call(`{"jsonrpc":"2.0","id":3,"method":"strict"}`))
+	assert.JSONEq(t, `{"jsonrpc":"2.0","id":4,"error":{"code":-32601,"message":"method \"nope\" not found"}}`, call(`{"jsonrpc":"2.0","id":4,"method":"nope"}`))
+	assert.Contains(t, call(`{oops`), `"code":-32700`)
+	assert.Contains(t, call(`{"id":5,"method":"echo"}`), `"code":-32600`)
+	assert.Equal(t, []string{`"x"`}, notified, "Notifications run without a response")
+}
@@ -58,6 +58,14 @@ func TestBufferPos_Basic(t *testing.T) {
 		pos := ast.BufferPos{Line: 0, Col: 0}
 		assert.True(t, pos.IsValid(), "Zero position should be valid")
 	})
+
+	t.Run("ordering", func(t *testing.T) {
+		pos := ast.BufferPos{Line: 1, Col: 4}
+		assert.True(t, pos.Before(ast.BufferPos{Line: 1, Col: 5}))
+		assert.True(t, pos.Before(ast.BufferPos{Line: 2, Col: 0}), "Later lines come after any column")
+		assert.False(t, pos.Before(pos), "A position is not before itself")
+		assert.False(t, pos.Before(ast.BufferPos{Line: 0, Col: 9}))
+	})
 }
 
 // TestViewport_Transformation validates BufferPos → ScreenPos transformations
@@ -0,0 +1,184 @@
+package unit
+
+import (
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/ofri/mde/internal/crdt"
+	"github.com/ofri/mde/pkg/ast"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// exchange applies each replica's operations to the other.
+func exchange(a, b *crdt.Text, fromA, fromB []crdt.Op) {
+	for _, op := range fromB {
+		a.Apply(op)
+	}
+	for _, op := range fromA {
+		b.Apply(op)
+	}
+}
+
+func TestCRDT_ConcurrentEditsConverge(t *testing.T) {
+	a := crdt.NewText("a", "The fox")
+	b := crdt.NewText("b", "The fox")
+
+	fromA := a.Insert(4, "quick ")
+	fromB := append(b.Insert(7, " jumps"), b.Delete(0, 4)...)
+	exchange(a, b, fromA, fromB)
+
+	assert.Equal(t, "quick fox jumps", a.String())
+	assert.Equal(t, a.String(), b.String())
+}
+
+func TestCRDT_InsertionsAtTheSamePlace(t *testing.T) {
+	a := crdt.NewText("a", "ab")
+	b := crdt.NewText("b", "ab")
+
+	fromA := a.Insert(1, "XX")
+	fromB := b.Insert(1, "YY")
+	exchange(a, b, fromA, fromB)
+
+	assert.Equal(t, a.String(), b.String())
+	assert.Contains(t, []string{"aXXYYb", "aYYXXb"}, a.String(), "Concurrent insertions do not interleave")
+}
+
+func TestCRDT_DeletingTheSameText(t *testing.T) {
+	a := crdt.NewText("a", "one two three")
+	b := crdt.NewText("b", "one two three")
+
+	fromA := a.Delete(3, 4)
+	fromB := append(b.Delete(4, 4), b.Insert(4, "2 ")...)
+	exchange(a, b, fromA, fromB)
+
+	assert.Equal(t, "one2 three", a.String())
+	assert.Equal(t, a.String(), b.String())
+}
+
+func TestCRDT_OutOfOrderAndRepeatedDelivery(t *testing.T) {
+	a := crdt.NewText("a", "")
+	b := crdt.NewText("b", "")
+
+	ops := a.Insert(0, "hello")
+	ops = append(ops, a.Delete(0, 1)...)
+	ops = append(ops, a.Insert(0, "J")...)
+
+	// Everything backwards, then everything again
+	var changes []crdt.Change
+	for i := len(ops) - 1; i >= 0; i-- {
+		changes = append(changes, b.Apply(ops[i])...)
+	}
+	for _, op := range ops {
+		assert.Empty(t, b.Apply(op), "Operations apply once")
+	}
+	assert.Equal(t, "Jello", b.String())
+	assert.NotEmpty(t, changes)
+
+	late := crdt.NewText("c", "")
+	for _, op := range a.Ops() {
+		late.Apply(op)
+	}
+	assert.Equal(t, "Jello", late.String(), "A late replica catches up from the operations")
+}
+
+func TestCRDT_RandomEditsConverge(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	sites := []*crdt.Text{crdt.NewText("a", "base text"), crdt.NewText("b", "base text"), crdt.NewText("c", "base text")}
+
+	for round := 0; round < 20; round++ {
+		var ops [][]crdt.Op
+		for _, site := range sites {
+			var siteOps []crdt.Op
+			for edit := 0; edit < 3; edit++ {
+				length := len([]rune(site.String()))
+				if length > 0 && rng.Intn(3) == 0 {
+					siteOps = append(siteOps, site.Delete(rng.Intn(length), 1+rng.Intn(3))...)
+				} else {
+					siteOps = append(siteOps, site.Insert(rng.Intn(length+1), string(rune('a'+rng.Intn(26))))...)
+				}
+			}
+			ops = append(ops, siteOps)
+		}
+		// Each site receives the others' operations shuffled
+		for i, site := range sites {
+			var incoming []crdt.Op
+			for j, siteOps := range ops {
+				if i != j {
+					incoming = append(incoming, siteOps...)
+				}
+			}
+			rng.Shuffle(len(incoming), func(x, y int) { incoming[x], incoming[y] = incoming[y], incoming[x] })
+			for _, op := range incoming {
+				site.Apply(op)
+			}
+		}
+		for _, site := range sites[1:] {
+			require.Equal(t, sites[0].String(), site.String(), "round %d", round)
+		}
+	}
+}
+
+func TestCRDT_SnapshotJoinsLate(t *testing.T) {
+	a := crdt.NewText("a", "The fox")
+	a.Insert(4, "quick ")
+	a.Delete(0, 4)
+	inFlight := a.Insert(0, "A ")
+
+	late := crdt.FromSnapshot("c", a.Snapshot())
+	assert.Equal(t, a.String(), late.String())
+	for _, op := range inFlight {
+		assert.Empty(t, late.Apply(op), "Operations already in the snapshot change nothing")
+	}
+
+	// Both go on editing, around text deleted before the snapshot
+	fromLate := late.Insert(2, "very ")
+	fromA := a.Delete(0, 2)
+	exchange(a, late, fromA, fromLate)
+	assert.Equal(t, "very quick fox", a.String())
+	assert.Equal(t, a.String(), late.String())
+}
+
+func TestCRDT_LargeText(t *testing.T) {
+	// Long enough to span many blocks of elements
+	line := "Some words of a paragraph, long enough to wrap. "
+	a := crdt.NewText("a", strings.Repeat(line, 1000))
+	a.Insert(25_000, "inserted ")
+	a.Delete(10, 100)
+
+	b := crdt.NewText("b", "")
+	for _, op := range a.Ops() {
+		b.Apply(op)
+	}
+	assert.Equal(t, a.String(), b.String(), "A late replica catches up from the operations")
+	assert.Equal(t, a.String(), crdt.FromSnapshot("c", a.Snapshot()).String())
+
+	fromB := b.Insert(40_000, "B")
+	fromA := a.Insert(0, "A")
+	exchange(a, b, fromA, fromB)
+	assert.Equal(t, a.String(), b.String())
+}
+
+func TestCRDT_Document(t *testing.T) {
+	docA := ast.NewDocument("# Notes\nfirst line")
+	docB := ast.NewDocument("# Notes\nfirst line")
+	a := crdt.NewDocument(docA, "a")
+	b := crdt.NewDocument(docB, "b")
+
+	docA.InsertNewline(ast.BufferPos{Line: 1, Col: 10})
+	docA.InsertChar(ast.BufferPos{Line: 2, Col: 0}, 'x')
+	docB.DeleteRange(ast.BufferPos{Line: 0, Col: 0}, ast.BufferPos{Line: 0, Col: 2})
+
+	fromA := a.Commit()
+	changes := b.Apply(fromA)
+	a.Apply(b.Commit())
+
+	assert.Equal(t, "Notes\nfirst line\nx", docA.GetText())
+	assert.Equal(t, docA.GetText(), docB.GetText())
+	assert.Equal(t, []crdt.Change{{Offset: 16, Text: "\nx"}}, changes, "Changes are rune offsets of the text")
+	assert.Empty(t, a.Commit(), "Applied operations are not sent back")
+
+	assert.Equal(t, ast.BufferPos{Line: 2, Col: 1}, crdt.PosAt(docA, 18))
+	assert.Equal(t, 18, crdt.OffsetAt(docA, ast.BufferPos{Line: 2, Col: 1}))
+}
@@ -0,0 +1,80 @@
+package unit
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ofri/mde/internal/critic"
+	"github.com/ofri/mde/internal/plugins/parsers"
+	"github.com/ofri/mde/internal/plugins/renderers"
+	"github.com/ofri/mde/pkg/ast"
+	"github.com/ofri/mde/pkg/plugin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCriticFind(t *testing.T) {
+	doc := ast.NewDocument("Ünï {++new++} and {--old\ntext--} {++open\n{-- --}")
+	marks := critic.Find(doc)
+	require.Len(t, marks, 3)
+
+	assert.Equal(t, critic.Insertion, marks[0].Kind)
+	assert.Equal(t, "new", marks[0].Text)
+	assert.Equal(t, ast.Range{Start: ast.BufferPos{Line: 0, Col: 4}, End: ast.BufferPos{Line: 0, Col: 13}}, marks[0].Range, "Columns count runes")
+	assert.Equal(t, ast.Range{Start: ast.BufferPos{Line: 0, Col: 7}, End: ast.BufferPos{Line: 0, Col: 10}}, marks[0].Inner())
+
+	assert.Equal(t, critic.Deletion, marks[1].Kind)
+	assert.Equal(t, "old\ntext", marks[1].Text, "Marks span lines")
+	assert.Equal(t, ast.Range{Start: ast.BufferPos{Line: 0, Col: 18}, End: ast.BufferPos{Line: 1, Col: 7}}, marks[1].Range)
+
+	assert.Equal(t, critic.Deletion, marks[2].Kind, "An insertion left open is not a mark")
+
+	assert.Equal(t, "new", marks[0].Resolve(true))
+	assert.Equal(t, "", marks[0].Resolve(false))
+	assert.Equal(t, "", marks[1].Resolve(true))
+	assert.Equal(t, "old\ntext", marks[1].Resolve(false))
+
+	mark, ok := critic.At(marks, ast.BufferPos{Line: 0, Col: 13})
+	require.True(t, ok, "A mark holds the position just after it")
+	assert.Equal(t, "new", mark.Text)
+	_, ok = critic.At(marks, ast.BufferPos{Line: 0, Col: 14})
+	assert.False(t, ok)
+}
+
+func TestCriticComments(t *testing.T) {
+	line := "Fix {>>why?<<} it {++now++}"
+	spans := critic.Spans(line)
+	require.Len(t, spans, 2)
+	assert.Equal(t, critic.Span{Kind: critic.Comment, Start: 4, End: 14}, spans[0])
+
+	marks := critic.Find(ast.NewDocument(line))
+	require.Len(t, marks, 2)
+	assert.Equal(t, "why?", marks[0].Text)
+	assert.Equal(t, "", marks[0].Resolve(true), "Comments go either way")
+	assert.Equal(t, "", marks[0].Resolve(false))
+}
+
+func TestCriticTokensAndPreview(t *testing.T) {
+	parser := parsers.NewCommonMarkParser()
+	tokens, err := parser.GetSyntaxHighlighting(context.Background(), "a {++b++} {--c--} {>>d<<}")
+	require.NoError(t, err)
+	var kinds []ast.TokenKind
+	for _, token := range tokens {
+		if token.Kind() != ast.TokenDelimiter {
+			kinds = append(kinds, token.Kind())
+		}
+	}
+	assert.Equal(t, []ast.TokenKind{ast.TokenInsertion, ast.TokenDeletion, ast.TokenCriticComment}, kinds)
+
+	renderer := renderers.NewTerminalRenderer()
+	lines, err := renderer.RenderPreviewVisible(context.Background(), &plugin.RenderContext{
+		Document: ast.NewDocument("was {--old--}{++new++}"),
+		Viewport: ast.NewViewport(0, 0, 80, 5, 0, 4),
+	})
+	require.NoError(t, err)
+	line := lines[0]
+	assert.Equal(t, "was oldnew", line.Content)
+	require.Len(t, line.Styles, 2)
+	assert.True(t, line.Styles[0].Style.Strikethrough, "Deletions are struck through")
+	assert.Equal(t, 7, line.Styles[1].Start)
+}
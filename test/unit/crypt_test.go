@@ -0,0 +1,55 @@
+package unit
+
+import (
+	"context"
+	"os/exec"
+	"testing"
+
+	"github.com/ofri/mde/internal/crypt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCryptMatches(t *testing.T) {
+	patterns := []string{"*.md.age", "*.md.gpg"}
+	assert.True(t, crypt.Matches("/notes/diary.md.age", patterns))
+	assert.True(t, crypt.Matches("secret.md.gpg", patterns))
+	assert.False(t, crypt.Matches("/notes/diary.md", patterns))
+	assert.False(t, crypt.Matches("archive.md.age.bak", patterns))
+}
+
+func TestCryptFor(t *testing.T) {
+	assert.False(t, crypt.For("a.md.age", "").NeedsPassphrase())
+	assert.True(t, crypt.For("a.md.gpg", "").NeedsPassphrase())
+	assert.True(t, crypt.For("a.md.asc", "").NeedsPassphrase())
+
+	_, err := crypt.For("a.md.age", "").Decrypt(context.Background(), nil, "")
+	assert.ErrorIs(t, err, crypt.ErrNoIdentity)
+}
+
+func TestCryptGPGRoundTrip(t *testing.T) {
+	if _, err := exec.LookPath("gpg"); err != nil {
+		t.Skip("gpg not installed")
+	}
+	t.Setenv("GNUPGHOME", t.TempDir())
+	ctx := context.Background()
+	plaintext := []byte("# Diary\n\nNothing happened.\n")
+
+	for _, name := range []string{"diary.md.gpg", "diary.md.asc"} {
+		cipher := crypt.For(name, "")
+		ciphertext, err := cipher.Encrypt(ctx, plaintext, "hunter2")
+		require.NoError(t, err, name)
+		assert.NotContains(t, string(ciphertext), "Nothing happened", name)
+
+		decrypted, err := cipher.Decrypt(ctx, ciphertext, "hunter2")
+		require.NoError(t, err, name)
+		assert.Equal(t, plaintext, decrypted, name)
+
+		_, err = cipher.Decrypt(ctx, ciphertext, "wrong")
+		assert.Error(t, err, name)
+	}
+
+	armored, err := crypt.For("diary.md.asc", "").Encrypt(ctx, plaintext, "hunter2")
+	require.NoError(t, err)
+	assert.Contains(t, string(armored), "-----BEGIN PGP MESSAGE-----")
+}
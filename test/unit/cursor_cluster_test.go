@@ -0,0 +1,29 @@
+package unit
+
+import (
+	"testing"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+	"github.com/ofri/mde/internal/plugins/renderers"
+	"github.com/ofri/mde/pkg/plugin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCursor_CoversWholeGraphemeCluster(t *testing.T) {
+	renderer := renderers.NewTerminalRenderer()
+	render := func(content string, col int) string {
+		return renderer.RenderToStringWithCursor([]plugin.RenderedLine{{Content: content}}, 0, col)
+	}
+
+	assert.Equal(t, "a██b", render("a😀b", 1), "A wide emoji takes a glyph as wide")
+	assert.Equal(t, "a██b", render("a❤\ufe0fb", 2), "The variation selector belongs to the heart")
+	assert.Equal(t, "█x", render("e\u0301x", 1), "Combining marks go with their letter")
+	assert.Equal(t, "👩‍💻█", render("👩‍💻", 3), "Past the end of a joined emoji")
+
+	profile := lipgloss.ColorProfile()
+	lipgloss.SetColorProfile(termenv.ANSI)
+	t.Cleanup(func() { lipgloss.SetColorProfile(profile) })
+	assert.Equal(t, "a\x1b[7m❤️\x1b[0mb", render("a❤\ufe0fb", 2), "Reverse video spans the cluster")
+	assert.Equal(t, "\x1b[7m👩‍💻\x1b[0mx", render("👩‍💻x", 1))
+}
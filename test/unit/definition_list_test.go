@@ -0,0 +1,66 @@
+package unit
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ofri/mde/internal/plugins/parsers"
+	"github.com/ofri/mde/internal/plugins/renderers"
+	"github.com/ofri/mde/pkg/ast"
+	"github.com/ofri/mde/pkg/plugin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const definitionListDoc = "Apple\n: A red fruit\n  that grows on trees\n\nOrange\n\n: A citrus fruit\n:not a definition"
+
+func TestDefinitionList_Detection(t *testing.T) {
+	colon, content, ok := ast.DefinitionMarker(":   spaced")
+	assert.True(t, ok)
+	assert.Equal(t, 0, colon)
+	assert.Equal(t, 4, content)
+
+	_, _, ok = ast.DefinitionMarker(":emoji:")
+	assert.False(t, ok, "A colon must be followed by whitespace")
+	_, _, ok = ast.DefinitionMarker("    : code")
+	assert.False(t, ok, "Four spaces of indent is a code block")
+
+	doc := ast.NewDocument(definitionListDoc)
+	assert.True(t, doc.IsDefinitionTerm(0))
+	assert.True(t, doc.IsDefinitionTerm(4), "One blank line may separate term and definition")
+	assert.False(t, doc.IsDefinitionTerm(1))
+	assert.False(t, doc.IsDefinitionTerm(3))
+}
+
+func TestDefinitionList_Tokens(t *testing.T) {
+	parser := parsers.NewCommonMarkParser()
+	doc, err := parser.Parse(context.Background(), definitionListDoc)
+	require.NoError(t, err)
+
+	term := doc.GetLineTokens(0)
+	require.NotEmpty(t, term)
+	assert.Equal(t, ast.TokenDefinitionTerm, term[0].Kind())
+
+	definition := doc.GetLineTokens(1)
+	require.Len(t, definition, 2)
+	assert.Equal(t, ast.TokenDelimiter, definition[0].Kind())
+	assert.Equal(t, ast.TokenDefinition, definition[1].Kind())
+	assert.Equal(t, 2, definition[1].Start())
+}
+
+func TestDefinitionList_PreviewHangingIndent(t *testing.T) {
+	renderer := renderers.NewTerminalRenderer()
+	lines, err := renderer.RenderPreviewVisible(context.Background(), &plugin.RenderContext{
+		Document: ast.NewDocument(definitionListDoc),
+		Viewport: ast.NewViewport(0, 0, 80, 10, 0, 4),
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, "Apple", lines[0].Content)
+	require.Len(t, lines[0].Styles, 1)
+	assert.True(t, lines[0].Styles[0].Style.Bold)
+	assert.Equal(t, "    A red fruit", lines[1].Content)
+	assert.Equal(t, "    that grows on trees", lines[2].Content)
+	assert.Equal(t, "    A citrus fruit", lines[6].Content)
+	assert.Equal(t, ":not a definition", lines[7].Content)
+}
@@ -0,0 +1,101 @@
+package unit
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ofri/mde/internal/diagram"
+	"github.com/ofri/mde/internal/plugins/renderers"
+	"github.com/ofri/mde/pkg/ast"
+	"github.com/ofri/mde/pkg/plugin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFencedBlocks(t *testing.T) {
+	doc := ast.NewDocument("intro\n```go\nx := 1\n```\n~~~~ mermaid extra\ngraph TD\n```\n~~~~\n```\nunclosed")
+
+	blocks := doc.FencedBlocks()
+	require.Len(t, blocks, 3)
+
+	assert.Equal(t, ast.FencedBlock{StartLine: 1, EndLine: 3, Info: "go", Closed: true}, blocks[0])
+
+	// A backtick line does not close a tilde fence
+	assert.Equal(t, 4, blocks[1].StartLine)
+	assert.Equal(t, 7, blocks[1].EndLine)
+	assert.Equal(t, "mermaid", blocks[1].Language())
+	assert.Equal(t, "graph TD\n```", doc.FencedBlockSource(blocks[1]))
+
+	assert.False(t, blocks[2].Closed)
+	assert.Equal(t, 9, blocks[2].EndLine)
+	assert.Equal(t, "unclosed", doc.FencedBlockSource(blocks[2]))
+
+	block, ok := doc.FencedBlockAt(5)
+	assert.True(t, ok)
+	assert.Equal(t, 4, block.StartLine)
+	_, ok = doc.FencedBlockAt(0)
+	assert.False(t, ok)
+}
+
+func TestFencedBlocks_FollowEdits(t *testing.T) {
+	doc := ast.NewDocument("```\ncode\n```\ntext")
+	blocks := doc.FencedBlocks()
+	blocks[0].EndLine = 99
+	block, ok := doc.FencedBlockAt(1)
+	require.True(t, ok)
+	assert.Equal(t, 2, block.EndLine, "Callers get their own copy of the blocks")
+
+	doc.DeleteRange(ast.BufferPos{Line: 2, Col: 0}, ast.BufferPos{Line: 2, Col: 3})
+	block, ok = doc.FencedBlockAt(3)
+	require.True(t, ok, "Deleting the closing fence leaves the block open")
+	assert.False(t, block.Closed)
+}
+
+func TestPreview_DiagramFenceIsFramed(t *testing.T) {
+	doc := ast.NewDocument("# Title\n```mermaid\ngraph TD\n# not a heading\n```\ntext")
+	renderer := renderers.NewTerminalRenderer()
+
+	// Start below the opening fence: framing must not depend on it being visible
+	lines, err := renderer.RenderPreviewVisible(context.Background(), &plugin.RenderContext{
+		Document: doc,
+		Viewport: ast.NewViewport(2, 0, 30, 10, 0, 4),
+	})
+	require.NoError(t, err)
+	require.Len(t, lines, 4)
+
+	assert.Equal(t, "│ graph TD", lines[0].Content)
+	assert.Equal(t, "│ # not a heading", lines[1].Content)
+	assert.True(t, lines[1].Styles[len(lines[1].Styles)-1].Style.Faint, "Diagram source should be dimmed")
+	assert.Equal(t, "└"+strings.Repeat("─", 29), lines[2].Content)
+	assert.Equal(t, "text", lines[3].Content)
+
+	lines, err = renderer.RenderPreviewVisible(context.Background(), &plugin.RenderContext{
+		Document: doc,
+		Viewport: ast.NewViewport(1, 0, 30, 1, 0, 4),
+	})
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(lines[0].Content, "┌─ mermaid diagram ─"))
+}
+
+func TestDiagram_Render(t *testing.T) {
+	output := filepath.Join(t.TempDir(), "out's.svg")
+
+	err := diagram.Render(context.Background(), "cp {input} {output}", "mermaid", "graph TD", output)
+	require.NoError(t, err)
+	data, err := os.ReadFile(output)
+	require.NoError(t, err)
+	assert.Equal(t, "graph TD", string(data))
+
+	err = diagram.Render(context.Background(), "echo boom >&2; exit 1", "mermaid", "x", output)
+	assert.ErrorContains(t, err, "boom")
+
+	assert.ErrorIs(t, diagram.Render(context.Background(), "", "mermaid", "x", output), diagram.ErrNoCommand)
+
+	assert.Equal(t,
+		diagram.OutputPath("mermaid", "graph TD", "png"),
+		diagram.OutputPath("mermaid", "graph TD", "png"),
+		"Output path should be stable for unchanged source")
+}
@@ -0,0 +1,47 @@
+package unit
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ofri/mde/internal/diff"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSideBySide(t *testing.T) {
+	a := strings.Split("# Title\nkeep\nold line\ngone\nend", "\n")
+	b := strings.Split("# Title\nkeep\nnew line\nend\nextra", "\n")
+
+	assert.Equal(t, []diff.Row{
+		{Op: diff.Equal, A: 0, B: 0},
+		{Op: diff.Equal, A: 1, B: 1},
+		{Op: diff.Change, A: 2, B: 2},
+		{Op: diff.Delete, A: 3, B: -1},
+		{Op: diff.Equal, A: 4, B: 3},
+		{Op: diff.Insert, A: -1, B: 4},
+	}, diff.SideBySide(a, b))
+
+	assert.Empty(t, diff.SideBySide(nil, nil))
+	assert.Equal(t, []diff.Row{{Op: diff.Insert, A: -1, B: 0}}, diff.SideBySide(nil, []string{"x"}))
+}
+
+func TestEdits_Shortest(t *testing.T) {
+	edits := diff.Edits([]rune("ABCABBA"), []rune("CBABAC"))
+	changes := 0
+	for _, e := range edits {
+		if e.Op != diff.Equal {
+			changes++
+		}
+	}
+	assert.Equal(t, 5, changes, "The classic example takes five edits")
+}
+
+func TestIntraline(t *testing.T) {
+	removed, added := diff.Intraline("the old cat", "the new cat")
+	assert.Equal(t, []diff.Span{{Start: 4, End: 7}}, removed)
+	assert.Equal(t, []diff.Span{{Start: 4, End: 7}}, added)
+
+	removed, added = diff.Intraline("same", "same")
+	assert.Empty(t, removed)
+	assert.Empty(t, added)
+}
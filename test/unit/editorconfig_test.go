@@ -0,0 +1,92 @@
+package unit
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ofri/mde/internal/config"
+	"github.com/ofri/mde/internal/editorconfig"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeEditorConfig writes content as the .editorconfig in dir.
+func writeEditorConfig(t *testing.T, dir, content string) {
+	require.NoError(t, os.MkdirAll(dir, 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, editorconfig.Filename), []byte(content), 0o644))
+}
+
+func TestEditorConfig_Globs(t *testing.T) {
+	dir := t.TempDir()
+	writeEditorConfig(t, dir, `root = true
+
+[*]
+end_of_line = lf
+
+[*.{md,markdown}]
+indent_style = space
+
+[docs/**.md]
+indent_size = 2
+
+[chapter{1..3}.md]
+insert_final_newline = true
+
+[[!a-c]*.txt]
+trim_trailing_whitespace = true
+`)
+
+	lookup := func(name string) editorconfig.Settings {
+		settings, err := editorconfig.Lookup(filepath.Join(dir, name))
+		require.NoError(t, err)
+		return settings
+	}
+	assert.Equal(t, "lf", lookup("notes.txt").EndOfLine)
+	assert.Equal(t, "space", lookup("sub/notes.md").IndentStyle, "A glob without a slash matches at any depth")
+	assert.Equal(t, "space", lookup("notes.markdown").IndentStyle)
+	assert.Equal(t, "", lookup("notes.txt").IndentStyle)
+	assert.Equal(t, 2, lookup("docs/guides/intro.md").IndentSize)
+	assert.Equal(t, 0, lookup("sub/docs/intro.md").IndentSize, "A glob with a slash is relative to its file")
+	assert.NotNil(t, lookup("chapter2.md").InsertFinalNewline)
+	assert.Nil(t, lookup("chapter4.md").InsertFinalNewline)
+	assert.NotNil(t, lookup("notes.txt").TrimTrailingWhitespace)
+	assert.Nil(t, lookup("about.txt").TrimTrailingWhitespace)
+}
+
+func TestEditorConfig_NearerFilesWin(t *testing.T) {
+	dir := t.TempDir()
+	writeEditorConfig(t, dir, "[*]\nindent_style = tab\n")
+	project := filepath.Join(dir, "project")
+	writeEditorConfig(t, project, "root = true\n\n[*]\nindent_size = 4\ntrim_trailing_whitespace = true\n")
+	writeEditorConfig(t, filepath.Join(project, "docs"), "[*.md]\nindent_size = 2\ntrim_trailing_whitespace = unset\n")
+
+	settings, err := editorconfig.Lookup(filepath.Join(project, "docs", "a.md"))
+	require.NoError(t, err)
+	assert.Equal(t, editorconfig.Settings{IndentSize: 2}, settings, "The root file ends the search, and unset clears")
+
+	settings, err = editorconfig.Lookup(filepath.Join(t.TempDir(), "a.md"))
+	require.NoError(t, err)
+	assert.True(t, settings.Empty())
+}
+
+func TestEditorConfig_ApplyUnderUserConfig(t *testing.T) {
+	yes := true
+	settings := editorconfig.Settings{IndentStyle: "space", IndentSize: 2, TrimTrailingWhitespace: &yes}
+
+	path := filepath.Join(t.TempDir(), "config.toml")
+	require.NoError(t, os.WriteFile(path, []byte("tab_width = 8\n"), 0o644))
+	cfg, err := config.LoadFile(path)
+	require.NoError(t, err)
+	assert.True(t, cfg.IsSet("tab_width"))
+	assert.False(t, cfg.IsSet("indent_style"))
+
+	restore := settings.Apply(cfg)
+	assert.Equal(t, 8, cfg.TabWidth, "The user's own setting wins")
+	assert.Equal(t, "space", cfg.IndentStyle)
+	assert.True(t, cfg.StripTrailingWhitespace)
+
+	restore()
+	assert.Equal(t, "tab", cfg.IndentStyle)
+	assert.False(t, cfg.StripTrailingWhitespace)
+}
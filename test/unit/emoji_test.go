@@ -0,0 +1,68 @@
+package unit
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ofri/mde/internal/emoji"
+	"github.com/ofri/mde/internal/plugins/parsers"
+	"github.com/ofri/mde/internal/plugins/renderers"
+	"github.com/ofri/mde/pkg/ast"
+	"github.com/ofri/mde/pkg/plugin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEmoji_Lookup(t *testing.T) {
+	smile, ok := emoji.Lookup("smile")
+	assert.True(t, ok)
+	assert.Equal(t, "😄", smile)
+
+	_, ok = emoji.Lookup("not_an_emoji")
+	assert.False(t, ok)
+
+	assert.Greater(t, len(emoji.Names()), 1000, "Generated name list should cover the GitHub set")
+}
+
+func TestEmoji_CompleteAndPartial(t *testing.T) {
+	matches := emoji.Complete("smile")
+	assert.Contains(t, matches, "smile")
+	assert.Contains(t, matches, "smiley")
+	for _, name := range matches {
+		assert.Regexp(t, "^smile", name)
+	}
+	assert.Empty(t, emoji.Complete("zzzz"))
+
+	partial, ok := emoji.PartialAt("hi :smi", 7)
+	assert.True(t, ok)
+	assert.Equal(t, "smi", partial)
+
+	_, ok = emoji.PartialAt("at 10:3", 7)
+	assert.False(t, ok, "Times are not shortcodes")
+	_, ok = emoji.PartialAt("done :smile: ok", 15)
+	assert.False(t, ok)
+}
+
+func TestEmoji_FindShortcodes(t *testing.T) {
+	codes := emoji.FindShortcodes("at 10:30:smile: and :nope: :+1:")
+	require.Len(t, codes, 2)
+	assert.Equal(t, emoji.Shortcode{Start: 8, End: 15, Name: "smile"}, codes[0])
+	assert.Equal(t, "+1", codes[1].Name)
+}
+
+func TestEmoji_ParserAndPreview(t *testing.T) {
+	parser := parsers.NewCommonMarkParser()
+	tokens, err := parser.GetSyntaxHighlighting(context.Background(), "ship it :rocket:")
+	require.NoError(t, err)
+	require.Len(t, tokens, 1)
+	assert.Equal(t, ast.TokenEmoji, tokens[0].Kind())
+	assert.Equal(t, 8, tokens[0].Start())
+
+	renderer := renderers.NewTerminalRenderer()
+	lines, err := renderer.RenderPreviewVisible(context.Background(), &plugin.RenderContext{
+		Document: ast.NewDocument("ship it :rocket: now"),
+		Viewport: ast.NewViewport(0, 0, 80, 5, 0, 4),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "ship it 🚀 now", lines[0].Content)
+}
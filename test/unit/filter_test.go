@@ -0,0 +1,47 @@
+package unit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ofri/mde/internal/filter"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFilterRun(t *testing.T) {
+	ctx := context.Background()
+
+	output, err := filter.Run(ctx, "sort", "pear\napple\nfig\n")
+	require.NoError(t, err)
+	assert.Equal(t, "apple\nfig\npear\n", output)
+
+	output, err = filter.Run(ctx, "tr a-z A-Z", "part of a line")
+	require.NoError(t, err)
+	assert.Equal(t, "PART OF A LINE", output, "Text without a final newline gets none back")
+
+	_, err = filter.Run(ctx, "echo first >&2; echo 'bad input' >&2; exit 3", "x")
+	assert.ErrorContains(t, err, "exit status 3")
+	assert.ErrorContains(t, err, "bad input", "The last line of stderr explains the failure")
+
+	_, err = filter.Run(ctx, "  ", "x")
+	assert.Error(t, err)
+
+	short, cancel := context.WithTimeout(ctx, 100*time.Millisecond)
+	defer cancel()
+	_, err = filter.Run(short, "sleep 5", "x")
+	assert.ErrorIs(t, err, filter.ErrTimeout)
+}
+
+func TestFilterCapture(t *testing.T) {
+	ctx := context.Background()
+
+	output, err := filter.Capture(ctx, "sh", "echo out; echo err >&2; exit 1\n", ".sh")
+	assert.Equal(t, "out\nerr\n", output, "Output is kept when the code fails")
+	assert.ErrorContains(t, err, "exit status 1")
+
+	output, err = filter.Capture(ctx, "cat {file}", "from a file", ".txt")
+	require.NoError(t, err)
+	assert.Equal(t, "from a file", output)
+}
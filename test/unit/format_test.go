@@ -0,0 +1,87 @@
+package unit
+
+import (
+	"testing"
+
+	"github.com/ofri/mde/pkg/ast"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestToggleFormat_Word(t *testing.T) {
+	tests := []struct {
+		name, text, marker, expected string
+		col, cursor                  int
+	}{
+		{"bold", "say hello now", ast.BoldMarker, "say **hello** now", 6, 8},
+		{"unbold", "say **hello** now", ast.BoldMarker, "say hello now", 8, 6},
+		{"italic inside bold", "say **hello** now", ast.ItalicMarker, "say ***hello*** now", 8, 9},
+		{"unitalic keeps bold", "say ***hello*** now", ast.ItalicMarker, "say **hello** now", 9, 8},
+		{"unbold keeps italic", "say ***hello*** now", ast.BoldMarker, "say *hello* now", 9, 7},
+		{"code", "run ls now", ast.CodeMarker, "run `ls` now", 4, 5},
+		{"uncode", "run `ls` now", ast.CodeMarker, "run ls now", 5, 4},
+		{"no word", "a  b", ast.BoldMarker, "a **** b", 2, 4},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			editor := ast.NewEditorWithContent(tt.text)
+			editor.GetCursor().SetBufferPos(ast.BufferPos{Line: 0, Col: tt.col})
+			editor.ToggleFormat(tt.marker)
+			assert.Equal(t, tt.expected, editor.GetDocument().GetText())
+			assert.Equal(t, tt.cursor, editor.GetCursor().GetBufferPos().Col)
+		})
+	}
+}
+
+func TestToggleFormat_Selection(t *testing.T) {
+	editor := ast.NewEditorWithContent("a big deal")
+	editor.GetCursor().SetSelection(&ast.Selection{Start: ast.BufferPos{Line: 0, Col: 2}, End: ast.BufferPos{Line: 0, Col: 10}})
+
+	editor.ToggleFormat(ast.BoldMarker)
+	assert.Equal(t, "a **big deal**", editor.GetDocument().GetText())
+	assert.Equal(t, "big deal", editor.GetSelectionText(), "The inner text stays selected")
+
+	editor.ToggleFormat(ast.BoldMarker)
+	assert.Equal(t, "a big deal", editor.GetDocument().GetText())
+
+	// Selecting the markers too unwraps from the inside
+	editor.GetCursor().SetSelection(&ast.Selection{Start: ast.BufferPos{Line: 0, Col: 2}, End: ast.BufferPos{Line: 0, Col: 10}})
+	editor.ToggleFormat(ast.CodeMarker)
+	editor.GetCursor().SetSelection(&ast.Selection{Start: ast.BufferPos{Line: 0, Col: 2}, End: ast.BufferPos{Line: 0, Col: 12}})
+	editor.ToggleFormat(ast.CodeMarker)
+	assert.Equal(t, "a big deal", editor.GetDocument().GetText())
+
+	// Underscores count as emphasis markers too
+	editor = ast.NewEditorWithContent("say __hello__")
+	editor.GetCursor().SetSelection(&ast.Selection{Start: ast.BufferPos{Line: 0, Col: 6}, End: ast.BufferPos{Line: 0, Col: 11}})
+	editor.ToggleFormat(ast.BoldMarker)
+	assert.Equal(t, "say hello", editor.GetDocument().GetText())
+}
+
+func TestWrapLink(t *testing.T) {
+	editor := ast.NewEditorWithContent("see docs here")
+	editor.GetCursor().SetBufferPos(ast.BufferPos{Line: 0, Col: 5})
+
+	assert.True(t, editor.WrapLink(""))
+	assert.Equal(t, "see [docs]() here", editor.GetDocument().GetText())
+	assert.Equal(t, 11, editor.GetCursor().GetBufferPos().Col, "Cursor waits in the parentheses")
+
+	editor = ast.NewEditorWithContent("see docs")
+	editor.GetCursor().SetBufferPos(ast.BufferPos{Line: 0, Col: 5})
+	assert.True(t, editor.WrapLink("https://example.com"))
+	assert.Equal(t, "see [docs](https://example.com)", editor.GetDocument().GetText())
+}
+
+func TestWrapReferenceLink(t *testing.T) {
+	editor := ast.NewEditorWithContent("see docs here")
+	editor.GetCursor().SetBufferPos(ast.BufferPos{Line: 0, Col: 5})
+
+	assert.True(t, editor.WrapReferenceLink("1", ""))
+	assert.Equal(t, "see [docs][1] here\n\n[1]: ", editor.GetDocument().GetText())
+	assert.Equal(t, ast.BufferPos{Line: 2, Col: 5}, editor.GetCursor().GetBufferPos(), "Cursor waits for the URL")
+
+	editor.GetCursor().SetBufferPos(ast.BufferPos{Line: 0, Col: 16})
+	assert.True(t, editor.WrapReferenceLink("2", "https://example.com"))
+	assert.Equal(t, "see [docs][1] [here][2]\n\n[1]: \n[2]: https://example.com", editor.GetDocument().GetText(),
+		"Definitions gather at the end")
+	assert.Equal(t, ast.BufferPos{Line: 0, Col: 23}, editor.GetCursor().GetBufferPos())
+}
@@ -0,0 +1,105 @@
+package unit
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/ofri/mde/internal/plugins/parsers"
+	"github.com/ofri/mde/internal/plugins/renderers"
+	"github.com/ofri/mde/pkg/ast"
+	"github.com/ofri/mde/pkg/plugin"
+	"github.com/stretchr/testify/require"
+)
+
+// Token and style offsets are rune offsets; a byte offset leaking through
+// on multibyte text usually lands past the end of the line.
+
+// fuzzSeeds cover each tokenizer rule plus multibyte text, so mutations
+// start from interesting inputs. Plain test runs only check the seeds;
+// fuzz with e.g.
+//
+//	go test ./test/unit -run XXX -fuzz FuzzRenderLine -fuzztime 1m -fuzzminimizetime 1s
+var fuzzSeeds = []string{
+	"",
+	"# Heading",
+	"###### deep ####",
+	"**bold** and *italic* and __under__ _score_",
+	"~~strike~~ ==mark== `code` ``a ` b``",
+	"[text](https://example.com) ![img](a.png) <https://x.y>",
+	"[ref][id] [^1] [^1]: note",
+	"[id]: https://example.com \"title\"",
+	"$x^2$ and $$\\alpha$$",
+	":smile: :+1: :not an emoji:",
+	"> > nested quote",
+	"- item\n  1. nested",
+	"Term\n: definition",
+	"<span class=\"x\">html</span> <!-- comment -->",
+	"[[Wiki Link|alias]]",
+	"日本語の**太字**と`コード`",
+	"emoji 👍🏽 *em* [链接](url) ~~删~~",
+	"é́ combining *marks*",
+	"\t**tab**\tsep",
+	"\\*escaped\\* \\`tick",
+	"***",
+	"*",
+	"[",
+	"`",
+	"\xff\xfe invalid *utf8*",
+}
+
+func FuzzGetSyntaxHighlighting(f *testing.F) {
+	for _, seed := range fuzzSeeds {
+		f.Add(seed)
+	}
+	parser := parsers.NewCommonMarkParser()
+
+	f.Fuzz(func(t *testing.T, text string) {
+		for _, line := range strings.Split(text, "\n") {
+			tokens, err := parser.GetSyntaxHighlighting(context.Background(), line)
+			require.NoError(t, err)
+			runes := utf8.RuneCountInString(line)
+			for _, token := range tokens {
+				require.Truef(t, 0 <= token.Start() && token.Start() <= token.End() && token.End() <= runes,
+					"token %v [%d,%d) out of range for %q (%d runes)", token.Kind(), token.Start(), token.End(), line, runes)
+			}
+		}
+	})
+}
+
+func FuzzRenderLine(f *testing.F) {
+	for _, seed := range fuzzSeeds {
+		f.Add(seed)
+	}
+	parser := parsers.NewCommonMarkParser()
+	renderer := renderers.NewTerminalRenderer()
+
+	f.Fuzz(func(t *testing.T, text string) {
+		ctx := context.Background()
+		for _, line := range strings.Split(text, "\n") {
+			tokens, err := parser.GetSyntaxHighlighting(ctx, line)
+			require.NoError(t, err)
+			rendered, err := renderer.RenderLine(ctx, line, tokens)
+			require.NoError(t, err)
+			runes := utf8.RuneCountInString(rendered.Content)
+			for _, style := range rendered.Styles {
+				require.Truef(t, 0 <= style.Start && style.Start <= style.End && style.End <= runes,
+					"style [%d,%d) out of range for %q (%d runes)", style.Start, style.End, rendered.Content, runes)
+			}
+		}
+
+		// The full pipeline slices the styled lines for display
+		doc := ast.NewDocument(text)
+		renderCtx := &plugin.RenderContext{
+			Document:        doc,
+			Viewport:        ast.NewViewport(0, 0, 40, 10, 4, 4),
+			ShowLineNumbers: true,
+		}
+		_, err := renderer.RenderVisible(ctx, renderCtx)
+		require.NoError(t, err)
+		renderCtx.ShowLineNumbers = false
+		_, err = renderer.RenderPreviewVisible(ctx, renderCtx)
+		require.NoError(t, err)
+	})
+}
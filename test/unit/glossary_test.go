@@ -0,0 +1,80 @@
+package unit
+
+import (
+	"testing"
+
+	"github.com/ofri/mde/internal/glossary"
+	"github.com/ofri/mde/internal/lint"
+	"github.com/ofri/mde/pkg/ast"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGlossaryParse(t *testing.T) {
+	terms := glossary.Parse(`# Glossary
+
+*[API]: Application Programming Interface
+*[HTTP]:   Hypertext Transfer Protocol
+
+Event loop
+: The loop that runs queued callbacks
+: One at a time
+
+Callback
+
+: A function run later
+`)
+	assert.Equal(t, []glossary.Term{
+		{Name: "API", Definition: "Application Programming Interface"},
+		{Name: "HTTP", Definition: "Hypertext Transfer Protocol"},
+		{Name: "Event loop", Definition: "The loop that runs queued callbacks; One at a time"},
+		{Name: "Callback", Definition: "A function run later"},
+	}, terms)
+}
+
+func TestGlossaryFind(t *testing.T) {
+	g := glossary.New([]glossary.Term{
+		{Name: "API", Definition: "Application Programming Interface"},
+		{Name: "event loop", Definition: "Runs callbacks"},
+		{Name: "event", Definition: "Something that happens"},
+		{Name: "C++", Definition: "A language"},
+	})
+
+	line := "The APIs and Event Loop, not api or RAPID, an event in C++ `API` [x](API)."
+	var found []string
+	for _, match := range g.Find(line) {
+		found = append(found, line[match.Start:match.End]+"="+match.Term.Name)
+	}
+	assert.Equal(t, []string{"APIs=API", "Event Loop=event loop", "event=event", "C++=C++"}, found,
+		"Capitals match as written, other terms in any case, whole words only, outside code and URLs")
+
+	match, ok := g.At("Ünï API", 5)
+	require.True(t, ok, "Columns count runes")
+	assert.Equal(t, "API", match.Term.Name)
+
+	term, ok := g.Lookup("Event loops")
+	require.True(t, ok)
+	assert.Equal(t, "Runs callbacks", term.Definition)
+	_, ok = g.Lookup("Api")
+	assert.False(t, ok)
+}
+
+func TestGlossaryCheckReportsUndefinedAcronyms(t *testing.T) {
+	g := glossary.New([]glossary.Term{{Name: "API", Definition: "Application Programming Interface"}})
+	doc := ast.NewDocument("Use the API over HTTP.\n" +
+		"A Domain Name System (DNS) lookup, then DNS again and HTTP again.\n" +
+		"Chapter IV, `CODE` and\n" +
+		"```\nSQL\n```\n" +
+		"*[TLS]: Transport Layer Security\n" +
+		"TLS and URLs")
+
+	diagnostics := g.Check(doc)
+	require.Len(t, diagnostics, 2, "Each undefined acronym is reported once, at first use")
+	assert.Equal(t, ast.Range{Start: ast.BufferPos{Line: 0, Col: 17}, End: ast.BufferPos{Line: 0, Col: 21}}, diagnostics[0].Range)
+	assert.Equal(t, lint.Warning, diagnostics[0].Severity)
+	assert.Contains(t, diagnostics[0].Message, "Undefined acronym HTTP")
+	assert.Contains(t, diagnostics[1].Message, "Undefined acronym URL", "The plural names the acronym")
+
+	var none *glossary.Glossary
+	assert.Len(t, none.Check(ast.NewDocument("An API")), 1, "Without a glossary only the document defines acronyms")
+}
@@ -0,0 +1,173 @@
+package unit
+
+import (
+	"testing"
+
+	"github.com/ofri/mde/pkg/ast"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShiftHeading(t *testing.T) {
+	editor := ast.NewEditorWithContent("## Title\ntext")
+	editor.GetCursor().SetBufferPos(ast.BufferPos{Col: 5})
+
+	assert.True(t, editor.ShiftHeading(1))
+	assert.Equal(t, "### Title\ntext", editor.GetDocument().GetText())
+	assert.Equal(t, ast.BufferPos{Col: 6}, editor.GetCursor().GetBufferPos(), "The cursor stays on its character")
+
+	assert.True(t, editor.ShiftHeading(-1))
+	assert.True(t, editor.ShiftHeading(-1))
+	assert.Equal(t, "# Title\ntext", editor.GetDocument().GetText())
+	assert.False(t, editor.ShiftHeading(-1), "Level 1 cannot be promoted")
+
+	editor.GetCursor().SetBufferPos(ast.BufferPos{Line: 1})
+	assert.False(t, editor.ShiftHeading(1), "Not a heading")
+	assert.Equal(t, "# Title\ntext", editor.GetDocument().GetText())
+}
+
+func TestShiftHeading_Setext(t *testing.T) {
+	editor := ast.NewEditorWithContent("Title\n=====\n\nafter")
+
+	assert.True(t, editor.ShiftHeading(1))
+	assert.Equal(t, "Title\n-----\n\nafter", editor.GetDocument().GetText(), "Level 2 keeps its underline")
+
+	editor.GetCursor().SetBufferPos(ast.BufferPos{Line: 0, Col: 2})
+	assert.True(t, editor.ShiftHeading(1))
+	assert.Equal(t, "### Title\n\nafter", editor.GetDocument().GetText(), "Level 3 becomes ATX")
+	assert.Equal(t, ast.BufferPos{Col: 6}, editor.GetCursor().GetBufferPos())
+}
+
+func TestShiftSection(t *testing.T) {
+	text := "# Top\n## Part\ntext\n### Sub\n```\n# not a heading\n```\n## Next"
+	editor := ast.NewEditorWithContent(text)
+	editor.GetCursor().SetBufferPos(ast.BufferPos{Line: 2})
+
+	assert.True(t, editor.ShiftSection(1))
+	assert.Equal(t, "# Top\n### Part\ntext\n#### Sub\n```\n# not a heading\n```\n## Next", editor.GetDocument().GetText())
+
+	assert.True(t, editor.ShiftSection(-1))
+	assert.Equal(t, text, editor.GetDocument().GetText())
+
+	editor.GetCursor().SetBufferPos(ast.BufferPos{Line: 0})
+	assert.False(t, editor.ShiftSection(-1), "Any heading leaving 1–6 refuses the shift")
+	assert.Equal(t, text, editor.GetDocument().GetText())
+
+	editor = ast.NewEditorWithContent("intro\n# Top")
+	assert.False(t, editor.ShiftSection(1), "No section above the first heading")
+}
+
+func TestMoveSection(t *testing.T) {
+	text := "# Doc\n\n## A\na text\n### A.1\n\n## B\nb text\n\n# Next\n## C"
+	editor := ast.NewEditorWithContent(text)
+	editor.GetCursor().SetBufferPos(ast.BufferPos{Line: 7, Col: 2})
+
+	assert.True(t, editor.MoveSection(-1))
+	assert.Equal(t, "# Doc\n\n## B\nb text\n\n## A\na text\n### A.1\n\n# Next\n## C", editor.GetDocument().GetText(),
+		"Blank lines between sections stay put")
+	assert.Equal(t, ast.BufferPos{Line: 3, Col: 2}, editor.GetCursor().GetBufferPos(), "The cursor moves with its section")
+
+	assert.False(t, editor.MoveSection(-1), "No previous sibling under # Doc")
+
+	assert.True(t, editor.MoveSection(1))
+	assert.Equal(t, text, editor.GetDocument().GetText())
+	assert.Equal(t, ast.BufferPos{Line: 7, Col: 2}, editor.GetCursor().GetBufferPos())
+
+	assert.False(t, editor.MoveSection(1), "# Next is a parent's sibling, not ours")
+
+	editor.GetCursor().SetBufferPos(ast.BufferPos{Line: 10})
+	assert.False(t, editor.MoveSection(1))
+	assert.False(t, editor.MoveSection(-1))
+}
+
+func TestMoveSection_RefreshesIndexes(t *testing.T) {
+	editor := ast.NewEditorWithContent("# A\naaa\n\n# B\nbbbbbbbb\n")
+	doc := editor.GetDocument()
+	require.Len(t, doc.Headings(), 2)
+	assert.Equal(t, 9, doc.PosToOffset(ast.BufferPos{Line: 3}))
+
+	editor.GetCursor().SetBufferPos(ast.BufferPos{Line: 3})
+	revision := doc.Revision()
+	require.True(t, editor.MoveSection(-1))
+	assert.Equal(t, revision+1, doc.Revision(), "The move is one change to the document")
+	headings := doc.Headings()
+	require.Len(t, headings, 2)
+	assert.Equal(t, "B", headings[0].Text)
+	assert.Equal(t, 0, headings[0].Line)
+	assert.Equal(t, 3, headings[1].Line)
+	assert.Equal(t, 14, doc.PosToOffset(ast.BufferPos{Line: 3}), "Offsets follow the moved lines")
+}
+
+func TestMoveSection_TopLevel(t *testing.T) {
+	editor := ast.NewEditorWithContent("intro\n\n# One\n1\n\n# Two\n2")
+	editor.GetCursor().SetBufferPos(ast.BufferPos{Line: 2})
+
+	assert.True(t, editor.MoveSection(1))
+	assert.Equal(t, "intro\n\n# Two\n2\n\n# One\n1", editor.GetDocument().GetText())
+	assert.Equal(t, 5, editor.GetCursor().GetBufferPos().Line)
+
+	editor.GetCursor().SetBufferPos(ast.BufferPos{Line: 0})
+	assert.False(t, editor.MoveSection(1), "Text above the first heading is no section")
+}
+
+func TestHeadings_Path(t *testing.T) {
+	doc := ast.NewDocument("intro\n# Chapter 2\n## Setup\n### Install\ntext\n```\n# not a heading\n```\n## Usage\nmore")
+	texts := func(line int) []string {
+		var texts []string
+		for _, h := range doc.HeadingPath(line) {
+			texts = append(texts, h.Text)
+		}
+		return texts
+	}
+
+	assert.Empty(t, texts(0), "Nothing encloses text above the first heading")
+	assert.Equal(t, []string{"Chapter 2", "Setup", "Install"}, texts(6), "Headings in code are skipped")
+	assert.Equal(t, []string{"Chapter 2", "Usage"}, texts(9))
+
+	line, ok := doc.HeadingAbove(7)
+	assert.True(t, ok)
+	assert.Equal(t, 3, line)
+
+	doc.InsertNewline(ast.BufferPos{Line: 0, Col: 0})
+	assert.Equal(t, []string{"Chapter 2", "Usage"}, texts(10), "The outline follows edits")
+}
+
+func TestHeadings_Numbers(t *testing.T) {
+	numbers := func(content string) []string {
+		return ast.HeadingNumbers(ast.NewDocument(content).Headings())
+	}
+	assert.Equal(t, []string{"", "1", "1.1", "1.2", "2", "2.0.1"},
+		numbers("# Title\n## A\n### B\n### C\n## D\n#### E"), "A lone leading top heading is the title")
+	assert.Equal(t, []string{"1", "1.1", "2"}, numbers("# A\n## B\n# C"))
+	assert.Equal(t, []string{"1"}, numbers("# Only"))
+}
+
+func TestHeadings_NumberAndUnnumber(t *testing.T) {
+	editor := ast.NewEditorWithContent("# Guide\n\n## 3. Setup\n\n### Install\n\nUsage\n-----\n\n```\n## code\n```\n##")
+	editor.GetCursor().SetBufferPos(ast.BufferPos{Line: 4, Col: 6})
+
+	assert.Equal(t, 4, editor.NumberHeadings(-1))
+	assert.Equal(t, "# Guide\n\n## 1 Setup\n\n### 1.1 Install\n\n2 Usage\n-----\n\n```\n## code\n```\n## 3 ",
+		editor.GetDocument().GetText())
+	assert.Equal(t, ast.BufferPos{Line: 4, Col: 10}, editor.GetCursor().GetBufferPos(), "The cursor stays on its text")
+	assert.Equal(t, 0, editor.NumberHeadings(-1), "Numbers in order are left alone")
+
+	assert.Equal(t, 4, editor.UnnumberHeadings())
+	assert.Equal(t, "# Guide\n\n## Setup\n\n### Install\n\nUsage\n-----\n\n```\n## code\n```\n## ",
+		editor.GetDocument().GetText())
+}
+
+func TestHeadings_NumbersFollowMovedSections(t *testing.T) {
+	editor := ast.NewEditorWithContent("# Guide\n## 1 Setup\n### 1.1 Install\n## 2 Usage\n")
+	editor.GetCursor().SetBufferPos(ast.BufferPos{Line: 3})
+	assert.True(t, editor.MoveSection(-1))
+
+	assert.Equal(t, 3, editor.NumberHeadings(-1))
+	assert.Equal(t, "# Guide\n## 1 Usage\n## 2 Setup\n### 2.1 Install\n", editor.GetDocument().GetText())
+}
+
+func TestHeadings_NumberSkipsHeadingBeingTyped(t *testing.T) {
+	editor := ast.NewEditorWithContent("# Doc\n## One\n## Two\n##")
+	assert.Equal(t, 2, editor.NumberHeadings(3))
+	assert.Equal(t, "# Doc\n## 1 One\n## 2 Two\n##", editor.GetDocument().GetText())
+}
@@ -0,0 +1,86 @@
+package unit
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ofri/mde/pkg/editor"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHeadlessEditing(t *testing.T) {
+	ed := editor.New("# Title\n\nSome text")
+
+	ed.SetCursor(editor.Pos{Line: 2, Col: 99})
+	assert.Equal(t, editor.Pos{Line: 2, Col: 9}, ed.Cursor(), "The cursor is clamped to the line")
+	ed.Insert(" here")
+	ed.Delete(4)
+	assert.Equal(t, "# Title\n\nSome text ", ed.Text())
+
+	ed.Select(editor.Range{Start: editor.Pos{Line: 0, Col: 2}, End: editor.Pos{Line: 0, Col: 7}})
+	assert.Equal(t, "Title", ed.SelectedText())
+	ed.Insert("Notes")
+	_, selected := ed.Selection()
+	assert.False(t, selected)
+	assert.Equal(t, "# Notes", ed.Line(0))
+	assert.True(t, ed.Modified())
+}
+
+func TestHeadlessSearch(t *testing.T) {
+	ed := editor.New("TODO one\ntodo two\nTODO three")
+
+	assert.Len(t, ed.FindAll("todo", false), 3)
+	assert.Len(t, ed.FindAll("TODO", true), 2)
+
+	ed.SetCursor(editor.Pos{Line: 1})
+	r, ok := ed.Find("TODO", editor.SearchOptions{CaseSensitive: true})
+	require.True(t, ok)
+	assert.Equal(t, 2, r.Start.Line)
+	assert.Equal(t, editor.Pos{Line: 1}, ed.Cursor(), "Find leaves the cursor")
+
+	assert.Equal(t, 2, ed.ReplaceAll("TODO", "DONE!", true))
+	assert.Equal(t, "DONE! one\ntodo two\nDONE! three", ed.Text())
+	assert.Equal(t, editor.Pos{Line: 2, Col: 5}, ed.Cursor())
+}
+
+func TestHeadlessOpenSaveRender(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "notes.md")
+	ed, err := editor.Open(path)
+	require.NoError(t, err, "A missing file opens empty")
+	ed.Insert("# Notes\n**bold**")
+	require.NoError(t, ed.Save(""))
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "# Notes\n**bold**", string(data))
+	assert.False(t, ed.Modified())
+
+	out, err := ed.Render(editor.RenderOptions{Plain: true})
+	require.NoError(t, err)
+	assert.Equal(t, "# Notes\n**bold**", out)
+
+	out, err = ed.Render(editor.RenderOptions{Plain: true, LineNumbers: true})
+	require.NoError(t, err)
+	assert.Contains(t, out, "1│ # Notes")
+}
+
+func TestBatchScript(t *testing.T) {
+	commands, err := editor.ParseScript("replace-all:a\\:b:c\\;d; format-tables\n# comment; not a command\n\nsave")
+	require.NoError(t, err)
+	assert.Equal(t, []editor.Command{
+		{Name: "replace-all", Args: []string{"a:b", "c;d"}},
+		{Name: "format-tables", Args: []string{}},
+		{Name: "save", Args: []string{}},
+	}, commands)
+
+	_, err = editor.ParseScript("format-tables; frobnicate")
+	assert.ErrorContains(t, err, `unknown command "frobnicate"`)
+	_, err = editor.ParseScript("replace-all:only-one")
+	assert.ErrorContains(t, err, "wrong number of arguments")
+
+	ed := editor.New("x a:b\n\n|a|b|\n|-|-|\n|ccc|d|")
+	require.NoError(t, ed.RunScript("replace-all:a\\:b:c\\;d; format-tables; goto:1; insert:# "))
+	assert.Equal(t, "# x c;d\n\n| a   | b   |\n| --- | --- |\n| ccc | d   |", ed.Text())
+	assert.ErrorContains(t, ed.RunScript("update-toc"), "update-toc: no <!-- toc --> marker")
+}
@@ -0,0 +1,62 @@
+package unit
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/ofri/mde/internal/history"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHistory_SaveAndList(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(t.TempDir(), "notes.md")
+
+	snapshots, err := history.List(dir, file)
+	require.NoError(t, err)
+	assert.Empty(t, snapshots, "A file never saved has no history")
+
+	for _, text := range []string{"one", "two", "two", "three"} {
+		_, err := history.Save(dir, file, []byte(text), history.Limits{})
+		require.NoError(t, err)
+	}
+	snapshots, err = history.List(dir, file)
+	require.NoError(t, err)
+	require.Len(t, snapshots, 3, "Saving the same text again adds no snapshot")
+
+	var texts []string
+	for _, snapshot := range snapshots {
+		content, err := snapshot.Read()
+		require.NoError(t, err)
+		texts = append(texts, string(content))
+	}
+	assert.Equal(t, []string{"three", "two", "one"}, texts, "The latest comes first")
+	assert.Equal(t, int64(5), snapshots[0].Size)
+
+	other, err := history.List(dir, filepath.Join(t.TempDir(), "notes.md"))
+	require.NoError(t, err)
+	assert.Empty(t, other, "Files of the same name keep separate histories")
+}
+
+func TestHistory_Limits(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(t.TempDir(), "notes.md")
+
+	for _, text := range []string{"a", "b", "c", "d"} {
+		_, err := history.Save(dir, file, []byte(text), history.Limits{Count: 3})
+		require.NoError(t, err)
+	}
+	snapshots, err := history.List(dir, file)
+	require.NoError(t, err)
+	require.Len(t, snapshots, 3)
+	content, _ := snapshots[2].Read()
+	assert.Equal(t, "b", string(content), "The oldest go first")
+
+	added, err := history.Save(dir, file, []byte("a much longer text"), history.Limits{Bytes: 4})
+	require.NoError(t, err)
+	assert.True(t, added)
+	snapshots, err = history.List(dir, file)
+	require.NoError(t, err)
+	require.Len(t, snapshots, 1, "The latest snapshot is kept whatever its size")
+}
@@ -0,0 +1,86 @@
+package unit
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ofri/mde/internal/plugins/parsers"
+	"github.com/ofri/mde/internal/plugins/renderers"
+	"github.com/ofri/mde/pkg/ast"
+	"github.com/ofri/mde/pkg/plugin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHTML_FindTags(t *testing.T) {
+	tags := ast.FindHTMLTags(`a <span class="x">b</span><br/> <!-- c --> <https://x.io> ` + "`<div>`")
+	require.Len(t, tags, 4, "Autolinks and code spans are not HTML")
+
+	assert.Equal(t, "span", tags[0].Name)
+	assert.True(t, tags[1].Closing)
+	assert.True(t, tags[2].SelfClosing)
+	assert.True(t, tags[3].Comment)
+}
+
+func TestHTML_Blocks(t *testing.T) {
+	doc := ast.NewDocument("<div>\n*not emphasis*\n</div>\n\ntext <b>x</b>\n\n<!--\nnote\n-->\n<pre>\n\ncode\n</pre>\n```\n<div>\n```")
+	blocks := doc.HTMLBlocks()
+
+	assert.Equal(t, []ast.HTMLBlock{
+		{StartLine: 0, EndLine: 2},
+		{StartLine: 6, EndLine: 8},
+		{StartLine: 9, EndLine: 12, Raw: true},
+	}, blocks, "Inline tags in paragraphs and fenced code are not blocks")
+}
+
+func TestHTML_BlockAtFollowsEdits(t *testing.T) {
+	doc := ast.NewDocument("<div>\nx\n</div>\n\ntext")
+	block, ok := doc.HTMLBlockAt(1)
+	require.True(t, ok)
+	assert.Equal(t, 2, block.EndLine)
+	_, ok = doc.HTMLBlockAt(4)
+	assert.False(t, ok)
+
+	doc.InsertNewline(ast.BufferPos{Line: 0, Col: 0})
+	block, ok = doc.HTMLBlockAt(3)
+	require.True(t, ok)
+	assert.Equal(t, ast.HTMLBlock{StartLine: 1, EndLine: 3}, block)
+}
+
+func TestHTML_Tokens(t *testing.T) {
+	parser := parsers.NewCommonMarkParser()
+	tokens, err := parser.GetSyntaxHighlighting(context.Background(), "press <kbd>Ctrl</kbd>")
+	require.NoError(t, err)
+
+	var html int
+	for _, token := range tokens {
+		if token.Kind() == ast.TokenHTMLInline {
+			html++
+		}
+	}
+	assert.Equal(t, 2, html)
+
+	doc, err := parser.Parse(context.Background(), "<div>\n**x**\n</div>")
+	require.NoError(t, err)
+	lineTokens := doc.GetLineTokens(1)
+	require.Len(t, lineTokens, 1)
+	assert.Equal(t, ast.TokenHTMLBlock, lineTokens[0].Kind(), "Markdown inside HTML blocks is not highlighted")
+}
+
+func TestHTML_Preview(t *testing.T) {
+	renderer := renderers.NewTerminalRenderer()
+	lines, err := renderer.RenderPreviewVisible(context.Background(), &plugin.RenderContext{
+		Document: ast.NewDocument("Press <kbd>Ctrl</kbd> &amp; <em>go</em><br>\n\n<!--\nhidden\n-->\n<div align=\"center\">\n  Centered &copy;\n</div>"),
+		Viewport: ast.NewViewport(0, 0, 80, 10, 0, 9),
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, "Press Ctrl & go", lines[0].Content)
+	require.Len(t, lines[0].Styles, 3)
+	assert.True(t, lines[0].Styles[0].Style.Bold)
+	assert.True(t, lines[0].Styles[2].Style.Italic)
+
+	assert.Equal(t, "", lines[3].Content, "Comments are hidden")
+	assert.Equal(t, "", lines[5].Content)
+	assert.Equal(t, "Centered ©", lines[6].Content)
+}
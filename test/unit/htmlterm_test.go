@@ -0,0 +1,47 @@
+package unit
+
+import (
+	"testing"
+
+	"github.com/charmbracelet/x/ansi"
+	"github.com/ofri/mde/internal/htmlterm"
+	"github.com/ofri/mde/internal/tui"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func renderHTML(t *testing.T, content string) string {
+	t.Helper()
+	text, err := htmlterm.Render(content)
+	require.NoError(t, err)
+	return ansi.Strip(text)
+}
+
+func TestHTMLTerm_Blocks(t *testing.T) {
+	text := renderHTML(t, `<h2 id="intro">Intro &amp; <em>more</em></h2>
+<p class="lead">First <strong>bold <em>nested</em></strong> text.</p>
+<blockquote><p>Quoted</p><p>Twice</p></blockquote>
+<pre><code>  keep   spaces
+</code></pre>`)
+
+	assert.Equal(t, "## Intro & more\n\nFirst bold nested text.\n\n│ Quoted\n│\n│ Twice\n\n  keep   spaces", text)
+}
+
+func TestHTMLTerm_Lists(t *testing.T) {
+	text := renderHTML(t, `<ol start="3"><li>three<ul><li>inner</li></ul></li><li>four</li></ol><ul><li><input type="checkbox" checked disabled /> done</li></ul>`)
+	assert.Equal(t, "3. three\n   • inner\n4. four\n\n• ☑ done", text)
+}
+
+func TestHTMLTerm_LinksImagesTables(t *testing.T) {
+	text := renderHTML(t, `<p><a href="https://x.io" title="t">site</a> <a href="https://y.io">https://y.io</a> <img src="a.png" alt="logo"/></p>
+<table><thead><tr><th>Name</th><th>N</th></tr></thead><tbody><tr><td>a</td><td>10</td></tr></tbody></table>
+<script>alert(1)</script><!-- hidden -->`)
+
+	assert.Equal(t, "site (https://x.io) https://y.io [image: logo]\n\nName │ N\n─────┼───\na    │ 10", text)
+}
+
+func TestHTMLTerm_ModelConverter(t *testing.T) {
+	model := tui.New()
+	html := model.ConvertMarkdownToHTML("# Title\n\n- a\n- b")
+	assert.Equal(t, "# Title\n\n• a\n• b", ansi.Strip(model.ConvertHTMLToTerminalText(html)))
+}
@@ -0,0 +1,35 @@
+package unit
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ofri/mde/internal/journal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJournal_EnsureAndCapture(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "journal")
+	day := time.Date(2024, 3, 9, 0, 0, 0, 0, time.UTC)
+
+	path, created, err := journal.Ensure(dir, day, journal.DefaultContent(day))
+	require.NoError(t, err)
+	assert.True(t, created)
+	assert.Equal(t, filepath.Join(dir, "2024-03-09.md"), path)
+
+	_, created, err = journal.Ensure(dir, day, "ignored")
+	require.NoError(t, err)
+	assert.False(t, created, "Existing entries must not be overwritten")
+
+	at := time.Date(2024, 3, 9, 8, 5, 0, 0, time.UTC)
+	require.NoError(t, journal.Capture(path, at, "call the bank"))
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "# 2024-03-09 Saturday\n\n- 08:05 call the bank\n", string(data))
+
+	assert.Error(t, journal.Capture(path, at, "   "), "Empty captures are rejected")
+}
@@ -0,0 +1,80 @@
+package unit
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ofri/mde/pkg/ast"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLineIndex_RoundTrip(t *testing.T) {
+	doc := ast.NewDocument("one\n日本語\n\nlast 👍")
+	text := doc.GetText()
+	require.Equal(t, len(text), doc.TextLength())
+
+	for offset := 0; offset <= len(text); offset++ {
+		pos := doc.OffsetToPos(offset)
+		prefix := strings.Split(text[:doc.PosToOffset(pos)], "\n")
+		assert.Equal(t, pos.Line, len(prefix)-1, "offset %d", offset)
+		assert.Equal(t, pos.Col, len([]rune(prefix[len(prefix)-1])), "offset %d", offset)
+	}
+
+	assert.Equal(t, ast.BufferPos{Line: 1, Col: 1}, doc.OffsetToPos(7))
+	assert.Equal(t, 7, doc.PosToOffset(ast.BufferPos{Line: 1, Col: 1}))
+	assert.Equal(t, ast.BufferPos{Line: 2, Col: 0}, doc.OffsetToPos(14))
+	assert.Equal(t, ast.BufferPos{Line: 0, Col: 0}, doc.OffsetToPos(-5), "Clamped to the text")
+	assert.Equal(t, ast.BufferPos{Line: 3, Col: 6}, doc.OffsetToPos(999))
+	assert.Equal(t, len(text), doc.PosToOffset(ast.BufferPos{Line: 9, Col: 99}))
+}
+
+func TestLineIndex_FollowsEdits(t *testing.T) {
+	doc := ast.NewDocument("alpha\nbeta\ngamma")
+	assert.Equal(t, 11, doc.PosToOffset(ast.BufferPos{Line: 2, Col: 0}))
+
+	doc.InsertChar(ast.BufferPos{Line: 0, Col: 0}, 'é')
+	assert.Equal(t, 13, doc.PosToOffset(ast.BufferPos{Line: 2, Col: 0}))
+
+	doc.InsertNewline(ast.BufferPos{Line: 1, Col: 2})
+	assert.Equal(t, ast.BufferPos{Line: 3, Col: 1}, doc.OffsetToPos(strings.Index(doc.GetText(), "amma")))
+
+	doc.DeleteRange(ast.BufferPos{Line: 0, Col: 3}, ast.BufferPos{Line: 2, Col: 1})
+	assert.Equal(t, "éala\ngamma", doc.GetText())
+	assert.Equal(t, ast.BufferPos{Line: 1, Col: 2}, doc.OffsetToPos(strings.Index(doc.GetText(), "mma")))
+	assert.Equal(t, len(doc.GetText()), doc.TextLength())
+}
+
+func TestLineIndex_RuneOffsets(t *testing.T) {
+	doc := ast.NewDocument("one\n日本語\n\nlast 👍")
+	runes := []rune(doc.GetText())
+	for offset := 0; offset <= len(runes); offset++ {
+		pos := doc.RuneOffsetToPos(offset)
+		prefix := strings.Split(string(runes[:offset]), "\n")
+		assert.Equal(t, ast.BufferPos{Line: len(prefix) - 1, Col: len([]rune(prefix[len(prefix)-1]))}, pos, "offset %d", offset)
+		assert.Equal(t, offset, doc.PosToRuneOffset(pos))
+	}
+	assert.Equal(t, ast.BufferPos{Line: 3, Col: 6}, doc.RuneOffsetToPos(999), "Clamped to the text")
+
+	doc.InsertNewline(ast.BufferPos{Line: 1, Col: 1})
+	assert.Equal(t, ast.BufferPos{Line: 2, Col: 1}, doc.RuneOffsetToPos(7), "The index follows edits")
+}
+
+func TestLineIndex_FindAndReplace(t *testing.T) {
+	editor := ast.NewEditorWithContent("日本語 cat\nthe Cat sat")
+	editor.GetCursor().SetBufferPos(ast.BufferPos{Line: 0, Col: 5})
+
+	pos := editor.FindText("cat", true)
+	require.NotNil(t, pos)
+	assert.Equal(t, ast.BufferPos{Line: 0, Col: 4}, *pos, "Wraps around, in runes")
+
+	editor.GetCursor().SetBufferPos(ast.BufferPos{Line: 0, Col: 5})
+	pos = editor.FindText("cat", false)
+	require.NotNil(t, pos)
+	assert.Equal(t, ast.BufferPos{Line: 1, Col: 4}, *pos)
+
+	editor.GetCursor().SetBufferPos(ast.BufferPos{Line: 0, Col: 4})
+	assert.True(t, editor.ReplaceText("cat", "dog", true))
+	assert.Equal(t, "日本語 dog\nthe Cat sat", editor.GetDocument().GetText())
+	assert.False(t, editor.ReplaceText("cat", "dog", true), "No match at the cursor")
+}
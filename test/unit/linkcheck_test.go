@@ -0,0 +1,92 @@
+package unit
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ofri/mde/internal/linkcheck"
+	"github.com/ofri/mde/internal/lint"
+	"github.com/ofri/mde/pkg/ast"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLinkCheckTargets(t *testing.T) {
+	doc := ast.NewDocument("See [guide](guide.md#setup), ![logo](<img/my logo.png>) and https://example.com.\n" +
+		"[site]: https://example.org/page\n" +
+		"[top](#top) [mail](mailto:a@example.com) [root](/abs/path) [ref][site]\n" +
+		"```\n[code](missing.md)\n```\n")
+	targets := linkcheck.Targets(doc)
+	var urls []string
+	for _, target := range targets {
+		urls = append(urls, target.URL)
+	}
+	assert.Equal(t, []string{"guide.md#setup", "img/my logo.png", "https://example.com", "https://example.org/page"}, urls)
+	assert.Equal(t, ast.Range{Start: ast.BufferPos{Line: 0, Col: 4}, End: ast.BufferPos{Line: 0, Col: 27}}, targets[0].Range)
+}
+
+func TestLinkCheckLocalFiles(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "guide.md"), []byte("# Guide\n"), 0o644))
+	doc := ast.NewDocument("[ok](guide.md#setup) [gone](missing.md) [web](https://example.com)\n")
+
+	diagnostics := linkcheck.Check(context.Background(), linkcheck.Targets(doc), linkcheck.Options{Dir: dir})
+	require.Len(t, diagnostics, 1, "Web links are only requested when asked")
+	assert.Equal(t, lint.Error, diagnostics[0].Severity)
+	assert.Equal(t, "File not found: missing.md", diagnostics[0].Message)
+	assert.Equal(t, 21, diagnostics[0].Range.Start.Col)
+}
+
+func TestLinkCheckExternal(t *testing.T) {
+	var requests, inFlight, maxInFlight atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		n := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for {
+			m := maxInFlight.Load()
+			if n <= m || maxInFlight.CompareAndSwap(m, n) {
+				break
+			}
+		}
+		switch r.URL.Path {
+		case "/gone":
+			w.WriteHeader(http.StatusNotFound)
+		case "/get-only":
+			if r.Method == http.MethodHead {
+				w.WriteHeader(http.StatusMethodNotAllowed)
+			}
+		case "/slow":
+			select {
+			case <-time.After(2 * time.Second):
+			case <-r.Context().Done():
+			}
+		default:
+			time.Sleep(20 * time.Millisecond)
+		}
+	}))
+	defer server.Close()
+
+	doc := ast.NewDocument("[a](" + server.URL + "/ok) [b](" + server.URL + "/gone) [c](" + server.URL + "/get-only)\n" +
+		"[d](" + server.URL + "/slow) [e](" + server.URL + "/ok) [f](" + server.URL + "/one) [g](" + server.URL + "/two)\n")
+	diagnostics := linkcheck.Check(context.Background(), linkcheck.Targets(doc), linkcheck.Options{
+		External:    true,
+		Concurrency: 2,
+		Timeout:     200 * time.Millisecond,
+	})
+
+	require.Len(t, diagnostics, 2)
+	assert.Equal(t, lint.Error, diagnostics[0].Severity)
+	assert.Equal(t, server.URL+"/gone returned 404 Not Found", diagnostics[0].Message)
+	assert.Equal(t, lint.Warning, diagnostics[1].Severity)
+	assert.Equal(t, server.URL+"/slow timed out after 200ms", diagnostics[1].Message)
+	assert.Equal(t, 1, diagnostics[1].Range.Start.Line)
+	assert.LessOrEqual(t, maxInFlight.Load(), int32(2))
+	assert.Equal(t, int32(8), requests.Load(), "Each URL once, a GET after a refused HEAD, a 404 retried")
+}
@@ -0,0 +1,146 @@
+package unit
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ofri/mde/internal/links"
+	"github.com/ofri/mde/internal/plugins/parsers"
+	"github.com/ofri/mde/internal/plugins/renderers"
+	"github.com/ofri/mde/pkg/ast"
+	"github.com/ofri/mde/pkg/plugin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLinks_FindKinds(t *testing.T) {
+	tests := []struct {
+		line string
+		kind links.Kind
+		text string
+		url  string
+	}{
+		{"see [docs](https://example.com) now", links.Inline, "docs", "https://example.com"},
+		{"see [docs][ref] now", links.Reference, "docs", ""},
+		{"[ref]: https://example.com/ref", links.Definition, "ref", "https://example.com/ref"},
+		{"mail <me@example.com>", links.Autolink, "me@example.com", "mailto:me@example.com"},
+		{"go to https://example.com/a.", links.Bare, "https://example.com/a", "https://example.com/a"},
+		{"(www.example.com)", links.Bare, "www.example.com", "http://www.example.com"},
+	}
+
+	for _, tt := range tests {
+		found := links.Find(tt.line)
+		require.Len(t, found, 1, tt.line)
+		assert.Equal(t, tt.kind, found[0].Kind, tt.line)
+		assert.Equal(t, tt.text, found[0].Text(tt.line), tt.line)
+		assert.Equal(t, tt.url, found[0].URL, tt.line)
+	}
+}
+
+func TestLinks_NestedAndImages(t *testing.T) {
+	assert.Len(t, links.Find("[site](https://example.com/x)"), 1, "The URL of an inline link is not also a bare link")
+	assert.Empty(t, links.Find("![alt](image.png)"), "Images are not links")
+}
+
+func TestLinks_ReferenceResolution(t *testing.T) {
+	found := links.Find("read [The Guide][] first")
+	require.Len(t, found, 1)
+	assert.Equal(t, "The Guide", found[0].Label, "Collapsed references use their text")
+
+	defs := links.Definitions([]string{
+		"[the   guide]: <guide.md>",
+		"[THE GUIDE]: ignored.md",
+	})
+	assert.Equal(t, "guide.md", defs[links.NormalizeLabel(found[0].Label)], "Labels match case-insensitively; first wins")
+}
+
+func TestLinks_At(t *testing.T) {
+	line := "café [x](a.md) b"
+	link, ok := links.At(line, 6)
+	require.True(t, ok)
+	assert.Equal(t, "a.md", link.URL)
+
+	_, ok = links.At(line, 2)
+	assert.False(t, ok)
+}
+
+func TestLinks_Tokens(t *testing.T) {
+	parser := parsers.NewCommonMarkParser()
+	tokens, err := parser.GetSyntaxHighlighting(context.Background(), "[a][b] <https://x.io> https://y.io")
+	require.NoError(t, err)
+
+	kinds := map[ast.TokenKind]bool{}
+	for _, token := range tokens {
+		kinds[token.Kind()] = true
+	}
+	assert.True(t, kinds[ast.TokenLinkReference])
+	assert.True(t, kinds[ast.TokenAutolink])
+
+	tokens, err = parser.GetSyntaxHighlighting(context.Background(), "[b]: https://x.io")
+	require.NoError(t, err)
+	kinds = map[ast.TokenKind]bool{}
+	for _, token := range tokens {
+		kinds[token.Kind()] = true
+	}
+	assert.True(t, kinds[ast.TokenLinkDefinition])
+	assert.True(t, kinds[ast.TokenLinkURL])
+}
+
+func TestLinks_Preview(t *testing.T) {
+	renderer := renderers.NewTerminalRenderer()
+	lines, err := renderer.RenderPreviewVisible(context.Background(), &plugin.RenderContext{
+		Document: ast.NewDocument("see [docs][ref] and <https://x.io>\n\n[ref]: https://example.com"),
+		Viewport: ast.NewViewport(0, 0, 80, 5, 0, 4),
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, "see docs and https://x.io", lines[0].Content)
+	require.Len(t, lines[0].Styles, 2)
+	assert.True(t, lines[0].Styles[0].Style.Underline)
+	assert.Equal(t, "[ref]: https://example.com", lines[2].Content, "Definitions stay visible, dimmed")
+}
+
+func TestLinks_Footnotes(t *testing.T) {
+	found := links.Find("text[^1] and [^note]")
+	require.Len(t, found, 2)
+	assert.Equal(t, links.FootnoteRef, found[0].Kind)
+	assert.Equal(t, "1", found[0].Label)
+	assert.Equal(t, "note", found[1].Label)
+
+	def := links.Find("[^1]: The first note")
+	require.Len(t, def, 1)
+	assert.Equal(t, links.FootnoteDefinition, def[0].Kind)
+	assert.Empty(t, links.Definitions([]string{"[^1]: The first note"}), "Footnotes are not link definitions")
+
+	notes := links.Footnotes([]string{"[^1]: The first", "    continued here", "", "[^2]: Second"})
+	assert.Equal(t, "The first continued here", notes["1"])
+	assert.Equal(t, "Second", notes["2"])
+	assert.Equal(t, 3, links.FootnoteLine([]string{"a[^2]", "", "[^1]: x", "[^2]: y"}, "2"))
+}
+
+func TestFetchTitle(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/page":
+			w.Write([]byte("<html><head><title>\n  Fish &amp; Chips\n</title></head><body><title>no</title></body></html>"))
+		case "/bare":
+			w.Write([]byte("<p>No title here</p>"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	title, err := links.FetchTitle(context.Background(), server.URL+"/page")
+	require.NoError(t, err)
+	assert.Equal(t, "Fish & Chips", title)
+
+	title, err = links.FetchTitle(context.Background(), server.URL+"/bare")
+	require.NoError(t, err)
+	assert.Empty(t, title)
+
+	_, err = links.FetchTitle(context.Background(), server.URL+"/missing")
+	assert.Error(t, err)
+}
@@ -0,0 +1,53 @@
+package unit
+
+import (
+	"testing"
+
+	"github.com/ofri/mde/internal/lint"
+	"github.com/ofri/mde/pkg/ast"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLintCheck(t *testing.T) {
+	doc := ast.NewDocument("# Intro\n[ok](#intro) [gone](#outro) [ref][nope] [é][x]\nNote[^1]\n\n[x]: /x\n```\n[code](#nowhere)")
+
+	assert.Equal(t, []lint.Diagnostic{
+		{
+			Range:    ast.Range{Start: ast.BufferPos{Line: 1, Col: 13}, End: ast.BufferPos{Line: 1, Col: 27}},
+			Severity: lint.Error,
+			Message:  "No heading with anchor #outro",
+			Rule:     lint.RuleHeadingAnchor,
+		},
+		{
+			Range:    ast.Range{Start: ast.BufferPos{Line: 1, Col: 28}, End: ast.BufferPos{Line: 1, Col: 39}},
+			Severity: lint.Error,
+			Message:  "Undefined link reference [nope]",
+			Rule:     lint.RuleLinkReference,
+		},
+		{
+			Range:    ast.Range{Start: ast.BufferPos{Line: 2, Col: 4}, End: ast.BufferPos{Line: 2, Col: 8}},
+			Severity: lint.Error,
+			Message:  "Undefined footnote [^1]",
+			Rule:     lint.RuleFootnote,
+		},
+		{
+			Range:    ast.Range{Start: ast.BufferPos{Line: 5}, End: ast.BufferPos{Line: 5, Col: 3}},
+			Severity: lint.Warning,
+			Message:  "Code fence is never closed",
+			Rule:     lint.RuleOpenFence,
+		},
+	}, lint.Check(doc))
+}
+
+func TestLintFilter(t *testing.T) {
+	doc := ast.NewDocument("[gone](#outro) [ref][nope]\n```")
+	diagnostics := lint.Check(doc)
+	require.Len(t, diagnostics, 3)
+
+	assert.Equal(t, diagnostics, lint.Filter(diagnostics, nil))
+	kept := lint.Filter(diagnostics, map[string]bool{lint.RuleHeadingAnchor: false, lint.RuleOpenFence: true})
+	require.Len(t, kept, 2, "Rules turned off report nothing; those not listed stay on")
+	assert.Equal(t, lint.RuleLinkReference, kept[0].Rule)
+	assert.Equal(t, lint.RuleOpenFence, kept[1].Rule)
+}
@@ -0,0 +1,36 @@
+package unit
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ofri/mde/pkg/ast"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVisibleWindow(t *testing.T) {
+	tests := []struct {
+		line        string
+		left, width int
+		want        string
+	}{
+		{"abcdef", 0, 3, "abc"},
+		{"abcdef", 2, 3, "cde"},
+		{"abcdef", 4, 10, "ef"},
+		{"abcdef", 10, 3, ""},
+		{"a\tb", 0, 8, "a   b"},
+		{"a\tb", 2, 8, "  b"},
+		{"a\tb", 0, 2, "a "},
+		{"héllo wörld", 6, 5, "wörld"},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, ast.VisibleWindow(tt.line, tt.left, tt.width, 4), "%q from %d", tt.line, tt.left)
+	}
+}
+
+func TestIsLongLine(t *testing.T) {
+	doc := ast.NewDocument("short\n" + strings.Repeat("é", ast.LongLineRunes))
+	assert.False(t, doc.IsLongLine(0))
+	assert.True(t, doc.IsLongLine(1), "Length counts runes")
+	assert.False(t, doc.IsLongLine(5))
+}
@@ -0,0 +1,161 @@
+package unit
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/textproto"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/ofri/mde/internal/lsp"
+	"github.com/ofri/mde/pkg/ast"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeLanguageServer answers the requests the client makes on conn with
+// canned results, publishing one diagnostic for each opened document.
+func fakeLanguageServer(t *testing.T, conn net.Conn) {
+	r := bufio.NewReader(conn)
+	write := func(msg map[string]any) {
+		msg["jsonrpc"] = "2.0"
+		body, err := json.Marshal(msg)
+		require.NoError(t, err)
+		fmt.Fprintf(conn, "Content-Length: %d\r\n\r\n%s", len(body), body)
+	}
+	for {
+		header, err := textproto.NewReader(r).ReadMIMEHeader()
+		if err != nil {
+			return
+		}
+		length, _ := strconv.Atoi(header.Get("Content-Length"))
+		body := make([]byte, length)
+		if _, err := io.ReadFull(r, body); err != nil {
+			return
+		}
+		var msg struct {
+			ID     json.RawMessage `json:"id"`
+			Method string          `json:"method"`
+			Params json.RawMessage `json:"params"`
+		}
+		require.NoError(t, json.Unmarshal(body, &msg))
+
+		var result any
+		switch msg.Method {
+		case "initialize":
+			// Servers may ask the client things before answering
+			write(map[string]any{"id": "cfg", "method": "workspace/configuration",
+				"params": map[string]any{"items": []any{map[string]any{}}}})
+			result = map[string]any{"capabilities": map[string]any{}}
+		case "textDocument/didOpen":
+			var p struct {
+				TextDocument struct {
+					URI string `json:"uri"`
+				} `json:"textDocument"`
+			}
+			json.Unmarshal(msg.Params, &p)
+			write(map[string]any{"method": "textDocument/publishDiagnostics", "params": map[string]any{
+				"uri": p.TextDocument.URI,
+				"diagnostics": []any{map[string]any{
+					"range":    map[string]any{"start": map[string]int{"line": 0, "character": 4}, "end": map[string]int{"line": 0, "character": 9}},
+					"severity": 2,
+					"message":  "Unknown reference",
+				}},
+			}})
+		case "textDocument/completion":
+			result = map[string]any{"isIncomplete": false, "items": []any{
+				map[string]any{"label": "#intro"},
+				map[string]any{"label": "#usage", "textEdit": map[string]any{
+					"range":   map[string]any{"start": map[string]int{"line": 0, "character": 1}, "end": map[string]int{"line": 0, "character": 2}},
+					"newText": "#usage",
+				}},
+			}}
+		case "textDocument/hover":
+			result = map[string]any{"contents": map[string]any{"kind": "markdown", "value": "# Usage"}}
+		case "textDocument/rename":
+			var p struct {
+				TextDocument struct {
+					URI string `json:"uri"`
+				} `json:"textDocument"`
+				NewName string `json:"newName"`
+			}
+			json.Unmarshal(msg.Params, &p)
+			result = map[string]any{"changes": map[string]any{p.TextDocument.URI: []any{map[string]any{
+				"range":   map[string]any{"start": map[string]int{"line": 0, "character": 1}, "end": map[string]int{"line": 0, "character": 4}},
+				"newText": p.NewName,
+			}}}}
+		}
+		if msg.ID != nil && msg.Method != "" {
+			write(map[string]any{"id": msg.ID, "result": result})
+		}
+	}
+}
+
+func TestLSPClient(t *testing.T) {
+	client, server := net.Pipe()
+	go fakeLanguageServer(t, server)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	c, err := lsp.NewClient(ctx, client, t.TempDir())
+	require.NoError(t, err)
+
+	path := "/notes/readme.md"
+	require.NoError(t, c.DidOpen(path, "markdown", "See [foo][bar]"))
+	select {
+	case <-c.Changed():
+	case <-ctx.Done():
+		t.Fatal("No diagnostics published")
+	}
+	diagnostics := c.Diagnostics(path)
+	require.Len(t, diagnostics, 1)
+	assert.Equal(t, lsp.SeverityWarning, diagnostics[0].Severity)
+	assert.Equal(t, "warning", diagnostics[0].Severity.String())
+	assert.Equal(t, "Unknown reference", diagnostics[0].Message)
+	assert.Empty(t, c.Diagnostics("/notes/other.md"))
+
+	items, err := c.Completion(ctx, path, lsp.Position{Line: 0, Character: 2})
+	require.NoError(t, err)
+	require.Len(t, items, 2)
+	assert.Equal(t, "#intro", items[0].Label)
+	require.NotNil(t, items[1].TextEdit)
+	assert.Equal(t, "#usage", items[1].TextEdit.NewText)
+
+	text, err := c.Hover(ctx, path, lsp.Position{})
+	require.NoError(t, err)
+	assert.Equal(t, "# Usage", text)
+
+	edit, err := c.Rename(ctx, path, lsp.Position{Line: 0, Character: 2}, "baz")
+	require.NoError(t, err)
+	edits := edit.Edits(lsp.FileURI(path))
+	require.Len(t, edits, 1)
+	assert.Equal(t, "baz", edits[0].NewText)
+	assert.Empty(t, edit.Edits(lsp.FileURI("/notes/other.md")))
+
+	require.NoError(t, c.Shutdown(ctx))
+	<-c.Done()
+	_, err = c.Hover(ctx, path, lsp.Position{})
+	assert.ErrorIs(t, err, lsp.ErrClosed, "Calls after shutdown fail")
+}
+
+func TestLSPPositions(t *testing.T) {
+	line := "a😀é|b"
+	// The emoji takes two UTF-16 units, the rest one each
+	assert.Equal(t, lsp.Position{Line: 3, Character: 0}, lsp.ToPosition(3, line, 0))
+	assert.Equal(t, 3, lsp.ToPosition(0, line, 2).Character)
+	assert.Equal(t, 6, lsp.ToPosition(0, line, 5).Character)
+	assert.Equal(t, 6, lsp.ToPosition(0, line, 99).Character, "Columns past the end clamp")
+
+	assert.Equal(t, 2, lsp.FromPosition(line, lsp.Position{Character: 3}))
+	assert.Equal(t, 5, lsp.FromPosition(line, lsp.Position{Character: 6}))
+	assert.Equal(t, 5, lsp.FromPosition(line, lsp.Position{Character: 40}))
+
+	doc := ast.NewDocument("one\ntwo😀")
+	assert.Equal(t, ast.BufferPos{Line: 1, Col: 4}, lsp.BufferPos(doc, lsp.Position{Line: 1, Character: 5}))
+	assert.Equal(t, ast.BufferPos{Line: 1, Col: 4}, lsp.BufferPos(doc, lsp.Position{Line: 7}), "Lines past the end clamp")
+}
@@ -0,0 +1,95 @@
+package unit
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ofri/mde/internal/mathtext"
+	"github.com/ofri/mde/internal/plugins/parsers"
+	"github.com/ofri/mde/internal/plugins/renderers"
+	"github.com/ofri/mde/pkg/ast"
+	"github.com/ofri/mde/pkg/plugin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMath_FindSpans(t *testing.T) {
+	line := "Let $x^2$ and $$E = mc^2$$ hold"
+	spans := mathtext.FindSpans(line)
+	require.Len(t, spans, 2)
+
+	assert.Equal(t, mathtext.Span{Start: 4, End: 9}, spans[0])
+	assert.Equal(t, "x^2", spans[0].Inner(line))
+	assert.True(t, spans[1].Display)
+	assert.Equal(t, "E = mc^2", spans[1].Inner(line))
+
+	// Prose dollars are not math
+	assert.Empty(t, mathtext.FindSpans("costs $5 and $10 today"))
+	assert.Empty(t, mathtext.FindSpans("a $ b $ c"))
+	assert.Empty(t, mathtext.FindSpans(`escaped \$x$ here`))
+	assert.Empty(t, mathtext.FindSpans("code `$x$` span"))
+	assert.Empty(t, mathtext.FindSpans("$$"))
+}
+
+func TestMath_ToUnicode(t *testing.T) {
+	cases := map[string]string{
+		`\alpha^2 + \beta_1`:  "α² + β₁",
+		`\frac{a+b}{2}`:       "(a+b)/2",
+		`\sqrt{x} \le \infty`: "√x ≤ ∞",
+		`x^{n+1}`:             "xⁿ⁺¹",
+		`e^{i\pi}`:            "e^(iπ)",
+		`\sum_{i=0}^n i`:      "∑ᵢ₌₀ⁿ i",
+		`\text{if } x \in A`:  "if  x ∈ A",
+		`\unknown{x}`:         `\unknownx`,
+	}
+	for tex, want := range cases {
+		assert.Equal(t, want, mathtext.ToUnicode(tex), tex)
+	}
+}
+
+func TestMath_ParserTokens(t *testing.T) {
+	parser := parsers.NewCommonMarkParser()
+
+	tokens, err := parser.GetSyntaxHighlighting(context.Background(), "see $x$")
+	require.NoError(t, err)
+	var kinds []ast.TokenKind
+	for _, token := range tokens {
+		kinds = append(kinds, token.Kind())
+	}
+	assert.Equal(t, []ast.TokenKind{ast.TokenDelimiter, ast.TokenMath, ast.TokenDelimiter}, kinds)
+
+	doc, err := parser.Parse(context.Background(), "$$\n- a + b\n$$\n")
+	require.NoError(t, err)
+	body := doc.GetLineTokens(1)
+	require.Len(t, body, 1, "Display block bodies are math, not list items")
+	assert.Equal(t, ast.TokenMathBlock, body[0].Kind())
+}
+
+func TestMath_PreviewRendering(t *testing.T) {
+	doc := ast.NewDocument("Area $\\pi r^2$ here\n$$\n\\alpha \\ne \\beta\n$$\nafter")
+	renderer := renderers.NewTerminalRenderer()
+
+	render := func() []plugin.RenderedLine {
+		lines, err := renderer.RenderPreviewVisible(context.Background(), &plugin.RenderContext{
+			Document: doc,
+			Viewport: ast.NewViewport(0, 0, 80, 10, 0, 4),
+		})
+		require.NoError(t, err)
+		return lines
+	}
+
+	lines := render()
+	assert.Equal(t, `Area \pi r^2 here`, lines[0].Content, "Delimiters are hidden; source shown verbatim by default")
+	require.Len(t, lines[0].Styles, 1)
+	assert.Equal(t, 5, lines[0].Styles[0].Start)
+	assert.Equal(t, 12, lines[0].Styles[0].End)
+	assert.True(t, lines[0].Styles[0].Style.Italic)
+	assert.Equal(t, "", lines[1].Content)
+	assert.Equal(t, `    \alpha \ne \beta`, lines[2].Content)
+
+	require.NoError(t, renderer.Configure(map[string]interface{}{"mathUnicode": true}))
+	lines = render()
+	assert.Equal(t, "Area π r² here", lines[0].Content)
+	assert.Equal(t, "    α ≠ β", lines[2].Content)
+	assert.Equal(t, "after", lines[4].Content)
+}
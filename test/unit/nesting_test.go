@@ -0,0 +1,72 @@
+package unit
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ofri/mde/internal/plugins/parsers"
+	"github.com/ofri/mde/internal/plugins/renderers"
+	"github.com/ofri/mde/pkg/ast"
+	"github.com/ofri/mde/pkg/plugin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNesting_QuoteDepth(t *testing.T) {
+	depth, markers, content := ast.QuoteDepth(">> deep quote")
+	assert.Equal(t, 2, depth)
+	assert.Equal(t, []int{0, 1}, markers)
+	assert.Equal(t, 3, content)
+
+	depth, markers, _ = ast.QuoteDepth("> > > spaced")
+	assert.Equal(t, 3, depth)
+	assert.Equal(t, []int{0, 2, 4}, markers)
+
+	depth, _, _ = ast.QuoteDepth("not > a quote")
+	assert.Zero(t, depth)
+}
+
+func TestNesting_ListDepths(t *testing.T) {
+	doc := ast.NewDocument("- a\n    - b\n        1. c\n    - d\n- e\n\ntext\n  - f\n**bold** line")
+	assert.Equal(t, []int{1, 2, 3, 2, 1, 0, 0, 1, 0}, doc.ListDepths(),
+		"4-space nesting counts one level per step; text resets the list")
+
+	for i, want := range doc.ListDepths() {
+		assert.Equal(t, want, doc.ListDepth(i), "line %d", i)
+	}
+}
+
+func TestNesting_ParserTokens(t *testing.T) {
+	parser := parsers.NewCommonMarkParser()
+	doc, err := parser.Parse(context.Background(), ">> quoted\n- a\n    - b")
+	require.NoError(t, err)
+
+	depthOf := func(line int, kind ast.TokenKind) int {
+		for _, token := range doc.GetLineTokens(line) {
+			if token.Kind() == kind {
+				return token.Depth()
+			}
+		}
+		return -1
+	}
+	assert.Equal(t, 2, depthOf(0, ast.TokenQuote))
+	assert.Equal(t, 1, depthOf(1, ast.TokenList))
+	assert.Equal(t, 2, depthOf(2, ast.TokenList), "Parse corrects the indentation estimate")
+}
+
+func TestNesting_Preview(t *testing.T) {
+	renderer := renderers.NewTerminalRenderer()
+	lines, err := renderer.RenderPreviewVisible(context.Background(), &plugin.RenderContext{
+		Document: ast.NewDocument("> top\n>> deep\n- a\n  - b\n    1. c"),
+		Viewport: ast.NewViewport(0, 0, 80, 10, 0, 4),
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, "│ top", lines[0].Content)
+	assert.Equal(t, "│ │ deep", lines[1].Content)
+	assert.NotEqual(t, lines[1].Styles[0].Style.Foreground, lines[1].Styles[1].Style.Foreground,
+		"Each quote level has its own color")
+	assert.Equal(t, "  • a", lines[2].Content)
+	assert.Equal(t, "    ◦ b", lines[3].Content)
+	assert.Equal(t, "      1. c", lines[4].Content)
+}
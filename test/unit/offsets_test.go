@@ -0,0 +1,92 @@
+package unit
+
+import (
+	"context"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/ofri/mde/internal/plugins/parsers"
+	"github.com/ofri/mde/internal/plugins/renderers"
+	"github.com/ofri/mde/pkg/ast"
+	"github.com/ofri/mde/pkg/plugin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOffsets_Conversion(t *testing.T) {
+	line := "日本👍é!"
+	bytes := []int{0, 3, 6, 10, 12, 13}
+	for col, offset := range bytes {
+		assert.Equal(t, offset, ast.ByteOffset(line, col), "rune %d", col)
+		assert.Equal(t, col, ast.RuneOffset(line, offset), "byte %d", offset)
+	}
+
+	assert.Equal(t, len(line), ast.ByteOffset(line, 99), "Clamped to the line")
+	assert.Equal(t, 5, ast.RuneOffset(line, 99))
+	assert.Equal(t, 0, ast.RuneOffset(line, -1))
+	assert.Equal(t, 3, ast.RuneOffset(line, 7), "Inside a character counts that character")
+}
+
+func TestOffsets_TokensAreRunes(t *testing.T) {
+	parser := parsers.NewCommonMarkParser()
+	line := "日本語の**太字**と`コード`と:smile:"
+	tokens, err := parser.GetSyntaxHighlighting(context.Background(), line)
+	require.NoError(t, err)
+
+	runes := []rune(line)
+	text := map[ast.TokenKind]string{}
+	for _, token := range tokens {
+		require.LessOrEqual(t, token.End(), len(runes))
+		text[token.Kind()] = string(runes[token.Start():token.End()])
+	}
+	assert.Equal(t, "**太字**", text[ast.TokenBold])
+	assert.Equal(t, "`コード`", text[ast.TokenCode])
+	assert.Equal(t, ":smile:", text[ast.TokenEmoji])
+
+	doc, err := parser.Parse(context.Background(), "見出し\n===")
+	require.NoError(t, err)
+	heading := doc.GetLineTokens(0)[0]
+	assert.Equal(t, ast.TokenHeading, heading.Kind())
+	assert.Equal(t, 3, heading.End(), "Whole-line tokens end at the rune length")
+}
+
+func TestOffsets_RenderLineStylesMatchTokens(t *testing.T) {
+	parser := parsers.NewCommonMarkParser()
+	renderer := renderers.NewTerminalRenderer()
+	line := "👍 ~~消す~~ ok"
+	tokens, err := parser.GetSyntaxHighlighting(context.Background(), line)
+	require.NoError(t, err)
+
+	rendered, err := renderer.RenderLine(context.Background(), line, tokens)
+	require.NoError(t, err)
+	runes := []rune(rendered.Content)
+	var styled []string
+	for _, style := range rendered.Styles {
+		styled = append(styled, string(runes[style.Start:style.End]))
+	}
+	assert.Contains(t, styled, "消す")
+}
+
+func TestOffsets_PreviewHeadingCoversMultibyteLine(t *testing.T) {
+	renderer := renderers.NewTerminalRenderer()
+	lines, err := renderer.RenderPreviewVisible(context.Background(), &plugin.RenderContext{
+		Document: ast.NewDocument("# 見出し"),
+		Viewport: ast.NewViewport(0, 0, 80, 5, 0, 4),
+	})
+	require.NoError(t, err)
+	require.NotEmpty(t, lines[0].Styles)
+	assert.Equal(t, utf8.RuneCountInString(lines[0].Content), lines[0].Styles[0].End)
+}
+
+func TestOffsets_HorizontalScrollKeepsCharactersWhole(t *testing.T) {
+	renderer := renderers.NewTerminalRenderer()
+	lines, err := renderer.RenderVisible(context.Background(), &plugin.RenderContext{
+		Document:        ast.NewDocument("日本語のテキスト"),
+		Viewport:        ast.NewViewport(0, 2, 80, 5, 4, 4),
+		ShowLineNumbers: true,
+	})
+	require.NoError(t, err)
+	require.Len(t, lines, 1)
+	assert.True(t, utf8.ValidString(lines[0].Content))
+	assert.Equal(t, " 1│ 語のテキスト", lines[0].Content)
+}
@@ -0,0 +1,48 @@
+package unit
+
+import (
+	"testing"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+	"github.com/ofri/mde/internal/plugins/renderers"
+	"github.com/ofri/mde/pkg/plugin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStyles_OverlapsAreComposited(t *testing.T) {
+	profile := lipgloss.ColorProfile()
+	lipgloss.SetColorProfile(termenv.ANSI)
+	t.Cleanup(func() { lipgloss.SetColorProfile(profile) })
+
+	renderer := renderers.NewTerminalRenderer()
+	render := func(content string, styles []plugin.StyleRange) string {
+		return renderer.RenderToString([]plugin.RenderedLine{{Content: content, Styles: styles}})
+	}
+	styled := func(style plugin.Style, text string) string {
+		return style.ToLipgloss().Render(text)
+	}
+
+	link := plugin.Style{Foreground: "4", Underline: true}
+	bold := plugin.Style{Bold: true}
+	boldLink := plugin.Style{Foreground: "4", Underline: true, Bold: true}
+	want := styled(link, "see ") + styled(boldLink, "**bold**") + styled(link, " link")
+	assert.Equal(t, want, render("see **bold** link", []plugin.StyleRange{
+		{Start: 4, End: 12, Style: bold},
+		{Start: 0, End: 17, Style: link},
+	}), "Bold inside a link is drawn in both, whatever the order")
+
+	red := plugin.Style{Foreground: "1"}
+	green := plugin.Style{Foreground: "2", Italic: true}
+	assert.Equal(t, styled(red, "ab")+styled(plugin.Style{Foreground: "2", Italic: true}, "cd")+styled(green, "ef")+"g",
+		render("abcdefg", []plugin.StyleRange{{Start: 0, End: 4, Style: red}, {Start: 2, End: 6, Style: green}}),
+		"The later of two crossing ranges is drawn over the earlier")
+
+	reversed := plugin.Style{Reverse: true}
+	selection := renderers.Overlay(nil, 0, 4, reversed)
+	withCursor := renderers.Overlay(selection, 1, 2, reversed)
+	assert.Len(t, selection, 1, "Overlays are layered when drawn")
+	assert.Equal(t, styled(reversed, "a")+"b"+styled(reversed, "cd")+"e", render("abcde", withCursor),
+		"The cursor shows inside a reversed selection")
+	assert.Equal(t, "plain", render("plain", renderers.Overlay(nil, 2, 2, reversed)))
+}
@@ -0,0 +1,35 @@
+package unit
+
+import (
+	"testing"
+
+	"github.com/ofri/mde/pkg/ast"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOverwrite_ReplacesCharacters(t *testing.T) {
+	editor := ast.NewEditorWithContent("cat\nend")
+	editor.SetOverwriteMode(true)
+
+	editor.TypeText("b")
+	assert.Equal(t, "bat\nend", editor.GetDocument().GetText())
+
+	editor.TypeText("oots")
+	assert.Equal(t, "boots\nend", editor.GetDocument().GetText(), "Typing past the line end appends without joining lines")
+	assert.Equal(t, ast.BufferPos{Line: 0, Col: 5}, editor.GetCursorBufferPosition())
+}
+
+func TestOverwrite_InsertModeAndSelection(t *testing.T) {
+	editor := ast.NewEditorWithContent("cat")
+	editor.TypeText("b")
+	assert.Equal(t, "bcat", editor.GetDocument().GetText(), "Insert mode is the default")
+
+	editor.SetOverwriteMode(true)
+	editor.GetCursor().SetBufferPos(ast.BufferPos{Line: 0, Col: 1})
+	editor.GetCursor().StartSelection()
+	editor.GetCursor().SetBufferPos(ast.BufferPos{Line: 0, Col: 3})
+	editor.GetCursor().ExtendSelection()
+
+	editor.TypeText("x")
+	assert.Equal(t, "bxt", editor.GetDocument().GetText(), "A selection is replaced, not the text after it")
+}
@@ -0,0 +1,83 @@
+package unit
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ofri/mde/internal/pandoc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakePandoc writes a script that behaves like pandoc enough for tests:
+// it logs to stderr, copies stdin to --output and records its arguments
+// next to it. Converting to "bogus" fails.
+func fakePandoc(t *testing.T) string {
+	path := filepath.Join(t.TempDir(), "pandoc")
+	script := `#!/bin/sh
+all="$*"
+while [ $# -gt 0 ]; do
+	case "$1" in
+	--to) to="$2"; shift ;;
+	--output) out="$2"; shift ;;
+	esac
+	shift
+done
+echo "[INFO] Running for $to" >&2
+if [ "$to" = bogus ]; then
+	echo "[WARNING] Something odd" >&2
+	echo "Unknown output format bogus" >&2
+	exit 22
+fi
+cat > "$out"
+echo "$all" > "$out.args"
+`
+	require.NoError(t, os.WriteFile(path, []byte(script), 0o755))
+	return path
+}
+
+func TestPandocConvert(t *testing.T) {
+	command := fakePandoc(t)
+	dir := t.TempDir()
+	output := filepath.Join(dir, "notes.docx")
+
+	var progress []string
+	err := pandoc.Convert(context.Background(), "# Notes\n", pandoc.Options{
+		Command: command,
+		To:      "docx",
+		Output:  output,
+		Dir:     dir,
+		Args:    []string{"--toc"},
+	}, func(line string) {
+		progress = append(progress, line)
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"[INFO] Running for docx"}, progress, "The log streams to progress")
+
+	data, err := os.ReadFile(output)
+	require.NoError(t, err)
+	assert.Equal(t, "# Notes\n", string(data))
+	args, err := os.ReadFile(output + ".args")
+	require.NoError(t, err)
+	assert.True(t, strings.HasSuffix(strings.TrimSpace(string(args)), "--toc"), "Configured options come last: %s", args)
+
+	err = pandoc.Convert(context.Background(), "x", pandoc.Options{Command: command, To: "bogus", Output: output}, nil)
+	assert.EqualError(t, err, "pandoc: [WARNING] Something odd; Unknown output format bogus", "Failures report pandoc's messages")
+
+	err = pandoc.Convert(context.Background(), "x", pandoc.Options{Command: filepath.Join(dir, "missing"), To: "docx", Output: output}, nil)
+	assert.Error(t, err)
+}
+
+func TestPandocPaths(t *testing.T) {
+	assert.Equal(t, "docs/notes.docx", pandoc.OutputPath("docs/notes.md", "docx"))
+	assert.Equal(t, "notes.tex", pandoc.OutputPath("notes.md", "latex"))
+	assert.Equal(t, "notes.epub", pandoc.OutputPath("notes", "epub"))
+
+	args := map[string][]string{"all": {"--toc"}, "docx": {"--reference-doc=ref.docx"}}
+	assert.Equal(t, []string{"--toc", "--reference-doc=ref.docx"}, pandoc.ArgsFor(args, "docx"))
+	assert.Equal(t, []string{"--toc"}, pandoc.ArgsFor(args, "epub"))
+	assert.Empty(t, pandoc.ArgsFor(nil, "epub"))
+}
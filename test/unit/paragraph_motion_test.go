@@ -0,0 +1,66 @@
+package unit
+
+import (
+	"testing"
+
+	"github.com/ofri/mde/pkg/ast"
+	"github.com/stretchr/testify/assert"
+)
+
+const motionDoc = "First one. First two.\nStill first.\n\n\nSecond para! Yes?\n\nThird."
+
+func TestParagraphMotion(t *testing.T) {
+	doc := ast.NewDocument(motionDoc)
+
+	pos := doc.MoveCursorParagraphDown(ast.BufferPos{Line: 0, Col: 5})
+	assert.Equal(t, ast.BufferPos{Line: 4, Col: 0}, pos, "Blank line runs are skipped")
+	pos = doc.MoveCursorParagraphDown(pos)
+	assert.Equal(t, ast.BufferPos{Line: 6, Col: 0}, pos)
+	pos = doc.MoveCursorParagraphDown(pos)
+	assert.Equal(t, ast.BufferPos{Line: 6, Col: 6}, pos, "After the last paragraph the cursor goes to the end")
+
+	pos = doc.MoveCursorParagraphUp(pos)
+	assert.Equal(t, ast.BufferPos{Line: 6, Col: 0}, pos, "First to the start of the current paragraph")
+	pos = doc.MoveCursorParagraphUp(pos)
+	assert.Equal(t, ast.BufferPos{Line: 4, Col: 0}, pos)
+	pos = doc.MoveCursorParagraphUp(ast.BufferPos{Line: 3, Col: 0})
+	assert.Equal(t, ast.BufferPos{Line: 0, Col: 0}, pos)
+}
+
+func TestSentenceMotion(t *testing.T) {
+	doc := ast.NewDocument(motionDoc)
+
+	var stops []ast.BufferPos
+	pos := ast.BufferPos{Line: 0, Col: 0}
+	for i := 0; i < 5; i++ {
+		pos = doc.MoveCursorSentenceRight(pos)
+		stops = append(stops, pos)
+	}
+	assert.Equal(t, []ast.BufferPos{
+		{Line: 0, Col: 11}, // First two.
+		{Line: 1, Col: 0},  // Still first.
+		{Line: 4, Col: 0},  // Second para!
+		{Line: 4, Col: 13}, // Yes?
+		{Line: 6, Col: 0},  // Third.
+	}, stops)
+
+	pos = doc.MoveCursorSentenceLeft(ast.BufferPos{Line: 4, Col: 15})
+	assert.Equal(t, ast.BufferPos{Line: 4, Col: 13}, pos, "First to the start of the current sentence")
+	pos = doc.MoveCursorSentenceLeft(pos)
+	assert.Equal(t, ast.BufferPos{Line: 4, Col: 0}, pos)
+	pos = doc.MoveCursorSentenceLeft(pos)
+	assert.Equal(t, ast.BufferPos{Line: 1, Col: 0}, pos, "Crosses blank lines into the previous paragraph")
+	pos = doc.MoveCursorSentenceLeft(ast.BufferPos{Line: 0, Col: 0})
+	assert.Equal(t, ast.BufferPos{Line: 0, Col: 0}, pos)
+}
+
+func TestSentenceMotion_SelectionVariants(t *testing.T) {
+	editor := ast.NewEditorWithContent(motionDoc)
+
+	editor.SelectSentenceRight()
+	assert.Equal(t, "First one. ", editor.GetSelectionText())
+
+	editor.GetCursor().ClearSelection()
+	editor.SelectParagraphDown()
+	assert.Equal(t, "First two.\nStill first.\n\n\n", editor.GetSelectionText())
+}
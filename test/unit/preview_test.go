@@ -0,0 +1,119 @@
+package unit
+
+import (
+	"bufio"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ofri/mde/internal/preview"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func getPreview(t *testing.T, url string) string {
+	resp, err := http.Get(url)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode, url)
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	return string(body)
+}
+
+func TestPreviewServer(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "logo.png"), []byte("PNG"), 0o644))
+
+	server, err := preview.Start("127.0.0.1:0", preview.Document{
+		Title:   "notes.md",
+		Content: []byte("<h1>First</h1>"),
+		Dir:     dir,
+	})
+	require.NoError(t, err)
+	defer server.Close()
+
+	page := getPreview(t, server.URL())
+	assert.Contains(t, page, "<title>notes.md</title>")
+	assert.Contains(t, page, "<h1>First</h1>")
+	assert.Contains(t, page, `new EventSource("/events")`)
+	assert.Equal(t, "PNG", getPreview(t, server.URL()+"logo.png"), "Relative images are served")
+
+	// The event stream announces the current version, then each update
+	resp, err := http.Get(server.URL() + "events")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+	events := bufio.NewReader(resp.Body)
+	nextData := func() string {
+		for {
+			line, err := events.ReadString('\n')
+			require.NoError(t, err)
+			if data, ok := strings.CutPrefix(strings.TrimSpace(line), "data: "); ok {
+				return data
+			}
+		}
+	}
+	assert.Equal(t, "1", nextData())
+
+	server.Update(preview.Document{Title: "notes.md", Content: []byte("<h1>Second</h1>"), Dir: dir})
+	assert.Equal(t, "2", nextData())
+	assert.Equal(t, "<h1>Second</h1>", getPreview(t, server.URL()+"content"))
+
+	// Closing ends the stream
+	require.NoError(t, server.Close())
+	_, err = io.ReadAll(events)
+	assert.NoError(t, err)
+}
+
+func TestPreviewServer_ServesOnlyDocumentFiles(t *testing.T) {
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		".env":             "SECRET=1",
+		".git/config":      "[core]",
+		"img/.hidden.png":  "PNG",
+		"img/logo.png":     "PNG",
+		"linked notes.md":  "# Linked",
+		"unlinked.md":      "# Unlinked",
+		"scripts/build.sh": "#!/bin/sh",
+	} {
+		require.NoError(t, os.MkdirAll(filepath.Dir(filepath.Join(dir, name)), 0o755))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644))
+	}
+	server, err := preview.Start("127.0.0.1:0", preview.Document{
+		Title:   "notes.md",
+		Content: []byte(`<p><a href="linked%20notes.md#top">Linked</a> <a href="https://example.com/unlinked.md">Away</a></p>`),
+		Dir:     dir,
+	})
+	require.NoError(t, err)
+	defer server.Close()
+
+	status := func(path string) int {
+		resp, err := http.Get(server.URL() + path)
+		require.NoError(t, err)
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+	assert.Equal(t, "PNG", getPreview(t, server.URL()+"img/logo.png"), "Images are served unlinked")
+	assert.Equal(t, "# Linked", getPreview(t, server.URL()+"linked%20notes.md"), "So are files the document links to")
+	for _, path := range []string{".env", ".git/config", "img/.hidden.png", "img/../.env", "unlinked.md", "scripts/build.sh", "img/"} {
+		assert.Equal(t, http.StatusNotFound, status(path), path)
+	}
+
+	// Only requests naming the server by its address are answered
+	req, err := http.NewRequest(http.MethodGet, server.URL(), nil)
+	require.NoError(t, err)
+	req.Host = "rebound.example:" + req.URL.Port()
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+	req.Host = "localhost:" + req.URL.Port()
+	resp, err = http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+}
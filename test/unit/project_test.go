@@ -0,0 +1,57 @@
+package unit
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ofri/mde/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProject_FindWalksUp(t *testing.T) {
+	dir := t.TempDir()
+	nested := filepath.Join(dir, "docs", "guides")
+	require.NoError(t, os.MkdirAll(nested, 0o755))
+	assert.Equal(t, "", config.FindProject(nested))
+
+	path := filepath.Join(dir, config.ProjectFile)
+	require.NoError(t, os.WriteFile(path, []byte("line_width = 72\n"), 0o644))
+	assert.Equal(t, path, config.FindProject(nested))
+	assert.Equal(t, path, config.FindProject(dir))
+}
+
+func TestProject_LoadRejectsOtherSettings(t *testing.T) {
+	dir := t.TempDir()
+	project, err := config.LoadProject(filepath.Join(dir, config.ProjectFile))
+	require.NoError(t, err, "A missing project file sets nothing")
+	assert.Equal(t, &config.Project{}, project)
+
+	path := filepath.Join(dir, config.ProjectFile)
+	require.NoError(t, os.WriteFile(path, []byte("line_width = 72\ndiagram_command = \"rm -rf ~\"\n"), 0o644))
+	_, err = config.LoadProject(path)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "a project cannot set diagram_command")
+}
+
+func TestProject_ApplyAndRestore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), config.ProjectFile)
+	require.NoError(t, os.WriteFile(path, []byte("tab_width = 2\nlink_style = \"reference\"\n\n[lint]\nfootnote = false\n"), 0o644))
+	project, err := config.LoadProject(path)
+	require.NoError(t, err)
+
+	cfg := config.Default()
+	cfg.LineWidth = 100
+	cfg.Lint = map[string]bool{"open-fence": false}
+	restore := project.Apply(cfg)
+	assert.Equal(t, 2, cfg.TabWidth)
+	assert.Equal(t, 100, cfg.LineWidth, "Settings the project leaves alone stay the user's")
+	assert.Equal(t, "reference", cfg.LinkStyle)
+	assert.Equal(t, map[string]bool{"open-fence": false, "footnote": false}, cfg.Lint)
+
+	restore()
+	assert.Equal(t, 4, cfg.TabWidth)
+	assert.Equal(t, "inline", cfg.LinkStyle)
+	assert.Equal(t, map[string]bool{"open-fence": false}, cfg.Lint)
+}
@@ -0,0 +1,71 @@
+package unit
+
+import (
+	"testing"
+
+	"github.com/ofri/mde/pkg/ast"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReflow(t *testing.T) {
+	tests := []struct {
+		name  string
+		lines []string
+		want  []string
+	}{
+		{
+			name:  "fills short lines",
+			lines: []string{"one two", "three four five", "six"},
+			want:  []string{"one two three", "four five six"},
+		},
+		{
+			name:  "splits long lines",
+			lines: []string{"alpha beta gamma delta epsilon zeta"},
+			want:  []string{"alpha beta gamma", "delta epsilon", "zeta"},
+		},
+		{
+			name:  "keeps quote markers",
+			lines: []string{"> one two three four five six"},
+			want:  []string{"> one two three", "> four five six"},
+		},
+		{
+			name:  "indents list items",
+			lines: []string{"- one two three four five", "- [ ] six seven eight nine", "  ten"},
+			want:  []string{"- one two three", "  four five", "- [ ] six seven", "  eight nine ten"},
+		},
+		{
+			name:  "keeps hard breaks",
+			lines: []string{"one two  ", "three four"},
+			want:  []string{"one two  ", "three four"},
+		},
+		{
+			name:  "keeps headings and tables",
+			lines: []string{"# A heading that is quite long", "| a | b |", "Setext title here", "---"},
+			want:  []string{"# A heading that is quite long", "| a | b |", "Setext title here", "---"},
+		},
+		{
+			name:  "gives long words a line",
+			lines: []string{"see https://example.com/a/very/long/path now"},
+			want:  []string{"see", "https://example.com/a/very/long/path", "now"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, ast.Reflow(tt.lines, 16))
+		})
+	}
+}
+
+func TestReflowParagraph(t *testing.T) {
+	editor := ast.NewEditorWithContent("Intro\n\nalpha beta\ngamma delta epsilon zeta\n\n```\nlong code line stays as it is\n```")
+	editor.GetCursor().SetBufferPos(ast.BufferPos{Line: 3, Col: 6}) // At "delta"
+
+	assert.True(t, editor.ReflowParagraph(16))
+	assert.Equal(t, "Intro\n\nalpha beta gamma\ndelta epsilon\nzeta\n\n```\nlong code line stays as it is\n```", editor.GetDocument().GetText())
+	assert.Equal(t, ast.BufferPos{Line: 3, Col: 0}, editor.GetCursor().GetBufferPos(), "The cursor stays at the word")
+
+	editor.GetCursor().SetBufferPos(ast.BufferPos{Line: 1})
+	assert.False(t, editor.ReflowParagraph(16), "Nothing to reflow on a blank line")
+	editor.GetCursor().SetBufferPos(ast.BufferPos{Line: 7})
+	assert.False(t, editor.ReflowParagraph(16), "Code is not reflowed")
+}
@@ -0,0 +1,118 @@
+package unit
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ofri/mde/internal/remote"
+	"github.com/ofri/mde/pkg/ast"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeSSH puts an ssh on PATH that runs the remote command locally, or
+// fails to connect to the host "down".
+func fakeSSH(t *testing.T) {
+	dir := t.TempDir()
+	script := `#!/bin/sh
+while [ "$1" != "--" ]; do shift; done
+host=$2
+if [ "$host" = down ]; then
+	echo "ssh: connect to host down port 22: Connection refused" >&2
+	exit 255
+fi
+exec sh -c "$3"
+`
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "ssh"), []byte(script), 0755))
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+}
+
+func TestRemote_Parse(t *testing.T) {
+	loc, err := remote.Parse("ssh://ann@example.com:2222/srv/notes/a.md")
+	require.NoError(t, err)
+	assert.Equal(t, remote.Location{User: "ann", Host: "example.com", Port: "2222", Path: "/srv/notes/a.md"}, loc)
+
+	loc, err = remote.Parse("sftp://example.com/~/notes.md")
+	require.NoError(t, err)
+	assert.Equal(t, "~/notes.md", loc.Path, "/~/ is relative to the home directory")
+
+	for _, bad := range []string{"ssh://example.com", "ssh:///a.md", "https://example.com/a.md"} {
+		_, err := remote.Parse(bad)
+		assert.Error(t, err, bad)
+	}
+}
+
+func TestRemote_FileScheme(t *testing.T) {
+	assert.Equal(t, "ssh", ast.FileScheme("ssh://host/a.md"))
+	assert.Equal(t, "", ast.FileScheme("notes/a.md"))
+	assert.Equal(t, "", ast.FileScheme("dir/x://y.md"))
+}
+
+func TestRemote_SSHReadWrite(t *testing.T) {
+	fakeSSH(t)
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "it's here.md")
+	name := "ssh://host" + path
+
+	_, err := remote.SSH{}.ReadFile(ctx, name)
+	assert.ErrorIs(t, err, os.ErrNotExist)
+
+	require.NoError(t, remote.SSH{}.WriteFile(ctx, name, []byte("# Remote\n")))
+	data, err := remote.SSH{}.ReadFile(ctx, name)
+	require.NoError(t, err)
+	assert.Equal(t, "# Remote\n", string(data))
+
+	entries, err := os.ReadDir(filepath.Dir(path))
+	require.NoError(t, err)
+	assert.Len(t, entries, 1, "The temporary upload is renamed over the file")
+}
+
+func TestRemote_ConnectionLost(t *testing.T) {
+	fakeSSH(t)
+	_, err := remote.SSH{}.ReadFile(context.Background(), "ssh://down/a.md")
+	assert.ErrorIs(t, err, remote.ErrConnection)
+	assert.Contains(t, err.Error(), "Connection refused")
+
+	err = remote.SSH{}.WriteFile(context.Background(), "ssh://host/no/such/dir/a.md", []byte("x"))
+	require.Error(t, err)
+	assert.NotErrorIs(t, err, remote.ErrConnection, "Errors on the host are not connection errors")
+}
+
+func TestRemote_HTTPRead(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/README.md":
+			fmt.Fprint(w, "# Hello\n")
+		case "/big.md":
+			w.Header().Set("Content-Length", "4096")
+			w.Write(make([]byte, 4096))
+		case "/stream.md":
+			w.(http.Flusher).Flush() // No Content-Length
+			w.Write(make([]byte, 4096))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+	ctx := context.Background()
+	backend := remote.HTTP{MaxBytes: 1024}
+
+	data, err := backend.ReadFile(ctx, srv.URL+"/README.md")
+	require.NoError(t, err)
+	assert.Equal(t, "# Hello\n", string(data))
+
+	_, err = backend.ReadFile(ctx, srv.URL+"/missing.md")
+	assert.ErrorContains(t, err, "404")
+
+	for _, path := range []string{"/big.md", "/stream.md"} {
+		_, err = backend.ReadFile(ctx, srv.URL+path)
+		assert.ErrorContains(t, err, "larger than 1 KB", path)
+	}
+
+	assert.ErrorIs(t, backend.WriteFile(ctx, srv.URL+"/README.md", data), remote.ErrReadOnly)
+}
@@ -0,0 +1,47 @@
+package unit
+
+import (
+	"testing"
+
+	"github.com/ofri/mde/pkg/ast"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRenumberList(t *testing.T) {
+	tests := []struct {
+		name    string
+		text    string
+		want    string
+		changed int
+	}{
+		{"sequential", "1. a\n1. b\n5. c", "1. a\n2. b\n3. c", 2},
+		{"keeps the start", "3) a\n7) b", "3) a\n4) b", 1},
+		{"nested lists restart", "1. a\n   1. x\n   3. y\n2. b\n   5. z", "1. a\n   1. x\n   2. y\n2. b\n   5. z", 1},
+		{"bullets between", "1. a\n   - x\n3. b", "1. a\n   - x\n2. b", 1},
+		{"loose list with content", "1. a\n\n   more\n\n1. b", "1. a\n\n   more\n\n2. b", 1},
+		{"widening numbers", "9. a\n9. b", "9. a\n10. b", 1},
+		{"in order", "1. a\n2. b", "1. a\n2. b", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			editor := ast.NewEditorWithContent(tt.text)
+			assert.Equal(t, tt.changed, editor.RenumberList())
+			assert.Equal(t, tt.want, editor.GetDocument().GetText())
+		})
+	}
+}
+
+func TestRenumberList_Bounds(t *testing.T) {
+	text := "1. a\n3. b\n\npara\n\n1. c\n1. d\n```\n1. code\n```"
+	editor := ast.NewEditorWithContent(text)
+	editor.GetCursor().SetBufferPos(ast.BufferPos{Line: 6, Col: 4})
+
+	assert.Equal(t, 1, editor.RenumberList())
+	assert.Equal(t, "1. a\n3. b\n\npara\n\n1. c\n2. d\n```\n1. code\n```", editor.GetDocument().GetText(),
+		"Only the list under the cursor changes")
+
+	editor = ast.NewEditorWithContent("9. a\n1. b")
+	editor.GetCursor().SetBufferPos(ast.BufferPos{Line: 1, Col: 3})
+	editor.RenumberList()
+	assert.Equal(t, ast.BufferPos{Line: 1, Col: 4}, editor.GetCursor().GetBufferPos(), "The cursor stays on its character")
+}
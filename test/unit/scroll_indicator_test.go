@@ -0,0 +1,36 @@
+package unit
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/ofri/mde/internal/plugins/renderers"
+	"github.com/ofri/mde/pkg/ast"
+	"github.com/ofri/mde/pkg/plugin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScrollIndicator_LinesEndingLeftOfView(t *testing.T) {
+	doc := ast.NewDocument(strings.Repeat("x", 30) + "\nshort\n")
+	renderer := renderers.NewTerminalRenderer()
+	render := func(left int) []plugin.RenderedLine {
+		lines, err := renderer.RenderVisible(context.Background(), &plugin.RenderContext{
+			Document: doc,
+			Viewport: ast.NewViewport(0, left, 20, 5, 0, 4),
+		})
+		require.NoError(t, err)
+		return lines
+	}
+
+	lines := render(0)
+	assert.Equal(t, "short", lines[1].Content, "No marker without scrolling")
+
+	lines = render(10)
+	assert.Equal(t, strings.Repeat("x", 20), lines[0].Content)
+	assert.Equal(t, "‹", lines[1].Content, "The line ends left of the view")
+	require.Len(t, lines[1].Styles, 1)
+	assert.Equal(t, 0, lines[1].Styles[0].Start)
+	assert.Equal(t, "", lines[2].Content, "Empty lines stay empty")
+}
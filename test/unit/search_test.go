@@ -0,0 +1,66 @@
+package unit
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ofri/mde/pkg/ast"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSearch_Find(t *testing.T) {
+	doc := ast.NewDocument("the cat\n日本 Cat\nlast cat")
+	find := func(query string, from ast.BufferPos, opts ast.SearchOptions) ast.BufferPos {
+		r, ok := doc.Find(query, from, opts)
+		require.True(t, ok, "%q from %v", query, from)
+		return r.Start
+	}
+
+	assert.Equal(t, ast.BufferPos{Line: 0, Col: 4}, find("cat", ast.BufferPos{}, ast.SearchOptions{}))
+	assert.Equal(t, ast.BufferPos{Line: 0, Col: 4}, find("cat", ast.BufferPos{Line: 0, Col: 4}, ast.SearchOptions{}),
+		"A match at the cursor counts")
+	assert.Equal(t, ast.BufferPos{Line: 1, Col: 3}, find("cat", ast.BufferPos{Line: 0, Col: 5}, ast.SearchOptions{}),
+		"Case folded, in runes")
+	assert.Equal(t, ast.BufferPos{Line: 2, Col: 5}, find("cat", ast.BufferPos{Line: 0, Col: 5}, ast.SearchOptions{CaseSensitive: true}))
+	assert.Equal(t, ast.BufferPos{Line: 0, Col: 4}, find("cat", ast.BufferPos{Line: 2, Col: 6}, ast.SearchOptions{}),
+		"Wraps around the end")
+
+	backward := ast.SearchOptions{Backward: true}
+	assert.Equal(t, ast.BufferPos{Line: 1, Col: 3}, find("cat", ast.BufferPos{Line: 2, Col: 5}, backward),
+		"A match at the cursor does not count backward")
+	assert.Equal(t, ast.BufferPos{Line: 2, Col: 5}, find("cat", ast.BufferPos{Line: 0, Col: 4}, backward),
+		"Wraps around the start")
+	assert.Equal(t, ast.BufferPos{Line: 0, Col: 4}, find("CAT", ast.BufferPos{Line: 0, Col: 6}, backward),
+		"A match starting before the cursor counts even if it ends after")
+
+	_, ok := doc.Find("dog", ast.BufferPos{}, ast.SearchOptions{})
+	assert.False(t, ok)
+	_, ok = doc.Find("", ast.BufferPos{}, ast.SearchOptions{})
+	assert.False(t, ok)
+}
+
+func TestSearch_AcrossLines(t *testing.T) {
+	doc := ast.NewDocument("one\ntwo\nthree")
+	r, ok := doc.Find("ne\ntwo\nth", ast.BufferPos{}, ast.SearchOptions{})
+	require.True(t, ok)
+	assert.Equal(t, ast.Range{Start: ast.BufferPos{Line: 0, Col: 1}, End: ast.BufferPos{Line: 2, Col: 2}}, r)
+
+	_, ok = doc.Find("on\ntwo", ast.BufferPos{}, ast.SearchOptions{})
+	assert.False(t, ok, "Every line but the last must match to its end")
+
+	r, ok = doc.MatchAt("O\nT", ast.BufferPos{Line: 1, Col: 2}, false)
+	assert.True(t, ok)
+	assert.Equal(t, ast.BufferPos{Line: 2, Col: 1}, r.End)
+	_, ok = doc.MatchAt("O\nT", ast.BufferPos{Line: 1, Col: 2}, true)
+	assert.False(t, ok)
+}
+
+func TestSearch_NoDocumentCopies(t *testing.T) {
+	doc := ast.NewDocument(strings.Repeat("some text without the word\n", 5000) + "needle")
+	allocs := testing.AllocsPerRun(10, func() {
+		doc.Find("NEEDLE", ast.BufferPos{}, ast.SearchOptions{})
+		doc.Find("needle", ast.BufferPos{}, ast.SearchOptions{CaseSensitive: true, Backward: true})
+	})
+	assert.LessOrEqual(t, allocs, 2.0, "Only the query is split, once per search")
+}
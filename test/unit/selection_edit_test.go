@@ -0,0 +1,111 @@
+package unit
+
+import (
+	"testing"
+
+	"github.com/ofri/mde/pkg/ast"
+	"github.com/stretchr/testify/assert"
+)
+
+// selectRange selects from start to end, leaving the cursor at end.
+func selectRange(editor *ast.Editor, start, end ast.BufferPos) {
+	editor.GetCursor().SetBufferPos(start)
+	editor.GetCursor().StartSelection()
+	editor.GetCursor().SetBufferPos(end)
+	editor.GetCursor().ExtendSelection()
+}
+
+func TestSelectionEdit_BackspaceDeletesSelection(t *testing.T) {
+	editor := ast.NewEditorWithContent("hello brave world")
+	selectRange(editor, ast.BufferPos{Line: 0, Col: 6}, ast.BufferPos{Line: 0, Col: 12})
+
+	editor.DeleteText(1)
+
+	assert.Equal(t, "hello world", editor.GetDocument().GetText())
+	assert.Equal(t, ast.BufferPos{Line: 0, Col: 6}, editor.GetCursorBufferPosition())
+	assert.False(t, editor.GetCursor().HasSelection())
+}
+
+func TestSelectionEdit_DeleteBackwardSelectionAcrossLines(t *testing.T) {
+	editor := ast.NewEditorWithContent("one\ntwo\nthree")
+	// Selected right to left, from "three" back into "one"
+	selectRange(editor, ast.BufferPos{Line: 2, Col: 2}, ast.BufferPos{Line: 0, Col: 1})
+
+	editor.DeleteTextForward(1)
+
+	assert.Equal(t, "oree", editor.GetDocument().GetText())
+	assert.Equal(t, ast.BufferPos{Line: 0, Col: 1}, editor.GetCursorBufferPosition())
+}
+
+func TestSelectionEdit_TypingReplacesSelection(t *testing.T) {
+	editor := ast.NewEditorWithContent("hello world")
+	selectRange(editor, ast.BufferPos{Line: 0, Col: 0}, ast.BufferPos{Line: 0, Col: 5})
+
+	editor.InsertText("J")
+
+	assert.Equal(t, "J world", editor.GetDocument().GetText())
+	assert.Equal(t, ast.BufferPos{Line: 0, Col: 1}, editor.GetCursorBufferPosition())
+	assert.False(t, editor.GetCursor().HasSelection())
+}
+
+func TestSelectionEdit_EmptySelectionDeletesNormally(t *testing.T) {
+	editor := ast.NewEditorWithContent("abc")
+	selectRange(editor, ast.BufferPos{Line: 0, Col: 2}, ast.BufferPos{Line: 0, Col: 2})
+
+	editor.DeleteText(1)
+	assert.Equal(t, "ac", editor.GetDocument().GetText())
+
+	editor.DeleteTextForward(1)
+	assert.Equal(t, "a", editor.GetDocument().GetText())
+
+	editor.DeleteTextForward(1)
+	assert.Equal(t, "a", editor.GetDocument().GetText(), "Delete at the end of the document does nothing")
+}
+
+func TestSelectionEdit_CutRemovesSelectedText(t *testing.T) {
+	editor := ast.NewEditorWithContent("keep cut keep")
+	selectRange(editor, ast.BufferPos{Line: 0, Col: 5}, ast.BufferPos{Line: 0, Col: 9})
+
+	editor.Cut()
+	assert.Equal(t, "keep keep", editor.GetDocument().GetText())
+
+	editor.Paste()
+	assert.Equal(t, "keep cut keep", editor.GetDocument().GetText())
+}
+
+func TestSelectionEdit_SelectionCommands(t *testing.T) {
+	editor := ast.NewEditorWithContent("first line here\nsecond line")
+	editor.GetCursor().SetBufferPos(ast.BufferPos{Line: 0, Col: 6})
+
+	editor.SelectToLineEnd()
+	assert.Equal(t, "line here", editor.GetSelectionText())
+
+	editor.SelectWordLeft()
+	assert.Equal(t, "line ", editor.GetSelectionText(), "The anchor stays where the selection began")
+
+	editor.GetCursor().ClearSelection()
+	editor.GetCursor().SetBufferPos(ast.BufferPos{Line: 0, Col: 6})
+	editor.SelectToDocumentEnd()
+	assert.Equal(t, "line here\nsecond line", editor.GetSelectionText())
+
+	editor.GetCursor().ClearSelection()
+	editor.GetCursor().SetBufferPos(ast.BufferPos{Line: 1, Col: 6})
+	editor.SelectToLineStart()
+	assert.Equal(t, "second", editor.GetSelectionText())
+	editor.SelectToDocumentStart()
+	assert.Equal(t, "first line here\nsecond", editor.GetSelectionText())
+}
+
+func TestSelectionEdit_SelectParagraph(t *testing.T) {
+	editor := ast.NewEditorWithContent("intro\n\npara one\npara two\n\noutro")
+	editor.GetCursor().SetBufferPos(ast.BufferPos{Line: 3, Col: 2})
+
+	assert.True(t, editor.SelectParagraph())
+	assert.Equal(t, "para one\npara two", editor.GetSelectionText())
+	assert.Equal(t, ast.BufferPos{Line: 3, Col: 8}, editor.GetCursorBufferPosition())
+
+	editor.GetCursor().ClearSelection()
+	editor.GetCursor().SetBufferPos(ast.BufferPos{Line: 1, Col: 0})
+	assert.False(t, editor.SelectParagraph(), "Blank lines belong to no paragraph")
+	assert.False(t, editor.GetCursor().HasSelection())
+}
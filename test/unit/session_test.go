@@ -0,0 +1,24 @@
+package unit
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ofri/mde/internal/session"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSessionBaseline(t *testing.T) {
+	t.Setenv("MDE_SESSION_FILE", filepath.Join(t.TempDir(), "sessions.json"))
+	monday := time.Date(2024, 5, 6, 9, 0, 0, 0, time.Local)
+
+	assert.Equal(t, 100, session.Baseline("notes.md", monday, 100))
+	assert.Equal(t, 100, session.Baseline("notes.md", monday.Add(5*time.Hour), 350),
+		"Later sessions the same day keep the first count")
+	assert.Equal(t, 7, session.Baseline("other.md", monday, 7), "Each file has its own")
+
+	assert.Equal(t, 350, session.Baseline("notes.md", monday.AddDate(0, 0, 1), 350), "A new day starts over")
+	assert.Equal(t, 20, session.Baseline("other.md", monday, 20), "Earlier days are forgotten")
+	assert.Equal(t, 5, session.Baseline("", monday, 5), "Unnamed buffers are not stored")
+}
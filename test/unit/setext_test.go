@@ -0,0 +1,71 @@
+package unit
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ofri/mde/internal/plugins/parsers"
+	"github.com/ofri/mde/internal/plugins/renderers"
+	"github.com/ofri/mde/pkg/ast"
+	"github.com/ofri/mde/pkg/plugin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const setextDoc = "Title\n=====\n\nSection\n---\n\n---\n- item\n---\n```\ncode\n---\n```"
+
+func TestSetext_Detection(t *testing.T) {
+	assert.Equal(t, 1, ast.SetextUnderline("==="))
+	assert.Equal(t, 2, ast.SetextUnderline("  ---  "))
+	assert.Equal(t, 0, ast.SetextUnderline("-- -"))
+	assert.Equal(t, 0, ast.SetextUnderline("    ==="))
+
+	doc := ast.NewDocument(setextDoc)
+	cases := []struct {
+		line      int
+		level     int
+		underline bool
+	}{
+		{0, 1, false},
+		{1, 1, true},
+		{3, 2, false},
+		{4, 2, true},
+		{6, 0, false},  // Thematic break after a blank line
+		{8, 0, false},  // List items cannot be setext headings
+		{11, 0, false}, // Inside a code fence
+	}
+	for _, c := range cases {
+		level, underline := doc.SetextHeadingAt(c.line)
+		assert.Equal(t, c.level, level, "line %d", c.line)
+		assert.Equal(t, c.underline, underline, "line %d", c.line)
+	}
+}
+
+func TestSetext_Tokens(t *testing.T) {
+	parser := parsers.NewCommonMarkParser()
+	doc, err := parser.Parse(context.Background(), setextDoc)
+	require.NoError(t, err)
+
+	text := doc.GetLineTokens(0)
+	require.NotEmpty(t, text)
+	assert.Equal(t, ast.TokenHeading, text[0].Kind())
+
+	underline := doc.GetLineTokens(1)
+	require.Len(t, underline, 1)
+	assert.Equal(t, ast.TokenDelimiter, underline[0].Kind())
+}
+
+func TestSetext_Preview(t *testing.T) {
+	renderer := renderers.NewTerminalRenderer()
+	lines, err := renderer.RenderPreviewVisible(context.Background(), &plugin.RenderContext{
+		Document: ast.NewDocument(setextDoc),
+		Viewport: ast.NewViewport(0, 0, 80, 20, 0, 4),
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, "Title", lines[0].Content)
+	assert.True(t, lines[0].Styles[0].Style.Bold)
+	assert.Equal(t, "═════", lines[1].Content)
+	assert.Equal(t, "Section", lines[3].Content)
+	assert.Equal(t, "───────", lines[4].Content)
+}
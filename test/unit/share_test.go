@@ -0,0 +1,105 @@
+package unit
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ofri/mde/internal/crdt"
+	"github.com/ofri/mde/internal/share"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// receive returns the next message from session, failing the test if
+// none comes.
+func receive(t *testing.T, session *share.Session) share.Message {
+	t.Helper()
+	select {
+	case msg, ok := <-session.Incoming():
+		require.True(t, ok, "The session ended")
+		return msg
+	case <-time.After(5 * time.Second):
+		t.Fatal("No message")
+		return share.Message{}
+	}
+}
+
+func TestShareSession(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "share.sock")
+	host, err := share.Host(path)
+	require.NoError(t, err)
+	defer host.Close()
+	assert.True(t, host.Hosting())
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm(), "Only the user may join on a socket")
+
+	_, err = share.Host(path)
+	assert.ErrorContains(t, err, "in use", "A live session is not taken over")
+
+	ann, err := share.Join(path)
+	require.NoError(t, err)
+	defer ann.Close()
+	assert.False(t, ann.Hosting())
+	ann.Send(share.Message{Site: "ann", Name: "Ann"})
+	assert.Equal(t, share.Message{Site: "ann", Name: "Ann"}, receive(t, host))
+
+	bob, err := share.Join(path)
+	require.NoError(t, err)
+	defer bob.Close()
+	bob.Send(share.Message{Site: "bob", Name: "Bob"})
+	assert.Equal(t, "bob", receive(t, host).Site)
+	assert.Equal(t, "Bob", receive(t, ann).Name, "The host relays what guests send to the others")
+
+	// Messages to one site reach only it
+	host.Send(share.Message{Site: "host", To: "bob", Path: "notes.md"})
+	host.Send(share.Message{Site: "host", Ops: []crdt.Op{{ID: crdt.ID{Site: "host", Counter: 1}, Char: 'x'}}})
+	assert.Equal(t, "notes.md", receive(t, bob).Path)
+	assert.Equal(t, 'x', receive(t, bob).Ops[0].Char)
+	assert.Equal(t, 'x', receive(t, ann).Ops[0].Char, "Ann never got Bob's welcome")
+
+	cursor := 3
+	bob.Send(share.Message{Site: "bob", Cursor: &cursor})
+	assert.Equal(t, 3, *receive(t, ann).Cursor)
+	assert.Equal(t, 3, *receive(t, host).Cursor)
+
+	bob.Close()
+	assert.Equal(t, share.Message{Site: "bob", Left: true}, receive(t, host))
+	assert.Equal(t, share.Message{Site: "bob", Left: true}, receive(t, ann), "The others hear who left")
+
+	host.Close()
+	select {
+	case _, ok := <-ann.Incoming():
+		assert.False(t, ok, "The session ends for the guests when the host leaves")
+	case <-time.After(5 * time.Second):
+		t.Fatal("The session did not end")
+	}
+}
+
+func TestShareOverTCP(t *testing.T) {
+	host, err := share.Host("127.0.0.1:0")
+	require.NoError(t, err)
+	defer host.Close()
+
+	guest, err := share.Join(host.Addr())
+	require.NoError(t, err)
+	defer guest.Close()
+	guest.Send(share.Message{Site: "guest", Name: "Ann"})
+	assert.Equal(t, "Ann", receive(t, host).Name)
+	host.Send(share.Message{Site: "host", To: "guest", Name: "Bob"})
+	assert.Equal(t, "Bob", receive(t, guest).Name)
+}
+
+func TestChangeShift(t *testing.T) {
+	insert := crdt.Change{Offset: 4, Text: "ab"}
+	assert.Equal(t, 3, insert.Shift(3), "Offsets before an insertion stay")
+	assert.Equal(t, 4, insert.Shift(4), "Text inserted at an offset goes after it")
+	assert.Equal(t, 7, insert.Shift(5))
+
+	remove := crdt.Change{Offset: 4, Deleted: 3}
+	assert.Equal(t, 4, remove.Shift(4))
+	assert.Equal(t, 4, remove.Shift(6), "Offsets in deleted text go to where it was")
+	assert.Equal(t, 7, remove.Shift(10))
+}
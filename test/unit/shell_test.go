@@ -0,0 +1,18 @@
+package unit
+
+import (
+	"os/exec"
+	"testing"
+
+	"github.com/ofri/mde/internal/shell"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShellQuote(t *testing.T) {
+	for _, arg := range []string{"plain", "two words", "it's", `$HOME "x" \n`, ""} {
+		out, err := exec.Command("sh", "-c", "printf %s "+shell.Quote(arg)).Output()
+		require.NoError(t, err)
+		assert.Equal(t, arg, string(out))
+	}
+}
@@ -0,0 +1,88 @@
+package unit
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ofri/mde/internal/site"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSiteRewriteLink(t *testing.T) {
+	tests := map[string]string{
+		"guide.md":                   "guide.html",
+		"../intro.markdown#setup":    "../intro.html#setup",
+		"sub/page.md?x=1":            "sub/page.html?x=1",
+		"https://example.com/a.md":   "https://example.com/a.md",
+		"/abs/page.md":               "/abs/page.md",
+		"image.png":                  "image.png",
+		"#heading":                   "#heading",
+		"mailto:someone@example.com": "mailto:someone@example.com",
+	}
+	for dest, want := range tests {
+		assert.Equal(t, want, site.RewriteLink(dest), dest)
+	}
+}
+
+func TestSiteBuild(t *testing.T) {
+	source := t.TempDir()
+	write := func(rel, content string) {
+		path := filepath.Join(source, filepath.FromSlash(rel))
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	}
+	write("index.md", "# Welcome\n\nStart with the [guide](guide/intro.md).\n")
+	write("guide/intro.md", "# Getting started\n\nSee [the API](../api.md#calls) and ![logo](../logo.png).\n")
+	write("api.md", "Calls and more.\n")
+	write("logo.png", "PNG")
+	write(".hidden/secret.md", "# Secret\n")
+
+	output := filepath.Join(t.TempDir(), "public")
+	pages, err := site.Build(site.Options{Source: source, Output: output, Title: "Docs"})
+	require.NoError(t, err)
+	assert.Equal(t, []site.Page{
+		{Title: "api", Path: "api.html"},
+		{Title: "Getting started", Path: "guide/intro.html"},
+		{Title: "Welcome", Path: "index.html"},
+	}, pages)
+
+	intro, err := os.ReadFile(filepath.Join(output, "guide", "intro.html"))
+	require.NoError(t, err)
+	assert.Contains(t, string(intro), `<a href="../api.html#calls">the API</a>`)
+	assert.Contains(t, string(intro), `src="../logo.png"`)
+	assert.Contains(t, string(intro), `<a href="../index.html">`, "pages link back to the index")
+	assert.Contains(t, string(intro), `<title>Getting started - Docs</title>`)
+
+	index, err := os.ReadFile(filepath.Join(output, "index.html"))
+	require.NoError(t, err)
+	assert.Contains(t, string(index), `<a href="guide/intro.html">guide</a>`)
+	assert.Contains(t, string(index), `<li><a href="guide/intro.html">Getting started</a></li>`)
+	assert.Contains(t, string(index), `<li><a href="api.html">api</a></li>`)
+
+	logo, err := os.ReadFile(filepath.Join(output, "logo.png"))
+	require.NoError(t, err)
+	assert.Equal(t, "PNG", string(logo))
+	assert.NoFileExists(t, filepath.Join(output, ".hidden", "secret.html"))
+}
+
+func TestSiteBuildCustomTemplate(t *testing.T) {
+	source := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(source, "a.md"), []byte("# A\n"), 0o644))
+	tmpl := filepath.Join(t.TempDir(), "page.html")
+	require.NoError(t, os.WriteFile(tmpl, []byte("[{{.SiteTitle}}|{{.Title}}]{{.Content}}"), 0o644))
+
+	output := filepath.Join(source, "public")
+	_, err := site.Build(site.Options{Source: source, Output: output, Template: tmpl})
+	require.NoError(t, err)
+
+	page, err := os.ReadFile(filepath.Join(output, "a.html"))
+	require.NoError(t, err)
+	assert.Equal(t, "["+filepath.Base(source)+"|A]<h1 id=\"a\">A</h1>\n", string(page))
+
+	// Building again skips the output inside the source
+	pages, err := site.Build(site.Options{Source: source, Output: output, Template: tmpl})
+	require.NoError(t, err)
+	assert.Len(t, pages, 2)
+}
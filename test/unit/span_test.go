@@ -0,0 +1,37 @@
+package unit
+
+import (
+	"testing"
+
+	"github.com/ofri/mde/pkg/ast"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSpans(t *testing.T) {
+	doc := ast.NewDocument("> quote\n> > nested\n> back\n\n  ```go\n> code\n  ```\n$$\nx^2\n$$\n<div>\nhi\n</div>")
+
+	assert.Equal(t, []ast.Span{
+		{Kind: ast.SpanQuote, Start: ast.BufferPos{Line: 0, Col: 0}, End: ast.BufferPos{Line: 2, Col: 6}, Depth: 1},
+		{Kind: ast.SpanQuote, Start: ast.BufferPos{Line: 1, Col: 2}, End: ast.BufferPos{Line: 1, Col: 10}, Depth: 2},
+		{Kind: ast.SpanFence, Start: ast.BufferPos{Line: 4, Col: 2}, End: ast.BufferPos{Line: 6, Col: 5}, Info: "go"},
+		{Kind: ast.SpanMath, Start: ast.BufferPos{Line: 7, Col: 0}, End: ast.BufferPos{Line: 9, Col: 2}},
+		{Kind: ast.SpanHTML, Start: ast.BufferPos{Line: 10, Col: 0}, End: ast.BufferPos{Line: 12, Col: 6}},
+	}, doc.Spans(), "A quote marker inside a fence is code")
+
+	at := doc.SpansAt(1)
+	assert.Len(t, at, 2)
+	assert.Equal(t, 1, at[0].Depth, "Outermost span first")
+	assert.Empty(t, doc.SpansAt(3))
+}
+
+func TestSpansFollowEdits(t *testing.T) {
+	editor := ast.NewEditorWithContent("```\ncode")
+	assert.Equal(t, 1, editor.GetDocument().Spans()[0].End.Line, "An unclosed fence runs to the end")
+
+	editor.GetCursor().SetBufferPos(ast.BufferPos{Line: 1, Col: 4})
+	editor.TypeText("\n```\n> quote")
+	spans := editor.GetDocument().Spans()
+	assert.Len(t, spans, 2)
+	assert.Equal(t, 2, spans[0].End.Line)
+	assert.Equal(t, ast.SpanQuote, spans[1].Kind)
+}
@@ -0,0 +1,41 @@
+package unit
+
+import (
+	"testing"
+
+	"github.com/ofri/mde/pkg/ast"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSectionCounter(t *testing.T) {
+	doc := ast.NewDocument("Intro words here\n# One\none two\n## Sub\nthree\nSetext\n------\nfour five -\n# Two\nsix")
+
+	var counter ast.SectionCounter
+	sections := counter.Sections(doc)
+	require.Len(t, sections, 5)
+
+	type summary struct {
+		text         string
+		level, own   int
+		total, chars int
+	}
+	var got []summary
+	for _, s := range sections {
+		got = append(got, summary{s.Heading.Text, s.Heading.Level, s.Own.Words, s.Total.Words, s.Total.Chars})
+	}
+	assert.Equal(t, []summary{
+		{"", 0, 3, 3, 16},
+		{"One", 1, 2, 5, 7 + 5 + 11},
+		{"Sub", 2, 1, 1, 5},
+		{"Setext", 2, 2, 2, 11},
+		{"Two", 1, 1, 1, 3},
+	}, got, "Setext underlines and lone punctuation are not words")
+
+	// Counting again after an edit reflects it
+	doc.InsertChar(ast.BufferPos{Line: 4, Col: 5}, ' ')
+	doc.InsertChar(ast.BufferPos{Line: 4, Col: 6}, 'x')
+	sections = counter.Sections(doc)
+	assert.Equal(t, 2, sections[2].Own.Words)
+	assert.Equal(t, 6, sections[1].Total.Words)
+}
@@ -0,0 +1,48 @@
+package unit
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ofri/mde/internal/plugins/parsers"
+	"github.com/ofri/mde/internal/plugins/renderers"
+	"github.com/ofri/mde/pkg/ast"
+	"github.com/ofri/mde/pkg/plugin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStrikeHighlight_Tokens(t *testing.T) {
+	parser := parsers.NewCommonMarkParser()
+	tokens, err := parser.GetSyntaxHighlighting(context.Background(), "a ~~gone~~ b ==key== c")
+	require.NoError(t, err)
+
+	var kinds []ast.TokenKind
+	for _, token := range tokens {
+		if token.Kind() != ast.TokenDelimiter {
+			kinds = append(kinds, token.Kind())
+		}
+	}
+	assert.Equal(t, []ast.TokenKind{ast.TokenStrikethrough, ast.TokenHighlight}, kinds)
+
+	tokens, err = parser.GetSyntaxHighlighting(context.Background(), "a == b == c and ~~ x~~")
+	require.NoError(t, err)
+	assert.Empty(t, tokens, "Delimiters padded with spaces are literal text")
+}
+
+func TestStrikeHighlight_Preview(t *testing.T) {
+	renderer := renderers.NewTerminalRenderer()
+	lines, err := renderer.RenderPreviewVisible(context.Background(), &plugin.RenderContext{
+		Document: ast.NewDocument("was ~~old~~ now ==new=="),
+		Viewport: ast.NewViewport(0, 0, 80, 5, 0, 4),
+	})
+	require.NoError(t, err)
+
+	line := lines[0]
+	assert.Equal(t, "was old now new", line.Content)
+	require.Len(t, line.Styles, 2)
+	assert.Equal(t, plugin.StyleRange{Start: 4, End: 7, Style: line.Styles[0].Style}, line.Styles[0])
+	assert.True(t, line.Styles[0].Style.Strikethrough)
+	assert.Equal(t, 12, line.Styles[1].Start)
+	assert.NotEmpty(t, line.Styles[1].Style.Background, "Highlight uses a background color")
+}
@@ -0,0 +1,107 @@
+package unit
+
+import (
+	"testing"
+
+	"github.com/ofri/mde/pkg/ast"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const structureDoc = `# Title
+
+Intro text. Second sentence here.
+
+## Part one
+
+- item one
+  continued
+  - nested
+- item two
+
+## Part two
+
+Closing words.`
+
+func TestStructure_WordAt(t *testing.T) {
+	doc := ast.NewDocument("hello, wor_ld!")
+
+	r, ok := doc.WordAt(ast.BufferPos{Line: 0, Col: 9})
+	require.True(t, ok)
+	assert.Equal(t, ast.Range{Start: ast.BufferPos{Line: 0, Col: 7}, End: ast.BufferPos{Line: 0, Col: 13}}, r)
+
+	r, ok = doc.WordAt(ast.BufferPos{Line: 0, Col: 5})
+	require.True(t, ok, "A cursor just after a word selects it")
+	assert.Equal(t, 0, r.Start.Col)
+
+	_, ok = doc.WordAt(ast.BufferPos{Line: 0, Col: 6})
+	assert.False(t, ok)
+}
+
+func TestStructure_SentenceAt(t *testing.T) {
+	doc := ast.NewDocument(structureDoc)
+
+	r, ok := doc.SentenceAt(ast.BufferPos{Line: 2, Col: 15})
+	require.True(t, ok)
+	assert.Equal(t, ast.BufferPos{Line: 2, Col: 12}, r.Start)
+	assert.Equal(t, ast.BufferPos{Line: 2, Col: 33}, r.End)
+}
+
+func TestStructure_ListItemAt(t *testing.T) {
+	doc := ast.NewDocument(structureDoc)
+
+	r, ok := doc.ListItemAt(7)
+	require.True(t, ok)
+	assert.Equal(t, 6, r.Start.Line, "Continuation lines belong to their item")
+	assert.Equal(t, 8, r.End.Line, "Nested items belong to their parent")
+
+	r, ok = doc.ListItemAt(8)
+	require.True(t, ok)
+	assert.Equal(t, ast.Range{Start: ast.BufferPos{Line: 8, Col: 0}, End: ast.BufferPos{Line: 8, Col: 10}}, r)
+
+	_, ok = doc.ListItemAt(2)
+	assert.False(t, ok)
+}
+
+func TestStructure_SectionAt(t *testing.T) {
+	doc := ast.NewDocument(structureDoc)
+
+	r, ok := doc.SectionAt(7)
+	require.True(t, ok)
+	assert.Equal(t, 4, r.Start.Line)
+	assert.Equal(t, 9, r.End.Line, "Sections stop before the next heading and its blank line")
+
+	r, ok = doc.SectionAt(2)
+	require.True(t, ok)
+	assert.Equal(t, 0, r.Start.Line)
+	assert.Equal(t, 13, r.End.Line, "A level 1 section holds its subsections")
+
+	_, ok = ast.NewDocument("no headings").SectionAt(0)
+	assert.False(t, ok)
+	assert.Zero(t, ast.NewDocument("```\n# code\n```").HeadingLevel(1))
+}
+
+func TestStructure_ExpandSelection(t *testing.T) {
+	editor := ast.NewEditorWithContent(structureDoc)
+	editor.GetCursor().SetBufferPos(ast.BufferPos{Line: 2, Col: 14})
+
+	var steps []string
+	for editor.ExpandSelection() {
+		steps = append(steps, editor.GetSelectionText())
+	}
+
+	require.Len(t, steps, 4)
+	assert.Equal(t, "Second", steps[0])
+	assert.Equal(t, "Second sentence here.", steps[1])
+	assert.Equal(t, "Intro text. Second sentence here.", steps[2])
+	assert.Equal(t, structureDoc, steps[3], "The title section spans the whole document")
+}
+
+func TestStructure_SelectLine(t *testing.T) {
+	editor := ast.NewEditorWithContent("one\ntwo")
+	editor.SelectLine()
+	assert.Equal(t, "one\n", editor.GetSelectionText())
+
+	editor.DeleteText(1)
+	assert.Equal(t, "two", editor.GetDocument().GetText())
+}
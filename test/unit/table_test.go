@@ -0,0 +1,121 @@
+package unit
+
+import (
+	"testing"
+
+	"github.com/ofri/mde/pkg/ast"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const messyTable = "intro\n|Name|Qty|\n|:--|--:|\n|apple|3|\n|kiwi \\| lime|12|\nafter"
+
+func TestTableAt(t *testing.T) {
+	doc := ast.NewDocument(messyTable)
+
+	table, ok := doc.TableAt(3)
+	require.True(t, ok)
+	assert.Equal(t, 1, table.Start)
+	assert.Equal(t, 4, table.End)
+	assert.Equal(t, []ast.Alignment{ast.AlignLeft, ast.AlignRight}, table.Align)
+	assert.Equal(t, [][]string{{"Name", "Qty"}, {"apple", "3"}, {`kiwi \| lime`, "12"}}, table.Rows)
+
+	assert.Equal(t, []string{
+		"| Name         | Qty |",
+		"| :----------- | --: |",
+		"| apple        |   3 |",
+		`| kiwi \| lime |  12 |`,
+	}, table.Format())
+
+	_, ok = doc.TableAt(0)
+	assert.False(t, ok)
+	_, ok = ast.NewDocument("a | b\nc | d").TableAt(0)
+	assert.False(t, ok, "Pipes without a delimiter row are no table")
+}
+
+func TestTableNavigation(t *testing.T) {
+	editor := ast.NewEditorWithContent(messyTable)
+	editor.GetCursor().SetBufferPos(ast.BufferPos{Line: 3, Col: 3})
+
+	assert.True(t, editor.TableNextCell())
+	assert.Equal(t, "| apple        |   3 |", editor.GetDocument().GetLine(3), "Moving realigns the table")
+	assert.Equal(t, ast.BufferPos{Line: 3, Col: 20}, editor.GetCursor().GetBufferPos(), "At the end of the next cell's text")
+
+	assert.True(t, editor.TablePrevCell())
+	assert.Equal(t, ast.BufferPos{Line: 3, Col: 7}, editor.GetCursor().GetBufferPos())
+
+	editor.GetCursor().SetBufferPos(ast.BufferPos{Line: 4, Col: 19})
+	assert.True(t, editor.TableNextCell(), "Past the last cell adds a row")
+	assert.Equal(t, "|              |     |", editor.GetDocument().GetLine(5))
+	assert.Equal(t, ast.BufferPos{Line: 5, Col: 2}, editor.GetCursor().GetBufferPos())
+
+	editor.GetCursor().SetBufferPos(ast.BufferPos{Line: 0})
+	assert.False(t, editor.TableNextCell())
+}
+
+func TestTableWideCharacters(t *testing.T) {
+	editor := ast.NewEditorWithContent("|名前|Qty|\n|---|--:|\n|🍎|3|\n|kiwi|12|")
+	editor.GetCursor().SetBufferPos(ast.BufferPos{Line: 2, Col: 2})
+
+	assert.True(t, editor.TableNextCell())
+	assert.Equal(t, "| 名前 | Qty |\n| ---- | --: |\n| 🍎   |   3 |\n| kiwi |  12 |", editor.GetDocument().GetText(),
+		"CJK and emoji take two cells")
+	assert.Equal(t, ast.BufferPos{Line: 2, Col: 11}, editor.GetCursor().GetBufferPos())
+}
+
+func TestTableNewRow(t *testing.T) {
+	editor := ast.NewEditorWithContent("| a | b |\n|---|---|\n| 1 | 2 |\nafter")
+	editor.GetCursor().SetBufferPos(ast.BufferPos{Line: 0, Col: 2})
+
+	assert.True(t, editor.TableNewRow(), "From the header the row goes below the delimiter")
+	assert.Equal(t, "| a   | b   |\n| --- | --- |\n|     |     |\n| 1   | 2   |\nafter", editor.GetDocument().GetText())
+	assert.Equal(t, ast.BufferPos{Line: 2, Col: 2}, editor.GetCursor().GetBufferPos())
+
+	editor.GetCursor().SetBufferPos(ast.BufferPos{Line: 3, Col: 2})
+	assert.True(t, editor.TableNewRow())
+	assert.True(t, editor.TableNewRow(), "An empty last row ends the table")
+	assert.Equal(t, "| a   | b   |\n| --- | --- |\n|     |     |\n| 1   | 2   |\n\nafter", editor.GetDocument().GetText())
+	assert.Equal(t, ast.BufferPos{Line: 4}, editor.GetCursor().GetBufferPos())
+}
+
+func TestTableColumns(t *testing.T) {
+	editor := ast.NewEditorWithContent("| a | b |\n|---|---|\n| 1 | 2 |")
+	editor.GetCursor().SetBufferPos(ast.BufferPos{Line: 2, Col: 2})
+
+	assert.True(t, editor.TableInsertColumn())
+	assert.Equal(t, "| a   |     | b   |\n| --- | --- | --- |\n| 1   |     | 2   |", editor.GetDocument().GetText())
+	assert.Equal(t, ast.BufferPos{Line: 2, Col: 8}, editor.GetCursor().GetBufferPos())
+
+	editor.GetCursor().SetBufferPos(ast.BufferPos{Line: 2, Col: 14})
+	assert.True(t, editor.TableAlignColumn(ast.AlignCenter))
+	assert.Equal(t, "| a   |     |  b  |\n| --- | --- | :-: |\n| 1   |     |  2  |", editor.GetDocument().GetText())
+
+	assert.True(t, editor.TableDeleteColumn())
+	assert.True(t, editor.TableDeleteColumn())
+	assert.Equal(t, "| a   |\n| --- |\n| 1   |", editor.GetDocument().GetText())
+	assert.False(t, editor.TableDeleteColumn(), "The last column stays")
+}
+
+func TestTableFromDelimited(t *testing.T) {
+	table, ok := ast.TableFromDelimited("name\tqty\napple\t3\nkiwi|lime\t12\n")
+	require.True(t, ok)
+	assert.Equal(t, []string{
+		"| name       | qty |",
+		"| ---------- | --- |",
+		"| apple      | 3   |",
+		`| kiwi\|lime | 12  |`,
+	}, table.Format())
+
+	table, ok = ast.TableFromDelimited("a,b\r\n\"x, y\",2\r\n")
+	require.True(t, ok)
+	assert.Equal(t, [][]string{{"a", "b"}, {"x, y", "2"}}, table.Rows)
+
+	for _, text := range []string{
+		"one,two",                           // A single row
+		"a\nb",                              // A single column
+		"Hi, there.\nIt is, I think, fine.", // Ragged prose
+	} {
+		_, ok := ast.TableFromDelimited(text)
+		assert.False(t, ok, text)
+	}
+}
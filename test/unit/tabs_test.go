@@ -0,0 +1,78 @@
+package unit
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ofri/mde/internal/plugins/renderers"
+	"github.com/ofri/mde/pkg/ast"
+	"github.com/ofri/mde/pkg/plugin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTabs_ColumnMapping(t *testing.T) {
+	line := "a\tbc\td"
+	// a=0, tab fills 1-3, b=4, c=5, tab fills 6-7, d=8
+	display := []int{0, 1, 4, 5, 6, 8, 9}
+	for col, want := range display {
+		assert.Equal(t, want, ast.DisplayColumn(line, col, 4), "buffer column %d", col)
+		assert.Equal(t, col, ast.BufferColumn(line, want, 4), "display column %d", want)
+	}
+
+	assert.Equal(t, 1, ast.BufferColumn(line, 3, 4), "Cells inside a tab map to the tab")
+	assert.Equal(t, 7, ast.BufferColumn(line, 10, 4), "Past the end maps one for one")
+	assert.Equal(t, 11, ast.DisplayColumn(line, 8, 4))
+	assert.Equal(t, 2, ast.DisplayColumn("\t\t", 1, 2))
+	assert.Equal(t, "a   bc  d", ast.ExpandTabs(line, 4))
+	assert.Equal(t, line, ast.ExpandTabs(line, 0))
+}
+
+func TestTabs_ViewportTransforms(t *testing.T) {
+	viewport := ast.NewViewport(0, 0, 40, 10, 5, 4)
+	line := "\tx"
+
+	screen, err := viewport.BufferToScreenOnLine(ast.BufferPos{Line: 0, Col: 1}, line)
+	require.NoError(t, err)
+	assert.Equal(t, ast.ScreenPos{Row: 0, Col: 9}, screen, "x is drawn after the expanded tab")
+
+	assert.Equal(t, ast.BufferPos{Line: 0, Col: 1}, viewport.ScreenToBufferOnLine(screen, line))
+	assert.Equal(t, ast.BufferPos{Line: 0, Col: 0}, viewport.ScreenToBufferOnLine(ast.ScreenPos{Row: 0, Col: 7}, line))
+
+	scrolled := viewport.WithLeftColumn(2)
+	screen, err = scrolled.BufferToScreenOnLine(ast.BufferPos{Line: 0, Col: 1}, line)
+	require.NoError(t, err)
+	assert.Equal(t, 7, screen.Col, "leftColumn counts display columns")
+}
+
+func TestTabs_CursorAndRenderingAgree(t *testing.T) {
+	editor := ast.NewEditorWithContent("\tindented\n\t\tdeeper")
+	editor.SetViewPort(40, 10)
+	editor.GetCursor().SetBufferPos(ast.BufferPos{Line: 1, Col: 2})
+
+	screen, err := editor.GetCursor().GetScreenPos()
+	require.NoError(t, err)
+
+	renderer := renderers.NewTerminalRenderer()
+	lines, err := renderer.RenderVisible(context.Background(), &plugin.RenderContext{
+		Document:        editor.GetDocument(),
+		Viewport:        editor.GetViewport(),
+		ShowLineNumbers: true,
+	})
+	require.NoError(t, err)
+	require.Len(t, lines, 2)
+	assert.Equal(t, " 2│         deeper", lines[1].Content)
+	assert.Equal(t, 'd', []rune(lines[1].Content)[screen.Col], "The cursor sits on the character after the tabs")
+}
+
+func TestTabs_HorizontalScrollFollowsDisplayColumns(t *testing.T) {
+	editor := ast.NewEditorWithContent("\t\t\t\t\t\t\tend")
+	editor.SetViewPort(20, 5)
+	editor.MoveCursorToLineEnd()
+
+	viewport := editor.GetViewport()
+	screen, err := editor.GetCursor().GetScreenPos()
+	require.NoError(t, err, "Scrolling brings the cursor past the tabs into view")
+	assert.Less(t, screen.Col, viewport.GetWidth())
+	assert.Equal(t, 31-(20-viewport.GetLineNumberWidth()-1), viewport.GetLeftColumn())
+}
@@ -0,0 +1,71 @@
+package unit
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ofri/mde/internal/config"
+	"github.com/ofri/mde/internal/templates"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTemplates_BuiltinRender(t *testing.T) {
+	now := time.Date(2024, 3, 9, 14, 30, 0, 0, time.UTC)
+	vars := templates.NewVars("Use Postgres", "Jane", now)
+
+	content, err := templates.Render("", "adr", vars)
+	require.NoError(t, err)
+
+	assert.Contains(t, content, "# Use Postgres")
+	assert.Contains(t, content, "Date: 2024-03-09")
+	assert.Contains(t, content, "Deciders: Jane")
+}
+
+func TestTemplates_UserTemplateShadowsBuiltin(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "adr.md"), []byte("custom {{.Title}}"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "standup.md"), []byte("{{.Date}}"), 0644))
+
+	content, err := templates.Render(dir, "adr", templates.Vars{Title: "X"})
+	require.NoError(t, err)
+	assert.Equal(t, "custom X", content)
+
+	names := templates.List(dir)
+	assert.Equal(t, []string{"adr", "meeting", "note", "standup"}, names)
+}
+
+func TestTemplates_Errors(t *testing.T) {
+	_, err := templates.Render("", "does-not-exist", templates.Vars{})
+	assert.Error(t, err)
+
+	_, err = templates.Render("", "../etc/passwd", templates.Vars{})
+	assert.Error(t, err, "Template names must not escape the templates directory")
+}
+
+func TestTemplates_TitleFromFilename(t *testing.T) {
+	assert.Equal(t, "My decision", templates.TitleFromFilename("docs/my-decision.md"))
+	assert.Equal(t, "Notes", templates.TitleFromFilename("notes"))
+	assert.Equal(t, "École", templates.TitleFromFilename("école.md"), "The first letter is upper-cased whole")
+}
+
+func TestConfig_LoadFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.toml")
+
+	cfg, err := config.LoadFile(path)
+	require.NoError(t, err, "Missing config file should yield defaults")
+	assert.Equal(t, "", cfg.Author)
+
+	require.NoError(t, os.WriteFile(path, []byte("author = \"Jane\"\ntemplates_dir = \"/tmp/t\"\n"), 0644))
+	cfg, err = config.LoadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "Jane", cfg.Author)
+	assert.Equal(t, "/tmp/t", cfg.TemplatesDir)
+
+	require.NoError(t, os.WriteFile(path, []byte("author = "), 0644))
+	_, err = config.LoadFile(path)
+	assert.Error(t, err, "Malformed config should be reported")
+}
@@ -0,0 +1,190 @@
+package unit
+
+import (
+	"context"
+	"fmt"
+	"image/color"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ofri/mde/internal/plugins/renderers"
+	"github.com/ofri/mde/internal/theme"
+	"github.com/ofri/mde/pkg/ast"
+	"github.com/ofri/mde/pkg/plugin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTheme_ExportRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	exported := renderers.DefaultTheme().Clone("copy")
+	exported.Background = "#1e1e1e"
+	exported.MinContrast = theme.AAA
+	exported.Styles[theme.Highlight] = plugin.Style{Foreground: "#000000", Background: "#ffd700", Italic: true}
+
+	for _, name := range []string{"copy.toml", "copy.json"} {
+		path := filepath.Join(dir, name)
+		require.NoError(t, exported.Save(path))
+
+		loaded, err := theme.Load(path)
+		require.NoError(t, err)
+		assert.Equal(t, exported, loaded, name)
+	}
+
+	assert.Error(t, exported.Save(filepath.Join(dir, "copy.toml")), "Existing files are not overwritten")
+}
+
+func TestTheme_LoadDirExtendsDefault(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "paper.toml"),
+		[]byte("[styles.heading]\nfg = \"4\"\nbold = true\n"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "sepia.json"),
+		[]byte(`{"extends": "paper", "styles": {"keyword": {"fg": "#704214"}}}`), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "broken.toml"), []byte("styles = ["), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("ignored"), 0o644))
+
+	err := theme.LoadDir(dir)
+	assert.ErrorContains(t, err, "broken.toml", "Bad files are reported")
+
+	defaults := renderers.DefaultTheme()
+	paper, ok := theme.Get("paper")
+	require.True(t, ok, "Named after the file")
+	assert.Equal(t, plugin.Style{Foreground: "4", Bold: true}, paper.Style(theme.Heading))
+	assert.Equal(t, defaults.Style(theme.Keyword), paper.Style(theme.Keyword), "Unnamed elements keep the default style")
+
+	sepia, ok := theme.Get("sepia")
+	require.True(t, ok)
+	assert.Equal(t, plugin.Style{Foreground: "4", Bold: true}, sepia.Style(theme.Heading), "Extends paper")
+	assert.Equal(t, plugin.Style{Foreground: "#704214"}, sepia.Style(theme.Keyword))
+
+	assert.NoError(t, theme.LoadDir(filepath.Join(dir, "missing")))
+}
+
+func TestTheme_ActiveThemeStylesRendering(t *testing.T) {
+	theme.Register(&theme.Theme{Name: "test-active", Styles: map[string]plugin.Style{
+		theme.Keyword: {Foreground: "#ff0000", Underline: true},
+	}})
+	require.NoError(t, theme.SetActive("test-active"))
+	t.Cleanup(func() { theme.SetActive(theme.Default) })
+
+	renderer := renderers.NewTerminalRenderer()
+	rendered, err := renderer.RenderLine(context.Background(), "func main",
+		[]ast.Token{ast.NewToken(0, 4, ast.TokenKeyword)})
+	require.NoError(t, err)
+	require.Len(t, rendered.Styles, 1)
+	assert.Equal(t, plugin.Style{Foreground: "#ff0000", Underline: true}, rendered.Styles[0].Style)
+
+	assert.Error(t, theme.SetActive("no-such-theme"))
+	assert.Equal(t, "test-active", theme.Active().Name, "An unknown theme leaves the active one")
+}
+
+func TestTheme_LightThemeAvoidsBrightColors(t *testing.T) {
+	light, ok := theme.Get(theme.Light)
+	require.True(t, ok)
+	for element, style := range light.Styles {
+		switch style.Foreground {
+		case renderers.ColorWhite, renderers.ColorBrightRed, renderers.ColorBrightGreen, renderers.ColorBrightYellow,
+			renderers.ColorBrightBlue, renderers.ColorBrightMagenta, renderers.ColorBrightCyan:
+			t.Errorf("%s is drawn in %s, which washes out on a light background", element, style.Foreground)
+		}
+	}
+	assert.Equal(t, plugin.Style{Foreground: renderers.ColorRed, Bold: true}, light.Style(theme.Heading1))
+}
+
+func TestTheme_AccessibleThemes(t *testing.T) {
+	contrast, ok := theme.Get(theme.HighContrast)
+	require.True(t, ok)
+	for element, style := range contrast.Styles {
+		assert.False(t, style.Faint, "%s is not dimmed", element)
+		assert.NotContains(t, []string{renderers.ColorGray, renderers.ColorWhite, renderers.ColorRed, renderers.ColorBlue},
+			style.Foreground, "%s uses a bright color", element)
+	}
+
+	safe, ok := theme.Get(theme.Deuteranopia)
+	require.True(t, ok)
+	removed, added := safe.Style(theme.DiffRemovedText), safe.Style(theme.DiffAddedText)
+	assert.Equal(t, "#D55E00", removed.Background)
+	assert.Equal(t, "#56B4E9", added.Background)
+	for element, style := range safe.Styles {
+		assert.NotContains(t, []string{renderers.ColorRed, renderers.ColorGreen, renderers.ColorBrightRed, renderers.ColorBrightGreen},
+			style.Foreground, "%s avoids red and green", element)
+	}
+}
+
+func TestTheme_ContrastRatio(t *testing.T) {
+	black, _ := theme.ParseColor("0")
+	white, ok := theme.ParseColor("#fff")
+	require.True(t, ok)
+	assert.InDelta(t, 21.0, theme.ContrastRatio(black, white), 0.01)
+	assert.InDelta(t, 1.0, theme.ContrastRatio(white, white), 0.01)
+
+	gray, ok := theme.ParseColor("#777777")
+	require.True(t, ok)
+	assert.InDelta(t, 4.48, theme.ContrastRatio(gray, white), 0.01, "The classic just-fails-AA gray")
+
+	cube, ok := theme.ParseColor("196")
+	require.True(t, ok)
+	assert.Equal(t, "#ff0000", fmt.Sprintf("#%02x%02x%02x", cube.R, cube.G, cube.B))
+	_, ok = theme.ParseColor("#12345g")
+	assert.False(t, ok)
+
+	readable := theme.Readable(gray, white, theme.AA)
+	assert.GreaterOrEqual(t, theme.ContrastRatio(readable, white), theme.AA)
+	assert.Less(t, theme.Luminance(readable), theme.Luminance(gray), "Darkened on white")
+	assert.Equal(t, white, theme.Readable(white, black, theme.AAA), "Readable colors are kept")
+}
+
+func TestTheme_ForBackgroundMeetsMinimum(t *testing.T) {
+	dim := &theme.Theme{Name: "dim", Styles: map[string]plugin.Style{
+		theme.Comment:   {Foreground: "#999999"},
+		theme.Invisible: {Foreground: "#999999", Faint: true},
+		theme.Highlight: {Foreground: "#999999", Background: "#000000"},
+		theme.Bold:      {Bold: true},
+	}}
+	assert.Same(t, dim, dim.ForBackground(nil), "Nothing to check against")
+
+	light := dim.ForBackground(color.White)
+	comment, _ := theme.ParseColor(light.Style(theme.Comment).Foreground)
+	bg, _ := theme.ParseColor("#ffffff")
+	assert.GreaterOrEqual(t, theme.ContrastRatio(comment, bg), theme.AA)
+	assert.Equal(t, dim.Style(theme.Invisible), light.Style(theme.Invisible), "Faint styles are dim by design")
+	assert.Equal(t, dim.Style(theme.Highlight), light.Style(theme.Highlight), "Checked against its own background")
+	assert.Equal(t, dim.Style(theme.Bold), light.Style(theme.Bold))
+	assert.Equal(t, "#999999", dim.Style(theme.Comment).Foreground, "The registered theme is untouched")
+
+	strict := dim.Clone("strict")
+	strict.MinContrast = theme.AAA
+	strict.Background = "#ffffff"
+	comment, _ = theme.ParseColor(strict.ForBackground(color.Black).Style(theme.Comment).Foreground)
+	assert.GreaterOrEqual(t, theme.ContrastRatio(comment, bg), theme.AAA, "The theme's background wins")
+
+	off := dim.Clone("off")
+	off.MinContrast = 1
+	assert.Equal(t, "#999999", off.ForBackground(color.White).Style(theme.Comment).Foreground)
+}
+
+func TestTheme_ResolvedFollowsBackground(t *testing.T) {
+	theme.Register(&theme.Theme{Name: "test-resolved", Styles: map[string]plugin.Style{
+		theme.Keyword: {Foreground: renderers.ColorBrightYellow},
+	}})
+	require.NoError(t, theme.SetActive("test-resolved"))
+	t.Cleanup(func() {
+		theme.SetActive(theme.Default)
+		theme.SetBackground(nil)
+	})
+
+	assert.Equal(t, renderers.ColorBrightYellow, theme.Resolved().Style(theme.Keyword).Foreground)
+	theme.SetBackground(color.White)
+	adjusted := theme.Resolved()
+	assert.NotEqual(t, renderers.ColorBrightYellow, adjusted.Style(theme.Keyword).Foreground, "Yellow on white is adjusted")
+	assert.Same(t, adjusted, theme.Resolved(), "Adjusted once per theme and background")
+	assert.Equal(t, renderers.ColorBrightYellow, theme.Active().Style(theme.Keyword).Foreground)
+
+	renderer := renderers.NewTerminalRenderer()
+	rendered, err := renderer.RenderLine(context.Background(), "func main",
+		[]ast.Token{ast.NewToken(0, 4, ast.TokenKeyword)})
+	require.NoError(t, err)
+	require.Len(t, rendered.Styles, 1)
+	assert.Equal(t, adjusted.Style(theme.Keyword), rendered.Styles[0].Style)
+}
@@ -0,0 +1,50 @@
+package unit
+
+import (
+	"testing"
+
+	"github.com/ofri/mde/pkg/ast"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHeadings(t *testing.T) {
+	doc := ast.NewDocument("# Intro ##\nSetext Title\n---\n```\n# code\n```\n## Intro\n### C++ & Go!")
+
+	headings := doc.Headings()
+	assert.Equal(t, []ast.Heading{
+		{Line: 0, Level: 1, Text: "Intro", Anchor: "intro"},
+		{Line: 1, Level: 2, Text: "Setext Title", Anchor: "setext-title"},
+		{Line: 6, Level: 2, Text: "Intro", Anchor: "intro-1"},
+		{Line: 7, Level: 3, Text: "C++ & Go!", Anchor: "c--go"},
+	}, headings)
+}
+
+func TestTableOfContents(t *testing.T) {
+	toc := ast.TableOfContents([]ast.Heading{
+		{Level: 2, Text: "Usage", Anchor: "usage"},
+		{Level: 3, Text: "[Flags]", Anchor: "flags"},
+		{Level: 2, Text: "FAQ", Anchor: "faq"},
+	})
+	assert.Equal(t, []string{
+		"- [Usage](#usage)",
+		"  - [\\[Flags\\]](#flags)",
+		"- [FAQ](#faq)",
+	}, toc)
+}
+
+func TestUpdateTOC(t *testing.T) {
+	editor := ast.NewEditorWithContent("# Doc\n\n## One\n## Two")
+	editor.GetCursor().SetBufferPos(ast.BufferPos{Line: 1})
+
+	assert.False(t, editor.UpdateTOC("<!-- toc -->", "<!-- /toc -->", false), "Nothing to refresh without markers")
+	assert.True(t, editor.UpdateTOC("<!-- toc -->", "<!-- /toc -->", true))
+	assert.Equal(t, "# Doc\n<!-- toc -->\n- [Doc](#doc)\n  - [One](#one)\n  - [Two](#two)\n<!-- /toc -->\n\n## One\n## Two",
+		editor.GetDocument().GetText())
+	assert.Equal(t, 6, editor.GetCursor().GetBufferPos().Line, "The cursor stays on its line")
+
+	editor.GetCursor().SetBufferPos(ast.BufferPos{Line: 8, Col: 6})
+	editor.TypeText("s")
+	assert.True(t, editor.UpdateTOC("<!-- toc -->", "<!-- /toc -->", false))
+	assert.Equal(t, "# Doc\n<!-- toc -->\n- [Doc](#doc)\n  - [One](#one)\n  - [Twos](#twos)\n<!-- /toc -->\n\n## One\n## Twos",
+		editor.GetDocument().GetText())
+}
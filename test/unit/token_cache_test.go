@@ -0,0 +1,70 @@
+package unit
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/ofri/mde/pkg/ast"
+	"github.com/stretchr/testify/assert"
+)
+
+// countingTokenizer makes one token per line and counts its calls
+func countingTokenizer(calls *int) func(string) []ast.Token {
+	return func(line string) []ast.Token {
+		*calls++
+		return []ast.Token{ast.NewToken(0, len([]rune(line)), ast.TokenText)}
+	}
+}
+
+func TestTokenCache_TokenizesOnDemand(t *testing.T) {
+	doc := ast.NewDocument("one\ntwo")
+	assert.Nil(t, doc.GetLineTokens(0), "No tokens without a tokenizer")
+
+	calls := 0
+	doc.SetTokenizer(countingTokenizer(&calls))
+	assert.Len(t, doc.GetLineTokens(0), 1)
+	assert.Len(t, doc.GetLineTokens(0), 1)
+	assert.Equal(t, 1, calls, "Cached tokens are reused")
+
+	doc.InsertChar(ast.BufferPos{Line: 0, Col: 3}, '!')
+	assert.False(t, doc.HasLineTokens(0), "Editing a line makes its tokens stale")
+	assert.Equal(t, 4, doc.GetLineTokens(0)[0].End())
+	assert.Equal(t, 2, calls)
+}
+
+func TestTokenCache_Budget(t *testing.T) {
+	lines := make([]string, 100)
+	for i := range lines {
+		lines[i] = fmt.Sprintf("line %d", i)
+	}
+	doc := ast.NewDocument(strings.Join(lines, "\n"))
+	calls := 0
+	doc.SetTokenizer(countingTokenizer(&calls))
+	for i := range lines {
+		doc.GetLineTokens(i)
+	}
+	full := doc.CachedTokenBytes()
+	assert.Positive(t, full)
+
+	// Room for ten lines' tokens keeps the ten used last
+	doc.SetTokenBudget(full / 10)
+	assert.LessOrEqual(t, doc.CachedTokenBytes(), full/10)
+	assert.True(t, doc.HasLineTokens(99))
+	assert.True(t, doc.HasLineTokens(90))
+	assert.False(t, doc.HasLineTokens(89))
+
+	// An evicted line is tokenized again when asked for
+	calls = 0
+	assert.Len(t, doc.GetLineTokens(0), 1)
+	assert.Equal(t, 1, calls)
+	assert.False(t, doc.HasLineTokens(90), "Using a line evicts the least recently used")
+}
+
+func TestTokenCache_LongLinesNotTokenized(t *testing.T) {
+	doc := ast.NewDocument(strings.Repeat("x", ast.LongLineRunes))
+	calls := 0
+	doc.SetTokenizer(countingTokenizer(&calls))
+	assert.Nil(t, doc.GetLineTokens(0))
+	assert.Zero(t, calls)
+}
@@ -0,0 +1,44 @@
+package unit
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ofri/mde/pkg/ast"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTypewriter_CentersCursorLine(t *testing.T) {
+	editor := ast.NewEditorWithContent(strings.Repeat("line\n", 30))
+	editor.SetViewPort(80, 10)
+	editor.SetTypewriterMode(true)
+
+	// Near the top the document cannot scroll above line 0
+	editor.MoveCursorDown()
+	assert.Equal(t, 0, editor.GetViewport().GetTopLine())
+
+	for i := 0; i < 9; i++ {
+		editor.MoveCursorDown()
+	}
+	assert.Equal(t, 5, editor.GetViewport().GetTopLine(), "Line 10 sits mid-screen")
+
+	// Typing a newline scrolls the text, not the cursor
+	editor.InsertText("\n")
+	assert.Equal(t, 6, editor.GetViewport().GetTopLine())
+	editor.DeleteText(1)
+	assert.Equal(t, 5, editor.GetViewport().GetTopLine())
+}
+
+func TestTypewriter_OffScrollsOnlyAtEdges(t *testing.T) {
+	editor := ast.NewEditorWithContent(strings.Repeat("line\n", 30))
+	editor.SetViewPort(80, 10)
+
+	for i := 0; i < 10; i++ {
+		editor.MoveCursorDown()
+	}
+	assert.Equal(t, 1, editor.GetViewport().GetTopLine())
+
+	// Typing at the bottom edge keeps the cursor visible
+	editor.InsertText("\n")
+	assert.Equal(t, 2, editor.GetViewport().GetTopLine())
+}
@@ -0,0 +1,62 @@
+package unit
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/ofri/mde/internal/config"
+	"github.com/ofri/mde/internal/plugins/renderers"
+	"github.com/ofri/mde/internal/tui"
+	"github.com/ofri/mde/pkg/ast"
+	"github.com/ofri/mde/pkg/plugin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func previewContents(t *testing.T, text string, options map[string]interface{}) []string {
+	renderer := renderers.NewTerminalRenderer()
+	require.NoError(t, renderer.Configure(options))
+	lines, err := renderer.RenderPreviewVisible(context.Background(), &plugin.RenderContext{
+		Document: ast.NewDocument(text),
+		Viewport: ast.NewViewport(0, 0, 80, 10, 0, 9),
+	})
+	require.NoError(t, err)
+
+	contents := make([]string, len(lines))
+	for i, line := range lines {
+		contents[i] = line.Content
+	}
+	return contents
+}
+
+func TestTypographer_Preview(t *testing.T) {
+	source := strings.Join([]string{
+		`"Hi," she said -- it's 'fine'... really---yes`,
+		"keep `\"code\" -- as is` and \\\"this\\\"",
+		"|---|:--:|",
+		"```",
+		`x = "raw" -- 'code'`,
+		"```",
+	}, "\n")
+
+	lines := previewContents(t, source, map[string]interface{}{"typographer": true})
+	assert.Equal(t, "“Hi,” she said – it’s ‘fine’… really—yes", lines[0])
+	assert.Equal(t, "keep `\"code\" -- as is` and \\\"this\\\"", lines[1])
+	assert.Equal(t, "|---|:--:|", lines[2], "Delimiter rows are not dashes")
+	assert.Equal(t, `x = "raw" -- 'code'`, lines[4], "Fenced code is shown as written")
+
+	lines = previewContents(t, source, map[string]interface{}{"typographer": false})
+	assert.Equal(t, `"Hi," she said -- it's 'fine'... really---yes`, lines[0])
+}
+
+func TestTypographer_HTML(t *testing.T) {
+	model := tui.New()
+	assert.Contains(t, model.ConvertMarkdownToHTML(`"a" -- b`), "&quot;a&quot; -- b")
+
+	cfg := config.Default()
+	cfg.Typographer = true
+	model.SetConfig(cfg)
+	html := model.ConvertMarkdownToHTML(`"a" -- b`)
+	assert.Contains(t, html, "&ldquo;a&rdquo; &ndash; b")
+}
@@ -1,10 +1,12 @@
 package unit
 
 import (
+	"strings"
 	"testing"
 	
 	"github.com/ofri/mde/pkg/ast"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestViewportScrolling(t *testing.T) {
@@ -87,4 +89,60 @@ func TestViewportScrolling(t *testing.T) {
 		viewport := editor.GetViewport()
 		assert.Equal(t, 0, viewport.GetLeftColumn())
 	})
-}
\ No newline at end of file
+}
+
+func TestViewportScrolling_Pages(t *testing.T) {
+	var lines []string
+	for i := 0; i < 50; i++ {
+		lines = append(lines, "line")
+	}
+	editor := ast.NewEditorWithContent(strings.Join(lines, "\n"))
+	editor.SetViewPort(80, 10)
+	cursor := editor.GetCursorBufferPosition()
+
+	editor.ScrollHalfPageDown()
+	assert.Equal(t, 5, editor.GetViewport().GetTopLine())
+	editor.ScrollPageDown()
+	assert.Equal(t, 14, editor.GetViewport().GetTopLine())
+	editor.ScrollPageUp()
+	editor.ScrollHalfPageUp()
+	assert.Equal(t, 0, editor.GetViewport().GetTopLine())
+
+	editor.ScrollToBottom()
+	assert.Equal(t, 40, editor.GetViewport().GetTopLine(), "Last line sits at the bottom")
+	editor.ScrollToTop()
+	assert.Equal(t, 0, editor.GetViewport().GetTopLine())
+
+	assert.Equal(t, cursor, editor.GetCursorBufferPosition(), "Scrolling never moves the cursor")
+}
+
+func TestViewportScrolling_HorizontalClamp(t *testing.T) {
+	editor := ast.NewEditorWithContent(strings.Repeat("x", 30) + "\nshort")
+	editor.SetViewPort(20, 5)
+	textWidth := 20 - editor.GetViewport().GetLineNumberWidth()
+
+	editor.ScrollViewportRight(100)
+	assert.Equal(t, 30-textWidth+1, editor.GetViewport().GetLeftColumn(),
+		"Stops once the longest visible line ends on screen")
+
+	editor.ScrollViewportDown(1)
+	editor.ScrollViewportRight(5)
+	assert.Equal(t, 30-textWidth+1, editor.GetViewport().GetLeftColumn(),
+		"Shorter lines in view never pull the viewport back")
+
+	editor = ast.NewEditorWithContent("short")
+	editor.SetViewPort(20, 5)
+	editor.ScrollViewportRight(3)
+	assert.Equal(t, 0, editor.GetViewport().GetLeftColumn(), "Nothing to scroll when every line fits")
+}
+
+func TestViewportScrolling_ClampOnResize(t *testing.T) {
+	editor := ast.NewEditorWithContent(strings.Repeat("x", 30) + "\nshort")
+	editor.SetViewPort(20, 5)
+	editor.ScrollViewportRight(100)
+	assert.Equal(t, 30, editor.LongestVisibleLine())
+	require.Positive(t, editor.GetViewport().GetLeftColumn())
+
+	editor.SetViewPort(60, 5)
+	assert.Equal(t, 0, editor.GetViewport().GetLeftColumn(), "Nothing left to scroll once the lines fit")
+}
@@ -0,0 +1,50 @@
+package unit
+
+import (
+	"testing"
+
+	"github.com/ofri/mde/pkg/ast"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTrailingWhitespaceStart(t *testing.T) {
+	assert.Equal(t, 4, ast.TrailingWhitespaceStart("text \t "))
+	assert.Equal(t, 4, ast.TrailingWhitespaceStart("text"))
+	assert.Equal(t, 0, ast.TrailingWhitespaceStart("  "))
+	assert.Equal(t, 3, ast.TrailingWhitespaceStart("日本語  "), "Rune offsets")
+}
+
+func TestStripTrailingWhitespace(t *testing.T) {
+	doc := ast.NewDocument("a  \nb    \nc\t\n   \nd")
+	assert.Equal(t, 3, doc.StripTrailingWhitespace(true))
+	assert.Equal(t, "a  \nb  \nc\n\nd", doc.GetText())
+
+	doc = ast.NewDocument("a  \nb")
+	assert.Equal(t, 1, doc.StripTrailingWhitespace(false))
+	assert.Equal(t, "a\nb", doc.GetText())
+	assert.Equal(t, 0, doc.StripTrailingWhitespace(false))
+}
+
+func TestSetFinalNewline(t *testing.T) {
+	doc := ast.NewDocument("a\nb")
+	assert.False(t, doc.HasFinalNewline())
+	assert.True(t, doc.SetFinalNewline(true))
+	assert.Equal(t, "a\nb\n", doc.GetText())
+	assert.False(t, doc.SetFinalNewline(true))
+
+	doc = ast.NewDocument("a\n\n\n")
+	assert.True(t, doc.HasFinalNewline())
+	assert.True(t, doc.SetFinalNewline(false))
+	assert.Equal(t, "a", doc.GetText(), "Every final line break goes")
+	assert.False(t, doc.SetFinalNewline(false))
+
+	doc = ast.NewDocument("")
+	assert.False(t, doc.HasFinalNewline())
+	assert.False(t, doc.SetFinalNewline(true), "An empty document stays empty")
+}
+
+func TestStripCarriageReturns(t *testing.T) {
+	doc := ast.NewDocument("a\r\nb\r\nc")
+	assert.Equal(t, 2, doc.StripCarriageReturns())
+	assert.Equal(t, "a\nb\nc", doc.GetText())
+}
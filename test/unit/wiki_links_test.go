@@ -0,0 +1,85 @@
+package unit
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ofri/mde/internal/plugins/parsers"
+	"github.com/ofri/mde/internal/wiki"
+	"github.com/ofri/mde/pkg/ast"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWikiLinks_FindLinks(t *testing.T) {
+	links := wiki.FindLinks("See [[Page One]] and [[Other Page#Setup|the setup]].")
+	require.Len(t, links, 2)
+
+	assert.Equal(t, "Page One", links[0].Target)
+	assert.Equal(t, 4, links[0].Start)
+	assert.Equal(t, 16, links[0].End)
+
+	assert.Equal(t, "Other Page", links[1].Target)
+	assert.Equal(t, "Setup", links[1].Heading)
+	assert.Equal(t, "the setup", links[1].Alias)
+}
+
+func TestWikiLinks_LinkAtAndPartial(t *testing.T) {
+	line := "ünï [[Target]] x"
+
+	link, ok := wiki.LinkAt(line, 6)
+	assert.True(t, ok, "Rune column inside the link should hit it")
+	assert.Equal(t, "Target", link.Target)
+
+	_, ok = wiki.LinkAt(line, 1)
+	assert.False(t, ok)
+
+	partial, ok := wiki.PartialAt("text [[Pag", 10)
+	assert.True(t, ok)
+	assert.Equal(t, "Pag", partial)
+
+	_, ok = wiki.PartialAt("text [[Page]] more", 18)
+	assert.False(t, ok, "Closed links are not completion candidates")
+}
+
+func TestWikiLinks_Resolve(t *testing.T) {
+	root := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "sub"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "sub", "my-note.md"), nil, 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "Other.md"), nil, 0644))
+
+	path, found := wiki.Resolve(root, "My Note")
+	assert.True(t, found)
+	assert.Equal(t, filepath.Join(root, "sub", "my-note.md"), path)
+
+	_, found = wiki.Resolve(root, "Missing")
+	assert.False(t, found)
+	assert.Equal(t, filepath.Join(root, "Missing.md"), wiki.NewNotePath(root, "Missing"))
+
+	assert.Equal(t, []string{"Other", "my-note"}, wiki.NoteNames(root))
+}
+
+func TestWikiLinks_ParserOption(t *testing.T) {
+	parser := parsers.NewCommonMarkParser()
+	ctx := context.Background()
+
+	hasWikiToken := func() bool {
+		tokens, err := parser.GetSyntaxHighlighting(ctx, "go to [[Home]]")
+		require.NoError(t, err)
+		for _, token := range tokens {
+			if token.Kind() == ast.TokenWikiLink {
+				assert.Equal(t, 8, token.Start())
+				assert.Equal(t, 12, token.End())
+				return true
+			}
+		}
+		return false
+	}
+
+	assert.False(t, hasWikiToken(), "Wiki links are opt-in")
+
+	require.NoError(t, parser.Configure(map[string]interface{}{"wiki_links": true}))
+	assert.True(t, hasWikiToken())
+}